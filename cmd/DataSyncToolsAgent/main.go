@@ -4,26 +4,40 @@ package main
 import (
 	"database/sql"
 	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncclient"
 	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncdao/syncdaobolt"
+	"data-sync-tools-go/syncdao/syncdaomem"
 	"data-sync-tools-go/syncdao/syncdaopq"
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"data-sync-tools-go/syncdao/syncdaosqlite"
 	"data-sync-tools-go/synchandler"
+	"data-sync-tools-go/syncorchestrator"
 	"data-sync-tools-go/syncutil"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
-var dbType = flag.String("dbty", "postgressql", "The database to use: 'postgressql' or 'mssql'.")
-var dbUser = flag.String("dbusr", "doug", "The name of the database database to use: 'postgressql', 'sqlite' or 'mssql'.")
+var dbType = flag.String("dbty", "postgressql", "The database to use: 'postgressql', 'sqlite', 'bolt' or 'memory'. With 'memory' the data is only kept while the agent runs.")
+var dbUser = flag.String("dbusr", "doug", "The database user.")
 var dbPass = flag.String("dbpw", "", "The database password.")
 var dbServer = flag.String("dbsv", "localhost", "The database server.")
-var dbName = flag.String("dbnm", "threads", "The database name.")
+var dbName = flag.String("dbnm", "threads", "The database name. For 'sqlite' and 'bolt', the path of the database file.")
 var dbPort = flag.Int("dbpt", 0, "The database port.")
 var httpAddress = flag.String("httpad", "", "The http address.")
 var httpPort = flag.Int("httppt", 8080, "The http port.")
+var tombstoneRetention = flag.Duration("tombretn", 0, "How long tombstones are kept when not yet acknowledged by every paired node. 0 keeps them until acknowledged.")
+var tombstoneInterval = flag.Duration("tombint", 0, "How often tombstones are collected in the background. 0 disables background collection.")
+var sessionReapInterval = flag.Duration("sesint", time.Minute, "How often sessions outliving the maximum session duration of their pair are ended in the background. 0 disables reaping.")
+var peerNodeNames = flag.String("peer", "", "The names of the peer nodes to sync with, separated by commas. Their agents are reached at the DataMsgConsumerURI and DataMsgProducerURI of their pair. Empty disables peer to peer sync.")
+var localNodeName = flag.String("node", "", "The name of the node of this agent. Required with 'peer'.")
+var peerInterval = flag.Duration("peerint", 5*time.Minute, "How often the peers are synced with in the background. 0 only syncs on demand with 'PUT /peerSync'.")
 
 func main() {
 
@@ -41,6 +55,7 @@ func main() {
 
 	var db *sql.DB
 	var dbFactory syncdao.DaosFactory
+	var repository syncapi.Repository
 	var err error
 	//Setup Database
 	if *dbType == "postgressql" {
@@ -49,22 +64,36 @@ func main() {
 			log.Fatal("Bad argument for 'dbty'")
 			return
 		}
-		handlers := synchandler.Handlers{
-			Repository: syncapi.Repository{
-				DataRepo:   syncdaopq.NewDataRepository(db),
-				ConfigRepo: syncdaopq.NewConfigRepository(db),
-			},
-		}
-		router = synchandler.NewRouter(handlers)
+		repository = newSQLRepository(db, syncdaopq.Dialect{})
 
 		dbFactory, err = syncdaopq.NewPostgresSQLDaosFactory(*dbUser, *dbPass, *dbServer, *dbName, *dbPort)
-		//} else if *dbType == "sqlite" {
-		//	dbFactory, err = NewSqliteDaosFactory(*dbUser, *dbPass, *dbName, *dbPort)
-		//} else if *dbType == "mssql" {
 		//} else if *dbType == "mssql" {
 		//dbFactory, err = NewPostgresSqlDaosFactory("doug", "postgres", "threads")
 		//	dbFactory, err = NewMsSqlDaosFactory(*dbUser, *dbPass, *dbServer, *dbName, *dbPort)
 		//dbFactory, err = NewMsSqlDaosFactory("doug", "postgres", "threads")
+	} else if *dbType == "sqlite" {
+		var sqliteFactory *syncdaosql.SQLDaosFactory
+		sqliteFactory, err = syncdaosqlite.NewSQLiteDaosFactory(*dbName)
+		if err != nil {
+			log.Fatal("Cannot open sqlite database '", *dbName, "'. Error: ", err)
+			return
+		}
+		db = sqliteFactory.SQLDb()
+		repository = newSQLRepository(db, syncdaosqlite.Dialect{})
+		dbFactory = sqliteFactory
+	} else if *dbType == "bolt" {
+		var boltFactory *syncdaomem.MemoryDaosFactory
+		boltFactory, err = syncdaobolt.NewBoltDaosFactory(*dbName)
+		if err != nil {
+			log.Fatal("Cannot open bolt database '", *dbName, "'. Error: ", err)
+			return
+		}
+		repository = newMemoryRepository(boltFactory)
+		dbFactory = boltFactory
+	} else if *dbType == "memory" {
+		memoryFactory := syncdaomem.NewMemoryDaosFactory()
+		repository = newMemoryRepository(memoryFactory)
+		dbFactory = memoryFactory
 	} else {
 		log.Fatal("Bad argument for 'dbty'")
 		return
@@ -77,6 +106,46 @@ func main() {
 		log.Println("No Data object Found")
 	}
 	defer dbFactory.Close()
+	handlers := synchandler.Handlers{
+		Repository:         repository,
+		TombstoneRetention: *tombstoneRetention,
+	}
+	router = synchandler.NewRouter(handlers)
+	if *tombstoneInterval > 0 {
+		stopTombstoneCollection := syncapi.StartTombstoneCollection(handlers.Repository.TombstoneRepo, *tombstoneInterval, *tombstoneRetention)
+		defer stopTombstoneCollection()
+	}
+	if *sessionReapInterval > 0 {
+		stopSessionReaping := syncapi.StartSessionReaping(handlers.Repository.SessionRepo, *sessionReapInterval)
+		defer stopSessionReaping()
+	}
+	if *peerNodeNames != "" {
+		if *localNodeName == "" {
+			log.Fatal("Argument 'node' is required with 'peer'")
+			return
+		}
+		localHost := *httpAddress
+		if localHost == "" {
+			localHost = "localhost"
+		}
+		peerSyncer := &syncorchestrator.PeerSyncer{
+			Local: syncorchestrator.Agent{
+				NodeName: *localNodeName,
+				Client:   syncclient.NewClient(fmt.Sprintf("http://%v:%v", localHost, *httpPort)),
+			},
+			PeerNodeNames: strings.Split(*peerNodeNames, ","),
+			Orchestrator: syncorchestrator.Orchestrator{
+				MaxRetries: 3,
+				RetryWait:  5 * time.Second,
+			},
+		}
+		router.Methods("PUT").Path("/peerSync").Name("PeerSync").Handler(synchandler.Logger(peerSyncer, "PeerSync"))
+		if *peerInterval > 0 {
+			stopPeerSync := peerSyncer.StartPeerSync(*peerInterval)
+			defer stopPeerSync()
+		}
+		log.Printf("Syncing '%s' with peers '%s'", *localNodeName, *peerNodeNames)
+	}
 	var address = fmt.Sprintf("%v:%v", *httpAddress, *httpPort)
 	log.Println("Listening on " + address)
 	log.Fatal(http.ListenAndServe(address, router))
@@ -97,3 +166,27 @@ func createAndVerifyDBConn(dbUser string, dbPassword string, dbHost string, dbNa
 	}
 	return db, nil
 }
+
+//newSQLRepository provides the repository of the sql database db, whose sql is written in dialect.
+func newSQLRepository(db *sql.DB, dialect syncdaosql.Dialect) syncapi.Repository {
+	return syncapi.Repository{
+		DataRepo:      syncdaosql.NewDataRepository(db, dialect),
+		ConfigRepo:    syncdaosql.NewConfigRepository(db),
+		ConflictRepo:  syncdaosql.NewConflictRepository(db, dialect),
+		TombstoneRepo: syncdaosql.NewTombstoneRepository(db, dialect),
+		SessionRepo:   syncdaosql.NewSessionRepository(db),
+		NodeAdminRepo: syncdaosql.NewNodeAdminRepository(db),
+	}
+}
+
+//newMemoryRepository provides the repository of the sync model held in memory by factory.
+func newMemoryRepository(factory *syncdaomem.MemoryDaosFactory) syncapi.Repository {
+	return syncapi.Repository{
+		DataRepo:      syncdaomem.NewDataRepository(factory),
+		ConfigRepo:    syncdaomem.NewConfigRepository(factory),
+		ConflictRepo:  syncdaomem.NewConflictRepository(factory),
+		TombstoneRepo: syncdaomem.NewTombstoneRepository(factory),
+		SessionRepo:   syncdaomem.NewSessionRepository(factory),
+		NodeAdminRepo: syncdaomem.NewNodeAdminRepository(factory),
+	}
+}
@@ -0,0 +1,56 @@
+//Data Sync Orchestrator: syncs a pair of nodes held by one or two running agents
+package main
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncclient"
+	"data-sync-tools-go/syncorchestrator"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/twinj/uuid"
+)
+
+var localURL = flag.String("localurl", "http://localhost:8080", "The http address of the agent holding the local node.")
+var localNode = flag.String("node", "", "The name of the local node.")
+var peerURL = flag.String("peerurl", "", "The http address of the agent holding the peer node. Empty when the local agent holds both nodes.")
+var peerNode = flag.String("peernode", "", "The name of the peer node.")
+var sessionID = flag.String("session", "", "The id of the sync session. Pass the id of a failed sync to resume it. Empty creates a new id.")
+var maxMsgs = flag.Int("maxmsgs", 0, "The most records fetched in one batch. 0 uses the batch size configured for the node.")
+var maxBytes = flag.Int("maxbytes", 0, "The most record bytes fetched in one batch. 0 uses the batch size configured for the node.")
+var maxRetries = flag.Int("retries", 3, "How many times a call to an agent failing for an unavailable agent is retried.")
+var retryWait = flag.Duration("retrywait", 5*time.Second, "How long to wait before retrying a call.")
+
+func main() {
+
+	flag.Parse()
+
+	if *localNode == "" || *peerNode == "" {
+		log.Fatal("Both 'node' and 'peernode' are required")
+		return
+	}
+	if *peerURL == "" {
+		*peerURL = *localURL
+	}
+	if *sessionID == "" {
+		*sessionID = uuid.Formatter(uuid.NewV4(), uuid.FormatCanonical)
+	}
+	orchestrator := syncorchestrator.Orchestrator{
+		Local: syncorchestrator.Agent{NodeName: *localNode, Client: syncclient.NewClient(*localURL)},
+		Peer:  syncorchestrator.Agent{NodeName: *peerNode, Client: syncclient.NewClient(*peerURL)},
+		Limits: syncapi.FetchLimits{
+			MaxMsgs:           *maxMsgs,
+			MaxGroupBytesSize: *maxBytes,
+		},
+		MaxRetries: *maxRetries,
+		RetryWait:  *retryWait,
+	}
+	log.Printf("Syncing '%s' at '%s' with '%s' at '%s' in session '%s'", *localNode, *localURL, *peerNode, *peerURL, *sessionID)
+	summary, err := orchestrator.Sync(*sessionID)
+	fmt.Print(summary)
+	if err != nil {
+		log.Fatalf("Sync failed: %v. Resume it with -session %s", err, *sessionID)
+	}
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncclient"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncdao/syncdaobolt"
+	"data-sync-tools-go/syncdao/syncdaomem"
+	"data-sync-tools-go/syncdao/syncdaopq"
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"data-sync-tools-go/syncdao/syncdaosqlite"
+	"data-sync-tools-go/synchandler"
+	"fmt"
+	"time"
+)
+
+//administrator performs the commands available both against a database and a running agent.
+type administrator interface {
+	CreateNode(node syncdao.SyncNode) error
+	ListNodes() ([]syncdao.SyncNode, error)
+	DeleteNode(nodeID string) error
+	PendingChanges(nodeID string) (syncapi.NodePendingChanges, error)
+	PairState(pairID string) (synchandler.QueryPairStateResponse, error)
+	CancelSession(sessionID string, reason string) (synchandler.CancelSyncSessionResponse, error)
+	AddPair(pair syncdao.SyncPair, node1Name string, node2Name string) error
+	ListPairs() ([]syncdao.SyncPair, error)
+	DeletePair(pairID string) error
+	AddDataVersion(dataVersionName string) error
+	ListDataVersions() ([]string, error)
+	AddEntity(dataVersionName string, entity syncdao.EntityPairItem) error
+	AddField(dataVersionName string, entitySingularName string, field syncdao.SyncFieldDefinition) error
+	ValidateSyncModel(dataVersionName string) ([]string, error)
+}
+
+//dbAdministrator administers the sync cluster held in a database.
+type dbAdministrator struct {
+	daos       syncdao.DaosFactory
+	repository syncapi.Repository
+}
+
+//openDbAdministrator opens the database of dbType the way the agent does.
+func openDbAdministrator(dbType string, dbUser string, dbPass string, dbServer string, dbName string, dbPort int) (*dbAdministrator, error) {
+	switch dbType {
+	case "postgressql":
+		factory, err := syncdaopq.NewPostgresSQLDaosFactory(dbUser, dbPass, dbServer, dbName, dbPort)
+		if err != nil {
+			return nil, err
+		}
+		return &dbAdministrator{
+			daos: factory,
+			repository: syncapi.Repository{
+				SessionRepo:   syncdaosql.NewSessionRepository(factory.SQLDb()),
+				NodeAdminRepo: syncdaosql.NewNodeAdminRepository(factory.SQLDb()),
+			},
+		}, nil
+	case "sqlite":
+		factory, err := syncdaosqlite.NewSQLiteDaosFactory(dbName)
+		if err != nil {
+			return nil, err
+		}
+		return &dbAdministrator{
+			daos: factory,
+			repository: syncapi.Repository{
+				SessionRepo:   syncdaosql.NewSessionRepository(factory.SQLDb()),
+				NodeAdminRepo: syncdaosql.NewNodeAdminRepository(factory.SQLDb()),
+			},
+		}, nil
+	case "bolt":
+		factory, err := syncdaobolt.NewBoltDaosFactory(dbName)
+		if err != nil {
+			return nil, err
+		}
+		return &dbAdministrator{
+			daos: factory,
+			repository: syncapi.Repository{
+				SessionRepo:   syncdaomem.NewSessionRepository(factory),
+				NodeAdminRepo: syncdaomem.NewNodeAdminRepository(factory),
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown database type '%s'. Expected 'postgressql', 'sqlite' or 'bolt'", dbType)
+	}
+}
+
+func (admin *dbAdministrator) CreateNode(node syncdao.SyncNode) error {
+	return admin.daos.SyncNodeDao().AddNode(node)
+}
+
+func (admin *dbAdministrator) ListNodes() ([]syncdao.SyncNode, error) {
+	return admin.daos.SyncNodeDao().ListNodes()
+}
+
+func (admin *dbAdministrator) DeleteNode(nodeID string) error {
+	return admin.daos.SyncNodeDao().DeleteNodeByNodeID(nodeID)
+}
+
+func (admin *dbAdministrator) PendingChanges(nodeID string) (syncapi.NodePendingChanges, error) {
+	return admin.repository.NodeAdminRepo.PendingChanges(nodeID)
+}
+
+func (admin *dbAdministrator) PairState(pairID string) (synchandler.QueryPairStateResponse, error) {
+	result, err := admin.daos.SyncPairDao().QueryPairState(syncdao.QueryPairStateRequest{PairID: pairID})
+	if err != nil {
+		return synchandler.QueryPairStateResponse{PairID: pairID, Result: "Error", ResultMsg: err.Error()}, err
+	}
+	return synchandler.QueryPairStateResponse{
+		PairID:          pairID,
+		State:           result.State,
+		SessionID:       result.SessionID,
+		Progress:        result.Progress,
+		PercentComplete: syncdao.PercentComplete(result.Progress),
+		Result:          "OK",
+	}, nil
+}
+
+func (admin *dbAdministrator) CancelSession(sessionID string, reason string) (synchandler.CancelSyncSessionResponse, error) {
+	result, err := admin.repository.SessionRepo.CancelSession(sessionID, reason, time.Now())
+	if err != nil {
+		return synchandler.CancelSyncSessionResponse{SessionID: sessionID}, err
+	}
+	return synchandler.CancelSyncSessionResponse{
+		SessionID:      sessionID,
+		PairID:         result.Session.PairID,
+		State:          result.Session.State,
+		ReleasedCount:  result.Session.ReleasedCount,
+		DiscardedCount: result.DiscardedCount,
+		Result:         result.Result,
+	}, nil
+}
+
+//AddPair adds the pair of the nodes named node1Name and node2Name, along with the configuration of each node targeting
+//the other.
+func (admin *dbAdministrator) AddPair(pair syncdao.SyncPair, node1Name string, node2Name string) error {
+	err := syncapi.ValidatePair(pair)
+	if err != nil {
+		return err
+	}
+	node1, err := admin.daos.SyncNodeDao().GetOneNodeByNodeName(node1Name)
+	if err != nil {
+		return fmt.Errorf("node '%s': %v", node1Name, err)
+	}
+	node2, err := admin.daos.SyncNodeDao().GetOneNodeByNodeName(node2Name)
+	if err != nil {
+		return fmt.Errorf("node '%s': %v", node2Name, err)
+	}
+	pairNodes := []syncdao.PairNode{
+		{PairID: pair.PairID, NodeID: node1.NodeID, TargetNodeID: node2.NodeID, SyncConflictURI: pair.SyncConflictURI},
+		{PairID: pair.PairID, NodeID: node2.NodeID, TargetNodeID: node1.NodeID, SyncConflictURI: pair.SyncConflictURI},
+	}
+	for _, pairNode := range pairNodes {
+		err = syncapi.ValidatePairNode(pairNode)
+		if err != nil {
+			return err
+		}
+	}
+	syncPairDao := admin.daos.SyncPairDao()
+	err = syncPairDao.AddPair(pair)
+	if err != nil {
+		return err
+	}
+	for _, pairNode := range pairNodes {
+		err = syncPairDao.AddPairNode(pairNode.PairID, pairNode.NodeID, pairNode.TargetNodeID, "", pairNode.SyncConflictURI)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (admin *dbAdministrator) ListPairs() ([]syncdao.SyncPair, error) {
+	return admin.daos.SyncPairDao().ListPairs()
+}
+
+func (admin *dbAdministrator) DeletePair(pairID string) error {
+	return admin.daos.SyncPairDao().DeletePair(pairID)
+}
+
+func (admin *dbAdministrator) AddDataVersion(dataVersionName string) error {
+	err := syncapi.ValidateDataVersionName(dataVersionName)
+	if err != nil {
+		return err
+	}
+	return admin.daos.SyncPairDao().AddDataVersion(dataVersionName)
+}
+
+func (admin *dbAdministrator) ListDataVersions() ([]string, error) {
+	return admin.daos.SyncPairDao().ListDataVersions()
+}
+
+func (admin *dbAdministrator) AddEntity(dataVersionName string, entity syncdao.EntityPairItem) error {
+	err := syncapi.ValidateEntity(entity)
+	if err != nil {
+		return err
+	}
+	return admin.daos.SyncPairDao().AddEntity(dataVersionName, entity)
+}
+
+func (admin *dbAdministrator) AddField(dataVersionName string, entitySingularName string, field syncdao.SyncFieldDefinition) error {
+	err := syncapi.ValidateField(field)
+	if err != nil {
+		return err
+	}
+	return admin.daos.SyncPairDao().AddField(dataVersionName, entitySingularName, field)
+}
+
+//ValidateSyncModel gives why the entities of the data version cannot be synced, none when they can.
+func (admin *dbAdministrator) ValidateSyncModel(dataVersionName string) ([]string, error) {
+	entities, err := admin.daos.SyncPairDao().ListEntities(dataVersionName)
+	if err != nil {
+		return nil, err
+	}
+	return syncapi.SyncModelProblems(entities), nil
+}
+
+//httpAdministrator administers the sync cluster through a running agent.
+type httpAdministrator struct {
+	client *syncclient.Client
+}
+
+func newHTTPAdministrator(agentURL string) httpAdministrator {
+	return httpAdministrator{client: syncclient.NewClient(agentURL)}
+}
+
+func (admin httpAdministrator) CreateNode(node syncdao.SyncNode) error {
+	_, err := admin.client.CreateNode(node)
+	return err
+}
+
+func (admin httpAdministrator) ListNodes() ([]syncdao.SyncNode, error) {
+	answer, err := admin.client.ListNodes()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []syncdao.SyncNode{}
+	for _, node := range answer.Nodes {
+		nodes = append(nodes, syncdao.SyncNode{
+			NodeID:          node.NodeID,
+			NodeName:        node.NodeName,
+			DataVersionName: node.DataVersionName,
+		})
+	}
+	return nodes, nil
+}
+
+func (admin httpAdministrator) DeleteNode(nodeID string) error {
+	_, err := admin.client.DeleteNodeByNodeID(nodeID)
+	return err
+}
+
+func (admin httpAdministrator) PendingChanges(nodeID string) (syncapi.NodePendingChanges, error) {
+	return admin.client.GetPendingChanges(nodeID)
+}
+
+func (admin httpAdministrator) PairState(pairID string) (synchandler.QueryPairStateResponse, error) {
+	return admin.client.GetPairState(pairID)
+}
+
+func (admin httpAdministrator) CancelSession(sessionID string, reason string) (synchandler.CancelSyncSessionResponse, error) {
+	return admin.client.CancelSyncSession(sessionID, reason)
+}
+
+//AddPair adds the pair of the nodes named node1Name and node2Name, along with the configuration of each node targeting
+//the other.
+func (admin httpAdministrator) AddPair(pair syncdao.SyncPair, node1Name string, node2Name string) error {
+	node1, err := admin.client.GetNodeByNodeName(node1Name)
+	if err != nil {
+		return fmt.Errorf("node '%s': %v", node1Name, err)
+	}
+	node2, err := admin.client.GetNodeByNodeName(node2Name)
+	if err != nil {
+		return fmt.Errorf("node '%s': %v", node2Name, err)
+	}
+	_, err = admin.client.CreatePair(synchandler.PairItem{PairID: pair.PairID, PairName: pair.PairName,
+		SyncConflictURI: pair.SyncConflictURI})
+	if err != nil {
+		return err
+	}
+	_, err = admin.client.AddPairNode(syncdao.PairNode{PairID: pair.PairID, NodeID: node1.NodeID, TargetNodeID: node2.NodeID,
+		SyncConflictURI: pair.SyncConflictURI})
+	if err != nil {
+		return err
+	}
+	_, err = admin.client.AddPairNode(syncdao.PairNode{PairID: pair.PairID, NodeID: node2.NodeID, TargetNodeID: node1.NodeID,
+		SyncConflictURI: pair.SyncConflictURI})
+	return err
+}
+
+func (admin httpAdministrator) ListPairs() ([]syncdao.SyncPair, error) {
+	answer, err := admin.client.ListPairs()
+	if err != nil {
+		return nil, err
+	}
+	pairs := []syncdao.SyncPair{}
+	for _, pair := range answer.Pairs {
+		pairs = append(pairs, syncdao.SyncPair{
+			PairID:            pair.PairID,
+			PairName:          pair.PairName,
+			MaxSesDurValue:    pair.MaxSesDurValue,
+			MaxSesDurUnit:     pair.MaxSesDurUnit,
+			SyncDataTransForm: pair.SyncDataTransForm,
+			SyncMsgTransForm:  pair.SyncMsgTransForm,
+			SyncMsgSecPol:     pair.SyncMsgSecPol,
+			SyncConflictURI:   pair.SyncConflictURI,
+			SyncSessionID:     pair.SyncSessionID,
+			SyncSessionState:  pair.SyncSessionState,
+		})
+	}
+	return pairs, nil
+}
+
+func (admin httpAdministrator) DeletePair(pairID string) error {
+	_, err := admin.client.DeletePair(pairID)
+	return err
+}
+
+func (admin httpAdministrator) AddDataVersion(dataVersionName string) error {
+	_, err := admin.client.CreateDataVersion(dataVersionName)
+	return err
+}
+
+func (admin httpAdministrator) ListDataVersions() ([]string, error) {
+	answer, err := admin.client.ListDataVersions()
+	if err != nil {
+		return nil, err
+	}
+	dataVersionNames := []string{}
+	for _, dataVersion := range answer.DataVersions {
+		dataVersionNames = append(dataVersionNames, dataVersion.DataVersionName)
+	}
+	return dataVersionNames, nil
+}
+
+func (admin httpAdministrator) AddEntity(dataVersionName string, entity syncdao.EntityPairItem) error {
+	_, err := admin.client.AddEntity(dataVersionName, entity)
+	return err
+}
+
+func (admin httpAdministrator) AddField(dataVersionName string, entitySingularName string, field syncdao.SyncFieldDefinition) error {
+	_, err := admin.client.AddField(dataVersionName, entitySingularName, synchandler.FieldItem{
+		FieldName:    field.FieldName,
+		DataTypeName: syncdao.SyncFieldTypeEnumName[int32(field.FieldType)],
+		IsPrimaryKey: field.IsPrimaryKey,
+	})
+	return err
+}
+
+func (admin httpAdministrator) ValidateSyncModel(dataVersionName string) ([]string, error) {
+	answer, err := admin.client.ValidateSyncModel(dataVersionName)
+	if err != nil {
+		return nil, err
+	}
+	return answer.Problems, nil
+}
@@ -0,0 +1,263 @@
+//Data Sync Admin: administers the nodes, pairs, sync model and sessions of a sync cluster
+package main
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+var agentURL = flag.String("agent", "", "The http address of a running agent to administer, e.g. 'http://localhost:8080'. Empty administers the database directly.")
+var dbType = flag.String("dbty", "postgressql", "The database to administer: 'postgressql', 'sqlite' or 'bolt'.")
+var dbUser = flag.String("dbusr", "doug", "The database user.")
+var dbPass = flag.String("dbpw", "", "The database password.")
+var dbServer = flag.String("dbsv", "localhost", "The database server.")
+var dbName = flag.String("dbnm", "threads", "The database name. For 'sqlite' and 'bolt', the path of the database file.")
+var dbPort = flag.Int("dbpt", 0, "The database port.")
+
+const commandsUsage = `Commands:
+  node create <nodeId> <nodeName> <dataVersionName>
+  node list
+  node delete <nodeId>
+  node pending <nodeId>
+  dataversion add <dataVersionName>
+  dataversion list
+  dataversion validate <dataVersionName>
+  entity add <dataVersionName> <singularName> <pluralName> <processOrderAddUpdate> <processOrderDelete> [entityHandlerUri]
+  field add <dataVersionName> <entitySingularName> <fieldName> <String|Int|Float|Bool|Date|Binary> [primarykey]
+  pair add <pairId> <pairName> <node1Name> <node2Name> [syncConflictUri]
+  pair list
+  pair delete <pairId>
+  session show <pairId>
+  session close <sessionId> [reason]
+`
+
+func main() {
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <command> <action> [args]\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprint(os.Stderr, commandsUsage)
+	}
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var admin administrator
+	if *agentURL != "" {
+		admin = newHTTPAdministrator(*agentURL)
+	} else {
+		dbAdmin, err := openDbAdministrator(*dbType, *dbUser, *dbPass, *dbServer, *dbName, *dbPort)
+		if err != nil {
+			log.Fatal("Cannot open the '", *dbType, "' database '", *dbName, "'. Error: ", err)
+			return
+		}
+		defer dbAdmin.daos.Close()
+		admin = dbAdmin
+	}
+	err := run(admin, args[0], args[1], args[2:])
+	if err != nil {
+		log.Fatalf("%s %s failed: %v", args[0], args[1], err)
+	}
+}
+
+//run performs the action of command with args.
+func run(admin administrator, command string, action string, args []string) error {
+	switch command + " " + action {
+	case "node create":
+		if err := expectArgs(args, 3, 3); err != nil {
+			return err
+		}
+		err := admin.CreateNode(syncdao.SyncNode{NodeID: args[0], NodeName: args[1], DataVersionName: args[2]})
+		if err == nil {
+			fmt.Printf("Created node '%s' (%s)\n", args[1], args[0])
+		}
+		return err
+	case "node list":
+		nodes, err := admin.ListNodes()
+		if err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			fmt.Printf("%s\t%s\t%s\n", node.NodeName, node.NodeID, node.DataVersionName)
+		}
+		return nil
+	case "node delete":
+		if err := expectArgs(args, 1, 1); err != nil {
+			return err
+		}
+		err := admin.DeleteNode(args[0])
+		if err == nil {
+			fmt.Printf("Deleted node %s\n", args[0])
+		}
+		return err
+	case "node pending":
+		if err := expectArgs(args, 1, 1); err != nil {
+			return err
+		}
+		pending, err := admin.PendingChanges(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d changes pending for node %s\n", pending.Total, args[0])
+		for _, count := range pending.Entities {
+			fmt.Printf("  %s: %d added or updated, %d deleted, %d in flight\n", count.EntityPluralName, count.AddedOrUpdated,
+				count.Deleted, count.InFlight)
+		}
+		return nil
+	case "pair add":
+		if err := expectArgs(args, 4, 5); err != nil {
+			return err
+		}
+		pair := syncdao.SyncPair{PairID: args[0], PairName: args[1], SyncConflictURI: "none"}
+		if len(args) > 4 {
+			pair.SyncConflictURI = args[4]
+		}
+		err := admin.AddPair(pair, args[2], args[3])
+		if err == nil {
+			fmt.Printf("Added pair '%s' (%s) of nodes %s and %s\n", args[1], args[0], args[2], args[3])
+		}
+		return err
+	case "pair list":
+		pairs, err := admin.ListPairs()
+		if err != nil {
+			return err
+		}
+		for _, pair := range pairs {
+			fmt.Printf("%s\t%s\t%d %s\t%s\n", pair.PairName, pair.PairID, pair.MaxSesDurValue, pair.MaxSesDurUnit,
+				pair.SyncSessionState)
+		}
+		return nil
+	case "pair delete":
+		if err := expectArgs(args, 1, 1); err != nil {
+			return err
+		}
+		err := admin.DeletePair(args[0])
+		if err == nil {
+			fmt.Printf("Deleted pair %s\n", args[0])
+		}
+		return err
+	case "session show":
+		if err := expectArgs(args, 1, 1); err != nil {
+			return err
+		}
+		state, err := admin.PairState(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Pair %s is '%s'", state.PairID, state.State)
+		if state.SessionID != "" {
+			fmt.Printf(" in session %s, %d%% complete", state.SessionID, state.PercentComplete)
+		}
+		fmt.Println()
+		for _, progress := range state.Progress {
+			fmt.Printf("  %s -> %s: %d of %d records, %d of %d bytes\n", progress.NodeID, progress.TargetNodeID,
+				progress.ProceSessRecCount, progress.TotalSessRecCount, progress.ProceSessRecBytes, progress.TotalSessRecBytes)
+		}
+		return nil
+	case "session close":
+		if err := expectArgs(args, 1, 2); err != nil {
+			return err
+		}
+		reason := "Closed by datasync"
+		if len(args) > 1 {
+			reason = args[1]
+		}
+		answer, err := admin.CancelSession(args[0], reason)
+		if err != nil {
+			return err
+		}
+		if answer.Result != "OK" {
+			return fmt.Errorf("session %s: %s", args[0], answer.Result)
+		}
+		fmt.Printf("Closed session %s of pair %s in state '%s': %d records released, %d received records discarded\n",
+			args[0], answer.PairID, answer.State, answer.ReleasedCount, answer.DiscardedCount)
+		return nil
+	case "dataversion add":
+		if err := expectArgs(args, 1, 1); err != nil {
+			return err
+		}
+		err := admin.AddDataVersion(args[0])
+		if err == nil {
+			fmt.Printf("Added data version '%s'\n", args[0])
+		}
+		return err
+	case "dataversion list":
+		dataVersionNames, err := admin.ListDataVersions()
+		if err != nil {
+			return err
+		}
+		for _, dataVersionName := range dataVersionNames {
+			fmt.Println(dataVersionName)
+		}
+		return nil
+	case "dataversion validate":
+		if err := expectArgs(args, 1, 1); err != nil {
+			return err
+		}
+		problems, err := admin.ValidateSyncModel(args[0])
+		if err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			fmt.Printf("Data version '%s' is valid\n", args[0])
+			return nil
+		}
+		for _, problem := range problems {
+			fmt.Printf("  %s\n", problem)
+		}
+		return fmt.Errorf("data version '%s' has %d problems", args[0], len(problems))
+	case "entity add":
+		if err := expectArgs(args, 5, 6); err != nil {
+			return err
+		}
+		entity := syncdao.EntityPairItem{
+			EntitySingularName: args[1],
+			EntityPluralName:   args[2],
+			EntityHandlerURI:   "none",
+		}
+		var err error
+		entity.ProcessOrderAddUpdate, err = strconv.Atoi(args[3])
+		if err != nil {
+			return fmt.Errorf("invalid processOrderAddUpdate '%s'", args[3])
+		}
+		entity.ProcessOrderDelete, err = strconv.Atoi(args[4])
+		if err != nil {
+			return fmt.Errorf("invalid processOrderDelete '%s'", args[4])
+		}
+		if len(args) > 5 {
+			entity.EntityHandlerURI = args[5]
+		}
+		return admin.AddEntity(args[0], entity)
+	case "field add":
+		if err := expectArgs(args, 4, 5); err != nil {
+			return err
+		}
+		fieldType, err := syncapi.ParseFieldType(args[3])
+		if err != nil {
+			return err
+		}
+		field := syncdao.SyncFieldDefinition{
+			FieldName:    args[2],
+			FieldType:    fieldType,
+			IsPrimaryKey: len(args) > 4 && args[4] == "primarykey",
+		}
+		return admin.AddField(args[0], args[1], field)
+	default:
+		return fmt.Errorf("unknown command '%s %s'", command, action)
+	}
+}
+
+func expectArgs(args []string, min int, max int) error {
+	if len(args) < min || len(args) > max {
+		return fmt.Errorf("expected %d to %d arguments, got %d", min, max, len(args))
+	}
+	return nil
+}
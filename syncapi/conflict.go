@@ -0,0 +1,48 @@
+package syncapi
+
+import (
+	"data-sync-tools-go/syncmsg"
+	"errors"
+	"time"
+)
+
+//ConflictResolutionChoice is how a recorded conflict is resolved manually.
+type ConflictResolutionChoice string
+
+const (
+	//ConflictResolutionChoiceLocal keeps the local version of the record and sends it to the peer.
+	ConflictResolutionChoiceLocal ConflictResolutionChoice = "Local"
+	//ConflictResolutionChoiceRemote keeps the peer's version of the record.
+	ConflictResolutionChoiceRemote ConflictResolutionChoice = "Remote"
+	//ConflictResolutionChoiceMerged keeps a supplied record and sends it to the peer.
+	ConflictResolutionChoiceMerged ConflictResolutionChoice = "Merged"
+)
+
+var (
+	//ErrConflictNotFound is given when no recorded conflict has the requested id.
+	ErrConflictNotFound = errors.New("conflict not found")
+	//ErrConflictStale is given when the local record changed since the conflict was recorded.
+	ErrConflictStale = errors.New("record changed since the conflict was recorded")
+	//ErrConflictMergedRecordMissing is given when a merged resolution does not supply the merged record.
+	ErrConflictMergedRecordMissing = errors.New("merged resolution requires a record")
+)
+
+//ConflictItem is a RecordConflict left for manual resolution. SyncState is the name of the syncmsg.AckSyncStateEnum
+//reported to the peer when the conflict was recorded.
+type ConflictItem struct {
+	ConflictID string `json:"conflictId"`
+	SyncState  string `json:"syncState"`
+	RecordConflict
+	Created time.Time `json:"created"`
+}
+
+//ConflictRepositoryable gives access to the conflicts a local node could not resolve automatically.
+type ConflictRepositoryable interface {
+	//FindConflicts retrieves the unresolved conflicts with the records received from the given node.
+	FindConflicts(nodeID string) ([]ConflictItem, error)
+	//GetConflict retrieves one unresolved conflict, giving ErrConflictNotFound if there is none with conflictID.
+	GetConflict(conflictID string) (ConflictItem, error)
+	//ResolveConflict persists the chosen version of the record, clears the conflict and gives the resulting record
+	//hash. mergedRecord is only used with ConflictResolutionChoiceMerged.
+	ResolveConflict(conflictID string, choice ConflictResolutionChoice, mergedRecord *syncmsg.ProtoRecord) (string, error)
+}
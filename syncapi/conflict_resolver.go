@@ -0,0 +1,177 @@
+package syncapi
+
+import (
+	"bytes"
+	"data-sync-tools-go/syncmsg"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+//ConflictResolverURINone is the SyncConflictURI value for pairs that do not auto resolve conflicts.
+const ConflictResolverURINone = "none"
+
+//ConflictRecordVersion is one side's version of a conflicting record.
+type ConflictRecordVersion struct {
+	RecordHash string               `json:"recordHash"`
+	IsDelete   bool                 `json:"isDelete"`
+	Record     *syncmsg.ProtoRecord `json:"record"`
+}
+
+//RecordConflict describes a record changed by both the local node and its peer since they last synced. Ancestor is
+//the version both last agreed on, or nil when it is no longer available.
+type RecordConflict struct {
+	SessionID          string                `json:"sessionId"`
+	RemoteNodeID       string                `json:"remoteNodeId"`
+	EntitySingularName string                `json:"entitySingularName"`
+	EntityPluralName   string                `json:"entityPluralName"`
+	RecordID           string                `json:"recordId"`
+	ConflictingFields  []string              `json:"conflictingFields"`
+	Ancestor           *syncmsg.ProtoRecord  `json:"ancestor"`
+	Local              ConflictRecordVersion `json:"local"`
+	Remote             ConflictRecordVersion `json:"remote"`
+}
+
+//IsDeleteAndUpdate tells if one side deleted the record while the other side updated it.
+func (conflict RecordConflict) IsDeleteAndUpdate() bool {
+	return conflict.Local.IsDelete != conflict.Remote.IsDelete
+}
+
+//ConflictResolution is the outcome of resolving a RecordConflict. When Resolved is false the conflict is left for
+//manual resolution. When IsDelete is true the record is to be deleted, otherwise Record holds the record to keep.
+type ConflictResolution struct {
+	Resolved bool                 `json:"resolved"`
+	IsDelete bool                 `json:"isDelete"`
+	Record   *syncmsg.ProtoRecord `json:"record"`
+}
+
+//ResolveWithVersion creates a ConflictResolution keeping the given version of the record.
+func ResolveWithVersion(version ConflictRecordVersion) ConflictResolution {
+	return ConflictResolution{
+		Resolved: true,
+		IsDelete: version.IsDelete,
+		Record:   version.Record,
+	}
+}
+
+//ConflictResolver decides the outcome of a record changed on both sides of a sync pair.
+type ConflictResolver interface {
+	Resolve(conflict RecordConflict) (ConflictResolution, error)
+}
+
+//ConflictResolverFactory creates a ConflictResolver from the SyncConflictURI configured on a sync pair.
+type ConflictResolverFactory func(conflictURI *url.URL) (ConflictResolver, error)
+
+var (
+	conflictResolverFactoriesMutex sync.RWMutex
+	conflictResolverFactories      = map[string]ConflictResolverFactory{
+		"builtin": newBuiltinConflictResolver,
+		"http":    newHTTPConflictResolver,
+		"https":   newHTTPConflictResolver,
+	}
+)
+
+//RegisterConflictResolver makes a ConflictResolverFactory available for SyncConflictURIs with the given scheme,
+//replacing any factory already registered for it.
+func RegisterConflictResolver(scheme string, factory ConflictResolverFactory) {
+	conflictResolverFactoriesMutex.Lock()
+	defer conflictResolverFactoriesMutex.Unlock()
+	conflictResolverFactories[strings.ToLower(scheme)] = factory
+}
+
+//NewConflictResolver creates the ConflictResolver registered for the scheme of conflictURI. A nil ConflictResolver
+//with no error is given when conflictURI is empty or ConflictResolverURINone.
+func NewConflictResolver(conflictURI string) (ConflictResolver, error) {
+	if conflictURI == "" || conflictURI == ConflictResolverURINone {
+		return nil, nil
+	}
+	parsedURI, err := url.Parse(conflictURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid conflict resolver uri '%s': %s", conflictURI, err.Error())
+	}
+	conflictResolverFactoriesMutex.RLock()
+	factory, found := conflictResolverFactories[strings.ToLower(parsedURI.Scheme)]
+	conflictResolverFactoriesMutex.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("no conflict resolver registered for scheme '%s' of uri '%s'", parsedURI.Scheme, conflictURI)
+	}
+	return factory(parsedURI)
+}
+
+//newBuiltinConflictResolver supports 'builtin:remoteWins' and 'builtin:preferNode/{nodeId}'.
+func newBuiltinConflictResolver(conflictURI *url.URL) (ConflictResolver, error) {
+	name := conflictURI.Opaque
+	switch {
+	case name == "remoteWins":
+		return remoteWinsConflictResolver{}, nil
+	case name == "lastWriterWins":
+		return nil, errors.New("builtin:lastWriterWins is not supported as sync messages do not carry a modification " +
+			"time, use builtin:remoteWins to keep the version received")
+	case strings.HasPrefix(name, "preferNode/"):
+		nodeID := strings.TrimPrefix(name, "preferNode/")
+		if nodeID == "" {
+			return nil, errors.New("builtin:preferNode requires a node id, as in builtin:preferNode/{nodeId}")
+		}
+		return preferNodeConflictResolver{nodeID: nodeID}, nil
+	default:
+		return nil, fmt.Errorf("unknown builtin conflict resolver '%s'", conflictURI.String())
+	}
+}
+
+//remoteWinsConflictResolver keeps the remote version of the record, whichever side changed it last.
+type remoteWinsConflictResolver struct{}
+
+func (resolver remoteWinsConflictResolver) Resolve(conflict RecordConflict) (ConflictResolution, error) {
+	return ResolveWithVersion(conflict.Remote), nil
+}
+
+//preferNodeConflictResolver keeps the version of the record from the configured node.
+type preferNodeConflictResolver struct {
+	nodeID string
+}
+
+func (resolver preferNodeConflictResolver) Resolve(conflict RecordConflict) (ConflictResolution, error) {
+	if conflict.RemoteNodeID == resolver.nodeID {
+		return ResolveWithVersion(conflict.Remote), nil
+	}
+	return ResolveWithVersion(conflict.Local), nil
+}
+
+//httpConflictResolver posts the RecordConflict as JSON to a remote service answering with a ConflictResolution.
+type httpConflictResolver struct {
+	resolverURL string
+	client      *http.Client
+}
+
+func newHTTPConflictResolver(conflictURI *url.URL) (ConflictResolver, error) {
+	return httpConflictResolver{
+		resolverURL: conflictURI.String(),
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (resolver httpConflictResolver) Resolve(conflict RecordConflict) (ConflictResolution, error) {
+	var answer ConflictResolution
+	requestBytes, err := json.Marshal(conflict)
+	if err != nil {
+		return answer, err
+	}
+	response, err := resolver.client.Post(resolver.resolverURL, "application/json; charset=UTF-8", bytes.NewReader(requestBytes))
+	if err != nil {
+		return answer, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return answer, fmt.Errorf("conflict resolver at '%s' answered with status %v", resolver.resolverURL, response.StatusCode)
+	}
+	err = json.NewDecoder(response.Body).Decode(&answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, nil
+}
@@ -0,0 +1,131 @@
+package syncapi
+
+import (
+	"data-sync-tools-go/syncmsg"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestRecordConflict() RecordConflict {
+	creator := syncmsg.NewCreator()
+	return RecordConflict{
+		SessionID:          "session-1",
+		RemoteNodeID:       "*node-spoke1",
+		EntitySingularName: "Contact",
+		EntityPluralName:   "Contacts",
+		RecordID:           "record 01",
+		ConflictingFields:  []string{"lastName"},
+		Local: ConflictRecordVersion{
+			RecordHash: "local-hash",
+			Record: &syncmsg.ProtoRecord{
+				Fields: []*syncmsg.ProtoField{creator.CreateStringProtoField("lastName", "Smith")},
+			},
+		},
+		Remote: ConflictRecordVersion{
+			RecordHash: "remote-hash",
+			Record: &syncmsg.ProtoRecord{
+				Fields: []*syncmsg.ProtoField{creator.CreateStringProtoField("lastName", "Smyth")},
+			},
+		},
+	}
+}
+
+func TestConflictResolver_None(t *testing.T) {
+	for _, conflictURI := range []string{"", ConflictResolverURINone} {
+		resolver, err := NewConflictResolver(conflictURI)
+		assert.Nil(t, err)
+		assert.Nil(t, resolver)
+	}
+}
+
+func TestConflictResolver_UnknownScheme(t *testing.T) {
+	_, err := NewConflictResolver("unknown:resolver")
+	assert.NotNil(t, err)
+	_, err = NewConflictResolver("builtin:unknownResolver")
+	assert.NotNil(t, err)
+	_, err = NewConflictResolver("builtin:preferNode/")
+	assert.NotNil(t, err)
+}
+
+func TestConflictResolver_RemoteWins(t *testing.T) {
+	conflict := createTestRecordConflict()
+	resolver, err := NewConflictResolver("builtin:remoteWins")
+	assert.Nil(t, err)
+	resolution, err := resolver.Resolve(conflict)
+	assert.Nil(t, err)
+	assert.True(t, resolution.Resolved)
+	assert.False(t, resolution.IsDelete)
+	assert.Equal(t, conflict.Remote.Record, resolution.Record)
+
+	//The local version changed after the remote one still loses
+	creator := syncmsg.NewCreator()
+	remoteChanged := time.Date(2017, 3, 1, 10, 0, 0, 0, time.UTC)
+	conflict.Remote.Record.Fields = append(conflict.Remote.Record.Fields, creator.CreateTimeProtoField("modifiedOn", remoteChanged))
+	conflict.Local.Record.Fields = append(conflict.Local.Record.Fields, creator.CreateTimeProtoField("modifiedOn", remoteChanged.Add(time.Hour)))
+	resolution, err = resolver.Resolve(conflict)
+	assert.Nil(t, err)
+	assert.Equal(t, conflict.Remote.Record, resolution.Record)
+
+	_, err = NewConflictResolver("builtin:lastWriterWins")
+	assert.NotNil(t, err, "without modification times the last writer is unknown")
+}
+
+func TestConflictResolver_PreferNode(t *testing.T) {
+	conflict := createTestRecordConflict()
+	resolver, err := NewConflictResolver("builtin:preferNode/*node-spoke1")
+	assert.Nil(t, err)
+	resolution, err := resolver.Resolve(conflict)
+	assert.Nil(t, err)
+	assert.Equal(t, conflict.Remote.Record, resolution.Record)
+
+	resolver, err = NewConflictResolver("builtin:preferNode/*node-hub")
+	assert.Nil(t, err)
+	resolution, err = resolver.Resolve(conflict)
+	assert.Nil(t, err)
+	assert.Equal(t, conflict.Local.Record, resolution.Record)
+}
+
+func TestConflictResolver_HTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var conflict RecordConflict
+		err := json.NewDecoder(r.Body).Decode(&conflict)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(ResolveWithVersion(conflict.Local))
+	}))
+	defer server.Close()
+
+	conflict := createTestRecordConflict()
+	resolver, err := NewConflictResolver(server.URL + "/resolve")
+	assert.Nil(t, err)
+	resolution, err := resolver.Resolve(conflict)
+	assert.Nil(t, err)
+	assert.True(t, resolution.Resolved)
+	assert.Equal(t, "lastName", resolution.Record.Fields[0].GetFieldName())
+	assert.Equal(t, conflict.Local.Record.Fields[0].FieldValue, resolution.Record.Fields[0].FieldValue)
+}
+
+type testConflictResolver struct{}
+
+func (resolver testConflictResolver) Resolve(conflict RecordConflict) (ConflictResolution, error) {
+	return ConflictResolution{Resolved: false}, nil
+}
+
+func TestConflictResolver_Register(t *testing.T) {
+	RegisterConflictResolver("custom", func(conflictURI *url.URL) (ConflictResolver, error) {
+		return testConflictResolver{}, nil
+	})
+	resolver, err := NewConflictResolver("custom:manual")
+	assert.Nil(t, err)
+	resolution, err := resolver.Resolve(createTestRecordConflict())
+	assert.Nil(t, err)
+	assert.False(t, resolution.Resolved)
+}
@@ -3,8 +3,6 @@ package syncapi
 // TODO(doug4j@gmail.com): Add an abstraction to the Protocol Buffers intefaces in 'messages.pb.go' in the syncmsg package.
 import "data-sync-tools-go/syncmsg"
 
-// TODO(doug4j@gmail.com): Implement MessageAcknowledging for client implementation support.
-
 //MessageAcknowledgingData defines the typical re-usable data for MessageAcknowledging.
 type MessageAcknowledgingData struct {
 	//SessionId is the already active session for syncing.
@@ -13,14 +11,10 @@ type MessageAcknowledgingData struct {
 	NodeID string
 }
 
-//MessageAcknowledging provides services for retrieving a group of sync messages for downstream processes.
+//MessageAcknowledging provides services for applying the peer's report on a group of sync messages previously fetched
+//for it.
 type MessageAcknowledging interface {
-	//Fetch retrieves a group of sync messages for downstream processes returning a value with no request items
-	//in it should it be (Copied from SyncMessagesFetcher.Fetch() interface)
-	Acknowledge(msgs syncmsg.ProtoSyncEntityMessageResponse) error
-
-	//FindEntitiesToFetch retrieves entity names to be processed for a given sync session by orderNumber
-	//FindEntitiesToFetch(orderNum int, sessionID string, changeType ProcessSyncChangeEnum) ([]EntityNameItem, error)
-
-	//MarkProcessed(entities []string, sessionID string, changeType ProcessSyncChangeEnum) error
+	//Acknowledge applies the peer's response to the messages sent under the response's TransactionBindId and gives a
+	//report in the form of a response message whose Result tells whether the acknowledgement was persisted.
+	Acknowledge(response *syncmsg.ProtoSyncEntityMessageResponse) *syncmsg.ProtoSyncEntityMessageResponse
 }
@@ -15,6 +15,27 @@ type MessageFetchingData struct {
 	MaxMsgs int
 }
 
+//DefaultMaxGroupBytesSize is the MaxGroupBytesSize used unless a FetchLimits overrides it, as nodes do not configure
+//a byte size.
+const DefaultMaxGroupBytesSize = 1024 * 1024
+
+//FetchLimits overrides the limits a MessageFetching takes from the configuration of its node. Zero values keep the
+//configured limits.
+type FetchLimits struct {
+	MaxGroupBytesSize int
+	MaxMsgs           int
+}
+
+//ApplyLimits overrides the limits of data with the non zero limits.
+func (data *MessageFetchingData) ApplyLimits(limits FetchLimits) {
+	if limits.MaxGroupBytesSize > 0 {
+		data.MaxGroupBytesSize = limits.MaxGroupBytesSize
+	}
+	if limits.MaxMsgs > 0 {
+		data.MaxMsgs = limits.MaxMsgs
+	}
+}
+
 //MessageFetching provides services for retrieving a group of sync messages for downstream processes.
 type MessageFetching interface {
 	//Fetch retrieves a group of sync messages for downstream processes returning a value with no request items
@@ -23,3 +23,15 @@ type MessageProcessing interface {
 
 	//MarkProcessed(entities []string, sessionID string, changeType ProcessSyncChangeEnum) error
 }
+
+//RequestRecordTotals gives how many records, and of how many bytes, a request carries. Processing the request adds
+//them to the progress of the session from the sending node.
+func RequestRecordTotals(request *syncmsg.ProtoSyncEntityMessageRequest) (count int, bytes int) {
+	for _, item := range request.Items {
+		for _, msg := range item.Msgs {
+			count++
+			bytes += int(msg.GetRecordBytesSize())
+		}
+	}
+	return count, bytes
+}
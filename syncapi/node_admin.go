@@ -1 +1,41 @@
 package syncapi
+
+import "errors"
+
+//ErrNodeNotFound is given when no node has the requested id.
+var ErrNodeNotFound = errors.New("node not found")
+
+//PendingChangeCount counts the records of one entity waiting to be sent to a node.
+type PendingChangeCount struct {
+	EntityPluralName string `json:"entityPluralName"`
+	//AddedOrUpdated is the number of records never sent to the node or changed since they were last sent.
+	AddedOrUpdated int `json:"addedOrUpdated"`
+	//Deleted is the number of records deleted since they were last sent to the node.
+	Deleted int `json:"deleted"`
+	//InFlight is the number of records fetched for the node by a session and not acknowledged yet.
+	InFlight int `json:"inFlight"`
+}
+
+//NodePendingChanges reports the changes waiting to be sent to a node. Total is the number of records added, updated
+//or deleted, Entities their counts by entity ordered by plural name.
+type NodePendingChanges struct {
+	NodeID   string               `json:"nodeId"`
+	Total    int                  `json:"total"`
+	Entities []PendingChangeCount `json:"entities"`
+}
+
+//Add counts the changes of an entity, leaving out entities without any. Entities are added by plural name.
+func (pending *NodePendingChanges) Add(count PendingChangeCount) {
+	if count.AddedOrUpdated == 0 && count.Deleted == 0 && count.InFlight == 0 {
+		return
+	}
+	pending.Total += count.AddedOrUpdated + count.Deleted
+	pending.Entities = append(pending.Entities, count)
+}
+
+//NodeAdminRepositoryable gives access to the sync state kept for every node for administering the nodes.
+type NodeAdminRepositoryable interface {
+	//PendingChanges counts, without queuing anything, the records the next session would queue for nodeID along with
+	//those fetched for it and not acknowledged yet. It gives ErrNodeNotFound when there is no node nodeID.
+	PendingChanges(nodeID string) (NodePendingChanges, error)
+}
@@ -1 +1,128 @@
 package syncapi
+
+import (
+	"data-sync-tools-go/syncdao"
+	"errors"
+	"fmt"
+)
+
+//ErrPairSessionActive is given when changing a pair, or the nodes of a pair, while a session of the pair is active.
+var ErrPairSessionActive = errors.New("pair has an active session")
+
+//ValidationError reports a change of the sync configuration refused for breaking one of its rules.
+type ValidationError struct {
+	Msg string
+}
+
+//Error gives Msg.
+func (err ValidationError) Error() string {
+	return err.Msg
+}
+
+func newValidationError(format string, args ...interface{}) ValidationError {
+	return ValidationError{Msg: fmt.Sprintf(format, args...)}
+}
+
+//The defaults of the sync_pair columns, taken by the fields of a pair left at their zero value.
+const (
+	DefaultMaxSesDurValue  = 10
+	DefaultMaxSesDurUnit   = "minutes"
+	DefaultTransForm       = "json:V1"
+	DefaultSyncMsgSecPol   = "none"
+	DefaultSyncConflictURI = "none"
+)
+
+//PairWithDefaults gives pair with the configuration fields left at their zero value set to the defaults of the sql
+//schema.
+func PairWithDefaults(pair syncdao.SyncPair) syncdao.SyncPair {
+	if pair.MaxSesDurValue == 0 {
+		pair.MaxSesDurValue = DefaultMaxSesDurValue
+	}
+	if pair.MaxSesDurUnit == "" {
+		pair.MaxSesDurUnit = DefaultMaxSesDurUnit
+	}
+	if pair.SyncDataTransForm == "" {
+		pair.SyncDataTransForm = DefaultTransForm
+	}
+	if pair.SyncMsgTransForm == "" {
+		pair.SyncMsgTransForm = DefaultTransForm
+	}
+	if pair.SyncMsgSecPol == "" {
+		pair.SyncMsgSecPol = DefaultSyncMsgSecPol
+	}
+	if pair.SyncConflictURI == "" {
+		pair.SyncConflictURI = DefaultSyncConflictURI
+	}
+	return pair
+}
+
+//ValidatePair checks the configuration of a pair against the columns of sync_pair. Fields left at their zero value
+//are valid as they take the defaults of PairWithDefaults. The transforms are 'json:V1' and the message security
+//policy 'none', the only ones the agent implements, and the conflict uri has to name a registered ConflictResolver.
+func ValidatePair(pair syncdao.SyncPair) error {
+	if pair.PairID == "" || len(pair.PairID) > 36 {
+		return newValidationError("Pair id '%s' must have 1 to 36 characters", pair.PairID)
+	}
+	if pair.PairName == "" || len(pair.PairName) > 72 {
+		return newValidationError("Pair name '%s' must have 1 to 72 characters", pair.PairName)
+	}
+	if pair.MaxSesDurValue < 0 {
+		return newValidationError("Maximum session duration %d of pair '%s' is negative", pair.MaxSesDurValue, pair.PairID)
+	}
+	if _, isUnit := sessionDurationUnits[pair.MaxSesDurUnit]; pair.MaxSesDurUnit != "" && !isUnit {
+		return newValidationError("Unknown session duration unit '%s'. Expected 'seconds', 'minutes', 'hours' or 'days'",
+			pair.MaxSesDurUnit)
+	}
+	for _, transForm := range []string{pair.SyncDataTransForm, pair.SyncMsgTransForm} {
+		if transForm != "" && transForm != DefaultTransForm {
+			return newValidationError("Unknown transform '%s'. Expected '%s'", transForm, DefaultTransForm)
+		}
+	}
+	if pair.SyncMsgSecPol != "" && pair.SyncMsgSecPol != DefaultSyncMsgSecPol {
+		return newValidationError("Unknown message security policy '%s'. Expected '%s'", pair.SyncMsgSecPol,
+			DefaultSyncMsgSecPol)
+	}
+	if len(pair.SyncConflictURI) > 2048 {
+		return newValidationError("Conflict uri of pair '%s' is longer than 2048 characters", pair.PairID)
+	}
+	if _, err := NewConflictResolver(pair.SyncConflictURI); err != nil {
+		return newValidationError("Conflict uri of pair '%s' names no conflict resolver: %s", pair.PairID, err.Error())
+	}
+	return nil
+}
+
+//ValidatePairNode checks the configuration of a node of a pair against the columns of sync_pair_nodes. As for a pair,
+//the conflict uri has to name a registered ConflictResolver.
+func ValidatePairNode(node syncdao.PairNode) error {
+	if node.PairID == "" || node.NodeID == "" || node.TargetNodeID == "" {
+		return newValidationError("A node of a pair needs a pair id, a node id and a target node id")
+	}
+	if node.NodeID == node.TargetNodeID {
+		return newValidationError("Node '%s' of pair '%s' cannot target itself", node.NodeID, node.PairID)
+	}
+	if len(node.SyncConflictURI) > 2048 {
+		return newValidationError("Conflict uri of node '%s' of pair '%s' is longer than 2048 characters", node.NodeID,
+			node.PairID)
+	}
+	if _, err := NewConflictResolver(node.SyncConflictURI); err != nil {
+		return newValidationError("Conflict uri of node '%s' of pair '%s' names no conflict resolver: %s", node.NodeID,
+			node.PairID, err.Error())
+	}
+	return nil
+}
+
+//ValidatePairMembership checks that node can be added to a pair having the nodes existing. A pair syncs two nodes,
+//each targeting the other once, so every node of the pair syncs the same two nodes.
+func ValidatePairMembership(existing []syncdao.PairNode, node syncdao.PairNode) error {
+	for _, other := range existing {
+		if other.NodeID == node.NodeID && other.TargetNodeID == node.TargetNodeID {
+			return newValidationError("Node '%s' of pair '%s' already targets node '%s'", node.NodeID, node.PairID,
+				node.TargetNodeID)
+		}
+		if other.NodeID != node.TargetNodeID || other.TargetNodeID != node.NodeID {
+			return newValidationError("Pair '%s' syncs node '%s' with node '%s', it cannot sync node '%s' with node '%s'",
+				node.PairID, other.NodeID, other.TargetNodeID, node.NodeID, node.TargetNodeID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package syncapi
+
+import (
+	"data-sync-tools-go/syncdao"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePair(t *testing.T) {
+	assert.Nil(t, ValidatePair(syncdao.SyncPair{PairID: "*pair-1", PairName: "A <-> Z"}))
+	assert.Nil(t, ValidatePair(syncdao.SyncPair{PairID: "*pair-1", PairName: "A <-> Z", MaxSesDurValue: 30, MaxSesDurUnit: "seconds",
+		SyncDataTransForm: "json:V1", SyncMsgTransForm: "json:V1", SyncMsgSecPol: "none", SyncConflictURI: "http://localhost:8080/conflict"}))
+	for _, pair := range []syncdao.SyncPair{
+		{PairName: "A <-> Z"},
+		{PairID: strings.Repeat("x", 37), PairName: "A <-> Z"},
+		{PairID: "*pair-1"},
+		{PairID: "*pair-1", PairName: strings.Repeat("x", 73)},
+		{PairID: "*pair-1", PairName: "A <-> Z", MaxSesDurValue: -1},
+		{PairID: "*pair-1", PairName: "A <-> Z", MaxSesDurUnit: "weeks"},
+		{PairID: "*pair-1", PairName: "A <-> Z", SyncDataTransForm: "xml:V1"},
+		{PairID: "*pair-1", PairName: "A <-> Z", SyncMsgTransForm: "json:V2"},
+		{PairID: "*pair-1", PairName: "A <-> Z", SyncMsgSecPol: "tls"},
+		{PairID: "*pair-1", PairName: "A <-> Z", SyncConflictURI: strings.Repeat("x", 2049)},
+		{PairID: "*pair-1", PairName: "A <-> Z", SyncConflictURI: "builtin:lastWriterWins"},
+		{PairID: "*pair-1", PairName: "A <-> Z", SyncConflictURI: "ftp://localhost/conflict"},
+	} {
+		assert.IsType(t, ValidationError{}, ValidatePair(pair), pair)
+	}
+}
+
+func TestPairWithDefaults(t *testing.T) {
+	pair := PairWithDefaults(syncdao.SyncPair{PairID: "*pair-1", PairName: "A <-> Z", MaxSesDurUnit: "hours"})
+	assert.Equal(t, syncdao.SyncPair{PairID: "*pair-1", PairName: "A <-> Z", MaxSesDurValue: 10, MaxSesDurUnit: "hours",
+		SyncDataTransForm: "json:V1", SyncMsgTransForm: "json:V1", SyncMsgSecPol: "none", SyncConflictURI: "none"}, pair)
+}
+
+func TestValidatePairMembership(t *testing.T) {
+	hubToSpoke := syncdao.PairNode{PairID: "*pair-1", NodeID: "*node-hub", TargetNodeID: "*node-spoke1"}
+	spokeToHub := syncdao.PairNode{PairID: "*pair-1", NodeID: "*node-spoke1", TargetNodeID: "*node-hub"}
+	assert.Nil(t, ValidatePairNode(hubToSpoke))
+	assert.IsType(t, ValidationError{}, ValidatePairNode(syncdao.PairNode{PairID: "*pair-1", NodeID: "*node-hub", TargetNodeID: "*node-hub"}))
+	assert.IsType(t, ValidationError{}, ValidatePairNode(syncdao.PairNode{PairID: "*pair-1", NodeID: "*node-hub"}))
+	assert.IsType(t, ValidationError{}, ValidatePairNode(syncdao.PairNode{PairID: "*pair-1", NodeID: "*node-hub", TargetNodeID: "*node-spoke1",
+		SyncConflictURI: "builtin:lastWriterWins"}))
+
+	assert.Nil(t, ValidatePairMembership([]syncdao.PairNode{}, hubToSpoke))
+	assert.Nil(t, ValidatePairMembership([]syncdao.PairNode{hubToSpoke}, spokeToHub))
+	assert.IsType(t, ValidationError{}, ValidatePairMembership([]syncdao.PairNode{hubToSpoke}, hubToSpoke), "a node targets its peer once")
+	assert.IsType(t, ValidationError{}, ValidatePairMembership([]syncdao.PairNode{hubToSpoke, spokeToHub},
+		syncdao.PairNode{PairID: "*pair-1", NodeID: "*node-spoke2", TargetNodeID: "*node-hub"}), "a pair syncs two nodes")
+}
@@ -2,20 +2,20 @@ package syncapi
 
 //Repository defines a repository for dependency injection for sync servicing.
 type Repository struct {
-	DataRepo   DataRepositoryable
-	ConfigRepo ConfigRepositoryable
-	//SessionRepo SessionRepository
-	//ConflictRepo ConflictRepository
+	DataRepo      DataRepositoryable
+	ConfigRepo    ConfigRepositoryable
+	ConflictRepo  ConflictRepositoryable
+	TombstoneRepo TombstoneRepositoryable
+	SessionRepo   SessionRepositoryable
+	NodeAdminRepo NodeAdminRepositoryable
 }
 
 //DataRepositoryable acts as a factory to access a store for servicing local sync data.
 type DataRepositoryable interface {
-	CreateMessageFetcher(sessionID string, nodeID string) (MessageFetching, error)
+	CreateMessageFetcher(sessionID string, nodeID string, limits FetchLimits) (MessageFetching, error)
 	CreateMessageProcessor(sessionID string, nodeID string, entitiesByPluralName map[string]EntityNameItem) (MessageProcessing, error)
 	CreateMessageQueuer(sessionID string, nodeID string) (MessageQueuing, error)
-	// TODO(doug4j@gmail.com): Implement CreateMessageAcknowledger for client implementation support.
-
-	//CreateMessageAcknowledger(sessionID string, nodeID string) (MessageAcknowledging, error)
+	CreateMessageAcknowledger(sessionID string, nodeID string) (MessageAcknowledging, error)
 
 	/*
 		QueueChanges(sessionID string, nodeIDToQueue string) (int, error)
@@ -47,10 +47,6 @@ type ConfigRepositoryable interface {
 	// GetPairByNames(requestingNodeName string, toPairWithNodeName string) (SyncPair, error)
 	// GetNodePairItem(pairID string, nodeName string) (NodePairItem, error)
 	// GetEntityPairItem(pairID string, nodeName string) ([]EntityPairItem, error)
-
-	// BUG(doug4j@gmail.com): Add UpdateSyncingWithTotals(request UpdateSyncingRequestWithTotals) (UpdateSyncingResult, error)
-	// BUG(doug4j@gmail.com): Add UpdateSyncingWithProcessed(request UpdateSyncingRequestWithProcessed) (UpdateSyncingResult, error)
-
 }
 
 /*
@@ -179,34 +175,3 @@ type QuerySessionConfigResult struct {
 }
 
 */
-
-// TODO(doug4j@gmail.com): Add type UpdateSyncingResult struct
-/*
-type UpdateSyncingResult struct {
-	Result    string
-	ResultMsg string
-}
-*/
-
-// BUG(doug4j@gmail.com): Create UpdateSyncingRequestWithTotals
-/*
-type UpdateSyncingRequestWithTotals struct {
-	State        string `json:"state"`
-	PairId       string `json:"pairId"`
-	SessionId    string `json:"sessionId"`
-	RecordBytes1 string `json:"recordBytes1"`
-	RecordCount1 string `json:"recordCount1"`
-	RecordBytes2 string `json:"recordBytes2"`
-	RecordCount2 string `json:"recordCount2"`
-}
-
-type UpdateSyncingRequestWithProcessed struct {
-	State        string `json:"state"`
-	PairId       string `json:"pairId"`
-	SessionId    string `json:"sessionId"`
-	RecordBytes1 string `json:"recordBytes1"`
-	RecordCount1 string `json:"recordCount1"`
-	RecordBytes2 string `json:"recordBytes2"`
-	RecordCount2 string `json:"recordCount2"`
-}
-*/
@@ -0,0 +1,23 @@
+package syncapi
+
+//SessionEndReasonCanceled is the reason recorded for a sync session ended by CancelSession.
+const SessionEndReasonCanceled = "Canceled"
+
+//ValidateCancelReason checks the reason a session is canceled for against sync_session_history.EndReasonMsg.
+func ValidateCancelReason(reasonMsg string) error {
+	if len(reasonMsg) > 1024 {
+		return newValidationError("Reason of the cancel is longer than 1024 characters")
+	}
+	return nil
+}
+
+//SessionCancelResult represents the results from canceling a sync session.
+type SessionCancelResult struct {
+	//Valid values: 'OK' or SessionNotActiveResult
+	Result string `json:"result"`
+	//Session records the canceled session as EndedSessions lists it.
+	Session EndedSession `json:"session"`
+	//DiscardedCount is the number of sync_peer_state records received from the nodes of the pair whose
+	//TransactionBindReceiveId was discarded.
+	DiscardedCount int `json:"discardedCount"`
+}
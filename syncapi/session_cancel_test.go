@@ -0,0 +1,14 @@
+package syncapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionCancel_ValidateCancelReason(t *testing.T) {
+	assert.Nil(t, ValidateCancelReason(""))
+	assert.Nil(t, ValidateCancelReason(strings.Repeat("x", 1024)))
+	assert.IsType(t, ValidationError{}, ValidateCancelReason(strings.Repeat("x", 1025)))
+}
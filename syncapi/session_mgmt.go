@@ -0,0 +1,99 @@
+package syncapi
+
+import "time"
+
+//SessionMgmtMsgTypeEnum defines the types of management messages the nodes of a sync session send each other.
+type SessionMgmtMsgTypeEnum int
+
+const (
+	//SessionMgmtMsgTypeHeartbeat tells the sender is alive, renewing the lease of the session.
+	SessionMgmtMsgTypeHeartbeat SessionMgmtMsgTypeEnum = 1
+	//SessionMgmtMsgTypeSeedingClosed tells the sender is done seeding.
+	SessionMgmtMsgTypeSeedingClosed SessionMgmtMsgTypeEnum = 2
+	//SessionMgmtMsgTypeCancel asks for the session to be abandoned.
+	SessionMgmtMsgTypeCancel SessionMgmtMsgTypeEnum = 3
+	//SessionMgmtMsgTypeHaultingError tells the sender cannot go on with the session.
+	SessionMgmtMsgTypeHaultingError SessionMgmtMsgTypeEnum = 4
+	//SessionMgmtMsgTypeStart tells the sender starts syncing.
+	SessionMgmtMsgTypeStart SessionMgmtMsgTypeEnum = 5
+)
+
+//SessionMgmtMsgTypeEnumName gives the string name of SessionMgmtMsgTypeEnum.
+var SessionMgmtMsgTypeEnumName = map[int]string{
+	1: "Heartbeat",
+	2: "SeedingClosed",
+	3: "Cancel",
+	4: "HaultingError",
+	5: "Start",
+}
+
+//SessionMgmtMsgTypeEnumValue gives the int value of SessionMgmtMsgTypeEnum.
+var SessionMgmtMsgTypeEnumValue = map[string]int{
+	"Heartbeat":     1,
+	"SeedingClosed": 2,
+	"Cancel":        3,
+	"HaultingError": 4,
+	"Start":         5,
+}
+
+//Enum gives the SessionMgmtMsgTypeEnum given one of the constants from SessionMgmtMsgType*.
+func (x SessionMgmtMsgTypeEnum) Enum() *SessionMgmtMsgTypeEnum {
+	p := new(SessionMgmtMsgTypeEnum)
+	*p = x
+	return p
+}
+
+//IsValid answers whether x is one of the constants from SessionMgmtMsgType*.
+func (x SessionMgmtMsgTypeEnum) IsValid() bool {
+	_, ok := SessionMgmtMsgTypeEnumName[int(x)]
+	return ok
+}
+
+//IsCanceling answers whether a message of type x moves its session into Canceling.
+func (x SessionMgmtMsgTypeEnum) IsCanceling() bool {
+	return x == SessionMgmtMsgTypeCancel || x == SessionMgmtMsgTypeHaultingError
+}
+
+//SessionMgmtMsg is a management message sent by a node of a sync session to its peer. MsgSeq orders the messages of
+//every session and is given when the message is sent.
+type SessionMgmtMsg struct {
+	MsgSeq        int64
+	SessionID     string
+	SenderNodeID  string
+	MessageType   SessionMgmtMsgTypeEnum
+	Result        string
+	RecordCreated time.Time
+}
+
+//ValidateSessionMgmtMsg checks a management message to be sent against the columns of sync_session_mgmt_msg.
+func ValidateSessionMgmtMsg(msg SessionMgmtMsg) error {
+	if !msg.MessageType.IsValid() {
+		return newValidationError("Unknown management message type %d", int(msg.MessageType))
+	}
+	if msg.SessionID == "" || len(msg.SessionID) > 36 {
+		return newValidationError("Session id '%s' must have 1 to 36 characters", msg.SessionID)
+	}
+	if msg.SenderNodeID == "" || len(msg.SenderNodeID) > 36 {
+		return newValidationError("Sender node id '%s' must have 1 to 36 characters", msg.SenderNodeID)
+	}
+	if len(msg.Result) > 1024 {
+		return newValidationError("Result of the management message of session '%s' is longer than 1024 characters", msg.SessionID)
+	}
+	return nil
+}
+
+//SessionMgmtMsgResult reports the sending of a management message.
+type SessionMgmtMsgResult struct {
+	//Result is 'OK' or 'CouldNoFindActiveSession' when the session is not the active session of any pair.
+	Result string
+	MsgSeq int64
+	PairID string
+	//State is the state of the session once the message was handled.
+	State string
+	//ReleasedCount is the number of sync_peer_state records whose in-flight fetch was rolled back by a Cancel or a
+	//HaultingError.
+	ReleasedCount int
+}
+
+//SessionNotActiveResult is the result given when a management message is sent for a session no pair has active.
+const SessionNotActiveResult = "CouldNoFindActiveSession"
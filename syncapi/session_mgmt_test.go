@@ -0,0 +1,22 @@
+package syncapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionMgmt_ValidateSessionMgmtMsg(t *testing.T) {
+	assert.Nil(t, ValidateSessionMgmtMsg(SessionMgmtMsg{SessionID: "*session-1", SenderNodeID: "*node-hub", MessageType: SessionMgmtMsgTypeHeartbeat}))
+	for _, msg := range []SessionMgmtMsg{
+		{SessionID: "*session-1", SenderNodeID: "*node-hub", MessageType: 9},
+		{SessionID: "*session-1", SenderNodeID: "*node-hub"},
+		{SenderNodeID: "*node-hub", MessageType: SessionMgmtMsgTypeCancel},
+		{SessionID: "*session-1", MessageType: SessionMgmtMsgTypeCancel},
+		{SessionID: "*session-1", SenderNodeID: strings.Repeat("x", 37), MessageType: SessionMgmtMsgTypeCancel},
+		{SessionID: "*session-1", SenderNodeID: "*node-hub", MessageType: SessionMgmtMsgTypeCancel, Result: strings.Repeat("x", 1025)},
+	} {
+		assert.IsType(t, ValidationError{}, ValidateSessionMgmtMsg(msg), msg)
+	}
+}
@@ -0,0 +1,22 @@
+package syncapi
+
+const (
+	//SyncingSessionNotActiveResult is the result given when the progress of a session no pair has active is updated.
+	SyncingSessionNotActiveResult = "CouldNoFindActiveSessionToUpdate"
+	//SyncingNodeNotInPairResult is the result given when the progress of a node outside the pair is updated.
+	SyncingNodeNotInPairResult = "CouldNoFindNodeInPair"
+)
+
+//ValidateSyncingCounts checks the bytes and counts given for both directions of a session before they are set on
+//sync_pair_nodes.
+func ValidateSyncingCounts(recordBytes1 int, recordCount1 int, recordBytes2 int, recordCount2 int) error {
+	for _, count := range []struct {
+		name  string
+		value int
+	}{{"recordBytes1", recordBytes1}, {"recordCount1", recordCount1}, {"recordBytes2", recordBytes2}, {"recordCount2", recordCount2}} {
+		if count.value < 0 {
+			return newValidationError("%s must not be negative, got %d", count.name, count.value)
+		}
+	}
+	return nil
+}
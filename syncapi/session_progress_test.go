@@ -0,0 +1,15 @@
+package syncapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionProgress_ValidateSyncingCounts(t *testing.T) {
+	assert.Nil(t, ValidateSyncingCounts(0, 0, 2048, 10))
+	assert.IsType(t, ValidationError{}, ValidateSyncingCounts(-1, 0, 0, 0))
+	assert.IsType(t, ValidationError{}, ValidateSyncingCounts(0, -1, 0, 0))
+	assert.IsType(t, ValidationError{}, ValidateSyncingCounts(0, 0, -1, 0))
+	assert.IsType(t, ValidationError{}, ValidateSyncingCounts(0, 0, 0, -1))
+}
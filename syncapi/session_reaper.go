@@ -0,0 +1,114 @@
+package syncapi
+
+import (
+	"data-sync-tools-go/syncutil"
+	"errors"
+	"time"
+)
+
+//SessionEndReasonExpired is the reason recorded for a sync session ended for outliving the MaxSesDurValue and
+//MaxSesDurUnit of its pair.
+const SessionEndReasonExpired = "Expired"
+
+//EndedSession records a sync session ended by the agent rather than closed by its client.
+type EndedSession struct {
+	PairID    string `json:"pairId"`
+	SessionID string `json:"sessionId"`
+	//State is the state the session was in when it was ended.
+	State        string    `json:"state"`
+	SessionStart time.Time `json:"sessionStart"`
+	SessionEnd   time.Time `json:"sessionEnd"`
+	Reason       string    `json:"reason"`
+	ReasonMsg    string    `json:"reasonMsg"`
+	//ReleasedCount is the number of sync_peer_state records bound to the session which were released.
+	ReleasedCount int `json:"releasedCount"`
+}
+
+//SessionReapResult reports the sync sessions ended by one reaping.
+type SessionReapResult struct {
+	TotalReaped int            `json:"totalReaped"`
+	Sessions    []EndedSession `json:"sessions"`
+}
+
+//SessionRepositoryable gives access to the sync sessions of every pair for ending those a client left behind and for
+//the management messages the nodes of a session send each other.
+type SessionRepositoryable interface {
+	//ReapExpiredSessions cancels and closes every session started, or last renewed by a heartbeat, longer than its
+	//pair's maximum session duration before now. The session is rolled back as by CancelSession, and recorded as ended
+	//with SessionEndReasonExpired.
+	ReapExpiredSessions(now time.Time) (SessionReapResult, error)
+	//EndedSessions lists the sessions of a pair ended by the agent, oldest first.
+	EndedSessions(pairID string) ([]EndedSession, error)
+	//SendMgmtMsg keeps the message, checked by ValidateSessionMgmtMsg beforehand, for the peer to poll. A Heartbeat
+	//renews the lease of the session. A Cancel or a HaultingError moves the pair into Canceling and rolls back the
+	//fetches in flight for the session, releasing the records reserved by QueueBindSendId and TransactionBindSendId to
+	//be fetched again.
+	SendMgmtMsg(msg SessionMgmtMsg) (SessionMgmtMsgResult, error)
+	//PollMgmtMsgs lists, in order, the messages of the session sent by nodes other than nodeID after afterSeq.
+	PollMgmtMsgs(sessionID string, nodeID string, afterSeq int64) ([]SessionMgmtMsg, error)
+	//CancelSession ends the active session sessionID so that the next session of its pair starts from a consistent
+	//point. The session moves into Canceling and the sync_peer_state records bound to it by Queue and Fetch are
+	//released to be queued and sent again. The peer never acknowledges the transactions of a canceled session, so the
+	//TransactionBindReceiveId of the records received from the nodes of the pair is discarded and a transaction the
+	//peer sends again is processed anew. The session is then closed and recorded as ended with
+	//SessionEndReasonCanceled and reasonMsg, checked by ValidateCancelReason beforehand.
+	CancelSession(sessionID string, reasonMsg string, now time.Time) (SessionCancelResult, error)
+}
+
+//sessionDurationUnits gives the length of each unit sync_pair.MaxSesDurUnit may be given in.
+var sessionDurationUnits = map[string]time.Duration{
+	"seconds": time.Second,
+	"minutes": time.Minute,
+	"hours":   time.Hour,
+	"days":    24 * time.Hour,
+}
+
+//MaxSessionDuration gives the duration set by sync_pair.MaxSesDurValue and MaxSesDurUnit, where the unit is one of
+//'seconds', 'minutes', 'hours' or 'days'. A value of 0 or less answers 0, meaning sessions of the pair never expire.
+func MaxSessionDuration(value int, unit string) (time.Duration, error) {
+	if value <= 0 {
+		return 0, nil
+	}
+	unitDuration, ok := sessionDurationUnits[unit]
+	if !ok {
+		return 0, errors.New("Unknown session duration unit '" + unit + "'")
+	}
+	return time.Duration(value) * unitDuration, nil
+}
+
+//IsSessionExpired answers whether a session started at sessionStart, and last renewed by the heartbeat at
+//lastHeartbeat if any, has outlived maxDuration as of now. Sessions without a start or without a maximum duration
+//never expire.
+func IsSessionExpired(sessionStart time.Time, lastHeartbeat time.Time, maxDuration time.Duration, now time.Time) bool {
+	if sessionStart.IsZero() || maxDuration <= 0 {
+		return false
+	}
+	renewed := sessionStart
+	if lastHeartbeat.After(renewed) {
+		renewed = lastHeartbeat
+	}
+	return now.Sub(renewed) > maxDuration
+}
+
+//StartSessionReaping reaps expired sessions every interval in the background until the returned function is called.
+func StartSessionReaping(sessionRepository SessionRepositoryable, interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				_, err := sessionRepository.ReapExpiredSessions(time.Now())
+				if err != nil {
+					syncutil.Error(err, ". Error reaping expired sessions. Trying again in", interval)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+	}
+}
@@ -0,0 +1,67 @@
+package syncapi
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionReaper_MaxSessionDuration(t *testing.T) {
+	duration, err := MaxSessionDuration(10, "minutes")
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Minute, duration)
+	duration, err = MaxSessionDuration(2, "days")
+	assert.Nil(t, err)
+	assert.Equal(t, 48*time.Hour, duration)
+	duration, err = MaxSessionDuration(0, "minutes")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), duration)
+	_, err = MaxSessionDuration(10, "fortnights")
+	assert.NotNil(t, err)
+}
+
+func TestSessionReaper_IsSessionExpired(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.False(t, IsSessionExpired(start, time.Time{}, 10*time.Minute, start.Add(10*time.Minute)))
+	assert.True(t, IsSessionExpired(start, time.Time{}, 10*time.Minute, start.Add(11*time.Minute)))
+	assert.False(t, IsSessionExpired(start, start.Add(5*time.Minute), 10*time.Minute, start.Add(11*time.Minute)))
+	assert.True(t, IsSessionExpired(start, start.Add(5*time.Minute), 10*time.Minute, start.Add(16*time.Minute)))
+	assert.False(t, IsSessionExpired(start, time.Time{}, 0, start.Add(24*time.Hour)))
+	assert.False(t, IsSessionExpired(time.Time{}, time.Time{}, 10*time.Minute, start))
+}
+
+//countingSessionRepository counts the reapings. Only ReapExpiredSessions is called by StartSessionReaping.
+type countingSessionRepository struct {
+	SessionRepositoryable
+	mutex    sync.Mutex
+	reapings int
+}
+
+func (repo *countingSessionRepository) ReapExpiredSessions(now time.Time) (SessionReapResult, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	repo.reapings++
+	return SessionReapResult{}, nil
+}
+
+func (repo *countingSessionRepository) counted() int {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	return repo.reapings
+}
+
+func TestSessionReaper_StartSessionReaping(t *testing.T) {
+	repo := &countingSessionRepository{}
+	stop := StartSessionReaping(repo, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return repo.counted() >= 2
+	}, time.Second, time.Millisecond)
+	stop()
+	reapings := repo.counted()
+
+	//No reaping happens once stopped
+	time.Sleep(20 * time.Millisecond)
+	assert.LessOrEqual(t, repo.counted(), reapings+1)
+}
@@ -0,0 +1,42 @@
+package syncapi
+
+//The states of a sync session as stored in sync_pair.SyncSessionState.
+const (
+	//SyncSessionStateInactive is the state of a pair with no sync session.
+	SyncSessionStateInactive = "Inactive"
+	//SyncSessionStateInitializing is the state of a sync session which was just created.
+	SyncSessionStateInitializing = "Initializing"
+	//SyncSessionStateSeeding is the state of a sync session while the nodes are seeding their sync state.
+	SyncSessionStateSeeding = "Seeding"
+	//SyncSessionStateQueuing is the state of a sync session while changes are queued for the peer node.
+	SyncSessionStateQueuing = "Queuing"
+	//SyncSessionStateSyncing is the state of a sync session while changes are exchanged between the nodes.
+	SyncSessionStateSyncing = "Syncing"
+	//SyncSessionStateCanceling is the state of a sync session which is being abandoned.
+	SyncSessionStateCanceling = "Canceling"
+)
+
+//IllegalStateTransitionResult is the result given when a sync session is asked to move to a state it cannot reach from its current state.
+const IllegalStateTransitionResult = "IllegalStateTransition"
+
+//SyncSessionStateTransitions gives, for each sync session state, the states a session may move to next.
+//Inactive to Initializing is made by creating a sync session. A session may be closed from any state.
+var SyncSessionStateTransitions = map[string][]string{
+	SyncSessionStateInactive:     {SyncSessionStateInitializing},
+	SyncSessionStateInitializing: {SyncSessionStateSeeding, SyncSessionStateCanceling},
+	SyncSessionStateSeeding:      {SyncSessionStateQueuing, SyncSessionStateCanceling},
+	SyncSessionStateQueuing:      {SyncSessionStateSyncing, SyncSessionStateCanceling},
+	SyncSessionStateSyncing:      {SyncSessionStateInactive, SyncSessionStateCanceling},
+	SyncSessionStateCanceling:    {SyncSessionStateInactive},
+}
+
+//IsValidSyncSessionStateTransition answers whether a sync session in the current state may move to the requested state.
+//Unknown states are never valid.
+func IsValidSyncSessionStateTransition(current string, requested string) bool {
+	for _, next := range SyncSessionStateTransitions[current] {
+		if next == requested {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package syncapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionState_Transitions(t *testing.T) {
+	path := []string{SyncSessionStateInactive, SyncSessionStateInitializing, SyncSessionStateSeeding,
+		SyncSessionStateQueuing, SyncSessionStateSyncing, SyncSessionStateInactive}
+	for i := 1; i < len(path); i++ {
+		assert.True(t, IsValidSyncSessionStateTransition(path[i-1], path[i]), path[i-1]+" to "+path[i])
+		assert.False(t, IsValidSyncSessionStateTransition(path[i], path[i-1]), path[i]+" to "+path[i-1])
+	}
+	for _, state := range []string{SyncSessionStateInitializing, SyncSessionStateSeeding, SyncSessionStateQueuing, SyncSessionStateSyncing} {
+		assert.True(t, IsValidSyncSessionStateTransition(state, SyncSessionStateCanceling), state)
+		assert.False(t, IsValidSyncSessionStateTransition(state, state), state)
+	}
+	assert.False(t, IsValidSyncSessionStateTransition(SyncSessionStateInactive, SyncSessionStateCanceling))
+	assert.False(t, IsValidSyncSessionStateTransition(SyncSessionStateCanceling, SyncSessionStateSyncing))
+	assert.True(t, IsValidSyncSessionStateTransition(SyncSessionStateCanceling, SyncSessionStateInactive))
+	assert.False(t, IsValidSyncSessionStateTransition(SyncSessionStateInitializing, "Unknown"))
+	assert.False(t, IsValidSyncSessionStateTransition("Unknown", SyncSessionStateSeeding))
+}
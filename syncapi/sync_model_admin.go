@@ -0,0 +1,114 @@
+package syncapi
+
+import (
+	"data-sync-tools-go/syncdao"
+	"fmt"
+)
+
+//ValidateDataVersionName checks the name of a data version against the column of sync_data_version.
+func ValidateDataVersionName(dataVersionName string) error {
+	if dataVersionName == "" || len(dataVersionName) > 36 {
+		return newValidationError("Data version name '%s' must have 1 to 36 characters", dataVersionName)
+	}
+	return nil
+}
+
+//ValidateEntity checks an entity against the columns of sync_data_entity. The process orders start at 1.
+func ValidateEntity(entity syncdao.EntityPairItem) error {
+	if entity.EntitySingularName == "" || len(entity.EntitySingularName) > 50 {
+		return newValidationError("Entity singular name '%s' must have 1 to 50 characters", entity.EntitySingularName)
+	}
+	if entity.EntityPluralName == "" || len(entity.EntityPluralName) > 50 {
+		return newValidationError("Entity plural name '%s' must have 1 to 50 characters", entity.EntityPluralName)
+	}
+	if entity.ProcessOrderAddUpdate < 1 || entity.ProcessOrderDelete < 1 {
+		return newValidationError("Process orders %d (add/update) and %d (delete) of entity '%s' must be at least 1",
+			entity.ProcessOrderAddUpdate, entity.ProcessOrderDelete, entity.EntitySingularName)
+	}
+	if len(entity.EntityHandlerURI) > 2048 {
+		return newValidationError("Handler uri of entity '%s' is longer than 2048 characters", entity.EntitySingularName)
+	}
+	return nil
+}
+
+//ValidateEntityOrders checks that the process orders of entity agree with those of the entities existing in its data
+//version. Records are added and updated in ProcessOrderAddUpdate order, parents before their children, and deleted
+//in ProcessOrderDelete order, children before their parents, so the delete order must reverse the add order.
+func ValidateEntityOrders(existing []syncdao.EntityPairItem, entity syncdao.EntityPairItem) error {
+	for _, other := range existing {
+		if !processOrdersAgree(other, entity) {
+			return newValidationError("Process orders %d (add/update) and %d (delete) of entity '%s' do not reverse "+
+				"the orders %d and %d of entity '%s'", entity.ProcessOrderAddUpdate, entity.ProcessOrderDelete,
+				entity.EntitySingularName, other.ProcessOrderAddUpdate, other.ProcessOrderDelete, other.EntitySingularName)
+		}
+	}
+	return nil
+}
+
+//processOrdersAgree tells whether the entity processed first of a and b on add and update is processed last on
+//delete. Entities processed together on add and update are processed together on delete.
+func processOrdersAgree(a syncdao.EntityPairItem, b syncdao.EntityPairItem) bool {
+	switch {
+	case a.ProcessOrderAddUpdate < b.ProcessOrderAddUpdate:
+		return a.ProcessOrderDelete > b.ProcessOrderDelete
+	case a.ProcessOrderAddUpdate > b.ProcessOrderAddUpdate:
+		return a.ProcessOrderDelete < b.ProcessOrderDelete
+	default:
+		return a.ProcessOrderDelete == b.ProcessOrderDelete
+	}
+}
+
+//ValidateField checks a field against the columns of sync_data_field.
+func ValidateField(field syncdao.SyncFieldDefinition) error {
+	if field.FieldName == "" || len(field.FieldName) > 100 {
+		return newValidationError("Field name '%s' must have 1 to 100 characters", field.FieldName)
+	}
+	if _, isType := syncdao.SyncFieldTypeEnumName[int32(field.FieldType)]; !isType ||
+		field.FieldType == syncdao.SyncFieldTypeEnumUndefined {
+		return newValidationError("Field '%s' has no type", field.FieldName)
+	}
+	return nil
+}
+
+//ParseFieldType gives the field type of the DataTypeName of sync_data_field.
+func ParseFieldType(dataTypeName string) (syncdao.SyncFieldTypeEnum, error) {
+	fieldType, isType := syncdao.SyncFieldTypeEnumValue[dataTypeName]
+	if !isType || fieldType == int32(syncdao.SyncFieldTypeEnumUndefined) {
+		return syncdao.SyncFieldTypeEnumUndefined, newValidationError("Unknown data type name '%s'. Expected 'String', "+
+			"'Int', 'Float', 'Bool', 'Date' or 'Binary'", dataTypeName)
+	}
+	return syncdao.SyncFieldTypeEnum(fieldType), nil
+}
+
+//SyncModelProblems gives why the entities of a data version cannot be synced, none when they can. Unlike the checks
+//made while adding them, which cannot require a primary key of an entity having no field yet, it requires every
+//entity to have a primary key field. The checks are also made again since the model may have been filled with sql.
+func SyncModelProblems(entities []syncdao.EntityDefinition) []string {
+	problems := []string{}
+	if len(entities) == 0 {
+		problems = append(problems, "The data version has no entity")
+	}
+	for i, definition := range entities {
+		entity := definition.Entity
+		if err := ValidateEntity(entity); err != nil {
+			problems = append(problems, err.Error())
+		}
+		for _, other := range entities[:i] {
+			if !processOrdersAgree(other.Entity, entity) {
+				problems = append(problems, fmt.Sprintf("Process orders of entities '%s' and '%s' do not agree",
+					other.Entity.EntitySingularName, entity.EntitySingularName))
+			}
+		}
+		hasPrimaryKey := false
+		for _, field := range definition.Fields {
+			if err := ValidateField(field); err != nil {
+				problems = append(problems, fmt.Sprintf("Entity '%s': %s", entity.EntitySingularName, err.Error()))
+			}
+			hasPrimaryKey = hasPrimaryKey || field.IsPrimaryKey
+		}
+		if !hasPrimaryKey {
+			problems = append(problems, fmt.Sprintf("Entity '%s' has no primary key field", entity.EntitySingularName))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,72 @@
+package syncapi
+
+import (
+	"data-sync-tools-go/syncdao"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEntity(t *testing.T) {
+	assert.Nil(t, ValidateEntity(syncdao.EntityPairItem{EntitySingularName: "Task", EntityPluralName: "Tasks", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 1}))
+	for _, entity := range []syncdao.EntityPairItem{
+		{EntityPluralName: "Tasks", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 1},
+		{EntitySingularName: strings.Repeat("x", 51), EntityPluralName: "Tasks", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 1},
+		{EntitySingularName: "Task", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 1},
+		{EntitySingularName: "Task", EntityPluralName: "Tasks", ProcessOrderDelete: 1},
+		{EntitySingularName: "Task", EntityPluralName: "Tasks", ProcessOrderAddUpdate: 1},
+		{EntitySingularName: "Task", EntityPluralName: "Tasks", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 1, EntityHandlerURI: strings.Repeat("x", 2049)},
+	} {
+		assert.IsType(t, ValidationError{}, ValidateEntity(entity), entity)
+	}
+}
+
+func TestValidateEntityOrders(t *testing.T) {
+	project := syncdao.EntityPairItem{EntitySingularName: "Project", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 2}
+	existing := []syncdao.EntityPairItem{project}
+	assert.Nil(t, ValidateEntityOrders([]syncdao.EntityPairItem{}, project))
+	assert.Nil(t, ValidateEntityOrders(existing, syncdao.EntityPairItem{EntitySingularName: "Task", ProcessOrderAddUpdate: 2, ProcessOrderDelete: 1}))
+	assert.Nil(t, ValidateEntityOrders(existing, syncdao.EntityPairItem{EntitySingularName: "Team", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 2}))
+	assert.IsType(t, ValidationError{}, ValidateEntityOrders(existing, syncdao.EntityPairItem{EntitySingularName: "Task", ProcessOrderAddUpdate: 2, ProcessOrderDelete: 3}), "a child is deleted before its parent")
+	assert.IsType(t, ValidationError{}, ValidateEntityOrders(existing, syncdao.EntityPairItem{EntitySingularName: "Task", ProcessOrderAddUpdate: 2, ProcessOrderDelete: 2}), "a child is deleted before its parent")
+	assert.IsType(t, ValidationError{}, ValidateEntityOrders(existing, syncdao.EntityPairItem{EntitySingularName: "Team", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 1}), "entities added together are deleted together")
+}
+
+func TestValidateField(t *testing.T) {
+	assert.Nil(t, ValidateField(syncdao.SyncFieldDefinition{FieldName: "taskId", FieldType: syncdao.SyncFieldTypeEnumString, IsPrimaryKey: true}))
+	assert.IsType(t, ValidationError{}, ValidateField(syncdao.SyncFieldDefinition{FieldType: syncdao.SyncFieldTypeEnumString}))
+	assert.IsType(t, ValidationError{}, ValidateField(syncdao.SyncFieldDefinition{FieldName: strings.Repeat("x", 101), FieldType: syncdao.SyncFieldTypeEnumString}))
+	assert.IsType(t, ValidationError{}, ValidateField(syncdao.SyncFieldDefinition{FieldName: "title"}))
+	assert.IsType(t, ValidationError{}, ValidateField(syncdao.SyncFieldDefinition{FieldName: "title", FieldType: 7}))
+
+	fieldType, err := ParseFieldType("Date")
+	assert.Nil(t, err)
+	assert.Equal(t, syncdao.SyncFieldTypeEnumDate, fieldType)
+	for _, dataTypeName := range []string{"", "Undefined", "date", "Time"} {
+		_, err = ParseFieldType(dataTypeName)
+		assert.IsType(t, ValidationError{}, err, dataTypeName)
+	}
+}
+
+func TestSyncModelProblems(t *testing.T) {
+	taskID := syncdao.SyncFieldDefinition{FieldName: "taskId", FieldType: syncdao.SyncFieldTypeEnumString, IsPrimaryKey: true}
+	project := syncdao.EntityDefinition{
+		Entity: syncdao.EntityPairItem{EntitySingularName: "Project", EntityPluralName: "Projects", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 2},
+		Fields: []syncdao.SyncFieldDefinition{{FieldName: "projectId", FieldType: syncdao.SyncFieldTypeEnumString, IsPrimaryKey: true}},
+	}
+	task := syncdao.EntityDefinition{
+		Entity: syncdao.EntityPairItem{EntitySingularName: "Task", EntityPluralName: "Tasks", ProcessOrderAddUpdate: 2, ProcessOrderDelete: 1},
+		Fields: []syncdao.SyncFieldDefinition{taskID, {FieldName: "title", FieldType: syncdao.SyncFieldTypeEnumString}},
+	}
+	assert.Empty(t, SyncModelProblems([]syncdao.EntityDefinition{project, task}))
+	assert.Len(t, SyncModelProblems([]syncdao.EntityDefinition{}), 1)
+
+	task.Fields = []syncdao.SyncFieldDefinition{{FieldName: "title", FieldType: syncdao.SyncFieldTypeEnumString}}
+	task.Entity.ProcessOrderDelete = 3
+	problems := SyncModelProblems([]syncdao.EntityDefinition{project, task})
+	if assert.Len(t, problems, 2) {
+		assert.Equal(t, "Process orders of entities 'Project' and 'Task' do not agree", problems[0])
+		assert.Equal(t, "Entity 'Task' has no primary key field", problems[1])
+	}
+}
@@ -0,0 +1,44 @@
+package syncapi
+
+import (
+	"data-sync-tools-go/syncutil"
+	"time"
+)
+
+//TombstoneCollectResult reports the tombstones purged by one collection.
+type TombstoneCollectResult struct {
+	//Retention is the retention window applied, '0s' when only acknowledged tombstones were purged.
+	Retention      string         `json:"retention"`
+	TotalPurged    int            `json:"totalPurged"`
+	PurgedByEntity map[string]int `json:"purgedByEntity"`
+}
+
+//TombstoneRepositoryable gives access to the sync_state records kept as tombstones once deleted.
+type TombstoneRepositoryable interface {
+	//CollectTombstones purges the tombstones every node paired through sync_pair_nodes has acknowledged. When retention
+	//is greater than 0, tombstones deleted longer than retention ago are purged whether acknowledged or not.
+	CollectTombstones(retention time.Duration) (TombstoneCollectResult, error)
+}
+
+//StartTombstoneCollection collects tombstones every interval in the background until the returned function is called.
+func StartTombstoneCollection(tombstoneRepository TombstoneRepositoryable, interval time.Duration, retention time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				_, err := tombstoneRepository.CollectTombstones(retention)
+				if err != nil {
+					syncutil.Error(err, ". Error collecting tombstones. Trying again in", interval)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+	}
+}
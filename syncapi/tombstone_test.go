@@ -0,0 +1,47 @@
+package syncapi
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//countingTombstoneRepository counts the collections and the retention they were given.
+type countingTombstoneRepository struct {
+	mutex       sync.Mutex
+	collections int
+	retention   time.Duration
+}
+
+func (repo *countingTombstoneRepository) CollectTombstones(retention time.Duration) (TombstoneCollectResult, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	repo.collections++
+	repo.retention = retention
+	return TombstoneCollectResult{Retention: retention.String()}, nil
+}
+
+func (repo *countingTombstoneRepository) counted() (int, time.Duration) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	return repo.collections, repo.retention
+}
+
+func TestTombstone_StartTombstoneCollection(t *testing.T) {
+	repo := &countingTombstoneRepository{}
+	stop := StartTombstoneCollection(repo, 5*time.Millisecond, time.Hour)
+	assert.Eventually(t, func() bool {
+		collections, _ := repo.counted()
+		return collections >= 2
+	}, time.Second, time.Millisecond)
+	stop()
+	collections, retention := repo.counted()
+	assert.Equal(t, time.Hour, retention)
+
+	//No collection happens once stopped
+	time.Sleep(20 * time.Millisecond)
+	stopped, _ := repo.counted()
+	assert.LessOrEqual(t, stopped, collections+1)
+}
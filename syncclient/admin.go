@@ -0,0 +1,57 @@
+package syncclient
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/synchandler"
+	"data-sync-tools-go/syncmsg"
+	"net/url"
+	"time"
+)
+
+//FindSyncConflicts lists the conflicts left for manual resolution with records received from the node, see
+//synchandler.FindSyncConflicts.
+func (client *Client) FindSyncConflicts(nodeID string) ([]syncapi.ConflictItem, error) {
+	var answer []syncapi.ConflictItem
+	err := client.doJSON("GET", path("syncConflict", "nodeId", nodeID), nil, nil, &answer)
+	return answer, err
+}
+
+//GetSyncConflict gives both versions of a conflict left for manual resolution, see synchandler.GetSyncConflict.
+func (client *Client) GetSyncConflict(conflictID string) (syncapi.ConflictItem, error) {
+	var answer syncapi.ConflictItem
+	err := client.doJSON("GET", path("syncConflict", "conflictId", conflictID), nil, nil, &answer)
+	return answer, err
+}
+
+//ResolveSyncConflict resolves a conflict with choice, mergedRecord being only sent with
+//syncapi.ConflictResolutionChoiceMerged. See synchandler.ResolveSyncConflict.
+func (client *Client) ResolveSyncConflict(conflictID string, choice syncapi.ConflictResolutionChoice, mergedRecord *syncmsg.ProtoRecord) (synchandler.ResolveSyncConflictResponse, error) {
+	var answer synchandler.ResolveSyncConflictResponse
+	var request interface{}
+	if choice == syncapi.ConflictResolutionChoiceMerged && mergedRecord != nil {
+		request = mergedRecord
+	}
+	err := client.doJSON("PUT", path("syncConflict", "conflictId", conflictID, "resolve", string(choice)), nil, request, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("ResolveSyncConflict", answer.Result, answer.ResultMsg)
+}
+
+//CollectTombstones purges the deleted records every paired node has acknowledged, see synchandler.CollectTombstones.
+//A retention above zero also purges the tombstones older than it; zero keeps the retention configured in the agent.
+func (client *Client) CollectTombstones(retention time.Duration) (syncapi.TombstoneCollectResult, error) {
+	var answer syncapi.TombstoneCollectResult
+	query := url.Values{}
+	if retention > 0 {
+		query.Set("retention", retention.String())
+	}
+	err := client.doJSON("PUT", path("tombstone", "collect"), query, nil, &answer)
+	return answer, err
+}
+
+//IntegrationTestReset re-establishes the sample data of testName in the agent, see synchandler.IntegrationTestReset.
+func (client *Client) IntegrationTestReset(testName string) error {
+	_, err := client.do("GET", path("integrationTest", "testName", testName), nil, "", nil)
+	return err
+}
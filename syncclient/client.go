@@ -0,0 +1,164 @@
+//Package syncclient calls a data sync agent over its http protocol, the routes served by synchandler.NewRouter. JSON
+//routes answer with the synchandler response types and the sync data routes exchange syncmsg protobuf messages.
+package syncclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+)
+
+//Client calls the agent at BaseURL, such as 'http://localhost:8080'.
+type Client struct {
+	BaseURL string
+	//HTTPClient makes the calls, http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+//NewClient gives a Client calling the agent at baseURL with http.DefaultClient.
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+//StatusError is given when the agent answers with an http status other than 200, such as for a bad argument or a
+//server configuration error. Msg is the body of the answer.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Msg        string
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("%s %s answered %d %s: %s", err.Method, err.URL, err.StatusCode, http.StatusText(err.StatusCode),
+		strings.TrimSpace(err.Msg))
+}
+
+//ResultError is given along with the answer when the agent handled the call but its result is not a success, such as
+//'DifferentSessionIdAlreadyActive' when creating a session. The answer is still given so the caller may act on it.
+type ResultError struct {
+	Route     string
+	Result    string
+	ResultMsg string
+}
+
+func (err *ResultError) Error() string {
+	if err.ResultMsg == "" {
+		return fmt.Sprintf("%s result '%s'", err.Route, err.Result)
+	}
+	return fmt.Sprintf("%s result '%s': %s", err.Route, err.Result, err.ResultMsg)
+}
+
+//resultError gives a ResultError unless result is one of the successful results.
+func resultError(route string, result string, resultMsg string, successes ...string) error {
+	if len(successes) == 0 {
+		successes = []string{"OK"}
+	}
+	for _, success := range successes {
+		if result == success {
+			return nil
+		}
+	}
+	return &ResultError{Route: route, Result: result, ResultMsg: resultMsg}
+}
+
+//path builds the path of a route, escaping every value of segments placed after a name.
+//	path("syncNode", "nodeId", "*node-hub") gives '/syncNode/nodeId/*node-hub'
+func path(route string, namesAndValues ...string) string {
+	answer := "/" + route
+	for i, segment := range namesAndValues {
+		if i%2 == 1 {
+			segment = url.PathEscape(segment)
+		}
+		answer += "/" + segment
+	}
+	return answer
+}
+
+func (client *Client) httpClient() *http.Client {
+	if client.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return client.HTTPClient
+}
+
+//do sends the request and gives the body of an http 200 answer.
+func (client *Client) do(method string, routePath string, query url.Values, contentType string, body io.Reader) ([]byte, error) {
+	requestURL := client.BaseURL + routePath
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+	request, err := http.NewRequest(method, requestURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	response, err := client.httpClient().Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	answer, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return answer, &StatusError{Method: method, URL: requestURL, StatusCode: response.StatusCode, Msg: string(answer)}
+	}
+	return answer, nil
+}
+
+//doJSON sends request, when not nil, as JSON and decodes the JSON answer into answer.
+func (client *Client) doJSON(method string, routePath string, query url.Values, request interface{}, answer interface{}) error {
+	var body io.Reader
+	contentType := ""
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+	data, err := client.do(method, routePath, query, contentType, body)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, answer)
+	if err != nil {
+		return fmt.Errorf("%s %s answer cannot be decoded: %v", method, routePath, err)
+	}
+	return nil
+}
+
+//doProto sends request, when not nil, as a protobuf message and decodes the protobuf answer into answer.
+func (client *Client) doProto(method string, routePath string, query url.Values, request proto.Message, answer proto.Message) error {
+	var body io.Reader
+	contentType := ""
+	if request != nil {
+		data, err := proto.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/x-protobuf"
+	}
+	data, err := client.do(method, routePath, query, contentType, body)
+	if err != nil {
+		return err
+	}
+	err = proto.Unmarshal(data, answer)
+	if err != nil {
+		return fmt.Errorf("%s %s answer cannot be decoded: %v", method, routePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+package syncclient
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncdao/syncdaomem"
+	"data-sync-tools-go/synchandler"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+const sessionID = "*session-id-1"
+
+//readyAgent serves the profile3 sample data held in memory the way the agent does.
+func readyAgent() *httptest.Server {
+	factory := syncdaomem.NewMemoryDaosFactory()
+	err := testhelper.SetupSeededData(factory, "profile3")
+	if err != nil {
+		panic(err)
+	}
+	syncdao.DefaultDaos = factory
+	handlers := synchandler.Handlers{
+		Repository: syncapi.Repository{
+			DataRepo:      syncdaomem.NewDataRepository(factory),
+			ConfigRepo:    syncdaomem.NewConfigRepository(factory),
+			ConflictRepo:  syncdaomem.NewConflictRepository(factory),
+			TombstoneRepo: syncdaomem.NewTombstoneRepository(factory),
+			SessionRepo:   syncdaomem.NewSessionRepository(factory),
+			NodeAdminRepo: syncdaomem.NewNodeAdminRepository(factory),
+		},
+	}
+	return httptest.NewServer(synchandler.NewRouter(handlers))
+}
+
+func TestClient_Nodes(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	server := readyAgent()
+	defer server.Close()
+	client := NewClient(server.URL + "/")
+
+	node, err := client.GetNodeByNodeID("*node-hub")
+	assert.Nil(t, err)
+	assert.Equal(t, "Z", node.NodeName)
+	assert.Equal(t, "Demo Model 1", node.DataVersionName)
+
+	created, err := client.CreateNode(syncdao.SyncNode{NodeID: "*node-spoke4", NodeName: "D", DataVersionName: "Demo Model 1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", created.Result)
+	node, err = client.GetNodeByNodeName("D")
+	assert.Nil(t, err)
+	assert.Equal(t, "*node-spoke4", node.NodeID)
+	nodes, err := client.ListNodes()
+	if assert.Nil(t, err) && assert.Equal(t, 5, len(nodes.Nodes)) {
+		assert.Equal(t, synchandler.NodeItem{NodeID: "*node-spoke4", NodeName: "D", DataVersionName: "Demo Model 1"}, nodes.Nodes[3])
+	}
+	pending, err := client.GetPendingChanges("*node-hub")
+	assert.Nil(t, err)
+	assert.Equal(t, 6, pending.Total)
+	_, err = client.GetPendingChanges("*node-unknown")
+	if statusErr, ok := err.(*StatusError); assert.True(t, ok, "expected a StatusError, got %v", err) {
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	}
+	deleted, err := client.DeleteNodeByNodeID("*node-spoke4")
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", deleted.Result)
+
+	node, err = client.GetNodeByNodeName("D")
+	resultErr, ok := err.(*ResultError)
+	if assert.True(t, ok, "expected a ResultError, got %v", err) {
+		assert.Equal(t, "Error", resultErr.Result)
+		assert.Equal(t, syncdao.ErrDaoNoDataFound.Error(), resultErr.ResultMsg)
+	}
+	assert.Equal(t, "Error", node.Result, "the answer is given along with the ResultError")
+
+	testhelper.EndTest(testName)
+}
+
+func TestClient_Pairs(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	server := readyAgent()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	pairs, err := client.ListPairs()
+	if assert.Nil(t, err) {
+		assert.Len(t, pairs.Pairs, 5)
+	}
+	pair, err := client.CreatePair(synchandler.PairItem{PairID: "*pair-6", PairName: "C <-> Z"})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, pair.MaxSesDurValue)
+	pair.MaxSesDurValue = 5
+	pair, err = client.UpdatePair(pair)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, pair.MaxSesDurValue)
+	_, err = client.CreatePair(synchandler.PairItem{PairID: "*pair-6", PairName: "C <-> Z again"})
+	if statusErr, ok := err.(*StatusError); assert.True(t, ok, "expected a StatusError, got %v", err) {
+		assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	}
+
+	_, err = client.AddPairNode(syncdao.PairNode{PairID: "*pair-6", NodeID: "*node-hub", TargetNodeID: "*node-spoke3", SyncConflictURI: "none"})
+	assert.Nil(t, err)
+	nodes, err := client.AddPairNode(syncdao.PairNode{PairID: "*pair-6", NodeID: "*node-spoke3", TargetNodeID: "*node-hub", SyncConflictURI: "none"})
+	if assert.Nil(t, err) {
+		assert.Len(t, nodes.Nodes, 2)
+	}
+	nodes, err = client.UpdatePairNode(syncdao.PairNode{PairID: "*pair-6", NodeID: "*node-hub", TargetNodeID: "*node-spoke3", SeededDataVersion: "Demo Model 1"})
+	if assert.Nil(t, err) && assert.Len(t, nodes.Nodes, 2) {
+		assert.Equal(t, "Demo Model 1", nodes.Nodes[0].SeededDataVersion)
+	}
+	deletedNode, err := client.DeletePairNode("*pair-6", "*node-hub", "*node-spoke3")
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", deletedNode.Result)
+	nodes, err = client.ListPairNodes("*pair-6")
+	if assert.Nil(t, err) {
+		assert.Len(t, nodes.Nodes, 1)
+	}
+	deleted, err := client.DeletePair("*pair-6")
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", deleted.Result)
+	_, err = client.GetPair("*pair-6")
+	if statusErr, ok := err.(*StatusError); assert.True(t, ok, "expected a StatusError, got %v", err) {
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestClient_SyncModel(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	server := readyAgent()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	versions, err := client.ListDataVersions()
+	if assert.Nil(t, err) {
+		assert.Len(t, versions.DataVersions, 2)
+	}
+	_, err = client.CreateDataVersion("Demo Model 3")
+	assert.Nil(t, err)
+	_, err = client.AddEntity("Demo Model 3", syncdao.EntityPairItem{EntitySingularName: "Task", EntityPluralName: "Tasks", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 1, EntityHandlerURI: "none"})
+	assert.Nil(t, err)
+	_, err = client.AddEntity("Demo Model 3", syncdao.EntityPairItem{EntitySingularName: "Note", EntityPluralName: "Notes", ProcessOrderAddUpdate: 2, ProcessOrderDelete: 2})
+	if statusErr, ok := err.(*StatusError); assert.True(t, ok, "expected a StatusError, got %v", err) {
+		assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	}
+	validation, err := client.ValidateSyncModel("Demo Model 3")
+	if assert.Nil(t, err) {
+		assert.False(t, validation.Valid)
+	}
+	entities, err := client.AddField("Demo Model 3", "Task", synchandler.FieldItem{FieldName: "taskId", DataTypeName: "String", IsPrimaryKey: true})
+	if assert.Nil(t, err) && assert.Len(t, entities.Entities, 1) {
+		assert.Len(t, entities.Entities[0].Fields, 1)
+	}
+	entities, err = client.ListEntities("Demo Model 3")
+	if assert.Nil(t, err) {
+		assert.Len(t, entities.Entities, 1)
+	}
+	validation, err = client.ValidateSyncModel("Demo Model 3")
+	if assert.Nil(t, err) {
+		assert.True(t, validation.Valid)
+	}
+	_, err = client.ValidateSyncModel("Unknown Model")
+	if statusErr, ok := err.(*StatusError); assert.True(t, ok, "expected a StatusError, got %v", err) {
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestClient_GetSyncConfig(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	server := readyAgent()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	config, err := client.GetSyncConfig("A", "Z")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "*pair-1", config.PairID)
+	assert.Equal(t, "*node-spoke1", config.Node1.NodeID)
+	assert.Equal(t, "*node-hub", config.Node2.NodeID)
+
+	_, err = client.GetSyncConfig("A", "C")
+	resultErr, ok := err.(*ResultError)
+	if assert.True(t, ok, "expected a ResultError, got %v", err) {
+		assert.Equal(t, "RequestNodeAndOtherPeerExistsButNotPaired", resultErr.Result)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestClient_Session(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	server := readyAgent()
+	defer server.Close()
+	client := NewClient(server.URL)
+	pairID := "*pair-1"
+
+	created, err := client.CreateSyncSession(sessionID, pairID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, sessionID, created.ActualSessionID)
+	created, err = client.CreateSyncSession("*session-id-2", pairID)
+	if resultErr, ok := err.(*ResultError); assert.True(t, ok, "expected a ResultError, got %v", err) {
+		assert.Equal(t, "DifferentSessionIdAlreadyActive", resultErr.Result)
+	}
+	assert.Equal(t, sessionID, created.ActualSessionID)
+
+	for _, state := range []string{"Seeding", "Queuing"} {
+		updated, err := client.UpdateSyncSessionState(sessionID, pairID, state)
+		assert.Nil(t, err)
+		assert.Equal(t, state, updated.RequestedState)
+	}
+	queued, err := client.QueueSyncChanges(sessionID, "*node-hub", "*msg-id-1")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 6, queued.RecordsQueuedCount)
+
+	//Contacts are processed 4th when added or updated
+	fetched, err := client.FetchSyncData(sessionID, "*node-hub", 4, syncapi.ProcessSyncChangeEnumAddOrUpdate, syncapi.FetchLimits{MaxMsgs: 100})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, syncmsg.SyncRequestEntityMessageResponseResult_HasMsgs, fetched.GetResult())
+	request := fetched.GetRequest()
+	if !assert.Len(t, request.GetItems(), 1) {
+		return
+	}
+	assert.Equal(t, "Contacts", request.Items[0].GetEntityPluralName())
+	assert.Len(t, request.Items[0].Msgs, 3)
+
+	acked, err := client.AcknowledgeSyncData(sessionID, "*node-hub", fastBatchAck(request))
+	assert.Nil(t, err)
+	assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_OK, acked.GetResult())
+	fetched, err = client.FetchSyncData(sessionID, "*node-hub", 4, syncapi.ProcessSyncChangeEnumAddOrUpdate, syncapi.FetchLimits{})
+	assert.Nil(t, err)
+	assert.Equal(t, syncmsg.SyncRequestEntityMessageResponseResult_NoMsgs, fetched.GetResult())
+
+	pairState, err := client.GetPairState(pairID)
+	assert.Nil(t, err)
+	assert.Equal(t, "Queuing", pairState.State)
+	assert.Equal(t, sessionID, pairState.SessionID)
+	assert.Equal(t, 50, pairState.PercentComplete)
+	updated, err := client.UpdateSyncingWithTotals(syncdao.UpdateSyncingRequestWithTotals{PairID: pairID, SessionID: sessionID,
+		NodeID: "*node-hub", RecordBytes1: 600, RecordCount1: 6})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", updated.Result)
+	updated, err = client.UpdateSyncingWithProcessed(syncdao.UpdateSyncingRequestWithProcessed{PairID: pairID, SessionID: sessionID,
+		NodeID: "*node-hub", RecordBytes1: 600, RecordCount1: 6})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", updated.Result)
+	pairState, err = client.GetPairState(pairID)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, pairState.PercentComplete)
+
+	sent, err := client.SendSyncSessionMgmtMsg(sessionID, "*node-spoke1", syncapi.SessionMgmtMsgTypeHeartbeat, "")
+	assert.Nil(t, err)
+	assert.Equal(t, pairID, sent.PairID)
+	msgs, err := client.PollSyncSessionMgmtMsgs(sessionID, "*node-hub", 0)
+	if assert.Nil(t, err) && assert.Len(t, msgs, 1) {
+		assert.Equal(t, sent.MsgSeq, msgs[0].MsgSeq)
+		assert.Equal(t, "*node-spoke1", msgs[0].SenderNode)
+		assert.Equal(t, int(syncapi.SessionMgmtMsgTypeHeartbeat), msgs[0].MessageType)
+	}
+
+	canceled, err := client.CancelSyncSession(sessionID, "Disk full")
+	assert.Nil(t, err)
+	assert.Equal(t, pairID, canceled.PairID)
+	assert.Equal(t, "Queuing", canceled.State)
+	_, err = client.CancelSyncSession(sessionID, "")
+	if resultErr, ok := err.(*ResultError); assert.True(t, ok, "expected a ResultError, got %v", err) {
+		assert.Equal(t, syncapi.SessionNotActiveResult, resultErr.Result)
+	}
+	pairState, err = client.GetPairState(pairID)
+	assert.Nil(t, err)
+	assert.Equal(t, "Inactive", pairState.State)
+
+	created, err = client.CreateSyncSession("*session-id-2", pairID)
+	assert.Nil(t, err)
+	closed, err := client.CloseSyncSession("*session-id-2", pairID)
+	assert.Nil(t, err)
+	assert.Equal(t, "*session-id-2", closed.ActualSessionID)
+
+	testhelper.EndTest(testName)
+}
+
+func TestClient_ProcessSyncData(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	server := readyAgent()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	creator := syncmsg.NewCreator()
+	contact := testhelper.Contact{ContactID: "151EFA13-A3AD-4C18-A2CE-9D66D0AED112", DateOfBirthAsUTC: creator.FormatTimeFromString("1988-01-23 00:00:00.000"),
+		FirstName: "Jill", HeightFt: 5, HeightInch: 3.0, LastName: "Anderson", PreferredHeight: 1}
+	contactPackage, err := testhelper.CreateRecordAndSupport(contact, true, syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer, "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	request := &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(false),
+		TransactionBindId: proto.String("*bind-id-1"),
+		Items: []*syncmsg.ProtoSyncDataMessagesRequest{
+			&syncmsg.ProtoSyncDataMessagesRequest{
+				EntityPluralName: proto.String("Contacts"),
+				Msgs: []*syncmsg.ProtoSyncDataMessageRequest{
+					&syncmsg.ProtoSyncDataMessageRequest{
+						RecordId:        proto.String(contact.ContactID),
+						RecordHash:      proto.String(contactPackage.RecordSha256Hex),
+						SentSyncState:   syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer.Enum(),
+						RecordBytesSize: proto.Uint32(uint32(contactPackage.RecordBytesLen())),
+						RecordData:      contactPackage.RecordBytes,
+					},
+				},
+			},
+		},
+	}
+	processed, err := client.ProcessSyncData(sessionID, "*node-spoke1", request)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "*bind-id-1", processed.GetTransactionBindId())
+	if assert.Len(t, processed.Items, 1) && assert.Len(t, processed.Items[0].Msgs, 1) {
+		assert.Equal(t, contact.ContactID, processed.Items[0].Msgs[0].GetRecordId())
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestClient_ConflictsAndTombstones(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	server := readyAgent()
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	conflicts, err := client.FindSyncConflicts("*node-spoke1")
+	assert.Nil(t, err)
+	assert.Empty(t, conflicts)
+	_, err = client.GetSyncConflict("*conflict-unknown")
+	statusErr, ok := err.(*StatusError)
+	if assert.True(t, ok, "expected a StatusError, got %v", err) {
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	}
+	_, err = client.ResolveSyncConflict("*conflict-unknown", syncapi.ConflictResolutionChoiceLocal, nil)
+	statusErr, ok = err.(*StatusError)
+	if assert.True(t, ok, "expected a StatusError, got %v", err) {
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	}
+
+	collected, err := client.CollectTombstones(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, collected.TotalPurged)
+
+	testhelper.EndTest(testName)
+}
+
+//fastBatchAck acknowledges every record of request as the peer does after a fast batch.
+func fastBatchAck(request *syncmsg.ProtoSyncEntityMessageRequest) *syncmsg.ProtoSyncEntityMessageResponse {
+	answer := &syncmsg.ProtoSyncEntityMessageResponse{
+		TransactionBindId: proto.String(request.GetTransactionBindId()),
+		Result:            syncmsg.SyncEntityMessageResponseResult_OK.Enum(),
+		ResultMsg:         proto.String("All records are fast batch"),
+		Items:             []*syncmsg.ProtoSyncDataMessagesResponse{},
+	}
+	for _, item := range request.Items {
+		ackItem := &syncmsg.ProtoSyncDataMessagesResponse{
+			EntityPluralName: proto.String(item.GetEntityPluralName()),
+			Msgs:             []*syncmsg.ProtoSyncDataMessageResponse{},
+		}
+		for _, msg := range item.Msgs {
+			ackItem.Msgs = append(ackItem.Msgs, &syncmsg.ProtoSyncDataMessageResponse{
+				RecordId:     proto.String(msg.GetRecordId()),
+				RequestHash:  proto.String(msg.GetRecordHash()),
+				ResponseHash: proto.String(msg.GetRecordHash()),
+				SyncState:    syncmsg.AckSyncStateEnum_AckFastBatch.Enum(),
+			})
+		}
+		answer.Items = append(answer.Items, ackItem)
+	}
+	return answer
+}
@@ -0,0 +1,77 @@
+package syncclient
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/synchandler"
+)
+
+//CreateNode adds node to the sync cluster, see synchandler.CreateNewNode.
+func (client *Client) CreateNode(node syncdao.SyncNode) (synchandler.CreateNodeResponse, error) {
+	var answer synchandler.CreateNodeResponse
+	err := client.doJSON("POST", path("syncNode"), nil, node, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("CreateNewNode", answer.Result, answer.ResultMsg)
+}
+
+//ListNodes lists every node of the sync cluster ordered by node name, see synchandler.ListNodes.
+func (client *Client) ListNodes() (synchandler.ListNodesResponse, error) {
+	var answer synchandler.ListNodesResponse
+	err := client.doJSON("GET", path("syncNode"), nil, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("ListNodes", answer.Result, answer.ResultMsg)
+}
+
+//GetNodeByNodeName obtains the node named nodeName, see synchandler.GetNodeByNodeName.
+func (client *Client) GetNodeByNodeName(nodeName string) (synchandler.NodeResponse, error) {
+	var answer synchandler.NodeResponse
+	err := client.doJSON("GET", path("syncNode", "nodeName", nodeName), nil, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("GetNodeByNodeName", answer.Result, answer.ResultMsg)
+}
+
+//GetNodeByNodeID obtains the node with nodeID, see synchandler.GetNodeByNodeID.
+func (client *Client) GetNodeByNodeID(nodeID string) (synchandler.NodeResponse, error) {
+	var answer synchandler.NodeResponse
+	err := client.doJSON("GET", path("syncNode", "nodeId", nodeID), nil, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("GetNodeByNodeId", answer.Result, answer.ResultMsg)
+}
+
+//DeleteNodeByNodeID removes the node with nodeID from the sync cluster, see synchandler.DeleteNodeByNodeID.
+func (client *Client) DeleteNodeByNodeID(nodeID string) (synchandler.DeleteNodeResponse, error) {
+	var answer synchandler.DeleteNodeResponse
+	err := client.doJSON("DELETE", path("syncNode", "nodeId", nodeID), nil, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("DeleteNodeByNodeId", answer.Result, answer.ResultMsg)
+}
+
+//GetPendingChanges counts by entity the records waiting to be sent to the node with nodeID, see
+//synchandler.GetPendingChanges.
+func (client *Client) GetPendingChanges(nodeID string) (syncapi.NodePendingChanges, error) {
+	var answer syncapi.NodePendingChanges
+	err := client.doJSON("GET", path("syncNode", "nodeId", nodeID, "pendingChanges"), nil, nil, &answer)
+	return answer, err
+}
+
+//GetSyncConfig obtains the configuration of the pair of the nodes named node1Name and node2Name, see
+//synchandler.GetSyncConfig. Nodes that exist but are not paired give the result
+//'RequestNodeAndOtherPeerExistsButNotPaired'.
+func (client *Client) GetSyncConfig(node1Name string, node2Name string) (synchandler.PairConfigResponse, error) {
+	var answer synchandler.PairConfigResponse
+	err := client.doJSON("GET", path("syncPairConfig", "node1Name", node1Name, "node2Name", node2Name), nil, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("GetSyncConfig", answer.Response, answer.ResponseMsg)
+}
@@ -0,0 +1,74 @@
+package syncclient
+
+import (
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/synchandler"
+)
+
+//ListPairs lists every sync pair ordered by pair name, see synchandler.ListPairs.
+func (client *Client) ListPairs() (synchandler.ListPairsResponse, error) {
+	var answer synchandler.ListPairsResponse
+	err := client.doJSON("GET", path("syncPair"), nil, nil, &answer)
+	return answer, err
+}
+
+//CreatePair creates the sync pair, without its nodes, and gives it as stored, see synchandler.CreatePair.
+func (client *Client) CreatePair(pair synchandler.PairItem) (synchandler.PairItem, error) {
+	var answer synchandler.PairItem
+	err := client.doJSON("POST", path("syncPair"), nil, pair, &answer)
+	return answer, err
+}
+
+//GetPair obtains the configuration of the pair with pairID, see synchandler.GetPair.
+func (client *Client) GetPair(pairID string) (synchandler.PairItem, error) {
+	var answer synchandler.PairItem
+	err := client.doJSON("GET", path("syncPair", "pairId", pairID), nil, nil, &answer)
+	return answer, err
+}
+
+//UpdatePair replaces the configuration of the pair with pair.PairID and gives it as stored, see
+//synchandler.UpdatePair.
+func (client *Client) UpdatePair(pair synchandler.PairItem) (synchandler.PairItem, error) {
+	var answer synchandler.PairItem
+	err := client.doJSON("PUT", path("syncPair", "pairId", pair.PairID), nil, pair, &answer)
+	return answer, err
+}
+
+//DeletePair deletes the pair with pairID along with its nodes, see synchandler.DeletePair.
+func (client *Client) DeletePair(pairID string) (synchandler.DeletePairResponse, error) {
+	var answer synchandler.DeletePairResponse
+	err := client.doJSON("DELETE", path("syncPair", "pairId", pairID), nil, nil, &answer)
+	return answer, err
+}
+
+//ListPairNodes lists the nodes of the pair with pairID ordered by node id, see synchandler.ListPairNodes.
+func (client *Client) ListPairNodes(pairID string) (synchandler.ListPairNodesResponse, error) {
+	var answer synchandler.ListPairNodesResponse
+	err := client.doJSON("GET", path("syncPair", "pairId", pairID, "nodes"), nil, nil, &answer)
+	return answer, err
+}
+
+//AddPairNode adds node to the nodes of the pair with node.PairID and gives them, see synchandler.AddPairNode.
+func (client *Client) AddPairNode(node syncdao.PairNode) (synchandler.ListPairNodesResponse, error) {
+	var answer synchandler.ListPairNodesResponse
+	err := client.doJSON("POST", path("syncPair", "pairId", node.PairID, "nodes"), nil, node, &answer)
+	return answer, err
+}
+
+//UpdatePairNode replaces the seeded data version and the conflict uri of the node of a pair and gives the nodes of the
+//pair, see synchandler.UpdatePairNode.
+func (client *Client) UpdatePairNode(node syncdao.PairNode) (synchandler.ListPairNodesResponse, error) {
+	var answer synchandler.ListPairNodesResponse
+	err := client.doJSON("PUT", path("syncPair", "pairId", node.PairID, "nodeId", node.NodeID, "targetNodeId",
+		node.TargetNodeID), nil, node, &answer)
+	return answer, err
+}
+
+//DeletePairNode removes the node with nodeID targeting targetNodeID from the pair with pairID, see
+//synchandler.DeletePairNode.
+func (client *Client) DeletePairNode(pairID string, nodeID string, targetNodeID string) (synchandler.DeletePairNodeResponse, error) {
+	var answer synchandler.DeletePairNodeResponse
+	err := client.doJSON("DELETE", path("syncPair", "pairId", pairID, "nodeId", nodeID, "targetNodeId", targetNodeID),
+		nil, nil, &answer)
+	return answer, err
+}
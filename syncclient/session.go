@@ -0,0 +1,120 @@
+package syncclient
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/synchandler"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+//CreateSyncSession starts the session sessionID for the pair, see synchandler.CreateSyncSession.
+func (client *Client) CreateSyncSession(sessionID string, pairID string) (synchandler.CreateSyncSessionResponse, error) {
+	var answer synchandler.CreateSyncSessionResponse
+	err := client.doJSON("POST", path("syncSession", "sessionId", sessionID, "pairId", pairID), nil, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("CreateSyncSession", answer.Result, answer.ResultMsg)
+}
+
+//UpdateSyncSessionState moves the active session of the pair into state, one of 'Seeding', 'Queuing', 'Syncing' or
+//'Canceling'. See synchandler.UpdateSyncSessionState.
+func (client *Client) UpdateSyncSessionState(sessionID string, pairID string, state string) (synchandler.UpdateSyncSessionStateResponse, error) {
+	var answer synchandler.UpdateSyncSessionStateResponse
+	err := client.doJSON("PUT", path("syncSession", "sessionId", sessionID, "pairId", pairID, "state", state), nil, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("UpdateSyncSessionState", answer.Result, answer.ResultMsg)
+}
+
+//UpdateSyncingWithTotals sets how many records, and of how many bytes, the active session syncs in total for the node,
+//see synchandler.UpdateSyncSessionStateWithTotals.
+func (client *Client) UpdateSyncingWithTotals(totals syncdao.UpdateSyncingRequestWithTotals) (synchandler.UpdateSyncingResponse, error) {
+	return client.updateSyncing("UpdateSyncSessionStateWithTotals", "totals", totals)
+}
+
+//UpdateSyncingWithProcessed sets how many records, and of how many bytes, the active session processed so far for the
+//node, see synchandler.UpdateSyncSessionStateWithProcessed.
+func (client *Client) UpdateSyncingWithProcessed(processed syncdao.UpdateSyncingRequestWithProcessed) (synchandler.UpdateSyncingResponse, error) {
+	return client.updateSyncing("UpdateSyncSessionStateWithProcessed", "processed", syncdao.UpdateSyncingRequestWithTotals(processed))
+}
+
+//updateSyncing calls the totals or processed route, kind, with the fields shared by both requests.
+func (client *Client) updateSyncing(route string, kind string, request syncdao.UpdateSyncingRequestWithTotals) (synchandler.UpdateSyncingResponse, error) {
+	var answer synchandler.UpdateSyncingResponse
+	routePath := path("syncSession", "sessionId", request.SessionID, "pairId", request.PairID, "nodeId", request.NodeID) +
+		fmt.Sprintf("/%s/recordBytes1/%d/recordCount1/%d/recordBytes2/%d/recordCount2/%d", kind,
+			request.RecordBytes1, request.RecordCount1, request.RecordBytes2, request.RecordCount2)
+	err := client.doJSON("PUT", routePath, nil, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError(route, answer.Result, answer.ResultMsg)
+}
+
+//CloseSyncSession closes the session sessionID of the pair, see synchandler.CloseSyncSession.
+func (client *Client) CloseSyncSession(sessionID string, pairID string) (synchandler.CloseSyncSessionResponse, error) {
+	var answer synchandler.CloseSyncSessionResponse
+	err := client.doJSON("DELETE", path("syncSession", "sessionId", sessionID, "pairId", pairID), nil, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("CloseSyncSession", answer.Result, answer.ResultMsg)
+}
+
+//CancelSyncSession rolls back and closes the active session sessionID, recording reason with it when not empty. See
+//synchandler.CancelSyncSession.
+func (client *Client) CancelSyncSession(sessionID string, reason string) (synchandler.CancelSyncSessionResponse, error) {
+	var answer synchandler.CancelSyncSessionResponse
+	query := url.Values{}
+	if reason != "" {
+		query.Set("reason", reason)
+	}
+	err := client.doJSON("PUT", path("syncSession", "sessionId", sessionID, "cancel"), query, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("CancelSyncSession", answer.Result, "")
+}
+
+//GetPairState obtains the session state and progress of the pair, see synchandler.GetPairState.
+func (client *Client) GetPairState(pairID string) (synchandler.QueryPairStateResponse, error) {
+	var answer synchandler.QueryPairStateResponse
+	err := client.doJSON("GET", path("syncPairState", "pairId", pairID), nil, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("GetPairState", answer.Result, answer.ResultMsg)
+}
+
+//SendSyncSessionMgmtMsg sends a management message of messageType from the node to its peer in the session, passing
+//result on to the peer when not empty. See synchandler.SendSyncSessionMgmtMsg.
+func (client *Client) SendSyncSessionMgmtMsg(sessionID string, nodeID string, messageType syncapi.SessionMgmtMsgTypeEnum, result string) (synchandler.SendSyncSessionMgmtMsgResponse, error) {
+	var answer synchandler.SendSyncSessionMgmtMsgResponse
+	if !messageType.IsValid() {
+		return answer, fmt.Errorf("unknown session management message type %d", messageType)
+	}
+	query := url.Values{}
+	if result != "" {
+		query.Set("result", result)
+	}
+	routePath := path("syncSessionMgmt", "sessionId", sessionID, "nodeId", nodeID, "messageType",
+		syncapi.SessionMgmtMsgTypeEnumName[int(messageType)])
+	err := client.doJSON("POST", routePath, query, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("SendSyncSessionMgmtMsg", answer.Result, "")
+}
+
+//PollSyncSessionMgmtMsgs lists the management messages the peers of the node sent in the session after the message
+//numbered afterSeq, see synchandler.PollSyncSessionMgmtMsgs.
+func (client *Client) PollSyncSessionMgmtMsgs(sessionID string, nodeID string, afterSeq int64) ([]synchandler.SyncSessionMgmtMsg, error) {
+	var answer []synchandler.SyncSessionMgmtMsg
+	routePath := path("syncSessionMgmt", "sessionId", sessionID, "nodeId", nodeID, "afterSeq", strconv.FormatInt(afterSeq, 10))
+	err := client.doJSON("GET", routePath, nil, nil, &answer)
+	return answer, err
+}
@@ -0,0 +1,71 @@
+package syncclient
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/synchandler"
+	"data-sync-tools-go/syncmsg"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+//QueueSyncChanges queues the changes of the node to be fetched in the session, see synchandler.QueueSyncChanges.
+func (client *Client) QueueSyncChanges(sessionID string, nodeID string, msgID string) (synchandler.QueueSyncChangesResponse, error) {
+	var answer synchandler.QueueSyncChangesResponse
+	err := client.doJSON("PUT", path("changeQueue", "sessionId", sessionID, "nodeId", nodeID, "msgId", msgID), nil, nil, &answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("QueueSyncChanges", answer.Result, answer.ResultMsg)
+}
+
+//FetchSyncData fetches the next batch of queued changes of the node for the entities processed at orderNum, see
+//synchandler.FetchSyncData. The non zero limits override the batch sizes configured for the node. The results
+//'HasMsgs' and 'NoMsgs' are both successful; the batch to send the peer is the Request of the answer.
+func (client *Client) FetchSyncData(sessionID string, nodeID string, orderNum int, changeType syncapi.ProcessSyncChangeEnum, limits syncapi.FetchLimits) (*syncmsg.ProtoRequestSyncEntityMessageResponse, error) {
+	answer := &syncmsg.ProtoRequestSyncEntityMessageResponse{}
+	changeTypeName, found := syncapi.ProcessSyncChangeEnumName[int(changeType)]
+	if !found {
+		return answer, fmt.Errorf("unknown change type %d", changeType)
+	}
+	query := url.Values{}
+	if limits.MaxMsgs > 0 {
+		query.Set("maxMsgs", strconv.Itoa(limits.MaxMsgs))
+	}
+	if limits.MaxGroupBytesSize > 0 {
+		query.Set("maxBytes", strconv.Itoa(limits.MaxGroupBytesSize))
+	}
+	routePath := path("fetchData", "sessionId", sessionID, "nodeId", nodeID, "orderNum", strconv.Itoa(orderNum),
+		"changeType", changeTypeName) + "/"
+	err := client.doProto("GET", routePath, query, nil, answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("FetchSyncData", answer.GetResult().String(), answer.GetResultMsg(),
+		syncmsg.SyncRequestEntityMessageResponseResult_HasMsgs.String(),
+		syncmsg.SyncRequestEntityMessageResponseResult_NoMsgs.String())
+}
+
+//ProcessSyncData sends the batch the peer fetched to be processed as changes received by the node, see
+//synchandler.ProcessSyncData. The answer is the acknowledgement to give back to the peer with AcknowledgeSyncData.
+func (client *Client) ProcessSyncData(sessionID string, nodeID string, request *syncmsg.ProtoSyncEntityMessageRequest) (*syncmsg.ProtoSyncEntityMessageResponse, error) {
+	answer := &syncmsg.ProtoSyncEntityMessageResponse{}
+	routePath := path("syncData", "sessionId", sessionID, "nodeId", nodeID, "transactionBindId", request.GetTransactionBindId())
+	err := client.doProto("PUT", routePath, nil, request, answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("ProcessSyncData", answer.GetResult().String(), answer.GetResultMsg())
+}
+
+//AcknowledgeSyncData gives the node the results of the peer processing the batch it fetched, see
+//synchandler.AcknowledgeSyncData.
+func (client *Client) AcknowledgeSyncData(sessionID string, nodeID string, ack *syncmsg.ProtoSyncEntityMessageResponse) (*syncmsg.ProtoSyncEntityMessageResponse, error) {
+	answer := &syncmsg.ProtoSyncEntityMessageResponse{}
+	routePath := path("ackData", "sessionId", sessionID, "nodeId", nodeID, "transactionBindId", ack.GetTransactionBindId())
+	err := client.doProto("PUT", routePath, nil, ack, answer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, resultError("AcknowledgeSyncData", answer.GetResult().String(), answer.GetResultMsg())
+}
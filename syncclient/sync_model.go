@@ -0,0 +1,52 @@
+package syncclient
+
+import (
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/synchandler"
+)
+
+//ListDataVersions lists every data version of the sync model ordered by name, see synchandler.ListDataVersions.
+func (client *Client) ListDataVersions() (synchandler.ListDataVersionsResponse, error) {
+	var answer synchandler.ListDataVersionsResponse
+	err := client.doJSON("GET", path("syncDataVersion"), nil, nil, &answer)
+	return answer, err
+}
+
+//CreateDataVersion registers the data version dataVersionName, see synchandler.CreateDataVersion.
+func (client *Client) CreateDataVersion(dataVersionName string) (synchandler.DataVersionItem, error) {
+	var answer synchandler.DataVersionItem
+	err := client.doJSON("POST", path("syncDataVersion"), nil, synchandler.DataVersionItem{DataVersionName: dataVersionName},
+		&answer)
+	return answer, err
+}
+
+//ListEntities lists the entities of the data version along with their fields, see synchandler.ListEntities.
+func (client *Client) ListEntities(dataVersionName string) (synchandler.ListEntitiesResponse, error) {
+	var answer synchandler.ListEntitiesResponse
+	err := client.doJSON("GET", path("syncDataVersion", "dataVersionName", dataVersionName, "entities"), nil, nil, &answer)
+	return answer, err
+}
+
+//AddEntity adds entity to the data version and gives its entities, see synchandler.AddEntity.
+func (client *Client) AddEntity(dataVersionName string, entity syncdao.EntityPairItem) (synchandler.ListEntitiesResponse, error) {
+	var answer synchandler.ListEntitiesResponse
+	err := client.doJSON("POST", path("syncDataVersion", "dataVersionName", dataVersionName, "entities"), nil, entity,
+		&answer)
+	return answer, err
+}
+
+//AddField adds field to an entity of the data version and gives the entities of the data version, see
+//synchandler.AddField.
+func (client *Client) AddField(dataVersionName string, entitySingularName string, field synchandler.FieldItem) (synchandler.ListEntitiesResponse, error) {
+	var answer synchandler.ListEntitiesResponse
+	err := client.doJSON("POST", path("syncDataVersion", "dataVersionName", dataVersionName, "entitySingularName",
+		entitySingularName, "fields"), nil, field, &answer)
+	return answer, err
+}
+
+//ValidateSyncModel tells whether the entities of the data version can be synced, see synchandler.ValidateSyncModel.
+func (client *Client) ValidateSyncModel(dataVersionName string) (synchandler.ValidateSyncModelResponse, error) {
+	var answer synchandler.ValidateSyncModelResponse
+	err := client.doJSON("GET", path("syncDataVersion", "dataVersionName", dataVersionName, "validate"), nil, nil, &answer)
+	return answer, err
+}
@@ -20,16 +20,17 @@ type SyncPair struct {
 	PairID   string
 	PairName string
 	//DataVersionMapId string
-	MaxSesDurValue    int
-	MaxSesDurUnit     string
-	SyncDataTransForm string
-	SyncMsgTransForm  string
-	SyncMsgSecPol     string
-	SyncSessionID     string
-	SyncSessionState  string
-	SyncSessionStart  time.Time
-	SyncConflictURI   string
-	RecordCreated     time.Time
+	MaxSesDurValue       int
+	MaxSesDurUnit        string
+	SyncDataTransForm    string
+	SyncMsgTransForm     string
+	SyncMsgSecPol        string
+	SyncSessionID        string
+	SyncSessionState     string
+	SyncSessionStart     time.Time
+	SyncSessionHeartbeat time.Time
+	SyncConflictURI      string
+	RecordCreated        time.Time
 }
 
 //NodePairItem represents the configuration for a given node in a SyncPair.
@@ -50,6 +51,15 @@ type NodePairItem struct {
 	Entities                  []EntityPairItem `json:"entities"`
 }
 
+//PairNode represents the configuration (sync_pair_nodes) of a node of a SyncPair syncing to TargetNodeID.
+type PairNode struct {
+	PairID            string `json:"pairId"`
+	NodeID            string `json:"nodeId"`
+	TargetNodeID      string `json:"targetNodeId"`
+	SeededDataVersion string `json:"seededDataVersion"`
+	SyncConflictURI   string `json:"syncConflictUri"`
+}
+
 //EntityPairItem represents the data for a given node in a SyncPair.
 type EntityPairItem struct {
 	EntitySingularName    string `json:"entitySingularName"`
@@ -59,6 +69,13 @@ type EntityPairItem struct {
 	EntityHandlerURI      string `json:"entityHandlerUri"`
 }
 
+//EntityDefinition represents an entity (sync_data_entity) of a data version along with its fields (sync_data_field)
+//ordered by FieldName.
+type EntityDefinition struct {
+	Entity EntityPairItem
+	Fields []SyncFieldDefinition
+}
+
 //CreateSyncSessionRequest represents a request to create to sync session for a given SyncPair.
 type CreateSyncSessionRequest struct {
 	PairID    string `json:"pairId"`
@@ -77,28 +94,30 @@ type UpdateSyncSessionStateRequest struct {
 	SessionID string `json:"sessionId"`
 }
 
-// BUG(doug4j@gmail.com): Create UpdateSyncingRequestWithTotals
-/*
+//UpdateSyncingRequestWithTotals represents a request to set how many records, and of how many bytes, the active
+//session of a SyncPair syncs in total. Direction 1 is from NodeID to its peer in the pair, direction 2 from the peer to
+//NodeID.
 type UpdateSyncingRequestWithTotals struct {
-	State        string `json:"state"`
-	PairId       string `json:"pairId"`
-	SessionId    string `json:"sessionId"`
-	RecordBytes1 string `json:"recordBytes1"`
-	RecordCount1 string `json:"recordCount1"`
-	RecordBytes2 string `json:"recordBytes2"`
-	RecordCount2 string `json:"recordCount2"`
+	PairID       string `json:"pairId"`
+	SessionID    string `json:"sessionId"`
+	NodeID       string `json:"nodeId"`
+	RecordBytes1 int    `json:"recordBytes1"`
+	RecordCount1 int    `json:"recordCount1"`
+	RecordBytes2 int    `json:"recordBytes2"`
+	RecordCount2 int    `json:"recordCount2"`
 }
 
+//UpdateSyncingRequestWithProcessed represents a request to set how many records, and of how many bytes, the active
+//session of a SyncPair processed so far. The directions are those of UpdateSyncingRequestWithTotals.
 type UpdateSyncingRequestWithProcessed struct {
-	State        string `json:"state"`
-	PairId       string `json:"pairId"`
-	SessionId    string `json:"sessionId"`
-	RecordBytes1 string `json:"recordBytes1"`
-	RecordCount1 string `json:"recordCount1"`
-	RecordBytes2 string `json:"recordBytes2"`
-	RecordCount2 string `json:"recordCount2"`
+	PairID       string `json:"pairId"`
+	SessionID    string `json:"sessionId"`
+	NodeID       string `json:"nodeId"`
+	RecordBytes1 int    `json:"recordBytes1"`
+	RecordCount1 int    `json:"recordCount1"`
+	RecordBytes2 int    `json:"recordBytes2"`
+	RecordCount2 int    `json:"recordCount2"`
 }
-*/
 
 //CloseSyncSessionRequest represents a request to close to sync session for a given SyncPair.
 type CloseSyncSessionRequest struct {
@@ -126,6 +145,8 @@ type SyncNodeDao interface {
 	GetOneNodeByNodeName(nodeName string) (SyncNode, error)
 	GetOneNodeByNodeID(nodeID string) (SyncNode, error)
 	DeleteNodeByNodeID(nodeID string) error
+	//ListNodes lists every node ordered by NodeName.
+	ListNodes() ([]SyncNode, error)
 
 	//QueueChanges(sessionID string, nodeIDToQueue string) (int, error)
 	//ProcessChanges(sessionID string, nodeIDToProcess string, transactionBindID string, request syncmsg.ProtoSyncEntityMessageRequest) syncmsg.ProtoSyncEntityMessageResponse
@@ -148,13 +169,61 @@ type SyncPairDao interface {
 	GetEntityPairItem(pairID string, nodeName string) ([]EntityPairItem, error)
 	CreateSyncSession(syncSession CreateSyncSessionRequest) (CreateSyncSessionDaoResult, error)
 	UpdateSyncSessionState(request UpdateSyncSessionStateRequest) (UpdateSyncSessionStateResult, error)
-	// BUG(doug4j@gmail.com): Add UpdateSyncingWithTotals(request UpdateSyncingRequestWithTotals) (UpdateSyncingResult, error)
-	// BUG(doug4j@gmail.com): Add UpdateSyncingWithProcessed(request UpdateSyncingRequestWithProcessed) (UpdateSyncingResult, error)
+	UpdateSyncingWithTotals(request UpdateSyncingRequestWithTotals) (UpdateSyncingResult, error)
+	UpdateSyncingWithProcessed(request UpdateSyncingRequestWithProcessed) (UpdateSyncingResult, error)
 	QueryPairState(request QueryPairStateRequest) (QueryPairStateDaoResult, error)
 	//QuerySessionConfig(sessionId string) (map[string,NodePairItem], error)
 	//QuerySessionConfig(sessionId string) (QuerySessionConfigResult, error)
 	//String results include 'OK' or 'SessionIdAlreadyInactive'. Errors include the error from the underlying datastore (such as 'CloseSyncSessionUnknownError').
 	CloseSyncSession(syncSession CloseSyncSessionRequest) (CloseSyncSessionDaoResult, error)
+
+	//AddDataVersion adds a data version (sync_data_version), checked by syncapi.ValidateDataVersionName beforehand,
+	//which the entities and nodes are then added to. It gives a syncapi.ValidationError when the name is taken.
+	AddDataVersion(dataVersionName string) error
+	//AddEntity adds an entity (sync_data_entity) of an existing data version, checked by syncapi.ValidateEntity
+	//beforehand and by syncapi.ValidateEntityOrders against the entities of the data version. It gives
+	//ErrDaoNoDataFound when there is no such data version.
+	AddEntity(dataVersionName string, entity EntityPairItem) error
+	//AddField adds a field (sync_data_field) to an existing entity of the data version, checked by
+	//syncapi.ValidateField beforehand. It gives ErrDaoNoDataFound when the data version has no such entity.
+	AddField(dataVersionName string, entitySingularName string, field SyncFieldDefinition) error
+	//ListDataVersions lists the names of every data version in order.
+	ListDataVersions() ([]string, error)
+	//ListEntities lists the entities of the data version along with their fields, ordered by ProcessOrderAddUpdate
+	//then EntitySingularName. It gives ErrDaoNoDataFound when there is no such data version.
+	ListEntities(dataVersionName string) ([]EntityDefinition, error)
+	//AddPair adds a pair (sync_pair), checked by syncapi.ValidatePair beforehand. Fields left at their zero value take
+	//the defaults of the sql schema, so the pair starts out 'Inactive'.
+	AddPair(pair SyncPair) error
+	//AddPairNode adds the configuration (sync_pair_nodes) of a node of a pair syncing to targetNodeID, checked by
+	//syncapi.ValidatePairNode beforehand. A pair is complete once both of its nodes have been added, each targeting
+	//the other, as checked by syncapi.ValidatePairMembership. It gives ErrDaoNoDataFound when there is no pair pairID
+	//and syncapi.ErrPairSessionActive while the pair has an active session.
+	AddPairNode(pairID string, nodeID string, targetNodeID string, seededDataVersion string, syncConflictURI string) error
+
+	//GetPair gets the pair pairID. It gives ErrDaoNoDataFound when there is no such pair.
+	GetPair(pairID string) (SyncPair, error)
+	//ListPairs lists every pair ordered by PairName.
+	ListPairs() ([]SyncPair, error)
+	//UpdatePair changes the configuration of the pair pair.PairID, checked by syncapi.ValidatePair beforehand, fields
+	//left at their zero value taking the defaults of the sql schema. The session of the pair is left as is. It gives
+	//ErrDaoNoDataFound when there is no such pair and syncapi.ErrPairSessionActive while the pair has an active
+	//session.
+	UpdatePair(pair SyncPair) error
+	//DeletePair deletes the pair pairID along with its nodes and the history of its sessions, and their management
+	//messages when these record their pair. It gives ErrDaoNoDataFound when there is no such pair and
+	//syncapi.ErrPairSessionActive while the pair has an active session.
+	DeletePair(pairID string) error
+	//ListPairNodes lists the nodes of the pair pairID ordered by NodeID. It gives ErrDaoNoDataFound when there is no
+	//such pair.
+	ListPairNodes(pairID string) ([]PairNode, error)
+	//UpdatePairNode changes the SeededDataVersion and SyncConflictURI of a node of a pair, checked by
+	//syncapi.ValidatePairNode beforehand. It gives ErrDaoNoDataFound when the pair has no such node and
+	//syncapi.ErrPairSessionActive while the pair has an active session.
+	UpdatePairNode(node PairNode) error
+	//DeletePairNode removes the node nodeID targeting targetNodeID from the pair pairID. It gives ErrDaoNoDataFound
+	//when the pair has no such node and syncapi.ErrPairSessionActive while the pair has an active session.
+	DeletePairNode(pairID string, nodeID string, targetNodeID string) error
 }
 
 //CreateSyncSessionDaoResult represents the results from creating a SyncSession.
@@ -174,13 +243,40 @@ type UpdateSyncSessionStateResult struct {
 	ResultingState     string
 }
 
-// TODO(doug4j@gmail.com): Add type UpdateSyncingResult struct
-/*
+//UpdateSyncingResult represents the results from updating the progress of a SyncSession.
 type UpdateSyncingResult struct {
+	//Valid values: 'OK', syncapi.SyncingSessionNotActiveResult or syncapi.SyncingNodeNotInPairResult
 	Result    string
 	ResultMsg string
 }
-*/
+
+//SessionProgress represents how far the active, or last, session of a SyncPair got sending records from NodeID to
+//TargetNodeID. It is the TotalSessRec* and ProceSessRec* columns of the sync_pair_nodes row of the direction.
+type SessionProgress struct {
+	NodeID            string `json:"nodeId"`
+	TargetNodeID      string `json:"targetNodeId"`
+	TotalSessRecBytes int    `json:"totalSessRecBytes"`
+	TotalSessRecCount int    `json:"totalSessRecCount"`
+	ProceSessRecBytes int    `json:"proceSessRecBytes"`
+	ProceSessRecCount int    `json:"proceSessRecCount"`
+}
+
+//PercentComplete gives the percent, 0 to 100, of the records processed across the given directions. Nothing to sync
+//gives 0 as the totals are not known yet.
+func PercentComplete(progress []SessionProgress) int {
+	var total, processed int
+	for _, direction := range progress {
+		total += direction.TotalSessRecCount
+		processed += direction.ProceSessRecCount
+	}
+	if total <= 0 {
+		return 0
+	}
+	if processed >= total {
+		return 100
+	}
+	return processed * 100 / total
+}
 
 //QueryPairStateDaoResult represents the results from querying the state of a SyncPair.
 type QueryPairStateDaoResult struct {
@@ -189,6 +285,8 @@ type QueryPairStateDaoResult struct {
 	State        string
 	LastUpdated  time.Time
 	SessionStart time.Time
+	//Progress has a SessionProgress for each direction of the pair, ordered by NodeID
+	Progress []SessionProgress
 }
 
 //CloseSyncSessionDaoResult represents the results from closing a SyncSession.
@@ -1,4 +1,4 @@
 /*
-Placeholder code, not fully implemented for SQL Server and sqllite. 
+Placeholder code, not fully implemented for SQL Server. See syncdaosqlite for sqlite.
 */
 package main
\ No newline at end of file
@@ -0,0 +1,28 @@
+package syncdaobolt
+
+import (
+	"data-sync-tools-go/syncdao/syncdaomem"
+	"data-sync-tools-go/syncdao/syncdaotest"
+	"data-sync-tools-go/testhelper"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	syncdaotest.Run(t, func(profile string) (syncdaotest.Fixture, error) {
+		factory, err := NewBoltDaosFactory(testDbName)
+		if err != nil {
+			return syncdaotest.Fixture{}, err
+		}
+		err = testhelper.SetupSeededData(factory, profile)
+		fixture := syncdaotest.Fixture{
+			Daos:          factory,
+			DataRepo:      syncdaomem.NewDataRepository(factory),
+			ConflictRepo:  syncdaomem.NewConflictRepository(factory),
+			TombstoneRepo: syncdaomem.NewTombstoneRepository(factory),
+			SessionRepo:   syncdaomem.NewSessionRepository(factory),
+			NodeAdminRepo: syncdaomem.NewNodeAdminRepository(factory),
+			Local:         factory,
+		}
+		return fixture, err
+	})
+}
@@ -0,0 +1,89 @@
+//Package syncdaobolt keeps the sync model of syncdaomem in an embedded bbolt key-value file instead of a sql
+//database, suiting mobile-like and single-binary deployments. Every change is written to the file before an
+//operation returns.
+package syncdaobolt
+
+import (
+	"data-sync-tools-go/syncdao/syncdaomem"
+	"data-sync-tools-go/syncutil"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+//openTimeout is how long to wait for another process to release the file.
+const openTimeout = 5 * time.Second
+
+//boltPersistence implements syncdaomem.Persistence with a bbolt file, every table being a bucket of the file.
+type boltPersistence struct {
+	db *bolt.DB
+}
+
+//NewBoltDaosFactory creates a syncdaomem.MemoryDaosFactory instance holding the sync model of the bbolt file at path,
+//which is created when it does not exist yet. The file stays locked until Close, so only one process can use it at a
+//time.
+func NewBoltDaosFactory(path string) (*syncdaomem.MemoryDaosFactory, error) {
+	syncutil.Info("Using bbolt Mode with file:", path)
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		syncutil.Error(err, ". Cannot open bbolt file", path)
+		return nil, err
+	}
+	factory, err := syncdaomem.NewPersistentDaosFactory(boltPersistence{db: db})
+	if err != nil {
+		syncutil.Error(err, ". Cannot read bbolt file", path)
+		db.Close()
+		return nil, err
+	}
+	return factory, nil
+}
+
+//Load implements syncdaomem.Persistence.Load, reading every bucket of the file. bbolt gives the keys of a bucket in
+//order.
+func (persistence boltPersistence) Load(read func(table string, key []byte, value []byte) error) error {
+	return persistence.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+			return bucket.ForEach(func(key []byte, value []byte) error {
+				return read(string(name), key, value)
+			})
+		})
+	})
+}
+
+//Save implements syncdaomem.Persistence.Save within a single bbolt transaction.
+func (persistence boltPersistence) Save(writes []syncdaomem.Write) error {
+	return persistence.db.Update(func(tx *bolt.Tx) error {
+		for _, write := range writes {
+			err := saveWrite(tx, write)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+//saveWrite makes write within tx, creating its bucket when needed.
+func saveWrite(tx *bolt.Tx, write syncdaomem.Write) error {
+	name := []byte(write.Table)
+	if write.Key == nil {
+		err := tx.DeleteBucket(name)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		return nil
+	}
+	bucket, err := tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return err
+	}
+	if write.Value == nil {
+		return bucket.Delete(write.Key)
+	}
+	return bucket.Put(write.Key, write.Value)
+}
+
+//Close implements syncdaomem.Persistence.Close, releasing the file for other processes.
+func (persistence boltPersistence) Close() error {
+	return persistence.db.Close()
+}
@@ -0,0 +1,67 @@
+package syncdaobolt
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncdao/syncdaomem"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoltDaosFactory_ChangesAreWritten(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile3")
+
+	assert.Nil(t, factory.SyncNodeDao().AddNode(syncdao.SyncNode{NodeID: "*node-new", NodeName: "N", DataVersionName: "Demo Model 1"}))
+	assert.Nil(t, factory.SyncNodeDao().AddNode(syncdao.SyncNode{NodeID: "*node-deleted", NodeName: "D", DataVersionName: "Demo Model 1"}))
+	assert.Nil(t, factory.SyncNodeDao().DeleteNodeByNodeID("*node-deleted"))
+	_, err := factory.SyncPairDao().CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: "*pair-1", SessionID: "*session-id-1"})
+	assert.Nil(t, err)
+	reaped, err := syncdaomem.NewSessionRepository(factory).ReapExpiredSessions(time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, reaped.TotalReaped)
+	syncPairDao := factory.SyncPairDao()
+	assert.Nil(t, syncPairDao.DeletePairNode("*pair-2", "*node-hub", "*node-spoke2"))
+	assert.Nil(t, syncPairDao.UpdatePairNode(syncdao.PairNode{PairID: "*pair-4", NodeID: "*node-spoke3", TargetNodeID: "*node-hub", SyncConflictURI: "http://localhost:8080/conflict"}))
+	assert.Nil(t, syncPairDao.AddPairNode("*pair-2", "*node-hub", "*node-spoke2", "", "none"))
+
+	factory = reopenTestFactory(factory)
+	_, err = factory.SyncNodeDao().GetOneNodeByNodeID("*node-new")
+	assert.Nil(t, err)
+	_, err = factory.SyncNodeDao().GetOneNodeByNodeID("*node-deleted")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+	pairState, err := factory.SyncPairDao().QueryPairState(syncdao.QueryPairStateRequest{PairID: "*pair-1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Inactive", pairState.State)
+	ended, err := syncdaomem.NewSessionRepository(factory).EndedSessions("*pair-1")
+	assert.Nil(t, err)
+	if assert.Len(t, ended, 1) {
+		assert.Equal(t, "*session-id-1", ended[0].SessionID)
+		assert.Equal(t, syncapi.SessionEndReasonExpired, ended[0].Reason)
+	}
+	pairNodes, err := factory.SyncPairDao().ListPairNodes("*pair-4")
+	if assert.Nil(t, err) && assert.Len(t, pairNodes, 2) {
+		assert.Equal(t, "http://localhost:8080/conflict", pairNodes[1].SyncConflictURI)
+	}
+	pairNodes, err = factory.SyncPairDao().ListPairNodes("*pair-2")
+	if assert.Nil(t, err) {
+		assert.Len(t, pairNodes, 2)
+	}
+
+	//Reset empties the file
+	factory.Reset()
+	factory = reopenTestFactory(factory)
+	defer func() {
+		factory.Close()
+	}()
+	nodes, err := factory.SyncNodeDao().ListNodes()
+	assert.Nil(t, err)
+	assert.Empty(t, nodes)
+
+	testhelper.EndTest(testName)
+}
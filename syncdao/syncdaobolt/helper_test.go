@@ -0,0 +1,35 @@
+package syncdaobolt
+
+import (
+	"data-sync-tools-go/syncdao/syncdaomem"
+	"data-sync-tools-go/testhelper"
+	"os"
+	"path/filepath"
+)
+
+var testDbName = filepath.Join(os.TempDir(), testhelper.TestDbName+".bolt")
+
+//newTestFactory opens the test file holding the sample data of the given profile, see testhelper.SetupSeededData.
+//The factory has to be closed for the file to be opened again.
+func newTestFactory(profile string) *syncdaomem.MemoryDaosFactory {
+	factory, err := NewBoltDaosFactory(testDbName)
+	if err != nil {
+		panic(err)
+	}
+	err = testhelper.SetupSeededData(factory, profile)
+	if err != nil {
+		factory.Close()
+		panic(err)
+	}
+	return factory
+}
+
+//reopenTestFactory closes factory and opens the test file again, so that the sync model is read from the file.
+func reopenTestFactory(factory *syncdaomem.MemoryDaosFactory) *syncdaomem.MemoryDaosFactory {
+	factory.Close()
+	reopened, err := NewBoltDaosFactory(testDbName)
+	if err != nil {
+		panic(err)
+	}
+	return reopened
+}
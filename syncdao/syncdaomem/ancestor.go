@@ -0,0 +1,71 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+
+	"github.com/golang/protobuf/proto"
+)
+
+//The versions of a record exchanged with a peer are kept in the store's ancestors so that, when both sides change the
+//record, the version identified by the peer's LastKnownPeerHash is available as the common ancestor for a three-way
+//merge. Only the versions still referenced by a sync state or a peer state are kept.
+
+//saveAncestors keeps the current version of every record whose peer state for nodeID is accepted by bound, then
+//drops the versions of those records no longer referenced. The caller holds the store.
+func (store *store) saveAncestors(nodeID string, bound func(peer *peerStateRow) bool) {
+	records := make(map[recordKey]bool)
+	for key, peer := range store.peerStates {
+		if key.nodeID != nodeID || !bound(peer) {
+			continue
+		}
+		state, found := store.states[key.recordKey]
+		if !found {
+			continue
+		}
+		records[key.recordKey] = true
+		ancestor := ancestorKey{recordKey: key.recordKey, recordHash: state.recordHash}
+		if _, found := store.ancestors[ancestor]; !found {
+			store.putAncestor(ancestor, copyBytes(state.recordData))
+		}
+	}
+	if len(records) == 0 {
+		return
+	}
+	for ancestor := range store.ancestors {
+		if records[ancestor.recordKey] && !store.isAncestorReferenced(ancestor) {
+			store.deleteAncestor(ancestor)
+		}
+	}
+}
+
+//isAncestorReferenced tells whether a version of a record is the current one or the one last exchanged with a node.
+func (store *store) isAncestorReferenced(ancestor ancestorKey) bool {
+	if state, found := store.states[ancestor.recordKey]; found && state.recordHash == ancestor.recordHash {
+		return true
+	}
+	for nodeID := range store.nodes {
+		peer, found := store.peerStates[peerKey{nodeID: nodeID, recordKey: ancestor.recordKey}]
+		if found && (peer.peerLastKnownHash == ancestor.recordHash || peer.sentLastKnownHash == ancestor.recordHash) {
+			return true
+		}
+	}
+	return false
+}
+
+//findAncestorRecord gives the version of a record with the given hash. syncdao.ErrDaoNoDataFound is given when that
+//version is not kept.
+func (store *store) findAncestorRecord(entitySingularName string, recordID string, recordHash string) (*syncmsg.ProtoRecord, error) {
+	recordData, found := store.ancestors[ancestorKey{recordKey: recordKey{entitySingularName: entitySingularName, recordID: recordID}, recordHash: recordHash}]
+	if !found {
+		return nil, syncdao.ErrDaoNoDataFound
+	}
+	record := &syncmsg.ProtoRecord{}
+	err := proto.Unmarshal(recordData, record)
+	if err != nil {
+		syncutil.Error(err, ". Error unmarshaling ancestor of record", recordID)
+		return nil, err
+	}
+	return record, nil
+}
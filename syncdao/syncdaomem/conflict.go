@@ -0,0 +1,201 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+//NewConflictRepository provides access to the conflicts held by factory for a ConflictRepository.
+func NewConflictRepository(factory *MemoryDaosFactory) syncapi.ConflictRepositoryable {
+	return conflictRepositoryType{
+		store: factory.store,
+	}
+}
+
+type conflictRepositoryType struct {
+	store *store
+}
+
+func (conflictRepository conflictRepositoryType) FindConflicts(nodeID string) ([]syncapi.ConflictItem, error) {
+	conflictRepository.store.lock()
+	defer conflictRepository.store.unlock()
+	keys := []peerKey{}
+	for key := range conflictRepository.store.conflicts {
+		if key.nodeID == nodeID {
+			keys = append(keys, key)
+		}
+	}
+	conflicts := conflictRepository.store.conflicts
+	sort.Slice(keys, func(i, j int) bool {
+		left, right := conflicts[keys[i]], conflicts[keys[j]]
+		switch {
+		case !left.recordCreated.Equal(right.recordCreated):
+			return left.recordCreated.Before(right.recordCreated)
+		case keys[i].entitySingularName != keys[j].entitySingularName:
+			return keys[i].entitySingularName < keys[j].entitySingularName
+		}
+		return keys[i].recordID < keys[j].recordID
+	})
+	answer := []syncapi.ConflictItem{}
+	for _, key := range keys {
+		item, err := newConflictItem(key, conflicts[key])
+		if err != nil {
+			return answer, err
+		}
+		answer = append(answer, item)
+	}
+	return answer, nil
+}
+
+func (conflictRepository conflictRepositoryType) GetConflict(conflictID string) (syncapi.ConflictItem, error) {
+	conflictRepository.store.lock()
+	defer conflictRepository.store.unlock()
+	key, found := conflictRepository.store.findConflict(conflictID)
+	if !found {
+		return syncapi.ConflictItem{}, syncapi.ErrConflictNotFound
+	}
+	return newConflictItem(key, conflictRepository.store.conflicts[key])
+}
+
+//ResolveConflict applies the chosen version of the record locally and points the peer state at the peer's version
+//so that the next sync with the peer is a fast batch. When the kept version differs from the peer's, the record is
+//flagged as changed by the client so it is sent to the peer.
+func (conflictRepository conflictRepositoryType) ResolveConflict(conflictID string, choice syncapi.ConflictResolutionChoice, mergedRecord *syncmsg.ProtoRecord) (_ string, err error) {
+	conflictRepository.store.lock()
+	defer conflictRepository.store.release(&err)
+	key, found := conflictRepository.store.findConflict(conflictID)
+	if !found {
+		return "", syncapi.ErrConflictNotFound
+	}
+	conflict, err := newConflictItem(key, conflictRepository.store.conflicts[key])
+	if err != nil {
+		return "", err
+	}
+	var resolution syncapi.ConflictResolution
+	switch choice {
+	case syncapi.ConflictResolutionChoiceLocal:
+		resolution = syncapi.ResolveWithVersion(conflict.Local)
+	case syncapi.ConflictResolutionChoiceRemote:
+		resolution = syncapi.ResolveWithVersion(conflict.Remote)
+	case syncapi.ConflictResolutionChoiceMerged:
+		if mergedRecord == nil {
+			return "", syncapi.ErrConflictMergedRecordMissing
+		}
+		resolution = syncapi.ConflictResolution{Resolved: true, Record: mergedRecord}
+	default:
+		return "", fmt.Errorf("unknown conflict resolution choice '%v'", choice)
+	}
+
+	localRecord := localRecordState{
+		entitySingularName: conflict.EntitySingularName,
+		entityPluralName:   conflict.EntityPluralName,
+		recordID:           conflict.RecordID,
+		recordHash:         conflict.Local.RecordHash,
+		isDelete:           conflict.Local.IsDelete,
+	}
+	fieldDefinitions := conflictRepository.store.findNodeEntityFields(conflict.RemoteNodeID, conflict.EntitySingularName)
+	state, found := conflictRepository.store.states[key.recordKey]
+	if !found {
+		err = errors.New("Cannot find record '" + conflict.RecordID + "' of entity '" + conflict.EntitySingularName + "'")
+		syncutil.Error(err, ". Error reading sync state for conflict", conflictID)
+		return "", err
+	}
+	if state.recordHash != conflict.Local.RecordHash || state.isDelete != conflict.Local.IsDelete {
+		return "", syncapi.ErrConflictStale
+	}
+
+	tx := conflictRepository.store.begin()
+	recordHash := conflict.Local.RecordHash
+	switch {
+	case resolution.IsDelete && !conflict.Local.IsDelete:
+		err = eraseRecord(tx, localRecord, conflict.Local.Record, fieldDefinitions)
+	case !resolution.IsDelete:
+		var recordBytes []byte
+		recordBytes, err = proto.Marshal(resolution.Record)
+		if err != nil {
+			syncutil.Error(err, ". Error marshaling record for conflict", conflictID)
+			break
+		}
+		recordHash = hash256Bytes(recordBytes)
+		if recordHash != conflict.Local.RecordHash || conflict.Local.IsDelete {
+			err = writeRecord(tx, localRecord, resolution.Record, recordHash, recordBytes, fieldDefinitions)
+		}
+	}
+	if err != nil {
+		tx.rollback()
+		return "", err
+	}
+
+	keptRemote := resolution.IsDelete == conflict.Remote.IsDelete && (resolution.IsDelete || recordHash == conflict.Remote.RecordHash)
+	if peer, found := conflictRepository.store.peerStates[key]; found {
+		row := *peer
+		row.peerLastKnownHash = conflict.Remote.RecordHash
+		row.isConflict = false
+		row.isDelete = resolution.IsDelete
+		row.changedByClient = !keptRemote
+		row.lastUpdated = time.Now()
+		tx.putPeerState(key, &row)
+	}
+	tx.deleteConflict(key)
+	return recordHash, nil
+}
+
+//findConflict finds the key of the conflict with the given id. The caller holds the store.
+func (store *store) findConflict(conflictID string) (peerKey, bool) {
+	for key, conflict := range store.conflicts {
+		if conflict.conflictID == conflictID {
+			return key, true
+		}
+	}
+	return peerKey{}, false
+}
+
+func newConflictItem(key peerKey, row *conflictRow) (syncapi.ConflictItem, error) {
+	item := syncapi.ConflictItem{
+		ConflictID: row.conflictID,
+		SyncState:  row.syncState.String(),
+		Created:    row.recordCreated,
+	}
+	item.RemoteNodeID = key.nodeID
+	item.SessionID = row.sessionID
+	item.EntitySingularName = key.entitySingularName
+	item.EntityPluralName = row.entityPluralName
+	item.RecordID = key.recordID
+	item.ConflictingFields = append([]string{}, row.conflictingFields...)
+	item.Local.RecordHash = row.localRecordHash
+	item.Local.IsDelete = row.localIsDelete
+	item.Remote.RecordHash = row.remoteRecordHash
+	item.Remote.IsDelete = row.remoteIsDelete
+	var err error
+	item.Ancestor, err = decodeConflictRecord(row.ancestorData)
+	if err == nil {
+		item.Local.Record, err = decodeConflictRecord(row.localData)
+	}
+	if err == nil {
+		item.Remote.Record, err = decodeConflictRecord(row.remoteData)
+	}
+	if err != nil {
+		syncutil.Error(err, ". Error decoding conflict", item.ConflictID)
+		return item, err
+	}
+	return item, nil
+}
+
+func decodeConflictRecord(recordData []byte) (*syncmsg.ProtoRecord, error) {
+	if recordData == nil {
+		return nil, nil
+	}
+	record := &syncmsg.ProtoRecord{}
+	err := proto.Unmarshal(recordData, record)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,24 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncdao/syncdaotest"
+	"data-sync-tools-go/testhelper"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	syncdaotest.Run(t, func(profile string) (syncdaotest.Fixture, error) {
+		factory := NewMemoryDaosFactory()
+		err := testhelper.SetupSeededData(factory, profile)
+		fixture := syncdaotest.Fixture{
+			Daos:          factory,
+			DataRepo:      NewDataRepository(factory),
+			ConflictRepo:  NewConflictRepository(factory),
+			TombstoneRepo: NewTombstoneRepository(factory),
+			SessionRepo:   NewSessionRepository(factory),
+			NodeAdminRepo: NewNodeAdminRepository(factory),
+			Local:         factory,
+		}
+		return fixture, err
+	})
+}
@@ -0,0 +1,199 @@
+//Package syncdaomem implements core syncdao interfaces in memory, suiting tests and nodes whose sync data does not
+//need to outlive the process. The tables can be kept beyond the process by a Persistence (see NewPersistentDaosFactory),
+//which syncdaobolt does with a bbolt file.
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"errors"
+	"time"
+)
+
+//MemoryDaosFactory creates the data access objects for data synchronization held in memory. It implements
+//syncdao.DaosFactory. Since there is no schema to load, the sync model is filled through its Add methods.
+type MemoryDaosFactory struct {
+	store       *store
+	syncNodeDao syncdao.SyncNodeDao
+	syncPairDao syncdao.SyncPairDao
+}
+
+//NewMemoryDaosFactory creates a MemoryDaosFactory instance holding an empty sync model, which is gone once the process
+//ends.
+func NewMemoryDaosFactory() *MemoryDaosFactory {
+	syncutil.Info("Using Memory Mode.")
+	return newFactory(newStore())
+}
+
+//NewPersistentDaosFactory creates a MemoryDaosFactory instance holding the sync model loaded from persistence, to
+//which every change is saved before an operation returns. The factory takes over persistence, which Close closes.
+func NewPersistentDaosFactory(persistence Persistence) (*MemoryDaosFactory, error) {
+	store, err := newPersistentStore(persistence)
+	if err != nil {
+		syncutil.Error(err, ". Cannot load the sync model")
+		return nil, err
+	}
+	return newFactory(store), nil
+}
+
+func newFactory(store *store) *MemoryDaosFactory {
+	factory := new(MemoryDaosFactory)
+	factory.store = store
+	factory.syncNodeDao = SyncNodeMemoryDao{store: store}
+	factory.syncPairDao = SyncPairMemoryDao{store: store}
+	return factory
+}
+
+//SyncNodeDao provides the syncdao.SyncNodeDao instance.
+func (factory MemoryDaosFactory) SyncNodeDao() syncdao.SyncNodeDao {
+	return factory.syncNodeDao
+}
+
+//SyncPairDao provides the syncdao.SyncPairDao instance.
+func (factory MemoryDaosFactory) SyncPairDao() syncdao.SyncPairDao {
+	return factory.syncPairDao
+}
+
+//Close closes the persistence, if any. Without one there is nothing to release and the data stays available until
+//the process ends.
+func (factory MemoryDaosFactory) Close() {
+	if factory.store.persistence == nil {
+		return
+	}
+	err := factory.store.persistence.Close()
+	if err != nil {
+		syncutil.Error(err, ". Error closing the persistence")
+	}
+}
+
+//Reset removes everything from the sync model.
+func (factory MemoryDaosFactory) Reset() {
+	factory.store.lock()
+	defer factory.store.unlock()
+	factory.store.reset()
+}
+
+//AddDataVersion adds a data version (sync_data_version) to the sync model. It is the same as
+//SyncPairDao().AddDataVersion.
+func (factory MemoryDaosFactory) AddDataVersion(dataVersionName string) error {
+	return factory.syncPairDao.AddDataVersion(dataVersionName)
+}
+
+//AddEntity adds an entity (sync_data_entity) of an existing data version to the sync model. It is the same as
+//SyncPairDao().AddEntity.
+func (factory MemoryDaosFactory) AddEntity(dataVersionName string, entity syncdao.EntityPairItem) error {
+	return factory.syncPairDao.AddEntity(dataVersionName, entity)
+}
+
+//AddField adds a field (sync_data_field) to an existing entity of the data version. It is the same as
+//SyncPairDao().AddField.
+func (factory MemoryDaosFactory) AddField(dataVersionName string, entitySingularName string, field syncdao.SyncFieldDefinition) error {
+	return factory.syncPairDao.AddField(dataVersionName, entitySingularName, field)
+}
+
+//AddNode adds a node (sync_node) to the sync model. It is the same as SyncNodeDao().AddNode.
+func (factory MemoryDaosFactory) AddNode(node syncdao.SyncNode) error {
+	return factory.syncNodeDao.AddNode(node)
+}
+
+//AddPair adds a pair (sync_pair) to the sync model. It is the same as SyncPairDao().AddPair.
+func (factory MemoryDaosFactory) AddPair(pair syncdao.SyncPair) error {
+	return factory.syncPairDao.AddPair(pair)
+}
+
+//AddPairNode adds the configuration (sync_pair_nodes) of a node of a pair syncing to targetNodeID. It is the same
+//as SyncPairDao().AddPairNode.
+func (factory MemoryDaosFactory) AddPairNode(pairID string, nodeID string, targetNodeID string, seededDataVersion string, syncConflictURI string) error {
+	return factory.syncPairDao.AddPairNode(pairID, nodeID, targetNodeID, seededDataVersion, syncConflictURI)
+}
+
+//AddSyncState adds the sync state (sync_state) of a record as it would be found after the record was last changed
+//locally, without validating recordData or recordHash.
+func (factory MemoryDaosFactory) AddSyncState(entitySingularName string, recordID string, recordHash string, recordData []byte, isDelete bool) (err error) {
+	factory.store.lock()
+	defer factory.store.release(&err)
+	entity, found := factory.store.entities[entitySingularName]
+	if !found {
+		return errors.New("Cannot find entity '" + entitySingularName + "'")
+	}
+	key := recordKey{entitySingularName: entitySingularName, recordID: recordID}
+	if _, found := factory.store.states[key]; found {
+		return errors.New("Record '" + recordID + "' of entity '" + entitySingularName + "' already exists")
+	}
+	factory.store.putState(key, &stateRow{
+		dataVersionName: entity.dataVersionName,
+		recordHash:      recordHash,
+		recordData:      copyBytes(recordData),
+		recordBytesSize: len(recordData),
+		isDelete:        isDelete,
+		recordCreated:   time.Now(),
+	})
+	return nil
+}
+
+//UpdateSyncState changes the sync state (sync_state) of an existing record as the application does when it changes
+//the record locally, without validating recordData or recordHash. The change is picked up by the next queue.
+func (factory MemoryDaosFactory) UpdateSyncState(entitySingularName string, recordID string, recordHash string, recordData []byte) (err error) {
+	factory.store.lock()
+	defer factory.store.release(&err)
+	key := recordKey{entitySingularName: entitySingularName, recordID: recordID}
+	state, found := factory.store.states[key]
+	if !found {
+		return errors.New("Cannot find record '" + recordID + "' of entity '" + entitySingularName + "'")
+	}
+	row := *state
+	row.recordHash = recordHash
+	row.recordData = copyBytes(recordData)
+	row.recordBytesSize = len(recordData)
+	row.isDelete = false
+	row.deletedDate = time.Time{}
+	factory.store.putState(key, &row)
+	return nil
+}
+
+//MarkSyncStateDeleted marks the sync state (sync_state) of an existing record deleted as the application does when
+//it deletes the record locally. The delete is picked up by the next queue.
+func (factory MemoryDaosFactory) MarkSyncStateDeleted(entitySingularName string, recordID string) (err error) {
+	factory.store.lock()
+	defer factory.store.release(&err)
+	key := recordKey{entitySingularName: entitySingularName, recordID: recordID}
+	state, found := factory.store.states[key]
+	if !found {
+		return errors.New("Cannot find record '" + recordID + "' of entity '" + entitySingularName + "'")
+	}
+	row := *state
+	row.isDelete = true
+	factory.store.putState(key, &row)
+	return nil
+}
+
+//AddPeerState adds the state (sync_peer_state) of an existing record for a node as it would be found after the
+//record was last synced with the node. Empty hashes stand for hashes that are not known.
+func (factory MemoryDaosFactory) AddPeerState(nodeID string, entitySingularName string, recordID string, sentLastKnownHash string, peerLastKnownHash string, sentSyncState syncmsg.SentSyncStateEnum, changedByClient bool) (err error) {
+	factory.store.lock()
+	defer factory.store.release(&err)
+	if _, found := factory.store.nodes[nodeID]; !found {
+		return errors.New("Cannot find node '" + nodeID + "'")
+	}
+	key := peerKey{nodeID: nodeID, recordKey: recordKey{entitySingularName: entitySingularName, recordID: recordID}}
+	state, found := factory.store.states[key.recordKey]
+	if !found {
+		return errors.New("Cannot find record '" + recordID + "' of entity '" + entitySingularName + "'")
+	}
+	if _, found := factory.store.peerStates[key]; found {
+		return errors.New("Record '" + recordID + "' of entity '" + entitySingularName + "' already has a state for node '" + nodeID + "'")
+	}
+	now := time.Now()
+	factory.store.putPeerState(key, &peerStateRow{
+		sentLastKnownHash: sentLastKnownHash,
+		peerLastKnownHash: peerLastKnownHash,
+		sentSyncState:     sentSyncState,
+		isDelete:          state.isDelete,
+		changedByClient:   changedByClient,
+		recordBytesSize:   state.recordBytesSize,
+		lastUpdated:       now,
+		recordCreated:     now,
+	})
+	return nil
+}
@@ -0,0 +1,107 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncutil"
+	"errors"
+	"sort"
+)
+
+//SyncNodeMemoryDao implements the syncdao.SyncNodeDao interface as an in memory implementation.
+type SyncNodeMemoryDao struct {
+	store *store
+}
+
+//AddNode implements the syncdao.SyncNodeDao.AddNode interface as an in memory implementation. The node takes the
+//default configuration of the sql schema.
+func (dao SyncNodeMemoryDao) AddNode(item syncdao.SyncNode) (err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	if _, found := dao.store.dataVersions[item.DataVersionName]; !found {
+		err = errors.New("Cannot find data version '" + item.DataVersionName + "'")
+	} else if _, found := dao.store.nodes[item.NodeID]; found {
+		err = errors.New("Node '" + item.NodeID + "' already exists")
+	} else if _, found := dao.store.findNodeByName(item.NodeName); found {
+		err = errors.New("Node named '" + item.NodeName + "' already exists")
+	}
+	if err != nil {
+		syncutil.Error(err, ". Error inserting node, inputData=", item)
+		return err
+	}
+	dao.store.putNode(&syncdao.NodePairItem{
+		NodeID:             item.NodeID,
+		NodeName:           item.NodeName,
+		Enabled:            true,
+		InMsgBatchSize:     100,
+		MaxOutMsgBatchSize: 200,
+		InChanDepthSize:    16,
+		MaxOutChanDeptSize: 16,
+		DataVersionName:    item.DataVersionName,
+	})
+	return nil
+}
+
+//GetOneNodeByNodeName implements the syncdao.SyncNodeDao.GetOneNodeByNodeName interface as an in memory implementation.
+func (dao SyncNodeMemoryDao) GetOneNodeByNodeName(nodeName string) (syncdao.SyncNode, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	node, found := dao.store.findNodeByName(nodeName)
+	if !found {
+		syncutil.Info("No Data")
+		return syncdao.SyncNode{}, syncdao.ErrDaoNoDataFound
+	}
+	return syncdao.SyncNode{NodeID: node.NodeID, NodeName: node.NodeName, DataVersionName: node.DataVersionName}, nil
+}
+
+//GetOneNodeByNodeID implements the syncdao.SyncNodeDao.GetOneNodeByNodeID interface as an in memory implementation.
+func (dao SyncNodeMemoryDao) GetOneNodeByNodeID(nodeID string) (syncdao.SyncNode, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	node, found := dao.store.nodes[nodeID]
+	if !found {
+		syncutil.Info("No Data")
+		return syncdao.SyncNode{}, syncdao.ErrDaoNoDataFound
+	}
+	return syncdao.SyncNode{NodeID: node.NodeID, NodeName: node.NodeName, DataVersionName: node.DataVersionName}, nil
+}
+
+//DeleteNodeByNodeID implements the syncdao.SyncNodeDao.DeleteNodeByNodeID interface as an in memory implementation.
+//As with the foreign keys of the sql backends, a node still belonging to a pair or holding sync state of records
+//cannot be deleted.
+func (dao SyncNodeMemoryDao) DeleteNodeByNodeID(nodeID string) (err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	if _, found := dao.store.nodes[nodeID]; !found {
+		return syncdao.ErrDaoNoDataFound
+	}
+	for _, pairNode := range dao.store.pairNodes {
+		if pairNode.nodeID == nodeID || pairNode.targetNodeID == nodeID {
+			err = errors.New("Node '" + nodeID + "' still belongs to pair '" + pairNode.pairID + "'")
+			syncutil.Error(err, ". Error deleting node")
+			return err
+		}
+	}
+	for key := range dao.store.peerStates {
+		if key.nodeID == nodeID {
+			err = errors.New("Node '" + nodeID + "' still holds sync state of records")
+			syncutil.Error(err, ". Error deleting node")
+			return err
+		}
+	}
+	dao.store.deleteNode(nodeID)
+	return nil
+}
+
+//ListNodes implements the syncdao.SyncNodeDao.ListNodes interface as an in memory implementation.
+func (dao SyncNodeMemoryDao) ListNodes() ([]syncdao.SyncNode, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	answer := []syncdao.SyncNode{}
+	for _, node := range dao.store.nodes {
+		answer = append(answer, syncdao.SyncNode{NodeID: node.NodeID, NodeName: node.NodeName, DataVersionName: node.DataVersionName})
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].NodeName < answer[j].NodeName
+	})
+	return answer, nil
+}
@@ -0,0 +1,333 @@
+package syncdaomem
+
+import (
+	"database/sql"
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncutil"
+	"errors"
+	"time"
+)
+
+//SyncPairMemoryDao implements the syncdao.SyncPairDao interface as an in memory implementation.
+type SyncPairMemoryDao struct {
+	store *store
+}
+
+//GetPairByNames gets the SyncPair by sync node names as an in memory implementation.
+func (dao SyncPairMemoryDao) GetPairByNames(requestingNodeName string, toPairWithNodeName string) (syncdao.SyncPair, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	var syncPair syncdao.SyncPair
+	requestingNode, requestingFound := dao.store.findNodeByName(requestingNodeName)
+	toPairWithNode, toPairWithFound := dao.store.findNodeByName(toPairWithNodeName)
+	if !requestingFound || !toPairWithFound {
+		return syncPair, syncdao.ErrDaoNoDataFound
+	}
+	var rowCount int
+	for _, pairNode := range dao.store.pairNodes {
+		pair, found := dao.store.pairs[pairNode.pairID]
+		if !found {
+			continue
+		}
+		if (pairNode.nodeID == requestingNode.NodeID && pairNode.targetNodeID == toPairWithNode.NodeID) ||
+			(pairNode.nodeID == toPairWithNode.NodeID && pairNode.targetNodeID == requestingNode.NodeID) {
+			rowCount++
+			if rowCount == 1 {
+				syncPair = *pair
+			}
+		}
+	}
+	if rowCount == 0 {
+		return syncPair, syncdao.ErrDaoNoDataFound
+	} else if rowCount == 1 {
+		msg := "Only 1 record found, expected exactly 2."
+		return syncPair, errors.New(msg)
+	} else if rowCount > 2 {
+		msg := "More than 2 records found, expected exactly 2."
+		return syncPair, errors.New(msg)
+	}
+	//Note: if we're here, the row count was 2, which is correct (we had a pair)
+	return syncPair, nil
+}
+
+//GetNodePairItem gets the NodePairItem by pairId and node name as an in memory implementation.
+func (dao SyncPairMemoryDao) GetNodePairItem(pairID string, nodeName string) (syncdao.NodePairItem, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	node, found := dao.store.findNodeByName(nodeName)
+	if !found {
+		return syncdao.NodePairItem{}, syncdao.ErrDaoNoDataFound
+	}
+	if _, found := dao.store.pairs[pairID]; !found {
+		return syncdao.NodePairItem{}, syncdao.ErrDaoNoDataFound
+	}
+	for _, pairNode := range dao.store.pairNodes {
+		if pairNode.pairID == pairID && pairNode.nodeID == node.NodeID {
+			nodePairItem := *node
+			nodePairItem.DataVersionName = "-not implemented-"
+			nodePairItem.Entities = nil
+			return nodePairItem, nil
+		}
+	}
+	return syncdao.NodePairItem{}, syncdao.ErrDaoNoDataFound
+}
+
+//GetEntityPairItem retrieves the EntityPairId by pairId and node name as an in memory implementation.
+func (dao SyncPairMemoryDao) GetEntityPairItem(pairID string, nodeName string) ([]syncdao.EntityPairItem, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	var items []syncdao.EntityPairItem
+	node, found := dao.store.findNodeByName(nodeName)
+	if !found {
+		return items, nil
+	}
+	for _, entity := range dao.store.versionEntities(node.DataVersionName) {
+		items = append(items, entity.item)
+	}
+	return items, nil
+}
+
+//CreateSyncSession creates a session between sync pairs as an in memory implementation.
+func (dao SyncPairMemoryDao) CreateSyncSession(item syncdao.CreateSyncSessionRequest) (answer syncdao.CreateSyncSessionDaoResult, err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	pair, found := dao.store.pairs[item.PairID]
+	if found && pair.SyncSessionState == "Inactive" {
+		updated := *pair
+		updated.SyncSessionID = item.SessionID
+		updated.SyncSessionStart = time.Now()
+		updated.SyncSessionHeartbeat = time.Time{}
+		updated.SyncSessionState = "Initializing"
+		dao.store.putPair(&updated)
+		dao.store.resetSessionProgress(item.PairID)
+		answer = syncdao.CreateSyncSessionDaoResult{
+			Result:          "OK",
+			ActualSessionID: item.SessionID,
+		}
+		return answer, nil
+	}
+	//As with the sql backends, a pair that cannot be found or has no session id cannot be reported on
+	if !found || pair.SyncSessionID == "" {
+		return answer, sql.ErrNoRows
+	}
+	if item.SessionID == pair.SyncSessionID {
+		answer = syncdao.CreateSyncSessionDaoResult{
+			Result:          "ThisSessionIdAlreadyActive",
+			ActualSessionID: pair.SyncSessionID,
+		}
+	} else {
+		answer = syncdao.CreateSyncSessionDaoResult{
+			Result:          "DifferentSessionIdAlreadyActive",
+			ActualSessionID: pair.SyncSessionID,
+		}
+	}
+	return answer, nil
+}
+
+//CloseSyncSession closes a sync session as an in memory implementation.
+func (dao SyncPairMemoryDao) CloseSyncSession(item syncdao.CloseSyncSessionRequest) (answer syncdao.CloseSyncSessionDaoResult, err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	pair, found := dao.store.pairs[item.PairID]
+	if found && sameID(pair.SyncSessionID, item.SessionID) && pair.SyncSessionState != "Inactive" {
+		updated := *pair
+		updated.SyncSessionID = ""
+		updated.SyncSessionStart = time.Time{}
+		updated.SyncSessionHeartbeat = time.Time{}
+		updated.SyncSessionState = "Inactive"
+		dao.store.putPair(&updated)
+		answer = syncdao.CloseSyncSessionDaoResult{
+			Result:          "OK",
+			ActualSessionID: item.SessionID,
+		}
+		return answer, nil
+	}
+	if !found || pair.SyncSessionID == "" {
+		answer = syncdao.CloseSyncSessionDaoResult{
+			Result:          "ThisSessionIdAlreadyInactive",
+			ActualSessionID: "",
+		}
+	} else {
+		answer = syncdao.CloseSyncSessionDaoResult{
+			Result:          "DifferentSessionIdAlreadyActive",
+			ActualSessionID: pair.SyncSessionID,
+		}
+	}
+	return answer, nil
+}
+
+//UpdateSyncSessionState updates the sync session state as an in memory implementation.
+func (dao SyncPairMemoryDao) UpdateSyncSessionState(item syncdao.UpdateSyncSessionStateRequest) (answer syncdao.UpdateSyncSessionStateResult, err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	pair, found := dao.store.pairs[item.PairID]
+	if found && sameID(pair.SyncSessionID, item.SessionID) && pair.SyncSessionState != "Inactive" {
+		if !syncapi.IsValidSyncSessionStateTransition(pair.SyncSessionState, item.State) {
+			answer = syncdao.UpdateSyncSessionStateResult{
+				Result:             syncapi.IllegalStateTransitionResult,
+				ResultMsg:          "Cannot move session from '" + pair.SyncSessionState + "' to '" + item.State + "'",
+				RequestedSessionID: item.SessionID,
+				ActualSessionID:    pair.SyncSessionID,
+				RequestedState:     item.State,
+				ResultingState:     pair.SyncSessionState,
+			}
+			return answer, nil
+		}
+		updated := *pair
+		updated.SyncSessionState = item.State
+		dao.store.putPair(&updated)
+		answer = syncdao.UpdateSyncSessionStateResult{
+			Result:             "OK",
+			ResultMsg:          "",
+			RequestedSessionID: item.SessionID,
+			ActualSessionID:    item.SessionID,
+			RequestedState:     item.State,
+			ResultingState:     item.State,
+		}
+		return answer, nil
+	}
+	answer = syncdao.UpdateSyncSessionStateResult{
+		Result:             "CouldNoFindActiveSessionToUpdate",
+		ResultMsg:          "",
+		RequestedSessionID: item.SessionID,
+		ActualSessionID:    "",
+		RequestedState:     item.State,
+		ResultingState:     "",
+	}
+	if found && pair.SyncSessionID != "" {
+		answer.ActualSessionID = pair.SyncSessionID
+		answer.ResultingState = pair.SyncSessionState
+	}
+	return answer, nil
+}
+
+//QueryPairState queries the current pair state as an in memory implementation.
+func (dao SyncPairMemoryDao) QueryPairState(item syncdao.QueryPairStateRequest) (syncdao.QueryPairStateDaoResult, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	var answer syncdao.QueryPairStateDaoResult
+	pair, found := dao.store.pairs[item.PairID]
+	if !found {
+		syncutil.Info("No Data")
+		return answer, syncdao.ErrDaoNoDataFound
+	}
+	answer = syncdao.QueryPairStateDaoResult{
+		State:        pair.SyncSessionState,
+		SessionID:    pair.SyncSessionID,
+		SessionStart: pair.SyncSessionStart,
+		Progress:     dao.store.pairProgress(item.PairID),
+	}
+	return answer, nil
+}
+
+//AddDataVersion implements the syncdao.SyncPairDao.AddDataVersion interface as an in memory implementation.
+func (dao SyncPairMemoryDao) AddDataVersion(dataVersionName string) (err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	if _, found := dao.store.dataVersions[dataVersionName]; found {
+		return syncapi.ValidationError{Msg: "Data version '" + dataVersionName + "' already exists"}
+	}
+	dao.store.putDataVersion(dataVersionName, time.Now())
+	return nil
+}
+
+//AddEntity implements the syncdao.SyncPairDao.AddEntity interface as an in memory implementation.
+func (dao SyncPairMemoryDao) AddEntity(dataVersionName string, entity syncdao.EntityPairItem) (err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	if _, found := dao.store.dataVersions[dataVersionName]; !found {
+		return syncdao.ErrDaoNoDataFound
+	}
+	if _, found := dao.store.entities[entity.EntitySingularName]; found {
+		return syncapi.ValidationError{Msg: "Entity '" + entity.EntitySingularName + "' already exists"}
+	}
+	if _, found := dao.store.findEntityByPluralName(dataVersionName, entity.EntityPluralName); found {
+		return syncapi.ValidationError{Msg: "Entity with plural name '" + entity.EntityPluralName + "' already exists in data version '" + dataVersionName + "'"}
+	}
+	existing := []syncdao.EntityPairItem{}
+	for _, other := range dao.store.versionEntities(dataVersionName) {
+		existing = append(existing, other.item)
+	}
+	err = syncapi.ValidateEntityOrders(existing, entity)
+	if err != nil {
+		return err
+	}
+	dao.store.putEntity(&entityRow{
+		dataVersionName: dataVersionName,
+		item:            entity,
+		fields:          make(map[string]syncdao.SyncFieldDefinition),
+	})
+	return nil
+}
+
+//AddField implements the syncdao.SyncPairDao.AddField interface as an in memory implementation.
+func (dao SyncPairMemoryDao) AddField(dataVersionName string, entitySingularName string, field syncdao.SyncFieldDefinition) (err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	entity, found := dao.store.entities[entitySingularName]
+	if !found || entity.dataVersionName != dataVersionName {
+		return syncdao.ErrDaoNoDataFound
+	}
+	if _, found := entity.fields[field.FieldName]; found {
+		return syncapi.ValidationError{Msg: "Field '" + field.FieldName + "' of entity '" + entitySingularName + "' already exists"}
+	}
+	entity.fields[field.FieldName] = field
+	dao.store.putEntity(entity)
+	return nil
+}
+
+//AddPair implements the syncdao.SyncPairDao.AddPair interface as an in memory implementation. Fields left at their zero
+//value take the defaults of the sql schema, so the pair starts out 'Inactive'.
+func (dao SyncPairMemoryDao) AddPair(pair syncdao.SyncPair) (err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	if _, found := dao.store.pairs[pair.PairID]; found {
+		return syncapi.ValidationError{Msg: "Pair '" + pair.PairID + "' already exists"}
+	}
+	pair = syncapi.PairWithDefaults(pair)
+	if pair.SyncSessionState == "" {
+		pair.SyncSessionState = syncapi.SyncSessionStateInactive
+	}
+	if pair.RecordCreated.IsZero() {
+		pair.RecordCreated = time.Now()
+	}
+	dao.store.putPair(&pair)
+	return nil
+}
+
+//AddPairNode implements the syncdao.SyncPairDao.AddPairNode interface as an in memory implementation. A pair is complete
+//once both of its nodes have been added, each targeting the other.
+func (dao SyncPairMemoryDao) AddPairNode(pairID string, nodeID string, targetNodeID string, seededDataVersion string, syncConflictURI string) (err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	node := syncdao.PairNode{PairID: pairID, NodeID: nodeID, TargetNodeID: targetNodeID,
+		SeededDataVersion: seededDataVersion, SyncConflictURI: syncConflictURI}
+	pair, found := dao.store.pairs[pairID]
+	if !found {
+		return syncdao.ErrDaoNoDataFound
+	}
+	if pair.SyncSessionState != syncapi.SyncSessionStateInactive {
+		return syncapi.ErrPairSessionActive
+	}
+	for _, id := range []string{nodeID, targetNodeID} {
+		if _, found := dao.store.nodes[id]; !found {
+			return syncapi.ValidationError{Msg: "Cannot find node '" + id + "'"}
+		}
+	}
+	if _, found := dao.store.dataVersions[seededDataVersion]; seededDataVersion != "" && !found {
+		return syncapi.ValidationError{Msg: "Cannot find data version '" + seededDataVersion + "'"}
+	}
+	err = syncapi.ValidatePairMembership(dao.store.pairNodesOf(pairID), node)
+	if err != nil {
+		return err
+	}
+	dao.store.addPairNode(pairNodeRow{
+		pairID:            pairID,
+		nodeID:            nodeID,
+		targetNodeID:      targetNodeID,
+		seededDataVersion: seededDataVersion,
+		syncConflictURI:   syncConflictURI,
+	})
+	return nil
+}
@@ -0,0 +1,148 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncPairMemoryDao_GetPairByNames(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile3")
+	syncPairDao := factory.SyncPairDao()
+
+	syncPair, err := syncPairDao.GetPairByNames("A", "Z")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "*pair-1", syncPair.PairID)
+	assert.Equal(t, "A <-> Z", syncPair.PairName)
+	assert.Equal(t, 10, syncPair.MaxSesDurValue)
+	assert.Equal(t, "Inactive", syncPair.SyncSessionState)
+
+	//A <-> B only has one of its two nodes and C <-> Z is configured twice
+	_, err = syncPairDao.GetPairByNames("B", "A")
+	assert.EqualError(t, err, "Only 1 record found, expected exactly 2.")
+	_, err = syncPairDao.GetPairByNames("C", "Z")
+	assert.EqualError(t, err, "More than 2 records found, expected exactly 2.")
+	_, err = syncPairDao.GetPairByNames("A", "C")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+
+	testhelper.EndTest(testName)
+}
+
+func TestSyncPairMemoryDao_GetNodePairItem_GetEntityPairItem(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile3")
+	syncPairDao := factory.SyncPairDao()
+
+	nodePairItem, err := syncPairDao.GetNodePairItem("*pair-1", "A")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "*node-spoke1", nodePairItem.NodeID)
+	assert.True(t, nodePairItem.Enabled)
+	assert.Equal(t, 100, nodePairItem.InMsgBatchSize)
+	assert.Equal(t, 200, nodePairItem.MaxOutMsgBatchSize)
+	_, err = syncPairDao.GetNodePairItem("*pair-1", "C")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+
+	entities, err := syncPairDao.GetEntityPairItem("*pair-1", "A")
+	if !assert.Nil(t, err) {
+		return
+	}
+	names := []string{}
+	for _, entity := range entities {
+		names = append(names, entity.EntitySingularName)
+	}
+	assert.Equal(t, []string{"Entity 1", "Entity 2", "Entity 3", "Entity 4", "Entity 5", "Contact"}, names)
+	//Node B is of a data version without entities
+	entities, err = syncPairDao.GetEntityPairItem("*pair-2", "B")
+	assert.Nil(t, err)
+	assert.Empty(t, entities)
+
+	testhelper.EndTest(testName)
+}
+
+func TestSyncPairMemoryDao_CreateSyncSession_CloseSyncSession(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile3")
+	syncPairDao := factory.SyncPairDao()
+	pairID := "*pair-1"
+	sessionID := "*session-id-1"
+
+	created, err := syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "OK", created.Result)
+	assert.Equal(t, sessionID, created.ActualSessionID)
+	created, err = syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: "*session-id-2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "DifferentSessionIdAlreadyActive", created.Result)
+	assert.Equal(t, sessionID, created.ActualSessionID)
+
+	updated, err := syncPairDao.UpdateSyncSessionState(syncdao.UpdateSyncSessionStateRequest{PairID: pairID, SessionID: sessionID, State: "Seeding"})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", updated.Result)
+	syncPair, err := syncPairDao.GetPairByNames("A", "Z")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, sessionID, syncPair.SyncSessionID)
+	assert.Equal(t, "Seeding", syncPair.SyncSessionState)
+	assert.False(t, syncPair.SyncSessionStart.Equal(time.Time{}))
+
+	closed, err := syncPairDao.CloseSyncSession(syncdao.CloseSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "OK", closed.Result)
+	assert.Equal(t, sessionID, closed.ActualSessionID)
+	syncPair, err = syncPairDao.GetPairByNames("A", "Z")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "", syncPair.SyncSessionID)
+	assert.Equal(t, "Inactive", syncPair.SyncSessionState)
+	assert.True(t, syncPair.SyncSessionStart.Equal(time.Time{}))
+
+	testhelper.EndTest(testName)
+}
+
+func TestSyncNodeMemoryDao_AddNode_DeleteNode(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile3")
+	syncNodeDao := factory.SyncNodeDao()
+
+	node := syncdao.SyncNode{NodeID: "*node-new", NodeName: "N", DataVersionName: "Demo Model 1"}
+	if !assert.Nil(t, syncNodeDao.AddNode(node)) {
+		return
+	}
+	assert.NotNil(t, syncNodeDao.AddNode(node), "a node id is unique")
+	assert.NotNil(t, syncNodeDao.AddNode(syncdao.SyncNode{NodeID: "*node-other", NodeName: "N", DataVersionName: "Demo Model 1"}), "a node name is unique")
+	assert.NotNil(t, syncNodeDao.AddNode(syncdao.SyncNode{NodeID: "*node-other", NodeName: "O", DataVersionName: "Unknown Model"}), "the data version must exist")
+
+	actual, err := syncNodeDao.GetOneNodeByNodeName("N")
+	assert.Nil(t, err)
+	assert.Equal(t, node, actual)
+	actual, err = syncNodeDao.GetOneNodeByNodeID("*node-new")
+	assert.Nil(t, err)
+	assert.Equal(t, node, actual)
+
+	assert.Nil(t, syncNodeDao.DeleteNodeByNodeID("*node-new"))
+	_, err = syncNodeDao.GetOneNodeByNodeID("*node-new")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, syncNodeDao.DeleteNodeByNodeID("*node-new"))
+	assert.NotNil(t, syncNodeDao.DeleteNodeByNodeID("*node-hub"), "a paired node cannot be deleted")
+
+	testhelper.EndTest(testName)
+}
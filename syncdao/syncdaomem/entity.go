@@ -0,0 +1,59 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+)
+
+//newEntityFetcher creates an instance of the struct memoryEntityFetcher
+func newEntityFetcher(sessionID string, nodeID string, store *store) (syncapi.EntityFetching, error) {
+	fetcher := memoryEntityFetcher{
+		store: store,
+	}
+	return fetcher, nil
+}
+
+//memoryEntityFetcher logically implements EntityFetcher for the sync model held in memory.
+type memoryEntityFetcher struct {
+	store *store
+}
+
+func (fetcher memoryEntityFetcher) FindEntitiesForFetch(orderNum int, sessionID string, nodeID string, changeType syncapi.ProcessSyncChangeEnum) ([]syncapi.EntityNameItem, error) {
+	fetcher.store.lock()
+	defer fetcher.store.unlock()
+	var answer = []syncapi.EntityNameItem{}
+	node, found := fetcher.store.nodes[nodeID]
+	if !found {
+		return answer, nil
+	}
+	for _, entity := range fetcher.store.versionEntities(node.DataVersionName) {
+		processOrder := entity.item.ProcessOrderDelete
+		if changeType == syncapi.ProcessSyncChangeEnumAddOrUpdate {
+			processOrder = entity.item.ProcessOrderAddUpdate
+		}
+		if processOrder != orderNum {
+			continue
+		}
+		answer = append(answer, syncapi.EntityNameItem{
+			SingularName: entity.item.EntitySingularName,
+			PluralName:   entity.item.EntityPluralName,
+		})
+	}
+	return answer, nil
+}
+
+func (fetcher memoryEntityFetcher) FindPluralEntityNamesByID(sessionID string, nodeID string) (map[string]syncapi.EntityNameItem, error) {
+	fetcher.store.lock()
+	defer fetcher.store.unlock()
+	var answer = map[string]syncapi.EntityNameItem{}
+	node, found := fetcher.store.nodes[nodeID]
+	if !found {
+		return answer, nil
+	}
+	for _, entity := range fetcher.store.versionEntities(node.DataVersionName) {
+		answer[entity.item.EntityPluralName] = syncapi.EntityNameItem{
+			SingularName: entity.item.EntitySingularName,
+			PluralName:   entity.item.EntityPluralName,
+		}
+	}
+	return answer, nil
+}
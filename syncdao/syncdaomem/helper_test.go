@@ -0,0 +1,60 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/testhelper"
+)
+
+//newTestFactory creates a MemoryDaosFactory holding the sample data of the given profile, see
+//testhelper.SetupSeededData.
+func newTestFactory(profile string) *MemoryDaosFactory {
+	factory := NewMemoryDaosFactory()
+	err := testhelper.SetupSeededData(factory, profile)
+	if err != nil {
+		panic(err)
+	}
+	return factory
+}
+
+//updateState stands in for a local change of a record, applying change to a copy of its sync state.
+func updateState(factory *MemoryDaosFactory, entitySingularName string, recordID string, change func(state *stateRow)) {
+	factory.store.lock()
+	defer factory.store.unlock()
+	key := recordKey{entitySingularName: entitySingularName, recordID: recordID}
+	row := *factory.store.states[key]
+	change(&row)
+	factory.store.putState(key, &row)
+}
+
+//updatePeerState applies change to a copy of the state of a record for nodeID.
+func updatePeerState(factory *MemoryDaosFactory, nodeID string, entitySingularName string, recordID string, change func(peer *peerStateRow)) {
+	factory.store.lock()
+	defer factory.store.unlock()
+	key := peerKey{nodeID: nodeID, recordKey: recordKey{entitySingularName: entitySingularName, recordID: recordID}}
+	row := *factory.store.peerStates[key]
+	change(&row)
+	factory.store.putPeerState(key, &row)
+}
+
+//findPeerState gives a copy of the state of a record for nodeID, or nil when there is none.
+func findPeerState(factory *MemoryDaosFactory, nodeID string, entitySingularName string, recordID string) *peerStateRow {
+	factory.store.lock()
+	defer factory.store.unlock()
+	peer, found := factory.store.peerStates[peerKey{nodeID: nodeID, recordKey: recordKey{entitySingularName: entitySingularName, recordID: recordID}}]
+	if !found {
+		return nil
+	}
+	row := *peer
+	return &row
+}
+
+//findState gives a copy of the sync state of a record, or nil when there is none.
+func findState(factory *MemoryDaosFactory, entitySingularName string, recordID string) *stateRow {
+	factory.store.lock()
+	defer factory.store.unlock()
+	state, found := factory.store.states[recordKey{entitySingularName: entitySingularName, recordID: recordID}]
+	if !found {
+		return nil
+	}
+	row := *state
+	return &row
+}
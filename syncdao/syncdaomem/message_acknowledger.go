@@ -0,0 +1,231 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func newMessageAcknowledger(sessionID string, nodeID string, store *store) (syncapi.MessageAcknowledging, error) {
+	acknowledger := memoryMessageAcknowledger{
+		MessageAcknowledgingData: syncapi.MessageAcknowledgingData{
+			SessionID: sessionID,
+			NodeID:    nodeID,
+		},
+		store: store,
+	}
+	return acknowledger, nil
+}
+
+//memoryMessageAcknowledger logically implements MessageAcknowledging for the sync data held in memory.
+type memoryMessageAcknowledger struct {
+	syncapi.MessageAcknowledgingData
+	store *store
+}
+
+//Acknowledge applies the peer's report for every record sent under the response's TransactionBindId to the peer
+//state of the records, as the sql backends do:
+//
+// 1. the peer accepted the record -> PeerLastKnownHash becomes the ResponseHash, SentSyncState becomes
+// PersistedStandardSentToPeer and ChangedByClient is cleared. When the peer kept a different version (e.g. it merged
+// the record) and the record was not changed locally since it was sent, the peer's version is persisted locally.
+// Likewise, when the peer resolved a delete and update conflict by deleting the record, the record is deleted locally.
+//
+// 2. the peer left the record in conflict -> IsConflict is set, SentSyncState becomes PersistedStandardSentToPeer and
+// ChangedByClient is cleared. The conflict is resolved on the peer.
+//
+// 3. the TransactionBindSendId (and QueueBindSendId) of every record sent under the TransactionBindId is cleared so
+// records the peer did not report on are fetched again.
+func (acknowledger memoryMessageAcknowledger) Acknowledge(response *syncmsg.ProtoSyncEntityMessageResponse) *syncmsg.ProtoSyncEntityMessageResponse {
+	transactionBindID := response.GetTransactionBindId()
+	answer := &syncmsg.ProtoSyncEntityMessageResponse{
+		TransactionBindId: proto.String(transactionBindID),
+		Items:             []*syncmsg.ProtoSyncDataMessagesResponse{},
+	}
+	if response.GetResult() != syncmsg.SyncEntityMessageResponseResult_OK {
+		syncutil.Warn("Peer reported result", response.GetResult(), "for transactionBindId", transactionBindID, ":", response.GetResultMsg())
+	}
+	acknowledger.store.lock()
+	defer func() {
+		err := acknowledger.store.unlock()
+		if err != nil {
+			acknowledger.errorAnswer(answer, err)
+		}
+	}()
+	acknowledgedCount := 0
+	for _, item := range response.Items {
+		entity, err := acknowledger.findEntity(item.GetEntityPluralName())
+		if err != nil {
+			return acknowledger.errorAnswer(answer, err)
+		}
+		for _, msg := range item.Msgs {
+			err = acknowledger.acknowledgeRecord(entity, msg, transactionBindID)
+			if err != nil {
+				return acknowledger.errorAnswer(answer, err)
+			}
+			acknowledgedCount++
+		}
+	}
+	bound := func(peer *peerStateRow) bool {
+		return sameID(peer.transactionBindSendID, transactionBindID)
+	}
+	acknowledger.store.saveAncestors(acknowledger.NodeID, bound)
+	acknowledger.store.addAcknowledgedProgress(acknowledger.SessionID, acknowledger.NodeID, bound)
+	for _, key := range acknowledger.store.nodePeerKeys(acknowledger.NodeID, func(key peerKey, peer *peerStateRow) bool { return bound(peer) }) {
+		row := *acknowledger.store.peerStates[key]
+		row.transactionBindSendID = ""
+		row.queueBindSendID = ""
+		acknowledger.store.putPeerState(key, &row)
+	}
+	answer.Result = syncmsg.SyncEntityMessageResponseResult_OK.Enum()
+	answer.ResultMsg = proto.String(fmt.Sprintf("%v records acknowledged", acknowledgedCount))
+	return answer
+}
+
+func (acknowledger memoryMessageAcknowledger) errorAnswer(answer *syncmsg.ProtoSyncEntityMessageResponse, err error) *syncmsg.ProtoSyncEntityMessageResponse {
+	answer.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
+	answer.ResultMsg = proto.String(err.Error())
+	return answer
+}
+
+//acknowledgeIsConflict tells if the peer left the record in conflict rather than accepting a version of it.
+func acknowledgeIsConflict(msg *syncmsg.ProtoSyncDataMessageResponse) bool {
+	switch msg.GetSyncState() {
+	case syncmsg.AckSyncStateEnum_AckFieldLevelConflictwithNoAutoResolverAvailable,
+		syncmsg.AckSyncStateEnum_AckFieldLevelConflictWithNoAutoResolverResolution,
+		syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithNoAutoResolverAvailable,
+		syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithNoAutoResolution:
+		return true
+	}
+	return false
+}
+
+//acknowledgeAdoptsPeerRecord tells if the peer accepted the record but kept a version different from the one sent.
+func acknowledgeAdoptsPeerRecord(msg *syncmsg.ProtoSyncDataMessageResponse) bool {
+	return !acknowledgeIsConflict(msg) && msg.GetResponseHash() != msg.GetRequestHash() && len(msg.RecordData) != 0
+}
+
+//acknowledgeAdoptsPeerDelete tells if the peer resolved a delete and update conflict by deleting the record.
+func acknowledgeAdoptsPeerDelete(msg *syncmsg.ProtoSyncDataMessageResponse) bool {
+	return msg.GetSyncState() == syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithAutoResolution && len(msg.RecordData) == 0
+}
+
+func (acknowledger memoryMessageAcknowledger) acknowledgeRecord(entity *entityRow, msg *syncmsg.ProtoSyncDataMessageResponse, transactionBindID string) error {
+	entitySingularName := entity.item.EntitySingularName
+	recordID := msg.GetRecordId()
+	key := peerKey{nodeID: acknowledger.NodeID, recordKey: recordKey{entitySingularName: entitySingularName, recordID: recordID}}
+	peer, found := acknowledger.store.peerStates[key]
+	bound := found && sameID(peer.transactionBindSendID, transactionBindID)
+	now := time.Now()
+	if acknowledgeIsConflict(msg) {
+		if bound {
+			row := *peer
+			row.sentLastKnownHash = msg.GetRequestHash()
+			row.sentSyncState = syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer
+			row.changedByClient = false
+			row.isConflict = true
+			row.lastUpdated = now
+			acknowledger.store.putPeerState(key, &row)
+		}
+		return nil
+	}
+
+	tx := acknowledger.store.begin()
+	sentLastKnownHash := msg.GetRequestHash()
+	//peerIsDelete is only set when the acknowledgement changes the record locally
+	var peerIsDelete *bool
+	if acknowledgeAdoptsPeerRecord(msg) || acknowledgeAdoptsPeerDelete(msg) {
+		state, found := acknowledger.store.states[key.recordKey]
+		if !found {
+			err := errors.New("Cannot find record '" + recordID + "' of entity '" + entitySingularName + "'")
+			syncutil.Error(err, ". Error reading sync state for record", recordID)
+			return err
+		}
+		localRecord := localRecordState{
+			entitySingularName: entitySingularName,
+			entityPluralName:   entity.item.EntityPluralName,
+			recordID:           recordID,
+			recordHash:         state.recordHash,
+			recordData:         state.recordData,
+			isDelete:           state.isDelete,
+		}
+		fieldDefinitions := acknowledger.store.findNodeEntityFields(acknowledger.NodeID, entitySingularName)
+		switch {
+		case state.recordHash != msg.GetRequestHash():
+			syncutil.Info("Record '", recordID, "' of entity '", entitySingularName, "' changed since it was sent. Keeping the local version.")
+		case acknowledgeAdoptsPeerRecord(msg):
+			err := acknowledger.adoptPeerRecord(tx, localRecord, msg, fieldDefinitions)
+			if err != nil {
+				tx.rollback()
+				return err
+			}
+			sentLastKnownHash = msg.GetResponseHash()
+			peerIsDelete = new(bool)
+		default:
+			if !state.isDelete {
+				err := acknowledger.adoptPeerDelete(tx, localRecord, fieldDefinitions)
+				if err != nil {
+					tx.rollback()
+					return err
+				}
+			}
+			peerIsDelete = new(bool)
+			*peerIsDelete = true
+		}
+	}
+	if bound {
+		row := *peer
+		row.peerLastKnownHash = msg.GetResponseHash()
+		row.sentLastKnownHash = sentLastKnownHash
+		row.sentSyncState = syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer
+		row.changedByClient = false
+		row.isConflict = false
+		if peerIsDelete != nil {
+			row.isDelete = *peerIsDelete
+		}
+		row.lastUpdated = now
+		tx.putPeerState(key, &row)
+	}
+	return nil
+}
+
+//adoptPeerRecord persists within tx the version of the record kept by the peer.
+func (acknowledger memoryMessageAcknowledger) adoptPeerRecord(tx *storeTx, localRecord localRecordState, msg *syncmsg.ProtoSyncDataMessageResponse, fieldDefinitions map[string]syncdao.SyncFieldDefinition) error {
+	peerRecord := &syncmsg.ProtoRecord{}
+	err := proto.Unmarshal(msg.RecordData, peerRecord)
+	if err != nil {
+		syncutil.Error(err, ". Error unmarshaling peer record", localRecord.recordID)
+		return err
+	}
+	return writeRecord(tx, localRecord, peerRecord, msg.GetResponseHash(), msg.RecordData, fieldDefinitions)
+}
+
+//adoptPeerDelete deletes the record within tx as the peer did.
+func (acknowledger memoryMessageAcknowledger) adoptPeerDelete(tx *storeTx, localRecord localRecordState, fieldDefinitions map[string]syncdao.SyncFieldDefinition) error {
+	localProtoRecord := &syncmsg.ProtoRecord{}
+	err := proto.Unmarshal(localRecord.recordData, localProtoRecord)
+	if err != nil {
+		syncutil.Error(err, ". Error unmarshaling local record", localRecord.recordID)
+		return err
+	}
+	return eraseRecord(tx, localRecord, localProtoRecord, fieldDefinitions)
+}
+
+//findEntity finds an entity in the data version of the acknowledging node by its plural name.
+func (acknowledger memoryMessageAcknowledger) findEntity(entityPluralName string) (*entityRow, error) {
+	node, found := acknowledger.store.nodes[acknowledger.NodeID]
+	if found {
+		entity, found := acknowledger.store.findEntityByPluralName(node.DataVersionName, entityPluralName)
+		if found {
+			return entity, nil
+		}
+	}
+	syncutil.Error("No entity with plural name '", entityPluralName, "' for nodeId:", acknowledger.NodeID)
+	return nil, syncdao.ErrDaoNoDataFound
+}
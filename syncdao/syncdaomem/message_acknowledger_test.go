@@ -0,0 +1,72 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcknowledger_AcknowledgeFastBatch(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile5")
+	sessionID := "my-session-id-1"
+	nodeID := "*node-spoke1"
+	recordID := "911DD745-8916-41C4-9973-F8B38A501602"
+	transactionBindID := "ack-bind-id"
+
+	recordHash := findState(factory, "Contact", recordID).recordHash
+	//Simulate the record having been fetched for the peer under transactionBindID
+	updatePeerState(factory, nodeID, "Contact", recordID, func(peer *peerStateRow) {
+		peer.transactionBindSendID = transactionBindID
+		peer.changedByClient = true
+		peer.sentSyncState = syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer
+	})
+
+	acknowledger, err := newMessageAcknowledger(sessionID, nodeID, factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+	ack := &syncmsg.ProtoSyncEntityMessageResponse{
+		TransactionBindId: proto.String(transactionBindID),
+		Result:            syncmsg.SyncEntityMessageResponseResult_OK.Enum(),
+		ResultMsg:         proto.String(""),
+		Items: []*syncmsg.ProtoSyncDataMessagesResponse{
+			&syncmsg.ProtoSyncDataMessagesResponse{
+				EntityPluralName: proto.String("Contacts"),
+				Msgs: []*syncmsg.ProtoSyncDataMessageResponse{
+					&syncmsg.ProtoSyncDataMessageResponse{
+						RecordId:     proto.String(recordID),
+						RequestHash:  proto.String(recordHash),
+						ResponseHash: proto.String(recordHash),
+						SyncState:    syncmsg.AckSyncStateEnum_AckFastBatch.Enum(),
+					},
+				},
+			},
+		},
+	}
+	answer := acknowledger.Acknowledge(ack)
+	if !assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_OK, answer.GetResult()) {
+		return
+	}
+
+	peer := findPeerState(factory, nodeID, "Contact", recordID)
+	if !assert.NotNil(t, peer) {
+		return
+	}
+	assert.Equal(t, recordHash, peer.peerLastKnownHash)
+	assert.Equal(t, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, peer.sentSyncState)
+	assert.False(t, peer.changedByClient)
+	assert.Equal(t, "", peer.transactionBindSendID)
+
+	//An entity the node does not know fails the acknowledgement
+	ack.Items[0].EntityPluralName = proto.String("Unknowns")
+	answer = acknowledger.Acknowledge(ack)
+	assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_Error, answer.GetResult())
+
+	testhelper.EndTest(testName)
+}
@@ -0,0 +1,199 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"errors"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/twinj/uuid"
+)
+
+//newMessageFetcher creates a memoryMessageFetcher whose MaxMsgs is the smaller of the MaxOutMsgBatchSize and the
+//InMsgBatchSize configured for the node, unless overridden by limits.
+func newMessageFetcher(SessionID string, NodeID string, limits syncapi.FetchLimits, store *store) (syncapi.MessageFetching, error) {
+	store.lock()
+	node, found := store.nodes[NodeID]
+	store.unlock()
+	if !found {
+		msg := "Cannot find batch sizes of node '" + NodeID + "'"
+		syncutil.Error(msg)
+		return nil, errors.New(msg)
+	}
+	fetcherType := syncapi.MessageFetchingData{
+		SessionID:         SessionID,
+		NodeID:            NodeID,
+		MaxGroupBytesSize: syncapi.DefaultMaxGroupBytesSize,
+		MaxMsgs:           node.MaxOutMsgBatchSize,
+	}
+	if node.InMsgBatchSize < fetcherType.MaxMsgs {
+		fetcherType.MaxMsgs = node.InMsgBatchSize
+	}
+	fetcherType.ApplyLimits(limits)
+	if fetcherType.MaxMsgs <= 0 {
+		msg := "Node '" + NodeID + "' is not configured to send any messages"
+		syncutil.Error(msg)
+		return nil, errors.New(msg)
+	}
+	fetcher := memoryMessageFetcher{
+		MessageFetchingData: fetcherType,
+		store:               store,
+	}
+	return fetcher, nil
+}
+
+//memoryMessageFetcher logically implements MessageFetching for the sync data held in memory.
+type memoryMessageFetcher struct {
+	syncapi.MessageFetchingData
+	store *store
+}
+
+//Fetch retrieves a group of sync messages for downstream processes returning a value with no request items
+//in it should it be (Copied from SyncMessagesFetcher.Fetch() interface)
+func (fetcher memoryMessageFetcher) Fetch(entities []syncapi.EntityNameItem, changeType syncapi.ProcessSyncChangeEnum) (answer *syncmsg.ProtoRequestSyncEntityMessageResponse, err error) {
+	fetcher.store.lock()
+	defer fetcher.store.release(&err)
+	bindID := uuid.Formatter(uuid.NewV4(), uuid.FormatCanonical)
+	isDelete := changeType == syncapi.ProcessSyncChangeEnumDelete
+
+	answer = &syncmsg.ProtoRequestSyncEntityMessageResponse{}
+	request := &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(isDelete),
+		TransactionBindId: proto.String(bindID),
+		Items:             make([]*syncmsg.ProtoSyncDataMessagesRequest, 0),
+	}
+	state := fetchedState{}
+	for _, entity := range entities {
+		msgsRequest := fetcher.processEntity(&state, entity, isDelete)
+		if len(msgsRequest.Msgs) > 0 {
+			request.Items = append(request.Items, msgsRequest)
+		}
+		if state.full {
+			break
+		}
+	}
+	fetcher.markItemsWithBindID(bindID, request, entities)
+
+	answer.Request = request
+	if len(request.Items) > 0 {
+		answer.Result = syncmsg.SyncRequestEntityMessageResponseResult_HasMsgs.Enum()
+	} else {
+		answer.Result = syncmsg.SyncRequestEntityMessageResponseResult_NoMsgs.Enum()
+	}
+	answer.ResultMsg = proto.String("")
+	return answer, nil
+}
+
+//fetchedState tracks how much a fetch has gathered so far.
+type fetchedState struct {
+	processedCount      int
+	totalBytesProcessed uint32
+	full                bool
+}
+
+//processEntity gathers the queued changes of the entity until there are none left or the fetch is full. Changes are
+//reserved a batch at a time as the sql backends do, so that records reserved by one fetch are not gathered again by
+//the next.
+func (fetcher memoryMessageFetcher) processEntity(state *fetchedState, entity syncapi.EntityNameItem, isDelete bool) *syncmsg.ProtoSyncDataMessagesRequest {
+	msgsRequest := &syncmsg.ProtoSyncDataMessagesRequest{
+		EntityPluralName: proto.String(entity.PluralName),
+	}
+	for !state.full {
+		queueID := uuid.Formatter(uuid.NewV4(), uuid.FormatCanonical)
+		reserved := fetcher.reserveFetchItems(entity.SingularName, isDelete, queueID, fetcher.MaxMsgs-state.processedCount)
+		if len(reserved) == 0 {
+			break
+		}
+		for _, key := range reserved {
+			peer := fetcher.store.peerStates[key]
+			record := fetcher.store.states[key.recordKey]
+			recordBytesSize := uint32(record.recordBytesSize)
+			//A record past the limits is given back for a later fetch. The first record is always fetched, even when
+			//larger than MaxGroupBytesSize, as it could never be fetched otherwise.
+			if state.processedCount >= fetcher.MaxMsgs ||
+				(state.processedCount > 0 && state.totalBytesProcessed+recordBytesSize > uint32(fetcher.MaxGroupBytesSize)) {
+				released := *peer
+				released.queueBindSendID = ""
+				fetcher.store.putPeerState(key, &released)
+				state.full = true
+				continue
+			}
+			sentSyncState := peer.sentSyncState
+			//Deletes still carry the last record data so the peer can find the row to delete by its primary key
+			if isDelete {
+				sentSyncState = syncmsg.SentSyncStateEnum_PersistedFastDeleted
+			}
+			//If this msg has never been sent to the client, mark it as the first time sent to the client
+			if sentSyncState == syncmsg.SentSyncStateEnum_PersistedNeverSentToPeer {
+				sentSyncState = syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer
+			}
+			msg := &syncmsg.ProtoSyncDataMessageRequest{
+				RecordId:        proto.String(key.recordID),
+				RecordHash:      proto.String(record.recordHash),
+				SentSyncState:   sentSyncState.Enum(),
+				RecordBytesSize: proto.Uint32(recordBytesSize),
+				RecordData:      copyBytes(record.recordData),
+			}
+			if peer.peerLastKnownHash != "" {
+				msg.LastKnownPeerHash = proto.String(peer.peerLastKnownHash)
+			}
+			msgsRequest.Msgs = append(msgsRequest.Msgs, msg)
+			state.processedCount++
+			state.totalBytesProcessed += recordBytesSize
+		}
+		if state.processedCount >= fetcher.MaxMsgs || state.totalBytesProcessed >= uint32(fetcher.MaxGroupBytesSize) {
+			state.full = true
+		}
+	}
+	return msgsRequest
+}
+
+//reserveFetchItems reserves up to limit queued changes of the entity under queueID, ordered by record id.
+func (fetcher memoryMessageFetcher) reserveFetchItems(entitySingularName string, isDelete bool, queueID string, limit int) []peerKey {
+	if limit <= 0 {
+		return []peerKey{}
+	}
+	reserved := fetcher.store.nodePeerKeys(fetcher.NodeID, func(key peerKey, peer *peerStateRow) bool {
+		_, hasState := fetcher.store.states[key.recordKey]
+		return hasState && key.entitySingularName == entitySingularName && sameID(peer.sessionBindID, fetcher.SessionID) &&
+			peer.queueBindSendID == "" && peer.isDelete == isDelete && peer.changedByClient
+	})
+	if len(reserved) > limit {
+		reserved = reserved[:limit]
+	}
+	for _, key := range reserved {
+		row := *fetcher.store.peerStates[key]
+		row.queueBindSendID = queueID
+		fetcher.store.putPeerState(key, &row)
+	}
+	return reserved
+}
+
+//markItemsWithBindID binds the peer state of every record in request to bindID and keeps the versions sent as
+//ancestors for the record level processing of later changes.
+func (fetcher memoryMessageFetcher) markItemsWithBindID(bindID string, request *syncmsg.ProtoSyncEntityMessageRequest, entities []syncapi.EntityNameItem) {
+	if len(request.Items) == 0 {
+		return
+	}
+	entityMapByPluralName := map[string]syncapi.EntityNameItem{}
+	for _, entity := range entities {
+		entityMapByPluralName[entity.PluralName] = entity
+	}
+	for _, item := range request.Items {
+		entitySingularName := entityMapByPluralName[item.GetEntityPluralName()].SingularName
+		for _, msg := range item.Msgs {
+			key := peerKey{nodeID: fetcher.NodeID, recordKey: recordKey{entitySingularName: entitySingularName, recordID: msg.GetRecordId()}}
+			peer, found := fetcher.store.peerStates[key]
+			if !found {
+				continue
+			}
+			row := *peer
+			row.transactionBindSendID = bindID
+			fetcher.store.putPeerState(key, &row)
+		}
+	}
+	fetcher.store.saveAncestors(fetcher.NodeID, func(peer *peerStateRow) bool {
+		return sameID(peer.transactionBindSendID, bindID)
+	})
+}
@@ -0,0 +1,78 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twinj/uuid"
+)
+
+func TestSyncFetcher_TestFetcherMaxMsgs(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile3")
+	sessionUUID := uuid.Formatter(uuid.NewV4(), uuid.CleanHyphen)
+	nodeID := "*node-hub"
+
+	msgQueuer, err := newMessageQueuer(factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = msgQueuer.Queue(sessionUUID, nodeID)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	fetcher, err := newMessageFetcher(sessionUUID, nodeID, syncapi.FetchLimits{MaxGroupBytesSize: 1000, MaxMsgs: 2}, factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+	entities := []syncapi.EntityNameItem{
+		syncapi.EntityNameItem{SingularName: "Entity 1", PluralName: "Entity 1"},
+		syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+	expectedRecordIDs := [][]string{
+		[]string{"*record-1", "*record-2"},
+		[]string{"*record-3", "0934A378-DEDB-4207-B99C-DD0D61DC59BC"},
+		[]string{"911DD745-8916-41C4-9973-F8B38A501602", "B6581A36-804D-45AC-B2E2-F6DA265AF7DE"},
+		[]string{},
+	}
+	for fetchIndex, expected := range expectedRecordIDs {
+		answer, err := fetcher.Fetch(entities, syncapi.ProcessSyncChangeEnumAddOrUpdate)
+		if !assert.Nil(t, err) {
+			return
+		}
+		actual := []string{}
+		for _, item := range answer.Request.Items {
+			for _, msg := range item.Msgs {
+				actual = append(actual, msg.GetRecordId())
+			}
+		}
+		assert.Equal(t, expected, actual, "fetch %v", fetchIndex+1)
+	}
+
+	//The records fetched are bound to the transaction they were sent in and kept as ancestors
+	peer := findPeerState(factory, nodeID, "Entity 1", "*record-1")
+	if assert.NotNil(t, peer) {
+		assert.NotEqual(t, "", peer.transactionBindSendID)
+	}
+	ancestorData, found := factory.store.ancestors[ancestorKey{recordKey: recordKey{entitySingularName: "Entity 1", recordID: "*record-1"}, recordHash: "hash-1"}]
+	assert.True(t, found)
+	assert.Equal(t, []byte("<record data>"), ancestorData)
+
+	testhelper.EndTest(testName)
+}
+
+func TestSyncFetcher_TestFetcherUnknownNode(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile3")
+
+	_, err := newMessageFetcher("my-session-id-1", "*node-unknown", syncapi.FetchLimits{}, factory.store)
+	assert.NotNil(t, err)
+
+	testhelper.EndTest(testName)
+}
@@ -0,0 +1,392 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+//newMessageProcessor creates an instance of the struct memoryMessageProcessor
+func newMessageProcessor(sessionID string, nodeID string, entitiesByPluralName map[string]syncapi.EntityNameItem, store *store) (syncapi.MessageProcessing, error) {
+	processorType := syncapi.MessageProcessingData{
+		SessionID:            sessionID,
+		NodeID:               nodeID,
+		EntitiesByPluralName: entitiesByPluralName,
+	}
+	processor := memoryMessageProcessor{
+		MessageProcessingData: processorType,
+		store:                 store,
+	}
+	return processor, nil
+}
+
+//memoryMessageProcessor logically implements MessageProcessor for the sync data held in memory.
+type memoryMessageProcessor struct {
+	syncapi.MessageProcessingData
+	store *store
+}
+
+//localRecordState is the local state of a record in a request that the fast batch could not persist.
+type localRecordState struct {
+	entitySingularName string
+	entityPluralName   string
+	recordID           string
+	recordHash         string
+	recordData         []byte
+	isDelete           bool
+}
+
+//Process transforms request data into persisted data synced to the local model while resolving or reporting
+//conflicts according to the sync policy for the session. It works as the sql backends do: the request is first
+//persisted as a batch assuming there are no conflicts ('AckFastBatch'), then the records the batch could not persist
+//are processed one at a time (see recordLevelProcessLoop).
+func (processor memoryMessageProcessor) Process(request *syncmsg.ProtoSyncEntityMessageRequest) *syncmsg.ProtoSyncEntityMessageResponse {
+	answer := &syncmsg.ProtoSyncEntityMessageResponse{
+		TransactionBindId: request.TransactionBindId,
+		Items:             []*syncmsg.ProtoSyncDataMessagesResponse{},
+	}
+	processor.store.lock()
+	defer func() {
+		err := processor.store.unlock()
+		if err != nil {
+			answer.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
+			answer.ResultMsg = proto.String(err.Error())
+		}
+	}()
+	node, found := processor.store.nodes[processor.NodeID]
+	if !found {
+		msg := "Cannot find data version of node '" + processor.NodeID + "'"
+		syncutil.Error(msg)
+		answer.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
+		answer.ResultMsg = proto.String(msg)
+		return answer
+	}
+	items := request.Items
+	if request.GetIsDelete() {
+		items = processor.orderItemsForDelete(items, node.DataVersionName)
+	}
+	transactionBindID := request.GetTransactionBindId()
+	err := processor.applyFastBatch(items, request.GetIsDelete(), node.DataVersionName, transactionBindID)
+	if err != nil {
+		answer.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
+		answer.ResultMsg = proto.String(err.Error())
+		return answer
+	}
+	unprocessedMsgs := processor.readFastBatch(items, node.DataVersionName, transactionBindID, answer)
+	resultMsg := "All records are fast batch"
+	if len(unprocessedMsgs) != 0 {
+		syncutil.Debug("Not Fast Batch Items: ", unprocessedMsgs)
+		err = processor.recordLevelProcessLoop(items, request.GetIsDelete(), transactionBindID, unprocessedMsgs, answer)
+		if err != nil {
+			syncutil.Error(err)
+			answer.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
+			answer.ResultMsg = proto.String(err.Error())
+			return answer
+		}
+		resultMsg = "Some records processed at record level"
+	}
+	processor.store.saveAncestors(processor.NodeID, func(peer *peerStateRow) bool {
+		return sameID(peer.transactionBindReceiveID, transactionBindID)
+	})
+	processor.store.addProcessedProgress(processor.SessionID, processor.NodeID, request)
+	answer.Result = syncmsg.SyncEntityMessageResponseResult_OK.Enum()
+	answer.ResultMsg = proto.String(resultMsg)
+	return answer
+}
+
+//orderItemsForDelete gives the items of a delete request sorted by the ProcOrderDelete of their entity so that rows
+//referencing others are deleted first. Items of entities with the same order keep their position in the request.
+func (processor memoryMessageProcessor) orderItemsForDelete(items []*syncmsg.ProtoSyncDataMessagesRequest, dataVersionName string) []*syncmsg.ProtoSyncDataMessagesRequest {
+	procOrderDelete := make(map[string]int)
+	for _, entity := range processor.store.versionEntities(dataVersionName) {
+		procOrderDelete[entity.item.EntityPluralName] = entity.item.ProcessOrderDelete
+	}
+	answer := make([]*syncmsg.ProtoSyncDataMessagesRequest, len(items))
+	copy(answer, items)
+	sort.SliceStable(answer, func(i, j int) bool {
+		return procOrderDelete[answer[i].GetEntityPluralName()] < procOrderDelete[answer[j].GetEntityPluralName()]
+	})
+	return answer
+}
+
+//applyFastBatch persists every message of the request whose record was not changed locally since the peer last
+//knew of it. Either all of the request is applied or, on error, none of it.
+func (processor memoryMessageProcessor) applyFastBatch(items []*syncmsg.ProtoSyncDataMessagesRequest, isDelete bool, dataVersionName string, transactionBindID string) error {
+	now := time.Now()
+	tx := processor.store.begin()
+	for _, item := range items {
+		entity, found := processor.store.findEntityByPluralName(dataVersionName, item.GetEntityPluralName())
+		if !found {
+			msg := "Cannot find singular form of entity from plural name '" + item.GetEntityPluralName() + "' and data version '" + dataVersionName + "'"
+			syncutil.Error(msg)
+			tx.rollback()
+			return errors.New(msg)
+		}
+		for _, msg := range item.Msgs {
+			var err error
+			switch {
+			case isDelete:
+				err = processor.fastBatchDelete(tx, entity, msg, transactionBindID, now)
+			case msg.GetSentSyncState() == syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer:
+				err = processor.fastBatchInsert(tx, entity, msg, transactionBindID, now)
+			case msg.GetSentSyncState() == syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer:
+				err = processor.fastBatchUpdate(tx, entity, msg, transactionBindID, now)
+			default:
+				err = errors.New("Unsupported sentSyncState " + msg.GetSentSyncState().String())
+			}
+			if err != nil {
+				syncutil.Error(err)
+				tx.rollback()
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//fastBatchInsert persists a record sent to this node for the first time.
+func (processor memoryMessageProcessor) fastBatchInsert(tx *storeTx, entity *entityRow, msg *syncmsg.ProtoSyncDataMessageRequest, transactionBindID string, now time.Time) error {
+	record := &syncmsg.ProtoRecord{}
+	err := proto.Unmarshal(msg.RecordData, record)
+	if err != nil {
+		return err
+	}
+	err = checkRecord(record, entity.fields, entity.item.EntitySingularName, recordCheckInsert)
+	if err != nil {
+		return err
+	}
+	key := processor.peerKey(entity.item.EntitySingularName, msg.GetRecordId())
+	if _, found := processor.store.states[key.recordKey]; found {
+		return errors.New("Record '" + msg.GetRecordId() + "' of entity '" + entity.item.EntitySingularName + "' already exists")
+	}
+	if _, found := processor.store.peerStates[key]; found {
+		return errors.New("Record '" + msg.GetRecordId() + "' of entity '" + entity.item.EntitySingularName + "' already has a state for node '" + processor.NodeID + "'")
+	}
+	tx.putState(key.recordKey, &stateRow{
+		dataVersionName: entity.dataVersionName,
+		recordHash:      msg.GetRecordHash(),
+		recordData:      copyBytes(msg.RecordData),
+		recordBytesSize: int(msg.GetRecordBytesSize()),
+		recordCreated:   now,
+	})
+	tx.putPeerState(key, &peerStateRow{
+		transactionBindReceiveID: transactionBindID,
+		sentLastKnownHash:        msg.GetRecordHash(),
+		peerLastKnownHash:        msg.GetRecordHash(),
+		sentSyncState:            msg.GetSentSyncState(),
+		recordBytesSize:          int(msg.GetRecordBytesSize()),
+		lastUpdated:              now,
+		recordCreated:            now,
+	})
+	return nil
+}
+
+//fastBatchUpdate persists a changed record, unless the local record is no longer the one the peer last knew of.
+func (processor memoryMessageProcessor) fastBatchUpdate(tx *storeTx, entity *entityRow, msg *syncmsg.ProtoSyncDataMessageRequest, transactionBindID string, now time.Time) error {
+	record := &syncmsg.ProtoRecord{}
+	err := proto.Unmarshal(msg.RecordData, record)
+	if err != nil {
+		syncutil.Debug(err)
+		return err
+	}
+	err = checkRecord(record, entity.fields, entity.item.EntitySingularName, recordCheckUpdate)
+	if err != nil {
+		syncutil.Debug(err)
+		return err
+	}
+	key := processor.peerKey(entity.item.EntitySingularName, msg.GetRecordId())
+	state, found := processor.store.states[key.recordKey]
+	if !found || state.recordHash != msg.GetLastKnownPeerHash() {
+		return nil
+	}
+	if peer, found := processor.store.peerStates[key]; found {
+		row := *peer
+		row.transactionBindReceiveID = transactionBindID
+		row.peerLastKnownHash = msg.GetRecordHash()
+		row.lastUpdated = now
+		tx.putPeerState(key, &row)
+	}
+	row := *state
+	row.recordHash = msg.GetRecordHash()
+	row.recordData = copyBytes(msg.RecordData)
+	row.recordBytesSize = int(msg.GetRecordBytesSize())
+	row.isDelete = false
+	row.deletedDate = time.Time{}
+	tx.putState(key.recordKey, &row)
+	return nil
+}
+
+//fastBatchDelete marks a record deleted, unless the local record is no longer the one the peer last knew of. The
+//delete has to carry the primary key of the record, as it does for the sql backends to find the row to delete.
+func (processor memoryMessageProcessor) fastBatchDelete(tx *storeTx, entity *entityRow, msg *syncmsg.ProtoSyncDataMessageRequest, transactionBindID string, now time.Time) error {
+	record := &syncmsg.ProtoRecord{}
+	err := proto.Unmarshal(msg.RecordData, record)
+	if err != nil {
+		syncutil.Debug(err)
+		return err
+	}
+	keyCount, recordKeyCount := 0, 0
+	for _, fieldDefinition := range entity.fields {
+		if fieldDefinition.IsPrimaryKey {
+			keyCount++
+		}
+	}
+	for _, field := range record.Fields {
+		if entity.fields[field.GetFieldName()].IsPrimaryKey {
+			recordKeyCount++
+		}
+	}
+	if keyCount == 0 || recordKeyCount != keyCount {
+		errMsg := "Delete of record '" + msg.GetRecordId() + "' does not carry the primary key of entity " + entity.item.EntitySingularName
+		syncutil.Error(errMsg)
+		return errors.New(errMsg)
+	}
+	err = checkRecord(record, entity.fields, entity.item.EntitySingularName, recordCheckDelete)
+	if err != nil {
+		syncutil.Error(err)
+		return err
+	}
+	key := processor.peerKey(entity.item.EntitySingularName, msg.GetRecordId())
+	state, found := processor.store.states[key.recordKey]
+	if !found || state.recordHash != msg.GetLastKnownPeerHash() {
+		return nil
+	}
+	if peer, found := processor.store.peerStates[key]; found {
+		row := *peer
+		row.transactionBindReceiveID = transactionBindID
+		row.peerLastKnownHash = msg.GetRecordHash()
+		row.isDelete = true
+		row.lastUpdated = now
+		tx.putPeerState(key, &row)
+	}
+	row := *state
+	row.isDelete = true
+	row.deletedDate = now
+	tx.putState(key.recordKey, &row)
+	return nil
+}
+
+//readFastBatch adds an 'AckFastBatch' response for every record of the request the fast batch persisted and gives
+//the local state of the others by entity singular name. Records without local state are left out, as the sql
+//backends leave them out.
+func (processor memoryMessageProcessor) readFastBatch(items []*syncmsg.ProtoSyncDataMessagesRequest, dataVersionName string, transactionBindID string, response *syncmsg.ProtoSyncEntityMessageResponse) map[string][]localRecordState {
+	unprocessedMsgs := make(map[string][]localRecordState)
+	read := make(map[recordKey]bool)
+	for _, item := range items {
+		entity, _ := processor.store.findEntityByPluralName(dataVersionName, item.GetEntityPluralName())
+		for _, msg := range item.Msgs {
+			key := processor.peerKey(entity.item.EntitySingularName, msg.GetRecordId())
+			if read[key.recordKey] {
+				continue
+			}
+			read[key.recordKey] = true
+			state, found := processor.store.states[key.recordKey]
+			if !found {
+				continue
+			}
+			peer, found := processor.store.peerStates[key]
+			if !found {
+				continue
+			}
+			if sameID(peer.transactionBindReceiveID, transactionBindID) {
+				response.Items = append(response.Items, &syncmsg.ProtoSyncDataMessagesResponse{
+					EntityPluralName: proto.String(entity.item.EntityPluralName),
+					Msgs: []*syncmsg.ProtoSyncDataMessageResponse{
+						&syncmsg.ProtoSyncDataMessageResponse{
+							RecordId:     proto.String(key.recordID),
+							RequestHash:  proto.String(state.recordHash),
+							ResponseHash: proto.String(state.recordHash),
+							SyncState:    syncmsg.AckSyncStateEnum_AckFastBatch.Enum(),
+						},
+					},
+				})
+				continue
+			}
+			unprocessedMsgs[entity.item.EntitySingularName] = append(unprocessedMsgs[entity.item.EntitySingularName], localRecordState{
+				entitySingularName: entity.item.EntitySingularName,
+				entityPluralName:   entity.item.EntityPluralName,
+				recordID:           key.recordID,
+				recordHash:         state.recordHash,
+				recordData:         state.recordData,
+				isDelete:           state.isDelete,
+			})
+		}
+	}
+	return unprocessedMsgs
+}
+
+func (processor memoryMessageProcessor) peerKey(entitySingularName string, recordID string) peerKey {
+	return peerKey{nodeID: processor.NodeID, recordKey: recordKey{entitySingularName: entitySingularName, recordID: recordID}}
+}
+
+//recordCheck is how much of a record is required to persist it, standing in for the statements run against the custom
+//table of the sql backends.
+type recordCheck int
+
+const (
+	//recordCheckInsert requires at least one field.
+	recordCheckInsert recordCheck = iota
+	//recordCheckUpdate requires the primary key fields and at least one other field.
+	recordCheckUpdate
+	//recordCheckDelete requires the primary key fields only.
+	recordCheckDelete
+)
+
+//checkRecord rejects a record missing the fields required by check, holding fields unknown to the entity or holding
+//a date that cannot be parsed. The fields of deletes other than the primary key fields are not looked at.
+func checkRecord(record *syncmsg.ProtoRecord, fieldDefinitions map[string]syncdao.SyncFieldDefinition, entitySingularName string, check recordCheck) error {
+	keyCount, otherCount := 0, 0
+	for _, field := range record.Fields {
+		if fieldDefinitions[field.GetFieldName()].IsPrimaryKey {
+			keyCount++
+		} else {
+			otherCount++
+		}
+	}
+	switch {
+	case check == recordCheckInsert && keyCount+otherCount == 0:
+		return errors.New("record of entity " + entitySingularName + " does not contain any fields")
+	case check != recordCheckInsert && keyCount == 0:
+		return errors.New("record of entity " + entitySingularName + " does not contain its primary key fields")
+	case check == recordCheckUpdate && otherCount == 0:
+		return errors.New("record of entity " + entitySingularName + " does not contain any non key fields")
+	}
+	for _, field := range record.Fields {
+		if check == recordCheckDelete && !fieldDefinitions[field.GetFieldName()].IsPrimaryKey {
+			continue
+		}
+		err := checkField(field, fieldDefinitions, entitySingularName)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//checkField rejects a field unknown to the entity or a date that cannot be parsed.
+func checkField(field *syncmsg.ProtoField, fieldDefinitions map[string]syncdao.SyncFieldDefinition, syncEntityName string) error {
+	fieldName := field.GetFieldName()
+	fieldDefinition := fieldDefinitions[fieldName]
+	if fieldDefinition.FieldType == syncdao.SyncFieldTypeEnumUndefined {
+		return errors.New("field " + fieldName + " does  not match a known field definition for entity " + syncEntityName)
+	}
+	if fieldDefinition.FieldType != syncdao.SyncFieldTypeEnumDate {
+		return nil
+	}
+	value := syncdao.CalculateValue(field.GetEncodedFieldType(), field.FieldValue)
+	creator := syncmsg.NewCreator()
+	creator.FormatTimeFromString(fmt.Sprintf("%v", value))
+	if len(creator.Errors) != 0 {
+		err := syncmsg.NewCreatorError(creator.Errors)
+		syncutil.Error(err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,463 @@
+package syncdaomem
+
+import (
+	"crypto/sha256"
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/twinj/uuid"
+)
+
+//recordLevelOutcome is the result of processing a single record outside of the fast batch.
+type recordLevelOutcome struct {
+	syncState    syncmsg.AckSyncStateEnum
+	responseHash string
+	recordData   []byte
+}
+
+//recordLevelProcessLoop processes, one record at a time, the messages the fast batch could not persist. A record
+//lands here when the local record hash no longer matches the LastKnownPeerHash sent by the peer, meaning both sides
+//changed the record since they last synced.
+func (processor memoryMessageProcessor) recordLevelProcessLoop(items []*syncmsg.ProtoSyncDataMessagesRequest, isDelete bool, transactionBindID string, unprocessedMsgs map[string][]localRecordState, response *syncmsg.ProtoSyncEntityMessageResponse) error {
+	requestMsgs := make(map[string]map[string]*syncmsg.ProtoSyncDataMessageRequest)
+	for _, item := range items {
+		entityPluralName := item.GetEntityPluralName()
+		if _, ok := requestMsgs[entityPluralName]; !ok {
+			requestMsgs[entityPluralName] = make(map[string]*syncmsg.ProtoSyncDataMessageRequest)
+		}
+		for _, msg := range item.Msgs {
+			requestMsgs[entityPluralName][msg.GetRecordId()] = msg
+		}
+	}
+
+	entitySingularNames := make([]string, 0, len(unprocessedMsgs))
+	for entitySingularName := range unprocessedMsgs {
+		entitySingularNames = append(entitySingularNames, entitySingularName)
+	}
+	sort.Strings(entitySingularNames)
+
+	resolver, err := processor.findConflictResolver()
+	if err != nil {
+		return err
+	}
+
+	for _, entitySingularName := range entitySingularNames {
+		fieldDefinitions := processor.store.findNodeEntityFields(processor.NodeID, entitySingularName)
+		var entityResponse *syncmsg.ProtoSyncDataMessagesResponse
+		for _, localRecord := range unprocessedMsgs[entitySingularName] {
+			msg := requestMsgs[localRecord.entityPluralName][localRecord.recordID]
+			outcome, err := processor.processRecordLevel(localRecord, msg, isDelete, transactionBindID, fieldDefinitions, resolver)
+			if err != nil {
+				syncutil.Error(err)
+				return err
+			}
+			if entityResponse == nil {
+				entityResponse = &syncmsg.ProtoSyncDataMessagesResponse{
+					EntityPluralName: proto.String(localRecord.entityPluralName),
+					Msgs:             []*syncmsg.ProtoSyncDataMessageResponse{},
+				}
+				response.Items = append(response.Items, entityResponse)
+			}
+			msgResponse := &syncmsg.ProtoSyncDataMessageResponse{
+				RecordId:     proto.String(localRecord.recordID),
+				RequestHash:  proto.String(msg.GetRecordHash()),
+				ResponseHash: proto.String(outcome.responseHash),
+				SyncState:    outcome.syncState.Enum(),
+			}
+			if outcome.recordData != nil {
+				msgResponse.RecordBytesSize = proto.Uint32(uint32(len(outcome.recordData)))
+				msgResponse.RecordData = outcome.recordData
+			}
+			entityResponse.Msgs = append(entityResponse.Msgs, msgResponse)
+		}
+	}
+	return nil
+}
+
+//processRecordLevel compares the incoming record against the local one and either persists the combined result or
+//hands the record to the pair's conflict resolver. Logically it works as follows:
+//
+// 1. both sides hold the same content or both deleted it -> nothing changes, AckFastBatch
+//
+// 2. the changes since the common ancestor (the version identified by LastKnownPeerHash) touch different fields ->
+// persist the merged record, AckRecordLevelConflictResolvedSeparateFieldsChanged. When the ancestor is no longer
+// kept, which side changed a field cannot be told, so every field holding different values conflicts (see
+// syncmsg.MergeRecords)
+//
+// 3. the changes overlap on at least one field, or one side deleted the record while the other updated it -> see
+// resolveRecordConflict
+func (processor memoryMessageProcessor) processRecordLevel(localRecord localRecordState, msg *syncmsg.ProtoSyncDataMessageRequest, isDelete bool, transactionBindID string, fieldDefinitions map[string]syncdao.SyncFieldDefinition, resolver syncapi.ConflictResolver) (recordLevelOutcome, error) {
+	bothDeleted := localRecord.isDelete && isDelete
+	if bothDeleted || (!localRecord.isDelete && !isDelete && msg.GetRecordHash() == localRecord.recordHash) {
+		processor.markRecordReceived(localRecord, localRecord.recordHash, transactionBindID)
+		return recordLevelOutcome{
+			syncState:    syncmsg.AckSyncStateEnum_AckFastBatch,
+			responseHash: localRecord.recordHash,
+		}, nil
+	}
+
+	local := &syncmsg.ProtoRecord{}
+	err := proto.Unmarshal(localRecord.recordData, local)
+	if err != nil {
+		syncutil.Error(err, ". Error unmarshaling local record", localRecord.recordID)
+		return recordLevelOutcome{}, err
+	}
+	remote := &syncmsg.ProtoRecord{}
+	err = proto.Unmarshal(msg.RecordData, remote)
+	if err != nil {
+		syncutil.Error(err, ". Error unmarshaling incoming record", localRecord.recordID)
+		return recordLevelOutcome{}, err
+	}
+
+	ancestor, err := processor.store.findAncestorRecord(localRecord.entitySingularName, localRecord.recordID, msg.GetLastKnownPeerHash())
+	if err == syncdao.ErrDaoNoDataFound {
+		syncutil.Debug("No ancestor kept for record", localRecord.recordID, "with hash", msg.GetLastKnownPeerHash(), "merging without it")
+	} else if err != nil {
+		return recordLevelOutcome{}, err
+	}
+
+	conflict := syncapi.RecordConflict{
+		SessionID:          processor.SessionID,
+		RemoteNodeID:       processor.NodeID,
+		EntitySingularName: localRecord.entitySingularName,
+		EntityPluralName:   localRecord.entityPluralName,
+		RecordID:           localRecord.recordID,
+		ConflictingFields:  []string{},
+		Ancestor:           ancestor,
+		Local: syncapi.ConflictRecordVersion{
+			RecordHash: localRecord.recordHash,
+			IsDelete:   localRecord.isDelete,
+			Record:     local,
+		},
+		Remote: syncapi.ConflictRecordVersion{
+			RecordHash: msg.GetRecordHash(),
+			IsDelete:   isDelete,
+			Record:     remote,
+		},
+	}
+	if !conflict.IsDeleteAndUpdate() {
+		merged, conflictingFields := syncmsg.MergeRecords(ancestor, local, remote)
+		if len(conflictingFields) == 0 {
+			mergedHash, mergedBytes, err := processor.applyRecord(localRecord, merged, transactionBindID, fieldDefinitions)
+			if err != nil {
+				return recordLevelOutcome{}, err
+			}
+			return recordLevelOutcome{
+				syncState:    syncmsg.AckSyncStateEnum_AckRecordLevelConflictResolvedSeparateFieldsChanged,
+				responseHash: mergedHash,
+				recordData:   mergedBytes,
+			}, nil
+		}
+		conflict.ConflictingFields = conflictingFields
+	}
+	return processor.resolveRecordConflict(conflict, localRecord, transactionBindID, fieldDefinitions, resolver)
+}
+
+//resolveRecordConflict consults the pair's conflict resolver (if any) for a record changed on both sides and
+//reports one of the following:
+//
+//  CONFLICT            RESOLVER                 LOGICAL ENUM
+//  --------            --------                 ------------
+//  field level         none configured          AckFieldLevelConflictwithNoAutoResolverAvailable
+//  field level         resolved                 AckFieldLevelConflictResolvedWithAutoResolver
+//  field level         did not resolve          AckFieldLevelConflictWithNoAutoResolverResolution
+//  delete and update   none configured          AckDeleteAndUpdateConflictWithNoAutoResolverAvailable
+//  delete and update   did not resolve          AckDeleteAndUpdateConflictWithNoAutoResolution
+//  delete and update   resolved                 AckDeleteAndUpdateConflictWithAutoResolution
+//
+//The store is not held while the resolver decides, as a resolver may call out to another service. What was processed
+//so far is saved before the store is given up.
+func (processor memoryMessageProcessor) resolveRecordConflict(conflict syncapi.RecordConflict, localRecord localRecordState, transactionBindID string, fieldDefinitions map[string]syncdao.SyncFieldDefinition, resolver syncapi.ConflictResolver) (recordLevelOutcome, error) {
+	noResolverState := syncmsg.AckSyncStateEnum_AckFieldLevelConflictwithNoAutoResolverAvailable
+	unresolvedState := syncmsg.AckSyncStateEnum_AckFieldLevelConflictWithNoAutoResolverResolution
+	resolvedState := syncmsg.AckSyncStateEnum_AckFieldLevelConflictResolvedWithAutoResolver
+	if conflict.IsDeleteAndUpdate() {
+		noResolverState = syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithNoAutoResolverAvailable
+		unresolvedState = syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithNoAutoResolution
+		resolvedState = syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithAutoResolution
+	}
+	syncutil.Info("Record '", conflict.RecordID, "' of entity '", conflict.EntitySingularName, "' conflicts. Conflicting fields:", conflict.ConflictingFields, "delete and update:", conflict.IsDeleteAndUpdate())
+
+	unresolvedOutcome := recordLevelOutcome{
+		syncState:    noResolverState,
+		responseHash: localRecord.recordHash,
+		recordData:   copyBytes(localRecord.recordData),
+	}
+	if resolver != nil {
+		unresolvedOutcome.syncState = unresolvedState
+		err := processor.store.unlock()
+		if err != nil {
+			processor.store.lock()
+			return recordLevelOutcome{}, err
+		}
+		resolution, err := resolver.Resolve(conflict)
+		processor.store.lock()
+		if err != nil {
+			syncutil.Error(err, ". Conflict resolver failed for record", conflict.RecordID, "leaving it unresolved")
+		} else if resolution.Resolved {
+			responseHash, recordData, err := processor.applyResolution(conflict, localRecord, resolution, transactionBindID, fieldDefinitions)
+			if err != nil {
+				return recordLevelOutcome{}, err
+			}
+			return recordLevelOutcome{
+				syncState:    resolvedState,
+				responseHash: responseHash,
+				recordData:   recordData,
+			}, nil
+		}
+	}
+	err := processor.markRecordConflict(conflict, localRecord, unresolvedOutcome.syncState, transactionBindID)
+	if err != nil {
+		return recordLevelOutcome{}, err
+	}
+	return unresolvedOutcome, nil
+}
+
+//applyResolution persists the outcome chosen by a conflict resolver and gives the resulting record hash and data.
+func (processor memoryMessageProcessor) applyResolution(conflict syncapi.RecordConflict, localRecord localRecordState, resolution syncapi.ConflictResolution, transactionBindID string, fieldDefinitions map[string]syncdao.SyncFieldDefinition) (string, []byte, error) {
+	if resolution.IsDelete {
+		if localRecord.isDelete {
+			processor.markRecordReceived(localRecord, localRecord.recordHash, transactionBindID)
+			return localRecord.recordHash, nil, nil
+		}
+		err := processor.deleteRecord(localRecord, conflict.Local.Record, transactionBindID, fieldDefinitions)
+		return localRecord.recordHash, nil, err
+	}
+	if resolution.Record == nil {
+		msg := "Conflict resolution for record '" + localRecord.recordID + "' of entity '" + localRecord.entitySingularName + "' keeps the record but does not supply it"
+		syncutil.Error(msg)
+		return "", nil, errors.New(msg)
+	}
+	return processor.applyRecord(localRecord, resolution.Record, transactionBindID, fieldDefinitions)
+}
+
+//findConflictResolver creates the ConflictResolver configured for the pair the session belongs to. The node level
+//conflict uri of the pair's nodes takes precedence over the pair level one. A nil ConflictResolver is given when
+//there is no active pair for the session or neither uri names a resolver. A uri stored before it was refused by
+//syncapi.ValidatePair, such as the former 'builtin:lastWriterWins', leaves the conflicts for manual resolution rather
+//than failing the sync.
+func (processor memoryMessageProcessor) findConflictResolver() (syncapi.ConflictResolver, error) {
+	for _, pairNode := range processor.store.pairNodes {
+		pair, found := processor.store.pairs[pairNode.pairID]
+		if !found || !sameID(pair.SyncSessionID, processor.SessionID) || pairNode.nodeID != processor.NodeID {
+			continue
+		}
+		conflictURI := pair.SyncConflictURI
+		if pairNode.syncConflictURI != "" && pairNode.syncConflictURI != syncapi.ConflictResolverURINone {
+			conflictURI = pairNode.syncConflictURI
+		}
+		resolver, err := syncapi.NewConflictResolver(conflictURI)
+		if err != nil {
+			syncutil.Error(err, ". Leaving the conflicts of session", processor.SessionID, "for manual resolution")
+			return nil, nil
+		}
+		return resolver, nil
+	}
+	return nil, nil
+}
+
+//hash256Bytes turns bytes into a sha256Hex string value
+func hash256Bytes(recordBytes []byte) string {
+	hasher := sha256.New()
+	hasher.Write(recordBytes)
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+//markRecordConflict records that the peer's version of the record was received but could not be applied and keeps
+//both versions for manual resolution.
+func (processor memoryMessageProcessor) markRecordConflict(conflict syncapi.RecordConflict, localRecord localRecordState, syncState syncmsg.AckSyncStateEnum, transactionBindID string) error {
+	key := processor.peerKey(localRecord.entitySingularName, localRecord.recordID)
+	tx := processor.store.begin()
+	if peer, found := processor.store.peerStates[key]; found {
+		row := *peer
+		row.transactionBindReceiveID = transactionBindID
+		row.isConflict = true
+		row.lastUpdated = time.Now()
+		tx.putPeerState(key, &row)
+	}
+	err := saveConflict(tx, key, conflict, syncState)
+	if err != nil {
+		tx.rollback()
+		return err
+	}
+	return nil
+}
+
+//markRecordReceived records that the peer's version of the record was received and that the peer now knows the
+//record by peerLastKnownHash.
+func (processor memoryMessageProcessor) markRecordReceived(localRecord localRecordState, peerLastKnownHash string, transactionBindID string) {
+	key := processor.peerKey(localRecord.entitySingularName, localRecord.recordID)
+	peer, found := processor.store.peerStates[key]
+	if !found {
+		return
+	}
+	row := *peer
+	row.transactionBindReceiveID = transactionBindID
+	row.peerLastKnownHash = peerLastKnownHash
+	row.isConflict = false
+	row.lastUpdated = time.Now()
+	processor.store.putPeerState(key, &row)
+}
+
+//applyRecord persists record to the sync state and peer state in one transaction and gives the new record hash and
+//data.
+func (processor memoryMessageProcessor) applyRecord(localRecord localRecordState, record *syncmsg.ProtoRecord, transactionBindID string, fieldDefinitions map[string]syncdao.SyncFieldDefinition) (string, []byte, error) {
+	recordBytes, err := proto.Marshal(record)
+	if err != nil {
+		syncutil.Error(err, ". Error marshaling record", localRecord.recordID)
+		return "", nil, err
+	}
+	recordHash := hash256Bytes(recordBytes)
+	if recordHash == localRecord.recordHash && !localRecord.isDelete {
+		processor.markRecordReceived(localRecord, recordHash, transactionBindID)
+		return recordHash, recordBytes, nil
+	}
+
+	tx := processor.store.begin()
+	err = writeRecord(tx, localRecord, record, recordHash, recordBytes, fieldDefinitions)
+	if err != nil {
+		tx.rollback()
+		return "", nil, err
+	}
+	key := processor.peerKey(localRecord.entitySingularName, localRecord.recordID)
+	if peer, found := processor.store.peerStates[key]; found {
+		row := *peer
+		row.transactionBindReceiveID = transactionBindID
+		row.peerLastKnownHash = recordHash
+		row.isConflict = false
+		row.isDelete = false
+		row.lastUpdated = time.Now()
+		tx.putPeerState(key, &row)
+	}
+	return recordHash, recordBytes, nil
+}
+
+//deleteRecord marks the record deleted in the sync state and peer state in one transaction.
+func (processor memoryMessageProcessor) deleteRecord(localRecord localRecordState, localProtoRecord *syncmsg.ProtoRecord, transactionBindID string, fieldDefinitions map[string]syncdao.SyncFieldDefinition) error {
+	tx := processor.store.begin()
+	err := eraseRecord(tx, localRecord, localProtoRecord, fieldDefinitions)
+	if err != nil {
+		tx.rollback()
+		return err
+	}
+	key := processor.peerKey(localRecord.entitySingularName, localRecord.recordID)
+	if peer, found := processor.store.peerStates[key]; found {
+		row := *peer
+		row.transactionBindReceiveID = transactionBindID
+		row.peerLastKnownHash = localRecord.recordHash
+		row.isConflict = false
+		row.isDelete = true
+		row.lastUpdated = time.Now()
+		tx.putPeerState(key, &row)
+	}
+	return nil
+}
+
+//writeRecord persists record to the sync state within tx. The write is guarded by the local hash read earlier so a
+//concurrent local change is not overwritten.
+func writeRecord(tx *storeTx, localRecord localRecordState, record *syncmsg.ProtoRecord, recordHash string, recordBytes []byte, fieldDefinitions map[string]syncdao.SyncFieldDefinition) error {
+	err := checkRecord(record, fieldDefinitions, localRecord.entitySingularName, recordCheckUpdate)
+	if err != nil {
+		syncutil.Error(err)
+		return err
+	}
+	key := recordKey{entitySingularName: localRecord.entitySingularName, recordID: localRecord.recordID}
+	state, err := checkRecordUnchanged(tx, localRecord)
+	if err != nil {
+		return err
+	}
+	row := *state
+	row.recordHash = recordHash
+	row.recordData = copyBytes(recordBytes)
+	row.recordBytesSize = len(recordBytes)
+	row.isDelete = false
+	row.deletedDate = time.Time{}
+	tx.putState(key, &row)
+	return nil
+}
+
+//eraseRecord marks the record deleted in the sync state within tx. The primary key fields of localProtoRecord are
+//validated as the sql backends use them to find the row to delete.
+func eraseRecord(tx *storeTx, localRecord localRecordState, localProtoRecord *syncmsg.ProtoRecord, fieldDefinitions map[string]syncdao.SyncFieldDefinition) error {
+	err := checkRecord(localProtoRecord, fieldDefinitions, localRecord.entitySingularName, recordCheckDelete)
+	if err != nil {
+		syncutil.Error(err)
+		return err
+	}
+	key := recordKey{entitySingularName: localRecord.entitySingularName, recordID: localRecord.recordID}
+	state, err := checkRecordUnchanged(tx, localRecord)
+	if err != nil {
+		return err
+	}
+	row := *state
+	row.isDelete = true
+	row.deletedDate = time.Now()
+	tx.putState(key, &row)
+	return nil
+}
+
+//checkRecordUnchanged gives the sync state of the record, provided it still has the hash read earlier.
+func checkRecordUnchanged(tx *storeTx, localRecord localRecordState) (*stateRow, error) {
+	state, found := tx.store.states[recordKey{entitySingularName: localRecord.entitySingularName, recordID: localRecord.recordID}]
+	if !found || state.recordHash != localRecord.recordHash {
+		msg := "Record '" + localRecord.recordID + "' of entity '" + localRecord.entitySingularName + "' changed while being processed"
+		syncutil.Error(msg)
+		return nil, errors.New(msg)
+	}
+	return state, nil
+}
+
+//saveConflict keeps both versions of a conflicting record within tx. A conflict already recorded for the same node
+//and record is replaced, keeping its conflict id.
+func saveConflict(tx *storeTx, key peerKey, conflict syncapi.RecordConflict, syncState syncmsg.AckSyncStateEnum) error {
+	ancestorData, err := encodeConflictRecord(conflict.Ancestor)
+	if err != nil {
+		syncutil.Error(err, ". Error marshaling ancestor of record", conflict.RecordID)
+		return err
+	}
+	localData, err := encodeConflictRecord(conflict.Local.Record)
+	if err != nil {
+		syncutil.Error(err, ". Error marshaling local record", conflict.RecordID)
+		return err
+	}
+	remoteData, err := encodeConflictRecord(conflict.Remote.Record)
+	if err != nil {
+		syncutil.Error(err, ". Error marshaling remote record", conflict.RecordID)
+		return err
+	}
+	conflictID := uuid.Formatter(uuid.NewV4(), uuid.FormatCanonical)
+	if previous, found := tx.store.conflicts[key]; found {
+		conflictID = previous.conflictID
+	}
+	tx.putConflict(key, &conflictRow{
+		conflictID:        conflictID,
+		sessionID:         conflict.SessionID,
+		entityPluralName:  conflict.EntityPluralName,
+		syncState:         syncState,
+		conflictingFields: append([]string{}, conflict.ConflictingFields...),
+		ancestorData:      ancestorData,
+		localRecordHash:   conflict.Local.RecordHash,
+		localIsDelete:     conflict.Local.IsDelete,
+		localData:         localData,
+		remoteRecordHash:  conflict.Remote.RecordHash,
+		remoteIsDelete:    conflict.Remote.IsDelete,
+		remoteData:        remoteData,
+		recordCreated:     time.Now(),
+	})
+	return nil
+}
+
+//encodeConflictRecord gives the record as bytes, or nil when there is no record.
+func encodeConflictRecord(record *syncmsg.ProtoRecord) ([]byte, error) {
+	if record == nil {
+		return nil, nil
+	}
+	return proto.Marshal(record)
+}
@@ -0,0 +1,315 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessor_ProcessorFastBatchOK(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile5")
+	sessionID := "my-session-id-1"
+	nodeIDToProcess := "*node-spoke1"
+
+	entitiesByPluralName := map[string]syncapi.EntityNameItem{
+		"Contacts": syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+	msgProcessor, err := newMessageProcessor(sessionID, nodeIDToProcess, entitiesByPluralName, factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	creator := syncmsg.NewCreator()
+	lastContact4 := testhelper.Contact{ContactID: "0934A378-DEDB-4207-B99C-DD0D61DC59BC", DateOfBirthAsUTC: creator.FormatTimeFromString("1987-05-28 00:00:00.000"),
+		FirstName: "Mindy", HeightFt: 5, HeightInch: 1.0, LastName: "Johnson", PreferredHeight: 2}
+	lastContactSyncPackage4, err := testhelper.CreateRecordAndSupport(lastContact4, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	contact4 := testhelper.Contact{ContactID: "0934A378-DEDB-4207-B99C-DD0D61DC59BC", DateOfBirthAsUTC: creator.FormatTimeFromString("1987-05-28 00:00:00.000"),
+		FirstName: "Mindy", HeightFt: 5, HeightInch: 2.0, LastName: "Johnson", PreferredHeight: 1}
+	contactSyncPackage4, err := testhelper.CreateRecordAndSupport(contact4, true, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, lastContactSyncPackage4.RecordSha256Hex)
+	if !assert.Nil(t, err) {
+		return
+	}
+	contact6 := testhelper.Contact{ContactID: "151EFA13-A3AD-4C18-A2CE-9D66D0AED112", DateOfBirthAsUTC: creator.FormatTimeFromString("1988-01-23 00:00:00.000"),
+		FirstName: "Jill", HeightFt: 5, HeightInch: 3.0, LastName: "Anderson", PreferredHeight: 1}
+	contactSyncPackage6, err := testhelper.CreateRecordAndSupport(contact6, true, syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer, "")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	request1 := &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(false),
+		TransactionBindId: proto.String("some-bind-id"),
+		Items: []*syncmsg.ProtoSyncDataMessagesRequest{
+			&syncmsg.ProtoSyncDataMessagesRequest{
+				EntityPluralName: proto.String("Contacts"),
+				Msgs: []*syncmsg.ProtoSyncDataMessageRequest{
+					&syncmsg.ProtoSyncDataMessageRequest{
+						RecordId:          proto.String(contact4.ContactID),
+						RecordHash:        proto.String(contactSyncPackage4.RecordSha256Hex),
+						LastKnownPeerHash: proto.String(contactSyncPackage4.PeerLastKnownHash),
+						SentSyncState:     syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer.Enum(),
+						RecordBytesSize:   proto.Uint32(123),
+						RecordData:        contactSyncPackage4.RecordBytes,
+					},
+					&syncmsg.ProtoSyncDataMessageRequest{
+						RecordId:          proto.String(contact6.ContactID),
+						RecordHash:        proto.String(contactSyncPackage6.RecordSha256Hex),
+						LastKnownPeerHash: nil,
+						SentSyncState:     syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer.Enum(),
+						RecordBytesSize:   proto.Uint32(123),
+						RecordData:        contactSyncPackage6.RecordBytes,
+					},
+				},
+			},
+		},
+	}
+	response1 := msgProcessor.Process(request1)
+	syncutil.Debug("response1", response1)
+
+	assert.Equal(t, "All records are fast batch", response1.GetResultMsg())
+	for _, contactSyncPackage := range []testhelper.ContactSyncPackage{contactSyncPackage4, contactSyncPackage6} {
+		state := findState(factory, "Contact", contactSyncPackage.Contact.ContactID)
+		if assert.NotNil(t, state) {
+			assert.Equal(t, contactSyncPackage.RecordSha256Hex, state.recordHash)
+			assert.Equal(t, contactSyncPackage.RecordBytes, state.recordData)
+		}
+		peer := findPeerState(factory, nodeIDToProcess, "Contact", contactSyncPackage.Contact.ContactID)
+		if assert.NotNil(t, peer) {
+			assert.Equal(t, contactSyncPackage.RecordSha256Hex, peer.peerLastKnownHash)
+		}
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestProcessor_ProcessorRecordLevelConflict(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile5")
+	sessionID := "my-session-id-1"
+	nodeIDToProcess := "*node-spoke1"
+
+	entitiesByPluralName := map[string]syncapi.EntityNameItem{
+		"Contacts": syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+	msgProcessor, err := newMessageProcessor(sessionID, nodeIDToProcess, entitiesByPluralName, factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	creator := syncmsg.NewCreator()
+	//Locally (see profile5) 'Adins' was changed to 'Adkins' after the peer last saw the record. The peer changed
+	//the height instead, so the record reaches the record level path.
+	lastContact2 := testhelper.Contact{ContactID: "911DD745-8916-41C4-9973-F8B38A501602", DateOfBirthAsUTC: creator.FormatTimeFromString("1994-07-10 00:00:00.000"),
+		FirstName: "Henry", HeightFt: 5, HeightInch: 5.5, LastName: "Adins", PreferredHeight: 2}
+	lastContactSyncPackage2, err := testhelper.CreateRecordAndSupport(lastContact2, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	contact2 := testhelper.Contact{ContactID: "911DD745-8916-41C4-9973-F8B38A501602", DateOfBirthAsUTC: creator.FormatTimeFromString("1994-07-10 00:00:00.000"),
+		FirstName: "Henry", HeightFt: 6, HeightInch: 5.5, LastName: "Adins", PreferredHeight: 2}
+	contactSyncPackage2, err := testhelper.CreateRecordAndSupport(contact2, true, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, lastContactSyncPackage2.RecordSha256Hex)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	request1 := newContactRequest(false, contactSyncPackage2, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer)
+	response1 := msgProcessor.Process(request1)
+	syncutil.Debug("response1", response1)
+
+	if !assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_OK, response1.GetResult()) {
+		return
+	}
+	if !assert.Len(t, response1.Items, 1) || !assert.Len(t, response1.Items[0].Msgs, 1) {
+		return
+	}
+	assert.Equal(t, syncmsg.AckSyncStateEnum_AckFieldLevelConflictwithNoAutoResolverAvailable, response1.Items[0].Msgs[0].GetSyncState())
+	peer := findPeerState(factory, nodeIDToProcess, "Contact", contact2.ContactID)
+	if assert.NotNil(t, peer) {
+		assert.True(t, peer.isConflict)
+	}
+
+	conflictRepo := NewConflictRepository(factory)
+	conflicts, err := conflictRepo.FindConflicts(nodeIDToProcess)
+	if !assert.Nil(t, err) || !assert.Len(t, conflicts, 1) {
+		return
+	}
+	assert.Equal(t, contact2.ContactID, conflicts[0].RecordID)
+	assert.Equal(t, contactSyncPackage2.RecordSha256Hex, conflicts[0].Remote.RecordHash)
+
+	recordHash, err := conflictRepo.ResolveConflict(conflicts[0].ConflictID, syncapi.ConflictResolutionChoiceRemote, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, contactSyncPackage2.RecordSha256Hex, recordHash)
+	state := findState(factory, "Contact", contact2.ContactID)
+	if assert.NotNil(t, state) {
+		assert.Equal(t, contactSyncPackage2.RecordSha256Hex, state.recordHash)
+	}
+	peer = findPeerState(factory, nodeIDToProcess, "Contact", contact2.ContactID)
+	if assert.NotNil(t, peer) {
+		assert.False(t, peer.isConflict)
+		assert.False(t, peer.changedByClient, "the remote version kept needs no sending back")
+	}
+	_, err = conflictRepo.GetConflict(conflicts[0].ConflictID)
+	assert.Equal(t, syncapi.ErrConflictNotFound, err)
+
+	testhelper.EndTest(testName)
+}
+
+func TestProcessor_ProcessorRecordLevelMerge(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile5")
+	sessionID := "my-session-id-1"
+	nodeIDToProcess := "*node-spoke1"
+
+	entitiesByPluralName := map[string]syncapi.EntityNameItem{
+		"Contacts": syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+	msgProcessor, err := newMessageProcessor(sessionID, nodeIDToProcess, entitiesByPluralName, factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	creator := syncmsg.NewCreator()
+	//Locally (see profile5) 'Adins' was changed to 'Adkins' after the peer last saw the record. The peer changed
+	//the height instead. With the common ancestor kept, the separate changes are merged.
+	lastContact2 := testhelper.Contact{ContactID: "911DD745-8916-41C4-9973-F8B38A501602", DateOfBirthAsUTC: creator.FormatTimeFromString("1994-07-10 00:00:00.000"),
+		FirstName: "Henry", HeightFt: 5, HeightInch: 5.5, LastName: "Adins", PreferredHeight: 2}
+	lastContactSyncPackage2, err := testhelper.CreateRecordAndSupport(lastContact2, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	contact2 := testhelper.Contact{ContactID: "911DD745-8916-41C4-9973-F8B38A501602", DateOfBirthAsUTC: creator.FormatTimeFromString("1994-07-10 00:00:00.000"),
+		FirstName: "Henry", HeightFt: 6, HeightInch: 5.5, LastName: "Adins", PreferredHeight: 2}
+	contactSyncPackage2, err := testhelper.CreateRecordAndSupport(contact2, true, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, lastContactSyncPackage2.RecordSha256Hex)
+	if !assert.Nil(t, err) {
+		return
+	}
+	mergedContact2 := testhelper.Contact{ContactID: "911DD745-8916-41C4-9973-F8B38A501602", DateOfBirthAsUTC: creator.FormatTimeFromString("1994-07-10 00:00:00.000"),
+		FirstName: "Henry", HeightFt: 6, HeightInch: 5.5, LastName: "Adkins", PreferredHeight: 2}
+	mergedContactSyncPackage2, err := testhelper.CreateRecordAndSupport(mergedContact2, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	ancestor := ancestorKey{recordKey: recordKey{entitySingularName: "Contact", recordID: lastContact2.ContactID}, recordHash: lastContactSyncPackage2.RecordSha256Hex}
+	factory.store.ancestors[ancestor] = lastContactSyncPackage2.RecordBytes
+
+	request1 := newContactRequest(false, contactSyncPackage2, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer)
+	response1 := msgProcessor.Process(request1)
+	syncutil.Debug("response1", response1)
+
+	if !assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_OK, response1.GetResult()) {
+		return
+	}
+	if !assert.Len(t, response1.Items, 1) || !assert.Len(t, response1.Items[0].Msgs, 1) {
+		return
+	}
+	assert.Equal(t, syncmsg.AckSyncStateEnum_AckRecordLevelConflictResolvedSeparateFieldsChanged, response1.Items[0].Msgs[0].GetSyncState())
+	state := findState(factory, "Contact", contact2.ContactID)
+	if assert.NotNil(t, state) {
+		assert.Equal(t, mergedContactSyncPackage2.RecordSha256Hex, state.recordHash, "the contact is 'Adkins' with height 6")
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestProcessor_ProcessorFastDelete(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile5")
+	sessionID := "my-session-id-1"
+	nodeIDToProcess := "*node-spoke1"
+
+	entitiesByPluralName := map[string]syncapi.EntityNameItem{
+		"Contacts": syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+	msgProcessor, err := newMessageProcessor(sessionID, nodeIDToProcess, entitiesByPluralName, factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	creator := syncmsg.NewCreator()
+	//The peer deletes the contact both sides last agreed on (see profile5)
+	contact4 := testhelper.Contact{ContactID: "0934A378-DEDB-4207-B99C-DD0D61DC59BC", DateOfBirthAsUTC: creator.FormatTimeFromString("1987-05-28 00:00:00.000"),
+		FirstName: "Mindy", HeightFt: 5, HeightInch: 1.0, LastName: "Johnson", PreferredHeight: 2}
+	contactSyncPackage4, err := testhelper.CreateRecordAndSupport(contact4, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	contactSyncPackage4.PeerLastKnownHash = contactSyncPackage4.RecordSha256Hex
+
+	request1 := newContactRequest(true, contactSyncPackage4, syncmsg.SentSyncStateEnum_PersistedFastDeleted)
+	response1 := msgProcessor.Process(request1)
+	syncutil.Debug("response1", response1)
+
+	if !assert.Equal(t, "All records are fast batch", response1.GetResultMsg()) {
+		return
+	}
+	state := findState(factory, "Contact", contact4.ContactID)
+	peer := findPeerState(factory, nodeIDToProcess, "Contact", contact4.ContactID)
+	if assert.NotNil(t, state) && assert.NotNil(t, peer) {
+		assert.True(t, state.isDelete)
+		assert.True(t, peer.isDelete)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestProcessor_ProcessorUnknownNodeDataVersion(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile5")
+
+	entitiesByPluralName := map[string]syncapi.EntityNameItem{
+		"Contacts": syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+	msgProcessor, err := newMessageProcessor("my-session-id-1", "*node-unknown", entitiesByPluralName, factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+	request := &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(false),
+		TransactionBindId: proto.String("some-bind-id"),
+		Items:             []*syncmsg.ProtoSyncDataMessagesRequest{},
+	}
+	response := msgProcessor.Process(request)
+	assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_Error, response.GetResult(), "a node without a data version cannot be processed")
+
+	testhelper.EndTest(testName)
+}
+
+//newContactRequest creates a request sending the contact of contactSyncPackage.
+func newContactRequest(isDelete bool, contactSyncPackage testhelper.ContactSyncPackage, sentSyncState syncmsg.SentSyncStateEnum) *syncmsg.ProtoSyncEntityMessageRequest {
+	msg := &syncmsg.ProtoSyncDataMessageRequest{
+		RecordId:        proto.String(contactSyncPackage.Contact.ContactID),
+		RecordHash:      proto.String(contactSyncPackage.RecordSha256Hex),
+		SentSyncState:   sentSyncState.Enum(),
+		RecordBytesSize: proto.Uint32(123),
+		RecordData:      contactSyncPackage.RecordBytes,
+	}
+	if contactSyncPackage.PeerLastKnownHash != "" {
+		msg.LastKnownPeerHash = proto.String(contactSyncPackage.PeerLastKnownHash)
+	}
+	return &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(isDelete),
+		TransactionBindId: proto.String("some-bind-id"),
+		Items: []*syncmsg.ProtoSyncDataMessagesRequest{
+			&syncmsg.ProtoSyncDataMessagesRequest{
+				EntityPluralName: proto.String("Contacts"),
+				Msgs:             []*syncmsg.ProtoSyncDataMessageRequest{msg},
+			},
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"errors"
+	"time"
+)
+
+func newMessageQueuer(store *store) (syncapi.MessageQueuing, error) {
+	queuer := memoryMessageQueuer{
+		store: store,
+	}
+	return queuer, nil
+}
+
+//memoryMessageQueuer logically implements MessageQueuing for the sync data held in memory.
+type memoryMessageQueuer struct {
+	store *store
+}
+
+//Queue implements the syncapi.MessageQueuing interface as an in memory implementation.
+func (queuer memoryMessageQueuer) Queue(sessionID string, nodeIDToQueue string) (answer int, err error) {
+	queuer.store.lock()
+	defer queuer.store.release(&err)
+	if _, found := queuer.store.nodes[nodeIDToQueue]; !found {
+		err = errors.New("Cannot find node '" + nodeIDToQueue + "'")
+		syncutil.Error(err, ". Error queuing with nodeIdToQueue:", nodeIDToQueue)
+		return 0, err
+	}
+	now := time.Now()
+	for key, state := range queuer.store.states {
+		//For queuing previously unqueued records
+		nodeKey := peerKey{nodeID: nodeIDToQueue, recordKey: key}
+		if _, found := queuer.store.peerStates[nodeKey]; !found && !state.isDelete {
+			queuer.store.putPeerState(nodeKey, &peerStateRow{
+				sentLastKnownHash: state.recordHash,
+				sentSyncState:     syncmsg.SentSyncStateEnum_PersistedNeverSentToPeer,
+				changedByClient:   true,
+				recordBytesSize:   state.recordBytesSize,
+				recordCreated:     now,
+			})
+		}
+		//Records deleted by the application do not stamp the deleted date, so the first queue after the delete does
+		if state.isDelete && state.deletedDate.IsZero() {
+			row := *state
+			row.deletedDate = now
+			queuer.store.putState(key, &row)
+		}
+	}
+	for key, peer := range queuer.store.peerStates {
+		if key.nodeID != nodeIDToQueue {
+			continue
+		}
+		row := *peer
+		state, found := queuer.store.states[key.recordKey]
+		switch {
+		case !found:
+		case state.isDelete && !row.isDelete:
+			//A peer never sent the record has nothing to delete
+			row.isDelete = true
+			row.changedByClient = row.sentSyncState != syncmsg.SentSyncStateEnum_PersistedNeverSentToPeer
+		case !row.changedByClient && row.sentLastKnownHash != "" && row.sentLastKnownHash != state.recordHash:
+			//A record changed since it was last sent
+			row.changedByClient = true
+		}
+		if row.changedByClient {
+			row.sessionBindID = sessionID
+			answer++
+		}
+		if row != *peer {
+			queuer.store.putPeerState(key, &row)
+		}
+	}
+	queuer.store.setQueuedTotals(sessionID, nodeIDToQueue)
+	return answer, nil
+}
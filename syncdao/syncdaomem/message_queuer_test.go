@@ -0,0 +1,99 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueuer_QueueOK(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile3")
+	nodeIDToQueue := "*node-spoke1"
+	sessionID := "my-session-id-1"
+
+	msgQueuer, err := newMessageQueuer(factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+	recordsQueued, err := msgQueuer.Queue(sessionID, nodeIDToQueue)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 5, recordsQueued)
+	peer := findPeerState(factory, nodeIDToQueue, "Entity 1", "*record-1")
+	if assert.NotNil(t, peer) {
+		assert.Equal(t, sessionID, peer.sessionBindID)
+		assert.Equal(t, "hash-1", peer.sentLastKnownHash)
+	}
+
+	for _, key := range factory.store.nodePeerKeys(nodeIDToQueue, func(key peerKey, peer *peerStateRow) bool { return true }) {
+		updatePeerState(factory, nodeIDToQueue, key.entitySingularName, key.recordID, func(peer *peerStateRow) {
+			peer.sessionBindID = ""
+			peer.changedByClient = false
+		})
+	}
+	updateState(factory, "Entity 1", "*record-2", func(state *stateRow) { state.recordHash = "hash-2b" })
+	updateState(factory, "Entity 1", "*record-3", func(state *stateRow) { state.recordHash = "hash-3b" })
+
+	recordsQueued, err = msgQueuer.Queue(sessionID, nodeIDToQueue)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 3, recordsQueued)
+
+	_, err = msgQueuer.Queue(sessionID, "*node-unknown")
+	assert.NotNil(t, err)
+
+	testhelper.EndTest(testName)
+}
+
+func TestQueuer_QueueDeletes(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile3")
+	nodeIDToQueue := "*node-spoke1"
+	sessionID := "my-session-id-1"
+
+	msgQueuer, err := newMessageQueuer(factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = msgQueuer.Queue(sessionID, nodeIDToQueue)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	//*record-2 was sent to the peer before being deleted, *record-3 never was
+	for _, recordID := range []string{"*record-2", "*record-3"} {
+		updatePeerState(factory, nodeIDToQueue, "Entity 1", recordID, func(peer *peerStateRow) {
+			peer.sessionBindID = ""
+			peer.changedByClient = false
+			if recordID == "*record-2" {
+				peer.sentSyncState = syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer
+			}
+		})
+		updateState(factory, "Entity 1", recordID, func(state *stateRow) { state.isDelete = true })
+	}
+
+	_, err = msgQueuer.Queue(sessionID, nodeIDToQueue)
+	if !assert.Nil(t, err) {
+		return
+	}
+	expectedChangedByClient := map[string]bool{"*record-2": true, "*record-3": false}
+	for recordID, expected := range expectedChangedByClient {
+		peer := findPeerState(factory, nodeIDToQueue, "Entity 1", recordID)
+		if !assert.NotNil(t, peer) {
+			return
+		}
+		assert.True(t, peer.isDelete, "record '%v' is queued as deleted", recordID)
+		assert.Equal(t, expected, peer.changedByClient, "ChangedByClient of record '%v'", recordID)
+		assert.False(t, findState(factory, "Entity 1", recordID).deletedDate.IsZero(), "the deleted date of record '%v' is stamped", recordID)
+	}
+
+	testhelper.EndTest(testName)
+}
@@ -0,0 +1,61 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncmsg"
+	"sort"
+)
+
+//NewNodeAdminRepository provides access to the sync data held by factory for a NodeAdminRepository.
+func NewNodeAdminRepository(factory *MemoryDaosFactory) syncapi.NodeAdminRepositoryable {
+	return nodeAdminRepositoryType{
+		store: factory.store,
+	}
+}
+
+type nodeAdminRepositoryType struct {
+	store *store
+}
+
+//PendingChanges counts the records of every entity the way Queue would find them changed.
+func (nodeAdminRepository nodeAdminRepositoryType) PendingChanges(nodeID string) (syncapi.NodePendingChanges, error) {
+	store := nodeAdminRepository.store
+	store.lock()
+	defer store.unlock()
+	answer := syncapi.NodePendingChanges{NodeID: nodeID, Entities: []syncapi.PendingChangeCount{}}
+	if _, found := store.nodes[nodeID]; !found {
+		return answer, syncapi.ErrNodeNotFound
+	}
+	counts := map[string]*syncapi.PendingChangeCount{}
+	for key, state := range store.states {
+		entity, found := store.entities[key.entitySingularName]
+		if !found {
+			continue
+		}
+		count, found := counts[entity.item.EntityPluralName]
+		if !found {
+			count = &syncapi.PendingChangeCount{EntityPluralName: entity.item.EntityPluralName}
+			counts[entity.item.EntityPluralName] = count
+		}
+		peer, known := store.peerStates[peerKey{nodeID: nodeID, recordKey: key}]
+		switch {
+		case !state.isDelete && (!known || peer.changedByClient || (peer.sentLastKnownHash != "" && peer.sentLastKnownHash != state.recordHash)):
+			count.AddedOrUpdated++
+		case state.isDelete && known && ((peer.isDelete && peer.changedByClient) ||
+			(!peer.isDelete && peer.sentSyncState != syncmsg.SentSyncStateEnum_PersistedNeverSentToPeer)):
+			count.Deleted++
+		}
+		if known && (peer.transactionBindSendID != "" || peer.queueBindSendID != "") {
+			count.InFlight++
+		}
+	}
+	entityPluralNames := make([]string, 0, len(counts))
+	for entityPluralName := range counts {
+		entityPluralNames = append(entityPluralNames, entityPluralName)
+	}
+	sort.Strings(entityPluralNames)
+	for _, entityPluralName := range entityPluralNames {
+		answer.Add(*counts[entityPluralName])
+	}
+	return answer, nil
+}
@@ -0,0 +1,175 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"sort"
+)
+
+//pairNodesOf gives the sync_pair_nodes rows of the pair ordered by NodeID. The caller holds the store.
+func (store *store) pairNodesOf(pairID string) []syncdao.PairNode {
+	answer := []syncdao.PairNode{}
+	for _, row := range store.pairNodes {
+		if row.pairID == pairID {
+			answer = append(answer, syncdao.PairNode{
+				PairID:            row.pairID,
+				NodeID:            row.nodeID,
+				TargetNodeID:      row.targetNodeID,
+				SeededDataVersion: row.seededDataVersion,
+				SyncConflictURI:   row.syncConflictURI,
+			})
+		}
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].NodeID < answer[j].NodeID
+	})
+	return answer
+}
+
+//inactivePair gives the pair pairID when it has no active session. The caller holds the store.
+func (store *store) inactivePair(pairID string) (*syncdao.SyncPair, error) {
+	pair, found := store.pairs[pairID]
+	if !found {
+		return nil, syncdao.ErrDaoNoDataFound
+	}
+	if pair.SyncSessionState != syncapi.SyncSessionStateInactive {
+		return nil, syncapi.ErrPairSessionActive
+	}
+	return pair, nil
+}
+
+//GetPair implements the syncdao.SyncPairDao.GetPair interface as an in memory implementation.
+func (dao SyncPairMemoryDao) GetPair(pairID string) (syncdao.SyncPair, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	pair, found := dao.store.pairs[pairID]
+	if !found {
+		return syncdao.SyncPair{}, syncdao.ErrDaoNoDataFound
+	}
+	return *pair, nil
+}
+
+//ListPairs implements the syncdao.SyncPairDao.ListPairs interface as an in memory implementation.
+func (dao SyncPairMemoryDao) ListPairs() ([]syncdao.SyncPair, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	answer := []syncdao.SyncPair{}
+	for _, pair := range dao.store.pairs {
+		answer = append(answer, *pair)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		if answer[i].PairName != answer[j].PairName {
+			return answer[i].PairName < answer[j].PairName
+		}
+		return answer[i].PairID < answer[j].PairID
+	})
+	return answer, nil
+}
+
+//UpdatePair implements the syncdao.SyncPairDao.UpdatePair interface as an in memory implementation.
+func (dao SyncPairMemoryDao) UpdatePair(pair syncdao.SyncPair) (err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	stored, err := dao.store.inactivePair(pair.PairID)
+	if err != nil {
+		return err
+	}
+	pair = syncapi.PairWithDefaults(pair)
+	updated := *stored
+	updated.PairName = pair.PairName
+	updated.MaxSesDurValue = pair.MaxSesDurValue
+	updated.MaxSesDurUnit = pair.MaxSesDurUnit
+	updated.SyncDataTransForm = pair.SyncDataTransForm
+	updated.SyncMsgTransForm = pair.SyncMsgTransForm
+	updated.SyncMsgSecPol = pair.SyncMsgSecPol
+	updated.SyncConflictURI = pair.SyncConflictURI
+	dao.store.putPair(&updated)
+	return nil
+}
+
+//DeletePair implements the syncdao.SyncPairDao.DeletePair interface as an in memory implementation. The management
+//messages do not record their pair and are kept, as their MsgSeq is their position.
+func (dao SyncPairMemoryDao) DeletePair(pairID string) (err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	_, err = dao.store.inactivePair(pairID)
+	if err != nil {
+		return err
+	}
+	pairNodes := []pairNodeRow{}
+	for _, row := range dao.store.pairNodes {
+		if row.pairID != pairID {
+			pairNodes = append(pairNodes, row)
+		}
+	}
+	endedSessions := []syncapi.EndedSession{}
+	for _, ended := range dao.store.endedSessions {
+		if ended.PairID != pairID {
+			endedSessions = append(endedSessions, ended)
+		}
+	}
+	dao.store.putPairNodes(pairNodes)
+	dao.store.putEndedSessions(endedSessions)
+	dao.store.deletePair(pairID)
+	return nil
+}
+
+//ListPairNodes implements the syncdao.SyncPairDao.ListPairNodes interface as an in memory implementation.
+func (dao SyncPairMemoryDao) ListPairNodes(pairID string) ([]syncdao.PairNode, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	if _, found := dao.store.pairs[pairID]; !found {
+		return []syncdao.PairNode{}, syncdao.ErrDaoNoDataFound
+	}
+	return dao.store.pairNodesOf(pairID), nil
+}
+
+//UpdatePairNode implements the syncdao.SyncPairDao.UpdatePairNode interface as an in memory implementation.
+func (dao SyncPairMemoryDao) UpdatePairNode(node syncdao.PairNode) (err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	position := dao.store.findPairNode(node.PairID, node.NodeID, node.TargetNodeID)
+	if position < 0 {
+		return syncdao.ErrDaoNoDataFound
+	}
+	_, err = dao.store.inactivePair(node.PairID)
+	if err != nil {
+		return err
+	}
+	if _, found := dao.store.dataVersions[node.SeededDataVersion]; node.SeededDataVersion != "" && !found {
+		return syncapi.ValidationError{Msg: "Cannot find data version '" + node.SeededDataVersion + "'"}
+	}
+	updated := dao.store.pairNodes[position]
+	updated.seededDataVersion = node.SeededDataVersion
+	updated.syncConflictURI = node.SyncConflictURI
+	dao.store.putPairNode(position, updated)
+	return nil
+}
+
+//DeletePairNode implements the syncdao.SyncPairDao.DeletePairNode interface as an in memory implementation.
+func (dao SyncPairMemoryDao) DeletePairNode(pairID string, nodeID string, targetNodeID string) (err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	position := dao.store.findPairNode(pairID, nodeID, targetNodeID)
+	if position < 0 {
+		return syncdao.ErrDaoNoDataFound
+	}
+	_, err = dao.store.inactivePair(pairID)
+	if err != nil {
+		return err
+	}
+	pairNodes := append([]pairNodeRow{}, dao.store.pairNodes[:position]...)
+	dao.store.putPairNodes(append(pairNodes, dao.store.pairNodes[position+1:]...))
+	return nil
+}
+
+//findPairNode gives the position in pairNodes of the node of the pair targeting targetNodeID, -1 when there is none.
+//The caller holds the store.
+func (store *store) findPairNode(pairID string, nodeID string, targetNodeID string) int {
+	for position, row := range store.pairNodes {
+		if row.pairID == pairID && row.nodeID == nodeID && row.targetNodeID == targetNodeID {
+			return position
+		}
+	}
+	return -1
+}
@@ -0,0 +1,410 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Persistence keeps the tables of the sync model held in memory beyond the life of the process, for instance in a file
+//as syncdaobolt does. The tables are loaded once when the MemoryDaosFactory is created and every change is saved
+//before the operation making it returns. A table is a set of rows, each value being held under its key.
+type Persistence interface {
+	//Load calls read with every row held, the rows of each table in the order of their keys compared as bytes.
+	Load(read func(table string, key []byte, value []byte) error) error
+	//Save makes every write or none of them.
+	Save(writes []Write) error
+	//Close releases what the persistence holds.
+	Close() error
+}
+
+//Write is a change to a row of a table. Value is held under Key, or Key is deleted when Value is nil. A nil Key
+//deletes every row of the table instead.
+type Write struct {
+	Table string
+	Key   []byte
+	Value []byte
+}
+
+//Every table is named after the sql table. Keys are the primary key columns of the table joined by keySeparator, and
+//values are the other columns encoded as json. The rows of sync_pair_nodes have no primary key, they are kept in the
+//order they were added under a sequence, as are the rows of sync_session_history and sync_session_mgmt_msg, whose
+//sequence is the MsgSeq of the message. The sequence of a row is its position in the table plus one, deleting rows of
+//sync_pair_nodes or sync_session_history saves the whole table again.
+const (
+	tableDataVersions  = "sync_data_version"
+	tableEntities      = "sync_data_entity"
+	tableNodes         = "sync_node"
+	tablePairs         = "sync_pair"
+	tablePairNodes     = "sync_pair_nodes"
+	tableStates        = "sync_state"
+	tablePeerStates    = "sync_peer_state"
+	tableAncestors     = "sync_state_ancestor"
+	tableConflicts     = "sync_conflict"
+	tableEndedSessions = "sync_session_history"
+	tableMgmtMsgs      = "sync_session_mgmt_msg"
+)
+
+var tables = []string{tableDataVersions, tableEntities, tableNodes, tablePairs, tablePairNodes, tableStates,
+	tablePeerStates, tableAncestors, tableConflicts, tableEndedSessions, tableMgmtMsgs}
+
+//keySeparator cannot be part of an id.
+const keySeparator = "\x00"
+
+func joinKey(columns ...string) []byte {
+	return []byte(strings.Join(columns, keySeparator))
+}
+
+func splitKey(key []byte, table string, columnCount int) ([]string, error) {
+	columns := strings.Split(string(key), keySeparator)
+	if len(columns) != columnCount {
+		return nil, errors.New("Key '" + strings.Join(columns, "', '") + "' of table " + table + " does not have " + strconv.Itoa(columnCount) + " columns")
+	}
+	return columns, nil
+}
+
+func (key recordKey) bytes() []byte {
+	return joinKey(key.entitySingularName, key.recordID)
+}
+
+func (key peerKey) bytes() []byte {
+	return joinKey(key.nodeID, key.entitySingularName, key.recordID)
+}
+
+func (key ancestorKey) bytes() []byte {
+	return joinKey(key.entitySingularName, key.recordID, key.recordHash)
+}
+
+func sequenceKey(position int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(position)+1)
+	return key
+}
+
+//entityRecord is the value of an entity in tableEntities. Position keeps the order in which the entities were added.
+type entityRecord struct {
+	Position        int
+	DataVersionName string
+	Item            syncdao.EntityPairItem
+	Fields          map[string]syncdao.SyncFieldDefinition
+}
+
+type pairNodeRecord struct {
+	PairID            string
+	NodeID            string
+	TargetNodeID      string
+	SeededDataVersion string
+	SyncConflictURI   string
+	TotalSessRecBytes int
+	TotalSessRecCount int
+	ProceSessRecBytes int
+	ProceSessRecCount int
+}
+
+type stateRecord struct {
+	DataVersionName string
+	RecordHash      string
+	RecordData      []byte
+	RecordBytesSize int
+	IsDelete        bool
+	DeletedDate     time.Time
+	RecordCreated   time.Time
+}
+
+type peerStateRecord struct {
+	SessionBindID            string
+	TransactionBindReceiveID string
+	TransactionBindSendID    string
+	QueueBindSendID          string
+	SentLastKnownHash        string
+	PeerLastKnownHash        string
+	SentSyncState            syncmsg.SentSyncStateEnum
+	IsConflict               bool
+	IsDelete                 bool
+	ChangedByClient          bool
+	RecordBytesSize          int
+	LastUpdated              time.Time
+	RecordCreated            time.Time
+}
+
+type conflictRecord struct {
+	ConflictID        string
+	SessionID         string
+	EntityPluralName  string
+	SyncState         syncmsg.AckSyncStateEnum
+	ConflictingFields []string
+	AncestorData      []byte
+	LocalRecordHash   string
+	LocalIsDelete     bool
+	LocalData         []byte
+	RemoteRecordHash  string
+	RemoteIsDelete    bool
+	RemoteData        []byte
+	RecordCreated     time.Time
+}
+
+//writeSet gathers the writes saving the changes noted by the store. The first error encoding a value is kept in err
+//and ends the gathering.
+type writeSet struct {
+	writes []Write
+	err    error
+}
+
+//put adds the write of value encoded as json under key, or the delete of key when the row is not found in its table.
+func (writes *writeSet) put(table string, key []byte, value interface{}, found bool) {
+	if writes.err != nil {
+		return
+	}
+	if !found {
+		writes.writes = append(writes.writes, Write{Table: table, Key: key})
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		writes.err = err
+		return
+	}
+	writes.writes = append(writes.writes, Write{Table: table, Key: key, Value: data})
+}
+
+//clear adds the delete of every row of table.
+func (writes *writeSet) clear(table string) {
+	writes.writes = append(writes.writes, Write{Table: table})
+}
+
+//writes gives the writes saving the changes noted by the store.
+func (store *store) writes() ([]Write, error) {
+	changes := store.changes
+	writes := &writeSet{}
+	if changes.reset {
+		for _, table := range tables {
+			writes.clear(table)
+		}
+	}
+	for dataVersionName := range changes.dataVersions {
+		writes.put(tableDataVersions, []byte(dataVersionName), store.dataVersions[dataVersionName], true)
+	}
+	for entitySingularName := range changes.entities {
+		entity := store.entities[entitySingularName]
+		record := entityRecord{
+			Position:        positionOf(store.entityNames, entitySingularName),
+			DataVersionName: entity.dataVersionName,
+			Item:            entity.item,
+			Fields:          entity.fields,
+		}
+		writes.put(tableEntities, []byte(entitySingularName), record, true)
+	}
+	for nodeID := range changes.nodes {
+		node, found := store.nodes[nodeID]
+		writes.put(tableNodes, []byte(nodeID), node, found)
+	}
+	for pairID := range changes.pairs {
+		pair, found := store.pairs[pairID]
+		writes.put(tablePairs, []byte(pairID), pair, found)
+	}
+	store.pairNodeWrites(writes)
+	store.endedSessionWrites(writes)
+	for position := len(store.mgmtMsgs) - changes.mgmtMsgs; position < len(store.mgmtMsgs); position++ {
+		writes.put(tableMgmtMsgs, sequenceKey(position), store.mgmtMsgs[position], true)
+	}
+	for key := range changes.states {
+		row, found := store.states[key]
+		var record stateRecord
+		if found {
+			record = stateRecord{row.dataVersionName, row.recordHash, row.recordData, row.recordBytesSize, row.isDelete, row.deletedDate, row.recordCreated}
+		}
+		writes.put(tableStates, key.bytes(), record, found)
+	}
+	for key := range changes.peerStates {
+		row, found := store.peerStates[key]
+		var record peerStateRecord
+		if found {
+			record = peerStateRecord{row.sessionBindID, row.transactionBindReceiveID, row.transactionBindSendID, row.queueBindSendID,
+				row.sentLastKnownHash, row.peerLastKnownHash, row.sentSyncState, row.isConflict, row.isDelete, row.changedByClient,
+				row.recordBytesSize, row.lastUpdated, row.recordCreated}
+		}
+		writes.put(tablePeerStates, key.bytes(), record, found)
+	}
+	for key := range changes.ancestors {
+		recordData, found := store.ancestors[key]
+		writes.put(tableAncestors, key.bytes(), recordData, found)
+	}
+	for key := range changes.conflicts {
+		row, found := store.conflicts[key]
+		var record conflictRecord
+		if found {
+			record = conflictRecord{row.conflictID, row.sessionID, row.entityPluralName, row.syncState, row.conflictingFields,
+				row.ancestorData, row.localRecordHash, row.localIsDelete, row.localData, row.remoteRecordHash, row.remoteIsDelete,
+				row.remoteData, row.recordCreated}
+		}
+		writes.put(tableConflicts, key.bytes(), record, found)
+	}
+	return writes.writes, writes.err
+}
+
+func (store *store) pairNodeWrites(writes *writeSet) {
+	added := len(store.pairNodes) - store.changes.pairNodes
+	if store.changes.pairNodesRewritten {
+		writes.clear(tablePairNodes)
+		added = 0
+	}
+	for position := added; position < len(store.pairNodes); position++ {
+		writes.put(tablePairNodes, sequenceKey(position), newPairNodeRecord(store.pairNodes[position]), true)
+	}
+	for position := range store.changes.changedPairNodes {
+		if position < added {
+			writes.put(tablePairNodes, sequenceKey(position), newPairNodeRecord(store.pairNodes[position]), true)
+		}
+	}
+}
+
+func newPairNodeRecord(row pairNodeRow) pairNodeRecord {
+	return pairNodeRecord{row.pairID, row.nodeID, row.targetNodeID, row.seededDataVersion, row.syncConflictURI,
+		row.totalSessRecBytes, row.totalSessRecCount, row.proceSessRecBytes, row.proceSessRecCount}
+}
+
+func (store *store) endedSessionWrites(writes *writeSet) {
+	added := len(store.endedSessions) - store.changes.endedSessions
+	if store.changes.endedSessionsRewritten {
+		writes.clear(tableEndedSessions)
+		added = 0
+	}
+	for position := added; position < len(store.endedSessions); position++ {
+		writes.put(tableEndedSessions, sequenceKey(position), store.endedSessions[position], true)
+	}
+}
+
+func positionOf(names []string, name string) int {
+	for i, each := range names {
+		if each == name {
+			return i
+		}
+	}
+	return len(names)
+}
+
+//load reads every table from the persistence.
+func (store *store) load() error {
+	store.clear()
+	positions := make(map[string]int)
+	err := store.persistence.Load(func(table string, key []byte, value []byte) error {
+		if table == tableEntities {
+			return store.readEntity(key, value, positions)
+		}
+		return store.read(table, key, value)
+	})
+	if err != nil {
+		store.clear()
+		return err
+	}
+	sort.SliceStable(store.entityNames, func(i, j int) bool {
+		return positions[store.entityNames[i]] < positions[store.entityNames[j]]
+	})
+	return nil
+}
+
+//readEntity reads a row of tableEntities, noting its position in positions.
+func (store *store) readEntity(key []byte, value []byte, positions map[string]int) error {
+	var record entityRecord
+	err := json.Unmarshal(value, &record)
+	if record.Fields == nil {
+		record.Fields = make(map[string]syncdao.SyncFieldDefinition)
+	}
+	store.entities[string(key)] = &entityRow{dataVersionName: record.DataVersionName, item: record.Item, fields: record.Fields}
+	store.entityNames = append(store.entityNames, string(key))
+	positions[string(key)] = record.Position
+	return err
+}
+
+//read reads a row of the sync model other than an entity. Rows of unknown tables are skipped.
+func (store *store) read(table string, key []byte, value []byte) error {
+	switch table {
+	case tableDataVersions:
+		var recordCreated time.Time
+		err := json.Unmarshal(value, &recordCreated)
+		store.dataVersions[string(key)] = recordCreated
+		return err
+	case tableNodes:
+		node := &syncdao.NodePairItem{}
+		store.nodes[string(key)] = node
+		return json.Unmarshal(value, node)
+	case tablePairs:
+		pair := &syncdao.SyncPair{}
+		store.pairs[string(key)] = pair
+		return json.Unmarshal(value, pair)
+	case tablePairNodes:
+		var record pairNodeRecord
+		err := json.Unmarshal(value, &record)
+		store.pairNodes = append(store.pairNodes, pairNodeRow{record.PairID, record.NodeID, record.TargetNodeID, record.SeededDataVersion,
+			record.SyncConflictURI, record.TotalSessRecBytes, record.TotalSessRecCount, record.ProceSessRecBytes, record.ProceSessRecCount})
+		return err
+	case tableEndedSessions:
+		var ended syncapi.EndedSession
+		err := json.Unmarshal(value, &ended)
+		store.endedSessions = append(store.endedSessions, ended)
+		return err
+	case tableMgmtMsgs:
+		var msg syncapi.SessionMgmtMsg
+		err := json.Unmarshal(value, &msg)
+		store.mgmtMsgs = append(store.mgmtMsgs, msg)
+		return err
+	}
+	return store.readRecord(table, key, value)
+}
+
+//readRecord reads a row of the tables holding the sync state of records.
+func (store *store) readRecord(table string, key []byte, value []byte) error {
+	switch table {
+	case tableStates:
+		columns, err := splitKey(key, table, 2)
+		if err != nil {
+			return err
+		}
+		var record stateRecord
+		err = json.Unmarshal(value, &record)
+		store.states[recordKey{columns[0], columns[1]}] = &stateRow{record.DataVersionName, record.RecordHash, record.RecordData,
+			record.RecordBytesSize, record.IsDelete, record.DeletedDate, record.RecordCreated}
+		return err
+	case tablePeerStates:
+		columns, err := splitKey(key, table, 3)
+		if err != nil {
+			return err
+		}
+		var record peerStateRecord
+		err = json.Unmarshal(value, &record)
+		store.peerStates[peerKey{columns[0], recordKey{columns[1], columns[2]}}] = &peerStateRow{record.SessionBindID,
+			record.TransactionBindReceiveID, record.TransactionBindSendID, record.QueueBindSendID, record.SentLastKnownHash,
+			record.PeerLastKnownHash, record.SentSyncState, record.IsConflict, record.IsDelete, record.ChangedByClient,
+			record.RecordBytesSize, record.LastUpdated, record.RecordCreated}
+		return err
+	case tableAncestors:
+		columns, err := splitKey(key, table, 3)
+		if err != nil {
+			return err
+		}
+		var recordData []byte
+		err = json.Unmarshal(value, &recordData)
+		store.ancestors[ancestorKey{recordKey{columns[0], columns[1]}, columns[2]}] = recordData
+		return err
+	case tableConflicts:
+		columns, err := splitKey(key, table, 3)
+		if err != nil {
+			return err
+		}
+		var record conflictRecord
+		err = json.Unmarshal(value, &record)
+		store.conflicts[peerKey{columns[0], recordKey{columns[1], columns[2]}}] = &conflictRow{record.ConflictID, record.SessionID,
+			record.EntityPluralName, record.SyncState, record.ConflictingFields, record.AncestorData, record.LocalRecordHash,
+			record.LocalIsDelete, record.LocalData, record.RemoteRecordHash, record.RemoteIsDelete, record.RemoteData,
+			record.RecordCreated}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package syncdaomem
+
+import (
+	"bytes"
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twinj/uuid"
+)
+
+//testPersistence keeps the tables in maps, standing in for a file that outlives the factory. Save fails while failSave
+//is set.
+type testPersistence struct {
+	tables   map[string]map[string][]byte
+	failSave bool
+}
+
+func newTestPersistence() *testPersistence {
+	return &testPersistence{tables: make(map[string]map[string][]byte)}
+}
+
+func (persistence *testPersistence) Load(read func(table string, key []byte, value []byte) error) error {
+	for table, rows := range persistence.tables {
+		keys := [][]byte{}
+		for key := range rows {
+			keys = append(keys, []byte(key))
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return bytes.Compare(keys[i], keys[j]) < 0
+		})
+		for _, key := range keys {
+			err := read(table, key, rows[string(key)])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (persistence *testPersistence) Save(writes []Write) error {
+	if persistence.failSave {
+		return errors.New("Save failed")
+	}
+	for _, write := range writes {
+		if write.Key == nil {
+			delete(persistence.tables, write.Table)
+			continue
+		}
+		rows, found := persistence.tables[write.Table]
+		if !found {
+			rows = make(map[string][]byte)
+			persistence.tables[write.Table] = rows
+		}
+		if write.Value == nil {
+			delete(rows, string(write.Key))
+		} else {
+			rows[string(write.Key)] = write.Value
+		}
+	}
+	return nil
+}
+
+func (persistence *testPersistence) Close() error {
+	return nil
+}
+
+//newPersistentTestFactory creates a MemoryDaosFactory saving to persistence and holding the sample data of the given
+//profile, see testhelper.SetupSeededData.
+func newPersistentTestFactory(persistence *testPersistence, profile string) *MemoryDaosFactory {
+	factory, err := NewPersistentDaosFactory(persistence)
+	if err != nil {
+		panic(err)
+	}
+	err = testhelper.SetupSeededData(factory, profile)
+	if err != nil {
+		panic(err)
+	}
+	return factory
+}
+
+//reopenTestFactory closes factory and creates another one loading the sync model from persistence.
+func reopenTestFactory(factory *MemoryDaosFactory, persistence *testPersistence) *MemoryDaosFactory {
+	factory.Close()
+	reopened, err := NewPersistentDaosFactory(persistence)
+	if err != nil {
+		panic(err)
+	}
+	return reopened
+}
+
+func TestMemoryDaosFactory_Reopen(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	persistence := newTestPersistence()
+	factory := newPersistentTestFactory(persistence, "profile5")
+	initial := factory.store
+
+	factory = reopenTestFactory(factory, persistence)
+	reopened := factory.store
+	assert.Equal(t, initial.entityNames, reopened.entityNames)
+	assert.Equal(t, initial.pairNodes, reopened.pairNodes)
+	assert.Len(t, reopened.dataVersions, len(initial.dataVersions))
+	assert.Len(t, reopened.nodes, len(initial.nodes))
+	assert.Equal(t, *initial.nodes["*node-hub"], *reopened.nodes["*node-hub"])
+	assert.Len(t, reopened.pairs, len(initial.pairs))
+	assert.Equal(t, initial.pairs["*pair-1"].SyncSessionState, reopened.pairs["*pair-1"].SyncSessionState)
+	assert.Equal(t, initial.entities["Contact"].fields, reopened.entities["Contact"].fields)
+	assert.Len(t, reopened.states, len(initial.states))
+	assert.Len(t, reopened.peerStates, len(initial.peerStates))
+	contactKey := peerKey{nodeID: "*node-spoke1", recordKey: recordKey{entitySingularName: "Contact", recordID: "911DD745-8916-41C4-9973-F8B38A501602"}}
+	if assert.NotNil(t, reopened.peerStates[contactKey]) {
+		assert.Equal(t, initial.peerStates[contactKey].sentLastKnownHash, reopened.peerStates[contactKey].sentLastKnownHash)
+		assert.Equal(t, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, reopened.peerStates[contactKey].sentSyncState)
+	}
+	if assert.NotNil(t, reopened.states[contactKey.recordKey]) {
+		assert.Equal(t, initial.states[contactKey.recordKey].recordData, reopened.states[contactKey.recordKey].recordData)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestMemoryDaosFactory_ChangesAreSaved(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	persistence := newTestPersistence()
+	factory := newPersistentTestFactory(persistence, "profile3")
+
+	assert.Nil(t, factory.SyncNodeDao().AddNode(syncdao.SyncNode{NodeID: "*node-new", NodeName: "N", DataVersionName: "Demo Model 1"}))
+	assert.Nil(t, factory.SyncNodeDao().AddNode(syncdao.SyncNode{NodeID: "*node-deleted", NodeName: "D", DataVersionName: "Demo Model 1"}))
+	assert.Nil(t, factory.SyncNodeDao().DeleteNodeByNodeID("*node-deleted"))
+	_, err := factory.SyncPairDao().CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: "*pair-1", SessionID: "*session-id-1"})
+	assert.Nil(t, err)
+	assert.Nil(t, factory.MarkSyncStateDeleted("Entity 1", "*record-1"))
+
+	factory = reopenTestFactory(factory, persistence)
+	_, err = factory.SyncNodeDao().GetOneNodeByNodeID("*node-new")
+	assert.Nil(t, err)
+	_, err = factory.SyncNodeDao().GetOneNodeByNodeID("*node-deleted")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+	pairState, err := factory.SyncPairDao().QueryPairState(syncdao.QueryPairStateRequest{PairID: "*pair-1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "*session-id-1", pairState.SessionID)
+	assert.Equal(t, "Initializing", pairState.State)
+	state := findState(factory, "Entity 1", "*record-1")
+	if assert.NotNil(t, state) {
+		assert.True(t, state.isDelete)
+	}
+
+	//Reset empties the persistence
+	factory.Reset()
+	factory = reopenTestFactory(factory, persistence)
+	assert.Empty(t, factory.store.nodes)
+	assert.Empty(t, factory.store.pairNodes)
+	assert.Empty(t, factory.store.states)
+
+	testhelper.EndTest(testName)
+}
+
+func TestMemoryDaosFactory_DeletedPairNodesAreSaved(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	persistence := newTestPersistence()
+	factory := newPersistentTestFactory(persistence, "profile3")
+
+	syncPairDao := factory.SyncPairDao()
+	assert.Nil(t, syncPairDao.DeletePairNode("*pair-2", "*node-hub", "*node-spoke2"))
+	assert.Nil(t, syncPairDao.DeletePair("*pair-1"))
+	assert.Nil(t, syncPairDao.UpdatePairNode(syncdao.PairNode{PairID: "*pair-4", NodeID: "*node-spoke3", TargetNodeID: "*node-hub", SyncConflictURI: "http://localhost:8080/conflict"}))
+	//Rows added after the rewrite are saved under the sequence that follows
+	assert.Nil(t, syncPairDao.AddPairNode("*pair-2", "*node-hub", "*node-spoke2", "", "none"))
+	initial := append([]pairNodeRow{}, factory.store.pairNodes...)
+
+	factory = reopenTestFactory(factory, persistence)
+	assert.Equal(t, initial, factory.store.pairNodes)
+	assert.Len(t, factory.store.pairNodes, 7)
+	_, found := factory.store.pairs["*pair-1"]
+	assert.False(t, found)
+	pairNodes, err := factory.SyncPairDao().ListPairNodes("*pair-4")
+	if assert.Nil(t, err) && assert.Len(t, pairNodes, 2) {
+		assert.Equal(t, "http://localhost:8080/conflict", pairNodes[1].SyncConflictURI)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestMemoryDaosFactory_FailedSaveIsUndone(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	persistence := newTestPersistence()
+	factory := newPersistentTestFactory(persistence, "profile3")
+	defer factory.Close()
+
+	persistence.failSave = true
+	err := factory.SyncNodeDao().AddNode(syncdao.SyncNode{NodeID: "*node-new", NodeName: "N", DataVersionName: "Demo Model 1"})
+	assert.NotNil(t, err)
+	persistence.failSave = false
+	//The sync model is loaded again, so it still matches what was saved
+	_, err = factory.SyncNodeDao().GetOneNodeByNodeID("*node-new")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+	_, err = factory.SyncNodeDao().GetOneNodeByNodeID("*node-hub")
+	assert.Nil(t, err)
+
+	testhelper.EndTest(testName)
+}
+
+func TestSessionRepository_EndedSessionsAreSaved(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	persistence := newTestPersistence()
+	factory := newPersistentTestFactory(persistence, "profile3")
+	_, err := factory.SyncPairDao().CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: "*pair-1", SessionID: "*session-id-1"})
+	assert.Nil(t, err)
+	reaped, err := NewSessionRepository(factory).ReapExpiredSessions(time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, reaped.TotalReaped)
+
+	factory = reopenTestFactory(factory, persistence)
+	pairState, err := factory.SyncPairDao().QueryPairState(syncdao.QueryPairStateRequest{PairID: "*pair-1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Inactive", pairState.State)
+	ended, err := NewSessionRepository(factory).EndedSessions("*pair-1")
+	assert.Nil(t, err)
+	if assert.Len(t, ended, 1) {
+		assert.Equal(t, "*session-id-1", ended[0].SessionID)
+		assert.Equal(t, "Initializing", ended[0].State)
+		assert.Equal(t, syncapi.SessionEndReasonExpired, ended[0].Reason)
+	}
+
+	//Reset empties the history along with the other tables
+	factory.Reset()
+	factory = reopenTestFactory(factory, persistence)
+	assert.Empty(t, factory.store.endedSessions)
+
+	testhelper.EndTest(testName)
+}
+
+func TestSessionRepository_HeartbeatIsSaved(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	persistence := newTestPersistence()
+	factory := newPersistentTestFactory(persistence, "profile3")
+	_, err := factory.SyncPairDao().CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: "*pair-1", SessionID: "*session-id-1"})
+	assert.Nil(t, err)
+	//The session started longer ago than the 10 minutes sessions of the pair last
+	store := factory.store
+	store.lock()
+	started := *store.pairs["*pair-1"]
+	started.SyncSessionStart = time.Now().Add(-20 * time.Minute)
+	store.putPair(&started)
+	assert.Nil(t, store.unlock())
+
+	sent, err := NewSessionRepository(factory).SendMgmtMsg(syncapi.SessionMgmtMsg{SessionID: "*session-id-1",
+		SenderNodeID: "*node-hub", MessageType: syncapi.SessionMgmtMsgTypeHeartbeat})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", sent.Result)
+
+	factory = reopenTestFactory(factory, persistence)
+	reaped, err := NewSessionRepository(factory).ReapExpiredSessions(time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, reaped.TotalReaped)
+	reaped, err = NewSessionRepository(factory).ReapExpiredSessions(time.Now().Add(11 * time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, reaped.TotalReaped)
+	msgs, err := NewSessionRepository(factory).PollMgmtMsgs("*session-id-1", "*node-peer", 0)
+	assert.Nil(t, err)
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, sent.MsgSeq, msgs[0].MsgSeq)
+		assert.Equal(t, syncapi.SessionMgmtMsgTypeHeartbeat, msgs[0].MessageType)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestSyncFetcher_ReservationsAreSaved(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	persistence := newTestPersistence()
+	factory := newPersistentTestFactory(persistence, "profile3")
+	sessionUUID := uuid.Formatter(uuid.NewV4(), uuid.CleanHyphen)
+	nodeID := "*node-hub"
+	entities := []syncapi.EntityNameItem{
+		syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+
+	msgQueuer, err := newMessageQueuer(factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = msgQueuer.Queue(sessionUUID, nodeID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	fetcher, err := newMessageFetcher(sessionUUID, nodeID, syncapi.FetchLimits{MaxMsgs: 2}, factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+	answer, err := fetcher.Fetch(entities, syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if !assert.Nil(t, err) {
+		return
+	}
+	transactionBindID := answer.Request.GetTransactionBindId()
+	fetched := []string{}
+	for _, msg := range answer.Request.Items[0].Msgs {
+		fetched = append(fetched, msg.GetRecordId())
+	}
+	assert.Equal(t, []string{"0934A378-DEDB-4207-B99C-DD0D61DC59BC", "911DD745-8916-41C4-9973-F8B38A501602"}, fetched)
+
+	//The records fetched stay reserved for their transaction once loaded again, so the next fetch only gathers the
+	//record left
+	factory = reopenTestFactory(factory, persistence)
+	for _, recordID := range fetched {
+		peer := findPeerState(factory, nodeID, "Contact", recordID)
+		if assert.NotNil(t, peer) {
+			assert.Equal(t, transactionBindID, peer.transactionBindSendID)
+			assert.NotEqual(t, "", peer.queueBindSendID)
+		}
+	}
+	fetcher, err = newMessageFetcher(sessionUUID, nodeID, syncapi.FetchLimits{MaxMsgs: 2}, factory.store)
+	if !assert.Nil(t, err) {
+		return
+	}
+	answer, err = fetcher.Fetch(entities, syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if !assert.Nil(t, err) || !assert.Len(t, answer.Request.Items, 1) {
+		return
+	}
+	assert.Len(t, answer.Request.Items[0].Msgs, 1)
+	assert.Equal(t, "B6581A36-804D-45AC-B2E2-F6DA265AF7DE", answer.Request.Items[0].Msgs[0].GetRecordId())
+
+	testhelper.EndTest(testName)
+}
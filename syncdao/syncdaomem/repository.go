@@ -0,0 +1,53 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+)
+
+//NewDataRepository provides access to the sync data held by factory for a DataRepository.
+func NewDataRepository(factory *MemoryDaosFactory) syncapi.DataRepositoryable {
+	return dataRepositoryType{
+		store: factory.store,
+	}
+}
+
+type dataRepositoryType struct {
+	store *store
+}
+
+func (dataRepository dataRepositoryType) CreateMessageFetcher(sessionID string, nodeID string, limits syncapi.FetchLimits) (syncapi.MessageFetching, error) {
+	fetcher, err := newMessageFetcher(sessionID, nodeID, limits, dataRepository.store)
+	if err != nil {
+		syncutil.Error(err)
+		return fetcher, err
+	}
+	return fetcher, nil
+}
+
+func (dataRepository dataRepositoryType) CreateMessageProcessor(sessionID string, nodeID string, entitiesByPluralName map[string]syncapi.EntityNameItem) (syncapi.MessageProcessing, error) {
+	return newMessageProcessor(sessionID, nodeID, entitiesByPluralName, dataRepository.store)
+}
+
+func (dataRepository dataRepositoryType) CreateMessageQueuer(sessionID string, nodeID string) (syncapi.MessageQueuing, error) {
+	return newMessageQueuer(dataRepository.store)
+}
+
+func (dataRepository dataRepositoryType) CreateMessageAcknowledger(sessionID string, nodeID string) (syncapi.MessageAcknowledging, error) {
+	return newMessageAcknowledger(sessionID, nodeID, dataRepository.store)
+}
+
+//NewConfigRepository provides access to the sync model held by factory for ConfigurationRepository.
+func NewConfigRepository(factory *MemoryDaosFactory) syncapi.ConfigRepositoryable {
+	return configRepositoryType{
+		store: factory.store,
+	}
+}
+
+type configRepositoryType struct {
+	store *store
+}
+
+func (configRepository configRepositoryType) CreateEntityFetcher(sessionID string, nodeID string) (syncapi.EntityFetching, error) {
+	return newEntityFetcher(sessionID, nodeID, configRepository.store)
+}
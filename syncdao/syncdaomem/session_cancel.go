@@ -0,0 +1,69 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"time"
+)
+
+//CancelSession rolls back and closes the active session sessionID, recording it as ended with
+//syncapi.SessionEndReasonCanceled.
+func (sessionRepository sessionRepositoryType) CancelSession(sessionID string, reasonMsg string, now time.Time) (answer syncapi.SessionCancelResult, err error) {
+	store := sessionRepository.store
+	store.lock()
+	defer store.release(&err)
+	pairID, pair := store.activeSessionPair(sessionID)
+	if pair == nil {
+		answer.Result = syncapi.SessionNotActiveResult
+		return answer, nil
+	}
+	answer.Session = syncapi.EndedSession{
+		PairID:       pairID,
+		SessionID:    pair.SyncSessionID,
+		State:        pair.SyncSessionState,
+		SessionStart: pair.SyncSessionStart,
+		SessionEnd:   now,
+		Reason:       syncapi.SessionEndReasonCanceled,
+		ReasonMsg:    reasonMsg,
+	}
+	answer.DiscardedCount = store.endSession(&answer.Session)
+	answer.Result = "OK"
+	return answer, nil
+}
+
+//endSession rolls back the session of ended, closes it and records it, answering how many TransactionBindReceiveId
+//were discarded. The session goes through Canceling, which no one can see while the caller holds the store.
+func (store *store) endSession(ended *syncapi.EndedSession) int {
+	ended.ReleasedCount = store.releaseSession(ended.SessionID)
+	discardedCount := store.discardReceiveBinds(ended.PairID)
+	closed := *store.pairs[ended.PairID]
+	closed.SyncSessionID = ""
+	closed.SyncSessionStart = time.Time{}
+	closed.SyncSessionHeartbeat = time.Time{}
+	closed.SyncSessionState = syncapi.SyncSessionStateInactive
+	store.putPair(&closed)
+	store.addEndedSession(*ended)
+	return discardedCount
+}
+
+//discardReceiveBinds clears the TransactionBindReceiveId of the sync_peer_state rows of the nodes of the pair,
+//answering how many there were. The caller holds the store.
+func (store *store) discardReceiveBinds(pairID string) int {
+	pairNodeIDs := make(map[string]bool)
+	for _, row := range store.pairNodes {
+		if row.pairID == pairID {
+			pairNodeIDs[row.nodeID] = true
+			pairNodeIDs[row.targetNodeID] = true
+		}
+	}
+	answer := 0
+	for key, peer := range store.peerStates {
+		if !pairNodeIDs[key.nodeID] || peer.transactionBindReceiveID == "" {
+			continue
+		}
+		discarded := *peer
+		discarded.transactionBindReceiveID = ""
+		store.putPeerState(key, &discarded)
+		answer++
+	}
+	return answer
+}
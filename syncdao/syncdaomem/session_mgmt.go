@@ -0,0 +1,84 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"time"
+)
+
+//SendMgmtMsg keeps the message and acts on the session of the message.
+func (sessionRepository sessionRepositoryType) SendMgmtMsg(msg syncapi.SessionMgmtMsg) (answer syncapi.SessionMgmtMsgResult, err error) {
+	store := sessionRepository.store
+	store.lock()
+	defer store.release(&err)
+	pairID, pair := store.activeSessionPair(msg.SessionID)
+	if pair == nil {
+		answer.Result = syncapi.SessionNotActiveResult
+		return answer, nil
+	}
+	now := time.Now()
+	kept := msg
+	kept.MsgSeq = int64(len(store.mgmtMsgs)) + 1
+	kept.RecordCreated = now
+	store.addMgmtMsg(kept)
+	answer.PairID = pairID
+	answer.MsgSeq = kept.MsgSeq
+	answer.State = pair.SyncSessionState
+	if msg.MessageType == syncapi.SessionMgmtMsgTypeHeartbeat {
+		updated := *pair
+		updated.SyncSessionHeartbeat = now
+		store.putPair(&updated)
+	} else if msg.MessageType.IsCanceling() {
+		if pair.SyncSessionState != syncapi.SyncSessionStateCanceling {
+			updated := *pair
+			updated.SyncSessionState = syncapi.SyncSessionStateCanceling
+			store.putPair(&updated)
+			answer.State = updated.SyncSessionState
+		}
+		answer.ReleasedCount = store.releaseSessionFetches(msg.SessionID)
+	}
+	answer.Result = "OK"
+	return answer, nil
+}
+
+//activeSessionPair gives the pair whose active session is sessionID, or a nil pair when there is none. The caller
+//holds the store.
+func (store *store) activeSessionPair(sessionID string) (string, *syncdao.SyncPair) {
+	for pairID, pair := range store.pairs {
+		if sameID(pair.SyncSessionID, sessionID) && pair.SyncSessionState != syncapi.SyncSessionStateInactive {
+			return pairID, pair
+		}
+	}
+	return "", nil
+}
+
+//releaseSessionFetches rolls back the fetches in flight for the session, answering how many sync_peer_state rows were
+//released. The rows stay bound to the session, so they are fetched again. The caller holds the store.
+func (store *store) releaseSessionFetches(sessionID string) int {
+	answer := 0
+	for key, peer := range store.peerStates {
+		if !sameID(peer.sessionBindID, sessionID) || (peer.queueBindSendID == "" && peer.transactionBindSendID == "") {
+			continue
+		}
+		released := *peer
+		released.queueBindSendID = ""
+		released.transactionBindSendID = ""
+		store.putPeerState(key, &released)
+		answer++
+	}
+	return answer
+}
+
+//PollMgmtMsgs lists, in order, the messages of the session sent by nodes other than nodeID after afterSeq.
+func (sessionRepository sessionRepositoryType) PollMgmtMsgs(sessionID string, nodeID string, afterSeq int64) ([]syncapi.SessionMgmtMsg, error) {
+	store := sessionRepository.store
+	store.lock()
+	defer store.unlock()
+	answer := []syncapi.SessionMgmtMsg{}
+	for _, msg := range store.mgmtMsgs {
+		if msg.MsgSeq > afterSeq && msg.SessionID == sessionID && msg.SenderNodeID != nodeID {
+			answer = append(answer, msg)
+		}
+	}
+	return answer, nil
+}
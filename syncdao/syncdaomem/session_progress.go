@@ -0,0 +1,171 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"sort"
+)
+
+//The progress of a session is kept on the pairNodeRow of each direction: nodeID sends to targetNodeID. The node given
+//to the queuer, acknowledger and processor is the peer, so records sent to it are counted on the row targeting it and
+//records received from it on the row it sends from. The caller of every store method below holds the store.
+
+//updatePairNodes puts a copy of every sync_pair_nodes row of the pair changed by update in its place, answering how
+//many rows were changed.
+func (store *store) updatePairNodes(pairID string, update func(row *pairNodeRow) bool) int {
+	answer := 0
+	for position, pairNode := range store.pairNodes {
+		row := pairNode
+		if row.pairID != pairID || !update(&row) {
+			continue
+		}
+		store.putPairNode(position, row)
+		answer++
+	}
+	return answer
+}
+
+//resetSessionProgress zeroes the progress of both directions of the pair for a new session.
+func (store *store) resetSessionProgress(pairID string) {
+	store.updatePairNodes(pairID, func(row *pairNodeRow) bool {
+		row.totalSessRecBytes, row.totalSessRecCount, row.proceSessRecBytes, row.proceSessRecCount = 0, 0, 0, 0
+		return true
+	})
+}
+
+//setQueuedTotals sets the totals sent to nodeID in the session to what was processed so far plus what is queued now.
+func (store *store) setQueuedTotals(sessionID string, nodeID string) {
+	pairID, pair := store.activeSessionPair(sessionID)
+	if pair == nil {
+		return
+	}
+	count, bytes := 0, 0
+	for key, peer := range store.peerStates {
+		if key.nodeID == nodeID && peer.changedByClient {
+			count++
+			bytes += peer.recordBytesSize
+		}
+	}
+	store.updatePairNodes(pairID, func(row *pairNodeRow) bool {
+		if row.targetNodeID != nodeID {
+			return false
+		}
+		row.totalSessRecCount = row.proceSessRecCount + count
+		row.totalSessRecBytes = row.proceSessRecBytes + bytes
+		return true
+	})
+}
+
+//addAcknowledgedProgress adds the records nodeID acknowledged among those bound to the transaction to what the
+//session processed sending to nodeID. It runs before the transaction binds are cleared.
+func (store *store) addAcknowledgedProgress(sessionID string, nodeID string, bound func(peer *peerStateRow) bool) {
+	pairID, pair := store.activeSessionPair(sessionID)
+	if pair == nil {
+		return
+	}
+	count, bytes := 0, 0
+	for key, peer := range store.peerStates {
+		if key.nodeID == nodeID && bound(peer) && !peer.changedByClient {
+			count++
+			bytes += peer.recordBytesSize
+		}
+	}
+	store.updatePairNodes(pairID, func(row *pairNodeRow) bool {
+		if row.targetNodeID != nodeID {
+			return false
+		}
+		row.proceSessRecCount += count
+		row.proceSessRecBytes += bytes
+		return true
+	})
+}
+
+//addProcessedProgress adds the records of the request to what the session processed receiving from nodeID. The peer
+//may send more than the totals it reported, so the totals grow with what was processed.
+func (store *store) addProcessedProgress(sessionID string, nodeID string, request *syncmsg.ProtoSyncEntityMessageRequest) {
+	pairID, pair := store.activeSessionPair(sessionID)
+	if pair == nil {
+		return
+	}
+	count, bytes := syncapi.RequestRecordTotals(request)
+	store.updatePairNodes(pairID, func(row *pairNodeRow) bool {
+		if row.nodeID != nodeID {
+			return false
+		}
+		row.proceSessRecCount += count
+		row.proceSessRecBytes += bytes
+		if row.totalSessRecCount < row.proceSessRecCount {
+			row.totalSessRecCount = row.proceSessRecCount
+		}
+		if row.totalSessRecBytes < row.proceSessRecBytes {
+			row.totalSessRecBytes = row.proceSessRecBytes
+		}
+		return true
+	})
+}
+
+//pairProgress gives the progress of both directions of the pair, ordered by nodeID.
+func (store *store) pairProgress(pairID string) []syncdao.SessionProgress {
+	answer := []syncdao.SessionProgress{}
+	for _, row := range store.pairNodes {
+		if row.pairID != pairID {
+			continue
+		}
+		answer = append(answer, syncdao.SessionProgress{
+			NodeID:            row.nodeID,
+			TargetNodeID:      row.targetNodeID,
+			TotalSessRecBytes: row.totalSessRecBytes,
+			TotalSessRecCount: row.totalSessRecCount,
+			ProceSessRecBytes: row.proceSessRecBytes,
+			ProceSessRecCount: row.proceSessRecCount,
+		})
+	}
+	sort.SliceStable(answer, func(i, j int) bool { return answer[i].NodeID < answer[j].NodeID })
+	return answer
+}
+
+//UpdateSyncingWithTotals sets the totals of both directions of the active session as an in memory implementation.
+func (dao SyncPairMemoryDao) UpdateSyncingWithTotals(request syncdao.UpdateSyncingRequestWithTotals) (syncdao.UpdateSyncingResult, error) {
+	return dao.updateSyncing(request.PairID, request.SessionID, request.NodeID, func(row *pairNodeRow, bytes int, count int) {
+		row.totalSessRecBytes, row.totalSessRecCount = bytes, count
+	}, request.RecordBytes1, request.RecordCount1, request.RecordBytes2, request.RecordCount2)
+}
+
+//UpdateSyncingWithProcessed sets what both directions of the active session processed as an in memory implementation.
+func (dao SyncPairMemoryDao) UpdateSyncingWithProcessed(request syncdao.UpdateSyncingRequestWithProcessed) (syncdao.UpdateSyncingResult, error) {
+	return dao.updateSyncing(request.PairID, request.SessionID, request.NodeID, func(row *pairNodeRow, bytes int, count int) {
+		row.proceSessRecBytes, row.proceSessRecCount = bytes, count
+	}, request.RecordBytes1, request.RecordCount1, request.RecordBytes2, request.RecordCount2)
+}
+
+//updateSyncing sets bytes1 and count1 on the direction from nodeID and bytes2 and count2 on the direction to nodeID.
+func (dao SyncPairMemoryDao) updateSyncing(pairID string, sessionID string, nodeID string, set func(row *pairNodeRow, bytes int, count int),
+	bytes1 int, count1 int, bytes2 int, count2 int) (answer syncdao.UpdateSyncingResult, err error) {
+	dao.store.lock()
+	defer dao.store.release(&err)
+	pair, found := dao.store.pairs[pairID]
+	if !found || !sameID(pair.SyncSessionID, sessionID) || pair.SyncSessionState == syncapi.SyncSessionStateInactive {
+		answer.Result = syncapi.SyncingSessionNotActiveResult
+		answer.ResultMsg = "Session '" + sessionID + "' is not the active session of pair '" + pairID + "'"
+		return answer, nil
+	}
+	updatedCount := dao.store.updatePairNodes(pairID, func(row *pairNodeRow) bool {
+		switch nodeID {
+		case row.nodeID:
+			set(row, bytes1, count1)
+		case row.targetNodeID:
+			set(row, bytes2, count2)
+		default:
+			return false
+		}
+		return true
+	})
+	if updatedCount == 0 {
+		answer.Result = syncapi.SyncingNodeNotInPairResult
+		answer.ResultMsg = "Node '" + nodeID + "' is not in pair '" + pairID + "'"
+		return answer, nil
+	}
+	answer.Result = "OK"
+	return answer, nil
+}
@@ -0,0 +1,95 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"sort"
+	"time"
+)
+
+//NewSessionRepository provides access to the sync data held by factory for a SessionRepository.
+func NewSessionRepository(factory *MemoryDaosFactory) syncapi.SessionRepositoryable {
+	return sessionRepositoryType{
+		store: factory.store,
+	}
+}
+
+type sessionRepositoryType struct {
+	store *store
+}
+
+//ReapExpiredSessions ends the sessions which outlived the maximum session duration of their pair, by order of pair id.
+func (sessionRepository sessionRepositoryType) ReapExpiredSessions(now time.Time) (answer syncapi.SessionReapResult, err error) {
+	answer = syncapi.SessionReapResult{
+		Sessions: []syncapi.EndedSession{},
+	}
+	store := sessionRepository.store
+	store.lock()
+	defer store.release(&err)
+	pairIDs := []string{}
+	for pairID, pair := range store.pairs {
+		if pair.SyncSessionID != "" && pair.SyncSessionState != syncapi.SyncSessionStateInactive {
+			pairIDs = append(pairIDs, pairID)
+		}
+	}
+	sort.Strings(pairIDs)
+	for _, pairID := range pairIDs {
+		pair := store.pairs[pairID]
+		maxDuration, err := syncapi.MaxSessionDuration(pair.MaxSesDurValue, pair.MaxSesDurUnit)
+		if err != nil {
+			syncutil.Error(err, ". Cannot tell whether session", pair.SyncSessionID, "of pair", pairID, "expired")
+			continue
+		}
+		if !syncapi.IsSessionExpired(pair.SyncSessionStart, pair.SyncSessionHeartbeat, maxDuration, now) {
+			continue
+		}
+		ended := syncapi.EndedSession{
+			PairID:       pairID,
+			SessionID:    pair.SyncSessionID,
+			State:        pair.SyncSessionState,
+			SessionStart: pair.SyncSessionStart,
+			SessionEnd:   now,
+			Reason:       syncapi.SessionEndReasonExpired,
+			ReasonMsg:    "Session outlived the maximum session duration of " + maxDuration.String(),
+		}
+		store.endSession(&ended)
+		answer.Sessions = append(answer.Sessions, ended)
+		answer.TotalReaped++
+	}
+	if answer.TotalReaped > 0 {
+		syncutil.Info("Reaped", answer.TotalReaped, "expired sessions")
+	}
+	return answer, nil
+}
+
+//releaseSession unbinds the sync_peer_state rows bound to the session, answering how many there were. The rows stay
+//changedByClient, so the next session queues them again. The caller holds the store.
+func (store *store) releaseSession(sessionID string) int {
+	answer := 0
+	for key, peer := range store.peerStates {
+		if !sameID(peer.sessionBindID, sessionID) {
+			continue
+		}
+		released := *peer
+		released.sessionBindID = ""
+		released.queueBindSendID = ""
+		released.transactionBindSendID = ""
+		store.putPeerState(key, &released)
+		answer++
+	}
+	return answer
+}
+
+//EndedSessions lists the sessions of a pair ended by the agent, oldest first.
+func (sessionRepository sessionRepositoryType) EndedSessions(pairID string) ([]syncapi.EndedSession, error) {
+	store := sessionRepository.store
+	store.lock()
+	defer store.unlock()
+	answer := []syncapi.EndedSession{}
+	for _, ended := range store.endedSessions {
+		if ended.PairID == pairID {
+			answer = append(answer, ended)
+		}
+	}
+	return answer, nil
+}
@@ -0,0 +1,540 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"sort"
+	"sync"
+	"time"
+)
+
+//recordKey identifies a record of an entity as the primary key of sync_state does.
+type recordKey struct {
+	entitySingularName string
+	recordID           string
+}
+
+//peerKey identifies the state of a record for a node as the primary key of sync_peer_state does.
+type peerKey struct {
+	nodeID string
+	recordKey
+}
+
+//ancestorKey identifies a version of a record as the primary key of sync_state_ancestor does.
+type ancestorKey struct {
+	recordKey
+	recordHash string
+}
+
+//entityRow holds a sync_data_entity row along with the sync_data_field rows of the entity.
+type entityRow struct {
+	dataVersionName string
+	item            syncdao.EntityPairItem
+	fields          map[string]syncdao.SyncFieldDefinition
+}
+
+//pairNodeRow holds a sync_pair_nodes row.
+type pairNodeRow struct {
+	pairID            string
+	nodeID            string
+	targetNodeID      string
+	seededDataVersion string
+	syncConflictURI   string
+	totalSessRecBytes int
+	totalSessRecCount int
+	proceSessRecBytes int
+	proceSessRecCount int
+}
+
+//stateRow holds a sync_state row. Since there are no custom tables in memory, recordData is the only copy of the
+//record. A zero deletedDate stands for null.
+type stateRow struct {
+	dataVersionName string
+	recordHash      string
+	recordData      []byte
+	recordBytesSize int
+	isDelete        bool
+	deletedDate     time.Time
+	recordCreated   time.Time
+}
+
+//peerStateRow holds a sync_peer_state row. Empty ids and hashes stand for null.
+type peerStateRow struct {
+	sessionBindID            string
+	transactionBindReceiveID string
+	transactionBindSendID    string
+	queueBindSendID          string
+	sentLastKnownHash        string
+	peerLastKnownHash        string
+	sentSyncState            syncmsg.SentSyncStateEnum
+	isConflict               bool
+	isDelete                 bool
+	changedByClient          bool
+	recordBytesSize          int
+	lastUpdated              time.Time
+	recordCreated            time.Time
+}
+
+//conflictRow holds a sync_conflict row. A nil record stands for null.
+type conflictRow struct {
+	conflictID        string
+	sessionID         string
+	entityPluralName  string
+	syncState         syncmsg.AckSyncStateEnum
+	conflictingFields []string
+	ancestorData      []byte
+	localRecordHash   string
+	localIsDelete     bool
+	localData         []byte
+	remoteRecordHash  string
+	remoteIsDelete    bool
+	remoteData        []byte
+	recordCreated     time.Time
+}
+
+//store holds the tables of the sync model. Every operation holds the store (see lock) for its whole duration, which
+//stands in for the transactions and row locks of the sql backends. Rows are never changed once they are in a map; a
+//changed copy is put in their place instead, so a row read earlier keeps describing the record as it was. Tables are
+//only changed through the put and delete methods, which note the change for unlock to save to the persistence, if
+//any. The persistence is only read when the store is created, so the tables have to be the only user of it.
+type store struct {
+	mutex        sync.Mutex
+	persistence  Persistence
+	changes      changeSet
+	dataVersions map[string]time.Time
+	entityNames  []string
+	entities     map[string]*entityRow
+	nodes        map[string]*syncdao.NodePairItem
+	pairs        map[string]*syncdao.SyncPair
+	pairNodes    []pairNodeRow
+	states       map[recordKey]*stateRow
+	peerStates   map[peerKey]*peerStateRow
+	ancestors    map[ancestorKey][]byte
+	conflicts    map[peerKey]*conflictRow
+	//endedSessions holds the sync_session_history rows in the order they were added.
+	endedSessions []syncapi.EndedSession
+	//mgmtMsgs holds the sync_session_mgmt_msg rows by order of MsgSeq, which starts at 1.
+	mgmtMsgs []syncapi.SessionMgmtMsg
+}
+
+//changeSet holds the rows changed since the store was last saved. A row changed and then found missing
+//from its table was deleted. Rows of sync_pair_nodes, sync_session_history and sync_session_mgmt_msg are mostly
+//added, so the last pairNodes, endedSessions and mgmtMsgs of them are the new ones. A changed sync_pair_nodes row is
+//noted in changedPairNodes by its position. Deleting rows of sync_pair_nodes or sync_session_history moves the rows
+//after them, so the whole table is written again.
+type changeSet struct {
+	reset        bool
+	dataVersions map[string]bool
+	entities     map[string]bool
+	nodes        map[string]bool
+	pairs        map[string]bool
+	pairNodes    int
+	//changedPairNodes holds the positions in pairNodes of the rows whose session progress or configuration changed.
+	changedPairNodes map[int]bool
+	states           map[recordKey]bool
+	peerStates       map[peerKey]bool
+	ancestors        map[ancestorKey]bool
+	conflicts        map[peerKey]bool
+	//endedSessions counts the sync_session_history rows added.
+	endedSessions int
+	//pairNodesRewritten is set once rows of sync_pair_nodes are deleted.
+	pairNodesRewritten bool
+	//endedSessionsRewritten is set once rows of sync_session_history are deleted.
+	endedSessionsRewritten bool
+	//mgmtMsgs counts the sync_session_mgmt_msg rows added.
+	mgmtMsgs int
+}
+
+func newChangeSet() changeSet {
+	return changeSet{
+		dataVersions:     make(map[string]bool),
+		entities:         make(map[string]bool),
+		nodes:            make(map[string]bool),
+		pairs:            make(map[string]bool),
+		changedPairNodes: make(map[int]bool),
+		states:           make(map[recordKey]bool),
+		peerStates:       make(map[peerKey]bool),
+		ancestors:        make(map[ancestorKey]bool),
+		conflicts:        make(map[peerKey]bool),
+	}
+}
+
+func (changes changeSet) isEmpty() bool {
+	return !changes.reset && !changes.pairNodesRewritten && !changes.endedSessionsRewritten && changes.pairNodes == 0 &&
+		changes.endedSessions == 0 && changes.mgmtMsgs == 0 &&
+		len(changes.dataVersions) == 0 && len(changes.entities) == 0 && len(changes.nodes) == 0 && len(changes.pairs) == 0 &&
+		len(changes.changedPairNodes) == 0 && len(changes.states) == 0 && len(changes.peerStates) == 0 &&
+		len(changes.ancestors) == 0 && len(changes.conflicts) == 0
+}
+
+//newStore creates a store holding empty tables, which are not saved anywhere.
+func newStore() *store {
+	answer := &store{}
+	answer.clear()
+	return answer
+}
+
+//newPersistentStore creates a store holding the tables loaded from persistence, to which every change is saved.
+func newPersistentStore(persistence Persistence) (*store, error) {
+	answer := &store{persistence: persistence}
+	err := answer.load()
+	if err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+//lock takes the store for an operation. It is given back with unlock or release.
+func (store *store) lock() {
+	store.mutex.Lock()
+}
+
+//unlock saves the changes made since lock to the persistence all at once and gives the store back. When saving
+//fails, the tables are loaded from the persistence again so that they keep matching it, and the error is given.
+func (store *store) unlock() error {
+	defer store.mutex.Unlock()
+	if store.persistence == nil || store.changes.isEmpty() {
+		store.changes = newChangeSet()
+		return nil
+	}
+	writes, err := store.writes()
+	if err == nil {
+		err = store.persistence.Save(writes)
+	}
+	if err != nil {
+		syncutil.Error(err, ". Error saving changes, loading the sync model again")
+		loadErr := store.load()
+		if loadErr != nil {
+			syncutil.Error(loadErr, ". Error loading the sync model")
+		}
+		return err
+	}
+	store.changes = newChangeSet()
+	return nil
+}
+
+//release is unlock for a deferred call. The error of unlock is kept in err unless the operation failed already.
+func (store *store) release(err *error) {
+	unlockErr := store.unlock()
+	if unlockErr != nil && *err == nil {
+		*err = unlockErr
+	}
+}
+
+//reset empties every table. The caller holds the store.
+func (store *store) reset() {
+	store.clear()
+	store.changes.reset = true
+}
+
+func (store *store) clear() {
+	store.changes = newChangeSet()
+	store.dataVersions = make(map[string]time.Time)
+	store.entityNames = []string{}
+	store.entities = make(map[string]*entityRow)
+	store.nodes = make(map[string]*syncdao.NodePairItem)
+	store.pairs = make(map[string]*syncdao.SyncPair)
+	store.pairNodes = []pairNodeRow{}
+	store.states = make(map[recordKey]*stateRow)
+	store.peerStates = make(map[peerKey]*peerStateRow)
+	store.ancestors = make(map[ancestorKey][]byte)
+	store.conflicts = make(map[peerKey]*conflictRow)
+	store.endedSessions = []syncapi.EndedSession{}
+	store.mgmtMsgs = []syncapi.SessionMgmtMsg{}
+}
+
+func (store *store) putDataVersion(dataVersionName string, recordCreated time.Time) {
+	store.dataVersions[dataVersionName] = recordCreated
+	store.changes.dataVersions[dataVersionName] = true
+}
+
+//putEntity adds the entity or notes the change of its fields.
+func (store *store) putEntity(entity *entityRow) {
+	entitySingularName := entity.item.EntitySingularName
+	if _, found := store.entities[entitySingularName]; !found {
+		store.entityNames = append(store.entityNames, entitySingularName)
+	}
+	store.entities[entitySingularName] = entity
+	store.changes.entities[entitySingularName] = true
+}
+
+func (store *store) putNode(node *syncdao.NodePairItem) {
+	store.nodes[node.NodeID] = node
+	store.changes.nodes[node.NodeID] = true
+}
+
+func (store *store) deleteNode(nodeID string) {
+	delete(store.nodes, nodeID)
+	store.changes.nodes[nodeID] = true
+}
+
+func (store *store) putPair(pair *syncdao.SyncPair) {
+	store.pairs[pair.PairID] = pair
+	store.changes.pairs[pair.PairID] = true
+}
+
+func (store *store) deletePair(pairID string) {
+	delete(store.pairs, pairID)
+	store.changes.pairs[pairID] = true
+}
+
+func (store *store) addPairNode(row pairNodeRow) {
+	store.pairNodes = append(store.pairNodes, row)
+	store.changes.pairNodes++
+}
+
+//putPairNode puts row, whose session progress or configuration changed, at position of pairNodes.
+func (store *store) putPairNode(position int, row pairNodeRow) {
+	store.pairNodes[position] = row
+	store.changes.changedPairNodes[position] = true
+}
+
+//putPairNodes replaces the rows of pairNodes by rows, having deleted some of them.
+func (store *store) putPairNodes(rows []pairNodeRow) {
+	store.pairNodes = rows
+	store.changes.pairNodesRewritten = true
+}
+
+//putEndedSessions replaces the rows of endedSessions by rows, having deleted some of them.
+func (store *store) putEndedSessions(rows []syncapi.EndedSession) {
+	store.endedSessions = rows
+	store.changes.endedSessionsRewritten = true
+}
+
+func (store *store) addEndedSession(ended syncapi.EndedSession) {
+	store.endedSessions = append(store.endedSessions, ended)
+	store.changes.endedSessions++
+}
+
+func (store *store) addMgmtMsg(msg syncapi.SessionMgmtMsg) {
+	store.mgmtMsgs = append(store.mgmtMsgs, msg)
+	store.changes.mgmtMsgs++
+}
+
+func (store *store) putState(key recordKey, row *stateRow) {
+	store.states[key] = row
+	store.changes.states[key] = true
+}
+
+func (store *store) deleteState(key recordKey) {
+	delete(store.states, key)
+	store.changes.states[key] = true
+}
+
+func (store *store) putPeerState(key peerKey, row *peerStateRow) {
+	store.peerStates[key] = row
+	store.changes.peerStates[key] = true
+}
+
+func (store *store) deletePeerState(key peerKey) {
+	delete(store.peerStates, key)
+	store.changes.peerStates[key] = true
+}
+
+func (store *store) putAncestor(key ancestorKey, recordData []byte) {
+	store.ancestors[key] = recordData
+	store.changes.ancestors[key] = true
+}
+
+func (store *store) deleteAncestor(key ancestorKey) {
+	delete(store.ancestors, key)
+	store.changes.ancestors[key] = true
+}
+
+func (store *store) putConflict(key peerKey, row *conflictRow) {
+	store.conflicts[key] = row
+	store.changes.conflicts[key] = true
+}
+
+func (store *store) deleteConflict(key peerKey) {
+	delete(store.conflicts, key)
+	store.changes.conflicts[key] = true
+}
+
+//versionEntities gives the entities of a data version in the order they were added.
+func (store *store) versionEntities(dataVersionName string) []*entityRow {
+	answer := []*entityRow{}
+	for _, entitySingularName := range store.entityNames {
+		entity := store.entities[entitySingularName]
+		if entity.dataVersionName == dataVersionName {
+			answer = append(answer, entity)
+		}
+	}
+	return answer
+}
+
+//findEntityByPluralName gives the entity of a data version having the plural name, if any.
+func (store *store) findEntityByPluralName(dataVersionName string, entityPluralName string) (*entityRow, bool) {
+	for _, entity := range store.versionEntities(dataVersionName) {
+		if entity.item.EntityPluralName == entityPluralName {
+			return entity, true
+		}
+	}
+	return nil, false
+}
+
+//findNodeEntityFields gives the field definitions of an entity of the node's data version by field name. The map is
+//empty when the node or the entity is unknown.
+func (store *store) findNodeEntityFields(nodeID string, entitySingularName string) map[string]syncdao.SyncFieldDefinition {
+	answer := make(map[string]syncdao.SyncFieldDefinition)
+	node, found := store.nodes[nodeID]
+	if !found {
+		return answer
+	}
+	entity, found := store.entities[entitySingularName]
+	if !found || entity.dataVersionName != node.DataVersionName {
+		return answer
+	}
+	for fieldName, fieldDefinition := range entity.fields {
+		answer[fieldName] = fieldDefinition
+	}
+	return answer
+}
+
+//nodePeerKeys gives the keys of the node's sync_peer_state rows accepted by filter, ordered by entity and record id.
+func (store *store) nodePeerKeys(nodeID string, filter func(key peerKey, peer *peerStateRow) bool) []peerKey {
+	answer := []peerKey{}
+	for key, peer := range store.peerStates {
+		if key.nodeID == nodeID && filter(key, peer) {
+			answer = append(answer, key)
+		}
+	}
+	sortPeerKeys(answer)
+	return answer
+}
+
+func sortPeerKeys(keys []peerKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].entitySingularName != keys[j].entitySingularName {
+			return keys[i].entitySingularName < keys[j].entitySingularName
+		}
+		if keys[i].recordID != keys[j].recordID {
+			return keys[i].recordID < keys[j].recordID
+		}
+		return keys[i].nodeID < keys[j].nodeID
+	})
+}
+
+//sameID compares ids as sql does, where null (the empty string) equals nothing.
+func sameID(stored string, requested string) bool {
+	return stored != "" && stored == requested
+}
+
+//copyBytes gives a copy of data so rows do not share memory with messages that are still in use.
+func copyBytes(data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+	return append([]byte{}, data...)
+}
+
+//storeTx undoes the changes made through it when it is rolled back, standing in for a sql transaction. It does not
+//need committing. The caller holds the store for the life of the storeTx, and what is left of the changes once it is
+//done is saved by unlock.
+type storeTx struct {
+	store *store
+	undo  []func()
+}
+
+func (store *store) begin() *storeTx {
+	return &storeTx{store: store}
+}
+
+func (tx *storeTx) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	tx.undo = nil
+}
+
+func (tx *storeTx) putState(key recordKey, row *stateRow) {
+	previous, existed := tx.store.states[key]
+	tx.store.putState(key, row)
+	tx.undo = append(tx.undo, func() {
+		if existed {
+			tx.store.putState(key, previous)
+		} else {
+			tx.store.deleteState(key)
+		}
+	})
+}
+
+func (tx *storeTx) deleteState(key recordKey) {
+	previous, existed := tx.store.states[key]
+	if !existed {
+		return
+	}
+	tx.store.deleteState(key)
+	tx.undo = append(tx.undo, func() {
+		tx.store.putState(key, previous)
+	})
+}
+
+func (tx *storeTx) putPeerState(key peerKey, row *peerStateRow) {
+	previous, existed := tx.store.peerStates[key]
+	tx.store.putPeerState(key, row)
+	tx.undo = append(tx.undo, func() {
+		if existed {
+			tx.store.putPeerState(key, previous)
+		} else {
+			tx.store.deletePeerState(key)
+		}
+	})
+}
+
+func (tx *storeTx) deletePeerState(key peerKey) {
+	previous, existed := tx.store.peerStates[key]
+	if !existed {
+		return
+	}
+	tx.store.deletePeerState(key)
+	tx.undo = append(tx.undo, func() {
+		tx.store.putPeerState(key, previous)
+	})
+}
+
+func (tx *storeTx) deleteAncestor(key ancestorKey) {
+	previous, existed := tx.store.ancestors[key]
+	if !existed {
+		return
+	}
+	tx.store.deleteAncestor(key)
+	tx.undo = append(tx.undo, func() {
+		tx.store.putAncestor(key, previous)
+	})
+}
+
+func (tx *storeTx) putConflict(key peerKey, row *conflictRow) {
+	previous, existed := tx.store.conflicts[key]
+	tx.store.putConflict(key, row)
+	tx.undo = append(tx.undo, func() {
+		if existed {
+			tx.store.putConflict(key, previous)
+		} else {
+			tx.store.deleteConflict(key)
+		}
+	})
+}
+
+func (tx *storeTx) deleteConflict(key peerKey) {
+	previous, existed := tx.store.conflicts[key]
+	if !existed {
+		return
+	}
+	tx.store.deleteConflict(key)
+	tx.undo = append(tx.undo, func() {
+		tx.store.putConflict(key, previous)
+	})
+}
+
+//findNodeByName gives the node named nodeName, if any.
+func (store *store) findNodeByName(nodeName string) (*syncdao.NodePairItem, bool) {
+	for _, node := range store.nodes {
+		if node.NodeName == nodeName {
+			return node, true
+		}
+	}
+	return nil, false
+}
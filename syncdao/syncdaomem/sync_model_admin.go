@@ -0,0 +1,50 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncdao"
+	"sort"
+)
+
+//entityDefinition gives the entity along with its fields ordered by FieldName.
+func (entity *entityRow) entityDefinition() syncdao.EntityDefinition {
+	answer := syncdao.EntityDefinition{Entity: entity.item, Fields: []syncdao.SyncFieldDefinition{}}
+	for _, field := range entity.fields {
+		answer.Fields = append(answer.Fields, field)
+	}
+	sort.Slice(answer.Fields, func(i, j int) bool {
+		return answer.Fields[i].FieldName < answer.Fields[j].FieldName
+	})
+	return answer
+}
+
+//ListDataVersions implements the syncdao.SyncPairDao.ListDataVersions interface as an in memory implementation.
+func (dao SyncPairMemoryDao) ListDataVersions() ([]string, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	answer := []string{}
+	for dataVersionName := range dao.store.dataVersions {
+		answer = append(answer, dataVersionName)
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
+
+//ListEntities implements the syncdao.SyncPairDao.ListEntities interface as an in memory implementation.
+func (dao SyncPairMemoryDao) ListEntities(dataVersionName string) ([]syncdao.EntityDefinition, error) {
+	dao.store.lock()
+	defer dao.store.unlock()
+	answer := []syncdao.EntityDefinition{}
+	if _, found := dao.store.dataVersions[dataVersionName]; !found {
+		return answer, syncdao.ErrDaoNoDataFound
+	}
+	for _, entity := range dao.store.versionEntities(dataVersionName) {
+		answer = append(answer, entity.entityDefinition())
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		if answer[i].Entity.ProcessOrderAddUpdate != answer[j].Entity.ProcessOrderAddUpdate {
+			return answer[i].Entity.ProcessOrderAddUpdate < answer[j].Entity.ProcessOrderAddUpdate
+		}
+		return answer[i].Entity.EntitySingularName < answer[j].Entity.EntitySingularName
+	})
+	return answer, nil
+}
@@ -0,0 +1,85 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"time"
+)
+
+//NewTombstoneRepository provides access to the sync data held by factory for a TombstoneRepository.
+func NewTombstoneRepository(factory *MemoryDaosFactory) syncapi.TombstoneRepositoryable {
+	return tombstoneRepositoryType{
+		store: factory.store,
+	}
+}
+
+type tombstoneRepositoryType struct {
+	store *store
+}
+
+//CollectTombstones purges the tombstones found collectable along with their peer states, ancestors and conflicts.
+func (tombstoneRepository tombstoneRepositoryType) CollectTombstones(retention time.Duration) (answer syncapi.TombstoneCollectResult, err error) {
+	if retention < 0 {
+		retention = 0
+	}
+	answer = syncapi.TombstoneCollectResult{
+		Retention:      retention.String(),
+		PurgedByEntity: map[string]int{},
+	}
+	store := tombstoneRepository.store
+	store.lock()
+	defer store.release(&err)
+	cutoff := time.Now().Add(-retention)
+	collected := map[recordKey]bool{}
+	for key, state := range store.states {
+		if !state.isDelete {
+			continue
+		}
+		deletedDate := state.deletedDate
+		if deletedDate.IsZero() {
+			deletedDate = state.recordCreated
+		}
+		if (retention > 0 && deletedDate.Before(cutoff)) || store.isTombstoneAcknowledged(key) {
+			collected[key] = true
+			answer.PurgedByEntity[key.entitySingularName]++
+			answer.TotalPurged++
+		}
+	}
+	for key := range store.conflicts {
+		if collected[key.recordKey] {
+			store.deleteConflict(key)
+		}
+	}
+	for key := range store.ancestors {
+		if collected[key.recordKey] {
+			store.deleteAncestor(key)
+		}
+	}
+	for key := range store.peerStates {
+		if collected[key.recordKey] {
+			store.deletePeerState(key)
+		}
+	}
+	for key := range collected {
+		store.deleteState(key)
+	}
+	syncutil.Info("Purged", answer.TotalPurged, "tombstones with retention", answer.Retention, ":", answer.PurgedByEntity)
+	return answer, nil
+}
+
+//isTombstoneAcknowledged tells whether every paired node has the record marked deleted without pending changes,
+//either because the delete was sent to it and acknowledged or because the node sent the delete.
+func (store *store) isTombstoneAcknowledged(key recordKey) bool {
+	pairedNodes := map[string]bool{}
+	for _, pairNode := range store.pairNodes {
+		pairedNodes[pairNode.nodeID] = true
+		pairedNodes[pairNode.targetNodeID] = true
+	}
+	for nodeID := range pairedNodes {
+		peer, found := store.peerStates[peerKey{nodeID: nodeID, recordKey: key}]
+		if found && !(peer.isDelete && !peer.changedByClient && !peer.isConflict && peer.transactionBindSendID == "") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package syncdaomem
+
+import (
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTombstone_CollectTombstones(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	factory := newTestFactory("profile5")
+	acknowledgedContactID := "0934A378-DEDB-4207-B99C-DD0D61DC59BC"
+	pendingContactID := "911DD745-8916-41C4-9973-F8B38A501602"
+
+	//Both contacts are deleted locally, only the first delete was acknowledged by *node-spoke1
+	deletedDate := time.Now().Add(-48 * time.Hour)
+	for _, recordID := range []string{acknowledgedContactID, pendingContactID} {
+		updateState(factory, "Contact", recordID, func(state *stateRow) {
+			state.isDelete = true
+			state.deletedDate = deletedDate
+		})
+	}
+	updatePeerState(factory, "*node-spoke1", "Contact", acknowledgedContactID, func(peer *peerStateRow) {
+		peer.isDelete = true
+		peer.changedByClient = false
+	})
+
+	tombstoneRepo := NewTombstoneRepository(factory)
+	result, err := tombstoneRepo.CollectTombstones(0)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 1, result.TotalPurged)
+	assert.Equal(t, 1, result.PurgedByEntity["Contact"])
+	assert.Nil(t, findState(factory, "Contact", acknowledgedContactID))
+	assert.Nil(t, findPeerState(factory, "*node-spoke1", "Contact", acknowledgedContactID))
+	assert.NotNil(t, findState(factory, "Contact", pendingContactID))
+
+	//The pending delete is kept within the retention window and purged once past it
+	result, err = tombstoneRepo.CollectTombstones(72 * time.Hour)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 0, result.TotalPurged)
+	result, err = tombstoneRepo.CollectTombstones(24 * time.Hour)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 1, result.TotalPurged)
+	assert.Nil(t, findPeerState(factory, "*node-spoke1", "Contact", pendingContactID))
+
+	testhelper.EndTest(testName)
+}
@@ -0,0 +1,48 @@
+package syncdaopq
+
+import (
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"data-sync-tools-go/syncdao/syncdaosql/syncdaosqltest"
+	"data-sync-tools-go/syncdao/syncdaotest"
+	"data-sync-tools-go/testhelper"
+	"database/sql"
+	"testing"
+)
+
+var (
+	testDbUser     = testhelper.TestDbUser
+	testDbPassword = testhelper.TestDbPassword
+	testDbName     = testhelper.TestDbName
+	testDbHost     = testhelper.TestDbHost
+	testDbPort     = testhelper.TestDbPort
+)
+
+func TestSQL(t *testing.T) {
+	syncdaosqltest.Run(t, syncdaosqltest.Database{
+		Dialect: Dialect{},
+		Open: func() (*sql.DB, error) {
+			return createAndVerifyDBConn(testDbUser, testDbPassword, testDbHost, testDbName, testDbPort)
+		},
+	})
+}
+
+func TestConformance(t *testing.T) {
+	syncdaotest.Run(t, func(profile string) (syncdaotest.Fixture, error) {
+		factory, err := NewPostgresSQLDaosFactory(testDbUser, testDbPassword, testDbHost, testDbName, testDbPort)
+		if err != nil {
+			return syncdaotest.Fixture{}, err
+		}
+		db := factory.SQLDb()
+		testhelper.SetupData(db, profile)
+		fixture := syncdaotest.Fixture{
+			Daos:          factory,
+			DataRepo:      syncdaosql.NewDataRepository(db, Dialect{}),
+			ConflictRepo:  syncdaosql.NewConflictRepository(db, Dialect{}),
+			TombstoneRepo: syncdaosql.NewTombstoneRepository(db, Dialect{}),
+			SessionRepo:   syncdaosql.NewSessionRepository(db),
+			NodeAdminRepo: syncdaosql.NewNodeAdminRepository(db),
+			Local:         syncdaotest.NewSQLLocalChanger(db),
+		}
+		return fixture, nil
+	})
+}
@@ -0,0 +1,36 @@
+package syncdaopq
+
+import (
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+//Dialect is the syncdaosql.Dialect of postgressql.
+type Dialect struct{}
+
+//Now implements syncdaosql.Dialect.Now.
+func (Dialect) Now() string {
+	return "now()"
+}
+
+//ForUpdate implements syncdaosql.Dialect.ForUpdate with a row lock.
+func (Dialect) ForUpdate() string {
+	return " for update"
+}
+
+//QuoteIdentifier implements syncdaosql.Dialect.QuoteIdentifier. Custom tables are created without quoting, so
+//Postgres folds their names to lower case; quoting the folded name keeps them addressable while the mixed case names
+//from sync_data_entity and sync_data_field can never be read as anything but a single identifier.
+func (Dialect) QuoteIdentifier(name string) string {
+	return pq.QuoteIdentifier(strings.ToLower(name))
+}
+
+//Cast implements syncdaosql.Dialect.Cast.
+func (Dialect) Cast(expr string, columnType syncdaosql.ColumnType) string {
+	if columnType == syncdaosql.ColumnTypeTimestamp {
+		return expr + "::timestamp"
+	}
+	return expr + "::varchar"
+}
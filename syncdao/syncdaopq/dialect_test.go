@@ -0,0 +1,10 @@
+package syncdaopq
+
+import (
+	"data-sync-tools-go/syncdao/syncdaosql/syncdaosqltest"
+	"testing"
+)
+
+func FuzzQuoteIdentifier(f *testing.F) {
+	syncdaosqltest.FuzzQuoteIdentifier(f, Dialect{})
+}
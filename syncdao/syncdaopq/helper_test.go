@@ -1,5 +0,0 @@
-package syncdaopq
-
-import "encoding/hex"
-
-var dummyData = hex.EncodeToString([]byte("<record data>"))
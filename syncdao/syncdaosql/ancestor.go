@@ -0,0 +1,108 @@
+package syncdaosql
+
+import (
+	"database/sql"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+
+	"github.com/golang/protobuf/proto"
+)
+
+//The versions of a record exchanged with a peer are kept in sync_state_ancestor so that, when both sides change the
+//record, the version identified by the peer's LastKnownPeerHash is available as the common ancestor for a three-way
+//merge. Only the versions still referenced by sync_state or sync_peer_state are kept.
+
+//saveReceivedAncestors keeps the current version of every record received from nodeID under transactionBindID.
+func saveReceivedAncestors(db *sql.DB, nodeID string, transactionBindID string) error {
+	return saveAncestors(db, sqlSaveReceivedAncestors, sqlPruneReceivedAncestors, nodeID, transactionBindID)
+}
+
+//saveSentAncestors keeps the current version of every record sent to nodeID under transactionBindID.
+func saveSentAncestors(db execer, nodeID string, transactionBindID string) error {
+	return saveAncestors(db, sqlSaveSentAncestors, sqlPruneSentAncestors, nodeID, transactionBindID)
+}
+
+func saveAncestors(db execer, saveSQL string, pruneSQL string, nodeID string, transactionBindID string) error {
+	_, err := db.Exec(saveSQL, nodeID, transactionBindID)
+	if err != nil {
+		syncutil.Error(err, ". Error saving ancestor records for nodeId:", nodeID, "transactionBindId:", transactionBindID)
+		return err
+	}
+	_, err = db.Exec(pruneSQL, nodeID, transactionBindID)
+	if err != nil {
+		syncutil.Error(err, ". Error pruning ancestor records for nodeId:", nodeID, "transactionBindId:", transactionBindID)
+		return err
+	}
+	return nil
+}
+
+//findAncestorRecord gives the version of a record with the given hash. syncdao.ErrDaoNoDataFound is given when that
+//version is not kept.
+func findAncestorRecord(db *sql.DB, entitySingularName string, recordID string, recordHash string) (*syncmsg.ProtoRecord, error) {
+	var recordData string
+	sqlStr := `
+SELECT        RecordData
+FROM            sync_state_ancestor
+WHERE        (EntitySingularName = $1 AND RecordId = $2 AND RecordHash = $3);
+`
+	err := db.QueryRow(sqlStr, entitySingularName, recordID, recordHash).Scan(&recordData)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, syncdao.ErrDaoNoDataFound
+	case err != nil:
+		syncutil.Error(err, ". Error finding ancestor of record", recordID)
+		return nil, err
+	}
+	recordBytes, err := decodeStoredRecordData(recordData)
+	if err != nil {
+		syncutil.Error(err, ". Error decoding ancestor of record", recordID)
+		return nil, err
+	}
+	record := &syncmsg.ProtoRecord{}
+	err = proto.Unmarshal(recordBytes, record)
+	if err != nil {
+		syncutil.Error(err, ". Error unmarshaling ancestor of record", recordID)
+		return nil, err
+	}
+	return record, nil
+}
+
+const (
+	sqlSaveReceivedAncestors = `
+insert into sync_state_ancestor (EntitySingularName, RecordId, RecordHash, RecordData)
+select sync_state.EntitySingularName, sync_state.RecordId, sync_state.RecordHash, sync_state.RecordData from sync_state INNER JOIN
+                         sync_peer_state ON sync_state.EntitySingularName = sync_peer_state.EntitySingularName AND
+                         sync_state.RecordId = sync_peer_state.RecordId
+where (sync_peer_state.NodeId = $1 AND sync_peer_state.TransactionBindReceiveId = $2)
+on conflict do nothing;
+`
+	sqlSaveSentAncestors = `
+insert into sync_state_ancestor (EntitySingularName, RecordId, RecordHash, RecordData)
+select sync_state.EntitySingularName, sync_state.RecordId, sync_state.RecordHash, sync_state.RecordData from sync_state INNER JOIN
+                         sync_peer_state ON sync_state.EntitySingularName = sync_peer_state.EntitySingularName AND
+                         sync_state.RecordId = sync_peer_state.RecordId
+where (sync_peer_state.NodeId = $1 AND sync_peer_state.TransactionBindSendId = $2)
+on conflict do nothing;
+`
+	sqlPruneReceivedAncestors = `
+delete from sync_state_ancestor as a where exists (
+	select 1 from sync_peer_state p where p.NodeId = $1 AND p.TransactionBindReceiveId = $2 AND
+		p.EntitySingularName = a.EntitySingularName AND p.RecordId = a.RecordId
+) ` + sqlPruneAncestorsStillReferenced
+
+	sqlPruneSentAncestors = `
+delete from sync_state_ancestor as a where exists (
+	select 1 from sync_peer_state p where p.NodeId = $1 AND p.TransactionBindSendId = $2 AND
+		p.EntitySingularName = a.EntitySingularName AND p.RecordId = a.RecordId
+) ` + sqlPruneAncestorsStillReferenced
+
+	sqlPruneAncestorsStillReferenced = `AND NOT exists (
+	select 1 from sync_state s where s.EntitySingularName = a.EntitySingularName AND s.RecordId = a.RecordId AND
+		s.RecordHash = a.RecordHash
+) AND NOT exists (
+	select 1 from sync_peer_state r where r.EntitySingularName = a.EntitySingularName AND r.RecordId = a.RecordId AND
+		(r.PeerLastKnownHash = a.RecordHash OR r.SentLastKnownHash = a.RecordHash)
+);
+`
+)
@@ -0,0 +1,286 @@
+package syncdaosql
+
+import (
+	"database/sql"
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/twinj/uuid"
+)
+
+//NewConflictRepository provides sql database access for a ConflictRepository.
+func NewConflictRepository(db *sql.DB, dialect Dialect) syncapi.ConflictRepositoryable {
+	return conflictRepositoryType{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+type conflictRepositoryType struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+const sqlSelectConflicts = `
+SELECT        ConflictId, NodeId, SessionId, EntitySingularName, EntityPluralName, RecordId, SyncState, ConflictingFields,
+                         AncestorRecordData, LocalRecordHash, LocalIsDelete, LocalRecordData, RemoteRecordHash,
+                         RemoteIsDelete, RemoteRecordData, RecordCreated
+FROM            sync_conflict
+`
+
+func (conflictRepository conflictRepositoryType) FindConflicts(nodeID string) ([]syncapi.ConflictItem, error) {
+	answer := []syncapi.ConflictItem{}
+	rows, err := conflictRepository.db.Query(sqlSelectConflicts+"WHERE        (NodeId = $1)\nORDER BY RecordCreated, EntitySingularName, RecordId;", nodeID)
+	if err != nil {
+		syncutil.Error(err, ". Error finding conflicts for nodeId:", nodeID)
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		item, err := scanConflict(rows)
+		if err != nil {
+			return answer, err
+		}
+		answer = append(answer, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		syncutil.Error(err, ". Error reading conflicts for nodeId:", nodeID)
+		return answer, err
+	}
+	return answer, nil
+}
+
+func (conflictRepository conflictRepositoryType) GetConflict(conflictID string) (syncapi.ConflictItem, error) {
+	row := conflictRepository.db.QueryRow(sqlSelectConflicts+"WHERE        (ConflictId = $1);", conflictID)
+	item, err := scanConflict(row)
+	switch {
+	case err == sql.ErrNoRows:
+		return item, syncapi.ErrConflictNotFound
+	case err != nil:
+		return item, err
+	}
+	return item, nil
+}
+
+//ResolveConflict applies the chosen version of the record locally and points the peer state at the peer's version
+//so that the next sync with the peer is a fast batch. When the kept version differs from the peer's, the record is
+//flagged as changed by the client so it is sent to the peer.
+func (conflictRepository conflictRepositoryType) ResolveConflict(conflictID string, choice syncapi.ConflictResolutionChoice, mergedRecord *syncmsg.ProtoRecord) (string, error) {
+	conflict, err := conflictRepository.GetConflict(conflictID)
+	if err != nil {
+		return "", err
+	}
+	var resolution syncapi.ConflictResolution
+	switch choice {
+	case syncapi.ConflictResolutionChoiceLocal:
+		resolution = syncapi.ResolveWithVersion(conflict.Local)
+	case syncapi.ConflictResolutionChoiceRemote:
+		resolution = syncapi.ResolveWithVersion(conflict.Remote)
+	case syncapi.ConflictResolutionChoiceMerged:
+		if mergedRecord == nil {
+			return "", syncapi.ErrConflictMergedRecordMissing
+		}
+		resolution = syncapi.ConflictResolution{Resolved: true, Record: mergedRecord}
+	default:
+		return "", fmt.Errorf("unknown conflict resolution choice '%v'", choice)
+	}
+
+	localRecord := readInitialTransactionBindResult{
+		entitySingularName: conflict.EntitySingularName,
+		entityPluralName:   conflict.EntityPluralName,
+		recordID:           conflict.RecordID,
+		recordHash:         conflict.Local.RecordHash,
+		isDelete:           conflict.Local.IsDelete,
+	}
+	fieldDefinitions, err := findNodeEntityFields(conflictRepository.db, conflict.RemoteNodeID, conflict.EntitySingularName)
+	if err != nil {
+		syncutil.Error(err)
+		return "", err
+	}
+
+	tx, err := conflictRepository.db.Begin()
+	if err != nil {
+		syncutil.Error(err, ". Error starting transaction for conflict", conflictID)
+		return "", err
+	}
+	var currentHash string
+	var currentIsDelete bool
+	err = tx.QueryRow("select RecordHash, IsDelete from sync_state where (EntitySingularName=$1 AND RecordId=$2)"+conflictRepository.dialect.ForUpdate()+";",
+		conflict.EntitySingularName, conflict.RecordID).Scan(&currentHash, &currentIsDelete)
+	if err != nil {
+		syncutil.Error(err, ". Error reading sync_state for conflict", conflictID)
+		rollbackQuietly(tx)
+		return "", err
+	}
+	if currentHash != conflict.Local.RecordHash || currentIsDelete != conflict.Local.IsDelete {
+		rollbackQuietly(tx)
+		return "", syncapi.ErrConflictStale
+	}
+
+	recordHash := conflict.Local.RecordHash
+	switch {
+	case resolution.IsDelete && !conflict.Local.IsDelete:
+		err = eraseRecord(tx, conflictRepository.dialect, localRecord, conflict.Local.Record, fieldDefinitions)
+	case !resolution.IsDelete:
+		var recordBytes []byte
+		recordBytes, err = proto.Marshal(resolution.Record)
+		if err != nil {
+			syncutil.Error(err, ". Error marshaling record for conflict", conflictID)
+			break
+		}
+		recordHash = hash256Bytes(recordBytes)
+		if recordHash != conflict.Local.RecordHash || conflict.Local.IsDelete {
+			err = writeRecord(tx, conflictRepository.dialect, localRecord, resolution.Record, recordHash, recordBytes, fieldDefinitions)
+		}
+	}
+	if err != nil {
+		rollbackQuietly(tx)
+		return "", err
+	}
+
+	keptRemote := resolution.IsDelete == conflict.Remote.IsDelete && (resolution.IsDelete || recordHash == conflict.Remote.RecordHash)
+	sqlStr := `
+update sync_peer_state set PeerLastKnownHash=$1, IsConflict=false, IsDelete=$2, ChangedByClient=$3, LastUpdated=$4
+where (NodeId=$5 AND EntitySingularName=$6 AND RecordId=$7);`
+	_, err = tx.Exec(sqlStr, conflict.Remote.RecordHash, resolution.IsDelete, !keptRemote, time.Now(), conflict.RemoteNodeID, conflict.EntitySingularName, conflict.RecordID)
+	if err != nil {
+		syncutil.Error(err, ". Error updating sync_peer_state for conflict", conflictID)
+		rollbackQuietly(tx)
+		return "", err
+	}
+	_, err = tx.Exec("delete from sync_conflict where (ConflictId=$1);", conflictID)
+	if err != nil {
+		syncutil.Error(err, ". Error removing conflict", conflictID)
+		rollbackQuietly(tx)
+		return "", err
+	}
+	err = tx.Commit()
+	if err != nil {
+		syncutil.Error(err, ". Error committing resolution of conflict", conflictID)
+		return "", err
+	}
+	return recordHash, nil
+}
+
+//saveConflict keeps both versions of a conflicting record within tx. A conflict already recorded for the same node
+//and record is replaced, keeping its ConflictId.
+func saveConflict(tx *sql.Tx, dialect Dialect, nodeID string, conflict syncapi.RecordConflict, syncState syncmsg.AckSyncStateEnum) error {
+	ancestorData, err := encodeConflictRecord(conflict.Ancestor)
+	if err != nil {
+		syncutil.Error(err, ". Error marshaling ancestor of record", conflict.RecordID)
+		return err
+	}
+	localData, err := encodeConflictRecord(conflict.Local.Record)
+	if err != nil {
+		syncutil.Error(err, ". Error marshaling local record", conflict.RecordID)
+		return err
+	}
+	remoteData, err := encodeConflictRecord(conflict.Remote.Record)
+	if err != nil {
+		syncutil.Error(err, ". Error marshaling remote record", conflict.RecordID)
+		return err
+	}
+	sqlStr := `
+insert into sync_conflict (ConflictId, NodeId, SessionId, EntitySingularName, EntityPluralName, RecordId, SyncState,
+	ConflictingFields, AncestorRecordData, LocalRecordHash, LocalIsDelete, LocalRecordData, RemoteRecordHash,
+	RemoteIsDelete, RemoteRecordData)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+on conflict (NodeId, EntitySingularName, RecordId) do update set SessionId=excluded.SessionId,
+	SyncState=excluded.SyncState, ConflictingFields=excluded.ConflictingFields,
+	AncestorRecordData=excluded.AncestorRecordData, LocalRecordHash=excluded.LocalRecordHash,
+	LocalIsDelete=excluded.LocalIsDelete, LocalRecordData=excluded.LocalRecordData,
+	RemoteRecordHash=excluded.RemoteRecordHash, RemoteIsDelete=excluded.RemoteIsDelete,
+	RemoteRecordData=excluded.RemoteRecordData, RecordCreated=` + dialect.Now() + `;`
+	conflictID := uuid.Formatter(uuid.NewV4(), uuid.FormatCanonical)
+	_, err = tx.Exec(sqlStr, conflictID, nodeID, conflict.SessionID, conflict.EntitySingularName, conflict.EntityPluralName,
+		conflict.RecordID, int32(syncState), strings.Join(conflict.ConflictingFields, ","), ancestorData,
+		conflict.Local.RecordHash, conflict.Local.IsDelete, localData, conflict.Remote.RecordHash, conflict.Remote.IsDelete,
+		remoteData)
+	if err != nil {
+		syncutil.Error(err, ". Error saving conflict for record", conflict.RecordID)
+		return err
+	}
+	return nil
+}
+
+//rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanConflict(row rowScanner) (syncapi.ConflictItem, error) {
+	var (
+		item                                       syncapi.ConflictItem
+		sessionID, conflictingFields, ancestorData sql.NullString
+		localData, remoteData                      sql.NullString
+		syncState                                  int32
+	)
+	err := row.Scan(&item.ConflictID, &item.RemoteNodeID, &sessionID, &item.EntitySingularName, &item.EntityPluralName,
+		&item.RecordID, &syncState, &conflictingFields, &ancestorData, &item.Local.RecordHash, &item.Local.IsDelete,
+		&localData, &item.Remote.RecordHash, &item.Remote.IsDelete, &remoteData, &item.Created)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			syncutil.Error(err, ". Error reading conflict")
+		}
+		return item, err
+	}
+	item.SessionID = sessionID.String
+	item.SyncState = syncmsg.AckSyncStateEnum(syncState).String()
+	item.ConflictingFields = []string{}
+	if conflictingFields.String != "" {
+		item.ConflictingFields = strings.Split(conflictingFields.String, ",")
+	}
+	item.Ancestor, err = decodeConflictRecord(ancestorData)
+	if err == nil {
+		item.Local.Record, err = decodeConflictRecord(localData)
+	}
+	if err == nil {
+		item.Remote.Record, err = decodeConflictRecord(remoteData)
+	}
+	if err != nil {
+		syncutil.Error(err, ". Error decoding conflict", item.ConflictID)
+		return item, err
+	}
+	return item, nil
+}
+
+//encodeConflictRecord gives the record as hex, like sync_state.RecordData, or nil when there is no record.
+func encodeConflictRecord(record *syncmsg.ProtoRecord) (interface{}, error) {
+	if record == nil {
+		return nil, nil
+	}
+	recordBytes, err := proto.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return hex.EncodeToString(recordBytes), nil
+}
+
+func decodeConflictRecord(recordData sql.NullString) (*syncmsg.ProtoRecord, error) {
+	if !recordData.Valid {
+		return nil, nil
+	}
+	recordBytes, err := decodeStoredRecordData(recordData.String)
+	if err != nil {
+		return nil, err
+	}
+	record := &syncmsg.ProtoRecord{}
+	err = proto.Unmarshal(recordBytes, record)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,59 @@
+package syncdaosql
+
+import (
+	"database/sql"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncutil"
+)
+
+//SQLDaosFactory creates the data access objects for data synchronization to a sql database. It implements
+//syncdao.DaosFactory.
+type SQLDaosFactory struct {
+	db          *sql.DB
+	dialect     Dialect
+	syncNodeDao syncdao.SyncNodeDao
+	syncPairDao syncdao.SyncPairDao
+}
+
+//NewSQLDaosFactory creates a SQLDaosFactory instance for the opened database db, whose sql is written in dialect.
+func NewSQLDaosFactory(db *sql.DB, dialect Dialect) *SQLDaosFactory {
+	syncNodeDao := new(SyncNodeSQLDao)
+	syncNodeDao.db = db
+	syncPairDao := new(SyncPairSQLDao)
+	syncPairDao.db = db
+	syncPairDao.dialect = dialect
+	factory := new(SQLDaosFactory)
+	factory.db = db
+	factory.dialect = dialect
+	factory.syncNodeDao = syncNodeDao
+	factory.syncPairDao = syncPairDao
+	return factory
+}
+
+//SQLDb supplies the underlying database instance.
+func (factory SQLDaosFactory) SQLDb() *sql.DB {
+	return factory.db
+}
+
+//Dialect supplies the dialect of the underlying database.
+func (factory SQLDaosFactory) Dialect() Dialect {
+	return factory.dialect
+}
+
+//SyncNodeDao provides the syncdao.SyncNodeDao instance.
+func (factory SQLDaosFactory) SyncNodeDao() syncdao.SyncNodeDao {
+	return factory.syncNodeDao
+}
+
+//SyncPairDao provides the syncdao.SyncPairDao instance.
+func (factory SQLDaosFactory) SyncPairDao() syncdao.SyncPairDao {
+	return factory.syncPairDao
+}
+
+//Close closes the underlying database connection.
+func (factory SQLDaosFactory) Close() {
+	err := factory.db.Close()
+	if err != nil {
+		syncutil.Error("Quietly handling of close error. Error: " + err.Error())
+	}
+}
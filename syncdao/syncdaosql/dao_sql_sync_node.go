@@ -1,4 +1,4 @@
-package syncdaopq
+package syncdaosql
 
 import (
 	"database/sql"
@@ -9,13 +9,13 @@ import (
 	//"strconv"
 )
 
-//SyncNodePostgresSQLDao implements the syncdao.SyncNodeDao interface as a postgressql implementation.
-type SyncNodePostgresSQLDao struct {
+//SyncNodeSQLDao implements the syncdao.SyncNodeDao interface as a sql implementation.
+type SyncNodeSQLDao struct {
 	db *sql.DB
 }
 
-//AddNode implements the syncdao.SyncNodeDao.AddNode interface as a postgressql implementation.
-func (dao SyncNodePostgresSQLDao) AddNode(item syncdao.SyncNode) error {
+//AddNode implements the syncdao.SyncNodeDao.AddNode interface as a sql implementation.
+func (dao SyncNodeSQLDao) AddNode(item syncdao.SyncNode) error {
 	// BUG(doug4j@gmail.com): Add SQL injection checks.
 	// (see http://go-database-sql.org/retrieving.html and
 	// http://stackoverflow.com/questions/26345318/how-can-i-prevent-sql-injection-attacks-in-go-while-using-database-sql)
@@ -30,8 +30,8 @@ func (dao SyncNodePostgresSQLDao) AddNode(item syncdao.SyncNode) error {
 	return nil
 }
 
-//GetOneNodeByNodeName implements the syncdao.SyncNodeDao.GetOneNodeByNodeName interface as a postgressql implementation.
-func (dao SyncNodePostgresSQLDao) GetOneNodeByNodeName(nodeName string) (syncdao.SyncNode, error) {
+//GetOneNodeByNodeName implements the syncdao.SyncNodeDao.GetOneNodeByNodeName interface as a sql implementation.
+func (dao SyncNodeSQLDao) GetOneNodeByNodeName(nodeName string) (syncdao.SyncNode, error) {
 	var (
 		nodeID          string
 		dataVersionName string
@@ -57,8 +57,8 @@ WHERE         (sync_node.nodeName = $1)
 
 }
 
-//GetOneNodeByNodeID implements the syncdao.SyncNodeDao.GetOneNodeByNodeID interface as a postgressql implementation.
-func (dao SyncNodePostgresSQLDao) GetOneNodeByNodeID(nodeID string) (syncdao.SyncNode, error) {
+//GetOneNodeByNodeID implements the syncdao.SyncNodeDao.GetOneNodeByNodeID interface as a sql implementation.
+func (dao SyncNodeSQLDao) GetOneNodeByNodeID(nodeID string) (syncdao.SyncNode, error) {
 	var (
 		nodeName        string
 		dataVersionName string
@@ -83,8 +83,8 @@ WHERE         (sync_node.nodeId = $1)
 	}
 }
 
-//DeleteNodeByNodeID implements the syncdao.SyncNodeDao.DeleteNodeByNodeID interface as a postgressql implementation.
-func (dao SyncNodePostgresSQLDao) DeleteNodeByNodeID(nodeID string) error {
+//DeleteNodeByNodeID implements the syncdao.SyncNodeDao.DeleteNodeByNodeID interface as a sql implementation.
+func (dao SyncNodeSQLDao) DeleteNodeByNodeID(nodeID string) error {
 	var sql = "DELETE from sync_node WHERE (nodeId = $1);"
 
 	result, err := dao.db.Exec(sql, nodeID)
@@ -102,3 +102,30 @@ func (dao SyncNodePostgresSQLDao) DeleteNodeByNodeID(nodeID string) error {
 	}
 	return nil
 }
+
+//ListNodes implements the syncdao.SyncNodeDao.ListNodes interface as a sql implementation.
+func (dao SyncNodeSQLDao) ListNodes() ([]syncdao.SyncNode, error) {
+	answer := []syncdao.SyncNode{}
+	rows, err := dao.db.Query("SELECT NodeId, NodeName, DataVersionName FROM sync_node ORDER BY NodeName;")
+	if err != nil {
+		syncutil.Error(err, ". Error listing nodes")
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		var node syncdao.SyncNode
+		err = rows.Scan(&node.NodeID, &node.NodeName, &node.DataVersionName)
+		if err != nil {
+			syncutil.Error(err, ". Error reading nodes")
+			return answer, err
+		}
+		answer = append(answer, node)
+	}
+	return answer, rows.Err()
+}
@@ -1,28 +1,28 @@
-package syncdaopq
+package syncdaosql
 
 import (
 	"database/sql"
+	"data-sync-tools-go/syncapi"
 	"data-sync-tools-go/syncdao"
 	"data-sync-tools-go/syncutil"
 	"errors"
 	"log"
 	"time"
-
-	"github.com/lib/pq"
 )
 
-//SyncPairPostgresSQLDao implements the syncdao.SyncPairDao interface via an postgressql database.
-type SyncPairPostgresSQLDao struct {
-	db *sql.DB
+//SyncPairSQLDao implements the syncdao.SyncPairDao interface via a sql database.
+type SyncPairSQLDao struct {
+	db      *sql.DB
+	dialect Dialect
 }
 
-//GetPairByNames gets the SyncPair by sync node names via a postgressql database.
-func (dao SyncPairPostgresSQLDao) GetPairByNames(requestingNodeName string, toPairWithNodeName string) (syncdao.SyncPair, error) {
+//GetPairByNames gets the SyncPair by sync node names via a sql database.
+func (dao SyncPairSQLDao) GetPairByNames(requestingNodeName string, toPairWithNodeName string) (syncdao.SyncPair, error) {
 	// BUG(doug4j@gmail.com): Add SQL injection checks (see http://go-database-sql.org/retrieving.html and http://stackoverflow.com/questions/26345318/how-can-i-prevent-sql-injection-attacks-in-go-while-using-database-sql
 	var syncPair syncdao.SyncPair
 	sqlStr := `
 SELECT        sync_pair.PairId, sync_pair.PairName, sync_pair.MaxSesDurValue, sync_pair.MaxSesDurUnit, sync_pair.SyncDataTransForm, sync_pair.SyncMsgTransForm,
-                         sync_pair.SyncMsgSecPol, sync_pair.SyncSessionId, sync_pair.SyncSessionState, sync_pair.SyncSessionStart, sync_pair.SyncConflictUri, sync_pair.RecordCreated
+                         sync_pair.SyncMsgSecPol, sync_pair.SyncSessionId, sync_pair.SyncSessionState, sync_pair.SyncSessionStart, sync_pair.SyncSessionHeartbeat, sync_pair.SyncConflictUri, sync_pair.RecordCreated
 FROM            sync_node INNER JOIN
                          sync_pair_nodes ON sync_node.NodeId = sync_pair_nodes.NodeId INNER JOIN
                          sync_pair ON sync_pair_nodes.PairId = sync_pair.PairId where (
@@ -60,29 +60,31 @@ FROM            sync_node INNER JOIN
 				syncMsgSecPol     string
 				syncSessionID     sql.NullString
 				syncSessionState  string
-				syncSessionStart  pq.NullTime
+				syncSessionStart  sql.NullTime
+				syncHeartbeat     sql.NullTime
 				syncConflictURI   string
-				recordCreated     pq.NullTime
+				recordCreated     sql.NullTime
 			)
 			if err := rows.Scan(&pairID, &pairName, &maxSesDurValue, &maxSesDurUnit, &syncDataTransForm,
 				&syncMsgTransForm, &syncMsgSecPol, &syncSessionID, &syncSessionState, &syncSessionStart,
-				&syncConflictURI, &recordCreated); err != nil {
+				&syncHeartbeat, &syncConflictURI, &recordCreated); err != nil {
 
 				return syncPair, err
 			}
 			syncPair = syncdao.SyncPair{
-				PairID:            pairID,
-				PairName:          pairName,
-				MaxSesDurValue:    maxSesDurValue,
-				MaxSesDurUnit:     maxSesDurUnit,
-				SyncDataTransForm: syncDataTransForm,
-				SyncMsgTransForm:  syncMsgTransForm,
-				SyncMsgSecPol:     syncMsgSecPol,
-				SyncSessionID:     syncSessionID.String,
-				SyncSessionState:  syncSessionState,
-				SyncSessionStart:  syncSessionStart.Time,
-				SyncConflictURI:   syncConflictURI,
-				RecordCreated:     recordCreated.Time,
+				PairID:               pairID,
+				PairName:             pairName,
+				MaxSesDurValue:       maxSesDurValue,
+				MaxSesDurUnit:        maxSesDurUnit,
+				SyncDataTransForm:    syncDataTransForm,
+				SyncMsgTransForm:     syncMsgTransForm,
+				SyncMsgSecPol:        syncMsgSecPol,
+				SyncSessionID:        syncSessionID.String,
+				SyncSessionState:     syncSessionState,
+				SyncSessionStart:     syncSessionStart.Time,
+				SyncSessionHeartbeat: syncHeartbeat.Time,
+				SyncConflictURI:      syncConflictURI,
+				RecordCreated:        recordCreated.Time,
 			}
 		}
 	}
@@ -100,8 +102,8 @@ FROM            sync_node INNER JOIN
 	return syncPair, nil
 }
 
-//GetNodePairItem gets the NodePairItem by pairId and node name via a postgressql database.
-func (dao SyncPairPostgresSQLDao) GetNodePairItem(pairID string, nodeName string) (syncdao.NodePairItem, error) {
+//GetNodePairItem gets the NodePairItem by pairId and node name via a sql database.
+func (dao SyncPairSQLDao) GetNodePairItem(pairID string, nodeName string) (syncdao.NodePairItem, error) {
 	var nodePairItem syncdao.NodePairItem
 	sqlStr := `
 SELECT        sync_node.NodeId, sync_node.Enabled, sync_node.DataMsgConsUri, sync_node.DataMsgProdUri,
@@ -127,7 +129,7 @@ WHERE        (sync_pair_nodes.PairId = $1 and sync_node.NodeName=$2)
 		inChanDepthSize           int
 		maxOutChanDeptSize        int
 		// BUG(doug4j@gmail.com): Leverage record created in output
-		recordCreated pq.NullTime
+		recordCreated sql.NullTime
 	)
 	if err := row.Scan(&nodeID, &enabled, &dataMsgConsumerURI, &dataMsgProducerURI, &mgmtMsgConsumerURI,
 		&mgmtMsgProducerURI, &syncDataPersistanceFormat, &inMsgBatchSize, &maxOutMsgBatchSize,
@@ -156,8 +158,8 @@ WHERE        (sync_pair_nodes.PairId = $1 and sync_node.NodeName=$2)
 	return nodePairItem, nil
 }
 
-//GetEntityPairItem retrieves the EntityPairId by pairId and node name via an postgressql database.
-func (dao SyncPairPostgresSQLDao) GetEntityPairItem(pairID string, nodeName string) ([]syncdao.EntityPairItem, error) {
+//GetEntityPairItem retrieves the EntityPairId by pairId and node name via a sql database.
+func (dao SyncPairSQLDao) GetEntityPairItem(pairID string, nodeName string) ([]syncdao.EntityPairItem, error) {
 	var items []syncdao.EntityPairItem
 	sqlStr := `
 SELECT        sync_data_entity.EntitySingularName, sync_data_entity.EntityPluralName, sync_data_entity.ProcOrderAddUpdate, sync_data_entity.ProcOrderDelete, sync_data_entity.EntityHandlerUri
@@ -209,18 +211,12 @@ WHERE			(sync_node.NodeName = $1);
 	return items, nil
 }
 
-//NullTime represents an empty time value.
-type NullTime struct {
-	Time  time.Time
-	Valid bool // Valid is true if Time is not NULL
-}
-
-//CreateSyncSession creates a session between sync pairs via a postgressql database.
-func (dao SyncPairPostgresSQLDao) CreateSyncSession(item syncdao.CreateSyncSessionRequest) (syncdao.CreateSyncSessionDaoResult, error) {
+//CreateSyncSession creates a session between sync pairs via a sql database.
+func (dao SyncPairSQLDao) CreateSyncSession(item syncdao.CreateSyncSessionRequest) (syncdao.CreateSyncSessionDaoResult, error) {
 	var answer syncdao.CreateSyncSessionDaoResult
 	var actualSessionID string
 	sqlStr := `
-Update sync_pair SET SyncSessionId=$2, SyncSessionStart=$3, SyncSessionState='Initializing'
+Update sync_pair SET SyncSessionId=$2, SyncSessionStart=$3, SyncSessionHeartbeat=null, SyncSessionState='Initializing'
 where SyncSessionState='Inactive' and PairId=$1;`
 	now := time.Now()
 	format := "2006-01-02 15:04:05.000"
@@ -262,6 +258,10 @@ where SyncSessionState='Inactive' and PairId=$1;`
 		}
 		return answer, nil
 	} else if affectedCount == 1 {
+		err = resetSessionProgress(dao.db, item.PairID)
+		if err != nil {
+			return answer, err
+		}
 		answer = syncdao.CreateSyncSessionDaoResult{
 			Result:          "OK",
 			ActualSessionID: item.SessionID,
@@ -277,12 +277,12 @@ where SyncSessionState='Inactive' and PairId=$1;`
 	}
 }
 
-//CloseSyncSession closes a sync session via an postgressql database.
-func (dao SyncPairPostgresSQLDao) CloseSyncSession(item syncdao.CloseSyncSessionRequest) (syncdao.CloseSyncSessionDaoResult, error) {
+//CloseSyncSession closes a sync session via a sql database.
+func (dao SyncPairSQLDao) CloseSyncSession(item syncdao.CloseSyncSessionRequest) (syncdao.CloseSyncSessionDaoResult, error) {
 	var answer syncdao.CloseSyncSessionDaoResult
 	var actualSessionID string
 	sqlStr := `
-Update sync_pair SET SyncSessionId=null, SyncSessionStart=null, SyncSessionState='Inactive' where PairId=$1 and
+Update sync_pair SET SyncSessionId=null, SyncSessionStart=null, SyncSessionHeartbeat=null, SyncSessionState='Inactive' where PairId=$1 and
 SyncSessionId=$2 and SyncSessionState<>'Inactive';`
 	result, err := dao.db.Exec(sqlStr, item.PairID, item.SessionID)
 	if err != nil {
@@ -333,17 +333,32 @@ SyncSessionId=$2 and SyncSessionState<>'Inactive';`
 	}
 }
 
-//UpdateSyncSessionState updates the sync session state via a postgressql database.
-func (dao SyncPairPostgresSQLDao) UpdateSyncSessionState(item syncdao.UpdateSyncSessionStateRequest) (syncdao.UpdateSyncSessionStateResult, error) {
+//UpdateSyncSessionState updates the sync session state via a sql database.
+func (dao SyncPairSQLDao) UpdateSyncSessionState(item syncdao.UpdateSyncSessionStateRequest) (syncdao.UpdateSyncSessionStateResult, error) {
 	var (
 		answer          syncdao.UpdateSyncSessionStateResult
 		actualSessionID string
 		resultingState  string
 	)
-	sqlStr := `
-Update sync_pair SET SyncSessionState=$3 where PairId=$1 and SyncSessionId=$2 and SyncSessionState<>'Inactive';
+	//Check the requested state can be reached from the current one. The update only applies while the state is unchanged.
+	sqlStr := "Select SyncSessionId, SyncSessionState from sync_pair where PairId=$1;"
+	row := dao.db.QueryRow(sqlStr, item.PairID)
+	if err := row.Scan(&actualSessionID, &resultingState); err == nil && actualSessionID == item.SessionID &&
+		resultingState != syncapi.SyncSessionStateInactive && !syncapi.IsValidSyncSessionStateTransition(resultingState, item.State) {
+		answer = syncdao.UpdateSyncSessionStateResult{
+			Result:             syncapi.IllegalStateTransitionResult,
+			ResultMsg:          "Cannot move session from '" + resultingState + "' to '" + item.State + "'",
+			RequestedSessionID: item.SessionID,
+			ActualSessionID:    actualSessionID,
+			RequestedState:     item.State,
+			ResultingState:     resultingState,
+		}
+		return answer, nil
+	}
+	sqlStr = `
+Update sync_pair SET SyncSessionState=$3 where PairId=$1 and SyncSessionId=$2 and SyncSessionState<>'Inactive' and SyncSessionState=$4;
 `
-	result, err := dao.db.Exec(sqlStr, item.PairID, item.SessionID, item.State)
+	result, err := dao.db.Exec(sqlStr, item.PairID, item.SessionID, item.State, resultingState)
 	if err != nil {
 		msg := "Error getting results from database, inputData=SessionId:'%s', State:'%s'. Error:%s"
 		log.Printf(msg, item.SessionID, item.State, err.Error())
@@ -355,8 +370,8 @@ Update sync_pair SET SyncSessionState=$3 where PairId=$1 and SyncSessionId=$2 an
 	if affectedCount == 0 {
 		//log.Println("No rows affected")
 		//Get the actual id
-		sqlStr := "Select SyncSessionId, SyncSessionState from sync_pair where PairId=$1;"
-		row := dao.db.QueryRow(sqlStr, item.PairID)
+		sqlStr = "Select SyncSessionId, SyncSessionState from sync_pair where PairId=$1;"
+		row = dao.db.QueryRow(sqlStr, item.PairID)
 		if err := row.Scan(&actualSessionID, &resultingState); err != nil {
 			//actualSessionId was null
 			answer = syncdao.UpdateSyncSessionStateResult{
@@ -398,12 +413,12 @@ Update sync_pair SET SyncSessionState=$3 where PairId=$1 and SyncSessionId=$2 an
 	}
 }
 
-//QueryPairState queries the current pair state via a postgressql database.
-func (dao SyncPairPostgresSQLDao) QueryPairState(item syncdao.QueryPairStateRequest) (syncdao.QueryPairStateDaoResult, error) {
+//QueryPairState queries the current pair state via a sql database.
+func (dao SyncPairSQLDao) QueryPairState(item syncdao.QueryPairStateRequest) (syncdao.QueryPairStateDaoResult, error) {
 	var (
 		sessionID    sql.NullString
 		sessionState string
-		sessionStart pq.NullTime
+		sessionStart sql.NullTime
 	)
 	sqlStr := `
 SELECT        sync_pair.SyncSessionState, sync_pair.SyncSessionStart, sync_pair.SyncSessionId
@@ -428,6 +443,151 @@ WHERE         (sync_pair.PairId = $1)
 			LastUpdated:  lastUpdated,
 		}
 	}
+	answer.Progress, err = queryPairProgress(dao.db, item.PairID)
+	if err != nil {
+		return answer, err
+	}
 
 	return answer, nil
 }
+
+//AddDataVersion implements the syncdao.SyncPairDao.AddDataVersion interface via a sql database.
+func (dao SyncPairSQLDao) AddDataVersion(dataVersionName string) error {
+	var count int
+	err := dao.db.QueryRow("select count(*) from sync_data_version where DataVersionName=$1;", dataVersionName).Scan(&count)
+	if err != nil {
+		syncutil.Error(err, ". Error finding data version", dataVersionName)
+		return err
+	}
+	if count > 0 {
+		return syncapi.ValidationError{Msg: "Data version '" + dataVersionName + "' already exists"}
+	}
+	_, err = dao.db.Exec("insert into sync_data_version (DataVersionName) values ($1);", dataVersionName)
+	if err != nil {
+		syncutil.Error(err, ". Error inserting data version", dataVersionName)
+		return err
+	}
+	return nil
+}
+
+//AddEntity implements the syncdao.SyncPairDao.AddEntity interface via a sql database. As the plural name is how
+//the peers name an entity, it is unique within the data version.
+func (dao SyncPairSQLDao) AddEntity(dataVersionName string, entity syncdao.EntityPairItem) error {
+	tx, err := dao.db.Begin()
+	if err != nil {
+		return err
+	}
+	err = lockDataVersion(tx, dao.dialect, dataVersionName)
+	if err == nil {
+		err = checkNewEntity(tx, dataVersionName, entity)
+	}
+	if err != nil {
+		rollbackQuietly(tx)
+		return err
+	}
+	sqlStr := `
+insert into sync_data_entity (EntitySingularName, EntityPluralName, DataVersionName, ProcOrderAddUpdate, ProcOrderDelete, EntityHandlerUri)
+values ($1, $2, $3, $4, $5, $6);`
+	_, err = tx.Exec(sqlStr, entity.EntitySingularName, entity.EntityPluralName, dataVersionName,
+		entity.ProcessOrderAddUpdate, entity.ProcessOrderDelete, entity.EntityHandlerURI)
+	if err != nil {
+		syncutil.Error(err, ". Error inserting entity, inputData=", entity)
+		rollbackQuietly(tx)
+		return err
+	}
+	return tx.Commit()
+}
+
+//AddField implements the syncdao.SyncPairDao.AddField interface via a sql database.
+func (dao SyncPairSQLDao) AddField(dataVersionName string, entitySingularName string, field syncdao.SyncFieldDefinition) error {
+	var count int
+	err := dao.db.QueryRow("select count(*) from sync_data_entity where EntitySingularName=$1 AND DataVersionName=$2;",
+		entitySingularName, dataVersionName).Scan(&count)
+	if err != nil {
+		syncutil.Error(err, ". Error finding entity", entitySingularName)
+		return err
+	}
+	if count == 0 {
+		return syncdao.ErrDaoNoDataFound
+	}
+	err = dao.db.QueryRow("select count(*) from sync_data_field where EntitySingularName=$1 AND FieldName=$2;",
+		entitySingularName, field.FieldName).Scan(&count)
+	if err != nil {
+		syncutil.Error(err, ". Error finding field", field.FieldName, "of entity", entitySingularName)
+		return err
+	}
+	if count > 0 {
+		return syncapi.ValidationError{Msg: "Field '" + field.FieldName + "' of entity '" + entitySingularName + "' already exists"}
+	}
+	sqlStr := `
+insert into sync_data_field (EntitySingularName, FieldName, DataVersionName, DataTypeName, IsPrimaryKey)
+values ($1, $2, $3, $4, $5);`
+	_, err = dao.db.Exec(sqlStr, entitySingularName, field.FieldName, dataVersionName,
+		syncdao.SyncFieldTypeEnumName[int32(field.FieldType)], field.IsPrimaryKey)
+	if err != nil {
+		syncutil.Error(err, ". Error inserting field of entity", entitySingularName, ", inputData=", field)
+		return err
+	}
+	return nil
+}
+
+//AddPair implements the syncdao.SyncPairDao.AddPair interface via a sql database. Fields left at their zero
+//value take the defaults of the sql schema, so the pair starts out 'Inactive'.
+func (dao SyncPairSQLDao) AddPair(pair syncdao.SyncPair) error {
+	var count int
+	err := dao.db.QueryRow("select count(*) from sync_pair where PairId=$1;", pair.PairID).Scan(&count)
+	if err != nil {
+		syncutil.Error(err, ". Error finding pair", pair.PairID)
+		return err
+	}
+	if count > 0 {
+		return syncapi.ValidationError{Msg: "Pair '" + pair.PairID + "' already exists"}
+	}
+	sqlStr := `
+insert into sync_pair (PairId, PairName, MaxSesDurValue, MaxSesDurUnit, SyncDataTransForm, SyncMsgTransForm, SyncMsgSecPol, SyncConflictUri)
+values ($1, $2, coalesce(nullif($3, 0), 10), coalesce(nullif($4, ''), 'minutes'), coalesce(nullif($5, ''), 'json:V1'),
+coalesce(nullif($6, ''), 'json:V1'), coalesce(nullif($7, ''), 'none'), coalesce(nullif($8, ''), 'none'));`
+	_, err = dao.db.Exec(sqlStr, pair.PairID, pair.PairName, pair.MaxSesDurValue, pair.MaxSesDurUnit,
+		pair.SyncDataTransForm, pair.SyncMsgTransForm, pair.SyncMsgSecPol, pair.SyncConflictURI)
+	if err != nil {
+		syncutil.Error(err, ". Error inserting pair, inputData=", pair)
+		return err
+	}
+	return nil
+}
+
+//AddPairNode implements the syncdao.SyncPairDao.AddPairNode interface via a sql database. A pair is complete
+//once both of its nodes have been added, each targeting the other.
+func (dao SyncPairSQLDao) AddPairNode(pairID string, nodeID string, targetNodeID string, seededDataVersion string, syncConflictURI string) error {
+	node := syncdao.PairNode{PairID: pairID, NodeID: nodeID, TargetNodeID: targetNodeID,
+		SeededDataVersion: seededDataVersion, SyncConflictURI: syncConflictURI}
+	tx, err := dao.db.Begin()
+	if err != nil {
+		return err
+	}
+	err = lockInactivePair(tx, dao.dialect, pairID)
+	if err == nil {
+		err = checkPairNodeRefs(tx, node)
+	}
+	var existing []syncdao.PairNode
+	if err == nil {
+		existing, err = queryPairNodes(tx, pairID)
+	}
+	if err == nil {
+		err = syncapi.ValidatePairMembership(existing, node)
+	}
+	if err != nil {
+		rollbackQuietly(tx)
+		return err
+	}
+	sqlStr := `
+insert into sync_pair_nodes (PairId, NodeId, TargetNodeId, SeededDataVersion, SyncConflictUri)
+values ($1, $2, $3, nullif($4, ''), $5);`
+	_, err = tx.Exec(sqlStr, pairID, nodeID, targetNodeID, seededDataVersion, syncConflictURI)
+	if err != nil {
+		syncutil.Error(err, ". Error inserting node", nodeID, "of pair", pairID, "targeting", targetNodeID)
+		rollbackQuietly(tx)
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,29 @@
+//Package syncdaosql implements core syncdao interfaces with the sql shared by the sql databases, such as syncdaopq for
+//postgressql and syncdaosqlite for sqlite. What the databases do not share is given by their Dialect.
+package syncdaosql
+
+//ColumnType is the type of a sync table column a value is compared with or stored in.
+type ColumnType int
+
+const (
+	//ColumnTypeVarchar is a text column, such as sync_peer_state.NodeId.
+	ColumnTypeVarchar ColumnType = iota
+	//ColumnTypeTimestamp is a date and time column, such as sync_state.DeletedDate.
+	ColumnTypeTimestamp
+)
+
+//Dialect is what differs between the sql databases sharing the statements of this package. The statements bind their
+//parameters as $1, $2 and so on.
+type Dialect interface {
+	//Now gives the sql function of the current time, as in 'DeletedDate=now()'.
+	Now() string
+	//ForUpdate gives the clause ending a select to lock the rows read until the transaction ends, empty when the
+	//transaction already holds a write lock on the whole database.
+	ForUpdate() string
+	//QuoteIdentifier quotes the name of a custom table or column so that it can never be read as anything but a
+	//single identifier.
+	QuoteIdentifier(name string) string
+	//Cast gives expr, a column or bound parameter, as a value of columnType, for when the database cannot tell the
+	//type of a parameter or compares columnType values as text.
+	Cast(expr string, columnType ColumnType) string
+}
@@ -1,4 +1,4 @@
-package syncdaopq
+package syncdaosql
 
 import (
 	"database/sql"
@@ -8,18 +8,18 @@ import (
 
 //NewSyncMessagesFetcher creates an instance of the struct SyncMessagesFetcherType
 func newEntityFetcher(sessionID string, nodeID string, db *sql.DB) (syncapi.EntityFetching, error) {
-	fetcher := postgresSQLEntityFetcher{
+	fetcher := sqlEntityFetcher{
 		db: db,
 	}
 	return fetcher, nil
 }
 
-//postgresSQLEntityFetcher logically implements EntityFetcher for the PostgressSQL database.
-type postgresSQLEntityFetcher struct {
+//sqlEntityFetcher logically implements EntityFetcher for the sql databases.
+type sqlEntityFetcher struct {
 	db *sql.DB
 }
 
-func (fetcher postgresSQLEntityFetcher) FindEntitiesForFetch(orderNum int, sessionID string, nodeID string, changeType syncapi.ProcessSyncChangeEnum) ([]syncapi.EntityNameItem, error) {
+func (fetcher sqlEntityFetcher) FindEntitiesForFetch(orderNum int, sessionID string, nodeID string, changeType syncapi.ProcessSyncChangeEnum) ([]syncapi.EntityNameItem, error) {
 	// TODO(doug4j@gmail.com): Add caching on a per session basis so we only hit the database once per orderNum
 
 	var answer = []syncapi.EntityNameItem{}
@@ -61,7 +61,7 @@ func (fetcher postgresSQLEntityFetcher) FindEntitiesForFetch(orderNum int, sessi
 	return answer, nil
 }
 
-func (fetcher postgresSQLEntityFetcher) FindPluralEntityNamesByID(sessionID string, nodeID string) (map[string]syncapi.EntityNameItem, error) {
+func (fetcher sqlEntityFetcher) FindPluralEntityNamesByID(sessionID string, nodeID string) (map[string]syncapi.EntityNameItem, error) {
 	// TODO(doug4j@gmail.com): FindEntitiesForProcess
 	var answer = map[string]syncapi.EntityNameItem{}
 
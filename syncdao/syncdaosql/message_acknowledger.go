@@ -0,0 +1,283 @@
+package syncdaosql
+
+import (
+	"database/sql"
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func newMessageAcknowledger(sessionID string, nodeID string, db *sql.DB, dialect Dialect) (syncapi.MessageAcknowledging, error) {
+	acknowledger := sqlMessageAcknowledger{
+		MessageAcknowledgingData: syncapi.MessageAcknowledgingData{
+			SessionID: sessionID,
+			NodeID:    nodeID,
+		},
+		db:      db,
+		dialect: dialect,
+	}
+	return acknowledger, nil
+}
+
+//sqlMessageAcknowledger logically implements MessageAcknowledging for the sql databases.
+type sqlMessageAcknowledger struct {
+	syncapi.MessageAcknowledgingData
+	db      *sql.DB
+	dialect Dialect
+}
+
+//Acknowledge applies the peer's report for every record sent under the response's TransactionBindId to
+//sync_peer_state. Logically it works as follows:
+//
+// 1. the peer accepted the record (AckFastBatch, AckRecordLevelConflictResolvedSeparateFieldsChanged,
+// AckFieldLevelConflictResolvedWithAutoResolver or AckDeleteAndUpdateConflictWithAutoResolution) -> PeerLastKnownHash
+// becomes the ResponseHash, SentSyncState becomes PersistedStandardSentToPeer and ChangedByClient is cleared. When the
+// peer kept a different version (e.g. it merged the record) and the record was not changed locally since it was sent,
+// the peer's version is persisted locally. Likewise, when the peer resolved a delete and update conflict by deleting
+// the record, the record is deleted locally.
+//
+// 2. the peer left the record in conflict -> IsConflict is set, SentSyncState becomes PersistedStandardSentToPeer and
+// ChangedByClient is cleared. The conflict is resolved on the peer.
+//
+// 3. the TransactionBindSendId (and QueueBindSendId) of every record sent under the TransactionBindId is cleared so
+// records the peer did not report on are fetched again.
+//
+//The whole response is applied within one transaction, so a record failing leaves every record as it was and the
+//response can be acknowledged again. Records no longer sent under the TransactionBindId, as after the response was
+//acknowledged, are skipped, so acknowledging the same response twice applies it once.
+func (acknowledger sqlMessageAcknowledger) Acknowledge(response *syncmsg.ProtoSyncEntityMessageResponse) *syncmsg.ProtoSyncEntityMessageResponse {
+	transactionBindID := response.GetTransactionBindId()
+	answer := &syncmsg.ProtoSyncEntityMessageResponse{
+		TransactionBindId: proto.String(transactionBindID),
+		Items:             []*syncmsg.ProtoSyncDataMessagesResponse{},
+	}
+	if response.GetResult() != syncmsg.SyncEntityMessageResponseResult_OK {
+		syncutil.Warn("Peer reported result", response.GetResult(), "for transactionBindId", transactionBindID, ":", response.GetResultMsg())
+	}
+	tx, err := acknowledger.db.Begin()
+	if err != nil {
+		syncutil.Error(err, ". Error starting transaction for transactionBindId:", transactionBindID)
+		return acknowledger.errorAnswer(answer, err)
+	}
+	acknowledgedCount, err := acknowledger.acknowledgeItems(tx, response.Items, transactionBindID)
+	if err == nil {
+		err = saveSentAncestors(tx, acknowledger.NodeID, transactionBindID)
+	}
+	if err == nil {
+		err = addAcknowledgedProgress(tx, acknowledger.SessionID, acknowledger.NodeID, transactionBindID)
+	}
+	if err == nil {
+		sqlStr := `
+update sync_peer_state set TransactionBindSendId=null, QueueBindSendId=null
+where (NodeId=$1 AND TransactionBindSendId=$2);`
+		_, err = tx.Exec(sqlStr, acknowledger.NodeID, transactionBindID)
+		if err != nil {
+			syncutil.Error(err, ". Error clearing transactionBindId:", transactionBindID)
+		}
+	}
+	if err != nil {
+		rollbackQuietly(tx)
+		return acknowledger.errorAnswer(answer, err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		syncutil.Error(err, ". Error committing transactionBindId:", transactionBindID)
+		return acknowledger.errorAnswer(answer, err)
+	}
+	answer.Result = syncmsg.SyncEntityMessageResponseResult_OK.Enum()
+	answer.ResultMsg = proto.String(fmt.Sprintf("%v records acknowledged", acknowledgedCount))
+	return answer
+}
+
+//acknowledgeItems applies within tx the peer's report for every record of items, answering how many records were
+//acknowledged.
+func (acknowledger sqlMessageAcknowledger) acknowledgeItems(tx *sql.Tx, items []*syncmsg.ProtoSyncDataMessagesResponse, transactionBindID string) (int, error) {
+	acknowledgedCount := 0
+	for _, item := range items {
+		entitySingularName, err := acknowledger.findSingularEntityName(tx, item.GetEntityPluralName())
+		if err != nil {
+			return acknowledgedCount, err
+		}
+		var fieldDefinitions map[string]syncdao.SyncFieldDefinition
+		for _, msg := range item.Msgs {
+			if fieldDefinitions == nil && (acknowledgeAdoptsPeerRecord(msg) || acknowledgeAdoptsPeerDelete(msg)) {
+				fieldDefinitions, err = findNodeEntityFields(tx, acknowledger.NodeID, entitySingularName)
+				if err != nil {
+					return acknowledgedCount, err
+				}
+			}
+			acknowledged, err := acknowledger.acknowledgeRecord(tx, entitySingularName, item.GetEntityPluralName(), msg, transactionBindID, fieldDefinitions)
+			if err != nil {
+				return acknowledgedCount, err
+			}
+			if acknowledged {
+				acknowledgedCount++
+			}
+		}
+	}
+	return acknowledgedCount, nil
+}
+
+func (acknowledger sqlMessageAcknowledger) errorAnswer(answer *syncmsg.ProtoSyncEntityMessageResponse, err error) *syncmsg.ProtoSyncEntityMessageResponse {
+	answer.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
+	answer.ResultMsg = proto.String(err.Error())
+	return answer
+}
+
+//acknowledgeIsConflict tells if the peer left the record in conflict rather than accepting a version of it.
+func acknowledgeIsConflict(msg *syncmsg.ProtoSyncDataMessageResponse) bool {
+	switch msg.GetSyncState() {
+	case syncmsg.AckSyncStateEnum_AckFieldLevelConflictwithNoAutoResolverAvailable,
+		syncmsg.AckSyncStateEnum_AckFieldLevelConflictWithNoAutoResolverResolution,
+		syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithNoAutoResolverAvailable,
+		syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithNoAutoResolution:
+		return true
+	}
+	return false
+}
+
+//acknowledgeAdoptsPeerRecord tells if the peer accepted the record but kept a version different from the one sent.
+func acknowledgeAdoptsPeerRecord(msg *syncmsg.ProtoSyncDataMessageResponse) bool {
+	return !acknowledgeIsConflict(msg) && msg.GetResponseHash() != msg.GetRequestHash() && len(msg.RecordData) != 0
+}
+
+//acknowledgeAdoptsPeerDelete tells if the peer resolved a delete and update conflict by deleting the record.
+func acknowledgeAdoptsPeerDelete(msg *syncmsg.ProtoSyncDataMessageResponse) bool {
+	return msg.GetSyncState() == syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithAutoResolution && len(msg.RecordData) == 0
+}
+
+//acknowledgeRecord applies within tx the peer's report for a record, answering false when the record is not sent
+//under transactionBindID.
+func (acknowledger sqlMessageAcknowledger) acknowledgeRecord(tx *sql.Tx, entitySingularName string, entityPluralName string, msg *syncmsg.ProtoSyncDataMessageResponse, transactionBindID string, fieldDefinitions map[string]syncdao.SyncFieldDefinition) (bool, error) {
+	recordID := msg.GetRecordId()
+	var boundCount int
+	err := tx.QueryRow("select count(*) from sync_peer_state where (NodeId=$1 AND EntitySingularName=$2 AND RecordId=$3 AND TransactionBindSendId=$4);",
+		acknowledger.NodeID, entitySingularName, recordID, transactionBindID).Scan(&boundCount)
+	if err != nil {
+		syncutil.Error(err, ". Error finding sync_peer_state for record", recordID)
+		return false, err
+	}
+	if boundCount == 0 {
+		syncutil.Info("Record '", recordID, "' of entity '", entitySingularName, "' is not sent under transactionBindId", transactionBindID, ". Skipping it.")
+		return false, nil
+	}
+	if acknowledgeIsConflict(msg) {
+		sqlStr := `
+update sync_peer_state set SentLastKnownHash=$1, SentSyncState=$2, ChangedByClient=false, IsConflict=true, LastUpdated=$3
+where (NodeId=$4 AND EntitySingularName=$5 AND RecordId=$6 AND TransactionBindSendId=$7);`
+		_, err = tx.Exec(sqlStr, msg.GetRequestHash(), int32(syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer),
+			time.Now(), acknowledger.NodeID, entitySingularName, recordID, transactionBindID)
+		if err != nil {
+			syncutil.Error(err, ". Error acknowledging conflict for record", recordID)
+			return false, err
+		}
+		return true, nil
+	}
+
+	sentLastKnownHash := msg.GetRequestHash()
+	//peerIsDelete is only set when the acknowledgement changes the record locally
+	var peerIsDelete sql.NullBool
+	if acknowledgeAdoptsPeerRecord(msg) || acknowledgeAdoptsPeerDelete(msg) {
+		var (
+			localHash, localData string
+			localIsDelete        bool
+		)
+		err = tx.QueryRow("select RecordHash, RecordData, IsDelete from sync_state where (EntitySingularName=$1 AND RecordId=$2)"+acknowledger.dialect.ForUpdate()+";",
+			entitySingularName, recordID).Scan(&localHash, &localData, &localIsDelete)
+		if err != nil {
+			syncutil.Error(err, ". Error reading sync_state for record", recordID)
+			return false, err
+		}
+		localRecord := readInitialTransactionBindResult{
+			entitySingularName: entitySingularName,
+			entityPluralName:   entityPluralName,
+			recordID:           recordID,
+			recordHash:         localHash,
+			recordData:         localData,
+			isDelete:           localIsDelete,
+		}
+		switch {
+		case localHash != msg.GetRequestHash():
+			syncutil.Info("Record '", recordID, "' of entity '", entitySingularName, "' changed since it was sent. Keeping the local version.")
+		case acknowledgeAdoptsPeerRecord(msg):
+			err = acknowledger.adoptPeerRecord(tx, localRecord, msg, fieldDefinitions)
+			if err != nil {
+				return false, err
+			}
+			sentLastKnownHash = msg.GetResponseHash()
+			peerIsDelete = sql.NullBool{Bool: false, Valid: true}
+		default:
+			if !localIsDelete {
+				err = acknowledger.adoptPeerDelete(tx, localRecord, fieldDefinitions)
+				if err != nil {
+					return false, err
+				}
+			}
+			peerIsDelete = sql.NullBool{Bool: true, Valid: true}
+		}
+	}
+	sqlStr := `
+update sync_peer_state set PeerLastKnownHash=$1, SentLastKnownHash=$2, SentSyncState=$3, ChangedByClient=false, IsConflict=false,
+	IsDelete=coalesce($4, IsDelete), LastUpdated=$5
+where (NodeId=$6 AND EntitySingularName=$7 AND RecordId=$8 AND TransactionBindSendId=$9);`
+	_, err = tx.Exec(sqlStr, msg.GetResponseHash(), sentLastKnownHash, int32(syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer),
+		peerIsDelete, time.Now(), acknowledger.NodeID, entitySingularName, recordID, transactionBindID)
+	if err != nil {
+		syncutil.Error(err, ". Error acknowledging record", recordID)
+		return false, err
+	}
+	return true, nil
+}
+
+//adoptPeerRecord persists within tx the version of the record kept by the peer.
+func (acknowledger sqlMessageAcknowledger) adoptPeerRecord(tx *sql.Tx, localRecord readInitialTransactionBindResult, msg *syncmsg.ProtoSyncDataMessageResponse, fieldDefinitions map[string]syncdao.SyncFieldDefinition) error {
+	peerRecord := &syncmsg.ProtoRecord{}
+	err := proto.Unmarshal(msg.RecordData, peerRecord)
+	if err != nil {
+		syncutil.Error(err, ". Error unmarshaling peer record", localRecord.recordID)
+		return err
+	}
+	return writeRecord(tx, acknowledger.dialect, localRecord, peerRecord, msg.GetResponseHash(), msg.RecordData, fieldDefinitions)
+}
+
+//adoptPeerDelete deletes the record within tx as the peer did.
+func (acknowledger sqlMessageAcknowledger) adoptPeerDelete(tx *sql.Tx, localRecord readInitialTransactionBindResult, fieldDefinitions map[string]syncdao.SyncFieldDefinition) error {
+	localBytes, err := decodeStoredRecordData(localRecord.recordData)
+	if err != nil {
+		syncutil.Error(err, ". Error decoding local record data for record", localRecord.recordID)
+		return err
+	}
+	localProtoRecord := &syncmsg.ProtoRecord{}
+	err = proto.Unmarshal(localBytes, localProtoRecord)
+	if err != nil {
+		syncutil.Error(err, ". Error unmarshaling local record", localRecord.recordID)
+		return err
+	}
+	return eraseRecord(tx, acknowledger.dialect, localRecord, localProtoRecord, fieldDefinitions)
+}
+
+//findSingularEntityName finds the singular name of an entity in the data version of the acknowledging node.
+func (acknowledger sqlMessageAcknowledger) findSingularEntityName(db queryer, entityPluralName string) (string, error) {
+	sqlStr := `
+SELECT        sync_data_entity.EntitySingularName
+FROM            sync_node INNER JOIN
+                         sync_data_entity ON sync_node.DataVersionName = sync_data_entity.DataVersionName
+WHERE        (sync_node.NodeId = $1 AND sync_data_entity.EntityPluralName = $2);
+`
+	var answer string
+	err := db.QueryRow(sqlStr, acknowledger.NodeID, entityPluralName).Scan(&answer)
+	switch {
+	case err == sql.ErrNoRows:
+		syncutil.Error("No entity with plural name '", entityPluralName, "' for nodeId:", acknowledger.NodeID)
+		return answer, syncdao.ErrDaoNoDataFound
+	case err != nil:
+		syncutil.Error(err, ". Error finding entity", entityPluralName)
+		return answer, err
+	}
+	return answer, nil
+}
@@ -1,4 +1,4 @@
-package syncdaopq
+package syncdaosql
 
 import (
 	"database/sql"
@@ -6,39 +6,62 @@ import (
 	"data-sync-tools-go/syncmsg"
 	"data-sync-tools-go/syncutil"
 	"encoding/hex"
+	"errors"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/twinj/uuid"
 )
 
-//NewSyncMessagesFetcher creates an instance of the struct SyncMessagesFetcherType
-func newMessageFetcher(SessionID string, NodeID string, db *sql.DB) (syncapi.MessageFetching, error) {
-
-	// BUG(doug4j@gmail.com): MaxGroupSize and MaxMsgs Should come from configuration and not be hard coded
-
+//newMessageFetcher creates a sqlMessageFetcher whose MaxMsgs is the smaller of the MaxOutMsgBatchSize and the
+//InMsgBatchSize configured for the node in sync_node, unless overridden by limits.
+func newMessageFetcher(SessionID string, NodeID string, limits syncapi.FetchLimits, db *sql.DB) (syncapi.MessageFetching, error) {
+	var maxOutMsgBatchSize, inMsgBatchSize int
+	sqlStr := `
+SELECT        coalesce(MaxOutMsgBatchSize, 200), coalesce(InMsgBatchSize, 100)
+FROM            sync_node
+WHERE        (NodeId = $1);
+`
+	err := db.QueryRow(sqlStr, NodeID).Scan(&maxOutMsgBatchSize, &inMsgBatchSize)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := "Cannot find batch sizes of node '" + NodeID + "'"
+		syncutil.Error(msg)
+		return nil, errors.New(msg)
+	case err != nil:
+		syncutil.Error(err, ". Cannot find batch sizes of node '"+NodeID+"'")
+		return nil, err
+	}
 	fetcherType := syncapi.MessageFetchingData{
 		SessionID:         SessionID,
 		NodeID:            NodeID,
-		MaxGroupBytesSize: 50, //Hard Coded for Now
-		MaxMsgs:           1,  //Hard Coded for Now
+		MaxGroupBytesSize: syncapi.DefaultMaxGroupBytesSize,
+		MaxMsgs:           maxOutMsgBatchSize,
+	}
+	if inMsgBatchSize < fetcherType.MaxMsgs {
+		fetcherType.MaxMsgs = inMsgBatchSize
+	}
+	fetcherType.ApplyLimits(limits)
+	if fetcherType.MaxMsgs <= 0 {
+		msg := "Node '" + NodeID + "' is not configured to send any messages"
+		syncutil.Error(msg)
+		return nil, errors.New(msg)
 	}
-	fetcher := postgresSQLMessageFetcher{
+	fetcher := sqlMessageFetcher{
 		MessageFetchingData: fetcherType,
 		db:                  db,
 	}
-	//syncutil.Info(fetcher)
 	return fetcher, nil
 }
 
-//postgresSQLMessageFetcher glogically implements SyncMessagesFetcherStruct for the PostgressSQL database.
-type postgresSQLMessageFetcher struct {
+//sqlMessageFetcher glogically implements SyncMessagesFetcherStruct for the sql databases.
+type sqlMessageFetcher struct {
 	syncapi.MessageFetchingData
 	db *sql.DB
 }
 
 //Fetch retrieves a group of sync messages for downstream processes returning a value with no request items
 //in it should it be (Copied from SyncMessagesFetcher.Fetch() interface)
-func (fetcher postgresSQLMessageFetcher) Fetch(entities []syncapi.EntityNameItem, changeType syncapi.ProcessSyncChangeEnum) (*syncmsg.ProtoRequestSyncEntityMessageResponse, error) {
+func (fetcher sqlMessageFetcher) Fetch(entities []syncapi.EntityNameItem, changeType syncapi.ProcessSyncChangeEnum) (*syncmsg.ProtoRequestSyncEntityMessageResponse, error) {
 	lastState := newFetchedState()
 	bindID := uuid.Formatter(uuid.NewV4(), uuid.FormatCanonical)
 	isDelete := *changeType.Enum() == syncapi.ProcessSyncChangeEnumDelete
@@ -106,7 +129,7 @@ func (fetcher postgresSQLMessageFetcher) Fetch(entities []syncapi.EntityNameItem
 	return answer, nil
 }
 
-func (fetcher postgresSQLMessageFetcher) createEntityMapByPluralName(items []syncapi.EntityNameItem) map[string]syncapi.EntityNameItem {
+func (fetcher sqlMessageFetcher) createEntityMapByPluralName(items []syncapi.EntityNameItem) map[string]syncapi.EntityNameItem {
 	var entityMapByPluralName = map[string]syncapi.EntityNameItem{}
 	for _, item := range items {
 		entityMapByPluralName[item.PluralName] = item
@@ -114,40 +137,44 @@ func (fetcher postgresSQLMessageFetcher) createEntityMapByPluralName(items []syn
 	return entityMapByPluralName
 }
 
-func (fetcher postgresSQLMessageFetcher) markItemsWithBindID(bindID string, request *syncmsg.ProtoSyncEntityMessageRequest, entityMapByPluralName map[string]syncapi.EntityNameItem) error {
+func (fetcher sqlMessageFetcher) markItemsWithBindID(bindID string, request *syncmsg.ProtoSyncEntityMessageRequest, entityMapByPluralName map[string]syncapi.EntityNameItem) error {
 	if len(request.Items) == 0 {
 		return nil
 	}
 
-	var sql = "update sync_peer_state set transactionBindSendId='" + bindID + "' where nodeId='" + fetcher.NodeID + "' AND"
+	sql, args := createMarkItemsWithBindIDSQL(bindID, fetcher.NodeID, request, entityMapByPluralName)
+	_, err := fetcher.db.Exec(sql, args...)
+	if err != nil {
+		syncutil.Error(err)
+		return err
+	}
+
+	return saveSentAncestors(fetcher.db, fetcher.NodeID, bindID)
+}
+
+//createMarkItemsWithBindIDSQL creates the update binding the peer state of every record in request to bindID.
+func createMarkItemsWithBindIDSQL(bindID string, nodeID string, request *syncmsg.ProtoSyncEntityMessageRequest, entityMapByPluralName map[string]syncapi.EntityNameItem) (string, sqlArgs) {
+	args := sqlArgs{}
+	var sql = "update sync_peer_state set transactionBindSendId=" + args.add(bindID) + " where nodeId=" + args.add(nodeID) + " AND ("
 	for entityCount, item := range request.Items {
 		singularEntityName := entityMapByPluralName[*item.EntityPluralName].SingularName
-		if entityCount == 0 {
-			sql = sql + " (entitySingularName='" + singularEntityName + "' AND ("
-		} else {
-			sql = sql + " OR (entitySingularName='" + singularEntityName + "' AND ("
+		if entityCount != 0 {
+			sql = sql + " OR "
 		}
+		sql = sql + "(entitySingularName=" + args.add(singularEntityName) + " AND recordId in ("
 		for recordCount, msg := range item.Msgs {
-			if recordCount == 0 {
-				sql = sql + "recordId='" + *msg.RecordId + "'"
-			} else {
-				sql = sql + " OR recordId='" + *msg.RecordId + "'"
+			if recordCount != 0 {
+				sql = sql + ", "
 			}
+			sql = sql + args.add(*msg.RecordId)
 		}
 		sql = sql + "))"
 	}
-	sql = sql + ";"
-	//syncutil.Debug("\n\n", sql, "\n\n")
-	_, err := fetcher.db.Exec(sql)
-	if err != nil {
-		syncutil.Error(err)
-		return err
-	}
-
-	return nil
+	sql = sql + ");"
+	return sql, args
 }
 
-func (fetcher postgresSQLMessageFetcher) processEntity(lastState *fetchedState, entity syncapi.EntityNameItem, changeType syncapi.ProcessSyncChangeEnum) (*syncmsg.ProtoSyncDataMessagesRequest, *fetchedState, error) {
+func (fetcher sqlMessageFetcher) processEntity(lastState *fetchedState, entity syncapi.EntityNameItem, changeType syncapi.ProcessSyncChangeEnum) (*syncmsg.ProtoSyncDataMessagesRequest, *fetchedState, error) {
 
 	//syncutil.Debug("Processing Entity {", entity, "}")
 	//var localState = lastState
@@ -157,7 +184,7 @@ func (fetcher postgresSQLMessageFetcher) processEntity(lastState *fetchedState,
 	}
 	for true {
 		queueID := uuid.Formatter(uuid.NewV4(), uuid.FormatCanonical)
-		err := fetcher.reserveFetchItems(entity.SingularName, changeType, queueID)
+		err := fetcher.reserveFetchItems(entity.SingularName, changeType, queueID, fetcher.MaxMsgs-lastState.lastProcessedCount)
 		if err != nil {
 			syncutil.Error(err)
 			return msgsRequest, lastState, err
@@ -202,7 +229,7 @@ func newFetchedState() fetchedState {
 	}
 }
 
-func (fetcher postgresSQLMessageFetcher) fetchReserveFetchItems(changeType syncapi.ProcessSyncChangeEnum, queueID string, request *syncmsg.ProtoSyncDataMessagesRequest, previousState *fetchedState) error {
+func (fetcher sqlMessageFetcher) fetchReserveFetchItems(changeType syncapi.ProcessSyncChangeEnum, queueID string, request *syncmsg.ProtoSyncDataMessagesRequest, previousState *fetchedState) error {
 	//Reset the reading state for ineligibility check downstream
 	previousState.readMoreFromEntity = true
 	rows, err := fetcher.db.Query(sqlFetchReserved, queueID, fetcher.NodeID)
@@ -230,6 +257,7 @@ func (fetcher postgresSQLMessageFetcher) fetchReserveFetchItems(changeType synca
 	)
 
 	var rowsInThisMethod = 0
+	var unfetchedRecordIDs = []string{}
 
 	for rows.Next() {
 		err = rows.Scan(&recordID, &recordHash, &lastKnownPeerHash, &sentSyncState, &recordBytesSize, &recordBytesAsHex)
@@ -237,12 +265,23 @@ func (fetcher postgresSQLMessageFetcher) fetchReserveFetchItems(changeType synca
 			syncutil.Error("Error scanning for change count. Error:", err.Error())
 			return err
 		}
+		//A record past the limits is left for a later fetch. The first record is always fetched, even when larger than
+		//MaxGroupBytesSize, as it could never be fetched otherwise.
+		if previousState.lastProcessedCount >= fetcher.MaxMsgs ||
+			(previousState.lastProcessedCount > 0 && previousState.totalBytesProcessed+recordBytesSize > uint32(fetcher.MaxGroupBytesSize)) {
+			unfetchedRecordIDs = append(unfetchedRecordIDs, recordID)
+			continue
+		}
 		recordBytes, err := hex.DecodeString(recordBytesAsHex)
 		if err != nil {
 			msg := "Error decoding hex bytes from database. This should not happen as long as data is written in a uniform manner. Error:"
 			syncutil.Error(msg, err.Error())
 			return err
 		}
+		//Deletes still carry the last record data so the peer can find the row to delete by its primary key
+		if changeType == syncapi.ProcessSyncChangeEnumDelete {
+			sentSyncState = int32(syncmsg.SentSyncStateEnum_PersistedFastDeleted)
+		}
 		err = fetcher.addDataMessagesResponse(queueID, recordID, recordHash, lastKnownPeerHash, sentSyncState, recordBytesSize, recordBytes, request)
 		if err != nil {
 			syncutil.Error(err)
@@ -255,7 +294,21 @@ func (fetcher postgresSQLMessageFetcher) fetchReserveFetchItems(changeType synca
 		//time.Sleep(1 * time.Second)
 	}
 
-	if previousState.totalBytesProcessed >= uint32(fetcher.MaxGroupBytesSize) {
+	err = rows.Err()
+	if err != nil {
+		syncutil.Error(err)
+		return err
+	}
+	if len(unfetchedRecordIDs) > 0 {
+		err = fetcher.releaseFetchItems(queueID, unfetchedRecordIDs)
+		if err != nil {
+			syncutil.Error(err)
+			return err
+		}
+	}
+
+	if len(unfetchedRecordIDs) > 0 || previousState.lastProcessedCount >= fetcher.MaxMsgs ||
+		previousState.totalBytesProcessed >= uint32(fetcher.MaxGroupBytesSize) {
 		previousState.readMoreFromEntity = false
 		previousState.readAnotherEntity = false
 		//syncutil.Info("Total bytes greater than or equal to max group bytes size", fetcher.MaxGroupBytesSize, ". processed", previousState.totalBytesProcessed)
@@ -269,7 +322,7 @@ func (fetcher postgresSQLMessageFetcher) fetchReserveFetchItems(changeType synca
 	return nil
 }
 
-func (fetcher postgresSQLMessageFetcher) addDataMessagesResponse(bindID string, recordID string, recordHash string, lastKnownPeerHash sql.NullString, sentSyncState int32, recordBytesSize uint32, recordBytes []byte, request *syncmsg.ProtoSyncDataMessagesRequest) error {
+func (fetcher sqlMessageFetcher) addDataMessagesResponse(bindID string, recordID string, recordHash string, lastKnownPeerHash sql.NullString, sentSyncState int32, recordBytesSize uint32, recordBytes []byte, request *syncmsg.ProtoSyncDataMessagesRequest) error {
 
 	sentSyncStateEnum, err := syncmsg.CreateSentSyncState(sentSyncState)
 	if err != nil {
@@ -303,9 +356,10 @@ func (fetcher postgresSQLMessageFetcher) addDataMessagesResponse(bindID string,
 	return nil
 }
 
-func (fetcher postgresSQLMessageFetcher) reserveFetchItems(entitySingularName string, changeType syncapi.ProcessSyncChangeEnum, bindID string) error {
+//reserveFetchItems reserves up to limit records of the entity for fetching under bindID.
+func (fetcher sqlMessageFetcher) reserveFetchItems(entitySingularName string, changeType syncapi.ProcessSyncChangeEnum, bindID string, limit int) error {
 	isDelete := *changeType.Enum() == syncapi.ProcessSyncChangeEnumDelete
-	_, err := fetcher.db.Exec(sqlReserveForFetching, bindID, entitySingularName, fetcher.SessionID, fetcher.NodeID, isDelete, fetcher.MaxMsgs)
+	_, err := fetcher.db.Exec(sqlReserveForFetching, bindID, entitySingularName, fetcher.SessionID, fetcher.NodeID, isDelete, limit)
 	if err != nil {
 		syncutil.Error(err)
 		return err
@@ -314,6 +368,25 @@ func (fetcher postgresSQLMessageFetcher) reserveFetchItems(entitySingularName st
 	return nil
 }
 
+//releaseFetchItems gives back the reserved records left out of a fetch so a later fetch reserves them again.
+func (fetcher sqlMessageFetcher) releaseFetchItems(queueID string, recordIDs []string) error {
+	args := sqlArgs{}
+	sqlStr := "update sync_peer_state set queueBindSendId=null where queueBindSendId=" + args.add(queueID) + " AND nodeId=" + args.add(fetcher.NodeID) + " AND recordId in ("
+	for index, recordID := range recordIDs {
+		if index != 0 {
+			sqlStr = sqlStr + ", "
+		}
+		sqlStr = sqlStr + args.add(recordID)
+	}
+	sqlStr = sqlStr + ");"
+	_, err := fetcher.db.Exec(sqlStr, args...)
+	if err != nil {
+		syncutil.Error(err)
+		return err
+	}
+	return nil
+}
+
 const (
 	sqlReserveForFetching = `
 update sync_peer_state set queueBindSendId=$1 where entitySingularName=$2 AND nodeId=$4 AND recordId in (select recordId from sync_peer_state where entitySingularName=$2 AND sessionBindId=$3 AND queueBindSendId is null AND nodeId=$4 AND isDelete=$5 AND changedByClient=true limit $6);
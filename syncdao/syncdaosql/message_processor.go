@@ -1,7 +1,6 @@
-package syncdaopq
+package syncdaosql
 
 import (
-	"bytes"
 	"database/sql"
 	"data-sync-tools-go/syncapi"
 	"data-sync-tools-go/syncdao"
@@ -9,8 +8,6 @@ import (
 	"data-sync-tools-go/syncutil"
 	"encoding/hex"
 	"errors"
-	"fmt"
-	"html/template"
 	"sort"
 	"time"
 
@@ -18,25 +15,27 @@ import (
 )
 
 //NewSyncMessagesFetcher creates an instance of the struct SyncMessagesFetcherType
-func newMessageProcessor(sessionID string, nodeID string, entitiesByPluralName map[string]syncapi.EntityNameItem, db *sql.DB) (syncapi.MessageProcessing, error) {
+func newMessageProcessor(sessionID string, nodeID string, entitiesByPluralName map[string]syncapi.EntityNameItem, db *sql.DB, dialect Dialect) (syncapi.MessageProcessing, error) {
 
 	processorType := syncapi.MessageProcessingData{
 		SessionID:            sessionID,
 		NodeID:               nodeID,
 		EntitiesByPluralName: entitiesByPluralName,
 	}
-	processor := postgresSQLMessageProcessor{
+	processor := sqlMessageProcessor{
 		MessageProcessingData: processorType,
-		db: db,
+		db:                    db,
+		dialect:               dialect,
 	}
 	//syncutil.Info(fetcher)
 	return processor, nil
 }
 
-//postgresSQLMessageProcessor logically implements MessageProcessor for the PostgressSQL database.
-type postgresSQLMessageProcessor struct {
+//sqlMessageProcessor logically implements MessageProcessor for the sql databases.
+type sqlMessageProcessor struct {
 	syncapi.MessageProcessingData
-	db *sql.DB
+	db      *sql.DB
+	dialect Dialect
 }
 
 //Process transforms request data into persisted data synced to the local model while resolving or reporting
@@ -74,37 +73,140 @@ type postgresSQLMessageProcessor struct {
 //  OUT    AckDeleteAndUpdateConflictWithNoAutoResolverAvailable 26
 //  OUT    AckDeleteAndUpdateConflictWithNoAutoResolution        27
 //  OUT    AckDeleteAndUpdateConflictWithAutoResolution          28
-func (processor postgresSQLMessageProcessor) Process(request *syncmsg.ProtoSyncEntityMessageRequest) *syncmsg.ProtoSyncEntityMessageResponse {
+func (processor sqlMessageProcessor) Process(request *syncmsg.ProtoSyncEntityMessageRequest) *syncmsg.ProtoSyncEntityMessageResponse {
 	answer := &syncmsg.ProtoSyncEntityMessageResponse{
 		TransactionBindId: request.TransactionBindId,
 		Items:             []*syncmsg.ProtoSyncDataMessagesResponse{}, //make([]*syncmsg.ProtoSyncDataMessagesResponse, 0, 1),
 	}
+	dataVersionName, err := processor.findNodeDataVersionName()
+	if err != nil {
+		answer.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
+		answer.ResultMsg = proto.String(err.Error())
+		return answer
+	}
+	requestData := *request
+	if requestData.GetIsDelete() {
+		orderedItems, err := processor.orderItemsForDelete(requestData.Items)
+		if err != nil {
+			answer.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
+			answer.ResultMsg = proto.String(err.Error())
+			return answer
+		}
+		requestData.Items = orderedItems
+	}
 	sqlProcessor := &compoundSQLProcessor{
-		builders: []sqlBuilder{&changeInitialSQLBuilder{}, &readInitialSQLBuilder{}},
+		builders:        []sqlBuilder{&changeInitialSQLBuilder{dialect: processor.dialect}, &readInitialSQLBuilder{}},
+		dataVersionName: dataVersionName,
 	}
-	unprocessedMsgs, err := processor.initialProcessLoop(sqlProcessor, *request, answer, processor.NodeID, *request.TransactionBindId)
+	unprocessedMsgs, err := processor.initialProcessLoop(sqlProcessor, requestData, answer, processor.NodeID, *request.TransactionBindId)
 	if err != nil {
 		//initialProcessLoop fills in the erros on the answer object, so, just return it as is
 		return answer
 	}
-	if len(unprocessedMsgs) == 0 {
-		answer.Result = syncmsg.SyncEntityMessageResponseResult_OK.Enum()
-		answer.ResultMsg = proto.String("All records are fast batch")
+	resultMsg := "All records are fast batch"
+	if len(unprocessedMsgs) != 0 {
+		syncutil.Debug("Not Fast Batch Items: ", unprocessedMsgs)
+		err = processor.recordLevelProcessLoop(requestData, unprocessedMsgs, answer)
+		if err != nil {
+			syncutil.Error(err)
+			answer.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
+			answer.ResultMsg = proto.String(err.Error())
+			return answer
+		}
+		resultMsg = "Some records processed at record level"
+	}
+	err = saveReceivedAncestors(processor.db, processor.NodeID, *request.TransactionBindId)
+	if err != nil {
+		answer.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
+		answer.ResultMsg = proto.String(err.Error())
+		return answer
+	}
+	err = addProcessedProgress(processor.db, processor.SessionID, processor.NodeID, request)
+	if err != nil {
+		answer.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
+		answer.ResultMsg = proto.String(err.Error())
 		return answer
 	}
 	answer.Result = syncmsg.SyncEntityMessageResponseResult_OK.Enum()
-	answer.ResultMsg = proto.String("Implement Me: I'm not fast batch")
-	syncutil.Debug("Not Fast Batch Items: ", unprocessedMsgs)
+	answer.ResultMsg = proto.String(resultMsg)
 	return answer
 }
 
-func (processor postgresSQLMessageProcessor) initialProcessLoop(sqlProcessor *compoundSQLProcessor, requestData syncmsg.ProtoSyncEntityMessageRequest, response *syncmsg.ProtoSyncEntityMessageResponse, nodeIDToProcess string, transactionBindID string) (map[string][]readInitialTransactionBindResult, error) {
+//orderItemsForDelete gives the items of a delete request sorted by the ProcOrderDelete of their entity so that rows
+//referencing others are deleted first. Items of entities with the same order keep their position in the request.
+func (processor sqlMessageProcessor) orderItemsForDelete(items []*syncmsg.ProtoSyncDataMessagesRequest) ([]*syncmsg.ProtoSyncDataMessagesRequest, error) {
+	sqlStr := `
+SELECT        sync_data_entity.EntityPluralName, sync_data_entity.ProcOrderDelete
+FROM            sync_node INNER JOIN
+                         sync_data_entity ON sync_node.DataVersionName = sync_data_entity.DataVersionName
+WHERE        (sync_node.NodeId = $1);
+`
+	rows, err := processor.db.Query(sqlStr, processor.NodeID)
+	if err != nil {
+		syncutil.Error(err, ". Error finding delete order for nodeId:", processor.NodeID)
+		return items, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	procOrderDelete := make(map[string]int)
+	for rows.Next() {
+		var (
+			entityPluralName string
+			procOrder        int
+		)
+		err = rows.Scan(&entityPluralName, &procOrder)
+		if err != nil {
+			syncutil.Error(err, ". Error reading delete order for nodeId:", processor.NodeID)
+			return items, err
+		}
+		procOrderDelete[entityPluralName] = procOrder
+	}
+	err = rows.Err()
+	if err != nil {
+		syncutil.Error(err, ". Error reading delete order for nodeId:", processor.NodeID)
+		return items, err
+	}
+	answer := deleteOrderedItems{
+		items:           make([]*syncmsg.ProtoSyncDataMessagesRequest, len(items)),
+		procOrderDelete: procOrderDelete,
+	}
+	copy(answer.items, items)
+	sort.Stable(answer)
+	return answer.items, nil
+}
+
+//deleteOrderedItems sorts the items of a delete request by the ProcOrderDelete of their entity.
+type deleteOrderedItems struct {
+	items           []*syncmsg.ProtoSyncDataMessagesRequest
+	procOrderDelete map[string]int
+}
+
+func (ordered deleteOrderedItems) Len() int {
+	return len(ordered.items)
+}
+
+func (ordered deleteOrderedItems) Less(i, j int) bool {
+	return ordered.procOrderDelete[ordered.items[i].GetEntityPluralName()] < ordered.procOrderDelete[ordered.items[j].GetEntityPluralName()]
+}
+
+func (ordered deleteOrderedItems) Swap(i, j int) {
+	ordered.items[i], ordered.items[j] = ordered.items[j], ordered.items[i]
+}
+
+func (processor sqlMessageProcessor) initialProcessLoop(sqlProcessor *compoundSQLProcessor, requestData syncmsg.ProtoSyncEntityMessageRequest, response *syncmsg.ProtoSyncEntityMessageResponse, nodeIDToProcess string, transactionBindID string) (map[string][]readInitialTransactionBindResult, error) {
 	unprocessedMsgs := make(map[string][]readInitialTransactionBindResult)
 	sqlProcessor.processStart(requestData, nodeIDToProcess, transactionBindID)
 	err := processor.initialProcessChangesLoop(sqlProcessor, requestData)
 	if err != nil {
-		// TODO(doug4j@gmail.com): Add check for 'ERROR:  could not serialize access due to concurrent update' as
-		// described in http://www.postgresql.org/docs/current/static/transaction-iso.html
+		// TODO(doug4j@gmail.com): Add check for the database refusing a concurrent write, such as postgressql's
+		// 'ERROR:  could not serialize access due to concurrent update' as described in
+		// http://www.postgresql.org/docs/current/static/transaction-iso.html or sqlite's 'database is locked'
+		// (SQLITE_BUSY) once the busy_timeout is exceeded
 		syncutil.Error(err)
 		response.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
 		response.ResultMsg = proto.String(err.Error())
@@ -118,7 +220,7 @@ func (processor postgresSQLMessageProcessor) initialProcessLoop(sqlProcessor *co
 		response.ResultMsg = proto.String(err.Error())
 		return unprocessedMsgs, err
 	}
-	unprocessedMsgs, err = processor.initialProcessReadChanges(sqlProcessor.builders[1].result(), transactionBindID, response)
+	unprocessedMsgs, err = processor.initialProcessReadChanges(sqlProcessor.builders[1].result()[0], transactionBindID, response)
 	if err != nil {
 		syncutil.Error(err)
 		response.Result = syncmsg.SyncEntityMessageResponseResult_Error.Enum()
@@ -128,26 +230,38 @@ func (processor postgresSQLMessageProcessor) initialProcessLoop(sqlProcessor *co
 	return unprocessedMsgs, nil
 }
 
-func (processor postgresSQLMessageProcessor) initialProcessApplyChanges(processInitialChangeSQL string) error {
-	//syncutil.Debug("processInitialChangeSQL", processInitialChangeSQL)
-	_, err := processor.db.Exec(processInitialChangeSQL)
+//initialProcessApplyChanges runs the statements persisting the fast batch in one transaction.
+func (processor sqlMessageProcessor) initialProcessApplyChanges(statements []sqlStatement) error {
+	tx, err := processor.db.Begin()
 	if err != nil {
-		syncutil.Error(err, ". Error processing initial database changes")
+		syncutil.Error(err, ". Error starting initial database changes")
+		return err
+	}
+	for _, statement := range statements {
+		_, err = tx.Exec(statement.sql, statement.args...)
+		if err != nil {
+			syncutil.Error(err, ". Error processing initial database changes")
+			rollbackQuietly(tx)
+			return err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		syncutil.Error(err, ". Error committing initial database changes")
 		return err
 	}
 	return nil
 }
 
 type readInitialTransactionBindResult struct {
-	entitySingularName, recordID, recordHash, recordData, transactionBindReceiveID string
-	isDelete                                                                       bool
+	entitySingularName, entityPluralName, recordID, recordHash, recordData, transactionBindReceiveID string
+	isDelete                                                                                         bool
 }
 
-func (processor postgresSQLMessageProcessor) initialProcessReadChanges(readInitialChangeSQL string, transactionBindID string, response *syncmsg.ProtoSyncEntityMessageResponse) (map[string][]readInitialTransactionBindResult, error) {
+func (processor sqlMessageProcessor) initialProcessReadChanges(readInitialChange sqlStatement, transactionBindID string, response *syncmsg.ProtoSyncEntityMessageResponse) (map[string][]readInitialTransactionBindResult, error) {
 	processedFastBatchMsgs := make(map[string]map[string]string)
 	unprocessedMsgs := make(map[string][]readInitialTransactionBindResult)
-	//syncutil.Debug("readInitialChangeSql", readInitialChangeSQL)
-	rows, err := processor.db.Query(readInitialChangeSQL)
+	rows, err := processor.db.Query(readInitialChange.sql, readInitialChange.args...)
 	if err != nil {
 		syncutil.Error(err, ". Error reading initial database changes")
 		return unprocessedMsgs, err
@@ -182,6 +296,7 @@ func (processor postgresSQLMessageProcessor) initialProcessReadChanges(readIniti
 			}
 			unprocessedMsgs[entitySingularName] = append(unprocessedMsgs[entitySingularName], readInitialTransactionBindResult{
 				entitySingularName:       entitySingularName,
+				entityPluralName:         entityPluralName,
 				recordID:                 recordID,
 				recordHash:               recordHash,
 				recordData:               recordData,
@@ -199,6 +314,7 @@ func (processor postgresSQLMessageProcessor) initialProcessReadChanges(readIniti
 				Msgs: []*syncmsg.ProtoSyncDataMessageResponse{
 					&syncmsg.ProtoSyncDataMessageResponse{
 						RecordId:     proto.String(fastBatchRecordID),
+						RequestHash:  proto.String(fastBatchRecordHash),
 						ResponseHash: proto.String(fastBatchRecordHash),
 						SyncState:    syncmsg.AckSyncStateEnum_AckFastBatch.Enum(),
 					},
@@ -209,7 +325,7 @@ func (processor postgresSQLMessageProcessor) initialProcessReadChanges(readIniti
 	return unprocessedMsgs, nil
 }
 
-func (processor postgresSQLMessageProcessor) initialProcessChangesLoop(sqlProcessor *compoundSQLProcessor, requestData syncmsg.ProtoSyncEntityMessageRequest) error {
+func (processor sqlMessageProcessor) initialProcessChangesLoop(sqlProcessor *compoundSQLProcessor, requestData syncmsg.ProtoSyncEntityMessageRequest) error {
 	recordIndexLen := len(requestData.Items)
 	for recordIndex, item := range requestData.Items {
 
@@ -230,7 +346,8 @@ func (processor postgresSQLMessageProcessor) initialProcessChangesLoop(sqlProces
 	return nil
 }
 
-func (processor postgresSQLMessageProcessor) findNodeEntityFields(nodeIDToProcess string, syncEntitySingularName string) (map[string]syncdao.SyncFieldDefinition, error) {
+//findNodeEntityFields gives the field definitions of an entity in the data version of the given node.
+func findNodeEntityFields(db queryer, nodeIDToProcess string, syncEntitySingularName string) (map[string]syncdao.SyncFieldDefinition, error) {
 
 	answer := map[string]syncdao.SyncFieldDefinition{}
 	//answer := map[string]syncdao.SyncFieldTypeEnum{}
@@ -241,7 +358,7 @@ FROM            sync_node INNER JOIN
                          sync_data_field ON sync_data_version.DataVersionName = sync_data_field.DataVersionName
 WHERE        (sync_node.NodeId = $1 and EntitySingularName = $2);
 `
-	rows, err := processor.db.Query(sqlStr, nodeIDToProcess, syncEntitySingularName)
+	rows, err := db.Query(sqlStr, nodeIDToProcess, syncEntitySingularName)
 	if err != nil {
 		syncutil.Error(err)
 		return answer, errors.New("Error finding Node Entity fields: " + err.Error())
@@ -253,7 +370,7 @@ WHERE        (sync_node.NodeId = $1 and EntitySingularName = $2);
 		}
 	}
 	defer closeRowQuietly()
-	err = processor.mapFoundNodeEntitiesFinal(rows, &answer)
+	err = mapFoundNodeEntitiesFinal(rows, &answer)
 	if err != nil {
 		syncutil.Error(err)
 		return answer, err
@@ -261,7 +378,7 @@ WHERE        (sync_node.NodeId = $1 and EntitySingularName = $2);
 	return answer, nil
 }
 
-func (processor postgresSQLMessageProcessor) mapFoundNodeEntitiesFinal(rows *sql.Rows, answer *map[string]syncdao.SyncFieldDefinition) error {
+func mapFoundNodeEntitiesFinal(rows *sql.Rows, answer *map[string]syncdao.SyncFieldDefinition) error {
 	localAnswer := map[string]syncdao.SyncFieldDefinition{}
 	for rows.Next() {
 		var (
@@ -329,7 +446,24 @@ func (processor postgresSQLMessageProcessor) mapFoundNodeEntitiesFinal(rows *sql
 	return nil
 }
 
-func (processor postgresSQLMessageProcessor) findSingularEntityName(syncEntityPluralName string, dataVersion string) (string, error) {
+//findNodeDataVersionName gives the data version of the node the session syncs with.
+func (processor sqlMessageProcessor) findNodeDataVersionName() (string, error) {
+	var answer string
+	err := processor.db.QueryRow("SELECT DataVersionName FROM sync_node WHERE (NodeId = $1);", processor.NodeID).Scan(&answer)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := "Cannot find data version of node '" + processor.NodeID + "'"
+		syncutil.Error(msg)
+		return answer, errors.New(msg)
+	case err != nil:
+		syncutil.Error(err, ". Cannot find data version of node '"+processor.NodeID+"'")
+		return answer, err
+	default:
+		return answer, nil
+	}
+}
+
+func (processor sqlMessageProcessor) findSingularEntityName(syncEntityPluralName string, dataVersion string) (string, error) {
 	var answer string
 	sqlStr := `
 SELECT        EntitySingularName
@@ -351,53 +485,27 @@ WHERE        (DataVersionName = $1 and EntityPluralName = $2);
 	}
 }
 
-func calculateSQLValue(field syncmsg.ProtoField, fieldDefinitions map[string]syncdao.SyncFieldDefinition, syncEntityName string) (string, error) {
-	fieldName := *field.FieldName
-	fieldDefinition := fieldDefinitions[fieldName]
-	if fieldDefinition.FieldType == syncdao.SyncFieldTypeEnumUndefined {
-		return "", errors.New("field " + fieldName + " does  not match a known field definition for entity " + syncEntityName)
-	}
-	valueType := *field.EncodedFieldType
-	switch fieldDefinition.FieldType {
-	case syncdao.SyncFieldTypeEnumString:
-		return fmt.Sprintf("'%v'", syncdao.CalculateValue(valueType, field.FieldValue)), nil
-	case syncdao.SyncFieldTypeEnumDate:
-		creator := syncmsg.NewCreator()
-		rawTimeString := fmt.Sprintf("%v", syncdao.CalculateValue(valueType, field.FieldValue))
-		theTime := creator.FormatTimeFromString(rawTimeString)
-		if len(creator.Errors) != 0 {
-			err := syncmsg.NewCreatorError(creator.Errors)
-			syncutil.Error(err.Error())
-			return "", err
-		}
-		timeString := theTime.Format(SQLDateFormat)
-		return "'" + timeString + "'", nil
-	default:
-		return fmt.Sprintf("%v", syncdao.CalculateValue(valueType, field.FieldValue)), nil
-	}
-}
-
 type sqlBuilder interface {
 	processStart(msg changeEntityMessage, nodeIDToProcess string, transactionBindID string)
 	processEnd()
 	startRecords(item changeDataMessageList)
 	startRecordItem(item changeDataMessage, requestData changeEntityMessage, changeDataMessages changeDataMessageList) error
-	result() string
+	result() []sqlStatement
 }
 
 type compoundSQLProcessor struct {
 	builders           []sqlBuilder
+	dataVersionName    string
 	requestData        changeEntityMessage
 	changeDataMessages changeDataMessageList
 	changeDataMessage  changeDataMessage
 }
 
 func (processor *compoundSQLProcessor) processStart(requestData syncmsg.ProtoSyncEntityMessageRequest, nodeIDToProcess string, transactionBindID string) {
-	now := time.Now()
-	format := "2006-01-02 15:04:05.000"
 	processor.requestData = changeEntityMessage{
 		isDelete:          *requestData.IsDelete,
-		NowDateString:     now.Format(format),
+		now:               time.Now(),
+		DataVersionName:   processor.dataVersionName,
 		NodeIDToProcess:   nodeIDToProcess,
 		TransactionBindID: transactionBindID,
 	}
@@ -406,10 +514,9 @@ func (processor *compoundSQLProcessor) processStart(requestData syncmsg.ProtoSyn
 	}
 }
 
-func (processor *compoundSQLProcessor) startRecords(item *syncmsg.ProtoSyncDataMessagesRequest, recordIndex int, recordIndexLen int, msgProcessor postgresSQLMessageProcessor) error {
-	// TODO(doug4j@gmail.com): Remove hard coding of data version name
+func (processor *compoundSQLProcessor) startRecords(item *syncmsg.ProtoSyncDataMessagesRequest, recordIndex int, recordIndexLen int, msgProcessor sqlMessageProcessor) error {
 	entityPluralName := *item.EntityPluralName
-	entitySingularName, err := msgProcessor.findSingularEntityName(entityPluralName, "Demo Model 1")
+	entitySingularName, err := msgProcessor.findSingularEntityName(entityPluralName, processor.requestData.DataVersionName)
 	if err != nil {
 		syncutil.Error(err)
 		return err
@@ -417,7 +524,7 @@ func (processor *compoundSQLProcessor) startRecords(item *syncmsg.ProtoSyncDataM
 
 	//Get entity field definitions
 	var fieldDefinitions map[string]syncdao.SyncFieldDefinition
-	fieldDefinitions, err = msgProcessor.findNodeEntityFields(processor.requestData.NodeIDToProcess, entitySingularName)
+	fieldDefinitions, err = findNodeEntityFields(msgProcessor.db, processor.requestData.NodeIDToProcess, entitySingularName)
 	if err != nil {
 		syncutil.Error(err)
 		return err
@@ -452,26 +559,19 @@ func (processor *compoundSQLProcessor) startRecords(item *syncmsg.ProtoSyncDataM
 
 func (processor *compoundSQLProcessor) startRecordItem(item *syncmsg.ProtoSyncDataMessageRequest, itemIndex int, indexLength int) error {
 	//printSyncDebugItem(msgIndex int, msg *syncmsg.ProtoSyncDataMessageRequest, record syncmsg.ProtoRecord)
-	var (
-		sentSyncStateString = item.SentSyncState.Enum().String()
-		lastKnownPeerHash   string
-	)
+	var lastKnownPeerHash string
 	if item.LastKnownPeerHash != nil {
 		lastKnownPeerHash = *item.LastKnownPeerHash
 	}
 	processor.changeDataMessage = changeDataMessage{
-		recordData:    item.RecordData,
-		sentSyncState: *item.SentSyncState,
-		//recordIndexStr         string                    = fmt.Sprintf("%v", recordIndex+1)
-		MsgIndexStr:            fmt.Sprintf("%v", itemIndex+1),
-		msgIndex:               itemIndex,
-		msgIndexLen:            indexLength,
-		RecordID:               *item.RecordId,
-		RecordDataHex:          hex.EncodeToString(item.RecordData),
-		RecordHash:             *item.RecordHash,
-		LastKnownPeerHash:      lastKnownPeerHash,
-		RecordBytesSizeStr:     fmt.Sprintf("%v", *item.RecordBytesSize),
-		SentSyncStateNumString: fmt.Sprintf("%v", syncmsg.SentSyncStateEnum_value[sentSyncStateString]),
+		recordData:        item.RecordData,
+		sentSyncState:     *item.SentSyncState,
+		msgIndex:          itemIndex,
+		msgIndexLen:       indexLength,
+		RecordID:          *item.RecordId,
+		RecordHash:        *item.RecordHash,
+		LastKnownPeerHash: lastKnownPeerHash,
+		RecordBytesSize:   *item.RecordBytesSize,
 	}
 	for _, builder := range processor.builders {
 		err := builder.startRecordItem(processor.changeDataMessage, processor.requestData, processor.changeDataMessages)
@@ -485,13 +585,13 @@ func (processor *compoundSQLProcessor) startRecordItem(item *syncmsg.ProtoSyncDa
 func (processor *compoundSQLProcessor) processEnd(requestData syncmsg.ProtoSyncEntityMessageRequest) {
 	for _, builder := range processor.builders {
 		builder.processEnd()
-		//syncutil.Debug(reflect.TypeOf(builder).String() + ".result()=" + builder.result())
 	}
 }
 
 type changeEntityMessage struct {
 	isDelete          bool
-	NowDateString     string
+	now               time.Time
+	DataVersionName   string
 	NodeIDToProcess   string
 	TransactionBindID string
 	//entityFieldDefinitions map[string]map[string]syncdao.SyncFieldDefinition
@@ -508,49 +608,58 @@ type changeDataMessageList struct {
 }
 
 type changeDataMessage struct {
-	recordData             []byte
-	sentSyncState          syncmsg.SentSyncStateEnum
-	RecordIndexStr         string
-	msgIndexLen            int
-	msgIndex               int
-	MsgIndexStr            string
-	LastKnownPeerHash      string
-	RecordID               string
-	RecordDataHex          string
-	RecordHash             string
-	RecordBytesSizeStr     string
-	SentSyncStateNumString string
+	recordData        []byte
+	sentSyncState     syncmsg.SentSyncStateEnum
+	msgIndexLen       int
+	msgIndex          int
+	LastKnownPeerHash string
+	RecordID          string
+	RecordHash        string
+	RecordBytesSize   uint32
 }
 
+//lock gives the recordLock holding while the local record is still the one the peer last knew of.
+func (item changeDataMessage) lock(changeDataMessages changeDataMessageList) *recordLock {
+	return &recordLock{
+		entitySingularName: changeDataMessages.SyncEntitySingularName,
+		recordID:           item.RecordID,
+		recordHash:         item.LastKnownPeerHash,
+	}
+}
+
+//changeInitialSQLBuilder collects the statements persisting a fast batch, all of which are run in one transaction.
 type changeInitialSQLBuilder struct {
-	sql string
+	dialect    Dialect
+	statements []sqlStatement
 }
 
 func (builder *changeInitialSQLBuilder) processStart(msg changeEntityMessage, nodeIDToProcess string, transactionBindID string) {
-	builder.sql = "begin;"
+	builder.statements = []sqlStatement{}
 }
 
 func (builder *changeInitialSQLBuilder) startRecords(item changeDataMessageList) {
 }
 
 func (builder *changeInitialSQLBuilder) startRecordItem(item changeDataMessage, requestData changeEntityMessage, changeDataMessages changeDataMessageList) error {
-	switch item.sentSyncState {
-	case syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer:
+	switch {
+	case requestData.isDelete:
+		//syncutil.Debug("Processing delete for recordId", item.recordId)
+		err := builder.handleSyncDelete(item, requestData, changeDataMessages)
+		if err != nil {
+			return err
+		}
+	case item.sentSyncState == syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer:
 		//syncutil.Debug("Processing first time sent to peer for recordId", item.recordId)
 
 		err := builder.handleSyncFirstTimeSentToPeer(item, requestData, changeDataMessages)
 		if err != nil {
 			return err
 		}
-	case syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer:
+	case item.sentSyncState == syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer:
 		//syncutil.Debug("Processing standard to peer for recordId", item.recordId)
-		if requestData.isDelete {
-			syncutil.NotImplementedMsg("Delete me")
-		} else {
-			err := builder.handleSyncStandardSentToPeerUpdate(item, requestData, changeDataMessages)
-			if err != nil {
-				return err
-			}
+		err := builder.handleSyncStandardSentToPeerUpdate(item, requestData, changeDataMessages)
+		if err != nil {
+			return err
 		}
 	default:
 		errMsg := "Unsupported sentSyncState " + item.sentSyncState.String()
@@ -560,234 +669,166 @@ func (builder *changeInitialSQLBuilder) startRecordItem(item changeDataMessage,
 	return nil
 }
 
-func (builder *changeInitialSQLBuilder) createSyncFirstTimeSentSQL(item changeDataMessage, requestData changeEntityMessage, changeDataMessages changeDataMessageList) (string, error) {
-	var templateStr = `
-	-- msg {{.Item.MsgIndexStr}} | rec {{.Item.RecordIndexStr}} SyncState
-	insert into sync_state (EntitySingularName, RecordId, DataVersionName, RecordHash, RecordData, RecordBytesSize, IsDelete)
-	values('{{.ChangeDataMessages.SyncEntitySingularName}}', '{{.Item.RecordID}}', 'Demo Model 1', '{{.Item.RecordHash}}',
-	' {{.Item.RecordDataHex}}', {{.Item.RecordBytesSizeStr}}, False);
-	-- msg {{.Item.MsgIndexStr}} | rec {{.Item.RecordIndexStr}} SyncPeerState
-	insert into sync_peer_state (NodeId, EntitySingularName, RecordId, TransactionBindReceiveId, SentLastKnownHash,
-		PeerLastKnownHash, SentSyncState, RecordBytesSize, LastUpdated, RecordCreated)
-		values ('{{.RequestData.NodeIDToProcess}}', '{{.ChangeDataMessages.SyncEntitySingularName}}', '{{.Item.RecordID}}',
-			'{{.RequestData.TransactionBindID}}', '{{.Item.RecordHash}}', '{{.Item.RecordHash}}', {{.Item.SentSyncStateNumString}},
-			{{.Item.RecordBytesSizeStr}}, '{{.RequestData.NowDateString}}', '{{.RequestData.NowDateString}}');`
-	sqlTemplate := template.Must(template.New("syncFirstTimeSentSQL").Parse(templateStr))
-	var substitutedSQLBytes bytes.Buffer
-	var data = struct {
-		Item               changeDataMessage
-		RequestData        changeEntityMessage
-		ChangeDataMessages changeDataMessageList
-	}{item, requestData, changeDataMessages}
-	err := sqlTemplate.Execute(&substitutedSQLBytes, data)
-	if err != nil {
-		return "", err
-
-	}
-	substitutedSQL := substitutedSQLBytes.String()
-	//syncutil.Debug(substitutedSQL)
-	return substitutedSQL, nil
+func (builder *changeInitialSQLBuilder) add(sqlStr string, args ...interface{}) {
+	builder.statements = append(builder.statements, sqlStatement{sql: sqlStr, args: args})
 }
 
+const sqlInsertFirstTimeSyncState = `
+insert into sync_state (EntitySingularName, RecordId, DataVersionName, RecordHash, RecordData, RecordBytesSize, IsDelete)
+values ($1, $2, $3, $4, $5, $6, False);`
+
+const sqlInsertFirstTimeSyncPeerState = `
+insert into sync_peer_state (NodeId, EntitySingularName, RecordId, TransactionBindReceiveId, SentLastKnownHash,
+	PeerLastKnownHash, SentSyncState, RecordBytesSize, LastUpdated, RecordCreated)
+values ($1, $2, $3, $4, $5, $5, $6, $7, $8, $8);`
+
 func (builder *changeInitialSQLBuilder) handleSyncFirstTimeSentToPeer(item changeDataMessage, requestData changeEntityMessage, changeDataMessages changeDataMessageList) error {
-	sql, err := builder.createSyncFirstTimeSentSQL(item, requestData, changeDataMessages)
-	if err != nil {
-		syncutil.Error(err.Error())
-		return err
-	}
-	builder.sql = builder.sql + sql
+	builder.add(sqlInsertFirstTimeSyncState, changeDataMessages.SyncEntitySingularName, item.RecordID, requestData.DataVersionName,
+		item.RecordHash, hex.EncodeToString(item.recordData), item.RecordBytesSize)
+	builder.add(sqlInsertFirstTimeSyncPeerState, requestData.NodeIDToProcess, changeDataMessages.SyncEntitySingularName,
+		item.RecordID, requestData.TransactionBindID, item.RecordHash, int32(item.sentSyncState), item.RecordBytesSize,
+		requestData.now)
 
-	//Process Custom Table Start
+	//Process Custom Table
 	record := &syncmsg.ProtoRecord{}
-	err = proto.Unmarshal(item.recordData, record)
+	err := proto.Unmarshal(item.recordData, record)
 	if err != nil {
 		return err
 	}
-	//Process Custom Table Name
-	for fieldIndex, field := range record.Fields {
-		if fieldIndex == 0 {
-			builder.sql = builder.sql + `
--- msg ` + item.MsgIndexStr + ` | rec ` + item.RecordIndexStr + ` CustomTable
-insert into ` + changeDataMessages.SyncEntityPluralName + ` (` + *field.FieldName
-		} else {
-			builder.sql = builder.sql + `, ` + *field.FieldName
-		}
-	}
-	//Process Custom Table Value
-	for fieldIndex, field := range record.Fields {
-		rawValue, err := calculateSQLValue(*field, changeDataMessages.fieldDefinitions, changeDataMessages.SyncEntitySingularName)
-		if err != nil {
-			return err
-		}
-		valueAsString := fmt.Sprintf("%v", rawValue)
-		if fieldIndex == 0 {
-			builder.sql = builder.sql + `)
-	values (` + valueAsString
-		} else {
-			builder.sql = builder.sql + `, ` + valueAsString
-		}
+	insertSQL, insertArgs, err := createCustomTableInsertSQL(builder.dialect, changeDataMessages.SyncEntityPluralName, changeDataMessages.SyncEntitySingularName, record, changeDataMessages.fieldDefinitions)
+	if err != nil {
+		return err
 	}
-	builder.sql = builder.sql + `);`
+	builder.add(insertSQL, insertArgs...)
 	return nil
 }
 
-func (builder *changeInitialSQLBuilder) handleSyncStandardSentToPeerUpdate(item changeDataMessage, requestData changeEntityMessage, changeDataMessages changeDataMessageList) error {
+// TODO(doug4j@gmail.com): Is there a reason for updating PeerLastKnownHash? Is it possible there is a PeerLastKnownSendHash and PeerLastKnownReceiveHash instead of just PeerLastKnownHash? And with it, do we update a PeerLastKnownReceiveHash here?
+const sqlUpdateStandardSyncPeerState = `
+update sync_peer_state set TransactionBindReceiveId=$1, PeerLastKnownHash=$2, LastUpdated=$3
+where (NodeId=$4 AND EntitySingularName=$5 AND RecordId=$6 AND
+	((select count(RecordId) as syncRecordCount from sync_state where (EntitySingularName=$5 AND RecordId=$6 AND RecordHash=$7)) = 1));`
 
-	var newSQLStr = ""
+const sqlUpdateStandardSyncState = `
+update sync_state set RecordHash=$1, RecordData=$2, RecordBytesSize=$3, IsDelete=false, DeletedDate=null
+where (EntitySingularName=$4 AND RecordId=$5 AND RecordHash=$6);`
 
-	//Process Custom Table Start
+func (builder *changeInitialSQLBuilder) handleSyncStandardSentToPeerUpdate(item changeDataMessage, requestData changeEntityMessage, changeDataMessages changeDataMessageList) error {
+	//Process Custom Table
 	record := &syncmsg.ProtoRecord{}
 	err := proto.Unmarshal(item.recordData, record)
 	if err != nil {
 		syncutil.Debug(err)
 		return err
 	}
-
-	newSQLStr, err = builder.processCustomTableUpdateBase(newSQLStr, item, changeDataMessages, record)
+	//The lock makes it so we don't update the custom table unless the item previous hash matches
+	updateSQL, updateArgs, err := createCustomTableUpdateSQL(builder.dialect, changeDataMessages.SyncEntityPluralName, changeDataMessages.SyncEntitySingularName, record, changeDataMessages.fieldDefinitions, item.lock(changeDataMessages))
 	if err != nil {
 		syncutil.Debug(err)
 		return err
 	}
-
-	newSQLStr, err = builder.processCustomTableUpdateWhere(newSQLStr, item, changeDataMessages, requestData, record)
-	if err != nil {
-		syncutil.Debug(err)
-		return err
-	}
-	builder.sql = builder.sql + newSQLStr
+	builder.add(updateSQL, updateArgs...)
+	builder.add(sqlUpdateStandardSyncPeerState, requestData.TransactionBindID, item.RecordHash, requestData.now,
+		requestData.NodeIDToProcess, changeDataMessages.SyncEntitySingularName, item.RecordID, item.LastKnownPeerHash)
+	builder.add(sqlUpdateStandardSyncState, item.RecordHash, hex.EncodeToString(item.recordData), item.RecordBytesSize,
+		changeDataMessages.SyncEntitySingularName, item.RecordID, item.LastKnownPeerHash)
 	return nil
 }
 
-func (builder *changeInitialSQLBuilder) processCustomTableUpdateBase(newSQLStr string, item changeDataMessage, changeDataMessages changeDataMessageList, record *syncmsg.ProtoRecord) (string, error) {
-	//Process Custom Table Name
-	fieldLen := len(record.Fields) - 1
-	for fieldIndex, field := range record.Fields {
-		if fieldIndex == 0 {
-			newSQLStr = newSQLStr + `
--- msg ` + item.MsgIndexStr + ` | rec ` + item.RecordIndexStr + ` CustomTable
-update ` + changeDataMessages.SyncEntityPluralName + ` set ` + *field.FieldName + `=`
+const sqlUpdateDeleteSyncPeerState = `
+update sync_peer_state set TransactionBindReceiveId=$1, PeerLastKnownHash=$2, IsDelete=true, LastUpdated=$3
+where (NodeId=$4 AND EntitySingularName=$5 AND RecordId=$6 AND
+	((select count(RecordId) as syncRecordCount from sync_state where (EntitySingularName=$5 AND RecordId=$6 AND RecordHash=$7)) = 1));`
 
-		} else {
-			newSQLStr = newSQLStr + *field.FieldName + `=`
-		}
-		rawValue, err := calculateSQLValue(*field, changeDataMessages.fieldDefinitions, changeDataMessages.SyncEntitySingularName)
-		if err != nil {
-			return newSQLStr, err
-		}
-		valueAsString := fmt.Sprintf("%v", rawValue)
-		if fieldIndex == fieldLen { //Is last field
-			newSQLStr = newSQLStr + valueAsString
-		} else {
-			newSQLStr = newSQLStr + valueAsString + `, `
-		}
-	}
-	return newSQLStr, nil
-}
-
-func (builder *changeInitialSQLBuilder) processCustomTableUpdateWhere(newSQLStr string, item changeDataMessage, changeDataMessages changeDataMessageList, requestData changeEntityMessage, record *syncmsg.ProtoRecord) (string, error) {
-	//The extra where clause '((select count(... where(make it so we don't
-	//update the custom table unless the item previous hash matches
+const sqlUpdateDeleteSyncState = `
+update sync_state set IsDelete=true, DeletedDate=$1
+where (EntitySingularName=$2 AND RecordId=$3 AND RecordHash=$4);`
 
-	nameValues, err := builder.createKeysAndSQLValues(changeDataMessages, record)
+//handleSyncDelete removes the record from the custom table and marks it deleted in sync_state and sync_peer_state.
+//Like an update, nothing is changed unless the local record is still the one the peer last knew of, leaving records
+//changed locally since then to the record level processing.
+func (builder *changeInitialSQLBuilder) handleSyncDelete(item changeDataMessage, requestData changeEntityMessage, changeDataMessages changeDataMessageList) error {
+	record := &syncmsg.ProtoRecord{}
+	err := proto.Unmarshal(item.recordData, record)
 	if err != nil {
-		syncutil.Error(err)
-		return "", err
-	}
-
-	for index, key := range changeDataMessages.entitySortedKeys {
-		if index == 0 {
-			newSQLStr = newSQLStr + ` where (` + key + `=` + nameValues[key]
-		} else {
-			newSQLStr = newSQLStr + ` AND ` + key + `=` + nameValues[key]
-		}
+		syncutil.Debug(err)
+		return err
 	}
-	sqlLockViaWhereOnRecordItem := `((select count(RecordId) as syncRecordCount from sync_state where (EntitySingularName='` + changeDataMessages.SyncEntitySingularName + `' AND RecordId='` + item.RecordID + `' AND RecordHash='` + item.LastKnownPeerHash + `'))) = 1)`
-
-	newSQLStr = newSQLStr + ` AND ((select count(RecordId) as syncRecordCount from sync_state where (EntitySingularName='` + changeDataMessages.SyncEntitySingularName + `' AND RecordId='` + item.RecordID + `' and RecordHash='` + item.LastKnownPeerHash + `'))) = 1);`
-	// TODO(doug4j@gmail.com): Is there a reason for updating PeerLastKnownHash? Is it possible there is a PeerLastKnownSendHash and PeerLastKnownReceiveHash instead of just PeerLastKnownHash? And with it, do we update a PeerLastKnownReceiveHash here?
-	newSQLStr = newSQLStr + `
--- msg ` + item.MsgIndexStr + ` | rec ` + item.RecordIndexStr + ` SyncPeerState
-update sync_peer_state set TransactionBindReceiveId='` + requestData.TransactionBindID + `', PeerLastKnownHash='` + item.RecordHash + `', LastUpdated='` + requestData.NowDateString + `' where (NodeId='` + requestData.NodeIDToProcess + `' AND EntitySingularName='` + changeDataMessages.SyncEntitySingularName + `' AND RecordId='` + item.RecordID + `' AND ` + sqlLockViaWhereOnRecordItem + `;` + `
--- msg ` + item.MsgIndexStr + ` | rec ` + item.RecordIndexStr + ` SyncState
-update sync_state set RecordHash='` + item.RecordHash + `', RecordData='` + item.RecordDataHex + `', RecordBytesSize=` + item.RecordBytesSizeStr + `, IsDelete=false where (EntitySingularName='` + changeDataMessages.SyncEntitySingularName + `' AND RecordId='` + item.RecordID + `' AND RecordHash='` + item.LastKnownPeerHash +
-		`');`
-
-	return newSQLStr, nil
-}
-
-func (builder *changeInitialSQLBuilder) createKeysAndSQLValues(changeDataMessages changeDataMessageList, record *syncmsg.ProtoRecord) (map[string]string, error) {
-	answer := make(map[string]string)
-	var err error
+	keyCount := 0
 	for _, field := range record.Fields {
-		fieldName := *field.FieldName
-		if _, found := changeDataMessages.entityKeyMap[fieldName]; found {
-			answer[fieldName], err = calculateSQLValue(*field, changeDataMessages.fieldDefinitions, changeDataMessages.SyncEntitySingularName)
-			if err != nil {
-				syncutil.Error(err)
-				return answer, err
-			}
+		if changeDataMessages.entityKeyMap[field.GetFieldName()] {
+			keyCount++
 		}
 	}
-	return answer, nil
+	if len(changeDataMessages.entitySortedKeys) == 0 || keyCount != len(changeDataMessages.entitySortedKeys) {
+		errMsg := "Delete of record '" + item.RecordID + "' does not carry the primary key of entity " + changeDataMessages.SyncEntitySingularName
+		syncutil.Error(errMsg)
+		return errors.New(errMsg)
+	}
+	deleteSQL, deleteArgs, err := createCustomTableDeleteSQL(builder.dialect, changeDataMessages.SyncEntityPluralName, changeDataMessages.SyncEntitySingularName, record, changeDataMessages.fieldDefinitions, item.lock(changeDataMessages))
+	if err != nil {
+		syncutil.Error(err)
+		return err
+	}
+	builder.add(deleteSQL, deleteArgs...)
+	builder.add(sqlUpdateDeleteSyncPeerState, requestData.TransactionBindID, item.RecordHash, requestData.now,
+		requestData.NodeIDToProcess, changeDataMessages.SyncEntitySingularName, item.RecordID, item.LastKnownPeerHash)
+	builder.add(sqlUpdateDeleteSyncState, requestData.now, changeDataMessages.SyncEntitySingularName, item.RecordID,
+		item.LastKnownPeerHash)
+	return nil
 }
 
 func (builder *changeInitialSQLBuilder) processEnd() {
-	builder.sql = builder.sql + `
-commit;`
-	//syncutil.Debug(self.sql)
 }
 
-func (builder *changeInitialSQLBuilder) result() string {
-	answer := builder.sql
-	//debug("answer=" + answer)
-	return answer
+func (builder *changeInitialSQLBuilder) result() []sqlStatement {
+	return builder.statements
 }
 
+//readInitialSQLBuilder creates the query reading back the local state of every record in the request.
 type readInitialSQLBuilder struct {
-	sql string
+	sql             string
+	args            sqlArgs
+	nodeIDToProcess string
+	hasRecords      bool
 }
 
 func (builder *readInitialSQLBuilder) processStart(msg changeEntityMessage, nodeIDToProcess string, transactionBindID string) {
-	builder.sql = ""
+	builder.args = sqlArgs{}
+	builder.nodeIDToProcess = nodeIDToProcess
+	builder.hasRecords = false
+	builder.sql = `
+select sync_state.EntitySingularName, sync_data_entity.EntityPluralName, sync_state.RecordId, sync_state.RecordHash, sync_state.RecordData, sync_state.IsDelete, sync_peer_state.TransactionBindReceiveId from sync_state INNER JOIN sync_peer_state ON sync_state.EntitySingularName = sync_peer_state.EntitySingularName AND sync_state.RecordId = sync_peer_state.RecordId INNER JOIN sync_data_entity ON sync_state.EntitySingularName = sync_data_entity.EntitySingularName where sync_peer_state.NodeId=` + builder.args.add(nodeIDToProcess) + ` AND (`
 }
 
 func (builder *readInitialSQLBuilder) startRecords(item changeDataMessageList) {
-
-	if item.recordIndex == 0 {
+	if item.recordIndex != 0 {
 		builder.sql = builder.sql + `
-select sync_state.EntitySingularName, sync_data_entity.EntityPluralName, sync_state.RecordId, sync_state.RecordHash, sync_state.RecordData, sync_state.IsDelete, sync_peer_state.TransactionBindReceiveId from sync_state INNER JOIN sync_peer_state ON sync_state.EntitySingularName = sync_peer_state.EntitySingularName AND sync_state.RecordId = sync_peer_state.RecordId INNER JOIN sync_data_entity ON sync_state.EntitySingularName = sync_data_entity.EntitySingularName where (
-	(sync_state.EntitySingularName='` + item.SyncEntitySingularName + `' AND`
-	} else if item.recordIndex == item.recordIndexLen {
-		builder.sql = builder.sql + `
-	(sync_state.EntitySingluarName='` + item.SyncEntitySingularName + `'`
-	} else {
-		builder.sql = builder.sql + `
-	(sync_state.EntitySingularName='` + item.SyncEntitySingularName + `' AND`
+	OR`
 	}
-	//debug("self.sql=" + self.sql)
+	builder.sql = builder.sql + `
+	(sync_state.EntitySingularName=` + builder.args.add(item.SyncEntitySingularName) + ` AND sync_state.RecordId in (`
+	builder.hasRecords = true
 }
 
 func (builder *readInitialSQLBuilder) processEnd() {
+	if !builder.hasRecords {
+		builder.sql = builder.sql + "false"
+	}
 	builder.sql = builder.sql + `
 );`
 }
 
 func (builder *readInitialSQLBuilder) startRecordItem(item changeDataMessage, requestData changeEntityMessage, changeDataMessages changeDataMessageList) error {
-	if (item.msgIndex == 0) && (item.msgIndex == item.msgIndexLen) {
-		builder.sql = builder.sql + ` sync_state.RecordId='` + item.RecordID + `')`
-	} else if item.msgIndex == 0 {
-		builder.sql = builder.sql + ` sync_state.RecordId='` + item.RecordID + `' OR`
-	} else if item.msgIndex == item.msgIndexLen {
-		builder.sql = builder.sql + ` sync_state.RecordId='` + item.RecordID + `')`
+	builder.sql = builder.sql + builder.args.add(item.RecordID)
+	if item.msgIndex == item.msgIndexLen {
+		builder.sql = builder.sql + `))`
 	} else {
-		builder.sql = builder.sql + ` sync_state.RecordId='` + item.RecordID + `' OR`
+		builder.sql = builder.sql + `, `
 	}
-	//syncutil.Debug("self.sql", self.sql)
 	return nil
 }
 
-func (builder *readInitialSQLBuilder) result() string {
-	answer := builder.sql
-	//debug("answer=" + answer)
-	return answer
+func (builder *readInitialSQLBuilder) result() []sqlStatement {
+	return []sqlStatement{{sql: builder.sql, args: builder.args}}
 }
@@ -0,0 +1,600 @@
+package syncdaosql
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+//recordLevelOutcome is the result of processing a single record outside of the fast batch.
+type recordLevelOutcome struct {
+	syncState    syncmsg.AckSyncStateEnum
+	responseHash string
+	recordData   []byte
+}
+
+//recordLevelProcessLoop processes, one record at a time, the messages the fast batch could not persist. A record
+//lands here when the local sync_state.RecordHash no longer matches the LastKnownPeerHash sent by the peer, meaning
+//both sides changed the record since they last synced.
+func (processor sqlMessageProcessor) recordLevelProcessLoop(requestData syncmsg.ProtoSyncEntityMessageRequest, unprocessedMsgs map[string][]readInitialTransactionBindResult, response *syncmsg.ProtoSyncEntityMessageResponse) error {
+	requestMsgs := make(map[string]map[string]*syncmsg.ProtoSyncDataMessageRequest)
+	for _, item := range requestData.Items {
+		entityPluralName := item.GetEntityPluralName()
+		if _, ok := requestMsgs[entityPluralName]; !ok {
+			requestMsgs[entityPluralName] = make(map[string]*syncmsg.ProtoSyncDataMessageRequest)
+		}
+		for _, msg := range item.Msgs {
+			requestMsgs[entityPluralName][msg.GetRecordId()] = msg
+		}
+	}
+
+	entitySingularNames := make([]string, 0, len(unprocessedMsgs))
+	for entitySingularName := range unprocessedMsgs {
+		entitySingularNames = append(entitySingularNames, entitySingularName)
+	}
+	sort.Strings(entitySingularNames)
+
+	resolver, err := processor.findConflictResolver()
+	if err != nil {
+		return err
+	}
+
+	for _, entitySingularName := range entitySingularNames {
+		fieldDefinitions, err := findNodeEntityFields(processor.db, processor.NodeID, entitySingularName)
+		if err != nil {
+			syncutil.Error(err)
+			return err
+		}
+		var entityResponse *syncmsg.ProtoSyncDataMessagesResponse
+		for _, localRecord := range unprocessedMsgs[entitySingularName] {
+			msg, found := requestMsgs[localRecord.entityPluralName][localRecord.recordID]
+			if !found {
+				syncutil.Warn("Record '", localRecord.recordID, "' of entity '", entitySingularName, "' is not part of the request. Skipping it.")
+				continue
+			}
+			outcome, err := processor.processRecordLevel(localRecord, msg, requestData, fieldDefinitions, resolver)
+			if err != nil {
+				syncutil.Error(err)
+				return err
+			}
+			if entityResponse == nil {
+				entityResponse = &syncmsg.ProtoSyncDataMessagesResponse{
+					EntityPluralName: proto.String(localRecord.entityPluralName),
+					Msgs:             []*syncmsg.ProtoSyncDataMessageResponse{},
+				}
+				response.Items = append(response.Items, entityResponse)
+			}
+			msgResponse := &syncmsg.ProtoSyncDataMessageResponse{
+				RecordId:     proto.String(localRecord.recordID),
+				RequestHash:  proto.String(msg.GetRecordHash()),
+				ResponseHash: proto.String(outcome.responseHash),
+				SyncState:    outcome.syncState.Enum(),
+			}
+			if outcome.recordData != nil {
+				msgResponse.RecordBytesSize = proto.Uint32(uint32(len(outcome.recordData)))
+				msgResponse.RecordData = outcome.recordData
+			}
+			entityResponse.Msgs = append(entityResponse.Msgs, msgResponse)
+		}
+	}
+	return nil
+}
+
+//processRecordLevel compares the incoming record against the local one and either persists the combined result or
+//hands the record to the pair's conflict resolver. Logically it works as follows:
+//
+// 1. both sides hold the same content or both deleted it -> nothing changes, AckFastBatch
+//
+// 2. the changes since the common ancestor (the version identified by LastKnownPeerHash) touch different fields ->
+// persist the merged record, AckRecordLevelConflictResolvedSeparateFieldsChanged. When the ancestor is no longer
+// kept, which side changed a field cannot be told, so every field holding different values conflicts (see
+// syncmsg.MergeRecords)
+//
+// 3. the changes overlap on at least one field, or one side deleted the record while the other updated it -> see
+// resolveRecordConflict
+func (processor sqlMessageProcessor) processRecordLevel(localRecord readInitialTransactionBindResult, msg *syncmsg.ProtoSyncDataMessageRequest, requestData syncmsg.ProtoSyncEntityMessageRequest, fieldDefinitions map[string]syncdao.SyncFieldDefinition, resolver syncapi.ConflictResolver) (recordLevelOutcome, error) {
+	transactionBindID := requestData.GetTransactionBindId()
+	bothDeleted := localRecord.isDelete && requestData.GetIsDelete()
+	if bothDeleted || (!localRecord.isDelete && !requestData.GetIsDelete() && msg.GetRecordHash() == localRecord.recordHash) {
+		err := processor.markRecordReceived(localRecord, localRecord.recordHash, transactionBindID)
+		if err != nil {
+			return recordLevelOutcome{}, err
+		}
+		return recordLevelOutcome{
+			syncState:    syncmsg.AckSyncStateEnum_AckFastBatch,
+			responseHash: localRecord.recordHash,
+		}, nil
+	}
+
+	localBytes, err := decodeStoredRecordData(localRecord.recordData)
+	if err != nil {
+		syncutil.Error(err, ". Error decoding local record data for record", localRecord.recordID)
+		return recordLevelOutcome{}, err
+	}
+	local := &syncmsg.ProtoRecord{}
+	err = proto.Unmarshal(localBytes, local)
+	if err != nil {
+		syncutil.Error(err, ". Error unmarshaling local record", localRecord.recordID)
+		return recordLevelOutcome{}, err
+	}
+	remote := &syncmsg.ProtoRecord{}
+	err = proto.Unmarshal(msg.RecordData, remote)
+	if err != nil {
+		syncutil.Error(err, ". Error unmarshaling incoming record", localRecord.recordID)
+		return recordLevelOutcome{}, err
+	}
+
+	ancestor, err := findAncestorRecord(processor.db, localRecord.entitySingularName, localRecord.recordID, msg.GetLastKnownPeerHash())
+	if err == syncdao.ErrDaoNoDataFound {
+		syncutil.Debug("No ancestor kept for record", localRecord.recordID, "with hash", msg.GetLastKnownPeerHash(), "merging without it")
+	} else if err != nil {
+		return recordLevelOutcome{}, err
+	}
+
+	conflict := syncapi.RecordConflict{
+		SessionID:          processor.SessionID,
+		RemoteNodeID:       processor.NodeID,
+		EntitySingularName: localRecord.entitySingularName,
+		EntityPluralName:   localRecord.entityPluralName,
+		RecordID:           localRecord.recordID,
+		ConflictingFields:  []string{},
+		Ancestor:           ancestor,
+		Local: syncapi.ConflictRecordVersion{
+			RecordHash: localRecord.recordHash,
+			IsDelete:   localRecord.isDelete,
+			Record:     local,
+		},
+		Remote: syncapi.ConflictRecordVersion{
+			RecordHash: msg.GetRecordHash(),
+			IsDelete:   requestData.GetIsDelete(),
+			Record:     remote,
+		},
+	}
+	if !conflict.IsDeleteAndUpdate() {
+		merged, conflictingFields := syncmsg.MergeRecords(ancestor, local, remote)
+		if len(conflictingFields) == 0 {
+			mergedHash, mergedBytes, err := processor.applyRecord(localRecord, merged, transactionBindID, fieldDefinitions)
+			if err != nil {
+				return recordLevelOutcome{}, err
+			}
+			return recordLevelOutcome{
+				syncState:    syncmsg.AckSyncStateEnum_AckRecordLevelConflictResolvedSeparateFieldsChanged,
+				responseHash: mergedHash,
+				recordData:   mergedBytes,
+			}, nil
+		}
+		conflict.ConflictingFields = conflictingFields
+	}
+	return processor.resolveRecordConflict(conflict, localRecord, localBytes, transactionBindID, fieldDefinitions, resolver)
+}
+
+//resolveRecordConflict consults the pair's conflict resolver (if any) for a record changed on both sides and
+//reports one of the following:
+//
+//  CONFLICT            RESOLVER                 LOGICAL ENUM
+//  --------            --------                 ------------
+//  field level         none configured          AckFieldLevelConflictwithNoAutoResolverAvailable
+//  field level         resolved                 AckFieldLevelConflictResolvedWithAutoResolver
+//  field level         did not resolve          AckFieldLevelConflictWithNoAutoResolverResolution
+//  delete and update   none configured          AckDeleteAndUpdateConflictWithNoAutoResolverAvailable
+//  delete and update   did not resolve          AckDeleteAndUpdateConflictWithNoAutoResolution
+//  delete and update   resolved                 AckDeleteAndUpdateConflictWithAutoResolution
+func (processor sqlMessageProcessor) resolveRecordConflict(conflict syncapi.RecordConflict, localRecord readInitialTransactionBindResult, localBytes []byte, transactionBindID string, fieldDefinitions map[string]syncdao.SyncFieldDefinition, resolver syncapi.ConflictResolver) (recordLevelOutcome, error) {
+	noResolverState := syncmsg.AckSyncStateEnum_AckFieldLevelConflictwithNoAutoResolverAvailable
+	unresolvedState := syncmsg.AckSyncStateEnum_AckFieldLevelConflictWithNoAutoResolverResolution
+	resolvedState := syncmsg.AckSyncStateEnum_AckFieldLevelConflictResolvedWithAutoResolver
+	if conflict.IsDeleteAndUpdate() {
+		noResolverState = syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithNoAutoResolverAvailable
+		unresolvedState = syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithNoAutoResolution
+		resolvedState = syncmsg.AckSyncStateEnum_AckDeleteAndUpdateConflictWithAutoResolution
+	}
+	syncutil.Info("Record '", conflict.RecordID, "' of entity '", conflict.EntitySingularName, "' conflicts. Conflicting fields:", conflict.ConflictingFields, "delete and update:", conflict.IsDeleteAndUpdate())
+
+	unresolvedOutcome := recordLevelOutcome{
+		syncState:    noResolverState,
+		responseHash: localRecord.recordHash,
+		recordData:   localBytes,
+	}
+	if resolver != nil {
+		unresolvedOutcome.syncState = unresolvedState
+		resolution, err := resolver.Resolve(conflict)
+		if err != nil {
+			syncutil.Error(err, ". Conflict resolver failed for record", conflict.RecordID, "leaving it unresolved")
+		} else if resolution.Resolved {
+			responseHash, recordData, err := processor.applyResolution(conflict, localRecord, resolution, transactionBindID, fieldDefinitions)
+			if err != nil {
+				return recordLevelOutcome{}, err
+			}
+			return recordLevelOutcome{
+				syncState:    resolvedState,
+				responseHash: responseHash,
+				recordData:   recordData,
+			}, nil
+		}
+	}
+	err := processor.markRecordConflict(conflict, localRecord, unresolvedOutcome.syncState, transactionBindID)
+	if err != nil {
+		return recordLevelOutcome{}, err
+	}
+	return unresolvedOutcome, nil
+}
+
+//applyResolution persists the outcome chosen by a conflict resolver and gives the resulting record hash and data.
+func (processor sqlMessageProcessor) applyResolution(conflict syncapi.RecordConflict, localRecord readInitialTransactionBindResult, resolution syncapi.ConflictResolution, transactionBindID string, fieldDefinitions map[string]syncdao.SyncFieldDefinition) (string, []byte, error) {
+	if resolution.IsDelete {
+		if localRecord.isDelete {
+			err := processor.markRecordReceived(localRecord, localRecord.recordHash, transactionBindID)
+			return localRecord.recordHash, nil, err
+		}
+		err := processor.deleteRecord(localRecord, conflict.Local.Record, transactionBindID, fieldDefinitions)
+		return localRecord.recordHash, nil, err
+	}
+	if resolution.Record == nil {
+		msg := "Conflict resolution for record '" + localRecord.recordID + "' of entity '" + localRecord.entitySingularName + "' keeps the record but does not supply it"
+		syncutil.Error(msg)
+		return "", nil, errors.New(msg)
+	}
+	return processor.applyRecord(localRecord, resolution.Record, transactionBindID, fieldDefinitions)
+}
+
+//findConflictResolver creates the ConflictResolver configured for the pair the session belongs to. The node level
+//SyncConflictUri of sync_pair_nodes takes precedence over the pair level one of sync_pair. A nil ConflictResolver is
+//given when there is no active pair for the session or neither uri names a resolver. A uri stored before it was
+//refused by syncapi.ValidatePair, such as the former 'builtin:lastWriterWins', leaves the conflicts for manual
+//resolution rather than failing the sync.
+func (processor sqlMessageProcessor) findConflictResolver() (syncapi.ConflictResolver, error) {
+	sqlStr := `
+SELECT        sync_pair.SyncConflictUri, sync_pair_nodes.SyncConflictUri
+FROM            sync_pair INNER JOIN
+                         sync_pair_nodes ON sync_pair.PairId = sync_pair_nodes.PairId
+WHERE        (sync_pair.SyncSessionId = $1 AND sync_pair_nodes.NodeId = $2);
+`
+	var pairConflictURI, nodeConflictURI string
+	err := processor.db.QueryRow(sqlStr, processor.SessionID, processor.NodeID).Scan(&pairConflictURI, &nodeConflictURI)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		syncutil.Error(err, ". Error finding conflict resolver for session", processor.SessionID)
+		return nil, err
+	}
+	conflictURI := pairConflictURI
+	if nodeConflictURI != "" && nodeConflictURI != syncapi.ConflictResolverURINone {
+		conflictURI = nodeConflictURI
+	}
+	resolver, err := syncapi.NewConflictResolver(conflictURI)
+	if err != nil {
+		syncutil.Error(err, ". Leaving the conflicts of session", processor.SessionID, "for manual resolution")
+		return nil, nil
+	}
+	return resolver, nil
+}
+
+//decodeStoredRecordData turns sync_state.RecordData, which holds the record bytes as hex, back into record bytes.
+func decodeStoredRecordData(recordData string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimSpace(recordData))
+}
+
+//hash256Bytes turns bytes into a sha256Hex string value
+func hash256Bytes(recordBytes []byte) string {
+	hasher := sha256.New()
+	hasher.Write(recordBytes)
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+//markRecordConflict records that the peer's version of the record was received but could not be applied and keeps
+//both versions in sync_conflict for manual resolution.
+func (processor sqlMessageProcessor) markRecordConflict(conflict syncapi.RecordConflict, localRecord readInitialTransactionBindResult, syncState syncmsg.AckSyncStateEnum, transactionBindID string) error {
+	tx, err := processor.db.Begin()
+	if err != nil {
+		syncutil.Error(err, ". Error starting transaction for record", localRecord.recordID)
+		return err
+	}
+	sqlStr := `
+update sync_peer_state set TransactionBindReceiveId=$1, IsConflict=true, LastUpdated=$2
+where (NodeId=$3 AND EntitySingularName=$4 AND RecordId=$5);`
+	_, err = tx.Exec(sqlStr, transactionBindID, time.Now(), processor.NodeID, localRecord.entitySingularName, localRecord.recordID)
+	if err != nil {
+		syncutil.Error(err, ". Error marking conflict for record", localRecord.recordID)
+		rollbackQuietly(tx)
+		return err
+	}
+	err = saveConflict(tx, processor.dialect, processor.NodeID, conflict, syncState)
+	if err != nil {
+		rollbackQuietly(tx)
+		return err
+	}
+	return tx.Commit()
+}
+
+//markRecordReceived records that the peer's version of the record was received and that the peer now knows the
+//record by peerLastKnownHash.
+func (processor sqlMessageProcessor) markRecordReceived(localRecord readInitialTransactionBindResult, peerLastKnownHash string, transactionBindID string) error {
+	sqlStr := `
+update sync_peer_state set TransactionBindReceiveId=$1, PeerLastKnownHash=$2, IsConflict=false, LastUpdated=$3
+where (NodeId=$4 AND EntitySingularName=$5 AND RecordId=$6);`
+	_, err := processor.db.Exec(sqlStr, transactionBindID, peerLastKnownHash, time.Now(), processor.NodeID, localRecord.entitySingularName, localRecord.recordID)
+	if err != nil {
+		syncutil.Error(err, ". Error marking record received for record", localRecord.recordID)
+		return err
+	}
+	return nil
+}
+
+//applyRecord persists record to the custom table, sync_state and sync_peer_state in one transaction and gives the
+//new record hash and data.
+func (processor sqlMessageProcessor) applyRecord(localRecord readInitialTransactionBindResult, record *syncmsg.ProtoRecord, transactionBindID string, fieldDefinitions map[string]syncdao.SyncFieldDefinition) (string, []byte, error) {
+	recordBytes, err := proto.Marshal(record)
+	if err != nil {
+		syncutil.Error(err, ". Error marshaling record", localRecord.recordID)
+		return "", nil, err
+	}
+	recordHash := hash256Bytes(recordBytes)
+	if recordHash == localRecord.recordHash && !localRecord.isDelete {
+		err = processor.markRecordReceived(localRecord, recordHash, transactionBindID)
+		return recordHash, recordBytes, err
+	}
+
+	tx, err := processor.db.Begin()
+	if err != nil {
+		syncutil.Error(err, ". Error starting transaction for record", localRecord.recordID)
+		return "", nil, err
+	}
+	err = writeRecord(tx, processor.dialect, localRecord, record, recordHash, recordBytes, fieldDefinitions)
+	if err != nil {
+		rollbackQuietly(tx)
+		return "", nil, err
+	}
+	sqlStr := `
+update sync_peer_state set TransactionBindReceiveId=$1, PeerLastKnownHash=$2, IsConflict=false, IsDelete=false, LastUpdated=$3
+where (NodeId=$4 AND EntitySingularName=$5 AND RecordId=$6);`
+	_, err = tx.Exec(sqlStr, transactionBindID, recordHash, time.Now(), processor.NodeID, localRecord.entitySingularName, localRecord.recordID)
+	if err != nil {
+		syncutil.Error(err, ". Error updating sync_peer_state for record", localRecord.recordID)
+		rollbackQuietly(tx)
+		return "", nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		syncutil.Error(err, ". Error committing record", localRecord.recordID)
+		return "", nil, err
+	}
+	return recordHash, recordBytes, nil
+}
+
+//deleteRecord removes the record from the custom table and marks it deleted in sync_state and sync_peer_state in one
+//transaction. The primary key values are taken from localProtoRecord.
+func (processor sqlMessageProcessor) deleteRecord(localRecord readInitialTransactionBindResult, localProtoRecord *syncmsg.ProtoRecord, transactionBindID string, fieldDefinitions map[string]syncdao.SyncFieldDefinition) error {
+	tx, err := processor.db.Begin()
+	if err != nil {
+		syncutil.Error(err, ". Error starting transaction for record", localRecord.recordID)
+		return err
+	}
+	err = eraseRecord(tx, processor.dialect, localRecord, localProtoRecord, fieldDefinitions)
+	if err != nil {
+		rollbackQuietly(tx)
+		return err
+	}
+	sqlStr := `
+update sync_peer_state set TransactionBindReceiveId=$1, PeerLastKnownHash=$2, IsConflict=false, IsDelete=true, LastUpdated=$3
+where (NodeId=$4 AND EntitySingularName=$5 AND RecordId=$6);`
+	_, err = tx.Exec(sqlStr, transactionBindID, localRecord.recordHash, time.Now(), processor.NodeID, localRecord.entitySingularName, localRecord.recordID)
+	if err != nil {
+		syncutil.Error(err, ". Error updating sync_peer_state for record", localRecord.recordID)
+		rollbackQuietly(tx)
+		return err
+	}
+	return tx.Commit()
+}
+
+//writeRecord persists record to the custom table and sync_state within tx. The sync_state update is guarded by the
+//local hash read earlier so a concurrent local change is not overwritten. The custom table row is inserted when it no
+//longer exists (e.g. it was deleted locally).
+func writeRecord(tx *sql.Tx, dialect Dialect, localRecord readInitialTransactionBindResult, record *syncmsg.ProtoRecord, recordHash string, recordBytes []byte, fieldDefinitions map[string]syncdao.SyncFieldDefinition) error {
+	updateSQL, updateArgs, err := createCustomTableUpdateSQL(dialect, localRecord.entityPluralName, localRecord.entitySingularName, record, fieldDefinitions, nil)
+	if err != nil {
+		syncutil.Error(err)
+		return err
+	}
+	result, err := tx.Exec(updateSQL, updateArgs...)
+	if err != nil {
+		syncutil.Error(err, ". Error updating custom table for record", localRecord.recordID)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		syncutil.Error(err, ". Error reading custom table update for record", localRecord.recordID)
+		return err
+	}
+	if rowsAffected == 0 {
+		insertSQL, insertArgs, err := createCustomTableInsertSQL(dialect, localRecord.entityPluralName, localRecord.entitySingularName, record, fieldDefinitions)
+		if err != nil {
+			syncutil.Error(err)
+			return err
+		}
+		_, err = tx.Exec(insertSQL, insertArgs...)
+		if err != nil {
+			syncutil.Error(err, ". Error inserting into custom table for record", localRecord.recordID)
+			return err
+		}
+	}
+
+	sqlStr := `
+update sync_state set RecordHash=$1, RecordData=$2, RecordBytesSize=$3, IsDelete=false, DeletedDate=null
+where (EntitySingularName=$4 AND RecordId=$5 AND RecordHash=$6);`
+	result, err = tx.Exec(sqlStr, recordHash, hex.EncodeToString(recordBytes), len(recordBytes), localRecord.entitySingularName, localRecord.recordID, localRecord.recordHash)
+	if err != nil {
+		syncutil.Error(err, ". Error updating sync_state for record", localRecord.recordID)
+		return err
+	}
+	return checkOneRowAffected(result, localRecord)
+}
+
+//eraseRecord removes the record from the custom table and marks it deleted in sync_state within tx. The primary key
+//values are taken from localProtoRecord.
+func eraseRecord(tx *sql.Tx, dialect Dialect, localRecord readInitialTransactionBindResult, localProtoRecord *syncmsg.ProtoRecord, fieldDefinitions map[string]syncdao.SyncFieldDefinition) error {
+	deleteSQL, deleteArgs, err := createCustomTableDeleteSQL(dialect, localRecord.entityPluralName, localRecord.entitySingularName, localProtoRecord, fieldDefinitions, nil)
+	if err != nil {
+		syncutil.Error(err)
+		return err
+	}
+	_, err = tx.Exec(deleteSQL, deleteArgs...)
+	if err != nil {
+		syncutil.Error(err, ". Error deleting from custom table for record", localRecord.recordID)
+		return err
+	}
+	sqlStr := `
+update sync_state set IsDelete=true, DeletedDate=` + dialect.Now() + ` where (EntitySingularName=$1 AND RecordId=$2 AND RecordHash=$3);`
+	result, err := tx.Exec(sqlStr, localRecord.entitySingularName, localRecord.recordID, localRecord.recordHash)
+	if err != nil {
+		syncutil.Error(err, ". Error updating sync_state for record", localRecord.recordID)
+		return err
+	}
+	return checkOneRowAffected(result, localRecord)
+}
+
+func checkOneRowAffected(result sql.Result, localRecord readInitialTransactionBindResult) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		syncutil.Error(err, ". Error reading sync_state update for record", localRecord.recordID)
+		return err
+	}
+	if rowsAffected != 1 {
+		msg := "Record '" + localRecord.recordID + "' of entity '" + localRecord.entitySingularName + "' changed while being processed"
+		syncutil.Error(msg)
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func rollbackQuietly(tx *sql.Tx) {
+	err := tx.Rollback()
+	if err != nil {
+		syncutil.Error("Quietly handling of rollback error. Error: " + err.Error())
+	}
+}
+
+//createCustomTableUpdateSQL creates a parameterized update of the entity's own table setting every non key field of
+//record and selecting the row by its primary key fields. When lock is not nil the row is only updated while the lock
+//holds.
+func createCustomTableUpdateSQL(dialect Dialect, entityPluralName string, entitySingularName string, record *syncmsg.ProtoRecord, fieldDefinitions map[string]syncdao.SyncFieldDefinition, lock *recordLock) (string, []interface{}, error) {
+	setFields := []*syncmsg.ProtoField{}
+	keyFields := []*syncmsg.ProtoField{}
+	for _, field := range record.Fields {
+		if fieldDefinitions[field.GetFieldName()].IsPrimaryKey {
+			keyFields = append(keyFields, field)
+		} else {
+			setFields = append(setFields, field)
+		}
+	}
+	if len(keyFields) == 0 {
+		return "", nil, errors.New("record of entity " + entitySingularName + " does not contain its primary key fields")
+	}
+	if len(setFields) == 0 {
+		return "", nil, errors.New("record of entity " + entitySingularName + " does not contain any non key fields")
+	}
+	args := sqlArgs{}
+	setClauses, err := createFieldClauses(dialect, setFields, fieldDefinitions, entitySingularName, &args)
+	if err != nil {
+		return "", nil, err
+	}
+	whereClauses, err := createFieldClauses(dialect, keyFields, fieldDefinitions, entitySingularName, &args)
+	if err != nil {
+		return "", nil, err
+	}
+	if lock != nil {
+		whereClauses = append(whereClauses, lock.where(&args))
+	}
+	sqlStr := "update " + dialect.QuoteIdentifier(entityPluralName) + " set " + strings.Join(setClauses, ", ") + " where (" + strings.Join(whereClauses, " AND ") + ");"
+	return sqlStr, args, nil
+}
+
+//createCustomTableInsertSQL creates a parameterized insert of every field of record into the entity's own table.
+func createCustomTableInsertSQL(dialect Dialect, entityPluralName string, entitySingularName string, record *syncmsg.ProtoRecord, fieldDefinitions map[string]syncdao.SyncFieldDefinition) (string, []interface{}, error) {
+	fieldNames := []string{}
+	placeholders := []string{}
+	args := sqlArgs{}
+	for _, field := range record.Fields {
+		value, err := calculateSQLArg(*field, fieldDefinitions, entitySingularName)
+		if err != nil {
+			return "", nil, err
+		}
+		fieldNames = append(fieldNames, dialect.QuoteIdentifier(fieldDefinitions[field.GetFieldName()].FieldName))
+		placeholders = append(placeholders, args.add(value))
+	}
+	if len(fieldNames) == 0 {
+		return "", nil, errors.New("record of entity " + entitySingularName + " does not contain any fields")
+	}
+	sqlStr := "insert into " + dialect.QuoteIdentifier(entityPluralName) + " (" + strings.Join(fieldNames, ", ") + ") values (" + strings.Join(placeholders, ", ") + ");"
+	return sqlStr, args, nil
+}
+
+//createCustomTableDeleteSQL creates a parameterized delete from the entity's own table selecting the row by the
+//primary key fields of record. When lock is not nil the row is only deleted while the lock holds.
+func createCustomTableDeleteSQL(dialect Dialect, entityPluralName string, entitySingularName string, record *syncmsg.ProtoRecord, fieldDefinitions map[string]syncdao.SyncFieldDefinition, lock *recordLock) (string, []interface{}, error) {
+	keyFields := []*syncmsg.ProtoField{}
+	for _, field := range record.Fields {
+		if fieldDefinitions[field.GetFieldName()].IsPrimaryKey {
+			keyFields = append(keyFields, field)
+		}
+	}
+	if len(keyFields) == 0 {
+		return "", nil, errors.New("record of entity " + entitySingularName + " does not contain its primary key fields")
+	}
+	args := sqlArgs{}
+	whereClauses, err := createFieldClauses(dialect, keyFields, fieldDefinitions, entitySingularName, &args)
+	if err != nil {
+		return "", nil, err
+	}
+	if lock != nil {
+		whereClauses = append(whereClauses, lock.where(&args))
+	}
+	sqlStr := "delete from " + dialect.QuoteIdentifier(entityPluralName) + " where (" + strings.Join(whereClauses, " AND ") + ");"
+	return sqlStr, args, nil
+}
+
+//createFieldClauses gives a 'field=$n' clause for each of fields, binding the field values to args. The column name
+//is taken from the field definition found in sync_data_field, so fields unknown to the entity are rejected.
+func createFieldClauses(dialect Dialect, fields []*syncmsg.ProtoField, fieldDefinitions map[string]syncdao.SyncFieldDefinition, entitySingularName string, args *sqlArgs) ([]string, error) {
+	answer := []string{}
+	for _, field := range fields {
+		value, err := calculateSQLArg(*field, fieldDefinitions, entitySingularName)
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, dialect.QuoteIdentifier(fieldDefinitions[field.GetFieldName()].FieldName)+"="+args.add(value))
+	}
+	return answer, nil
+}
+
+//calculateSQLArg gives the value of field as a query argument, rejecting fields unknown to the entity.
+func calculateSQLArg(field syncmsg.ProtoField, fieldDefinitions map[string]syncdao.SyncFieldDefinition, syncEntityName string) (interface{}, error) {
+	fieldName := field.GetFieldName()
+	fieldDefinition := fieldDefinitions[fieldName]
+	if fieldDefinition.FieldType == syncdao.SyncFieldTypeEnumUndefined {
+		return nil, errors.New("field " + fieldName + " does  not match a known field definition for entity " + syncEntityName)
+	}
+	value := syncdao.CalculateValue(field.GetEncodedFieldType(), field.FieldValue)
+	if fieldDefinition.FieldType != syncdao.SyncFieldTypeEnumDate {
+		return value, nil
+	}
+	creator := syncmsg.NewCreator()
+	theTime := creator.FormatTimeFromString(fmt.Sprintf("%v", value))
+	if len(creator.Errors) != 0 {
+		err := syncmsg.NewCreatorError(creator.Errors)
+		syncutil.Error(err.Error())
+		return nil, err
+	}
+	return theTime, nil
+}
@@ -1,4 +1,4 @@
-package syncdaopq
+package syncdaosql
 
 import (
 	"database/sql"
@@ -6,47 +6,30 @@ import (
 	"data-sync-tools-go/syncutil"
 )
 
-func newMessageQueuer(db *sql.DB) (syncapi.MessageQueuing, error) {
+func newMessageQueuer(db *sql.DB, dialect Dialect) (syncapi.MessageQueuing, error) {
 
-	queuer := postgresSQLMessageQueuer{
-		db: db,
+	queuer := sqlMessageQueuer{
+		db:      db,
+		dialect: dialect,
 	}
 	//syncutil.Info(fetcher)
 	return queuer, nil
 }
 
-//postgresSQLMessageQueuer logically implements MessageQueuing for PostgressSQL database.
-type postgresSQLMessageQueuer struct {
-	db *sql.DB
+//sqlMessageQueuer logically implements MessageQueuing for the sql databases.
+type sqlMessageQueuer struct {
+	db      *sql.DB
+	dialect Dialect
 }
 
-//Queue implements the syncapi.MessageQueuing interface as a postgressql implementation.
-func (queuer postgresSQLMessageQueuer) Queue(sessionID string, nodeIDToQueue string) (int, error) {
-	// TODO(doug4j@gmail.com): Add SQL injection checks (see http://go-database-sql.org/retrieving.html and http://stackoverflow.com/questions/26345318/how-can-i-prevent-sql-injection-attacks-in-go-while-using-database-sql
-	//Injection Checks are particularly important due to the need for a dynamically set fixed field in the SQL for the
-	// Node Id.
-
+//Queue implements the syncapi.MessageQueuing interface as a sql implementation.
+func (queuer sqlMessageQueuer) Queue(sessionID string, nodeIDToQueue string) (int, error) {
 	var sqlStr string
 	//For queuing previously unqueued records
-	/*
-	   sqlStr = `
-	   insert into sync_peer_state (NodeId, EntitySingularName, RecordId, SentLastKnownHash, ChangedByClient)
-	   select '` + nodeIDToQueue
-	   sqlStr = sqlStr + `' NodeId, EntitySingularName, RecordId, RecordHash, '1' ChangedByClient from sync_state where
-	   					RecordId not in (
-	   SELECT        sync_peer_state.RecordId
-	   FROM            sync_peer_state INNER JOIN
-	   											 sync_state ON sync_peer_state.EntitySingularName = sync_state.EntitySingularName AND
-	   											 sync_peer_state.RecordId = sync_state.RecordId
-	   WHERE        (sync_peer_state.NodeId = $1)
-	   );
-	   `
-	*/
 	sqlStr = `
 insert into sync_peer_state (NodeId, EntitySingularName, RecordId, SentLastKnownHash, ChangedByClient, RecordBytesSize)
-select '` + nodeIDToQueue
-	sqlStr = sqlStr + `', EntitySingularName, RecordId, RecordHash, '1', RecordBytesSize from sync_state where
-						RecordId not in (
+select ` + queuer.dialect.Cast("$1", ColumnTypeVarchar) + `, EntitySingularName, RecordId, RecordHash, '1', RecordBytesSize from sync_state where
+						IsDelete = false AND RecordId not in (
 SELECT        sync_peer_state.RecordId
 FROM            sync_peer_state INNER JOIN
                          sync_state ON sync_peer_state.EntitySingularName = sync_state.EntitySingularName AND
@@ -57,7 +40,31 @@ WHERE        (sync_peer_state.NodeId = $1)
 	//syncutil.Debug("sqlStr:", sqlStr)
 	_, err := queuer.db.Exec(sqlStr, nodeIDToQueue)
 	if err != nil {
-		syncutil.Error(err, ". Error inserting with nodeIdToQueue:", nodeIDToQueue, "sql:", sqlStr)
+		syncutil.Error(err, ". Error inserting with nodeIdToQueue:", nodeIDToQueue)
+		return 0, err
+	}
+	//Records deleted by the application do not stamp DeletedDate, so the first queue after the delete does
+	_, err = queuer.db.Exec("update sync_state set DeletedDate = " + queuer.dialect.Now() + " where IsDelete = true AND DeletedDate is null;")
+	if err != nil {
+		syncutil.Error(err, ". Error stamping deleted records with nodeIdToQueue:", nodeIDToQueue)
+		return 0, err
+	}
+	sqlStr = `
+--For Queuing, how to mark deletes of previously queued records. A peer never sent the record has nothing to delete.
+update sync_peer_state set IsDelete = true, ChangedByClient = (sync_peer_state.SentSyncState <> 1)
+from sync_state where
+	sync_peer_state.EntitySingularName = sync_state.EntitySingularName
+and
+	sync_peer_state.RecordId = sync_state.RecordId
+and
+	sync_state.IsDelete = true
+and
+	sync_peer_state.IsDelete = false
+and
+	sync_peer_state.NodeId = $1;`
+	_, err = queuer.db.Exec(sqlStr, nodeIDToQueue)
+	if err != nil {
+		syncutil.Error(err, ". Error queuing deletes with nodeIdToQueue:", nodeIDToQueue)
 		return 0, err
 	}
 	sqlStr = `
@@ -70,7 +77,7 @@ update sync_peer_state set ChangedByClient = '1' where
 				NodeId = $1
 		and
 			RecordId in (
-				SELECT RecordId from sync_peer_state 	where SentLastKnownHash not in	(
+				SELECT RecordId from sync_peer_state 	where NodeId = $1 and SentLastKnownHash not in	(
 				SELECT  SentLastKnownHash
 				FROM            sync_peer_state INNER JOIN
 								 sync_state ON sync_peer_state.EntitySingularName = sync_state.EntitySingularName AND
@@ -79,7 +86,7 @@ update sync_peer_state set ChangedByClient = '1' where
 			)
 		and
 			EntitySingularName in (
-				SELECT EntitySingularName from sync_peer_state 	where SentLastKnownHash not in	(
+				SELECT EntitySingularName from sync_peer_state 	where NodeId = $1 and SentLastKnownHash not in	(
 				SELECT  SentLastKnownHash
 				FROM            sync_peer_state INNER JOIN
 								 sync_state ON sync_peer_state.EntitySingularName = sync_state.EntitySingularName AND
@@ -95,7 +102,7 @@ and
 				NodeId = $1
 		and
 			RecordId in (
-				SELECT RecordId from sync_peer_state 	where SentLastKnownHash not in	(
+				SELECT RecordId from sync_peer_state 	where NodeId = $1 and SentLastKnownHash not in	(
 				SELECT  SentLastKnownHash
 				FROM            sync_peer_state INNER JOIN
 								 sync_state ON sync_peer_state.EntitySingularName = sync_state.EntitySingularName AND
@@ -104,7 +111,7 @@ and
 			)
 		and
 			EntitySingularName in (
-				SELECT EntitySingularName from sync_peer_state 	where SentLastKnownHash not in	(
+				SELECT EntitySingularName from sync_peer_state 	where NodeId = $1 and SentLastKnownHash not in	(
 				SELECT  SentLastKnownHash
 				FROM            sync_peer_state INNER JOIN
 								 sync_state ON sync_peer_state.EntitySingularName = sync_state.EntitySingularName AND
@@ -127,7 +134,10 @@ and
 		syncutil.Error(err, ". Error updating with nodeIdToQueue:", nodeIDToQueue)
 		return 0, err
 	}
-
+	err = setQueuedTotals(queuer.db, sessionID, nodeIDToQueue)
+	if err != nil {
+		return 0, err
+	}
 	sqlStr = "select count(*) from sync_peer_state where NodeId=$1 and ChangedByClient='1';"
 	rows, err := queuer.db.Query(sqlStr, nodeIDToQueue)
 	if err != nil {
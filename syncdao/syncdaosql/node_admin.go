@@ -0,0 +1,69 @@
+package syncdaosql
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"database/sql"
+)
+
+//NewNodeAdminRepository provides sql database access for a NodeAdminRepository.
+func NewNodeAdminRepository(db *sql.DB) syncapi.NodeAdminRepositoryable {
+	return nodeAdminRepositoryType{
+		db: db,
+	}
+}
+
+type nodeAdminRepositoryType struct {
+	db *sql.DB
+}
+
+//sqlCountPendingChanges counts the records of every entity the way Queue finds them changed for the node: records
+//never queued for it, changed since last sent or deleted since last sent. A record never sent has nothing to delete.
+const sqlCountPendingChanges = `
+select sync_data_entity.EntityPluralName,
+sum(case when sync_state.IsDelete = false AND (sync_peer_state.NodeId is null OR sync_peer_state.ChangedByClient = true OR
+	sync_peer_state.SentLastKnownHash <> sync_state.RecordHash) then 1 else 0 end),
+sum(case when sync_state.IsDelete = true AND ((sync_peer_state.IsDelete = true AND sync_peer_state.ChangedByClient = true) OR
+	(sync_peer_state.IsDelete = false AND sync_peer_state.SentSyncState <> 1)) then 1 else 0 end),
+sum(case when sync_peer_state.TransactionBindSendId is not null OR sync_peer_state.QueueBindSendId is not null then 1 else 0 end)
+from sync_state INNER JOIN
+	sync_data_entity ON sync_state.EntitySingularName = sync_data_entity.EntitySingularName LEFT OUTER JOIN
+	sync_peer_state ON sync_peer_state.NodeId = $1 AND sync_peer_state.EntitySingularName = sync_state.EntitySingularName AND
+	sync_peer_state.RecordId = sync_state.RecordId
+group by sync_data_entity.EntityPluralName order by sync_data_entity.EntityPluralName;`
+
+//PendingChanges counts the pending changes of the node in a single query, without locking anything.
+func (nodeAdminRepository nodeAdminRepositoryType) PendingChanges(nodeID string) (syncapi.NodePendingChanges, error) {
+	answer := syncapi.NodePendingChanges{NodeID: nodeID, Entities: []syncapi.PendingChangeCount{}}
+	var nodeCount int
+	err := nodeAdminRepository.db.QueryRow("select count(*) from sync_node where NodeId=$1;", nodeID).Scan(&nodeCount)
+	if err != nil {
+		syncutil.Error(err, ". Error finding node", nodeID)
+		return answer, err
+	}
+	if nodeCount == 0 {
+		return answer, syncapi.ErrNodeNotFound
+	}
+	rows, err := nodeAdminRepository.db.Query(sqlCountPendingChanges, nodeID)
+	if err != nil {
+		syncutil.Error(err, ". Error counting pending changes of node", nodeID)
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		var count syncapi.PendingChangeCount
+		err = rows.Scan(&count.EntityPluralName, &count.AddedOrUpdated, &count.Deleted, &count.InFlight)
+		if err != nil {
+			syncutil.Error(err, ". Error reading pending changes of node", nodeID)
+			return answer, err
+		}
+		answer.Add(count)
+	}
+	return answer, rows.Err()
+}
@@ -0,0 +1,299 @@
+package syncdaosql
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncutil"
+	"database/sql"
+)
+
+const sqlSelectPair = `
+select PairId, PairName, MaxSesDurValue, MaxSesDurUnit, SyncDataTransForm, SyncMsgTransForm, SyncMsgSecPol, SyncSessionId,
+SyncSessionState, SyncSessionStart, SyncSessionHeartbeat, SyncConflictUri, RecordCreated from sync_pair`
+
+const sqlSelectPairNodes = `
+select PairId, NodeId, TargetNodeId, SeededDataVersion, SyncConflictUri from sync_pair_nodes where PairId=$1
+order by NodeId, TargetNodeId;`
+
+//queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+//execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func scanPair(row rowScanner) (syncdao.SyncPair, error) {
+	var (
+		pair                            syncdao.SyncPair
+		sessionID                       sql.NullString
+		sessionStart, heartbeat, create sql.NullTime
+	)
+	err := row.Scan(&pair.PairID, &pair.PairName, &pair.MaxSesDurValue, &pair.MaxSesDurUnit, &pair.SyncDataTransForm,
+		&pair.SyncMsgTransForm, &pair.SyncMsgSecPol, &sessionID, &pair.SyncSessionState, &sessionStart, &heartbeat,
+		&pair.SyncConflictURI, &create)
+	if err != nil {
+		return pair, err
+	}
+	pair.SyncSessionID = sessionID.String
+	if sessionStart.Valid {
+		pair.SyncSessionStart = localTime(sessionStart.Time)
+	}
+	if heartbeat.Valid {
+		pair.SyncSessionHeartbeat = localTime(heartbeat.Time)
+	}
+	if create.Valid {
+		pair.RecordCreated = localTime(create.Time)
+	}
+	return pair, nil
+}
+
+//queryPairNodes gives the sync_pair_nodes rows of the pair ordered by NodeId.
+func queryPairNodes(db queryer, pairID string) ([]syncdao.PairNode, error) {
+	answer := []syncdao.PairNode{}
+	rows, err := db.Query(sqlSelectPairNodes, pairID)
+	if err != nil {
+		syncutil.Error(err, ". Error finding the nodes of pair", pairID)
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		var (
+			node              syncdao.PairNode
+			seededDataVersion sql.NullString
+		)
+		err = rows.Scan(&node.PairID, &node.NodeID, &node.TargetNodeID, &seededDataVersion, &node.SyncConflictURI)
+		if err != nil {
+			syncutil.Error(err, ". Error reading the nodes of pair", pairID)
+			return answer, err
+		}
+		node.SeededDataVersion = seededDataVersion.String
+		answer = append(answer, node)
+	}
+	return answer, rows.Err()
+}
+
+//lockInactivePair locks the sync_pair row of pairID within tx, so that no session starts until tx ends, and checks
+//that the pair has no active session. A dialect without row locks relies on tx holding the write lock instead.
+func lockInactivePair(tx *sql.Tx, dialect Dialect, pairID string) error {
+	var state string
+	err := tx.QueryRow("select SyncSessionState from sync_pair where PairId=$1"+dialect.ForUpdate()+";", pairID).Scan(&state)
+	if err == sql.ErrNoRows {
+		return syncdao.ErrDaoNoDataFound
+	}
+	if err != nil {
+		syncutil.Error(err, ". Error finding pair", pairID)
+		return err
+	}
+	if state != syncapi.SyncSessionStateInactive {
+		return syncapi.ErrPairSessionActive
+	}
+	return nil
+}
+
+//checkPairNodeRefs checks that the nodes and the seeded data version of node exist.
+func checkPairNodeRefs(db queryer, node syncdao.PairNode) error {
+	var count int
+	for _, id := range []string{node.NodeID, node.TargetNodeID} {
+		err := db.QueryRow("select count(*) from sync_node where NodeId=$1;", id).Scan(&count)
+		if err != nil {
+			syncutil.Error(err, ". Error finding node", id)
+			return err
+		}
+		if count == 0 {
+			return syncapi.ValidationError{Msg: "Cannot find node '" + id + "'"}
+		}
+	}
+	if node.SeededDataVersion == "" {
+		return nil
+	}
+	err := db.QueryRow("select count(*) from sync_data_version where DataVersionName=$1;", node.SeededDataVersion).Scan(&count)
+	if err != nil {
+		syncutil.Error(err, ". Error finding data version", node.SeededDataVersion)
+		return err
+	}
+	if count == 0 {
+		return syncapi.ValidationError{Msg: "Cannot find data version '" + node.SeededDataVersion + "'"}
+	}
+	return nil
+}
+
+//GetPair implements the syncdao.SyncPairDao.GetPair interface via a sql database.
+func (dao SyncPairSQLDao) GetPair(pairID string) (syncdao.SyncPair, error) {
+	pair, err := scanPair(dao.db.QueryRow(sqlSelectPair+" where PairId=$1;", pairID))
+	if err == sql.ErrNoRows {
+		return pair, syncdao.ErrDaoNoDataFound
+	}
+	if err != nil {
+		syncutil.Error(err, ". Error reading pair", pairID)
+		return pair, err
+	}
+	return pair, nil
+}
+
+//ListPairs implements the syncdao.SyncPairDao.ListPairs interface via a sql database.
+func (dao SyncPairSQLDao) ListPairs() ([]syncdao.SyncPair, error) {
+	answer := []syncdao.SyncPair{}
+	rows, err := dao.db.Query(sqlSelectPair + " order by PairName, PairId;")
+	if err != nil {
+		syncutil.Error(err, ". Error listing pairs")
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		pair, err := scanPair(rows)
+		if err != nil {
+			syncutil.Error(err, ". Error reading pairs")
+			return answer, err
+		}
+		answer = append(answer, pair)
+	}
+	return answer, rows.Err()
+}
+
+//UpdatePair implements the syncdao.SyncPairDao.UpdatePair interface via a sql database. The pair is only
+//updated while inactive, in a single statement, and is found again to tell why when it was not.
+func (dao SyncPairSQLDao) UpdatePair(pair syncdao.SyncPair) error {
+	pair = syncapi.PairWithDefaults(pair)
+	sqlStr := `
+update sync_pair set PairName=$2, MaxSesDurValue=$3, MaxSesDurUnit=$4, SyncDataTransForm=$5, SyncMsgTransForm=$6,
+SyncMsgSecPol=$7, SyncConflictUri=$8 where PairId=$1 AND SyncSessionState='Inactive';`
+	result, err := dao.db.Exec(sqlStr, pair.PairID, pair.PairName, pair.MaxSesDurValue, pair.MaxSesDurUnit,
+		pair.SyncDataTransForm, pair.SyncMsgTransForm, pair.SyncMsgSecPol, pair.SyncConflictURI)
+	if err != nil {
+		syncutil.Error(err, ". Error updating pair, inputData=", pair)
+		return err
+	}
+	affectedCount, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedCount > 0 {
+		return nil
+	}
+	var count int
+	err = dao.db.QueryRow("select count(*) from sync_pair where PairId=$1;", pair.PairID).Scan(&count)
+	if err != nil {
+		syncutil.Error(err, ". Error finding pair", pair.PairID)
+		return err
+	}
+	if count == 0 {
+		return syncdao.ErrDaoNoDataFound
+	}
+	return syncapi.ErrPairSessionActive
+}
+
+//DeletePair implements the syncdao.SyncPairDao.DeletePair interface via a sql database.
+func (dao SyncPairSQLDao) DeletePair(pairID string) error {
+	tx, err := dao.db.Begin()
+	if err != nil {
+		return err
+	}
+	err = lockInactivePair(tx, dao.dialect, pairID)
+	if err != nil {
+		rollbackQuietly(tx)
+		return err
+	}
+	for _, table := range []string{"sync_pair_nodes", "sync_session_mgmt_msg", "sync_session_history", "sync_pair"} {
+		_, err = tx.Exec("delete from "+table+" where PairId=$1;", pairID)
+		if err != nil {
+			syncutil.Error(err, ". Error deleting pair", pairID, "from", table)
+			rollbackQuietly(tx)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+//ListPairNodes implements the syncdao.SyncPairDao.ListPairNodes interface via a sql database.
+func (dao SyncPairSQLDao) ListPairNodes(pairID string) ([]syncdao.PairNode, error) {
+	var count int
+	err := dao.db.QueryRow("select count(*) from sync_pair where PairId=$1;", pairID).Scan(&count)
+	if err != nil {
+		syncutil.Error(err, ". Error finding pair", pairID)
+		return []syncdao.PairNode{}, err
+	}
+	if count == 0 {
+		return []syncdao.PairNode{}, syncdao.ErrDaoNoDataFound
+	}
+	return queryPairNodes(dao.db, pairID)
+}
+
+//UpdatePairNode implements the syncdao.SyncPairDao.UpdatePairNode interface via a sql database.
+func (dao SyncPairSQLDao) UpdatePairNode(node syncdao.PairNode) error {
+	tx, err := dao.db.Begin()
+	if err != nil {
+		return err
+	}
+	err = lockInactivePair(tx, dao.dialect, node.PairID)
+	if err == nil {
+		err = checkPairNodeRefs(tx, node)
+	}
+	if err != nil {
+		rollbackQuietly(tx)
+		return err
+	}
+	sqlStr := `
+update sync_pair_nodes set SeededDataVersion=nullif($4, ''), SyncConflictUri=$5
+where PairId=$1 AND NodeId=$2 AND TargetNodeId=$3;`
+	result, err := tx.Exec(sqlStr, node.PairID, node.NodeID, node.TargetNodeID, node.SeededDataVersion,
+		node.SyncConflictURI)
+	if err != nil {
+		syncutil.Error(err, ". Error updating node", node.NodeID, "of pair", node.PairID)
+		rollbackQuietly(tx)
+		return err
+	}
+	affectedCount, err := result.RowsAffected()
+	if err != nil || affectedCount == 0 {
+		rollbackQuietly(tx)
+		if err == nil {
+			err = syncdao.ErrDaoNoDataFound
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+//DeletePairNode implements the syncdao.SyncPairDao.DeletePairNode interface via a sql database.
+func (dao SyncPairSQLDao) DeletePairNode(pairID string, nodeID string, targetNodeID string) error {
+	tx, err := dao.db.Begin()
+	if err != nil {
+		return err
+	}
+	err = lockInactivePair(tx, dao.dialect, pairID)
+	if err != nil {
+		rollbackQuietly(tx)
+		return err
+	}
+	result, err := tx.Exec("delete from sync_pair_nodes where PairId=$1 AND NodeId=$2 AND TargetNodeId=$3;", pairID,
+		nodeID, targetNodeID)
+	if err != nil {
+		syncutil.Error(err, ". Error deleting node", nodeID, "of pair", pairID)
+		rollbackQuietly(tx)
+		return err
+	}
+	affectedCount, err := result.RowsAffected()
+	if err != nil || affectedCount == 0 {
+		rollbackQuietly(tx)
+		if err == nil {
+			err = syncdao.ErrDaoNoDataFound
+		}
+		return err
+	}
+	return tx.Commit()
+}
@@ -1,4 +1,4 @@
-package syncdaopq
+package syncdaosql
 
 import (
 	"database/sql"
@@ -6,19 +6,21 @@ import (
 	"data-sync-tools-go/syncutil"
 )
 
-//NewDataRepository provides postgressql database access for a DataRepository.
-func NewDataRepository(db *sql.DB) syncapi.DataRepositoryable {
+//NewDataRepository provides sql database access for a DataRepository.
+func NewDataRepository(db *sql.DB, dialect Dialect) syncapi.DataRepositoryable {
 	return dataRepositoryType{
-		db: db,
+		db:      db,
+		dialect: dialect,
 	}
 }
 
 type dataRepositoryType struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect Dialect
 }
 
-func (dataRepository dataRepositoryType) CreateMessageFetcher(sessionID string, nodeID string) (syncapi.MessageFetching, error) {
-	fetcher, err := newMessageFetcher(sessionID, nodeID, dataRepository.db)
+func (dataRepository dataRepositoryType) CreateMessageFetcher(sessionID string, nodeID string, limits syncapi.FetchLimits) (syncapi.MessageFetching, error) {
+	fetcher, err := newMessageFetcher(sessionID, nodeID, limits, dataRepository.db)
 	if err != nil {
 		syncutil.Error(err)
 		return fetcher, err
@@ -27,7 +29,7 @@ func (dataRepository dataRepositoryType) CreateMessageFetcher(sessionID string,
 }
 
 func (dataRepository dataRepositoryType) CreateMessageProcessor(sessionID string, nodeID string, entitiesByPluralName map[string]syncapi.EntityNameItem) (syncapi.MessageProcessing, error) {
-	processor, err := newMessageProcessor(sessionID, nodeID, entitiesByPluralName, dataRepository.db)
+	processor, err := newMessageProcessor(sessionID, nodeID, entitiesByPluralName, dataRepository.db, dataRepository.dialect)
 	if err != nil {
 		syncutil.Error(err)
 		return processor, err
@@ -36,10 +38,19 @@ func (dataRepository dataRepositoryType) CreateMessageProcessor(sessionID string
 }
 
 func (dataRepository dataRepositoryType) CreateMessageQueuer(sessionID string, nodeID string) (syncapi.MessageQueuing, error) {
-	return newMessageQueuer(dataRepository.db)
+	return newMessageQueuer(dataRepository.db, dataRepository.dialect)
+}
+
+func (dataRepository dataRepositoryType) CreateMessageAcknowledger(sessionID string, nodeID string) (syncapi.MessageAcknowledging, error) {
+	acknowledger, err := newMessageAcknowledger(sessionID, nodeID, dataRepository.db, dataRepository.dialect)
+	if err != nil {
+		syncutil.Error(err)
+		return acknowledger, err
+	}
+	return acknowledger, nil
 }
 
-//NewConfigRepository provides postgressql database access for ConfigurationRepository.
+//NewConfigRepository provides sql database access for ConfigurationRepository.
 func NewConfigRepository(db *sql.DB) syncapi.ConfigRepositoryable {
 	return configRepositoryType{
 		db: db,
@@ -0,0 +1,50 @@
+package syncdaosql
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"database/sql"
+	"time"
+)
+
+const sqlFindSessionToCancel = `
+select PairId, SyncSessionState, SyncSessionStart from sync_pair where SyncSessionId=$1 AND SyncSessionState <> 'Inactive';`
+
+//CancelSession rolls back and closes the active session sessionID within one transaction, recording it as ended with
+//syncapi.SessionEndReasonCanceled.
+func (sessionRepository sessionRepositoryType) CancelSession(sessionID string, reasonMsg string, now time.Time) (syncapi.SessionCancelResult, error) {
+	var answer syncapi.SessionCancelResult
+	//The session is only ended while still in the state it was found in, so it is found again when its client moves it
+	//on in the meantime.
+	for {
+		var sessionStart sql.NullTime
+		answer.Session = syncapi.EndedSession{
+			SessionID:  sessionID,
+			SessionEnd: now,
+			Reason:     syncapi.SessionEndReasonCanceled,
+			ReasonMsg:  reasonMsg,
+		}
+		err := sessionRepository.db.QueryRow(sqlFindSessionToCancel, sessionID).Scan(&answer.Session.PairID,
+			&answer.Session.State, &sessionStart)
+		if err == sql.ErrNoRows {
+			answer.Result = syncapi.SessionNotActiveResult
+			return answer, nil
+		}
+		if err != nil {
+			syncutil.Error(err, ". Error finding the pair of session", sessionID)
+			return answer, err
+		}
+		if sessionStart.Valid {
+			answer.Session.SessionStart = localTime(sessionStart.Time)
+		}
+		ended, discardedCount, err := sessionRepository.endSession(&answer.Session)
+		if err != nil {
+			return answer, err
+		}
+		if ended {
+			answer.DiscardedCount = discardedCount
+			answer.Result = "OK"
+			return answer, nil
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package syncdaosql
+
+import (
+	"database/sql"
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"time"
+)
+
+const (
+	sqlFindActiveSessionPair = `
+select PairId, SyncSessionState from sync_pair where SyncSessionId=$1 AND SyncSessionState <> 'Inactive';`
+
+	sqlInsertMgmtMsg = `
+insert into sync_session_mgmt_msg (PairId, SyncSessionId, SenderNodeId, MessageType, Result, RecordCreated)
+values ($1, $2, $3, $4, $5, $6) returning MsgSeq;`
+
+	sqlRenewSessionLease = `
+update sync_pair set SyncSessionHeartbeat=$3 where PairId=$1 AND SyncSessionId=$2;`
+
+	sqlCancelSession = `
+update sync_pair set SyncSessionState='Canceling' where PairId=$1 AND SyncSessionId=$2 AND SyncSessionState=$3;`
+
+	//Records stay bound to the session, so only the fetches in flight are rolled back and the records fetched again.
+	sqlReleaseSessionFetches = `
+update sync_peer_state set QueueBindSendId=null, TransactionBindSendId=null
+where SessionBindId=$1 AND (QueueBindSendId is not null OR TransactionBindSendId is not null);`
+
+	sqlPollMgmtMsgs = `
+select MsgSeq, SyncSessionId, SenderNodeId, MessageType, coalesce(Result, ''), RecordCreated from sync_session_mgmt_msg
+where SyncSessionId=$1 AND SenderNodeId <> $2 AND MsgSeq > $3 order by MsgSeq;`
+)
+
+//SendMgmtMsg keeps the message and acts on the session of the message within one transaction.
+func (sessionRepository sessionRepositoryType) SendMgmtMsg(msg syncapi.SessionMgmtMsg) (syncapi.SessionMgmtMsgResult, error) {
+	var answer syncapi.SessionMgmtMsgResult
+	tx, err := sessionRepository.db.Begin()
+	if err != nil {
+		syncutil.Error(err, ". Error starting transaction for management message of session", msg.SessionID)
+		return answer, err
+	}
+	err = tx.QueryRow(sqlFindActiveSessionPair, msg.SessionID).Scan(&answer.PairID, &answer.State)
+	if err == sql.ErrNoRows {
+		rollbackQuietly(tx)
+		answer.Result = syncapi.SessionNotActiveResult
+		return answer, nil
+	}
+	if err != nil {
+		syncutil.Error(err, ". Error finding the pair of session", msg.SessionID)
+		rollbackQuietly(tx)
+		return answer, err
+	}
+	now := time.Now().Format(sessionTimeFormat)
+	err = tx.QueryRow(sqlInsertMgmtMsg, answer.PairID, msg.SessionID, msg.SenderNodeID, int(msg.MessageType),
+		msg.Result, now).Scan(&answer.MsgSeq)
+	if err != nil {
+		syncutil.Error(err, ". Error keeping management message of session", msg.SessionID)
+		rollbackQuietly(tx)
+		return answer, err
+	}
+	if msg.MessageType == syncapi.SessionMgmtMsgTypeHeartbeat {
+		_, err = tx.Exec(sqlRenewSessionLease, answer.PairID, msg.SessionID, now)
+		if err != nil {
+			syncutil.Error(err, ". Error renewing the lease of session", msg.SessionID)
+			rollbackQuietly(tx)
+			return answer, err
+		}
+	} else if msg.MessageType.IsCanceling() {
+		if answer.State != syncapi.SyncSessionStateCanceling {
+			_, err = tx.Exec(sqlCancelSession, answer.PairID, msg.SessionID, answer.State)
+			if err != nil {
+				syncutil.Error(err, ". Error canceling session", msg.SessionID)
+				rollbackQuietly(tx)
+				return answer, err
+			}
+			answer.State = syncapi.SyncSessionStateCanceling
+		}
+		result, err := tx.Exec(sqlReleaseSessionFetches, msg.SessionID)
+		if err != nil {
+			syncutil.Error(err, ". Error rolling back the fetches of session", msg.SessionID)
+			rollbackQuietly(tx)
+			return answer, err
+		}
+		releasedCount, err := result.RowsAffected()
+		if err != nil {
+			rollbackQuietly(tx)
+			return answer, err
+		}
+		answer.ReleasedCount = int(releasedCount)
+	}
+	err = tx.Commit()
+	if err != nil {
+		syncutil.Error(err, ". Error committing management message of session", msg.SessionID)
+		return answer, err
+	}
+	answer.Result = "OK"
+	return answer, nil
+}
+
+//PollMgmtMsgs lists, in order, the messages of the session sent by nodes other than nodeID after afterSeq.
+func (sessionRepository sessionRepositoryType) PollMgmtMsgs(sessionID string, nodeID string, afterSeq int64) ([]syncapi.SessionMgmtMsg, error) {
+	answer := []syncapi.SessionMgmtMsg{}
+	rows, err := sessionRepository.db.Query(sqlPollMgmtMsgs, sessionID, nodeID, afterSeq)
+	if err != nil {
+		syncutil.Error(err, ". Error polling management messages of session", sessionID)
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		var (
+			msg         syncapi.SessionMgmtMsg
+			messageType int
+		)
+		err = rows.Scan(&msg.MsgSeq, &msg.SessionID, &msg.SenderNodeID, &messageType, &msg.Result, &msg.RecordCreated)
+		if err != nil {
+			syncutil.Error(err, ". Error reading management messages of session", sessionID)
+			return answer, err
+		}
+		msg.MessageType = syncapi.SessionMgmtMsgTypeEnum(messageType)
+		msg.RecordCreated = localTime(msg.RecordCreated)
+		answer = append(answer, msg)
+	}
+	err = rows.Err()
+	if err != nil {
+		syncutil.Error(err, ". Error reading management messages of session", sessionID)
+		return answer, err
+	}
+	return answer, nil
+}
@@ -0,0 +1,200 @@
+package syncdaosql
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"database/sql"
+)
+
+//The progress of a session is kept on the sync_pair_nodes row of each direction: NodeId sends to TargetNodeId. The
+//node given to the queuer, acknowledger and processor is the peer, so records sent to it are counted on the row
+//targeting it and records received from it on the row it sends from.
+const (
+	sqlResetSessionProgress = `
+update sync_pair_nodes set TotalSessRecBytes=0, TotalSessRecCount=0, ProceSessRecBytes=0, ProceSessRecCount=0
+where PairId=$1;`
+
+	sqlSetQueuedTotals = `
+update sync_pair_nodes set
+	TotalSessRecCount = ProceSessRecCount + (select count(*) from sync_peer_state where NodeId=$2 AND ChangedByClient=true),
+	TotalSessRecBytes = ProceSessRecBytes + (select coalesce(sum(RecordBytesSize), 0) from sync_peer_state where NodeId=$2 AND ChangedByClient=true)
+where TargetNodeId=$2 AND PairId in (select PairId from sync_pair where SyncSessionId=$1 AND SyncSessionState <> 'Inactive');`
+
+	sqlAddAcknowledgedProgress = `
+update sync_pair_nodes set
+	ProceSessRecCount = ProceSessRecCount + (select count(*) from sync_peer_state where NodeId=$2 AND TransactionBindSendId=$3 AND ChangedByClient=false),
+	ProceSessRecBytes = ProceSessRecBytes + (select coalesce(sum(RecordBytesSize), 0) from sync_peer_state where NodeId=$2 AND TransactionBindSendId=$3 AND ChangedByClient=false)
+where TargetNodeId=$2 AND PairId in (select PairId from sync_pair where SyncSessionId=$1 AND SyncSessionState <> 'Inactive');`
+
+	//The peer may send more than the totals it reported, so the totals grow with what was processed.
+	sqlAddProcessedProgress = `
+update sync_pair_nodes set
+	ProceSessRecCount = ProceSessRecCount + $3,
+	ProceSessRecBytes = ProceSessRecBytes + $4,
+	TotalSessRecCount = case when TotalSessRecCount < ProceSessRecCount + $3 then ProceSessRecCount + $3 else TotalSessRecCount end,
+	TotalSessRecBytes = case when TotalSessRecBytes < ProceSessRecBytes + $4 then ProceSessRecBytes + $4 else TotalSessRecBytes end
+where NodeId=$2 AND PairId in (select PairId from sync_pair where SyncSessionId=$1 AND SyncSessionState <> 'Inactive');`
+
+	sqlFindActiveSession = `
+select count(*) from sync_pair where PairId=$1 AND SyncSessionId=$2 AND SyncSessionState <> 'Inactive';`
+
+	sqlSetSentTotals = `
+update sync_pair_nodes set TotalSessRecBytes=$3, TotalSessRecCount=$4 where PairId=$1 AND NodeId=$2;`
+
+	sqlSetReceivedTotals = `
+update sync_pair_nodes set TotalSessRecBytes=$3, TotalSessRecCount=$4 where PairId=$1 AND TargetNodeId=$2;`
+
+	sqlSetSentProcessed = `
+update sync_pair_nodes set ProceSessRecBytes=$3, ProceSessRecCount=$4 where PairId=$1 AND NodeId=$2;`
+
+	sqlSetReceivedProcessed = `
+update sync_pair_nodes set ProceSessRecBytes=$3, ProceSessRecCount=$4 where PairId=$1 AND TargetNodeId=$2;`
+
+	sqlQueryPairProgress = `
+select NodeId, TargetNodeId, TotalSessRecBytes, TotalSessRecCount, ProceSessRecBytes, ProceSessRecCount
+from sync_pair_nodes where PairId=$1 order by NodeId;`
+)
+
+//resetSessionProgress zeroes the progress of both directions of the pair for a new session.
+func resetSessionProgress(db *sql.DB, pairID string) error {
+	_, err := db.Exec(sqlResetSessionProgress, pairID)
+	if err != nil {
+		syncutil.Error(err, ". Error resetting the session progress of pair", pairID)
+	}
+	return err
+}
+
+//setQueuedTotals sets the totals sent to nodeID in the session to what was processed so far plus what is queued now.
+func setQueuedTotals(db *sql.DB, sessionID string, nodeID string) error {
+	_, err := db.Exec(sqlSetQueuedTotals, sessionID, nodeID)
+	if err != nil {
+		syncutil.Error(err, ". Error setting the queued totals of session", sessionID, "for nodeId:", nodeID)
+	}
+	return err
+}
+
+//addAcknowledgedProgress adds the records nodeID acknowledged under transactionBindID to what the session processed
+//sending to nodeID. It runs before the transactionBindID is cleared.
+func addAcknowledgedProgress(db execer, sessionID string, nodeID string, transactionBindID string) error {
+	_, err := db.Exec(sqlAddAcknowledgedProgress, sessionID, nodeID, transactionBindID)
+	if err != nil {
+		syncutil.Error(err, ". Error adding the acknowledged progress of session", sessionID, "for transactionBindId:", transactionBindID)
+	}
+	return err
+}
+
+//addProcessedProgress adds the records of the request to what the session processed receiving from nodeID.
+func addProcessedProgress(db *sql.DB, sessionID string, nodeID string, request *syncmsg.ProtoSyncEntityMessageRequest) error {
+	count, bytes := syncapi.RequestRecordTotals(request)
+	_, err := db.Exec(sqlAddProcessedProgress, sessionID, nodeID, count, bytes)
+	if err != nil {
+		syncutil.Error(err, ". Error adding the processed progress of session", sessionID, "for nodeId:", nodeID)
+	}
+	return err
+}
+
+//queryPairProgress gives the progress of both directions of the pair.
+func queryPairProgress(db *sql.DB, pairID string) ([]syncdao.SessionProgress, error) {
+	answer := []syncdao.SessionProgress{}
+	rows, err := db.Query(sqlQueryPairProgress, pairID)
+	if err != nil {
+		syncutil.Error(err, ". Error querying the session progress of pair", pairID)
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		var progress syncdao.SessionProgress
+		err = rows.Scan(&progress.NodeID, &progress.TargetNodeID, &progress.TotalSessRecBytes, &progress.TotalSessRecCount,
+			&progress.ProceSessRecBytes, &progress.ProceSessRecCount)
+		if err != nil {
+			syncutil.Error(err, ". Error reading the session progress of pair", pairID)
+			return answer, err
+		}
+		answer = append(answer, progress)
+	}
+	err = rows.Err()
+	if err != nil {
+		syncutil.Error(err, ". Error reading the session progress of pair", pairID)
+		return answer, err
+	}
+	return answer, nil
+}
+
+//UpdateSyncingWithTotals sets the totals of both directions of the active session via a sql database.
+func (dao SyncPairSQLDao) UpdateSyncingWithTotals(request syncdao.UpdateSyncingRequestWithTotals) (syncdao.UpdateSyncingResult, error) {
+	return dao.updateSyncing(request.PairID, request.SessionID, request.NodeID, sqlSetSentTotals, sqlSetReceivedTotals,
+		request.RecordBytes1, request.RecordCount1, request.RecordBytes2, request.RecordCount2)
+}
+
+//UpdateSyncingWithProcessed sets what both directions of the active session processed via a sql database.
+func (dao SyncPairSQLDao) UpdateSyncingWithProcessed(request syncdao.UpdateSyncingRequestWithProcessed) (syncdao.UpdateSyncingResult, error) {
+	return dao.updateSyncing(request.PairID, request.SessionID, request.NodeID, sqlSetSentProcessed, sqlSetReceivedProcessed,
+		request.RecordBytes1, request.RecordCount1, request.RecordBytes2, request.RecordCount2)
+}
+
+//updateSyncing applies sentSQL to the direction from nodeID and receivedSQL to the direction to nodeID in one
+//transaction.
+func (dao SyncPairSQLDao) updateSyncing(pairID string, sessionID string, nodeID string, sentSQL string, receivedSQL string,
+	bytes1 int, count1 int, bytes2 int, count2 int) (syncdao.UpdateSyncingResult, error) {
+	var (
+		answer      syncdao.UpdateSyncingResult
+		activeCount int
+	)
+	tx, err := dao.db.Begin()
+	if err != nil {
+		syncutil.Error(err, ". Error starting transaction for the progress of session", sessionID)
+		return answer, err
+	}
+	err = tx.QueryRow(sqlFindActiveSession, pairID, sessionID).Scan(&activeCount)
+	if err != nil {
+		syncutil.Error(err, ". Error finding session", sessionID)
+		rollbackQuietly(tx)
+		return answer, err
+	}
+	if activeCount == 0 {
+		rollbackQuietly(tx)
+		answer.Result = syncapi.SyncingSessionNotActiveResult
+		answer.ResultMsg = "Session '" + sessionID + "' is not the active session of pair '" + pairID + "'"
+		return answer, nil
+	}
+	var affectedCount int64
+	for _, update := range []struct {
+		sqlStr string
+		bytes  int
+		count  int
+	}{{sentSQL, bytes1, count1}, {receivedSQL, bytes2, count2}} {
+		result, err := tx.Exec(update.sqlStr, pairID, nodeID, update.bytes, update.count)
+		if err != nil {
+			syncutil.Error(err, ". Error updating the progress of session", sessionID)
+			rollbackQuietly(tx)
+			return answer, err
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			rollbackQuietly(tx)
+			return answer, err
+		}
+		affectedCount += count
+	}
+	if affectedCount == 0 {
+		rollbackQuietly(tx)
+		answer.Result = syncapi.SyncingNodeNotInPairResult
+		answer.ResultMsg = "Node '" + nodeID + "' is not in pair '" + pairID + "'"
+		return answer, nil
+	}
+	err = tx.Commit()
+	if err != nil {
+		syncutil.Error(err, ". Error committing the progress of session", sessionID)
+		return answer, err
+	}
+	answer.Result = "OK"
+	return answer, nil
+}
@@ -0,0 +1,259 @@
+package syncdaosql
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"database/sql"
+	"time"
+)
+
+//NewSessionRepository provides sql database access for a SessionRepository.
+func NewSessionRepository(db *sql.DB) syncapi.SessionRepositoryable {
+	return sessionRepositoryType{
+		db: db,
+	}
+}
+
+type sessionRepositoryType struct {
+	db *sql.DB
+}
+
+const (
+	sqlFindActiveSessions = `
+select PairId, SyncSessionId, SyncSessionState, SyncSessionStart, SyncSessionHeartbeat, coalesce(MaxSesDurValue, 0), MaxSesDurUnit from sync_pair
+where SyncSessionState <> 'Inactive' AND SyncSessionId is not null order by PairId;`
+
+	//The session is only canceled while still in the state it was found in, so a session its client moves on in the
+	//meantime is left for the next reaping.
+	sqlCancelExpiredSession = `
+update sync_pair set SyncSessionState='Canceling' where PairId=$1 AND SyncSessionId=$2 AND SyncSessionState=$3;`
+
+	//Records bound to the session stay ChangedByClient, so the next session queues them again.
+	sqlReleaseSessionPeerStates = `
+update sync_peer_state set SessionBindId=null, QueueBindSendId=null, TransactionBindSendId=null where SessionBindId=$1;`
+
+	//The peer never acknowledges the transactions of an ended session, so the records it sent are processed anew when
+	//it sends them again.
+	sqlDiscardReceiveBinds = `
+update sync_peer_state set TransactionBindReceiveId=null
+where TransactionBindReceiveId is not null AND
+(NodeId in (select NodeId from sync_pair_nodes where PairId=$1) OR NodeId in (select TargetNodeId from sync_pair_nodes where PairId=$1));`
+
+	sqlCloseExpiredSession = `
+update sync_pair set SyncSessionId=null, SyncSessionStart=null, SyncSessionHeartbeat=null, SyncSessionState='Inactive' where PairId=$1 AND SyncSessionId=$2;`
+
+	sqlRecordEndedSession = `
+insert into sync_session_history (PairId, SyncSessionId, SyncSessionState, SyncSessionStart, SyncSessionEnd, EndReason, EndReasonMsg, ReleasedCount)
+values ($1, $2, $3, $4, $5, $6, $7, $8);`
+
+	sqlFindEndedSessions = `
+select PairId, SyncSessionId, SyncSessionState, SyncSessionStart, SyncSessionEnd, EndReason, coalesce(EndReasonMsg, ''), ReleasedCount
+from sync_session_history where PairId=$1 order by SyncSessionEnd, SyncSessionId;`
+)
+
+//sessionTimeFormat is the format CreateSyncSession stores the local time of SyncSessionStart in, and SendMgmtMsg the
+//local time of SyncSessionHeartbeat. The columns have no zone, so the times are written and read as local times.
+const sessionTimeFormat = "2006-01-02 15:04:05.000"
+
+//localTime gives the local time stored in a column without zone, which the driver reads as UTC.
+func localTime(stored time.Time) time.Time {
+	return time.Date(stored.Year(), stored.Month(), stored.Day(), stored.Hour(), stored.Minute(), stored.Second(),
+		stored.Nanosecond(), time.Local)
+}
+
+//activeSession is a sync_pair row with a session in progress.
+type activeSession struct {
+	pairID         string
+	sessionID      string
+	state          string
+	sessionStart   sql.NullTime
+	heartbeat      sql.NullTime
+	maxSesDurValue int
+	maxSesDurUnit  string
+}
+
+//ReapExpiredSessions ends, each within its own transaction, the sessions which outlived the maximum session duration
+//of their pair.
+func (sessionRepository sessionRepositoryType) ReapExpiredSessions(now time.Time) (syncapi.SessionReapResult, error) {
+	answer := syncapi.SessionReapResult{
+		Sessions: []syncapi.EndedSession{},
+	}
+	sessions, err := sessionRepository.findActiveSessions()
+	if err != nil {
+		return answer, err
+	}
+	for _, session := range sessions {
+		maxDuration, err := syncapi.MaxSessionDuration(session.maxSesDurValue, session.maxSesDurUnit)
+		if err != nil {
+			syncutil.Error(err, ". Cannot tell whether session", session.sessionID, "of pair", session.pairID, "expired")
+			continue
+		}
+		var sessionStart, heartbeat time.Time
+		if session.sessionStart.Valid {
+			sessionStart = localTime(session.sessionStart.Time)
+		}
+		if session.heartbeat.Valid {
+			heartbeat = localTime(session.heartbeat.Time)
+		}
+		if !syncapi.IsSessionExpired(sessionStart, heartbeat, maxDuration, now) {
+			continue
+		}
+		ended := syncapi.EndedSession{
+			PairID:       session.pairID,
+			SessionID:    session.sessionID,
+			State:        session.state,
+			SessionStart: sessionStart,
+			SessionEnd:   now,
+			Reason:       syncapi.SessionEndReasonExpired,
+			ReasonMsg:    "Session outlived the maximum session duration of " + maxDuration.String(),
+		}
+		reaped, _, err := sessionRepository.endSession(&ended)
+		if err != nil {
+			return answer, err
+		}
+		if reaped {
+			answer.Sessions = append(answer.Sessions, ended)
+			answer.TotalReaped++
+		}
+	}
+	if answer.TotalReaped > 0 {
+		syncutil.Info("Reaped", answer.TotalReaped, "expired sessions")
+	}
+	return answer, nil
+}
+
+func (sessionRepository sessionRepositoryType) findActiveSessions() ([]activeSession, error) {
+	answer := []activeSession{}
+	rows, err := sessionRepository.db.Query(sqlFindActiveSessions)
+	if err != nil {
+		syncutil.Error(err, ". Error finding active sessions")
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		var session activeSession
+		err = rows.Scan(&session.pairID, &session.sessionID, &session.state, &session.sessionStart,
+			&session.heartbeat, &session.maxSesDurValue, &session.maxSesDurUnit)
+		if err != nil {
+			syncutil.Error(err, ". Error reading active sessions")
+			return answer, err
+		}
+		answer = append(answer, session)
+	}
+	err = rows.Err()
+	if err != nil {
+		syncutil.Error(err, ". Error reading active sessions")
+		return answer, err
+	}
+	return answer, nil
+}
+
+//endSession cancels, rolls back and closes the session, answering how many TransactionBindReceiveId were discarded, or
+//false when the session moved on since it was found.
+func (sessionRepository sessionRepositoryType) endSession(ended *syncapi.EndedSession) (bool, int, error) {
+	tx, err := sessionRepository.db.Begin()
+	if err != nil {
+		syncutil.Error(err, ". Error starting transaction for ending session", ended.SessionID)
+		return false, 0, err
+	}
+	result, err := tx.Exec(sqlCancelExpiredSession, ended.PairID, ended.SessionID, ended.State)
+	if err != nil {
+		syncutil.Error(err, ". Error canceling session", ended.SessionID)
+		rollbackQuietly(tx)
+		return false, 0, err
+	}
+	canceledCount, err := result.RowsAffected()
+	if err != nil || canceledCount == 0 {
+		rollbackQuietly(tx)
+		return false, 0, err
+	}
+	result, err = tx.Exec(sqlReleaseSessionPeerStates, ended.SessionID)
+	if err != nil {
+		syncutil.Error(err, ". Error releasing records bound to session", ended.SessionID)
+		rollbackQuietly(tx)
+		return false, 0, err
+	}
+	releasedCount, err := result.RowsAffected()
+	if err != nil {
+		rollbackQuietly(tx)
+		return false, 0, err
+	}
+	ended.ReleasedCount = int(releasedCount)
+	result, err = tx.Exec(sqlDiscardReceiveBinds, ended.PairID)
+	if err != nil {
+		syncutil.Error(err, ". Error discarding the transactions received in session", ended.SessionID)
+		rollbackQuietly(tx)
+		return false, 0, err
+	}
+	discardedCount, err := result.RowsAffected()
+	if err != nil {
+		rollbackQuietly(tx)
+		return false, 0, err
+	}
+	_, err = tx.Exec(sqlCloseExpiredSession, ended.PairID, ended.SessionID)
+	if err == nil {
+		var sessionStart interface{}
+		if !ended.SessionStart.IsZero() {
+			sessionStart = ended.SessionStart.In(time.Local).Format(sessionTimeFormat)
+		}
+		_, err = tx.Exec(sqlRecordEndedSession, ended.PairID, ended.SessionID, ended.State, sessionStart,
+			ended.SessionEnd.In(time.Local).Format(sessionTimeFormat), ended.Reason, ended.ReasonMsg, ended.ReleasedCount)
+	}
+	if err != nil {
+		syncutil.Error(err, ". Error closing session", ended.SessionID)
+		rollbackQuietly(tx)
+		return false, 0, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		syncutil.Error(err, ". Error committing the end of session", ended.SessionID)
+		return false, 0, err
+	}
+	return true, int(discardedCount), nil
+}
+
+//EndedSessions lists the sessions of a pair ended by the agent, oldest first.
+func (sessionRepository sessionRepositoryType) EndedSessions(pairID string) ([]syncapi.EndedSession, error) {
+	answer := []syncapi.EndedSession{}
+	rows, err := sessionRepository.db.Query(sqlFindEndedSessions, pairID)
+	if err != nil {
+		syncutil.Error(err, ". Error finding ended sessions of pair", pairID)
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		var (
+			ended        syncapi.EndedSession
+			sessionStart sql.NullTime
+		)
+		err = rows.Scan(&ended.PairID, &ended.SessionID, &ended.State, &sessionStart, &ended.SessionEnd,
+			&ended.Reason, &ended.ReasonMsg, &ended.ReleasedCount)
+		if err != nil {
+			syncutil.Error(err, ". Error reading ended sessions of pair", pairID)
+			return answer, err
+		}
+		if sessionStart.Valid {
+			ended.SessionStart = localTime(sessionStart.Time)
+		}
+		ended.SessionEnd = localTime(ended.SessionEnd)
+		answer = append(answer, ended)
+	}
+	err = rows.Err()
+	if err != nil {
+		syncutil.Error(err, ". Error reading ended sessions of pair", pairID)
+		return answer, err
+	}
+	return answer, nil
+}
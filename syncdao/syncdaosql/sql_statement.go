@@ -0,0 +1,34 @@
+package syncdaosql
+
+import (
+	"strconv"
+)
+
+//sqlStatement is a statement whose data values are all bound as parameters.
+type sqlStatement struct {
+	sql  string
+	args []interface{}
+}
+
+//sqlArgs collects the arguments of a statement being built.
+type sqlArgs []interface{}
+
+//add binds value as the next argument, giving its placeholder.
+func (args *sqlArgs) add(value interface{}) string {
+	*args = append(*args, value)
+	return "$" + strconv.Itoa(len(*args))
+}
+
+//recordLock restricts a statement to the time the local sync_state still holds the version of the record with
+//recordHash, which is the one the peer last knew of.
+type recordLock struct {
+	entitySingularName string
+	recordID           string
+	recordHash         string
+}
+
+//where gives the condition of the lock, binding its values to args.
+func (lock recordLock) where(args *sqlArgs) string {
+	return "((select count(RecordId) as syncRecordCount from sync_state where (EntitySingularName=" + args.add(lock.entitySingularName) +
+		" AND RecordId=" + args.add(lock.recordID) + " AND RecordHash=" + args.add(lock.recordHash) + ")) = 1)"
+}
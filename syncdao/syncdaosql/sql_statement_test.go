@@ -0,0 +1,221 @@
+package syncdaosql
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+//maliciousSeeds are values trying to escape a SQL string literal or identifier.
+var maliciousSeeds = []string{
+	"",
+	"0934A378-DEDB-4207-B99C-DD0D61DC59BC",
+	"'; drop table sync_state; --",
+	"x' OR '1'='1",
+	`"; delete from contacts; --`,
+	"$1",
+	"\\'); commit; begin; update sync_peer_state set IsConflict=true; --",
+	"{{.Item.RecordID}}",
+}
+
+var testContactFieldDefinitions = map[string]syncdao.SyncFieldDefinition{
+	"contactId": {FieldName: "contactId", FieldType: syncdao.SyncFieldTypeEnumString, IsPrimaryKey: true},
+	"firstName": {FieldName: "firstName", FieldType: syncdao.SyncFieldTypeEnumString},
+	"sortOrder": {FieldName: "sortOrder", FieldType: syncdao.SyncFieldTypeEnumInt},
+}
+
+//testDialect quotes identifiers without case folding, as a Dialect given to the sql builders.
+type testDialect struct{}
+
+func (testDialect) Now() string {
+	return "now()"
+}
+
+func (testDialect) ForUpdate() string {
+	return " for update"
+}
+
+func (testDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func (testDialect) Cast(expr string, columnType ColumnType) string {
+	return expr
+}
+
+func createTestContactRecord(t *testing.T, fieldName string, recordID string, firstName string) *syncmsg.ProtoRecord {
+	creator := syncmsg.NewCreator()
+	record := &syncmsg.ProtoRecord{
+		Fields: []*syncmsg.ProtoField{
+			creator.CreateStringProtoField("contactId", recordID),
+			creator.CreateStringProtoField(fieldName, firstName),
+			creator.CreateInt64ProtoField("sortOrder", 5),
+		},
+	}
+	if len(creator.Errors) != 0 {
+		t.Skip("value cannot be encoded in a record:", syncmsg.NewCreatorError(creator.Errors))
+	}
+	return record
+}
+
+//createTestCustomTableSQL gives the update, insert and delete of the record, in that order.
+func createTestCustomTableSQL(record *syncmsg.ProtoRecord, lockRecordID string) ([]sqlStatement, error) {
+	lock := &recordLock{entitySingularName: "Contact", recordID: lockRecordID, recordHash: "hash-" + lockRecordID}
+	answer := []sqlStatement{}
+	updateSQL, updateArgs, err := createCustomTableUpdateSQL(testDialect{}, "Contacts", "Contact", record, testContactFieldDefinitions, lock)
+	if err != nil {
+		return answer, err
+	}
+	answer = append(answer, sqlStatement{updateSQL, updateArgs})
+	insertSQL, insertArgs, err := createCustomTableInsertSQL(testDialect{}, "Contacts", "Contact", record, testContactFieldDefinitions)
+	if err != nil {
+		return answer, err
+	}
+	answer = append(answer, sqlStatement{insertSQL, insertArgs})
+	deleteSQL, deleteArgs, err := createCustomTableDeleteSQL(testDialect{}, "Contacts", "Contact", record, testContactFieldDefinitions, lock)
+	if err != nil {
+		return answer, err
+	}
+	answer = append(answer, sqlStatement{deleteSQL, deleteArgs})
+	return answer, nil
+}
+
+//assertSameSQL asserts the statements only differ from the expected ones in their arguments.
+func assertSameSQL(t *testing.T, expected []sqlStatement, actual []sqlStatement) {
+	assert.Equal(t, len(expected), len(actual))
+	for index := range expected {
+		if index < len(actual) {
+			assert.Equal(t, expected[index].sql, actual[index].sql)
+			assert.Equal(t, len(expected[index].args), len(actual[index].args))
+		}
+	}
+}
+
+func FuzzCustomTableSQL(f *testing.F) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	for _, seed := range maliciousSeeds {
+		f.Add(seed, seed)
+	}
+	f.Fuzz(func(t *testing.T, recordID string, firstName string) {
+		expected, err := createTestCustomTableSQL(createTestContactRecord(t, "firstName", "id", "name"), "id")
+		assert.Nil(t, err)
+		actual, err := createTestCustomTableSQL(createTestContactRecord(t, "firstName", recordID, firstName), recordID)
+		assert.Nil(t, err)
+		assertSameSQL(t, expected, actual)
+		for _, statement := range actual {
+			assert.Contains(t, statement.args, recordID)
+		}
+	})
+}
+
+func FuzzCustomTableSQLUnknownField(f *testing.F) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	for _, seed := range maliciousSeeds {
+		f.Add(seed)
+	}
+	f.Add("firstName = 'x', contactId")
+	f.Fuzz(func(t *testing.T, fieldName string) {
+		if _, found := testContactFieldDefinitions[fieldName]; found {
+			t.Skip("field is known to the entity")
+		}
+		_, err := createTestCustomTableSQL(createTestContactRecord(t, fieldName, "id", "name"), "id")
+		assert.NotNil(t, err, "field %q was not rejected", fieldName)
+	})
+}
+
+//createTestFastBatchSQL gives the statements of a fast batch with one record for each supported SentSyncState
+//followed by the query reading them back.
+func createTestFastBatchSQL(t *testing.T, isDelete bool, nodeID string, bindID string, recordID string, firstName string) []sqlStatement {
+	recordData, err := proto.Marshal(createTestContactRecord(t, "firstName", recordID, firstName))
+	assert.Nil(t, err)
+	changeBuilder := &changeInitialSQLBuilder{dialect: testDialect{}}
+	readBuilder := &readInitialSQLBuilder{}
+	builders := []sqlBuilder{changeBuilder, readBuilder}
+	requestData := changeEntityMessage{isDelete: isDelete, NodeIDToProcess: nodeID, TransactionBindID: bindID}
+	changeDataMessages := changeDataMessageList{
+		SyncEntitySingularName: "Contact",
+		SyncEntityPluralName:   "Contacts",
+		entityKeyMap:           map[string]bool{"contactId": true},
+		entitySortedKeys:       []string{"contactId"},
+		fieldDefinitions:       testContactFieldDefinitions,
+	}
+	sentSyncStates := []syncmsg.SentSyncStateEnum{
+		syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer,
+		syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer,
+	}
+	for _, builder := range builders {
+		builder.processStart(requestData, nodeID, bindID)
+		builder.startRecords(changeDataMessages)
+		for msgIndex, sentSyncState := range sentSyncStates {
+			item := changeDataMessage{
+				recordData:        recordData,
+				sentSyncState:     sentSyncState,
+				msgIndex:          msgIndex,
+				msgIndexLen:       len(sentSyncStates) - 1,
+				RecordID:          recordID,
+				RecordHash:        "new-hash-" + recordID,
+				LastKnownPeerHash: "old-hash-" + recordID,
+				RecordBytesSize:   uint32(len(recordData)),
+			}
+			err = builder.startRecordItem(item, requestData, changeDataMessages)
+			assert.Nil(t, err)
+		}
+		builder.processEnd()
+	}
+	return append(changeBuilder.result(), readBuilder.result()...)
+}
+
+func FuzzFastBatchSQL(f *testing.F) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	for _, seed := range maliciousSeeds {
+		f.Add(seed, seed, seed, seed)
+	}
+	f.Fuzz(func(t *testing.T, nodeID string, bindID string, recordID string, firstName string) {
+		for _, isDelete := range []bool{false, true} {
+			expected := createTestFastBatchSQL(t, isDelete, "node", "bind", "id", "name")
+			actual := createTestFastBatchSQL(t, isDelete, nodeID, bindID, recordID, firstName)
+			assertSameSQL(t, expected, actual)
+		}
+	})
+}
+
+func FuzzMarkItemsWithBindIDSQL(f *testing.F) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	for _, seed := range maliciousSeeds {
+		f.Add(seed, seed, seed)
+	}
+	entityMapByPluralName := map[string]syncapi.EntityNameItem{
+		"Contacts": {SingularName: "Contact", PluralName: "Contacts"},
+	}
+	createRequest := func(recordID string) *syncmsg.ProtoSyncEntityMessageRequest {
+		return &syncmsg.ProtoSyncEntityMessageRequest{
+			Items: []*syncmsg.ProtoSyncDataMessagesRequest{
+				{
+					EntityPluralName: proto.String("Contacts"),
+					Msgs: []*syncmsg.ProtoSyncDataMessageRequest{
+						{RecordId: proto.String(recordID)},
+						{RecordId: proto.String(recordID + "-2")},
+					},
+				},
+			},
+		}
+	}
+	expectedSQL, expectedArgs := createMarkItemsWithBindIDSQL("bind", "node", createRequest("id"), entityMapByPluralName)
+	f.Fuzz(func(t *testing.T, bindID string, nodeID string, recordID string) {
+		actualSQL, actualArgs := createMarkItemsWithBindIDSQL(bindID, nodeID, createRequest(recordID), entityMapByPluralName)
+		assert.Equal(t, expectedSQL, actualSQL)
+		assert.Equal(t, len(expectedArgs), len(actualArgs))
+		assert.Contains(t, actualArgs, recordID)
+	})
+}
@@ -0,0 +1,177 @@
+package syncdaosql
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncutil"
+	"database/sql"
+)
+
+const sqlSelectEntities = `
+select EntitySingularName, EntityPluralName, ProcOrderAddUpdate, ProcOrderDelete, EntityHandlerUri from sync_data_entity
+where DataVersionName=$1 order by ProcOrderAddUpdate, EntitySingularName;`
+
+const sqlSelectFields = `
+select EntitySingularName, FieldName, DataTypeName, IsPrimaryKey from sync_data_field where DataVersionName=$1
+order by EntitySingularName, FieldName;`
+
+//queryEntities gives the sync_data_entity rows of the data version ordered by ProcOrderAddUpdate.
+func queryEntities(db queryer, dataVersionName string) ([]syncdao.EntityPairItem, error) {
+	answer := []syncdao.EntityPairItem{}
+	rows, err := db.Query(sqlSelectEntities, dataVersionName)
+	if err != nil {
+		syncutil.Error(err, ". Error finding the entities of data version", dataVersionName)
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		var (
+			entity           syncdao.EntityPairItem
+			entityHandlerURI sql.NullString
+		)
+		err = rows.Scan(&entity.EntitySingularName, &entity.EntityPluralName, &entity.ProcessOrderAddUpdate,
+			&entity.ProcessOrderDelete, &entityHandlerURI)
+		if err != nil {
+			syncutil.Error(err, ". Error reading the entities of data version", dataVersionName)
+			return answer, err
+		}
+		entity.EntityHandlerURI = entityHandlerURI.String
+		answer = append(answer, entity)
+	}
+	return answer, rows.Err()
+}
+
+//queryFields gives the sync_data_field rows of the data version by entity, ordered by FieldName.
+func queryFields(db queryer, dataVersionName string) (map[string][]syncdao.SyncFieldDefinition, error) {
+	answer := make(map[string][]syncdao.SyncFieldDefinition)
+	rows, err := db.Query(sqlSelectFields, dataVersionName)
+	if err != nil {
+		syncutil.Error(err, ". Error finding the fields of data version", dataVersionName)
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		var (
+			entitySingularName, dataTypeName string
+			field                            syncdao.SyncFieldDefinition
+		)
+		err = rows.Scan(&entitySingularName, &field.FieldName, &dataTypeName, &field.IsPrimaryKey)
+		if err != nil {
+			syncutil.Error(err, ". Error reading the fields of data version", dataVersionName)
+			return answer, err
+		}
+		field.FieldType = syncdao.SyncFieldTypeEnum(syncdao.SyncFieldTypeEnumValue[dataTypeName])
+		answer[entitySingularName] = append(answer[entitySingularName], field)
+	}
+	return answer, rows.Err()
+}
+
+//lockDataVersion locks the sync_data_version row of dataVersionName within tx, so that the entities of the data
+//version are checked against each other one at a time. A dialect without row locks relies on tx holding the write
+//lock instead.
+func lockDataVersion(tx *sql.Tx, dialect Dialect, dataVersionName string) error {
+	var name string
+	err := tx.QueryRow("select DataVersionName from sync_data_version where DataVersionName=$1"+dialect.ForUpdate()+";",
+		dataVersionName).Scan(&name)
+	if err == sql.ErrNoRows {
+		return syncdao.ErrDaoNoDataFound
+	}
+	if err != nil {
+		syncutil.Error(err, ". Error finding data version", dataVersionName)
+	}
+	return err
+}
+
+//checkNewEntity checks that entity is not yet known and that its process orders agree with those of the entities of
+//the data version.
+func checkNewEntity(db queryer, dataVersionName string, entity syncdao.EntityPairItem) error {
+	var count int
+	err := db.QueryRow("select count(*) from sync_data_entity where EntitySingularName=$1;",
+		entity.EntitySingularName).Scan(&count)
+	if err != nil {
+		syncutil.Error(err, ". Error finding entity", entity.EntitySingularName)
+		return err
+	}
+	if count > 0 {
+		return syncapi.ValidationError{Msg: "Entity '" + entity.EntitySingularName + "' already exists"}
+	}
+	existing, err := queryEntities(db, dataVersionName)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.EntityPluralName == entity.EntityPluralName {
+			return syncapi.ValidationError{Msg: "Entity with plural name '" + entity.EntityPluralName + "' already exists in data version '" + dataVersionName + "'"}
+		}
+	}
+	return syncapi.ValidateEntityOrders(existing, entity)
+}
+
+//ListDataVersions implements the syncdao.SyncPairDao.ListDataVersions interface via a sql database.
+func (dao SyncPairSQLDao) ListDataVersions() ([]string, error) {
+	answer := []string{}
+	rows, err := dao.db.Query("select DataVersionName from sync_data_version order by DataVersionName;")
+	if err != nil {
+		syncutil.Error(err, ". Error listing data versions")
+		return answer, err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		var dataVersionName string
+		err = rows.Scan(&dataVersionName)
+		if err != nil {
+			syncutil.Error(err, ". Error reading data versions")
+			return answer, err
+		}
+		answer = append(answer, dataVersionName)
+	}
+	return answer, rows.Err()
+}
+
+//ListEntities implements the syncdao.SyncPairDao.ListEntities interface via a sql database.
+func (dao SyncPairSQLDao) ListEntities(dataVersionName string) ([]syncdao.EntityDefinition, error) {
+	answer := []syncdao.EntityDefinition{}
+	var count int
+	err := dao.db.QueryRow("select count(*) from sync_data_version where DataVersionName=$1;", dataVersionName).Scan(&count)
+	if err != nil {
+		syncutil.Error(err, ". Error finding data version", dataVersionName)
+		return answer, err
+	}
+	if count == 0 {
+		return answer, syncdao.ErrDaoNoDataFound
+	}
+	entities, err := queryEntities(dao.db, dataVersionName)
+	if err != nil {
+		return answer, err
+	}
+	fields, err := queryFields(dao.db, dataVersionName)
+	if err != nil {
+		return answer, err
+	}
+	for _, entity := range entities {
+		definition := syncdao.EntityDefinition{Entity: entity, Fields: fields[entity.EntitySingularName]}
+		if definition.Fields == nil {
+			definition.Fields = []syncdao.SyncFieldDefinition{}
+		}
+		answer = append(answer, definition)
+	}
+	return answer, nil
+}
@@ -1,8 +1,8 @@
-package syncdaopq
+package syncdaosqltest
 
-// BUG(doug4j@gmail.com): Move this test file into a separate package, see https://medium.com/@matryer/5-simple-tips-and-tricks-for-writing-unit-tests-in-golang-619653f90742#.o8nxf7z53
 import (
 	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncdao/syncdaosql"
 	"data-sync-tools-go/testhelper"
 	"fmt"
 	"log"
@@ -10,24 +10,13 @@ import (
 	"time"
 )
 
-var (
-	testDbUser     = testhelper.TestDbUser
-	testDbPassword = testhelper.TestDbPassword
-	testDbName     = testhelper.TestDbName
-	testDbHost     = testhelper.TestDbHost
-	testDbPort     = testhelper.TestDbPort
-	dbFactory      syncdao.DaosFactory
-	//db             *sql.DB
-)
-
-func setupDatabaseObj() {
+func setupDatabaseObj(database Database) {
 	teardownDatabaseObj()
-	dbFactory, err := NewPostgresSQLDaosFactory(testDbUser, testDbPassword, testDbHost, testDbName, testDbPort)
+	db, err := database.Open()
 	if err != nil {
 		panic(err)
 	}
-	syncdao.DefaultDaos = dbFactory
-	db := dbFactory.SQLDb()
+	syncdao.DefaultDaos = syncdaosql.NewSQLDaosFactory(db, database.Dialect)
 	testhelper.SetupData(db, "profile3")
 }
 
@@ -37,13 +26,13 @@ func teardownDatabaseObj() {
 	}
 }
 
-func TestSyncPairPostgresSqlDao_GetPairByNames(t *testing.T) {
+func testSyncPairDaoGetPairByNames(t *testing.T, database Database) {
 	log.Println("")
 	log.Println("")
-	log.Println("***START TestSyncPairPostgresSqlDao_GetPairByNames")
+	log.Println("***START TestSyncPairSQLDao_GetPairByNames")
 	log.Println("")
 
-	setupDatabaseObj()
+	setupDatabaseObj(database)
 	defer teardownDatabaseObj()
 
 	requestingNodeName := "A"
@@ -96,18 +85,18 @@ func TestSyncPairPostgresSqlDao_GetPairByNames(t *testing.T) {
 		t.Error("RecordCreated incorrect")
 	}
 	log.Println("")
-	log.Println("***END TestSyncPairPostgresSqlDao_GetPairByNames")
+	log.Println("***END TestSyncPairSQLDao_GetPairByNames")
 	log.Println("")
 	log.Println("")
 }
 
-func TestSyncPairPostgresSqlDao_GetNodePairItem(t *testing.T) {
+func testSyncPairDaoGetNodePairItem(t *testing.T, database Database) {
 	log.Println("")
 	log.Println("")
-	log.Println("***START TestSyncPairPostgresSqlDao_GetNodePairItem")
+	log.Println("***START TestSyncPairSQLDao_GetNodePairItem")
 	log.Println("")
 
-	setupDatabaseObj()
+	setupDatabaseObj(database)
 	defer teardownDatabaseObj()
 
 	requestingNodeName := "A"
@@ -127,18 +116,18 @@ func TestSyncPairPostgresSqlDao_GetNodePairItem(t *testing.T) {
 		t.Error("Node Name incorrect")
 	}
 	log.Println("")
-	log.Println("***END TestSyncPairPostgresSqlDao_GetNodePairItem")
+	log.Println("***END TestSyncPairSQLDao_GetNodePairItem")
 	log.Println("")
 	log.Println("")
 }
 
-func TestSyncPairPostgresSqlDao_GetEntityPairItem(t *testing.T) {
+func testSyncPairDaoGetEntityPairItem(t *testing.T, database Database) {
 	log.Println("")
 	log.Println("")
-	log.Println("***START TestSyncPairPostgresSqlDao_GetEntityPairItem")
+	log.Println("***START TestSyncPairSQLDao_GetEntityPairItem")
 	log.Println("")
 
-	setupDatabaseObj()
+	setupDatabaseObj(database)
 	defer teardownDatabaseObj()
 
 	requestingNodeName := "A"
@@ -156,18 +145,18 @@ func TestSyncPairPostgresSqlDao_GetEntityPairItem(t *testing.T) {
 		t.Error("Missing items")
 	}
 	log.Println("")
-	log.Println("***END TestSyncPairPostgresSqlDao_GetEntityPairItem")
+	log.Println("***END TestSyncPairSQLDao_GetEntityPairItem")
 	log.Println("")
 	log.Println("")
 }
 
-func TestSyncPairPostgresSqlDao_CreateSyncSession_CloseSyncSession(t *testing.T) {
+func testSyncPairDaoCreateSyncSessionCloseSyncSession(t *testing.T, database Database) {
 	log.Println("")
 	log.Println("")
-	log.Println("***START TestSyncPairPostgresSqlDao_CreateSyncSession_CloseSyncSession")
+	log.Println("***START TestSyncPairSQLDao_CreateSyncSession_CloseSyncSession")
 	log.Println("")
 
-	setupDatabaseObj()
+	setupDatabaseObj(database)
 	defer teardownDatabaseObj()
 
 	syncPairDao := syncdao.DefaultDaos.SyncPairDao()
@@ -254,7 +243,7 @@ func TestSyncPairPostgresSqlDao_CreateSyncSession_CloseSyncSession(t *testing.T)
 		t.Error("RecordCreated incorrect")
 	}
 	log.Println("")
-	log.Println("***END TestSyncPairPostgresSqlDao_CreateSyncSession_CloseSyncSession")
+	log.Println("***END TestSyncPairSQLDao_CreateSyncSession_CloseSyncSession")
 	log.Println("")
 	log.Println("")
 }
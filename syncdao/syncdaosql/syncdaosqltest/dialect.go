@@ -0,0 +1,35 @@
+package syncdaosqltest
+
+import (
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//maliciousIdentifiers are names trying to escape a quoted identifier.
+var maliciousIdentifiers = []string{
+	"",
+	"Contacts",
+	`"; delete from contacts; --`,
+	`x" OR "1"="1`,
+	"\x00\"; drop table sync_state; --",
+}
+
+//FuzzQuoteIdentifier fuzzes the QuoteIdentifier of dialect, which must always give a single quoted identifier.
+func FuzzQuoteIdentifier(f *testing.F, dialect syncdaosql.Dialect) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	for _, seed := range maliciousIdentifiers {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		quoted := dialect.QuoteIdentifier(name)
+		assert.True(t, len(quoted) >= 2 && strings.HasPrefix(quoted, `"`) && strings.HasSuffix(quoted, `"`), quoted)
+		inner := strings.ReplaceAll(quoted[1:len(quoted)-1], `""`, "")
+		assert.NotContains(t, inner, `"`, "identifier %q escaped its quotes as %s", name, quoted)
+	})
+}
@@ -1,7 +1,8 @@
-package syncdaopq
+package syncdaosqltest
 
 import (
 	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao/syncdaosql"
 	"data-sync-tools-go/syncutil"
 	"data-sync-tools-go/testhelper"
 	"testing"
@@ -10,10 +11,10 @@ import (
 	"github.com/twinj/uuid"
 )
 
-func TestSyncFetcher_FindEntitiesForFetchOK(t *testing.T) {
+func testSyncFetcherFindEntitiesForFetchOK(t *testing.T, database Database) {
 	testName := syncutil.GetCallingName()
 	testhelper.StartTest(testName)
-	db, err := createAndVerifyDBConn(testDbUser, testDbPassword, testDbHost, testDbName, testDbPort)
+	db, err := database.Open()
 	if err != nil {
 		t.Error("Failed to connect to database: " + err.Error())
 		return
@@ -32,7 +33,7 @@ func TestSyncFetcher_FindEntitiesForFetchOK(t *testing.T) {
 
 	sessionUUID := uuid.Formatter(uuid.NewV4(), uuid.CleanHyphen)
 	nodeID := "*node-hub"
-	entityFetcher, err := newEntityFetcher(sessionUUID, nodeID, db)
+	entityFetcher, err := syncdaosql.NewConfigRepository(db).CreateEntityFetcher(sessionUUID, nodeID)
 	if err != nil {
 		syncutil.Error(err.Error())
 		t.Error(err.Error())
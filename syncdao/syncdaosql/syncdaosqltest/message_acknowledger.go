@@ -0,0 +1,241 @@
+package syncdaosqltest
+
+import (
+	"database/sql"
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func testAcknowledgerAcknowledgeFastBatch(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	testhelper.RemoveSyncTablesIfNeeded(db)
+	testhelper.CreateSyncTables(db)
+	testhelper.SetupData(db, "profile5")
+
+	sessionID := "my-session-id-1"
+	nodeID := "*node-spoke1"
+	recordID := "911DD745-8916-41C4-9973-F8B38A501602"
+	transactionBindID := "ack-bind-id"
+
+	var recordHash string
+	err = db.QueryRow("select RecordHash from sync_state where RecordId=$1;", recordID).Scan(&recordHash)
+	if err != nil {
+		t.Error("Failed to read sync_state: " + err.Error())
+		return
+	}
+	//Simulate the record having been fetched for the peer under transactionBindID
+	_, err = db.Exec("update sync_peer_state set TransactionBindSendId=$1, ChangedByClient=true, SentSyncState=2 where NodeId=$2 and RecordId=$3;",
+		transactionBindID, nodeID, recordID)
+	if err != nil {
+		t.Error("Failed to update sync_peer_state: " + err.Error())
+		return
+	}
+
+	acknowledger, err := syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageAcknowledger(sessionID, nodeID)
+	if err != nil {
+		t.Error("Failed to create acknowledger: " + err.Error())
+		return
+	}
+	ack := &syncmsg.ProtoSyncEntityMessageResponse{
+		TransactionBindId: proto.String(transactionBindID),
+		Result:            syncmsg.SyncEntityMessageResponseResult_OK.Enum(),
+		ResultMsg:         proto.String(""),
+		Items: []*syncmsg.ProtoSyncDataMessagesResponse{
+			&syncmsg.ProtoSyncDataMessagesResponse{
+				EntityPluralName: proto.String("Contacts"),
+				Msgs: []*syncmsg.ProtoSyncDataMessageResponse{
+					&syncmsg.ProtoSyncDataMessageResponse{
+						RecordId:     proto.String(recordID),
+						RequestHash:  proto.String(recordHash),
+						ResponseHash: proto.String(recordHash),
+						SyncState:    syncmsg.AckSyncStateEnum_AckFastBatch.Enum(),
+					},
+				},
+			},
+		},
+	}
+	answer := acknowledger.Acknowledge(ack)
+	if answer.GetResult() != syncmsg.SyncEntityMessageResponseResult_OK {
+		t.Errorf("Result not expected '%v'. it's '%v'", syncmsg.SyncEntityMessageResponseResult_OK, answer)
+		return
+	}
+
+	var (
+		peerLastKnownHash     sql.NullString
+		sentSyncState         int32
+		changedByClient       bool
+		transactionBindSendID sql.NullString
+	)
+	err = db.QueryRow("select PeerLastKnownHash, SentSyncState, ChangedByClient, TransactionBindSendId from sync_peer_state where NodeId=$1 and RecordId=$2;",
+		nodeID, recordID).Scan(&peerLastKnownHash, &sentSyncState, &changedByClient, &transactionBindSendID)
+	if err != nil {
+		t.Error("Failed to read sync_peer_state: " + err.Error())
+		return
+	}
+	if peerLastKnownHash.String != recordHash {
+		t.Errorf("PeerLastKnownHash not expected '%v'. it's '%v'", recordHash, peerLastKnownHash.String)
+	}
+	if sentSyncState != int32(syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer) {
+		t.Errorf("SentSyncState not expected '%v'. it's '%v'", syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, sentSyncState)
+	}
+	if changedByClient {
+		t.Error("Expected ChangedByClient to be cleared")
+	}
+	if transactionBindSendID.Valid {
+		t.Errorf("Expected TransactionBindSendId to be cleared. it's '%v'", transactionBindSendID.String)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func testAcknowledgerAcknowledgeFailingRecord(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	testhelper.RemoveSyncTablesIfNeeded(db)
+	testhelper.CreateSyncTables(db)
+	testhelper.SetupData(db, "profile5")
+
+	sessionID := "my-session-id-1"
+	nodeID := "*node-spoke1"
+	recordIDs := []string{"911DD745-8916-41C4-9973-F8B38A501602", "0934A378-DEDB-4207-B99C-DD0D61DC59BC"}
+	transactionBindID := "ack-bind-id"
+
+	recordHashes := []string{}
+	peerLastKnownHashes := []sql.NullString{}
+	for _, recordID := range recordIDs {
+		var (
+			recordHash        string
+			peerLastKnownHash sql.NullString
+		)
+		err = db.QueryRow("select RecordHash from sync_state where RecordId=$1;", recordID).Scan(&recordHash)
+		if err != nil {
+			t.Error("Failed to read sync_state: " + err.Error())
+			return
+		}
+		err = db.QueryRow("select PeerLastKnownHash from sync_peer_state where NodeId=$1 and RecordId=$2;", nodeID, recordID).Scan(&peerLastKnownHash)
+		if err != nil {
+			t.Error("Failed to read sync_peer_state: " + err.Error())
+			return
+		}
+		recordHashes = append(recordHashes, recordHash)
+		peerLastKnownHashes = append(peerLastKnownHashes, peerLastKnownHash)
+		//Simulate the record having been fetched for the peer under transactionBindID
+		_, err = db.Exec("update sync_peer_state set TransactionBindSendId=$1, ChangedByClient=true, SentSyncState=2 where NodeId=$2 and RecordId=$3;",
+			transactionBindID, nodeID, recordID)
+		if err != nil {
+			t.Error("Failed to update sync_peer_state: " + err.Error())
+			return
+		}
+	}
+
+	acknowledger, err := syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageAcknowledger(sessionID, nodeID)
+	if err != nil {
+		t.Error("Failed to create acknowledger: " + err.Error())
+		return
+	}
+	ackMsg := func(index int) *syncmsg.ProtoSyncDataMessageResponse {
+		return &syncmsg.ProtoSyncDataMessageResponse{
+			RecordId:     proto.String(recordIDs[index]),
+			RequestHash:  proto.String(recordHashes[index]),
+			ResponseHash: proto.String(recordHashes[index]),
+			SyncState:    syncmsg.AckSyncStateEnum_AckFastBatch.Enum(),
+		}
+	}
+	ack := func(msgs ...*syncmsg.ProtoSyncDataMessageResponse) *syncmsg.ProtoSyncEntityMessageResponse {
+		return &syncmsg.ProtoSyncEntityMessageResponse{
+			TransactionBindId: proto.String(transactionBindID),
+			Result:            syncmsg.SyncEntityMessageResponseResult_OK.Enum(),
+			ResultMsg:         proto.String(""),
+			Items: []*syncmsg.ProtoSyncDataMessagesResponse{
+				&syncmsg.ProtoSyncDataMessagesResponse{
+					EntityPluralName: proto.String("Contacts"),
+					Msgs:             msgs,
+				},
+			},
+		}
+	}
+
+	//The peer merged the second record but its version cannot be read, failing the acknowledgement mid-batch
+	failingMsg := ackMsg(1)
+	failingMsg.SyncState = syncmsg.AckSyncStateEnum_AckRecordLevelConflictResolvedSeparateFieldsChanged.Enum()
+	failingMsg.ResponseHash = proto.String("merged-hash")
+	failingMsg.RecordData = []byte{0xff, 0xff, 0xff}
+	answer := acknowledger.Acknowledge(ack(ackMsg(0), failingMsg))
+	if answer.GetResult() != syncmsg.SyncEntityMessageResponseResult_Error {
+		t.Errorf("Result not expected '%v'. it's '%v'", syncmsg.SyncEntityMessageResponseResult_Error, answer)
+		return
+	}
+	for index, recordID := range recordIDs {
+		var (
+			peerLastKnownHash     sql.NullString
+			transactionBindSendID sql.NullString
+		)
+		err = db.QueryRow("select PeerLastKnownHash, TransactionBindSendId from sync_peer_state where NodeId=$1 and RecordId=$2;",
+			nodeID, recordID).Scan(&peerLastKnownHash, &transactionBindSendID)
+		if err != nil {
+			t.Error("Failed to read sync_peer_state: " + err.Error())
+			return
+		}
+		if peerLastKnownHash != peerLastKnownHashes[index] {
+			t.Errorf("Expected PeerLastKnownHash of record '%v' to be left as '%v'. it's '%v'", recordID, peerLastKnownHashes[index], peerLastKnownHash)
+		}
+		if transactionBindSendID.String != transactionBindID {
+			t.Errorf("Expected record '%v' to be left under transactionBindId '%v'. it's '%v'", recordID, transactionBindID, transactionBindSendID)
+		}
+	}
+
+	//Acknowledging the response again applies it once
+	for _, expectedMsg := range []string{"2 records acknowledged", "0 records acknowledged"} {
+		answer = acknowledger.Acknowledge(ack(ackMsg(0), ackMsg(1)))
+		if answer.GetResult() != syncmsg.SyncEntityMessageResponseResult_OK {
+			t.Errorf("Result not expected '%v'. it's '%v'", syncmsg.SyncEntityMessageResponseResult_OK, answer)
+			return
+		}
+		if answer.GetResultMsg() != expectedMsg {
+			t.Errorf("ResultMsg not expected '%v'. it's '%v'", expectedMsg, answer.GetResultMsg())
+		}
+		for index, recordID := range recordIDs {
+			var peerLastKnownHash sql.NullString
+			err = db.QueryRow("select PeerLastKnownHash from sync_peer_state where NodeId=$1 and RecordId=$2;", nodeID, recordID).Scan(&peerLastKnownHash)
+			if err != nil {
+				t.Error("Failed to read sync_peer_state: " + err.Error())
+				return
+			}
+			if peerLastKnownHash.String != recordHashes[index] {
+				t.Errorf("PeerLastKnownHash of record '%v' not expected '%v'. it's '%v'", recordID, recordHashes[index], peerLastKnownHash.String)
+			}
+		}
+	}
+
+	testhelper.EndTest(testName)
+}
@@ -1,9 +1,10 @@
-package syncdaopq
+package syncdaosqltest
 
 import (
 	"bytes"
 	"database/sql"
 	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao/syncdaosql"
 	"data-sync-tools-go/syncmsg"
 	"data-sync-tools-go/syncutil"
 	"data-sync-tools-go/testhelper"
@@ -14,19 +15,13 @@ import (
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
 	"github.com/twinj/uuid"
 )
 
 func setupSyncFetcherTestFetcherOKMsgSpread(db *sql.DB, sessionBindID string) {
-	// teardownDatabaseObj()
-	// dbFactory, err := NewPostgresSQLDaosFactory(testDbUser, testDbPassword, testDbHost, testDbName, testDbPort)
-	// if err != nil {
-	// 	panic(err)
-	// }
-	// syncdao.DefaultDaos = dbFactory
-
 	now := time.Now()
-	timeString := now.Format(SQLDateFormat)
+	timeString := now.Format(sqlDateFormat)
 
 	//Custom
 	sqlTemplate := template.Must(template.New("sqlTemplate").Parse(sqlTemplateFetcherOKMsgSpread))
@@ -113,7 +108,7 @@ func setupSyncFetcherTestFetcherOKMsgSpread(db *sql.DB, sessionBindID string) {
 	syncutil.Info("Created setupSyncFetcherTestFetcherOKMsgSpread sample data.")
 }
 
-func TestSyncFetcher_TestBinary(t *testing.T) {
+func testSyncFetcherBinary(t *testing.T, database Database) {
 	creator := syncmsg.NewCreator()
 	recordID := "record 06"
 	expectedRecord := &syncmsg.ProtoRecord{
@@ -147,7 +142,7 @@ func TestSyncFetcher_TestBinary(t *testing.T) {
 		UPDATE sync_state SET RecordData=$1 WHERE EntitySingularName=$2 AND RecordId=$3;
 		`
 	*/
-	db, err := createAndVerifyDBConn(testDbUser, testDbPassword, testDbHost, testDbName, testDbPort)
+	db, err := database.Open()
 	if err != nil {
 		t.Error("Failed to connect to database: " + err.Error())
 		return
@@ -201,10 +196,10 @@ func TestSyncFetcher_TestBinary(t *testing.T) {
 	//syncutil.Info("Created setupSyncFetcherTestFetcherOKMsgSpread sample data. processed=", rowsAffected, ",recordHex=", recordHex)
 }
 
-func TestSyncFetcher_TestFetcherOKMsgSpread(t *testing.T) {
+func testSyncFetcherFetcherOKMsgSpread(t *testing.T, database Database) {
 	testName := syncutil.GetCallingName()
 	testhelper.StartTest(testName)
-	db, err := createAndVerifyDBConn(testDbUser, testDbPassword, testDbHost, testDbName, testDbPort)
+	db, err := database.Open()
 	if err != nil {
 		t.Error("Failed to connect to database: " + err.Error())
 		return
@@ -224,13 +219,14 @@ func TestSyncFetcher_TestFetcherOKMsgSpread(t *testing.T) {
 	setupSyncFetcherTestFetcherOKMsgSpread(db, sessionUUID)
 
 	//var fetcher syncapi.SyncMessagesFetcher
-	fetcher, err := newMessageFetcher(sessionUUID, nodeID, db)
+	//The byte limit stops each fetch before the count limit does
+	fetcher, err := syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageFetcher(sessionUUID, nodeID, syncapi.FetchLimits{MaxGroupBytesSize: 50, MaxMsgs: 10})
 	if err != nil {
 		syncutil.Error(err.Error())
 		t.Error(err.Error())
 	}
 	/*
-		fetcher := PostgresSQLSyncMessagesFetcher{
+		fetcher := sqlMessageFetcher{
 			SyncMessagesFetcherType: syncapi.SyncMessagesFetcherType{
 				SessionID:         sessionUUID,
 				PairID:            pairUUID,
@@ -277,24 +273,24 @@ func TestSyncFetcher_TestFetcherOKMsgSpread(t *testing.T) {
 		t.Error(msg)
 		return
 	}
-	//msgs 2
+	//msgs 2: 'record 05' of B would take the fetch past 50 bytes
 	answer, err = fetcher.Fetch(entities, changeType)
 	if err != nil {
 		syncutil.Error(err.Error())
 		t.Error(err.Error())
 	}
-	if len(answer.Request.Items) != 2 {
-		msg := fmt.Sprintf("Should have found exactly 2 item, instead found %v items.", len(answer.Request.Items))
+	if len(answer.Request.Items) != 1 {
+		msg := fmt.Sprintf("Should have found exactly 1 item, instead found %v items.", len(answer.Request.Items))
 		t.Error(msg)
 		return
 	}
-	msgsCount = (len(answer.Request.Items[0].Msgs) + len(answer.Request.Items[1].Msgs))
-	if msgsCount != 4 {
-		msg := fmt.Sprintf("Should have found 4 msg, instead found %v msgs", msgsCount)
+	msgsCount = len(answer.Request.Items[0].Msgs)
+	if msgsCount != 3 {
+		msg := fmt.Sprintf("Should have found 3 msg, instead found %v msgs", msgsCount)
 		t.Error(msg)
 		return
 	}
-	//msgs 3
+	//msgs 3: 'record 07' of B would take the fetch past 50 bytes
 	answer, err = fetcher.Fetch(entities, changeType)
 	if err != nil {
 		syncutil.Error(err.Error())
@@ -306,22 +302,39 @@ func TestSyncFetcher_TestFetcherOKMsgSpread(t *testing.T) {
 		return
 	}
 
+	msgsCount = len(answer.Request.Items[0].Msgs)
+	if msgsCount != 2 {
+		msg := fmt.Sprintf("Should have found 2 msg, instead found %v msgs", msgsCount)
+		t.Error(msg)
+		return
+	}
+
 	//Sample a record to make sure the response msgs parse OK.
 	actualMsg3Record := &syncmsg.ProtoRecord{}
 	//syncutil.Info(entityMsgRequest)
-	//syncutil.Debug(entityMsgRequest.Items[0].Msgs[0].RecordData)
-	err = proto.Unmarshal(answer.Request.Items[0].Msgs[0].RecordData, actualMsg3Record)
+	//syncutil.Debug(entityMsgRequest.Items[0].Msgs[1].RecordData)
+	err = proto.Unmarshal(answer.Request.Items[0].Msgs[1].RecordData, actualMsg3Record)
 	if err != nil {
 		t.Errorf("Error decoding response: %s", err.Error())
 	}
-
+	//msgs 4
+	answer, err = fetcher.Fetch(entities, changeType)
+	if err != nil {
+		syncutil.Error(err.Error())
+		t.Error(err.Error())
+	}
+	if len(answer.Request.Items) != 1 {
+		msg := fmt.Sprintf("Should have found exactly 1 item, instead found %v items.", len(answer.Request.Items))
+		t.Error(msg)
+		return
+	}
 	msgsCount = len(answer.Request.Items[0].Msgs)
-	if msgsCount != 2 {
-		msg := fmt.Sprintf("Should have found 2 msg, instead found %v msgs", msgsCount)
+	if msgsCount != 1 {
+		msg := fmt.Sprintf("Should have found 1 msg, instead found %v msgs", msgsCount)
 		t.Error(msg)
 		return
 	}
-	//msgs 4
+	//msgs 4b
 	answer, err = fetcher.Fetch(entities, changeType)
 	if err != nil {
 		syncutil.Error(err.Error())
@@ -383,7 +396,60 @@ func TestSyncFetcher_TestFetcherOKMsgSpread(t *testing.T) {
 	testhelper.EndTest(testName)
 }
 
-func TestSyncFetcher_TestFetcherOKNoMsgs(t *testing.T) {
+func testSyncFetcherFetcherMaxMsgs(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	sessionUUID := uuid.Formatter(uuid.NewV4(), uuid.CleanHyphen)
+	setupSyncFetcherTestFetcherOKMsgSpread(db, sessionUUID)
+
+	fetcher, err := syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageFetcher(sessionUUID, "*node-hub", syncapi.FetchLimits{MaxGroupBytesSize: 1000, MaxMsgs: 2})
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	entities := []syncapi.EntityNameItem{
+		syncapi.EntityNameItem{SingularName: "A", PluralName: "A"},
+		syncapi.EntityNameItem{SingularName: "B", PluralName: "B"},
+		syncapi.EntityNameItem{SingularName: "C", PluralName: "C"},
+	}
+	expectedRecordIDs := [][]string{
+		[]string{"record 01", "record 02"},
+		[]string{"record 03", "record 04"},
+		[]string{"record 05", "record 06"},
+		[]string{"record 07"},
+		[]string{},
+	}
+	for fetchIndex, expected := range expectedRecordIDs {
+		answer, err := fetcher.Fetch(entities, syncapi.ProcessSyncChangeEnumAddOrUpdate)
+		if err != nil {
+			t.Error(err.Error())
+			return
+		}
+		actual := []string{}
+		for _, item := range answer.Request.Items {
+			for _, msg := range item.Msgs {
+				actual = append(actual, msg.GetRecordId())
+			}
+		}
+		assert.Equal(t, expected, actual, "fetch %v", fetchIndex+1)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func testSyncFetcherFetcherOKNoMsgs(t *testing.T, database Database) {
 	testName := syncutil.GetCallingName()
 	testhelper.StartTest(testName)
 	syncutil.Info("\n\nFINISH ME\n\n")
@@ -392,53 +458,53 @@ func TestSyncFetcher_TestFetcherOKNoMsgs(t *testing.T) {
 
 var sqlTemplateFetcherOKMsgSpread = `
 --sync_state-1
-INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('A','record 01','Demo Model 1','some-hash-A','` + dummyData + `',50,'false','{{.NowString}}');
+INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('A','record 01','Demo Model 1','some-hash-A','` + dummyData + `',50,false,'{{.NowString}}');
 --sync_state-2
-INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('A','record 02','Demo Model 1','some-hash-B','` + dummyData + `',10,'false','{{.NowString}}');
+INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('A','record 02','Demo Model 1','some-hash-B','` + dummyData + `',10,false,'{{.NowString}}');
 --sync_state-3
-INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('A','record 03','Demo Model 1','some-hash-C','` + dummyData + `',10,'false','{{.NowString}}');
+INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('A','record 03','Demo Model 1','some-hash-C','` + dummyData + `',10,false,'{{.NowString}}');
 --sync_state-4
-INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('A','record 04','Demo Model 1','some-hash-D','` + dummyData + `',29,'false','{{.NowString}}');
+INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('A','record 04','Demo Model 1','some-hash-D','` + dummyData + `',29,false,'{{.NowString}}');
 --sync_state-5
-INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('B','record 05','Demo Model 1','some-hash-E','` + dummyData + `',2,'false','{{.NowString}}');
+INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('B','record 05','Demo Model 1','some-hash-E','` + dummyData + `',2,false,'{{.NowString}}');
 --sync_state-6
-INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('B','record 06','Demo Model 1','some-hash-F','` + dummyData + `',20,'false','{{.NowString}}');
+INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('B','record 06','Demo Model 1','some-hash-F','` + dummyData + `',20,false,'{{.NowString}}');
 --sync_state-7
-INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('B','record 07','Demo Model 1','some-hash-G','` + dummyData + `',30,'false','{{.NowString}}');
+INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('B','record 07','Demo Model 1','some-hash-G','` + dummyData + `',30,false,'{{.NowString}}');
 --sync_state-8
-INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('A','record 08','Demo Model 1','some-hash-H','` + dummyData + `',50,'true','{{.NowString}}');
+INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('A','record 08','Demo Model 1','some-hash-H','` + dummyData + `',50,true,'{{.NowString}}');
 --sync_state-9
-INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('B','record 09','Demo Model 1','some-hash-I','` + dummyData + `',20,'true','{{.NowString}}');
+INSERT INTO sync_state (EntitySingularName,RecordId,DataVersionName,RecordHash,RecordData,RecordBytesSize,IsDelete,RecordCreated) VALUES ('B','record 09','Demo Model 1','some-hash-I','` + dummyData + `',20,true,'{{.NowString}}');
 
 --sync_peer_state
 --//nodeToFetchId add/update
 --SyncPeerState-1 recordId1
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','A','record 01','{{.SessionBindID}}',NULL,NULL,'some-hash-A',NULL,'false',1,'true',50,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','A','record 01','{{.SessionBindID}}',NULL,NULL,'some-hash-A',NULL,false,1,true,50,'{{.NowString}}');
 --SyncPeerState-2 record2
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','A','record 02','{{.SessionBindID}}',NULL,NULL,'some-hash-B',NULL,'false',1,'true',10,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','A','record 02','{{.SessionBindID}}',NULL,NULL,'some-hash-B',NULL,false,1,true,10,'{{.NowString}}');
 --SyncPeerState-3 record3
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','A','record 03','{{.SessionBindID}}',NULL,NULL,'some-hash-C',NULL,'false',1,'true',10,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','A','record 03','{{.SessionBindID}}',NULL,NULL,'some-hash-C',NULL,false,1,true,10,'{{.NowString}}');
 --SyncPeerState-4 record4
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','A','record 04','{{.SessionBindID}}',NULL,NULL,'some-hash-D',NULL,'false',1,'true',29,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','A','record 04','{{.SessionBindID}}',NULL,NULL,'some-hash-D',NULL,false,1,true,29,'{{.NowString}}');
 --SyncPeerState-5 record5
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','B','record 05','{{.SessionBindID}}',NULL,NULL,'some-hash-E',NULL,'false',1,'true',2,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','B','record 05','{{.SessionBindID}}',NULL,NULL,'some-hash-E',NULL,false,1,true,2,'{{.NowString}}');
 --SyncPeerState-6 record6
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','B','record 06','{{.SessionBindID}}',NULL,NULL,'some-hash-F',NULL,'false',1,'true',20,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','B','record 06','{{.SessionBindID}}',NULL,NULL,'some-hash-F',NULL,false,1,true,20,'{{.NowString}}');
 --SyncPeerState-7 record7
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','B','record 07','{{.SessionBindID}}',NULL,NULL,'some-hash-G',NULL,'false',1,'true',30,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','B','record 07','{{.SessionBindID}}',NULL,NULL,'some-hash-G',NULL,false,1,true,30,'{{.NowString}}');
 --nodeToFetchId delete
 --SyncPeerState-8 record8
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,IsDelete,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','A','record 08','{{.SessionBindID}}',NULL,NULL,'some-hash-H',NULL,'false','true',1,'true',50,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,IsDelete,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','A','record 08','{{.SessionBindID}}',NULL,NULL,'some-hash-H',NULL,false,true,1,true,50,'{{.NowString}}');
 --SyncPeerState-9 record9
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,IsDelete,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','B','record 09','{{.SessionBindID}}',NULL,NULL,'some-hash-I',NULL,'false','true',1,'true',20,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,IsDelete,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-hub','B','record 09','{{.SessionBindID}}',NULL,NULL,'some-hash-I',NULL,false,true,1,true,20,'{{.NowString}}');
 --nodeToIgnoreId add/update
 --SyncPeerState-10 record1
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-spoke2','A','record 01','{{.SessionBindID}}',NULL,NULL,'some-hash-A',NULL,'false',1,'true',50,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-spoke2','A','record 01','{{.SessionBindID}}',NULL,NULL,'some-hash-A',NULL,false,1,true,50,'{{.NowString}}');
 --SyncPeerState-11 recordId6
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-spoke2','B','record 06','{{.SessionBindID}}',NULL,NULL,'some-hash-F',NULL,'false',1,'true',20,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-spoke2','B','record 06','{{.SessionBindID}}',NULL,NULL,'some-hash-F',NULL,false,1,true,20,'{{.NowString}}');
 --nodeToIgnoreId delete
 --SyncPeerState-12 recordId8
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,IsDelete,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-spoke2','A','record 08','{{.SessionBindID}}',NULL,NULL,'some-hash-H',NULL,'false','true',1,'true',50,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,IsDelete,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-spoke2','A','record 08','{{.SessionBindID}}',NULL,NULL,'some-hash-H',NULL,false,true,1,true,50,'{{.NowString}}');
 --SyncPeerState-13 recordId9
-INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,IsDelete,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-spoke2','B','record 09','{{.SessionBindID}}',NULL,NULL,'some-hash-I',NULL,'false','true',1,'true',20,'{{.NowString}}');
+INSERT INTO sync_peer_state (NodeId,EntitySingularName,RecordId,SessionBindId,TransactionBindReceiveId, TransactionBindSendId,SentLastKnownHash,PeerLastKnownHash,IsConflict,IsDelete,SentSyncState,ChangedByClient,RecordBytesSize,LastUpdated) VALUES ('*node-spoke2','B','record 09','{{.SessionBindID}}',NULL,NULL,'some-hash-I',NULL,false,true,1,true,20,'{{.NowString}}');
 `
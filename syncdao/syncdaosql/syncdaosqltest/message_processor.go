@@ -0,0 +1,618 @@
+package syncdaosqltest
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func testProcessorFastBatchOK(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	testhelper.RemoveSyncTablesIfNeeded(db)
+	testhelper.CreateSyncTables(db)
+	testhelper.SetupData(db, "profile5")
+
+	sessionID := "my-session-id-1"
+	nodeIDToProcess := "*node-spoke1"
+
+	entitiesByPluralName := map[string]syncapi.EntityNameItem{
+		"As":       syncapi.EntityNameItem{SingularName: "A", PluralName: "As"},
+		"Bs":       syncapi.EntityNameItem{SingularName: "B", PluralName: "Bs"},
+		"Contacts": syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+
+	var msgProcessor syncapi.MessageProcessing
+	msgProcessor, err = syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageProcessor(sessionID, nodeIDToProcess, entitiesByPluralName)
+	if err != nil {
+		msg := "Failed to Create Msg Processor : " + err.Error()
+		syncutil.Error(msg)
+		t.Error(msg)
+		return
+	}
+
+	creator := syncmsg.NewCreator()
+
+	lastContact4 := testhelper.Contact{ContactID: "0934A378-DEDB-4207-B99C-DD0D61DC59BC", DateOfBirthAsUTC: creator.FormatTimeFromString("1987-05-28 00:00:00.000"),
+		FirstName: "Mindy", HeightFt: 5, HeightInch: 1.0, LastName: "Johnson", PreferredHeight: 2}
+	lastContactSyncPackage4, err := testhelper.CreateRecordAndSupport(lastContact4, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, "")
+	if err != nil {
+		syncutil.Fatal("Marshaling error: ", err)
+		return //This return will never get called as the above panics
+	}
+
+	contact4 := testhelper.Contact{ContactID: "0934A378-DEDB-4207-B99C-DD0D61DC59BC", DateOfBirthAsUTC: creator.FormatTimeFromString("1987-05-28 00:00:00.000"),
+		FirstName: "Mindy", HeightFt: 5, HeightInch: 2.0, LastName: "Johnson", PreferredHeight: 1}
+	contactSyncPackage4, err := testhelper.CreateRecordAndSupport(contact4, true, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, lastContactSyncPackage4.RecordSha256Hex)
+	if err != nil {
+		syncutil.Fatal("Marshaling error: ", err)
+		return //This return will never get called as the above panics
+	}
+
+	syncutil.Debug("contactSyncPackage4.RecordSha256Hex:", contactSyncPackage4.RecordSha256Hex, "contactSyncPackage4.PeerLastKnownHash", contactSyncPackage4.PeerLastKnownHash)
+
+	contact6 := testhelper.Contact{ContactID: "151EFA13-A3AD-4C18-A2CE-9D66D0AED112", DateOfBirthAsUTC: creator.FormatTimeFromString("1988-01-23 00:00:00.000"),
+		FirstName: "Jill", HeightFt: 5, HeightInch: 3.0, LastName: "Anderson", PreferredHeight: 1}
+	contactSyncPackage6, err := testhelper.CreateRecordAndSupport(contact6, true, syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer, "")
+	if err != nil {
+		syncutil.Fatal("Marshaling error: ", err)
+		return //This return will never get called as the above panics
+	}
+
+	request1 := &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(false),
+		TransactionBindId: proto.String("some-bind-id"),
+		Items: []*syncmsg.ProtoSyncDataMessagesRequest{
+			&syncmsg.ProtoSyncDataMessagesRequest{
+				EntityPluralName: proto.String("Contacts"),
+				Msgs: []*syncmsg.ProtoSyncDataMessageRequest{
+					&syncmsg.ProtoSyncDataMessageRequest{
+						RecordId:          proto.String(contact4.ContactID),
+						RecordHash:        proto.String(contactSyncPackage4.RecordSha256Hex),
+						LastKnownPeerHash: proto.String(contactSyncPackage4.PeerLastKnownHash),
+						SentSyncState:     syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer.Enum(),
+						RecordBytesSize:   proto.Uint32(123),
+						RecordData:        contactSyncPackage4.RecordBytes,
+					},
+					&syncmsg.ProtoSyncDataMessageRequest{
+						RecordId:          proto.String(contact6.ContactID),
+						RecordHash:        proto.String(contactSyncPackage6.RecordSha256Hex),
+						LastKnownPeerHash: nil,
+						SentSyncState:     syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer.Enum(),
+						RecordBytesSize:   proto.Uint32(123),
+						RecordData:        contactSyncPackage6.RecordBytes,
+					},
+				},
+			},
+		},
+	}
+	syncutil.Debug("recordId", "0934A378-DEDB-4207-B99C-DD0D61DC59BC", "contactSyncPackage4.RecordSha256Hex", contactSyncPackage4.RecordSha256Hex, "lastContactSyncPackage4.RecordSha256Hex", lastContactSyncPackage4.RecordSha256Hex, "")
+
+	var response1 *syncmsg.ProtoSyncEntityMessageResponse
+	response1 = msgProcessor.Process(request1)
+	syncutil.Debug("response1", response1)
+
+	expectedMsg := "All records are fast batch"
+	if *response1.ResultMsg != expectedMsg {
+		t.Errorf("Result not expected '%v'. it's '%v'", expectedMsg, response1)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func testProcessorRecordLevelConflict(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	testhelper.RemoveSyncTablesIfNeeded(db)
+	testhelper.CreateSyncTables(db)
+	testhelper.SetupData(db, "profile5")
+
+	sessionID := "my-session-id-1"
+	nodeIDToProcess := "*node-spoke1"
+
+	entitiesByPluralName := map[string]syncapi.EntityNameItem{
+		"Contacts": syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+
+	var msgProcessor syncapi.MessageProcessing
+	msgProcessor, err = syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageProcessor(sessionID, nodeIDToProcess, entitiesByPluralName)
+	if err != nil {
+		msg := "Failed to Create Msg Processor : " + err.Error()
+		syncutil.Error(msg)
+		t.Error(msg)
+		return
+	}
+
+	creator := syncmsg.NewCreator()
+
+	//Locally (see profile5) 'Adins' was changed to 'Adkins' after the peer last saw the record. The peer changed
+	//the height instead, so the record reaches the record level path.
+	lastContact2 := testhelper.Contact{ContactID: "911DD745-8916-41C4-9973-F8B38A501602", DateOfBirthAsUTC: creator.FormatTimeFromString("1994-07-10 00:00:00.000"),
+		FirstName: "Henry", HeightFt: 5, HeightInch: 5.5, LastName: "Adins", PreferredHeight: 2}
+	lastContactSyncPackage2, err := testhelper.CreateRecordAndSupport(lastContact2, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, "")
+	if err != nil {
+		syncutil.Fatal("Marshaling error: ", err)
+		return //This return will never get called as the above panics
+	}
+
+	contact2 := testhelper.Contact{ContactID: "911DD745-8916-41C4-9973-F8B38A501602", DateOfBirthAsUTC: creator.FormatTimeFromString("1994-07-10 00:00:00.000"),
+		FirstName: "Henry", HeightFt: 6, HeightInch: 5.5, LastName: "Adins", PreferredHeight: 2}
+	contactSyncPackage2, err := testhelper.CreateRecordAndSupport(contact2, true, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, lastContactSyncPackage2.RecordSha256Hex)
+	if err != nil {
+		syncutil.Fatal("Marshaling error: ", err)
+		return //This return will never get called as the above panics
+	}
+
+	request1 := &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(false),
+		TransactionBindId: proto.String("some-bind-id"),
+		Items: []*syncmsg.ProtoSyncDataMessagesRequest{
+			&syncmsg.ProtoSyncDataMessagesRequest{
+				EntityPluralName: proto.String("Contacts"),
+				Msgs: []*syncmsg.ProtoSyncDataMessageRequest{
+					&syncmsg.ProtoSyncDataMessageRequest{
+						RecordId:          proto.String(contact2.ContactID),
+						RecordHash:        proto.String(contactSyncPackage2.RecordSha256Hex),
+						LastKnownPeerHash: proto.String(contactSyncPackage2.PeerLastKnownHash),
+						SentSyncState:     syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer.Enum(),
+						RecordBytesSize:   proto.Uint32(123),
+						RecordData:        contactSyncPackage2.RecordBytes,
+					},
+				},
+			},
+		},
+	}
+
+	var response1 *syncmsg.ProtoSyncEntityMessageResponse
+	response1 = msgProcessor.Process(request1)
+	syncutil.Debug("response1", response1)
+
+	if response1.GetResult() != syncmsg.SyncEntityMessageResponseResult_OK {
+		t.Errorf("Result not expected '%v'. it's '%v'", syncmsg.SyncEntityMessageResponseResult_OK, response1)
+		return
+	}
+	if len(response1.Items) != 1 || len(response1.Items[0].Msgs) != 1 {
+		t.Errorf("Expected one entity with one record in the response. it's '%v'", response1)
+		return
+	}
+	expectedState := syncmsg.AckSyncStateEnum_AckFieldLevelConflictwithNoAutoResolverAvailable
+	if actualState := response1.Items[0].Msgs[0].GetSyncState(); actualState != expectedState {
+		t.Errorf("Sync state not expected '%v'. it's '%v'", expectedState, actualState)
+	}
+
+	var isConflict bool
+	err = db.QueryRow("select IsConflict from sync_peer_state where NodeId=$1 and RecordId=$2;", nodeIDToProcess, contact2.ContactID).Scan(&isConflict)
+	if err != nil {
+		t.Error("Failed to read sync_peer_state: " + err.Error())
+		return
+	}
+	if !isConflict {
+		t.Error("Expected sync_peer_state to be marked as a conflict")
+	}
+
+	conflictRepo := syncdaosql.NewConflictRepository(db, database.Dialect)
+	conflicts, err := conflictRepo.FindConflicts(nodeIDToProcess)
+	if err != nil {
+		t.Error("Failed to find conflicts: " + err.Error())
+		return
+	}
+	if len(conflicts) != 1 || conflicts[0].RecordID != contact2.ContactID {
+		t.Errorf("Expected one conflict for record '%v'. it's '%v'", contact2.ContactID, conflicts)
+		return
+	}
+	if conflicts[0].Remote.RecordHash != contactSyncPackage2.RecordSha256Hex {
+		t.Errorf("Remote hash not expected '%v'. it's '%v'", contactSyncPackage2.RecordSha256Hex, conflicts[0].Remote.RecordHash)
+	}
+
+	recordHash, err := conflictRepo.ResolveConflict(conflicts[0].ConflictID, syncapi.ConflictResolutionChoiceRemote, nil)
+	if err != nil {
+		t.Error("Failed to resolve conflict: " + err.Error())
+		return
+	}
+	if recordHash != contactSyncPackage2.RecordSha256Hex {
+		t.Errorf("Resolved hash not expected '%v'. it's '%v'", contactSyncPackage2.RecordSha256Hex, recordHash)
+	}
+	var (
+		lastName string
+		heightFt int
+	)
+	err = db.QueryRow("select LastName, HeightFt from contacts where ContactId=$1;", contact2.ContactID).Scan(&lastName, &heightFt)
+	if err != nil {
+		t.Error("Failed to read contacts: " + err.Error())
+		return
+	}
+	if lastName != "Adins" || heightFt != 6 {
+		t.Errorf("Expected the remote contact 'Adins' with height 6. it's '%v' with height %v", lastName, heightFt)
+	}
+	_, err = conflictRepo.GetConflict(conflicts[0].ConflictID)
+	if err != syncapi.ErrConflictNotFound {
+		t.Errorf("Expected the conflict to be removed once resolved. it's '%v'", err)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func testProcessorRecordLevelMerge(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	testhelper.RemoveSyncTablesIfNeeded(db)
+	testhelper.CreateSyncTables(db)
+	testhelper.SetupData(db, "profile5")
+
+	sessionID := "my-session-id-1"
+	nodeIDToProcess := "*node-spoke1"
+
+	entitiesByPluralName := map[string]syncapi.EntityNameItem{
+		"Contacts": syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+
+	var msgProcessor syncapi.MessageProcessing
+	msgProcessor, err = syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageProcessor(sessionID, nodeIDToProcess, entitiesByPluralName)
+	if err != nil {
+		msg := "Failed to Create Msg Processor : " + err.Error()
+		syncutil.Error(msg)
+		t.Error(msg)
+		return
+	}
+
+	creator := syncmsg.NewCreator()
+
+	//Locally (see profile5) 'Adins' was changed to 'Adkins' after the peer last saw the record. The peer changed
+	//the height instead. With the common ancestor kept, the separate changes are merged.
+	lastContact2 := testhelper.Contact{ContactID: "911DD745-8916-41C4-9973-F8B38A501602", DateOfBirthAsUTC: creator.FormatTimeFromString("1994-07-10 00:00:00.000"),
+		FirstName: "Henry", HeightFt: 5, HeightInch: 5.5, LastName: "Adins", PreferredHeight: 2}
+	lastContactSyncPackage2, err := testhelper.CreateRecordAndSupport(lastContact2, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, "")
+	if err != nil {
+		syncutil.Fatal("Marshaling error: ", err)
+		return //This return will never get called as the above panics
+	}
+
+	contact2 := testhelper.Contact{ContactID: "911DD745-8916-41C4-9973-F8B38A501602", DateOfBirthAsUTC: creator.FormatTimeFromString("1994-07-10 00:00:00.000"),
+		FirstName: "Henry", HeightFt: 6, HeightInch: 5.5, LastName: "Adins", PreferredHeight: 2}
+	contactSyncPackage2, err := testhelper.CreateRecordAndSupport(contact2, true, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, lastContactSyncPackage2.RecordSha256Hex)
+	if err != nil {
+		syncutil.Fatal("Marshaling error: ", err)
+		return //This return will never get called as the above panics
+	}
+
+	_, err = db.Exec("insert into sync_state_ancestor (EntitySingularName, RecordId, RecordHash, RecordData) values ('Contact', $1, $2, $3);",
+		lastContact2.ContactID, lastContactSyncPackage2.RecordSha256Hex, lastContactSyncPackage2.RecordHex)
+	if err != nil {
+		t.Error("Failed to add ancestor record: " + err.Error())
+		return
+	}
+
+	request1 := &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(false),
+		TransactionBindId: proto.String("some-bind-id"),
+		Items: []*syncmsg.ProtoSyncDataMessagesRequest{
+			&syncmsg.ProtoSyncDataMessagesRequest{
+				EntityPluralName: proto.String("Contacts"),
+				Msgs: []*syncmsg.ProtoSyncDataMessageRequest{
+					&syncmsg.ProtoSyncDataMessageRequest{
+						RecordId:          proto.String(contact2.ContactID),
+						RecordHash:        proto.String(contactSyncPackage2.RecordSha256Hex),
+						LastKnownPeerHash: proto.String(contactSyncPackage2.PeerLastKnownHash),
+						SentSyncState:     syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer.Enum(),
+						RecordBytesSize:   proto.Uint32(123),
+						RecordData:        contactSyncPackage2.RecordBytes,
+					},
+				},
+			},
+		},
+	}
+
+	var response1 *syncmsg.ProtoSyncEntityMessageResponse
+	response1 = msgProcessor.Process(request1)
+	syncutil.Debug("response1", response1)
+
+	if response1.GetResult() != syncmsg.SyncEntityMessageResponseResult_OK {
+		t.Errorf("Result not expected '%v'. it's '%v'", syncmsg.SyncEntityMessageResponseResult_OK, response1)
+		return
+	}
+	if len(response1.Items) != 1 || len(response1.Items[0].Msgs) != 1 {
+		t.Errorf("Expected one entity with one record in the response. it's '%v'", response1)
+		return
+	}
+	expectedState := syncmsg.AckSyncStateEnum_AckRecordLevelConflictResolvedSeparateFieldsChanged
+	if actualState := response1.Items[0].Msgs[0].GetSyncState(); actualState != expectedState {
+		t.Errorf("Sync state not expected '%v'. it's '%v'", expectedState, actualState)
+	}
+
+	var (
+		lastName string
+		heightFt int
+	)
+	err = db.QueryRow("select LastName, HeightFt from contacts where ContactId=$1;", contact2.ContactID).Scan(&lastName, &heightFt)
+	if err != nil {
+		t.Error("Failed to read contacts: " + err.Error())
+		return
+	}
+	if lastName != "Adkins" || heightFt != 6 {
+		t.Errorf("Expected the merged contact to be 'Adkins' with height 6. it's '%v' with height %v", lastName, heightFt)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func testProcessorFastDelete(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	testhelper.RemoveSyncTablesIfNeeded(db)
+	testhelper.CreateSyncTables(db)
+	testhelper.SetupData(db, "profile5")
+
+	sessionID := "my-session-id-1"
+	nodeIDToProcess := "*node-spoke1"
+
+	entitiesByPluralName := map[string]syncapi.EntityNameItem{
+		"Contacts": syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+
+	var msgProcessor syncapi.MessageProcessing
+	msgProcessor, err = syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageProcessor(sessionID, nodeIDToProcess, entitiesByPluralName)
+	if err != nil {
+		msg := "Failed to Create Msg Processor : " + err.Error()
+		syncutil.Error(msg)
+		t.Error(msg)
+		return
+	}
+
+	creator := syncmsg.NewCreator()
+
+	//The peer deletes the contact both sides last agreed on (see profile5)
+	contact4 := testhelper.Contact{ContactID: "0934A378-DEDB-4207-B99C-DD0D61DC59BC", DateOfBirthAsUTC: creator.FormatTimeFromString("1987-05-28 00:00:00.000"),
+		FirstName: "Mindy", HeightFt: 5, HeightInch: 1.0, LastName: "Johnson", PreferredHeight: 2}
+	contactSyncPackage4, err := testhelper.CreateRecordAndSupport(contact4, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, "")
+	if err != nil {
+		syncutil.Fatal("Marshaling error: ", err)
+		return //This return will never get called as the above panics
+	}
+
+	request1 := &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(true),
+		TransactionBindId: proto.String("some-bind-id"),
+		Items: []*syncmsg.ProtoSyncDataMessagesRequest{
+			&syncmsg.ProtoSyncDataMessagesRequest{
+				EntityPluralName: proto.String("Contacts"),
+				Msgs: []*syncmsg.ProtoSyncDataMessageRequest{
+					&syncmsg.ProtoSyncDataMessageRequest{
+						RecordId:          proto.String(contact4.ContactID),
+						RecordHash:        proto.String(contactSyncPackage4.RecordSha256Hex),
+						LastKnownPeerHash: proto.String(contactSyncPackage4.RecordSha256Hex),
+						SentSyncState:     syncmsg.SentSyncStateEnum_PersistedFastDeleted.Enum(),
+						RecordBytesSize:   proto.Uint32(123),
+						RecordData:        contactSyncPackage4.RecordBytes,
+					},
+				},
+			},
+		},
+	}
+
+	var response1 *syncmsg.ProtoSyncEntityMessageResponse
+	response1 = msgProcessor.Process(request1)
+	syncutil.Debug("response1", response1)
+
+	expectedMsg := "All records are fast batch"
+	if response1.GetResultMsg() != expectedMsg {
+		t.Errorf("Result not expected '%v'. it's '%v'", expectedMsg, response1)
+		return
+	}
+
+	var contactCount int
+	err = db.QueryRow("select count(*) from contacts where ContactId=$1;", contact4.ContactID).Scan(&contactCount)
+	if err != nil {
+		t.Error("Failed to read contacts: " + err.Error())
+		return
+	}
+	if contactCount != 0 {
+		t.Errorf("Expected contact '%v' to be deleted", contact4.ContactID)
+	}
+	var stateIsDelete, peerIsDelete bool
+	err = db.QueryRow("select IsDelete from sync_state where RecordId=$1;", contact4.ContactID).Scan(&stateIsDelete)
+	if err != nil {
+		t.Error("Failed to read sync_state: " + err.Error())
+		return
+	}
+	err = db.QueryRow("select IsDelete from sync_peer_state where NodeId=$1 and RecordId=$2;", nodeIDToProcess, contact4.ContactID).Scan(&peerIsDelete)
+	if err != nil {
+		t.Error("Failed to read sync_peer_state: " + err.Error())
+		return
+	}
+	if !stateIsDelete || !peerIsDelete {
+		t.Errorf("Expected sync_state and sync_peer_state to be marked deleted. They are %v and %v", stateIsDelete, peerIsDelete)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func testProcessorNodeDataVersion(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	testhelper.RemoveSyncTablesIfNeeded(db)
+	testhelper.CreateSyncTables(db)
+	testhelper.SetupData(db, "profile5")
+
+	//'Contact' is moved to the data version of node B, so it is only found through the data version of the node
+	dataVersionName := "Demo Model 2 (orphand node)"
+	nodeIDToProcess := "*node-spoke2"
+	for _, sqlStr := range []string{
+		"update sync_data_entity set DataVersionName=$1 where EntitySingularName='Contact';",
+		"update sync_data_field set DataVersionName=$1 where EntitySingularName='Contact';",
+	} {
+		_, err = db.Exec(sqlStr, dataVersionName)
+		if err != nil {
+			t.Error("Failed to move 'Contact' to data version '" + dataVersionName + "': " + err.Error())
+			return
+		}
+	}
+
+	entitiesByPluralName := map[string]syncapi.EntityNameItem{
+		"Contacts": syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+	msgProcessor, err := syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageProcessor("my-session-id-1", nodeIDToProcess, entitiesByPluralName)
+	if err != nil {
+		t.Error("Failed to Create Msg Processor : " + err.Error())
+		return
+	}
+
+	creator := syncmsg.NewCreator()
+	contact6 := testhelper.Contact{ContactID: "151EFA13-A3AD-4C18-A2CE-9D66D0AED112", DateOfBirthAsUTC: creator.FormatTimeFromString("1988-01-23 00:00:00.000"),
+		FirstName: "Jill", HeightFt: 5, HeightInch: 3.0, LastName: "Anderson", PreferredHeight: 1}
+	contactSyncPackage6, err := testhelper.CreateRecordAndSupport(contact6, true, syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer, "")
+	if err != nil {
+		syncutil.Fatal("Marshaling error: ", err)
+		return //This return will never get called as the above panics
+	}
+	request := &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(false),
+		TransactionBindId: proto.String("some-bind-id"),
+		Items: []*syncmsg.ProtoSyncDataMessagesRequest{
+			&syncmsg.ProtoSyncDataMessagesRequest{
+				EntityPluralName: proto.String("Contacts"),
+				Msgs: []*syncmsg.ProtoSyncDataMessageRequest{
+					&syncmsg.ProtoSyncDataMessageRequest{
+						RecordId:        proto.String(contact6.ContactID),
+						RecordHash:      proto.String(contactSyncPackage6.RecordSha256Hex),
+						SentSyncState:   syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer.Enum(),
+						RecordBytesSize: proto.Uint32(123),
+						RecordData:      contactSyncPackage6.RecordBytes,
+					},
+				},
+			},
+		},
+	}
+	response := msgProcessor.Process(request)
+	syncutil.Debug("response", response)
+
+	expectedMsg := "All records are fast batch"
+	if response.GetResultMsg() != expectedMsg {
+		t.Errorf("Result not expected '%v'. it's '%v'", expectedMsg, response)
+		return
+	}
+	var stateDataVersionName string
+	err = db.QueryRow("select DataVersionName from sync_state where EntitySingularName='Contact' and RecordId=$1;", contact6.ContactID).Scan(&stateDataVersionName)
+	if err != nil {
+		t.Error("Failed to read sync_state: " + err.Error())
+		return
+	}
+	if stateDataVersionName != dataVersionName {
+		t.Errorf("Data version not expected '%v'. it's '%v'", dataVersionName, stateDataVersionName)
+	}
+	var lastName string
+	err = db.QueryRow("select LastName from contacts where ContactId=$1;", contact6.ContactID).Scan(&lastName)
+	if err != nil {
+		t.Error("Failed to read contacts: " + err.Error())
+		return
+	}
+	if lastName != contact6.LastName {
+		t.Errorf("Last name not expected '%v'. it's '%v'", contact6.LastName, lastName)
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func testProcessorUnknownNodeDataVersion(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	testhelper.RemoveSyncTablesIfNeeded(db)
+	testhelper.CreateSyncTables(db)
+	testhelper.SetupData(db, "profile5")
+
+	entitiesByPluralName := map[string]syncapi.EntityNameItem{
+		"Contacts": syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+	msgProcessor, err := syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageProcessor("my-session-id-1", "*node-unknown", entitiesByPluralName)
+	if err != nil {
+		t.Error("Failed to Create Msg Processor : " + err.Error())
+		return
+	}
+	request := &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(false),
+		TransactionBindId: proto.String("some-bind-id"),
+		Items:             []*syncmsg.ProtoSyncDataMessagesRequest{},
+	}
+	response := msgProcessor.Process(request)
+	if response.GetResult() != syncmsg.SyncEntityMessageResponseResult_Error {
+		t.Errorf("Expected an error processing for a node without a data version. Response: %v", response)
+	}
+
+	testhelper.EndTest(testName)
+}
@@ -0,0 +1,155 @@
+package syncdaosqltest
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"fmt"
+	"log"
+	"testing"
+)
+
+func testQueuerQueueOK(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	testhelper.RemoveSyncTablesIfNeeded(db)
+	testhelper.CreateSyncTables(db)
+	testhelper.SetupData(db, "profile3")
+
+	nodeIDToQueue := "*node-spoke1"
+
+	//syncNodeDao := syncdao.DefaultDaos.SyncNodeDao()
+
+	sessionID := "my-session-id-1"
+
+	var msgQueuer syncapi.MessageQueuing
+	msgQueuer, err = syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageQueuer(sessionID, nodeIDToQueue)
+	if err != nil {
+		msg := "Failed to Create Msg Processor : " + err.Error()
+		syncutil.Error(msg)
+		t.Error(msg)
+		return
+	}
+	recordsQueued, err := msgQueuer.Queue(sessionID, nodeIDToQueue)
+	if err != nil {
+		t.Error("Failed to get pair names: " + err.Error())
+		fmt.Println("Error")
+		return
+	}
+	var expectedCount int
+	// TODO(doug4j@gmail.com): Double checkt that this is the correct expected result
+	expectedCount = 5
+	if recordsQueued != expectedCount {
+		log.Printf("Actual Count: %v. Expected count: %v", recordsQueued, expectedCount)
+		t.Error("Records queue count incorrect. Expected ")
+	}
+
+	sqlStr := `
+update sync_peer_state set SessionBindId=null, ChangedByClient ='0'  where NodeId='*node-spoke1';
+update sync_state set RecordHash='hash-2b' where RecordId='*record-2';
+update sync_state set RecordHash='hash-3b' where RecordId='*record-3';`
+
+	_, err = db.Exec(sqlStr)
+	if err != nil {
+		t.Error("Error changing a record: " + err.Error())
+		return
+	}
+
+	recordsQueued, err = msgQueuer.Queue(sessionID, nodeIDToQueue)
+	if err != nil {
+		t.Error("Failed to get pair names: " + err.Error())
+		fmt.Println("Error")
+		return
+	}
+
+	expectedCount = 3
+	if recordsQueued != expectedCount {
+		log.Printf("Actual Count: %v. Expected %v", recordsQueued, expectedCount)
+		t.Error("Records queue count incorrect")
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func testQueuerQueueDeletes(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	testhelper.RemoveSyncTablesIfNeeded(db)
+	testhelper.CreateSyncTables(db)
+	testhelper.SetupData(db, "profile3")
+
+	nodeIDToQueue := "*node-spoke1"
+	sessionID := "my-session-id-1"
+
+	msgQueuer, err := syncdaosql.NewDataRepository(db, database.Dialect).CreateMessageQueuer(sessionID, nodeIDToQueue)
+	if err != nil {
+		t.Error("Failed to Create Msg Queuer : " + err.Error())
+		return
+	}
+	_, err = msgQueuer.Queue(sessionID, nodeIDToQueue)
+	if err != nil {
+		t.Error("Failed to queue: " + err.Error())
+		return
+	}
+
+	//*record-2 was sent to the peer before being deleted, *record-3 never was
+	sqlStr := `
+update sync_peer_state set SessionBindId=null, ChangedByClient ='0'  where NodeId='*node-spoke1';
+update sync_peer_state set SentSyncState=3 where NodeId='*node-spoke1' AND RecordId='*record-2';
+update sync_state set IsDelete=true where RecordId in ('*record-2', '*record-3');`
+	_, err = db.Exec(sqlStr)
+	if err != nil {
+		t.Error("Error deleting records: " + err.Error())
+		return
+	}
+
+	_, err = msgQueuer.Queue(sessionID, nodeIDToQueue)
+	if err != nil {
+		t.Error("Failed to queue: " + err.Error())
+		return
+	}
+	expectedChangedByClient := map[string]bool{"*record-2": true, "*record-3": false}
+	for recordID, expected := range expectedChangedByClient {
+		var isDelete, changedByClient bool
+		err = db.QueryRow("select IsDelete, ChangedByClient from sync_peer_state where NodeId=$1 AND RecordId=$2;", nodeIDToQueue, recordID).Scan(&isDelete, &changedByClient)
+		if err != nil {
+			t.Error("Failed to read sync_peer_state: " + err.Error())
+			return
+		}
+		if !isDelete {
+			t.Errorf("Expected record '%v' to be queued as deleted", recordID)
+		}
+		if changedByClient != expected {
+			t.Errorf("ChangedByClient of record '%v' not expected '%v'. it's '%v'", recordID, expected, changedByClient)
+		}
+	}
+
+	testhelper.EndTest(testName)
+}
@@ -0,0 +1,60 @@
+//Package syncdaosqltest is the test suite of the sql shared by the syncdaosql databases. Every database runs the suite
+//from its own tests with its driver and Dialect, on top of the backend independent suite of syncdaotest.
+package syncdaosqltest
+
+import (
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"database/sql"
+	"encoding/hex"
+	"testing"
+)
+
+//Database is a database to run the suite against.
+type Database struct {
+	Dialect syncdaosql.Dialect
+	//Open opens a connection to the database, which the test closes once done.
+	Open func() (*sql.DB, error)
+}
+
+//sqlDateFormat is the date format of the times written by the tests.
+var sqlDateFormat = "2006-01-02 15:04:05.000"
+
+var dummyData = hex.EncodeToString([]byte("<record data>"))
+
+type sqlTest struct {
+	name string
+	run  func(t *testing.T, database Database)
+}
+
+var sqlTests = []sqlTest{
+	{"SyncPairDaoGetPairByNames", testSyncPairDaoGetPairByNames},
+	{"SyncPairDaoGetNodePairItem", testSyncPairDaoGetNodePairItem},
+	{"SyncPairDaoGetEntityPairItem", testSyncPairDaoGetEntityPairItem},
+	{"SyncPairDaoCreateSyncSessionCloseSyncSession", testSyncPairDaoCreateSyncSessionCloseSyncSession},
+	{"SyncFetcherFindEntitiesForFetchOK", testSyncFetcherFindEntitiesForFetchOK},
+	{"AcknowledgerAcknowledgeFastBatch", testAcknowledgerAcknowledgeFastBatch},
+	{"AcknowledgerAcknowledgeFailingRecord", testAcknowledgerAcknowledgeFailingRecord},
+	{"SyncFetcherBinary", testSyncFetcherBinary},
+	{"SyncFetcherFetcherOKMsgSpread", testSyncFetcherFetcherOKMsgSpread},
+	{"SyncFetcherFetcherMaxMsgs", testSyncFetcherFetcherMaxMsgs},
+	{"SyncFetcherFetcherOKNoMsgs", testSyncFetcherFetcherOKNoMsgs},
+	{"ProcessorFastBatchOK", testProcessorFastBatchOK},
+	{"ProcessorRecordLevelConflict", testProcessorRecordLevelConflict},
+	{"ProcessorRecordLevelMerge", testProcessorRecordLevelMerge},
+	{"ProcessorFastDelete", testProcessorFastDelete},
+	{"ProcessorNodeDataVersion", testProcessorNodeDataVersion},
+	{"ProcessorUnknownNodeDataVersion", testProcessorUnknownNodeDataVersion},
+	{"QueuerQueueOK", testQueuerQueueOK},
+	{"QueuerQueueDeletes", testQueuerQueueDeletes},
+	{"TombstoneCollectTombstones", testTombstoneCollectTombstones},
+}
+
+//Run runs every test of the suite against database.
+func Run(t *testing.T, database Database) {
+	for _, test := range sqlTests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, database)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package syncdaosqltest
+
+import (
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTombstoneCollectTombstones(t *testing.T, database Database) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	db, err := database.Open()
+	if err != nil {
+		t.Error("Failed to connect to database: " + err.Error())
+		return
+	}
+	closeQuietly := func() {
+		err := db.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of db close error. Error: " + err.Error())
+		}
+	}
+	defer closeQuietly()
+	testhelper.RemoveSyncTablesIfNeeded(db)
+	testhelper.CreateSyncTables(db)
+	testhelper.SetupData(db, "profile5")
+
+	acknowledgedContactID := "0934A378-DEDB-4207-B99C-DD0D61DC59BC"
+	pendingContactID := "911DD745-8916-41C4-9973-F8B38A501602"
+
+	//Both contacts are deleted locally (see profile5), only the first delete was acknowledged by *node-spoke1
+	_, err = db.Exec(`
+update sync_state set IsDelete=true, DeletedDate=datetime('now', '-2 days') where RecordId in ($1, $2);
+`, acknowledgedContactID, pendingContactID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = db.Exec("update sync_peer_state set IsDelete=true, ChangedByClient=false where NodeId='*node-spoke1' AND RecordId=$1;", acknowledgedContactID)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	tombstoneRepo := syncdaosql.NewTombstoneRepository(db, database.Dialect)
+	result, err := tombstoneRepo.CollectTombstones(0)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 1, result.TotalPurged)
+	assert.Equal(t, 1, result.PurgedByEntity["Contact"])
+	var stateCount int
+	err = db.QueryRow("select count(*) from sync_state where RecordId in ($1, $2);", acknowledgedContactID, pendingContactID).Scan(&stateCount)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, stateCount)
+
+	//The pending delete is kept within the retention window and purged once past it
+	result, err = tombstoneRepo.CollectTombstones(72 * time.Hour)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 0, result.TotalPurged)
+	result, err = tombstoneRepo.CollectTombstones(24 * time.Hour)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 1, result.TotalPurged)
+	err = db.QueryRow("select count(*) from sync_peer_state where RecordId=$1;", pendingContactID).Scan(&stateCount)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, stateCount)
+
+	testhelper.EndTest(testName)
+}
@@ -0,0 +1,159 @@
+package syncdaosql
+
+import (
+	"database/sql"
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"time"
+)
+
+//NewTombstoneRepository provides sql database access for a TombstoneRepository.
+func NewTombstoneRepository(db *sql.DB, dialect Dialect) syncapi.TombstoneRepositoryable {
+	return tombstoneRepositoryType{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+type tombstoneRepositoryType struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+const (
+	//The table is dropped before committing rather than 'on commit drop', which not every database has. A rollback
+	//undoes its creation.
+	sqlCreateTombstoneCollect = `
+create temporary table tombstone_collect (EntitySingularName varchar(50) NOT NULL, RecordId varchar(112) NOT NULL);`
+
+	sqlDropTombstoneCollect = `
+drop table tombstone_collect;`
+
+	sqlCountTombstonesToCollect = `
+select EntitySingularName, count(*) from tombstone_collect group by EntitySingularName order by EntitySingularName;`
+)
+
+//findTombstonesToCollectSQL fills tombstone_collect. A tombstone is acknowledged by a node once the delete was sent to
+//it and acknowledged, or when the node received the delete from its peer. Either way the node's sync_peer_state is
+//marked IsDelete without pending changes.
+func findTombstonesToCollectSQL(dialect Dialect) string {
+	cutoff := dialect.Cast("$1", ColumnTypeTimestamp)
+	return `
+insert into tombstone_collect (EntitySingularName, RecordId)
+select sync_state.EntitySingularName, sync_state.RecordId from sync_state where
+	sync_state.IsDelete = true
+and (
+	(` + cutoff + ` is not null AND ` + dialect.Cast("coalesce(sync_state.DeletedDate, sync_state.RecordCreated)", ColumnTypeTimestamp) + ` < ` + cutoff + `)
+	or not exists (
+		select 1 from sync_peer_state where
+			sync_peer_state.EntitySingularName = sync_state.EntitySingularName
+		and
+			sync_peer_state.RecordId = sync_state.RecordId
+		and
+			sync_peer_state.NodeId in (select NodeId from sync_pair_nodes union select TargetNodeId from sync_pair_nodes)
+		and not (
+			sync_peer_state.IsDelete = true AND sync_peer_state.ChangedByClient = false AND
+			sync_peer_state.IsConflict = false AND sync_peer_state.TransactionBindSendId is null
+		)
+	)
+);`
+}
+
+//sqlPurgeTombstones removes the collected tombstones, referencing tables first.
+var sqlPurgeTombstones = []string{
+	"delete from sync_conflict where (EntitySingularName, RecordId) in (select EntitySingularName, RecordId from tombstone_collect);",
+	"delete from sync_state_ancestor where (EntitySingularName, RecordId) in (select EntitySingularName, RecordId from tombstone_collect);",
+	"delete from sync_peer_state where (EntitySingularName, RecordId) in (select EntitySingularName, RecordId from tombstone_collect);",
+	"delete from sync_state where (EntitySingularName, RecordId) in (select EntitySingularName, RecordId from tombstone_collect);",
+}
+
+//CollectTombstones purges within one transaction the tombstones found collectable along with their sync_peer_state,
+//sync_state_ancestor and sync_conflict rows.
+func (tombstoneRepository tombstoneRepositoryType) CollectTombstones(retention time.Duration) (syncapi.TombstoneCollectResult, error) {
+	if retention < 0 {
+		retention = 0
+	}
+	answer := syncapi.TombstoneCollectResult{
+		Retention:      retention.String(),
+		PurgedByEntity: map[string]int{},
+	}
+	var cutoff interface{}
+	if retention > 0 {
+		cutoff = time.Now().Add(-retention)
+	}
+
+	tx, err := tombstoneRepository.db.Begin()
+	if err != nil {
+		syncutil.Error(err, ". Error starting transaction for tombstone collection")
+		return answer, err
+	}
+	_, err = tx.Exec(sqlCreateTombstoneCollect)
+	if err == nil {
+		_, err = tx.Exec(findTombstonesToCollectSQL(tombstoneRepository.dialect), cutoff)
+	}
+	if err != nil {
+		syncutil.Error(err, ". Error finding tombstones to collect")
+		rollbackQuietly(tx)
+		return answer, err
+	}
+	err = countTombstonesToCollect(tx, &answer)
+	if err != nil {
+		rollbackQuietly(tx)
+		return answer, err
+	}
+	for _, sqlStr := range sqlPurgeTombstones {
+		_, err = tx.Exec(sqlStr)
+		if err != nil {
+			syncutil.Error(err, ". Error purging tombstones. sql:", sqlStr)
+			rollbackQuietly(tx)
+			return answer, err
+		}
+	}
+	_, err = tx.Exec(sqlDropTombstoneCollect)
+	if err != nil {
+		syncutil.Error(err, ". Error dropping tombstones collected")
+		rollbackQuietly(tx)
+		return answer, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		syncutil.Error(err, ". Error committing tombstone collection")
+		return answer, err
+	}
+	syncutil.Info("Purged", answer.TotalPurged, "tombstones with retention", answer.Retention, ":", answer.PurgedByEntity)
+	return answer, nil
+}
+
+func countTombstonesToCollect(tx *sql.Tx, answer *syncapi.TombstoneCollectResult) error {
+	rows, err := tx.Query(sqlCountTombstonesToCollect)
+	if err != nil {
+		syncutil.Error(err, ". Error counting tombstones to collect")
+		return err
+	}
+	closeRowQuietly := func() {
+		err := rows.Close()
+		if err != nil {
+			syncutil.Error("Quietly handling of row close error. Error: " + err.Error())
+		}
+	}
+	defer closeRowQuietly()
+	for rows.Next() {
+		var (
+			entitySingularName string
+			count              int
+		)
+		err = rows.Scan(&entitySingularName, &count)
+		if err != nil {
+			syncutil.Error(err, ". Error reading tombstones to collect")
+			return err
+		}
+		answer.PurgedByEntity[entitySingularName] = count
+		answer.TotalPurged = answer.TotalPurged + count
+	}
+	err = rows.Err()
+	if err != nil {
+		syncutil.Error(err, ". Error reading tombstones to collect")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package syncdaosqlite
+
+import (
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"data-sync-tools-go/syncdao/syncdaosql/syncdaosqltest"
+	"data-sync-tools-go/syncdao/syncdaotest"
+	"data-sync-tools-go/testhelper"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testDbName = filepath.Join(os.TempDir(), testhelper.TestDbName+".sqlite")
+
+func TestSQL(t *testing.T) {
+	syncdaosqltest.Run(t, syncdaosqltest.Database{
+		Dialect: Dialect{},
+		Open: func() (*sql.DB, error) {
+			return createAndVerifyDBConn(testDbName)
+		},
+	})
+}
+
+func TestConformance(t *testing.T) {
+	syncdaotest.Run(t, func(profile string) (syncdaotest.Fixture, error) {
+		factory, err := NewSQLiteDaosFactory(testDbName)
+		if err != nil {
+			return syncdaotest.Fixture{}, err
+		}
+		db := factory.SQLDb()
+		testhelper.SetupData(db, profile)
+		fixture := syncdaotest.Fixture{
+			Daos:          factory,
+			DataRepo:      syncdaosql.NewDataRepository(db, Dialect{}),
+			ConflictRepo:  syncdaosql.NewConflictRepository(db, Dialect{}),
+			TombstoneRepo: syncdaosql.NewTombstoneRepository(db, Dialect{}),
+			SessionRepo:   syncdaosql.NewSessionRepository(db),
+			NodeAdminRepo: syncdaosql.NewNodeAdminRepository(db),
+			Local:         syncdaotest.NewSQLLocalChanger(db),
+		}
+		return fixture, nil
+	})
+}
@@ -0,0 +1,45 @@
+//Package syncdaosqlite implements core syncdao interfaces for sqlite (https://www.sqlite.org/) through a driver
+//without cgo (https://pkg.go.dev/modernc.org/sqlite), suiting nodes that keep their sync data in a local file. The
+//sql is shared with the other sql databases in syncdaosql, written in the Dialect of this package.
+package syncdaosqlite
+
+import (
+	"database/sql"
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"data-sync-tools-go/syncutil"
+
+	//Registers the "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+//NewSQLiteDaosFactory creates a syncdaosql.SQLDaosFactory instance for the database file at dbPath.
+func NewSQLiteDaosFactory(dbPath string) (*syncdaosql.SQLDaosFactory, error) {
+	syncutil.Info("Using SQLite Mode.")
+	db, err := createAndVerifyDBConn(dbPath)
+	if err != nil {
+		syncutil.Error(err, ". Cannot establish a database connection.")
+		var nilDaosFactory *syncdaosql.SQLDaosFactory
+		return nilDaosFactory, err
+	}
+	return syncdaosql.NewSQLDaosFactory(db, Dialect{}), nil
+}
+
+//createAndVerifyDBConn opens the database file at dbPath, creating it when missing. Foreign keys are enforced as
+//they are by postgressql, writers wait on each other instead of failing with SQLITE_BUSY, and transactions take
+//the write lock when they begin, standing in for the 'for update' row locks of postgressql. Times are written in
+//a format the sqlite date and time functions understand.
+func createAndVerifyDBConn(dbPath string) (*sql.DB, error) {
+	dbinfo := "file:" + dbPath + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)" +
+		"&_txlock=immediate&_time_format=sqlite"
+	db, err := sql.Open("sqlite", dbinfo)
+	if err != nil {
+		syncutil.Error(err, ". Using connection:", dbinfo)
+		return db, err
+	}
+	err = db.Ping()
+	if err != nil {
+		syncutil.Error("Cannot ping db. error:", err, ". Using connection:", dbinfo)
+		return db, err
+	}
+	return db, nil
+}
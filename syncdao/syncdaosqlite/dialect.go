@@ -0,0 +1,39 @@
+package syncdaosqlite
+
+import (
+	"data-sync-tools-go/syncdao/syncdaosql"
+	"strings"
+)
+
+//Dialect is the syncdaosql.Dialect of sqlite.
+type Dialect struct{}
+
+//Now implements syncdaosql.Dialect.Now.
+func (Dialect) Now() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+//ForUpdate implements syncdaosql.Dialect.ForUpdate. Sqlite has no row locks. Transactions begin holding the write lock
+//instead (see createAndVerifyDBConn), which keeps the rows read until the commit.
+func (Dialect) ForUpdate() string {
+	return ""
+}
+
+//QuoteIdentifier implements syncdaosql.Dialect.QuoteIdentifier. Sqlite matches identifiers without regard to case,
+//quoted or not, so unlike postgressql the name is kept as is. Like pq.QuoteIdentifier, anything from a NUL on is
+//dropped.
+func (Dialect) QuoteIdentifier(name string) string {
+	if end := strings.IndexRune(name, 0); end >= 0 {
+		name = name[:end]
+	}
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+//Cast implements syncdaosql.Dialect.Cast. Parameters need no type, but times are compared as text unless read with
+//datetime, which also accepts the times written by the driver.
+func (Dialect) Cast(expr string, columnType syncdaosql.ColumnType) string {
+	if columnType == syncdaosql.ColumnTypeTimestamp {
+		return "datetime(" + expr + ")"
+	}
+	return expr
+}
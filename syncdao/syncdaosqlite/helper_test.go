@@ -0,0 +1,9 @@
+package syncdaosqlite
+
+import (
+	"data-sync-tools-go/testhelper"
+)
+
+func init() {
+	testhelper.TestDbDialect = "sqlite"
+}
@@ -0,0 +1,684 @@
+package syncdaotest
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testNodes(t *testing.T, fixture Fixture) {
+	syncNodeDao := fixture.Daos.SyncNodeDao()
+
+	node := syncdao.SyncNode{NodeID: "*node-new", NodeName: "N", DataVersionName: "Demo Model 1"}
+	if !assert.Nil(t, syncNodeDao.AddNode(node)) {
+		return
+	}
+	assert.NotNil(t, syncNodeDao.AddNode(node), "a node id is unique")
+	assert.NotNil(t, syncNodeDao.AddNode(syncdao.SyncNode{NodeID: "*node-other", NodeName: "N", DataVersionName: "Demo Model 1"}), "a node name is unique")
+	assert.NotNil(t, syncNodeDao.AddNode(syncdao.SyncNode{NodeID: "*node-other", NodeName: "O", DataVersionName: "Unknown Model"}), "the data version must exist")
+
+	actual, err := syncNodeDao.GetOneNodeByNodeName("N")
+	assert.Nil(t, err)
+	assert.Equal(t, node, actual)
+	actual, err = syncNodeDao.GetOneNodeByNodeID("*node-new")
+	assert.Nil(t, err)
+	assert.Equal(t, node, actual)
+	_, err = syncNodeDao.GetOneNodeByNodeName("Unknown")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+
+	assert.Nil(t, syncNodeDao.DeleteNodeByNodeID("*node-new"))
+	_, err = syncNodeDao.GetOneNodeByNodeID("*node-new")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, syncNodeDao.DeleteNodeByNodeID("*node-new"))
+	assert.NotNil(t, syncNodeDao.DeleteNodeByNodeID("*node-hub"), "a paired node cannot be deleted")
+}
+
+func testPairs(t *testing.T, fixture Fixture) {
+	syncPairDao := fixture.Daos.SyncPairDao()
+
+	syncPair, err := syncPairDao.GetPairByNames("A", "Z")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "*pair-1", syncPair.PairID)
+	assert.Equal(t, "A <-> Z", syncPair.PairName)
+	assert.Equal(t, "Inactive", syncPair.SyncSessionState)
+	assert.Equal(t, "", syncPair.SyncSessionID)
+	//A <-> B only has one of its two nodes and C <-> Z is configured twice
+	_, err = syncPairDao.GetPairByNames("B", "A")
+	assert.NotNil(t, err)
+	_, err = syncPairDao.GetPairByNames("C", "Z")
+	assert.NotNil(t, err)
+	_, err = syncPairDao.GetPairByNames("A", "C")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+
+	nodePairItem, err := syncPairDao.GetNodePairItem("*pair-1", "A")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "*node-spoke1", nodePairItem.NodeID)
+	assert.Equal(t, "A", nodePairItem.NodeName)
+	_, err = syncPairDao.GetNodePairItem("*pair-1", "C")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+
+	entities, err := syncPairDao.GetEntityPairItem("*pair-1", "A")
+	if !assert.Nil(t, err) {
+		return
+	}
+	names := map[string]string{}
+	for _, entity := range entities {
+		names[entity.EntityPluralName] = entity.EntitySingularName
+	}
+	assert.Len(t, names, 6)
+	assert.Equal(t, "Contact", names["Contacts"])
+}
+
+func testSyncModel(t *testing.T, fixture Fixture) {
+	syncPairDao := fixture.Daos.SyncPairDao()
+	syncNodeDao := fixture.Daos.SyncNodeDao()
+
+	if !assert.Nil(t, syncPairDao.AddDataVersion("Demo Model 3")) {
+		return
+	}
+	assert.NotNil(t, syncPairDao.AddDataVersion("Demo Model 3"), "a data version name is unique")
+	task := syncdao.EntityPairItem{EntitySingularName: "Task", EntityPluralName: "Tasks", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 1, EntityHandlerURI: "none"}
+	if !assert.Nil(t, syncPairDao.AddEntity("Demo Model 3", task)) {
+		return
+	}
+	assert.NotNil(t, syncPairDao.AddEntity("Demo Model 3", syncdao.EntityPairItem{EntitySingularName: "Other Task", EntityPluralName: "Tasks"}), "a plural name is unique in the data version")
+	assert.NotNil(t, syncPairDao.AddEntity("Unknown Model", syncdao.EntityPairItem{EntitySingularName: "Note", EntityPluralName: "Notes"}), "the data version must exist")
+	assert.Nil(t, syncPairDao.AddField("Demo Model 3", "Task", syncdao.SyncFieldDefinition{FieldName: "taskId", FieldType: syncdao.SyncFieldTypeEnumString, IsPrimaryKey: true}))
+	assert.NotNil(t, syncPairDao.AddField("Demo Model 3", "Task", syncdao.SyncFieldDefinition{FieldName: "taskId", FieldType: syncdao.SyncFieldTypeEnumString}), "a field name is unique in the entity")
+	assert.NotNil(t, syncPairDao.AddField("Demo Model 1", "Task", syncdao.SyncFieldDefinition{FieldName: "title", FieldType: syncdao.SyncFieldTypeEnumString}), "the entity must be of the data version")
+
+	for _, node := range []syncdao.SyncNode{
+		{NodeID: "*node-new1", NodeName: "N1", DataVersionName: "Demo Model 3"},
+		{NodeID: "*node-new2", NodeName: "N2", DataVersionName: "Demo Model 3"},
+	} {
+		if !assert.Nil(t, syncNodeDao.AddNode(node)) {
+			return
+		}
+	}
+	if !assert.Nil(t, syncPairDao.AddPair(syncdao.SyncPair{PairID: "*pair-new", PairName: "N1 <-> N2"})) {
+		return
+	}
+	assert.NotNil(t, syncPairDao.AddPair(syncdao.SyncPair{PairID: "*pair-new", PairName: "N1 <-> N2 again"}), "a pair id is unique")
+	assert.Nil(t, syncPairDao.AddPairNode("*pair-new", "*node-new1", "*node-new2", "Demo Model 3", "none"))
+	_, err := syncPairDao.GetPairByNames("N1", "N2")
+	assert.NotNil(t, err, "a pair with one of its nodes is not complete")
+	assert.Nil(t, syncPairDao.AddPairNode("*pair-new", "*node-new2", "*node-new1", "", "none"))
+	assert.NotNil(t, syncPairDao.AddPairNode("*pair-new", "*node-new2", "*node-new1", "", "none"), "a node targets its peer once")
+	assert.NotNil(t, syncPairDao.AddPairNode("*pair-new", "*node-new2", "*node-unknown", "", "none"), "the nodes must exist")
+
+	//The pair takes the defaults of the sql schema
+	syncPair, err := syncPairDao.GetPairByNames("N1", "N2")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "*pair-new", syncPair.PairID)
+		assert.Equal(t, 10, syncPair.MaxSesDurValue)
+		assert.Equal(t, "minutes", syncPair.MaxSesDurUnit)
+		assert.Equal(t, "json:V1", syncPair.SyncDataTransForm)
+		assert.Equal(t, "none", syncPair.SyncMsgSecPol)
+		assert.Equal(t, "Inactive", syncPair.SyncSessionState)
+	}
+	entities, err := syncPairDao.GetEntityPairItem("*pair-new", "N1")
+	if assert.Nil(t, err) {
+		assert.Equal(t, []syncdao.EntityPairItem{task}, entities)
+	}
+
+	nodes, err := syncNodeDao.ListNodes()
+	if assert.Nil(t, err) {
+		names := []string{}
+		for _, node := range nodes {
+			names = append(names, node.NodeName)
+		}
+		assert.Equal(t, []string{"A", "B", "C", "N1", "N2", "Z"}, names)
+		assert.Equal(t, syncdao.SyncNode{NodeID: "*node-new1", NodeName: "N1", DataVersionName: "Demo Model 3"}, nodes[3])
+	}
+}
+
+func testPairAdmin(t *testing.T, fixture Fixture) {
+	syncPairDao := fixture.Daos.SyncPairDao()
+
+	pairs, err := syncPairDao.ListPairs()
+	if !assert.Nil(t, err) {
+		return
+	}
+	names := []string{}
+	for _, pair := range pairs {
+		names = append(names, pair.PairName)
+	}
+	assert.Equal(t, []string{"A <-> B (Partial)", "A <-> Z", "B <-> Z", "C <-> Z (Dup 1 of 2)", "C <-> Z (Dup 2 of 2)"}, names)
+	_, err = syncPairDao.GetPair("*pair-unknown")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+
+	assert.Nil(t, syncPairDao.UpdatePair(syncdao.SyncPair{PairID: "*pair-1", PairName: "A <-> Z (Renamed)", MaxSesDurValue: 2, MaxSesDurUnit: "hours"}))
+	syncPair, err := syncPairDao.GetPair("*pair-1")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "A <-> Z (Renamed)", syncPair.PairName)
+		assert.Equal(t, 2, syncPair.MaxSesDurValue)
+		assert.Equal(t, "hours", syncPair.MaxSesDurUnit)
+		assert.Equal(t, "json:V1", syncPair.SyncMsgTransForm)
+		assert.Equal(t, "none", syncPair.SyncConflictURI)
+		assert.Equal(t, "Inactive", syncPair.SyncSessionState)
+	}
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, syncPairDao.UpdatePair(syncdao.SyncPair{PairID: "*pair-unknown", PairName: "Unknown"}))
+	assert.IsType(t, syncapi.ValidationError{}, syncPairDao.AddPair(syncdao.SyncPair{PairID: "*pair-1", PairName: "A <-> Z again"}), "a pair id is unique")
+
+	pairNodes, err := syncPairDao.ListPairNodes("*pair-1")
+	if assert.Nil(t, err) && assert.Len(t, pairNodes, 2) {
+		assert.Equal(t, syncdao.PairNode{PairID: "*pair-1", NodeID: "*node-hub", TargetNodeID: "*node-spoke1", SeededDataVersion: "Demo Model 1", SyncConflictURI: "none"}, pairNodes[0])
+		assert.Equal(t, "*node-spoke1", pairNodes[1].NodeID)
+	}
+	_, err = syncPairDao.ListPairNodes("*pair-unknown")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+
+	//A <-> B is completed, and a pair cannot sync a third node
+	assert.Nil(t, syncPairDao.AddPairNode("*pair-3", "*node-spoke1", "*node-spoke2", "", "none"))
+	assert.IsType(t, syncapi.ValidationError{}, syncPairDao.AddPairNode("*pair-3", "*node-spoke1", "*node-hub", "", "none"))
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, syncPairDao.AddPairNode("*pair-unknown", "*node-spoke1", "*node-spoke2", "", "none"))
+	assert.Nil(t, syncPairDao.UpdatePairNode(syncdao.PairNode{PairID: "*pair-3", NodeID: "*node-spoke1", TargetNodeID: "*node-spoke2", SeededDataVersion: "Demo Model 1", SyncConflictURI: "http://localhost:8080/conflict"}))
+	pairNodes, err = syncPairDao.ListPairNodes("*pair-3")
+	if assert.Nil(t, err) && assert.Len(t, pairNodes, 2) {
+		assert.Equal(t, "Demo Model 1", pairNodes[0].SeededDataVersion)
+		assert.Equal(t, "http://localhost:8080/conflict", pairNodes[0].SyncConflictURI)
+	}
+	assert.IsType(t, syncapi.ValidationError{}, syncPairDao.UpdatePairNode(syncdao.PairNode{PairID: "*pair-3", NodeID: "*node-spoke1", TargetNodeID: "*node-spoke2", SeededDataVersion: "Unknown Model"}))
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, syncPairDao.UpdatePairNode(syncdao.PairNode{PairID: "*pair-3", NodeID: "*node-spoke1", TargetNodeID: "*node-hub"}))
+	assert.Nil(t, syncPairDao.DeletePairNode("*pair-3", "*node-spoke1", "*node-spoke2"))
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, syncPairDao.DeletePairNode("*pair-3", "*node-spoke1", "*node-spoke2"))
+	pairNodes, err = syncPairDao.ListPairNodes("*pair-3")
+	if assert.Nil(t, err) && assert.Len(t, pairNodes, 1) {
+		assert.Equal(t, "*node-spoke2", pairNodes[0].NodeID)
+	}
+
+	//A pair is not changed while in session
+	_, err = syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: "*pair-1", SessionID: "*session-id-1"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, syncapi.ErrPairSessionActive, syncPairDao.UpdatePair(syncdao.SyncPair{PairID: "*pair-1", PairName: "A <-> Z"}))
+	assert.Equal(t, syncapi.ErrPairSessionActive, syncPairDao.DeletePair("*pair-1"))
+	assert.Equal(t, syncapi.ErrPairSessionActive, syncPairDao.DeletePairNode("*pair-1", "*node-hub", "*node-spoke1"))
+	_, err = syncPairDao.CloseSyncSession(syncdao.CloseSyncSessionRequest{PairID: "*pair-1", SessionID: "*session-id-1"})
+	assert.Nil(t, err)
+
+	assert.Nil(t, syncPairDao.DeletePair("*pair-1"))
+	_, err = syncPairDao.GetPair("*pair-1")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+	_, err = syncPairDao.ListPairNodes("*pair-1")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+	_, err = syncPairDao.GetPairByNames("A", "Z")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, syncPairDao.DeletePair("*pair-1"))
+	_, err = syncPairDao.GetPairByNames("B", "Z")
+	assert.Nil(t, err, "the other pairs are kept")
+}
+
+func testSyncModelAdmin(t *testing.T, fixture Fixture) {
+	syncPairDao := fixture.Daos.SyncPairDao()
+
+	versions, err := syncPairDao.ListDataVersions()
+	if assert.Nil(t, err) {
+		assert.Equal(t, []string{"Demo Model 1", "Demo Model 2 (orphand node)"}, versions)
+	}
+	entities, err := syncPairDao.ListEntities("Demo Model 1")
+	if assert.Nil(t, err) && assert.Len(t, entities, 6) {
+		names := []string{}
+		for _, entity := range entities {
+			names = append(names, entity.Entity.EntitySingularName)
+		}
+		assert.Equal(t, []string{"Entity 1", "Entity 2", "Entity 3", "Entity 4", "Contact", "Entity 5"}, names)
+		assert.Equal(t, syncdao.EntityPairItem{EntitySingularName: "Contact", EntityPluralName: "Contacts", ProcessOrderAddUpdate: 4, ProcessOrderDelete: 1, EntityHandlerURI: "none"}, entities[4].Entity)
+		if assert.Len(t, entities[4].Fields, 7) {
+			assert.Equal(t, syncdao.SyncFieldDefinition{FieldName: "contactId", FieldType: syncdao.SyncFieldTypeEnumString, IsPrimaryKey: true}, entities[4].Fields[0])
+			assert.Equal(t, syncdao.SyncFieldDefinition{FieldName: "dateOfBirth", FieldType: syncdao.SyncFieldTypeEnumDate}, entities[4].Fields[1])
+		}
+		assert.Empty(t, entities[1].Fields)
+	}
+	_, err = syncPairDao.ListEntities("Unknown Model")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, err)
+
+	if !assert.Nil(t, syncPairDao.AddDataVersion("Demo Model 3")) {
+		return
+	}
+	assert.IsType(t, syncapi.ValidationError{}, syncPairDao.AddDataVersion("Demo Model 3"), "a data version name is unique")
+	entities, err = syncPairDao.ListEntities("Demo Model 3")
+	if assert.Nil(t, err) {
+		assert.Empty(t, entities)
+	}
+
+	project := syncdao.EntityPairItem{EntitySingularName: "Project", EntityPluralName: "Projects", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 2, EntityHandlerURI: "none"}
+	if !assert.Nil(t, syncPairDao.AddEntity("Demo Model 3", project)) {
+		return
+	}
+	assert.Nil(t, syncPairDao.AddEntity("Demo Model 3", syncdao.EntityPairItem{EntitySingularName: "Task", EntityPluralName: "Tasks", ProcessOrderAddUpdate: 2, ProcessOrderDelete: 1}))
+	assert.IsType(t, syncapi.ValidationError{}, syncPairDao.AddEntity("Demo Model 3", syncdao.EntityPairItem{EntitySingularName: "Note", EntityPluralName: "Notes", ProcessOrderAddUpdate: 3, ProcessOrderDelete: 2}), "the delete order reverses the add order")
+	assert.IsType(t, syncapi.ValidationError{}, syncPairDao.AddEntity("Demo Model 3", syncdao.EntityPairItem{EntitySingularName: "Contact", EntityPluralName: "Contacts", ProcessOrderAddUpdate: 2, ProcessOrderDelete: 1}), "a singular name is unique")
+	assert.IsType(t, syncapi.ValidationError{}, syncPairDao.AddEntity("Demo Model 3", syncdao.EntityPairItem{EntitySingularName: "Other Task", EntityPluralName: "Tasks", ProcessOrderAddUpdate: 2, ProcessOrderDelete: 1}), "a plural name is unique in the data version")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, syncPairDao.AddEntity("Unknown Model", syncdao.EntityPairItem{EntitySingularName: "Note", EntityPluralName: "Notes", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 1}))
+
+	assert.Nil(t, syncPairDao.AddField("Demo Model 3", "Project", syncdao.SyncFieldDefinition{FieldName: "projectId", FieldType: syncdao.SyncFieldTypeEnumString, IsPrimaryKey: true}))
+	assert.Nil(t, syncPairDao.AddField("Demo Model 3", "Task", syncdao.SyncFieldDefinition{FieldName: "title", FieldType: syncdao.SyncFieldTypeEnumString}))
+	assert.IsType(t, syncapi.ValidationError{}, syncPairDao.AddField("Demo Model 3", "Task", syncdao.SyncFieldDefinition{FieldName: "title", FieldType: syncdao.SyncFieldTypeEnumString}), "a field name is unique in the entity")
+	assert.Equal(t, syncdao.ErrDaoNoDataFound, syncPairDao.AddField("Demo Model 1", "Task", syncdao.SyncFieldDefinition{FieldName: "title", FieldType: syncdao.SyncFieldTypeEnumString}), "the entity must be of the data version")
+
+	entities, err = syncPairDao.ListEntities("Demo Model 3")
+	if assert.Nil(t, err) && assert.Len(t, entities, 2) {
+		assert.Equal(t, project, entities[0].Entity)
+		assert.Equal(t, []string{"Entity 'Task' has no primary key field"}, syncapi.SyncModelProblems(entities))
+	}
+	assert.Nil(t, syncPairDao.AddField("Demo Model 3", "Task", syncdao.SyncFieldDefinition{FieldName: "taskId", FieldType: syncdao.SyncFieldTypeEnumString, IsPrimaryKey: true}))
+	entities, err = syncPairDao.ListEntities("Demo Model 3")
+	if assert.Nil(t, err) {
+		assert.Empty(t, syncapi.SyncModelProblems(entities))
+	}
+}
+
+func testSessionLifecycle(t *testing.T, fixture Fixture) {
+	syncPairDao := fixture.Daos.SyncPairDao()
+	pairID := "*pair-1"
+	sessionID := "*session-id-1"
+
+	created, err := syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "OK", created.Result)
+	assert.Equal(t, sessionID, created.ActualSessionID)
+	created, err = syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	assert.Nil(t, err)
+	assert.Equal(t, "ThisSessionIdAlreadyActive", created.Result)
+	created, err = syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: "*session-id-2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "DifferentSessionIdAlreadyActive", created.Result)
+	assert.Equal(t, sessionID, created.ActualSessionID)
+
+	updated, err := syncPairDao.UpdateSyncSessionState(syncdao.UpdateSyncSessionStateRequest{PairID: pairID, SessionID: sessionID, State: "Queuing"})
+	assert.Nil(t, err)
+	assert.Equal(t, syncapi.IllegalStateTransitionResult, updated.Result)
+	assert.Equal(t, sessionID, updated.ActualSessionID)
+	assert.Equal(t, "Queuing", updated.RequestedState)
+	assert.Equal(t, "Initializing", updated.ResultingState)
+	for _, state := range []string{"Seeding", "Queuing"} {
+		updated, err = syncPairDao.UpdateSyncSessionState(syncdao.UpdateSyncSessionStateRequest{PairID: pairID, SessionID: sessionID, State: state})
+		assert.Nil(t, err)
+		assert.Equal(t, "OK", updated.Result)
+		assert.Equal(t, state, updated.ResultingState)
+	}
+	updated, err = syncPairDao.UpdateSyncSessionState(syncdao.UpdateSyncSessionStateRequest{PairID: pairID, SessionID: sessionID, State: "Seeding"})
+	assert.Nil(t, err)
+	assert.Equal(t, syncapi.IllegalStateTransitionResult, updated.Result)
+	assert.Equal(t, "Queuing", updated.ResultingState)
+	updated, err = syncPairDao.UpdateSyncSessionState(syncdao.UpdateSyncSessionStateRequest{PairID: pairID, SessionID: sessionID, State: "Unknown"})
+	assert.Nil(t, err)
+	assert.Equal(t, syncapi.IllegalStateTransitionResult, updated.Result)
+	assert.Equal(t, "Queuing", updated.ResultingState)
+	updated, err = syncPairDao.UpdateSyncSessionState(syncdao.UpdateSyncSessionStateRequest{PairID: pairID, SessionID: "*session-id-2", State: "Syncing"})
+	assert.Nil(t, err)
+	assert.Equal(t, "CouldNoFindActiveSessionToUpdate", updated.Result)
+
+	pairState, err := syncPairDao.QueryPairState(syncdao.QueryPairStateRequest{PairID: pairID})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, sessionID, pairState.SessionID)
+	assert.Equal(t, "Queuing", pairState.State)
+	assert.False(t, pairState.SessionStart.Equal(time.Time{}))
+
+	updated, err = syncPairDao.UpdateSyncSessionState(syncdao.UpdateSyncSessionStateRequest{PairID: pairID, SessionID: sessionID, State: "Canceling"})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", updated.Result)
+	updated, err = syncPairDao.UpdateSyncSessionState(syncdao.UpdateSyncSessionStateRequest{PairID: pairID, SessionID: sessionID, State: "Syncing"})
+	assert.Nil(t, err)
+	assert.Equal(t, syncapi.IllegalStateTransitionResult, updated.Result)
+	assert.Equal(t, "Canceling", updated.ResultingState)
+
+	closed, err := syncPairDao.CloseSyncSession(syncdao.CloseSyncSessionRequest{PairID: pairID, SessionID: "*session-id-2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "DifferentSessionIdAlreadyActive", closed.Result)
+	closed, err = syncPairDao.CloseSyncSession(syncdao.CloseSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", closed.Result)
+	closed, err = syncPairDao.CloseSyncSession(syncdao.CloseSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	assert.Nil(t, err)
+	assert.Equal(t, "ThisSessionIdAlreadyInactive", closed.Result)
+
+	syncPair, err := syncPairDao.GetPairByNames("A", "Z")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "", syncPair.SyncSessionID)
+	assert.Equal(t, "Inactive", syncPair.SyncSessionState)
+}
+
+func testSessionReaping(t *testing.T, fixture Fixture) {
+	syncPairDao := fixture.Daos.SyncPairDao()
+	pairID := "*pair-1"
+
+	created, err := syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "OK", created.Result)
+	for _, state := range []string{"Seeding", "Queuing"} {
+		updated, err := syncPairDao.UpdateSyncSessionState(syncdao.UpdateSyncSessionStateRequest{PairID: pairID, SessionID: sessionID, State: state})
+		assert.Nil(t, err)
+		assert.Equal(t, "OK", updated.Result)
+	}
+	recordsQueued, request := queueAndFetch(t, fixture, "*node-hub", syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	assert.Equal(t, 6, recordsQueued)
+	hashes := fetchedHashes(request)
+	assert.Len(t, hashes, 3)
+
+	reaped, err := fixture.SessionRepo.ReapExpiredSessions(time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, reaped.TotalReaped)
+	assert.Empty(t, reaped.Sessions)
+
+	//Sessions of the sample pairs last at most 10 minutes
+	reaped, err = fixture.SessionRepo.ReapExpiredSessions(time.Now().Add(11 * time.Minute))
+	if !assert.Nil(t, err) || !assert.Equal(t, 1, reaped.TotalReaped) || !assert.Len(t, reaped.Sessions, 1) {
+		return
+	}
+	assert.Equal(t, pairID, reaped.Sessions[0].PairID)
+	assert.Equal(t, sessionID, reaped.Sessions[0].SessionID)
+	assert.Equal(t, "Queuing", reaped.Sessions[0].State)
+	assert.Equal(t, syncapi.SessionEndReasonExpired, reaped.Sessions[0].Reason)
+	assert.Equal(t, 6, reaped.Sessions[0].ReleasedCount)
+
+	syncPair, err := syncPairDao.GetPairByNames("A", "Z")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "", syncPair.SyncSessionID)
+	assert.Equal(t, "Inactive", syncPair.SyncSessionState)
+
+	ended, err := fixture.SessionRepo.EndedSessions(pairID)
+	if !assert.Nil(t, err) || !assert.Len(t, ended, 1) {
+		return
+	}
+	assert.Equal(t, sessionID, ended[0].SessionID)
+	assert.Equal(t, "Queuing", ended[0].State)
+	assert.Equal(t, syncapi.SessionEndReasonExpired, ended[0].Reason)
+	assert.NotEmpty(t, ended[0].ReasonMsg)
+	assert.Equal(t, 6, ended[0].ReleasedCount)
+	assert.False(t, ended[0].SessionStart.IsZero())
+	assert.False(t, ended[0].SessionEnd.IsZero())
+	ended, err = fixture.SessionRepo.EndedSessions("*pair-2")
+	assert.Nil(t, err)
+	assert.Empty(t, ended)
+
+	//The pair takes a new session, which is sent the records reserved by the ended one
+	created, err = syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: "*session-id-2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", created.Result)
+	recordsQueued, request = queueAndFetch(t, fixture, "*node-hub", syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	assert.Equal(t, 6, recordsQueued)
+	assert.Equal(t, hashes, fetchedHashes(request))
+}
+
+func testSessionCancel(t *testing.T, fixture Fixture) {
+	syncPairDao := fixture.Daos.SyncPairDao()
+	pairID := "*pair-1"
+
+	canceled, err := fixture.SessionRepo.CancelSession(sessionID, "", time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, syncapi.SessionNotActiveResult, canceled.Result)
+
+	created, err := syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "OK", created.Result)
+	for _, state := range []string{"Seeding", "Queuing"} {
+		updated, err := syncPairDao.UpdateSyncSessionState(syncdao.UpdateSyncSessionStateRequest{PairID: pairID, SessionID: sessionID, State: state})
+		assert.Nil(t, err)
+		assert.Equal(t, "OK", updated.Result)
+	}
+	recordsQueued, request := queueAndFetch(t, fixture, "*node-hub", syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	assert.Equal(t, 6, recordsQueued)
+	hashes := fetchedHashes(request)
+
+	canceled, err = fixture.SessionRepo.CancelSession(sessionID, "Disk full", time.Now())
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "OK", canceled.Result)
+	assert.Equal(t, pairID, canceled.Session.PairID)
+	assert.Equal(t, "Queuing", canceled.Session.State)
+	assert.Equal(t, syncapi.SessionEndReasonCanceled, canceled.Session.Reason)
+	assert.Equal(t, 6, canceled.Session.ReleasedCount)
+	assert.Equal(t, 0, canceled.DiscardedCount)
+
+	syncPair, err := syncPairDao.GetPairByNames("A", "Z")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "", syncPair.SyncSessionID)
+	assert.Equal(t, "Inactive", syncPair.SyncSessionState)
+	ended, err := fixture.SessionRepo.EndedSessions(pairID)
+	if !assert.Nil(t, err) || !assert.Len(t, ended, 1) {
+		return
+	}
+	assert.Equal(t, syncapi.SessionEndReasonCanceled, ended[0].Reason)
+	assert.Equal(t, "Disk full", ended[0].ReasonMsg)
+
+	canceled, err = fixture.SessionRepo.CancelSession(sessionID, "", time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, syncapi.SessionNotActiveResult, canceled.Result, "a session is canceled only once")
+
+	//The next session is sent the records reserved by the canceled one
+	created, err = syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: "*session-id-2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", created.Result)
+	recordsQueued, request = queueAndFetch(t, fixture, "*node-hub", syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	assert.Equal(t, 6, recordsQueued)
+	assert.Equal(t, hashes, fetchedHashes(request))
+}
+
+func testSessionMgmtMsgs(t *testing.T, fixture Fixture) {
+	syncPairDao := fixture.Daos.SyncPairDao()
+	pairID := "*pair-1"
+	heartbeat := syncapi.SessionMgmtMsg{SessionID: sessionID, SenderNodeID: "*node-hub", MessageType: syncapi.SessionMgmtMsgTypeHeartbeat}
+
+	sent, err := fixture.SessionRepo.SendMgmtMsg(heartbeat)
+	assert.Nil(t, err)
+	assert.Equal(t, syncapi.SessionNotActiveResult, sent.Result, "a session must be active to be sent messages")
+
+	created, err := syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "OK", created.Result)
+	for _, state := range []string{"Seeding", "Queuing"} {
+		updated, err := syncPairDao.UpdateSyncSessionState(syncdao.UpdateSyncSessionStateRequest{PairID: pairID, SessionID: sessionID, State: state})
+		assert.Nil(t, err)
+		assert.Equal(t, "OK", updated.Result)
+	}
+
+	sent, err = fixture.SessionRepo.SendMgmtMsg(heartbeat)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "OK", sent.Result)
+	assert.Equal(t, pairID, sent.PairID)
+	assert.Equal(t, "Queuing", sent.State)
+	heartbeatSeq := sent.MsgSeq
+	syncPair, err := syncPairDao.GetPairByNames("A", "Z")
+	assert.Nil(t, err)
+	assert.False(t, syncPair.SyncSessionHeartbeat.IsZero(), "a heartbeat renews the lease of the session")
+	sent, err = fixture.SessionRepo.SendMgmtMsg(syncapi.SessionMgmtMsg{SessionID: sessionID, SenderNodeID: "*node-peer",
+		MessageType: syncapi.SessionMgmtMsgTypeStart})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", sent.Result)
+	assert.True(t, sent.MsgSeq > heartbeatSeq)
+	startSeq := sent.MsgSeq
+
+	//Each node polls the messages of its peer
+	msgs, err := fixture.SessionRepo.PollMgmtMsgs(sessionID, "*node-hub", 0)
+	if !assert.Nil(t, err) || !assert.Len(t, msgs, 1) {
+		return
+	}
+	assert.Equal(t, startSeq, msgs[0].MsgSeq)
+	assert.Equal(t, "*node-peer", msgs[0].SenderNodeID)
+	assert.Equal(t, syncapi.SessionMgmtMsgTypeStart, msgs[0].MessageType)
+	msgs, err = fixture.SessionRepo.PollMgmtMsgs(sessionID, "*node-peer", 0)
+	if !assert.Nil(t, err) || !assert.Len(t, msgs, 1) {
+		return
+	}
+	assert.Equal(t, syncapi.SessionMgmtMsgTypeHeartbeat, msgs[0].MessageType)
+	msgs, err = fixture.SessionRepo.PollMgmtMsgs(sessionID, "*node-hub", startSeq)
+	assert.Nil(t, err)
+	assert.Empty(t, msgs)
+
+	recordsQueued, request := queueAndFetch(t, fixture, "*node-hub", syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	assert.Equal(t, 6, recordsQueued)
+	hashes := fetchedHashes(request)
+	assert.Len(t, hashes, 3)
+
+	//A cancel rolls back the fetch in flight, which is fetched again
+	sent, err = fixture.SessionRepo.SendMgmtMsg(syncapi.SessionMgmtMsg{SessionID: sessionID, SenderNodeID: "*node-peer",
+		MessageType: syncapi.SessionMgmtMsgTypeCancel, Result: "Disk full"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "OK", sent.Result)
+	assert.Equal(t, "Canceling", sent.State)
+	assert.Equal(t, 3, sent.ReleasedCount)
+	sent, err = fixture.SessionRepo.SendMgmtMsg(syncapi.SessionMgmtMsg{SessionID: sessionID, SenderNodeID: "*node-hub",
+		MessageType: syncapi.SessionMgmtMsgTypeHaultingError})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", sent.Result)
+	assert.Equal(t, "Canceling", sent.State)
+	assert.Equal(t, 0, sent.ReleasedCount)
+	syncPair, err = syncPairDao.GetPairByNames("A", "Z")
+	assert.Nil(t, err)
+	assert.Equal(t, "Canceling", syncPair.SyncSessionState)
+	msgs, err = fixture.SessionRepo.PollMgmtMsgs(sessionID, "*node-hub", startSeq)
+	if assert.Nil(t, err) && assert.Len(t, msgs, 1) {
+		assert.Equal(t, syncapi.SessionMgmtMsgTypeCancel, msgs[0].MessageType)
+		assert.Equal(t, "Disk full", msgs[0].Result)
+	}
+	fetcher, err := fixture.DataRepo.CreateMessageFetcher(sessionID, "*node-hub", syncapi.FetchLimits{MaxMsgs: 100})
+	if !assert.Nil(t, err) {
+		return
+	}
+	fetched, err := fetcher.Fetch(suiteEntities, syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, hashes, fetchedHashes(fetched.Request))
+
+	closed, err := syncPairDao.CloseSyncSession(syncdao.CloseSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", closed.Result)
+	sent, err = fixture.SessionRepo.SendMgmtMsg(heartbeat)
+	assert.Nil(t, err)
+	assert.Equal(t, syncapi.SessionNotActiveResult, sent.Result)
+	msgs, err = fixture.SessionRepo.PollMgmtMsgs(sessionID, "*node-peer", heartbeatSeq)
+	assert.Nil(t, err)
+	assert.Len(t, msgs, 1, "the messages of a closed session can still be polled")
+}
+
+func testSessionProgress(t *testing.T, fixture Fixture) {
+	syncPairDao := fixture.Daos.SyncPairDao()
+	pairID := "*pair-1"
+
+	created, err := syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "OK", created.Result)
+	pairState, err := syncPairDao.QueryPairState(syncdao.QueryPairStateRequest{PairID: pairID})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []syncdao.SessionProgress{
+		{NodeID: "*node-hub", TargetNodeID: "*node-spoke1"},
+		{NodeID: "*node-spoke1", TargetNodeID: "*node-hub"},
+	}, pairState.Progress)
+	assert.Equal(t, 0, syncdao.PercentComplete(pairState.Progress))
+
+	//Queuing sets the totals sent to the peer and acknowledging adds to what was processed
+	recordsQueued, request := queueAndFetch(t, fixture, "*node-hub", syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	assert.Equal(t, 6, recordsQueued)
+	if !acknowledgeProcessed(t, fixture, "*node-hub", request) {
+		return
+	}
+	fetchedBytes := 0
+	for _, item := range request.Items {
+		for _, msg := range item.Msgs {
+			fetchedBytes += int(msg.GetRecordBytesSize())
+		}
+	}
+	pairState, err = syncPairDao.QueryPairState(syncdao.QueryPairStateRequest{PairID: pairID})
+	if !assert.Nil(t, err) || !assert.Len(t, pairState.Progress, 2) {
+		return
+	}
+	sent := pairState.Progress[1]
+	assert.Equal(t, 6, sent.TotalSessRecCount)
+	assert.Equal(t, 3, sent.ProceSessRecCount)
+	assert.Equal(t, fetchedBytes, sent.ProceSessRecBytes)
+	assert.True(t, sent.TotalSessRecBytes >= sent.ProceSessRecBytes)
+	assert.Equal(t, syncdao.SessionProgress{NodeID: "*node-hub", TargetNodeID: "*node-spoke1"}, pairState.Progress[0])
+	assert.Equal(t, 50, syncdao.PercentComplete(pairState.Progress))
+
+	//A node may report its own totals and progress, direction 1 being from the node to its peer
+	updated, err := syncPairDao.UpdateSyncingWithTotals(syncdao.UpdateSyncingRequestWithTotals{PairID: pairID, SessionID: sessionID,
+		NodeID: "*node-hub", RecordBytes1: 400, RecordCount1: 4, RecordBytes2: 600, RecordCount2: 6})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", updated.Result)
+	updated, err = syncPairDao.UpdateSyncingWithProcessed(syncdao.UpdateSyncingRequestWithProcessed{PairID: pairID, SessionID: sessionID,
+		NodeID: "*node-hub", RecordBytes1: 400, RecordCount1: 4, RecordBytes2: 300, RecordCount2: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", updated.Result)
+	pairState, err = syncPairDao.QueryPairState(syncdao.QueryPairStateRequest{PairID: pairID})
+	assert.Nil(t, err)
+	assert.Equal(t, []syncdao.SessionProgress{
+		{NodeID: "*node-hub", TargetNodeID: "*node-spoke1", TotalSessRecBytes: 400, TotalSessRecCount: 4, ProceSessRecBytes: 400, ProceSessRecCount: 4},
+		{NodeID: "*node-spoke1", TargetNodeID: "*node-hub", TotalSessRecBytes: 600, TotalSessRecCount: 6, ProceSessRecBytes: 300, ProceSessRecCount: 3},
+	}, pairState.Progress)
+	assert.Equal(t, 70, syncdao.PercentComplete(pairState.Progress))
+
+	updated, err = syncPairDao.UpdateSyncingWithTotals(syncdao.UpdateSyncingRequestWithTotals{PairID: pairID, SessionID: sessionID, NodeID: "*node-unknown"})
+	assert.Nil(t, err)
+	assert.Equal(t, syncapi.SyncingNodeNotInPairResult, updated.Result)
+	updated, err = syncPairDao.UpdateSyncingWithProcessed(syncdao.UpdateSyncingRequestWithProcessed{PairID: pairID, SessionID: "*session-id-2", NodeID: "*node-hub"})
+	assert.Nil(t, err)
+	assert.Equal(t, syncapi.SyncingSessionNotActiveResult, updated.Result)
+
+	//The progress of the last session is kept until the next one starts
+	closed, err := syncPairDao.CloseSyncSession(syncdao.CloseSyncSessionRequest{PairID: pairID, SessionID: sessionID})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", closed.Result)
+	pairState, err = syncPairDao.QueryPairState(syncdao.QueryPairStateRequest{PairID: pairID})
+	assert.Nil(t, err)
+	assert.Equal(t, 70, syncdao.PercentComplete(pairState.Progress))
+	created, err = syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: pairID, SessionID: "*session-id-2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", created.Result)
+	pairState, err = syncPairDao.QueryPairState(syncdao.QueryPairStateRequest{PairID: pairID})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, syncdao.PercentComplete(pairState.Progress))
+}
@@ -0,0 +1,120 @@
+//Package syncdaotest is the conformance suite of the syncdao implementations. Every implementation runs the suite from
+//its own tests, so that all of them behave the same as seen through the syncdao and syncapi interfaces: nodes and
+//pairs, the sync model and pending changes, the session lifecycle, reaping, canceling and progress, the queue, fetch,
+//process and acknowledge round trip, conflicts and deletes.
+package syncdaotest
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/testhelper"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+//Fixture is an implementation set up with sample data for one test of the suite.
+type Fixture struct {
+	Daos          syncdao.DaosFactory
+	DataRepo      syncapi.DataRepositoryable
+	ConflictRepo  syncapi.ConflictRepositoryable
+	TombstoneRepo syncapi.TombstoneRepositoryable
+	SessionRepo   syncapi.SessionRepositoryable
+	NodeAdminRepo syncapi.NodeAdminRepositoryable
+	Local         LocalChanger
+}
+
+//LocalChanger stands in for the application changing its records, which it does outside of the syncdao interfaces.
+type LocalChanger interface {
+	UpdateSyncState(entitySingularName string, recordID string, recordHash string, recordData []byte) error
+	MarkSyncStateDeleted(entitySingularName string, recordID string) error
+}
+
+//SetupFunc replaces the data of the implementation with the sample data of profile (see testhelper.SetupData) and
+//gives the fixture to test it with. The fixture's Daos are closed once the test is done.
+type SetupFunc func(profile string) (Fixture, error)
+
+type conformanceTest struct {
+	name    string
+	profile string
+	run     func(t *testing.T, fixture Fixture)
+}
+
+var conformanceTests = []conformanceTest{
+	{"Nodes", "profile3", testNodes},
+	{"Pairs", "profile3", testPairs},
+	{"SyncModel", "profile3", testSyncModel},
+	{"PairAdmin", "profile3", testPairAdmin},
+	{"SyncModelAdmin", "profile3", testSyncModelAdmin},
+	{"PendingChanges", "profile3", testPendingChanges},
+	{"SessionLifecycle", "profile3", testSessionLifecycle},
+	{"SessionReaping", "profile3", testSessionReaping},
+	{"SessionCancel", "profile3", testSessionCancel},
+	{"SessionMgmtMsgs", "profile3", testSessionMgmtMsgs},
+	{"SessionProgress", "profile3", testSessionProgress},
+	{"QueueFetchAcknowledge", "profile3", testQueueFetchAcknowledge},
+	{"ProcessFastBatch", "profile5", testProcessFastBatch},
+	{"ProcessMerge", "profile5", testProcessMerge},
+	{"ConflictKeepRemote", "profile5", testConflictKeepRemote},
+	{"ConflictKeepLocal", "profile5", testConflictKeepLocal},
+	{"ConflictResolverRefused", "profile5", testConflictResolverRefused},
+	{"LocalDelete", "profile5", testLocalDelete},
+	{"PeerDelete", "profile5", testPeerDelete},
+	{"UnknownNode", "profile5", testUnknownNode},
+}
+
+//Run runs every test of the suite against the implementation set up by setup.
+func Run(t *testing.T, setup SetupFunc) {
+	for _, test := range conformanceTests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			testhelper.StartTest(test.name)
+			fixture, err := setup(test.profile)
+			if err != nil {
+				t.Fatal("Failed to set up " + test.profile + ": " + err.Error())
+			}
+			defer fixture.Daos.Close()
+			test.run(t, fixture)
+			testhelper.EndTest(test.name)
+		})
+	}
+}
+
+//NewSQLLocalChanger gives the LocalChanger of the sql implementations, which share the sync_state table.
+func NewSQLLocalChanger(db *sql.DB) LocalChanger {
+	return sqlLocalChanger{
+		db: db,
+	}
+}
+
+type sqlLocalChanger struct {
+	db *sql.DB
+}
+
+func (changer sqlLocalChanger) UpdateSyncState(entitySingularName string, recordID string, recordHash string, recordData []byte) error {
+	sqlStr := `
+update sync_state set RecordHash=$1, RecordData=$2, RecordBytesSize=$3, IsDelete=false, DeletedDate=null
+where (EntitySingularName=$4 AND RecordId=$5);`
+	result, err := changer.db.Exec(sqlStr, recordHash, hex.EncodeToString(recordData), len(recordData), entitySingularName, recordID)
+	return checkOneRowAffected(result, err, entitySingularName, recordID)
+}
+
+func (changer sqlLocalChanger) MarkSyncStateDeleted(entitySingularName string, recordID string) error {
+	result, err := changer.db.Exec("update sync_state set IsDelete=true where (EntitySingularName=$1 AND RecordId=$2);", entitySingularName, recordID)
+	return checkOneRowAffected(result, err, entitySingularName, recordID)
+}
+
+func checkOneRowAffected(result sql.Result, err error, entitySingularName string, recordID string) error {
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected != 1 {
+		return errors.New("Cannot find record '" + recordID + "' of entity '" + entitySingularName + "'")
+	}
+	return nil
+}
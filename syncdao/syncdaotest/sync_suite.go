@@ -0,0 +1,505 @@
+package syncdaotest
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/testhelper"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	sessionID     = "*session-id-1"
+	peerSessionID = "*session-peer"
+)
+
+//Only contacts are fetched, as the records of the other entities of the sample data are not valid records.
+var (
+	suiteEntities = []syncapi.EntityNameItem{
+		syncapi.EntityNameItem{SingularName: "Contact", PluralName: "Contacts"},
+	}
+	suiteEntitiesByPluralName = map[string]syncapi.EntityNameItem{
+		"Contacts": suiteEntities[0],
+	}
+	creator = syncmsg.NewCreator()
+	//The contacts of the sample data, see testhelper.SetupData
+	jackSmith = testhelper.Contact{ContactID: "B6581A36-804D-45AC-B2E2-F6DA265AF7DE", DateOfBirthAsUTC: creator.FormatTimeFromString("1990-04-29 00:00:00.000"),
+		FirstName: "Jack", HeightFt: 6, HeightInch: 1.0, LastName: "Smith", PreferredHeight: 1}
+	henryAdins = testhelper.Contact{ContactID: "911DD745-8916-41C4-9973-F8B38A501602", DateOfBirthAsUTC: creator.FormatTimeFromString("1994-07-10 00:00:00.000"),
+		FirstName: "Henry", HeightFt: 5, HeightInch: 5.5, LastName: "Adins", PreferredHeight: 2}
+	henryAdkins = testhelper.Contact{ContactID: "911DD745-8916-41C4-9973-F8B38A501602", DateOfBirthAsUTC: creator.FormatTimeFromString("1994-07-10 00:00:00.000"),
+		FirstName: "Henry", HeightFt: 5, HeightInch: 5.5, LastName: "Adkins", PreferredHeight: 2}
+	mindyJohnson = testhelper.Contact{ContactID: "0934A378-DEDB-4207-B99C-DD0D61DC59BC", DateOfBirthAsUTC: creator.FormatTimeFromString("1987-05-28 00:00:00.000"),
+		FirstName: "Mindy", HeightFt: 5, HeightInch: 1.0, LastName: "Johnson", PreferredHeight: 2}
+)
+
+func testQueueFetchAcknowledge(t *testing.T, fixture Fixture) {
+	nodeID := "*node-hub"
+	recordsQueued, request := queueAndFetch(t, fixture, nodeID, syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	//The 3 records of 'Entity 1' are queued along with the 3 contacts
+	assert.Equal(t, 6, recordsQueued)
+	hashes := fetchedHashes(request)
+	assert.Len(t, hashes, 3)
+	assert.Equal(t, contactPackage(t, jackSmith, "").RecordSha256Hex, hashes[jackSmith.ContactID])
+	if !acknowledgeProcessed(t, fixture, nodeID, request) {
+		return
+	}
+
+	//No contact is left to send once the peer acknowledged them
+	recordsQueued, request = queueAndFetch(t, fixture, nodeID, syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	assert.Equal(t, 3, recordsQueued)
+	assert.Empty(t, fetchedHashes(request))
+
+	//Only the contact changed since is sent next
+	changedContact := jackSmith
+	changedContact.HeightInch = 2.0
+	changedPackage := contactPackage(t, changedContact, "")
+	if !assert.Nil(t, fixture.Local.UpdateSyncState("Contact", changedContact.ContactID, changedPackage.RecordSha256Hex, changedPackage.RecordBytes)) {
+		return
+	}
+	recordsQueued, request = queueAndFetch(t, fixture, nodeID, syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	assert.Equal(t, 4, recordsQueued)
+	assert.Equal(t, map[string]string{changedContact.ContactID: changedPackage.RecordSha256Hex}, fetchedHashes(request))
+}
+
+func testPendingChanges(t *testing.T, fixture Fixture) {
+	nodeID := "*node-hub"
+	pending, err := fixture.NodeAdminRepo.PendingChanges(nodeID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	//The 3 records of 'Entity 1' were queued before and the 3 contacts never were
+	assert.Equal(t, 6, pending.Total)
+	assert.Equal(t, []syncapi.PendingChangeCount{
+		{EntityPluralName: "Contacts", AddedOrUpdated: 3},
+		{EntityPluralName: "Entity 1", AddedOrUpdated: 3},
+	}, pending.Entities)
+
+	_, request := queueAndFetch(t, fixture, nodeID, syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	pending, err = fixture.NodeAdminRepo.PendingChanges(nodeID)
+	if assert.Nil(t, err) && assert.Len(t, pending.Entities, 2) {
+		assert.Equal(t, syncapi.PendingChangeCount{EntityPluralName: "Contacts", AddedOrUpdated: 3, InFlight: 3}, pending.Entities[0])
+	}
+	if !acknowledgeProcessed(t, fixture, nodeID, request) {
+		return
+	}
+	if !assert.Nil(t, fixture.Local.MarkSyncStateDeleted("Contact", jackSmith.ContactID)) {
+		return
+	}
+	pending, err = fixture.NodeAdminRepo.PendingChanges(nodeID)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 4, pending.Total)
+		assert.Equal(t, []syncapi.PendingChangeCount{
+			{EntityPluralName: "Contacts", Deleted: 1},
+			{EntityPluralName: "Entity 1", AddedOrUpdated: 3},
+		}, pending.Entities)
+	}
+
+	_, err = fixture.NodeAdminRepo.PendingChanges("*node-unknown")
+	assert.Equal(t, syncapi.ErrNodeNotFound, err)
+}
+
+func testProcessFastBatch(t *testing.T, fixture Fixture) {
+	nodeID := "*node-spoke1"
+	//The peer changes a contact both sides last agreed on and adds one
+	changedContact := mindyJohnson
+	changedContact.HeightInch = 2.0
+	changedPackage := contactPackage(t, changedContact, contactPackage(t, mindyJohnson, "").RecordSha256Hex)
+	addedContact := testhelper.Contact{ContactID: "151EFA13-A3AD-4C18-A2CE-9D66D0AED112", DateOfBirthAsUTC: creator.FormatTimeFromString("1988-01-23 00:00:00.000"),
+		FirstName: "Jill", HeightFt: 5, HeightInch: 3.0, LastName: "Anderson", PreferredHeight: 1}
+	addedPackage := contactPackage(t, addedContact, "")
+
+	response := process(t, fixture, nodeID, contactRequest(false, changedPackage, addedPackage))
+	if response == nil {
+		return
+	}
+	assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_OK, response.GetResult())
+	assert.Equal(t, "All records are fast batch", response.GetResultMsg())
+
+	//The records received are passed on to the other peer
+	_, request := queueAndFetch(t, fixture, "*node-hub", syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	hashes := fetchedHashes(request)
+	assert.Equal(t, changedPackage.RecordSha256Hex, hashes[changedContact.ContactID])
+	assert.Equal(t, addedPackage.RecordSha256Hex, hashes[addedContact.ContactID])
+}
+
+func testProcessMerge(t *testing.T, fixture Fixture) {
+	nodeID := "*node-spoke1"
+	adkinsHash := contactPackage(t, henryAdkins, "").RecordSha256Hex
+	//'Adkins' is sent to the peer, making it the version both sides agree on
+	_, request := queueAndFetch(t, fixture, nodeID, syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	if !assert.Equal(t, adkinsHash, fetchedHashes(request)[henryAdkins.ContactID]) || !acknowledgeProcessed(t, fixture, nodeID, request) {
+		return
+	}
+
+	//Then the first name is changed locally and the height by the peer, which merge as separate fields
+	localContact := henryAdkins
+	localContact.FirstName = "Hank"
+	localPackage := contactPackage(t, localContact, "")
+	if !assert.Nil(t, fixture.Local.UpdateSyncState("Contact", localContact.ContactID, localPackage.RecordSha256Hex, localPackage.RecordBytes)) {
+		return
+	}
+	remoteContact := henryAdkins
+	remoteContact.HeightFt = 6
+	remotePackage := contactPackage(t, remoteContact, adkinsHash)
+	mergedContact := localContact
+	mergedContact.HeightFt = 6
+
+	response := process(t, fixture, nodeID, contactRequest(false, remotePackage))
+	if response == nil {
+		return
+	}
+	if !assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_OK, response.GetResult()) || !assertOneResponseMsg(t, response) {
+		return
+	}
+	assert.Equal(t, syncmsg.AckSyncStateEnum_AckRecordLevelConflictResolvedSeparateFieldsChanged, response.Items[0].Msgs[0].GetSyncState())
+	_, request = queueAndFetch(t, fixture, "*node-hub", syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	mergedPackage := contactPackage(t, mergedContact, adkinsHash)
+	assert.Equal(t, mergedPackage.RecordSha256Hex, fetchedHashes(request)[mergedContact.ContactID])
+
+	//A peer already holding the merged record changes nothing and does not report a conflict
+	response = process(t, fixture, nodeID, contactRequest(false, mergedPackage))
+	if response == nil {
+		return
+	}
+	if !assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_OK, response.GetResult()) || !assertOneResponseMsg(t, response) {
+		return
+	}
+	assert.Equal(t, syncmsg.AckSyncStateEnum_AckFastBatch, response.Items[0].Msgs[0].GetSyncState())
+	assert.Equal(t, mergedPackage.RecordSha256Hex, response.Items[0].Msgs[0].GetResponseHash())
+}
+
+//processRecordLevelConflict has the peer change the height of 'Adins', which was changed locally to 'Adkins' after
+//the peer last saw it. With no ancestor or resolver available, the record is left in conflict.
+func processRecordLevelConflict(t *testing.T, fixture Fixture) (syncapi.ConflictItem, testhelper.ContactSyncPackage, bool) {
+	nodeID := "*node-spoke1"
+	remoteContact := henryAdins
+	remoteContact.HeightFt = 6
+	remotePackage := contactPackage(t, remoteContact, contactPackage(t, henryAdins, "").RecordSha256Hex)
+
+	response := process(t, fixture, nodeID, contactRequest(false, remotePackage))
+	if response == nil {
+		return syncapi.ConflictItem{}, remotePackage, false
+	}
+	if !assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_OK, response.GetResult()) || !assertOneResponseMsg(t, response) {
+		return syncapi.ConflictItem{}, remotePackage, false
+	}
+	assert.Equal(t, syncmsg.AckSyncStateEnum_AckFieldLevelConflictwithNoAutoResolverAvailable, response.Items[0].Msgs[0].GetSyncState())
+
+	conflicts, err := fixture.ConflictRepo.FindConflicts(nodeID)
+	if !assert.Nil(t, err) || !assert.Len(t, conflicts, 1) {
+		return syncapi.ConflictItem{}, remotePackage, false
+	}
+	conflict := conflicts[0]
+	assert.Equal(t, remoteContact.ContactID, conflict.RecordID)
+	assert.Equal(t, "Contacts", conflict.EntityPluralName)
+	assert.Equal(t, remotePackage.RecordSha256Hex, conflict.Remote.RecordHash)
+	assert.Equal(t, contactPackage(t, henryAdkins, "").RecordSha256Hex, conflict.Local.RecordHash)
+	found, err := fixture.ConflictRepo.GetConflict(conflict.ConflictID)
+	assert.Nil(t, err)
+	assert.Equal(t, conflict.RecordID, found.RecordID)
+	return conflict, remotePackage, true
+}
+
+func testConflictKeepRemote(t *testing.T, fixture Fixture) {
+	conflict, remotePackage, ok := processRecordLevelConflict(t, fixture)
+	if !ok {
+		return
+	}
+	recordHash, err := fixture.ConflictRepo.ResolveConflict(conflict.ConflictID, syncapi.ConflictResolutionChoiceRemote, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, remotePackage.RecordSha256Hex, recordHash)
+	_, err = fixture.ConflictRepo.GetConflict(conflict.ConflictID)
+	assert.Equal(t, syncapi.ErrConflictNotFound, err)
+	_, err = fixture.ConflictRepo.ResolveConflict(conflict.ConflictID, syncapi.ConflictResolutionChoiceRemote, nil)
+	assert.Equal(t, syncapi.ErrConflictNotFound, err)
+
+	_, request := queueAndFetch(t, fixture, "*node-hub", syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	assert.Equal(t, remotePackage.RecordSha256Hex, fetchedHashes(request)[conflict.RecordID])
+}
+
+func testConflictKeepLocal(t *testing.T, fixture Fixture) {
+	conflict, _, ok := processRecordLevelConflict(t, fixture)
+	if !ok {
+		return
+	}
+	recordHash, err := fixture.ConflictRepo.ResolveConflict(conflict.ConflictID, syncapi.ConflictResolutionChoiceLocal, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, conflict.Local.RecordHash, recordHash)
+	conflicts, err := fixture.ConflictRepo.FindConflicts("*node-spoke1")
+	assert.Nil(t, err)
+	assert.Empty(t, conflicts)
+
+	//The version kept is sent to the peer, carrying the peer's version as the one last known
+	_, request := queueAndFetch(t, fixture, "*node-spoke1", syncapi.ProcessSyncChangeEnumAddOrUpdate)
+	if request == nil {
+		return
+	}
+	msg := findFetchedMsg(request, conflict.RecordID)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, conflict.Local.RecordHash, msg.GetRecordHash())
+		assert.Equal(t, conflict.Remote.RecordHash, msg.GetLastKnownPeerHash())
+	}
+}
+
+func testConflictResolverRefused(t *testing.T, fixture Fixture) {
+	//The pair was given 'builtin:lastWriterWins' before the uri was refused, which the dao does not check
+	syncPairDao := fixture.Daos.SyncPairDao()
+	if !assert.Nil(t, syncPairDao.UpdatePair(syncdao.SyncPair{PairID: "*pair-1", PairName: "A <-> Z", SyncConflictURI: "builtin:lastWriterWins"})) {
+		return
+	}
+	created, err := syncPairDao.CreateSyncSession(syncdao.CreateSyncSessionRequest{PairID: "*pair-1", SessionID: sessionID})
+	if !assert.Nil(t, err) || !assert.Equal(t, "OK", created.Result) {
+		return
+	}
+	//The sync goes on, leaving the conflict for manual resolution
+	processRecordLevelConflict(t, fixture)
+}
+
+func testLocalDelete(t *testing.T, fixture Fixture) {
+	nodeID := "*node-spoke1"
+	if !assert.Nil(t, fixture.Local.MarkSyncStateDeleted("Contact", mindyJohnson.ContactID)) {
+		return
+	}
+	_, request := queueAndFetch(t, fixture, nodeID, syncapi.ProcessSyncChangeEnumDelete)
+	if request == nil {
+		return
+	}
+	assert.True(t, request.GetIsDelete())
+	msg := findFetchedMsg(request, mindyJohnson.ContactID)
+	if !assert.NotNil(t, msg) {
+		return
+	}
+	assert.Equal(t, syncmsg.SentSyncStateEnum_PersistedFastDeleted, msg.GetSentSyncState())
+	//The record data is still sent so the peer can find the record to delete
+	assert.NotEmpty(t, msg.RecordData)
+
+	//Until the peer acknowledges the delete, the tombstone is kept for the retention window
+	result, err := fixture.TombstoneRepo.CollectTombstones(24 * time.Hour)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 0, result.TotalPurged)
+	if !acknowledgeProcessed(t, fixture, nodeID, request) {
+		return
+	}
+	result, err = fixture.TombstoneRepo.CollectTombstones(24 * time.Hour)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 1, result.TotalPurged)
+	assert.Equal(t, 1, result.PurgedByEntity["Contact"])
+
+	_, request = queueAndFetch(t, fixture, nodeID, syncapi.ProcessSyncChangeEnumDelete)
+	if request == nil {
+		return
+	}
+	assert.Empty(t, fetchedHashes(request))
+}
+
+func testPeerDelete(t *testing.T, fixture Fixture) {
+	nodeID := "*node-spoke1"
+	deletedPackage := contactPackage(t, mindyJohnson, "")
+	deletedPackage.PeerLastKnownHash = deletedPackage.RecordSha256Hex
+
+	response := process(t, fixture, nodeID, contactRequest(true, deletedPackage))
+	if response == nil {
+		return
+	}
+	assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_OK, response.GetResult())
+	assert.Equal(t, "All records are fast batch", response.GetResultMsg())
+
+	//The delete is not sent back to the peer it came from, whose delete is the acknowledgement
+	_, request := queueAndFetch(t, fixture, nodeID, syncapi.ProcessSyncChangeEnumDelete)
+	if request == nil {
+		return
+	}
+	assert.Empty(t, fetchedHashes(request))
+	result, err := fixture.TombstoneRepo.CollectTombstones(24 * time.Hour)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 1, result.TotalPurged)
+}
+
+func testUnknownNode(t *testing.T, fixture Fixture) {
+	msgProcessor, err := fixture.DataRepo.CreateMessageProcessor(sessionID, "*node-unknown", suiteEntitiesByPluralName)
+	if !assert.Nil(t, err) {
+		return
+	}
+	request := &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(false),
+		TransactionBindId: proto.String("some-bind-id"),
+		Items:             []*syncmsg.ProtoSyncDataMessagesRequest{},
+	}
+	response := msgProcessor.Process(request)
+	assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_Error, response.GetResult(), "a node without a data version cannot be processed")
+	_, err = fixture.DataRepo.CreateMessageFetcher(sessionID, "*node-unknown", syncapi.FetchLimits{})
+	assert.NotNil(t, err, "a node without batch sizes cannot be fetched for")
+}
+
+//queueAndFetch queues the changes for nodeID and fetches them in one batch. The request is nil when either failed.
+func queueAndFetch(t *testing.T, fixture Fixture, nodeID string, changeType syncapi.ProcessSyncChangeEnum) (int, *syncmsg.ProtoSyncEntityMessageRequest) {
+	msgQueuer, err := fixture.DataRepo.CreateMessageQueuer(sessionID, nodeID)
+	if !assert.Nil(t, err) {
+		return 0, nil
+	}
+	recordsQueued, err := msgQueuer.Queue(sessionID, nodeID)
+	if !assert.Nil(t, err) {
+		return 0, nil
+	}
+	fetcher, err := fixture.DataRepo.CreateMessageFetcher(sessionID, nodeID, syncapi.FetchLimits{MaxMsgs: 100})
+	if !assert.Nil(t, err) {
+		return 0, nil
+	}
+	answer, err := fetcher.Fetch(suiteEntities, changeType)
+	if !assert.Nil(t, err) {
+		return 0, nil
+	}
+	return recordsQueued, answer.Request
+}
+
+//acknowledgeProcessed acknowledges request with the response the processor gives for it. The suite has a single store,
+//so it stands in for the peer too: every record of request is sent back as one the peer already knew of, which the
+//processor persists as a fast batch. The peer has its own session, leaving the progress of the local one untouched.
+func acknowledgeProcessed(t *testing.T, fixture Fixture, nodeID string, request *syncmsg.ProtoSyncEntityMessageRequest) bool {
+	peerRequest := proto.Clone(request).(*syncmsg.ProtoSyncEntityMessageRequest)
+	for _, item := range peerRequest.Items {
+		for _, msg := range item.Msgs {
+			if !peerRequest.GetIsDelete() {
+				msg.SentSyncState = syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer.Enum()
+			}
+			msg.LastKnownPeerHash = proto.String(msg.GetRecordHash())
+		}
+	}
+	msgProcessor, err := fixture.DataRepo.CreateMessageProcessor(peerSessionID, nodeID, suiteEntitiesByPluralName)
+	if !assert.Nil(t, err) {
+		return false
+	}
+	response := msgProcessor.Process(peerRequest)
+	if !assert.Equal(t, "All records are fast batch", response.GetResultMsg()) {
+		return false
+	}
+	acknowledger, err := fixture.DataRepo.CreateMessageAcknowledger(sessionID, nodeID)
+	if !assert.Nil(t, err) {
+		return false
+	}
+	answer := acknowledger.Acknowledge(response)
+	return assert.Equal(t, syncmsg.SyncEntityMessageResponseResult_OK, answer.GetResult(), "acknowledging: %v", answer.GetResultMsg())
+}
+
+//process processes request as sent by nodeID. The response is nil when the processor could not be created.
+func process(t *testing.T, fixture Fixture, nodeID string, request *syncmsg.ProtoSyncEntityMessageRequest) *syncmsg.ProtoSyncEntityMessageResponse {
+	msgProcessor, err := fixture.DataRepo.CreateMessageProcessor(sessionID, nodeID, suiteEntitiesByPluralName)
+	if !assert.Nil(t, err) {
+		return nil
+	}
+	return msgProcessor.Process(request)
+}
+
+func assertOneResponseMsg(t *testing.T, response *syncmsg.ProtoSyncEntityMessageResponse) bool {
+	return assert.Len(t, response.Items, 1) && assert.Len(t, response.Items[0].Msgs, 1)
+}
+
+//fetchedHashes gives the hash of every record of request by record id.
+func fetchedHashes(request *syncmsg.ProtoSyncEntityMessageRequest) map[string]string {
+	answer := map[string]string{}
+	for _, item := range request.Items {
+		for _, msg := range item.Msgs {
+			answer[msg.GetRecordId()] = msg.GetRecordHash()
+		}
+	}
+	return answer
+}
+
+func findFetchedMsg(request *syncmsg.ProtoSyncEntityMessageRequest, recordID string) *syncmsg.ProtoSyncDataMessageRequest {
+	for _, item := range request.Items {
+		for _, msg := range item.Msgs {
+			if msg.GetRecordId() == recordID {
+				return msg
+			}
+		}
+	}
+	return nil
+}
+
+//contactPackage gives the contact as sent by a peer knowing the version with peerLastKnownHash, or as sent for the
+//first time when peerLastKnownHash is empty.
+func contactPackage(t *testing.T, contact testhelper.Contact, peerLastKnownHash string) testhelper.ContactSyncPackage {
+	sentState := syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer
+	if peerLastKnownHash == "" {
+		sentState = syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer
+	}
+	contactSyncPackage, err := testhelper.CreateRecordAndSupport(contact, true, sentState, peerLastKnownHash)
+	if err != nil {
+		t.Fatal("Marshaling error: " + err.Error())
+	}
+	return contactSyncPackage
+}
+
+//contactRequest creates the request of a peer sending the contacts of contactSyncPackages.
+func contactRequest(isDelete bool, contactSyncPackages ...testhelper.ContactSyncPackage) *syncmsg.ProtoSyncEntityMessageRequest {
+	msgs := []*syncmsg.ProtoSyncDataMessageRequest{}
+	for _, contactSyncPackage := range contactSyncPackages {
+		sentSyncState := syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer
+		switch {
+		case isDelete:
+			sentSyncState = syncmsg.SentSyncStateEnum_PersistedFastDeleted
+		case contactSyncPackage.PeerLastKnownHash == "":
+			sentSyncState = syncmsg.SentSyncStateEnum_PersistedFirstTimeSentToPeer
+		}
+		msg := &syncmsg.ProtoSyncDataMessageRequest{
+			RecordId:        proto.String(contactSyncPackage.Contact.ContactID),
+			RecordHash:      proto.String(contactSyncPackage.RecordSha256Hex),
+			SentSyncState:   sentSyncState.Enum(),
+			RecordBytesSize: proto.Uint32(uint32(contactSyncPackage.RecordBytesLen())),
+			RecordData:      contactSyncPackage.RecordBytes,
+		}
+		if contactSyncPackage.PeerLastKnownHash != "" {
+			msg.LastKnownPeerHash = proto.String(contactSyncPackage.PeerLastKnownHash)
+		}
+		msgs = append(msgs, msg)
+	}
+	return &syncmsg.ProtoSyncEntityMessageRequest{
+		IsDelete:          proto.Bool(isDelete),
+		TransactionBindId: proto.String("some-bind-id"),
+		Items: []*syncmsg.ProtoSyncDataMessagesRequest{
+			&syncmsg.ProtoSyncDataMessagesRequest{
+				EntityPluralName: proto.String("Contacts"),
+				Msgs:             msgs,
+			},
+		},
+	}
+}
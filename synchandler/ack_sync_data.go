@@ -4,6 +4,7 @@ import (
 	"data-sync-tools-go/syncmsg"
 	"data-sync-tools-go/syncutil"
 	"errors"
+	"fmt"
 	"io/ioutil"
 
 	"github.com/golang/protobuf/proto"
@@ -12,10 +13,10 @@ import (
 
 import "net/http"
 
-//AcknowledgeSyncData marks the final results of a processed sync data batch.
+//AcknowledgeSyncData marks the final results of a processed sync data batch. The request body is the
+//ProtoSyncEntityMessageResponse the peer created when processing the batch fetched under transactionBindId and the
+//response body is a ProtoSyncEntityMessageResponse telling whether the acknowledgement was persisted.
 func (handlers Handlers) AcknowledgeSyncData(w http.ResponseWriter, r *http.Request) {
-	syncutil.Info("Entering AcknowledgeSyncData")
-
 	vars := mux.Vars(r)
 
 	var validArgs ackSyncDataArgs
@@ -44,7 +45,38 @@ func (handlers Handlers) AcknowledgeSyncData(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	syncutil.Info("Hi from AcknowledgeSyncData, validArgs:", validArgs, ", requestData:", requestData)
+	if validArgs.transactionBindID != requestData.GetTransactionBindId() {
+		msg := fmt.Sprintf("Transaction Id '%s' in request URL is different than that in the data '%s'", validArgs.transactionBindID, requestData.GetTransactionBindId())
+		syncutil.Error(msg)
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	if handlers.Repository.DataRepo == nil {
+		msg := "DataRepo is nil"
+		syncutil.Error(msg)
+		http.Error(w, msg, http.StatusInternalServerError)
+		return
+	}
+	acknowledger, err := handlers.Repository.DataRepo.CreateMessageAcknowledger(validArgs.sessionID, validArgs.nodeIDToProcess)
+	if err != nil {
+		msg := "Could not create acknowledger." + err.Error()
+		syncutil.Error(msg)
+		http.Error(w, msg, http.StatusInternalServerError)
+		return
+	}
+
+	answer := acknowledger.Acknowledge(requestData)
+	data, err := proto.Marshal(answer)
+	if err != nil {
+		syncutil.Error("Error readying response: " + err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
 }
 
 func processAckSyncDataArgs(vars map[string]string) (ackSyncDataArgs, error) {
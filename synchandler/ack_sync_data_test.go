@@ -62,6 +62,14 @@ func readyAckSyncDataOK() *httptest.Server {
 				},
 			},
 		},
+		acknowledger: mockMessageAcknowledger{
+			acknowledgerAnswer: &syncmsg.ProtoSyncEntityMessageResponse{
+				TransactionBindId: proto.String("0F16AEED-E4B4-483E-A7A3-CCABA831FE6E"),
+				Result:            syncmsg.SyncEntityMessageResponseResult_OK.Enum(),
+				ResultMsg:         proto.String("2 records acknowledged"),
+				Items:             []*syncmsg.ProtoSyncDataMessagesResponse{},
+			},
+		},
 		//createFetcherError: nil,
 	}
 
@@ -152,13 +160,15 @@ func TestHandlers_AcknowledgeSyncDataOK(t *testing.T) {
 		return
 	}
 
-	// responseData := &syncmsg.ProtoSyncEntityMessageResponse{}
-	// err = proto.Unmarshal(responseBytes, responseData)
-	// if err != nil {
-	// 	t.Errorf("Error decoding response: %s", err.Error())
-	// 	return
-	// }
-	//syncutil.Debug("responseData: ", responseData)
+	responseData := &syncmsg.ProtoSyncEntityMessageResponse{}
+	err = proto.Unmarshal(responseBytes, responseData)
+	if err != nil {
+		t.Errorf("Error decoding response: %s", err.Error())
+		return
+	}
+	if responseData.GetResult() != syncmsg.SyncEntityMessageResponseResult_OK {
+		t.Errorf("Result not expected '%v'. it's '%v'", syncmsg.SyncEntityMessageResponseResult_OK, responseData)
+	}
 
 	testhelper.EndTest(testName)
 }
@@ -15,4 +15,6 @@ const (
 	BadOrderNumConversion = "Parameter 'orderNum' cannot be converted to a number. Supplied value: %v"
 	//BadChangeTypeConversion denotes a bad value for parameter 'changeType'
 	BadChangeTypeConversion = "Parameter 'changeType' cannot be converted to a syncapi.ProcessSyncChangeEnumAddOrUpdate. Supplied value: %v"
+	//BadPositiveNumConversion denotes a bad value for an optional positive number parameter such as 'maxMsgs'
+	BadPositiveNumConversion = "Parameter '%v' must be a positive number. Supplied value: %v"
 )
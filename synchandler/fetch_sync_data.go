@@ -13,13 +13,15 @@ import (
 	//"io"
 
 	"net/http"
+	"net/url"
 	//"strconv"
 )
 
 //FetchSyncData retrieves data to be processed for syncing.
 //func FetchSyncData(w http.ResponseWriter, r *http.Request) {
 
-//FetchSyncData retrieves data to be processed for syncing.
+//FetchSyncData retrieves data to be processed for syncing. The optional query parameters 'maxMsgs' and 'maxBytes'
+//override the batch sizes configured for the node.
 //	{httpbase}/fetchData/sessionId/{sessionId}/nodeId/{nodeId}/orderItem/{orderItem}/{changeType:AddOrUpdate|Delete}/
 //	curl -H "Content-Type: application/json" --request GET http://localhost:8080/fetchData/sessionId/8AD65ECB-5826-4CC9-B54D-0723A7FC99B9/nodeId/B616BEB5-341E-4701-862F-006EEA0230C0/orderItem/{orderItem}/changeType/AddOrUpdate
 func (handlers Handlers) FetchSyncData(w http.ResponseWriter, r *http.Request) {
@@ -29,6 +31,9 @@ func (handlers Handlers) FetchSyncData(w http.ResponseWriter, r *http.Request) {
 
 	var validArgs fetchSyncDataArgs
 	validArgs, err := processFetchSyncDataArgs(vars)
+	if err == nil {
+		validArgs.limits, err = processFetchLimitsArgs(r.URL.Query())
+	}
 	if err != nil {
 		msg := err.Error()
 		syncutil.Error(msg)
@@ -49,6 +54,7 @@ func (handlers Handlers) FetchSyncData(w http.ResponseWriter, r *http.Request) {
 		errMsg := err.Error()
 		syncutil.Error(errMsg)
 		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
 	}
 
 	var answer *syncmsg.ProtoRequestSyncEntityMessageResponse
@@ -56,9 +62,11 @@ func (handlers Handlers) FetchSyncData(w http.ResponseWriter, r *http.Request) {
 	answer, err = msgFetcher.Fetch(entities, validArgs.changeType)
 	if err != nil {
 		syncutil.Error(err)
-		answer.Result = syncmsg.SyncRequestEntityMessageResponseResult_ErrorCreatingMsgs.Enum()
-		answer.ResultMsg = proto.String(err.Error())
-		data, err := proto.Marshal(answer)
+		errAnswer := &syncmsg.ProtoRequestSyncEntityMessageResponse{
+			Result:    syncmsg.SyncRequestEntityMessageResponseResult_ErrorCreatingMsgs.Enum(),
+			ResultMsg: proto.String(err.Error()),
+		}
+		data, err := proto.Marshal(errAnswer)
 		if err != nil {
 			syncutil.Error("Error readying response: " + err.Error())
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -134,6 +142,34 @@ func processFetchSyncDataArgs(vars map[string]string) (fetchSyncDataArgs, error)
 	return answer, nil
 }
 
+//processFetchLimitsArgs reads the optional 'maxMsgs' and 'maxBytes' query parameters.
+func processFetchLimitsArgs(query url.Values) (syncapi.FetchLimits, error) {
+	answer := syncapi.FetchLimits{}
+	var err error
+	answer.MaxMsgs, err = processPositiveIntArg(query, "maxMsgs")
+	if err != nil {
+		return answer, err
+	}
+	answer.MaxGroupBytesSize, err = processPositiveIntArg(query, "maxBytes")
+	if err != nil {
+		return answer, err
+	}
+	return answer, nil
+}
+
+//processPositiveIntArg reads an optional positive number from the query, giving zero when it is not supplied.
+func processPositiveIntArg(query url.Values, name string) (int, error) {
+	valueStr := query.Get(name)
+	if valueStr == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseInt(valueStr, 10, 32)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf(BadPositiveNumConversion, name, valueStr)
+	}
+	return int(value), nil
+}
+
 func createFetchersForProcessFetchSyncData(validArgs fetchSyncDataArgs, repo syncapi.Repository) (syncapi.EntityFetching, syncapi.MessageFetching, error) {
 	var entityFetcher syncapi.EntityFetching
 	var msgFetcher syncapi.MessageFetching
@@ -145,7 +181,7 @@ func createFetchersForProcessFetchSyncData(validArgs fetchSyncDataArgs, repo syn
 		msg := ctxMsg + err.Error()
 		return entityFetcher, msgFetcher, errors.New(msg)
 	}
-	msgFetcher, err = repo.DataRepo.CreateMessageFetcher(validArgs.sessionID, validArgs.nodeIDToProcess)
+	msgFetcher, err = repo.DataRepo.CreateMessageFetcher(validArgs.sessionID, validArgs.nodeIDToProcess, validArgs.limits)
 	if err != nil {
 		ctxMsg := "Could not create msgFetcher."
 		syncutil.Error(ctxMsg, err)
@@ -160,4 +196,5 @@ type fetchSyncDataArgs struct {
 	nodeIDToProcess string
 	orderNum        int64
 	changeType      syncapi.ProcessSyncChangeEnum
+	limits          syncapi.FetchLimits
 }
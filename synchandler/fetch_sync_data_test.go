@@ -1,6 +1,5 @@
 package synchandler
 
-// TODO(doug4j@gmail.com): Transition handler testing to use a mock/stub syncdao rather than syncdaopq
 import (
 	"data-sync-tools-go/syncapi"
 	"data-sync-tools-go/syncmsg"
@@ -192,14 +191,8 @@ func readyFetchSyncDataErrorCreatingMsgs(expectedErrorMsg string) *httptest.Serv
 
 	dataRepo := mockDataRepository{
 		fetcher: mockSyncMessageFetcher{
-			fetchAnswer: &syncmsg.ProtoRequestSyncEntityMessageResponse{
-				Request: &syncmsg.ProtoSyncEntityMessageRequest{
-					IsDelete:          proto.Bool(false),
-					TransactionBindId: proto.String("some-bind-id"),
-					Items:             []*syncmsg.ProtoSyncDataMessagesRequest{},
-				},
-			},
-			fetchError: errors.New(expectedErrorMsg),
+			fetchAnswer: nil,
+			fetchError:  errors.New(expectedErrorMsg),
 		},
 		createFetcherError: nil,
 	}
@@ -466,3 +459,98 @@ func TestHandlers_FetchSyncDataBadOrderNumConversion(t *testing.T) {
 
 	testhelper.EndTest(testName)
 }
+
+func TestHandlers_FetchSyncDataLimits(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	var createdWith syncapi.FetchLimits
+	handlers := Handlers{
+		Repository: syncapi.Repository{
+			DataRepo: mockDataRepository{
+				fetcher: mockSyncMessageFetcher{
+					fetchAnswer: &syncmsg.ProtoRequestSyncEntityMessageResponse{
+						Result:    syncmsg.SyncRequestEntityMessageResponseResult_NoMsgs.Enum(),
+						ResultMsg: proto.String(""),
+					},
+				},
+				fetcherCreatedWith: &createdWith,
+			},
+			ConfigRepo: mockConfigRepository{
+				fetcher: mockEntityFetcher{findForFetchAnswer: []syncapi.EntityNameItem{}},
+			},
+		},
+	}
+	server := httptest.NewServer(NewRouter(handlers))
+	defer server.Close()
+	fetchURL := server.URL + "/fetchData/sessionId/F32A9BB9-7691-4F20-B337-55414E45B1D1/nodeId/9702F991-F1E7-4186-A97B-8B804F723F87/orderNum/1/changeType/AddOrUpdate/"
+
+	res, err := http.Get(fetchURL + "?maxMsgs=25&maxBytes=4096")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, syncapi.FetchLimits{MaxGroupBytesSize: 4096, MaxMsgs: 25}, createdWith)
+
+	res, err = http.Get(fetchURL)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, syncapi.FetchLimits{}, createdWith, "without overrides the node's batch sizes are kept")
+
+	res, err = http.Get(fetchURL + "?maxMsgs=0")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	responseBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	assert.Equal(t, fmt.Sprintf(BadPositiveNumConversion, "maxMsgs", "0")+"\n", string(responseBytes))
+
+	testhelper.EndTest(testName)
+}
+
+func TestHandlers_FetchSyncDataErrorFindingEntities(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	handlers := Handlers{
+		Repository: syncapi.Repository{
+			DataRepo: mockDataRepository{
+				fetcher: mockSyncMessageFetcher{
+					fetchAnswer: &syncmsg.ProtoRequestSyncEntityMessageResponse{
+						Result:    syncmsg.SyncRequestEntityMessageResponseResult_NoMsgs.Enum(),
+						ResultMsg: proto.String(""),
+					},
+				},
+			},
+			ConfigRepo: mockConfigRepository{
+				fetcher: mockEntityFetcher{findForFetchError: errors.New("Some Error")},
+			},
+		},
+	}
+	server := httptest.NewServer(NewRouter(handlers))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/fetchData/sessionId/F32A9BB9-7691-4F20-B337-55414E45B1D1/nodeId/9702F991-F1E7-4186-A97B-8B804F723F87/orderNum/1/changeType/AddOrUpdate")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	responseBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	assert.Equal(t, "Some Error\n", string(responseBytes), "nothing is fetched after the error")
+
+	testhelper.EndTest(testName)
+}
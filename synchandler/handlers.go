@@ -7,6 +7,7 @@ import (
 	"data-sync-tools-go/syncutil"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 //RequestParmParser abstracts out the parsing of parsing HTTP request args
@@ -28,6 +29,8 @@ func (parser RequestParmParserType) ParseParmsToMap(r *http.Request) map[string]
 type Handlers struct {
 	syncapi.Repository
 	VarsHandler func(*http.Request) map[string]string
+	//TombstoneRetention is the retention window used when collecting tombstones without one in the request.
+	TombstoneRetention time.Duration
 }
 
 //Index processes HTTP requests for a base url to the configured hostname and application
@@ -44,8 +47,10 @@ type SyncSessionState struct {
 //SyncSessionMgmtMsg contains one way messages for management purposes used for signaling of state changes between nodes
 //MessageTypes include the following: 1-Heartbeat, 2-SeedingClosed, 3-Cancel, 4-HaultingError, 5-Start.
 //Optional fields: Result.
-//Result possible values:
+//Result possible values: any text of the sender, such as the reason of a Cancel or a HaultingError.
+//MsgSeq orders the messages of a session, see SendSyncSessionMgmtMsg and PollSyncSessionMgmtMsgs.
 type SyncSessionMgmtMsg struct {
+	MsgSeq      int64  `json:"msgSeq"`
 	SessionID   string `json:"sessionId"`
 	SenderNode  string `json:"senderNode"`
 	MessageType int    `json:"messageType"`
@@ -1,6 +1,7 @@
 package synchandler
 
 import (
+	"data-sync-tools-go/syncapi"
 	"data-sync-tools-go/syncdao"
 	"data-sync-tools-go/syncutil"
 	"encoding/json"
@@ -9,6 +10,7 @@ import (
 	//"io/ioutil"
 
 	"net/http"
+	"strconv"
 )
 
 //MARK: CreateSyncSession Processing START
@@ -91,6 +93,7 @@ type CreateSyncSessionResponse struct {
 //MARK: UpdateSyncSessionState Processing START
 
 // UpdateSyncSessionState updates an active sync session. Valid states include: 'Inactive', 'Initializing', 'Seeding', 'Queuing', 'Syncing', or 'Canceling'.
+// A session only moves along syncapi.SyncSessionStateTransitions. Any other move answers with the result 'IllegalStateTransition'.
 // Invoked performed via the following http command:
 //	curl -i --header "Content-Type: application/json" --request PUT http://localhost:8080/SyncSession/sessionId/66091BCC-E470-41BC-9025-9686514CD4B1/state/queuing
 func UpdateSyncSessionState(w http.ResponseWriter, r *http.Request) {
@@ -165,162 +168,111 @@ type UpdateSyncSessionStateResponse struct {
 //MARK: UpdateSyncSession Processing END
 
 //MARK: UpdateSyncSessionStateWithTotals Processing START
-/*
-curl -i --header "Content-Type: application/json" --request PUT http://localhost:8080/SyncSession/sessionId/66091BCC-E470-41BC-9025-9686514CD4B1/state/queuing
-*/
 
-// BUG(doug4j@gmail.com): Create UpdateSyncSessionStateWithTotals:
-/*
+//UpdateSyncSessionStateWithTotals sets how many records, and of how many bytes, the active session syncs in total.
+//Direction 1 is from the node to its peer in the pair, direction 2 from the peer to the node. Queuing, processing and
+//acknowledging keep the totals up to date on their own; this lets a node report totals it knows better. Invoked
+//performed via the following http command:
+//	curl -i --header "Content-Type: application/json" --request PUT http://localhost:8080/syncSession/sessionId/66091BCC-E470-41BC-9025-9686514CD4B1/pairId/08751B44-289E-45C9-A4F9-F02F14A5D490/nodeId/*node-spoke1/totals/recordBytes1/2048/recordCount1/10/recordBytes2/0/recordCount2/0
 func UpdateSyncSessionStateWithTotals(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	var (
-		sessionId string
-		pairId    string
-		state     string
-		hasValue  bool
-		err       error
-	)
-	sessionId, hasValue = vars["sessionId"]
-	if !hasValue {
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-	state, hasValue = vars["state"]
-	if !hasValue {
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-	pairId, hasValue = vars["pairId"]
-	if !hasValue {
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-	var request = UpdateSyncSessionStateRequest{
-		SessionId: sessionId,
-		State:     state,
-		PairId:    pairId,
-	}
-	var responseJson UpdateSyncSessionStateResponse
-	if dbSetupError(w) {
-		return
-	}
-	response, err := DefaultDaos.SyncPairDao().UpdateSyncSessionState(request)
-	if err != nil {
-		errMsg := "Could not persist data to database:" + err.Error()
-		responseJson = UpdateSyncSessionStateResponse{
-			SessionId:      sessionId,
-			RequestedState: state,
-			Result:         "UpdateSyncSessionUnknownError",
-			ResultMsg:      errMsg,
-		}
-		//log.Println(errMsg)
-	} else {
-		responseJson = UpdateSyncSessionStateResponse{
-			SessionId:      sessionId,
-			RequestedState: state,
-			Result:         response.Result,
-			ResultMsg:      response.ResultMsg,
-		}
-	}
-	json.NewEncoder(w).Encode(responseJson)
-	return
+	updateSyncing(w, r, func(dao syncdao.SyncPairDao, args updateSyncingArgs) (syncdao.UpdateSyncingResult, error) {
+		return dao.UpdateSyncingWithTotals(syncdao.UpdateSyncingRequestWithTotals(args))
+	})
 }
-*/
 
 //MARK: UpdateSyncSessionStateWithTotals Processing END
 
 //MARK: UpdateSyncSessionStateWithProcessed Processing START
-/*
-curl -i --header "Content-Type: application/json" --request PUT http://localhost:8080/SyncSession/sessionId/66091BCC-E470-41BC-9025-9686514CD4B1/state/queuing
-*/
 
-// BUG(doug4j@gmail.com): Create UpdateSyncSessionStateWithProcessed:
-/*
+//UpdateSyncSessionStateWithProcessed sets how many records, and of how many bytes, the active session processed so
+//far. The directions are those of UpdateSyncSessionStateWithTotals. Invoked performed via the following http command:
+//	curl -i --header "Content-Type: application/json" --request PUT http://localhost:8080/syncSession/sessionId/66091BCC-E470-41BC-9025-9686514CD4B1/pairId/08751B44-289E-45C9-A4F9-F02F14A5D490/nodeId/*node-spoke1/processed/recordBytes1/1024/recordCount1/5/recordBytes2/0/recordCount2/0
 func UpdateSyncSessionStateWithProcessed(w http.ResponseWriter, r *http.Request) {
+	updateSyncing(w, r, func(dao syncdao.SyncPairDao, args updateSyncingArgs) (syncdao.UpdateSyncingResult, error) {
+		return dao.UpdateSyncingWithProcessed(syncdao.UpdateSyncingRequestWithProcessed(args))
+	})
+}
+
+//updateSyncingArgs holds the path arguments shared by the totals and processed routes. Its fields are those of
+//syncdao.UpdateSyncingRequestWithTotals and syncdao.UpdateSyncingRequestWithProcessed.
+type updateSyncingArgs struct {
+	PairID       string `json:"pairId"`
+	SessionID    string `json:"sessionId"`
+	NodeID       string `json:"nodeId"`
+	RecordBytes1 int    `json:"recordBytes1"`
+	RecordCount1 int    `json:"recordCount1"`
+	RecordBytes2 int    `json:"recordBytes2"`
+	RecordCount2 int    `json:"recordCount2"`
+}
+
+func updateSyncing(w http.ResponseWriter, r *http.Request, update func(dao syncdao.SyncPairDao, args updateSyncingArgs) (syncdao.UpdateSyncingResult, error)) {
 	vars := mux.Vars(r)
-	var (
-		sessionId    string
-		pairId       string
-		recordBytes1 string
-		recordBytes2 string
-		recordCount1 string
-		recordCount2 string
-		hasValue     bool
-		err          error
-	)
-	sessionId, hasValue = vars["sessionId"]
-	if !hasValue {
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-	pairId, hasValue = vars["pairId"]
-	if !hasValue {
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-	recordBytes1, hasValue = vars["recordBytes1"]
-	if !hasValue {
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-	recordBytes2, hasValue = vars["recordBytes2"]
-	if !hasValue {
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		w.WriteHeader(http.StatusNotFound)
-		return
+	args := updateSyncingArgs{
+		PairID:    vars["pairId"],
+		SessionID: vars["sessionId"],
+		NodeID:    vars["nodeId"],
+	}
+	var err error
+	for name, value := range map[string]*int{
+		"recordBytes1": &args.RecordBytes1,
+		"recordCount1": &args.RecordCount1,
+		"recordBytes2": &args.RecordBytes2,
+		"recordCount2": &args.RecordCount2,
+	} {
+		*value, err = strconv.Atoi(vars[name])
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
 	}
-	recordCount1, hasValue = vars["recordCount1"]
-	if !hasValue {
+	if args.PairID == "" || args.SessionID == "" || args.NodeID == "" {
 		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
-	recordCount2, hasValue = vars["recordCount2"]
-	if !hasValue {
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		w.WriteHeader(http.StatusNotFound)
+	err = syncapi.ValidateSyncingCounts(args.RecordBytes1, args.RecordCount1, args.RecordBytes2, args.RecordCount2)
+	if err != nil {
+		syncutil.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	var request = UpdateSyncingRequestWithProcessed{
-		SessionId:    sessionId,
-		PairId:       pairId,
-		RecordBytes1: recordBytes1,
-		RecordCount1: recordCount1,
-		RecordBytes2: recordBytes2,
-		RecordCount2: recordCount2,
-	}
-	var responseJson UpdateSyncSessionStateResponse
 	if dbSetupError(w) {
 		return
 	}
-	response, err := DefaultDaos.SyncPairDao().UpdateSyncingWithProcessed(request)
+	var answer UpdateSyncingResponse
+	result, err := update(syncdao.DefaultDaos.SyncPairDao(), args)
 	if err != nil {
-		errMsg := "Could not persist data to database:" + err.Error()
-		responseJson = UpdateSyncSessionStateResponse{
-			SessionId:      sessionId,
-			RequestedState: "Syncing",
-			Result:         "UpdateSyncSessionUnknownError",
-			ResultMsg:      errMsg,
+		answer = UpdateSyncingResponse{
+			PairID:    args.PairID,
+			SessionID: args.SessionID,
+			Result:    "UpdateSyncingUnknownError",
+			ResultMsg: "Could not persist data to database:" + err.Error(),
 		}
-		//log.Println(errMsg)
 	} else {
-		responseJson = UpdateSyncSessionStateResponse{
-			SessionId:      sessionId,
-			RequestedState: "Syncing",
-			Result:         response.Result,
-			ResultMsg:      response.ResultMsg,
+		answer = UpdateSyncingResponse{
+			PairID:    args.PairID,
+			SessionID: args.SessionID,
+			Result:    result.Result,
+			ResultMsg: result.ResultMsg,
 		}
 	}
-	json.NewEncoder(w).Encode(responseJson)
+	err = json.NewEncoder(w).Encode(answer)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
 	return
 }
-*/
+
+//UpdateSyncingResponse represents the answer to an UpdateSyncingRequestWithTotals or UpdateSyncingRequestWithProcessed
+//in dao.go.
+type UpdateSyncingResponse struct {
+	PairID    string `json:"pairId"`
+	SessionID string `json:"sessionId"`
+	//Valid values: 'OK', 'CouldNoFindActiveSessionToUpdate', 'CouldNoFindNodeInPair' or 'UpdateSyncingUnknownError'
+	Result    string `json:"result"`
+	ResultMsg string `json:"resultMsg"`
+}
 
 //MARK: UpdateSyncSessionStateWithProcessed Processing END
 
@@ -362,11 +314,13 @@ func GetPairState(w http.ResponseWriter, r *http.Request) {
 		//log.Println(errMsg)
 	} else {
 		answer = QueryPairStateResponse{
-			PairID:    request.PairID,
-			State:     result.State,
-			SessionID: result.SessionID,
-			Result:    "OK",
-			ResultMsg: "",
+			PairID:          request.PairID,
+			State:           result.State,
+			SessionID:       result.SessionID,
+			Progress:        result.Progress,
+			PercentComplete: syncdao.PercentComplete(result.Progress),
+			Result:          "OK",
+			ResultMsg:       "",
 		}
 	}
 	err = json.NewEncoder(w).Encode(answer)
@@ -383,6 +337,10 @@ type QueryPairStateResponse struct {
 	SessionID    string `json:"sessionId"`
 	SessionStart int    `json:"sessionStart"`
 	LastUpdated  int    `json:"lastUpdated"`
+	//Progress has the progress of the active, or last, session in each direction of the pair
+	Progress []syncdao.SessionProgress `json:"progress"`
+	//PercentComplete is the percent, 0 to 100, of the records of the session processed in both directions
+	PercentComplete int `json:"percentComplete"`
 
 	// BUG(doug4j@gmail.com): Create enum CreateNodeResponseType:
 	//case OK = "OK"
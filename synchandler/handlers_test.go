@@ -1,12 +1,10 @@
 package synchandler
 
-// TODO(doug4j@gmail.com): Transition handler testing to use a mock/stub syncdao rather than syncdaopq
 import (
 	"bytes"
-	"database/sql"
 	"data-sync-tools-go/syncapi"
 	"data-sync-tools-go/syncdao"
-	"data-sync-tools-go/syncdao/syncdaopq"
+	"data-sync-tools-go/syncdao/syncdaomem"
 	"data-sync-tools-go/syncmsg"
 	"data-sync-tools-go/syncutil"
 	"data-sync-tools-go/testhelper"
@@ -46,22 +44,13 @@ var (
 	syncPairConfigURL         string
 	syncDataBaseURL           string
 
-	testDbUser     = testhelper.TestDbUser
-	testDbPassword = testhelper.TestDbPassword
-	testDbName     = testhelper.TestDbName
-	testDbHost     = testhelper.TestDbHost
-	testDbPort     = testhelper.TestDbPort
+	//memoryDaos holds the profile3 sample data the handlers are tested against.
+	memoryDaos *syncdaomem.MemoryDaosFactory
 )
 
 func setupDatabaseObj() {
 	teardownDatabaseObj()
-	dbFactory, err := syncdaopq.NewPostgresSQLDaosFactory(testDbUser, testDbPassword, testDbHost, testDbName, testDbPort)
-	if err != nil {
-		panic(err)
-	}
-	syncdao.DefaultDaos = dbFactory
-	db := dbFactory.SQLDb()
-	testhelper.SetupData(db, "profile3")
+	syncdao.DefaultDaos = memoryDaos
 }
 
 func teardownDatabaseObj() {
@@ -240,14 +229,19 @@ var serviceAnswersExpecations = map[string]TestItem{
 			SessionID:    "placeholder",
 			SessionStart: 0,
 			LastUpdated:  0,
-			Result:       "OK",
-			ResultMsg:    "",
+			Progress: []syncdao.SessionProgress{
+				{NodeID: "*node-hub", TargetNodeID: "*node-spoke1"},
+				{NodeID: "*node-spoke1", TargetNodeID: "*node-hub"},
+			},
+			PercentComplete: 0,
+			Result:          "OK",
+			ResultMsg:       "",
 		},
 	},
 	"UpdateSession": TestItem{
 		ExpectedValue: UpdateSyncSessionStateResponse{
 			SessionID:      "placeholder",
-			RequestedState: "Queuing",
+			RequestedState: "Seeding",
 			Result:         "OK",
 			ResultMsg:      "",
 		},
@@ -282,32 +276,15 @@ var serviceAnswersExpecations = map[string]TestItem{
 	},
 }
 
-// TODO(doug4j@gmail.com): This will eventually be abstracted out from handler testing.
-func createAndVerifyDBConn(dbUser string, dbPassword string, dbHost string, dbName string, dbPort int) (*sql.DB, error) {
-	dbinfo := fmt.Sprintf("user=%s password=%s host=%s dbname=%s port=%v sslmode=disable", dbUser, dbPassword, dbHost, dbName, dbPort)
-	//dbinfo := fmt.Sprintf("user=%s password=%s host=%s dbname=%s sslmode=disable", "doug", "postgres", "localhost", "threads")
-	db, err := sql.Open("postgres", dbinfo)
-	if err != nil {
-		syncutil.Error(err, ". Using connection:", dbinfo)
-		return db, err
-	}
-	err = db.Ping()
-	if err != nil {
-		syncutil.Error("Cannot ping db. error:", err, ". Using connection:", dbinfo)
-		return db, err
-	}
-	return db, nil
-}
-
 func setupHTTPEnv() {
-	db, err := createAndVerifyDBConn(testDbUser, testDbPassword, testDbHost, testDbName, testDbPort)
+	memoryDaos = syncdaomem.NewMemoryDaosFactory()
+	err := testhelper.SetupSeededData(memoryDaos, "profile3")
 	if err != nil {
-		syncutil.Fatal(db)
-		panic("Cannot create database connection")
+		panic(err)
 	}
 	handlers := Handlers{
 		Repository: syncapi.Repository{
-			DataRepo: syncdaopq.NewDataRepository(db),
+			DataRepo: syncdaomem.NewDataRepository(memoryDaos),
 		},
 	}
 	server = httptest.NewServer(NewRouter(handlers)) //Creating new server with the user handlers
@@ -739,7 +716,7 @@ func TestHandlers_Session(t *testing.T) {
 	}
 
 	//Update session state
-	sessionURL = fmt.Sprintf(sessionBaseURL+"sessionId/%s/pairId/%s/state/Queuing", sessionID, "*pair-1")
+	sessionURL = fmt.Sprintf(sessionBaseURL+"sessionId/%s/pairId/%s/state/Seeding", sessionID, "*pair-1")
 	request, err = http.NewRequest("PUT", sessionURL, reader)
 	res, err = http.DefaultClient.Do(request)
 	if err != nil {
@@ -789,7 +766,7 @@ func TestHandlers_Session(t *testing.T) {
 		return
 	}
 	//log.Println("TestHandlers_Session.actualQuery", actualQuery)
-	expectedQuery.State = "Queuing"
+	expectedQuery.State = "Seeding"
 	expectedQuery.SessionID = sessionID
 	expectedQuery.SessionStart = 0
 	expectedQuery.LastUpdated = 0
@@ -27,6 +27,15 @@ func IntegrationTestReset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	dbFactory := syncdao.DefaultDaos
+	if seeder, ok := dbFactory.(testhelper.SyncModelSeeder); ok {
+		err := testhelper.SetupSeededData(seeder, testName)
+		if err != nil {
+			syncutil.Error(err, ". Error resetting test", testName)
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
 	sqlDbable, ok := dbFactory.(syncdao.SQLDbable)
 	if !ok {
 		syncutil.Error("dao factory does not contain sql SQLDbable interface")
@@ -3,19 +3,26 @@ package synchandler
 import (
 	"data-sync-tools-go/syncapi"
 	"data-sync-tools-go/syncmsg"
+	"time"
 )
 
 type mockDataRepository struct {
-	fetcher              syncapi.MessageFetching
-	processor            syncapi.MessageProcessing
-	queuer               syncapi.MessageQueuing
-	createFetcherError   error
-	createProcessorError error
-	createQueuerError    error
+	fetcher                 syncapi.MessageFetching
+	processor               syncapi.MessageProcessing
+	queuer                  syncapi.MessageQueuing
+	acknowledger            syncapi.MessageAcknowledging
+	createFetcherError      error
+	createProcessorError    error
+	createQueuerError       error
+	createAcknowledgerError error
+	fetcherCreatedWith      *syncapi.FetchLimits
 	//findForProcessAnswer map[string]syncapi.EntityNameItem
 }
 
-func (repo mockDataRepository) CreateMessageFetcher(SessionID string, NodeID string) (syncapi.MessageFetching, error) {
+func (repo mockDataRepository) CreateMessageFetcher(SessionID string, NodeID string, limits syncapi.FetchLimits) (syncapi.MessageFetching, error) {
+	if repo.fetcherCreatedWith != nil {
+		*repo.fetcherCreatedWith = limits
+	}
 	return repo.fetcher, repo.createFetcherError
 }
 
@@ -27,6 +34,10 @@ func (repo mockDataRepository) CreateMessageQueuer(sessionID string, nodeID stri
 	return repo.queuer, repo.createQueuerError
 }
 
+func (repo mockDataRepository) CreateMessageAcknowledger(sessionID string, nodeID string) (syncapi.MessageAcknowledging, error) {
+	return repo.acknowledger, repo.createAcknowledgerError
+}
+
 type mockSyncMessageFetcher struct {
 	fetchAnswer      *syncmsg.ProtoRequestSyncEntityMessageResponse
 	fetchError       error
@@ -82,3 +93,94 @@ type mockMessageQueuer struct {
 func (queuer mockMessageQueuer) Queue(sessionID string, nodeIDToQueue string) (int, error) {
 	return queuer.queuerAnswer, queuer.queueError
 }
+
+type mockMessageAcknowledger struct {
+	acknowledgerAnswer *syncmsg.ProtoSyncEntityMessageResponse
+}
+
+func (acknowledger mockMessageAcknowledger) Acknowledge(response *syncmsg.ProtoSyncEntityMessageResponse) *syncmsg.ProtoSyncEntityMessageResponse {
+	return acknowledger.acknowledgerAnswer
+}
+
+type mockConflictRepository struct {
+	findAnswer    []syncapi.ConflictItem
+	findError     error
+	getAnswer     syncapi.ConflictItem
+	getError      error
+	resolveAnswer string
+	resolveError  error
+	resolvedWith  *syncmsg.ProtoRecord
+}
+
+func (repo mockConflictRepository) FindConflicts(nodeID string) ([]syncapi.ConflictItem, error) {
+	return repo.findAnswer, repo.findError
+}
+
+func (repo mockConflictRepository) GetConflict(conflictID string) (syncapi.ConflictItem, error) {
+	return repo.getAnswer, repo.getError
+}
+
+func (repo *mockConflictRepository) ResolveConflict(conflictID string, choice syncapi.ConflictResolutionChoice, mergedRecord *syncmsg.ProtoRecord) (string, error) {
+	repo.resolvedWith = mergedRecord
+	return repo.resolveAnswer, repo.resolveError
+}
+
+type mockTombstoneRepository struct {
+	collectAnswer syncapi.TombstoneCollectResult
+	collectError  error
+	collectedWith time.Duration
+}
+
+func (repo *mockTombstoneRepository) CollectTombstones(retention time.Duration) (syncapi.TombstoneCollectResult, error) {
+	repo.collectedWith = retention
+	return repo.collectAnswer, repo.collectError
+}
+
+type mockSessionRepository struct {
+	sendAnswer    syncapi.SessionMgmtMsgResult
+	sent          []syncapi.SessionMgmtMsg
+	pollAnswer    []syncapi.SessionMgmtMsg
+	polledSession string
+	polledNode    string
+	polledAfter   int64
+	cancelAnswer  syncapi.SessionCancelResult
+	canceled      []string
+	cancelReasons []string
+}
+
+func (repo *mockSessionRepository) ReapExpiredSessions(now time.Time) (syncapi.SessionReapResult, error) {
+	return syncapi.SessionReapResult{}, nil
+}
+
+func (repo *mockSessionRepository) EndedSessions(pairID string) ([]syncapi.EndedSession, error) {
+	return []syncapi.EndedSession{}, nil
+}
+
+func (repo *mockSessionRepository) SendMgmtMsg(msg syncapi.SessionMgmtMsg) (syncapi.SessionMgmtMsgResult, error) {
+	repo.sent = append(repo.sent, msg)
+	return repo.sendAnswer, nil
+}
+
+func (repo *mockSessionRepository) PollMgmtMsgs(sessionID string, nodeID string, afterSeq int64) ([]syncapi.SessionMgmtMsg, error) {
+	repo.polledSession = sessionID
+	repo.polledNode = nodeID
+	repo.polledAfter = afterSeq
+	return repo.pollAnswer, nil
+}
+
+func (repo *mockSessionRepository) CancelSession(sessionID string, reasonMsg string, now time.Time) (syncapi.SessionCancelResult, error) {
+	repo.canceled = append(repo.canceled, sessionID)
+	repo.cancelReasons = append(repo.cancelReasons, reasonMsg)
+	return repo.cancelAnswer, nil
+}
+
+type mockNodeAdminRepository struct {
+	pendingAnswer syncapi.NodePendingChanges
+	pendingError  error
+	pendingNode   string
+}
+
+func (repo *mockNodeAdminRepository) PendingChanges(nodeID string) (syncapi.NodePendingChanges, error) {
+	repo.pendingNode = nodeID
+	return repo.pendingAnswer, repo.pendingError
+}
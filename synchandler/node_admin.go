@@ -0,0 +1,90 @@
+package synchandler
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncutil"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+//NodeItem is a node of the sync cluster as listed by ListNodes.
+type NodeItem struct {
+	NodeID          string `json:"nodeId"`
+	NodeName        string `json:"nodeName"`
+	DataVersionName string `json:"dataVersionName"`
+}
+
+//ListNodesResponse is the response structure for ListNodes.
+type ListNodesResponse struct {
+	Nodes []NodeItem `json:"nodes"`
+	//Result possible values: 'OK' or 'Error'
+	Result    string `json:"result"`
+	ResultMsg string `json:"resultMsg"`
+}
+
+//ListNodes lists every node of the sync cluster ordered by node name. Invoked performed via the following http command:
+//	curl -i --request GET http://localhost:8080/syncNode
+func ListNodes(w http.ResponseWriter, r *http.Request) {
+	if dbSetupError(w) {
+		return
+	}
+	answer := ListNodesResponse{
+		Nodes:  []NodeItem{},
+		Result: "OK",
+	}
+	nodes, err := syncdao.DefaultDaos.SyncNodeDao().ListNodes()
+	if err != nil {
+		syncutil.Error(err)
+		answer.Result = "Error"
+		answer.ResultMsg = err.Error()
+	}
+	for _, node := range nodes {
+		answer.Nodes = append(answer.Nodes, NodeItem{
+			NodeID:          node.NodeID,
+			NodeName:        node.NodeName,
+			DataVersionName: node.DataVersionName,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	err = json.NewEncoder(w).Encode(answer)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
+}
+
+//GetPendingChanges counts by entity the records waiting to be sent to a node, without queuing them. Invoked performed
+//via the following http command:
+//	curl -i --request GET http://localhost:8080/syncNode/nodeId/*node-hub/pendingChanges
+func (handlers Handlers) GetPendingChanges(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	nodeID, hasValue := vars["nodeId"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if handlers.Repository.NodeAdminRepo == nil {
+		errMsg := "Server Configuration Error: Node admin repository not set"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+	pending, err := handlers.Repository.NodeAdminRepo.PendingChanges(nodeID)
+	if err != nil {
+		syncutil.Error(err)
+		status := http.StatusInternalServerError
+		if err == syncapi.ErrNodeNotFound {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	err = json.NewEncoder(w).Encode(pending)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
+}
@@ -0,0 +1,101 @@
+package synchandler
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readyNodeAdmin(nodeAdminRepo *mockNodeAdminRepository) *httptest.Server {
+	handlers := Handlers{
+		Repository: syncapi.Repository{
+			DataRepo:      mockDataRepository{},
+			ConfigRepo:    mockConfigRepository{},
+			NodeAdminRepo: nodeAdminRepo,
+		},
+	}
+	return httptest.NewServer(NewRouter(handlers))
+}
+
+func TestHandlers_ListNodes(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	setupHTTPEnv()
+	defer teardownHTTPEnv()
+
+	res, err := http.Get(server.URL + "/syncNode")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var answer ListNodesResponse
+	err = json.NewDecoder(res.Body).Decode(&answer)
+	res.Body.Close()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "OK", answer.Result)
+	if assert.Equal(t, 4, len(answer.Nodes)) {
+		assert.Equal(t, NodeItem{NodeID: "*node-spoke1", NodeName: "A", DataVersionName: "Demo Model 1"}, answer.Nodes[0])
+		assert.Equal(t, "Z", answer.Nodes[3].NodeName)
+	}
+	testhelper.EndTest(testName)
+}
+
+func TestHandlers_GetPendingChanges(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	nodeAdminRepo := &mockNodeAdminRepository{
+		pendingAnswer: syncapi.NodePendingChanges{
+			NodeID: "*node-hub",
+			Total:  3,
+			Entities: []syncapi.PendingChangeCount{
+				{EntityPluralName: "Contacts", AddedOrUpdated: 2, Deleted: 1, InFlight: 1},
+			},
+		},
+	}
+	server := readyNodeAdmin(nodeAdminRepo)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/syncNode/nodeId/*node-hub/pendingChanges")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "*node-hub", nodeAdminRepo.pendingNode)
+	var pending syncapi.NodePendingChanges
+	err = json.NewDecoder(res.Body).Decode(&pending)
+	res.Body.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, nodeAdminRepo.pendingAnswer, pending)
+
+	nodeAdminRepo.pendingError = syncapi.ErrNodeNotFound
+	res, err = http.Get(server.URL + "/syncNode/nodeId/*node-unknown/pendingChanges")
+	if assert.Nil(t, err) {
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		res.Body.Close()
+	}
+	testhelper.EndTest(testName)
+}
+
+func TestHandlers_GetPendingChangesRepositoryNotSet(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	server := httptest.NewServer(NewRouter(Handlers{}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/syncNode/nodeId/*node-hub/pendingChanges")
+	if assert.Nil(t, err) {
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+		res.Body.Close()
+	}
+	testhelper.EndTest(testName)
+}
@@ -0,0 +1,374 @@
+package synchandler
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncutil"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+//PairItem is the configuration of a sync pair. Fields left at their zero value when creating or updating a pair take
+//the defaults of the sql schema (see syncapi.PairWithDefaults). The session fields are read only.
+type PairItem struct {
+	PairID            string `json:"pairId"`
+	PairName          string `json:"pairName"`
+	MaxSesDurValue    int    `json:"maxSesDurValue"`
+	MaxSesDurUnit     string `json:"maxSesDurUnit"`
+	SyncDataTransForm string `json:"syncDataTransForm"`
+	SyncMsgTransForm  string `json:"syncMsgTransForm"`
+	SyncMsgSecPol     string `json:"syncMsgSecPol"`
+	SyncConflictURI   string `json:"syncConflictUri"`
+	SyncSessionID     string `json:"syncSessionId,omitempty"`
+	SyncSessionState  string `json:"syncSessionState,omitempty"`
+}
+
+//ListPairsResponse is the response structure for ListPairs.
+type ListPairsResponse struct {
+	Pairs []PairItem `json:"pairs"`
+}
+
+//DeletePairResponse is the response structure for DeletePair.
+type DeletePairResponse struct {
+	PairID string `json:"pairId"`
+	//Result possible values: 'OK'
+	Result string `json:"result"`
+}
+
+//ListPairNodesResponse is the response structure for ListPairNodes.
+type ListPairNodesResponse struct {
+	PairID string             `json:"pairId"`
+	Nodes  []syncdao.PairNode `json:"nodes"`
+}
+
+//DeletePairNodeResponse is the response structure for DeletePairNode.
+type DeletePairNodeResponse struct {
+	PairID       string `json:"pairId"`
+	NodeID       string `json:"nodeId"`
+	TargetNodeID string `json:"targetNodeId"`
+	//Result possible values: 'OK'
+	Result string `json:"result"`
+}
+
+func toPairItem(pair syncdao.SyncPair) PairItem {
+	return PairItem{
+		PairID:            pair.PairID,
+		PairName:          pair.PairName,
+		MaxSesDurValue:    pair.MaxSesDurValue,
+		MaxSesDurUnit:     pair.MaxSesDurUnit,
+		SyncDataTransForm: pair.SyncDataTransForm,
+		SyncMsgTransForm:  pair.SyncMsgTransForm,
+		SyncMsgSecPol:     pair.SyncMsgSecPol,
+		SyncConflictURI:   pair.SyncConflictURI,
+		SyncSessionID:     pair.SyncSessionID,
+		SyncSessionState:  pair.SyncSessionState,
+	}
+}
+
+func fromPairItem(item PairItem) syncdao.SyncPair {
+	return syncdao.SyncPair{
+		PairID:            item.PairID,
+		PairName:          item.PairName,
+		MaxSesDurValue:    item.MaxSesDurValue,
+		MaxSesDurUnit:     item.MaxSesDurUnit,
+		SyncDataTransForm: item.SyncDataTransForm,
+		SyncMsgTransForm:  item.SyncMsgTransForm,
+		SyncMsgSecPol:     item.SyncMsgSecPol,
+		SyncConflictURI:   item.SyncConflictURI,
+	}
+}
+
+//ListPairs lists every sync pair ordered by pair name. Invoked performed via the following http command:
+//	curl -i --request GET http://localhost:8080/syncPair
+func ListPairs(w http.ResponseWriter, r *http.Request) {
+	if dbSetupError(w) {
+		return
+	}
+	pairs, err := syncdao.DefaultDaos.SyncPairDao().ListPairs()
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	answer := ListPairsResponse{Pairs: []PairItem{}}
+	for _, pair := range pairs {
+		answer.Pairs = append(answer.Pairs, toPairItem(pair))
+	}
+	writePairJSON(w, answer)
+}
+
+//CreatePair creates a sync pair, without its nodes, from the PairItem of the body. Invoked performed via the following
+//http command:
+//	curl -i --header "Content-Type: application/json" --request POST http://localhost:8080/syncPair -d '{"pairId":"*pair-6","pairName":"C <-> Z"}'
+func CreatePair(w http.ResponseWriter, r *http.Request) {
+	var request PairItem
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		errMsg := "Could not parse json from body"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	pair := fromPairItem(request)
+	err = syncapi.ValidatePair(pair)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	err = syncdao.DefaultDaos.SyncPairDao().AddPair(pair)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	writePair(w, request.PairID)
+}
+
+//GetPair gives the configuration of a sync pair. Invoked performed via the following http command:
+//	curl -i --request GET http://localhost:8080/syncPair/pairId/*pair-1
+func GetPair(w http.ResponseWriter, r *http.Request) {
+	pairID, hasValue := mux.Vars(r)["pairId"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	writePair(w, pairID)
+}
+
+//UpdatePair replaces the configuration of a sync pair having no active session by the PairItem of the body. Invoked
+//performed via the following http command:
+//	curl -i --header "Content-Type: application/json" --request PUT http://localhost:8080/syncPair/pairId/*pair-1 -d '{"pairName":"A <-> Z","maxSesDurValue":2,"maxSesDurUnit":"hours"}'
+func UpdatePair(w http.ResponseWriter, r *http.Request) {
+	pairID, hasValue := mux.Vars(r)["pairId"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var request PairItem
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		errMsg := "Could not parse json from body"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	request.PairID = pairID
+	pair := fromPairItem(request)
+	err = syncapi.ValidatePair(pair)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	err = syncdao.DefaultDaos.SyncPairDao().UpdatePair(pair)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	writePair(w, pairID)
+}
+
+//DeletePair deletes a sync pair having no active session, along with its nodes and session history. Invoked performed
+//via the following http command:
+//	curl -i --request DELETE http://localhost:8080/syncPair/pairId/*pair-1
+func DeletePair(w http.ResponseWriter, r *http.Request) {
+	pairID, hasValue := mux.Vars(r)["pairId"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	err := syncdao.DefaultDaos.SyncPairDao().DeletePair(pairID)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	writePairJSON(w, DeletePairResponse{PairID: pairID, Result: "OK"})
+}
+
+//ListPairNodes lists the nodes of a sync pair ordered by node id. Invoked performed via the following http command:
+//	curl -i --request GET http://localhost:8080/syncPair/pairId/*pair-1/nodes
+func ListPairNodes(w http.ResponseWriter, r *http.Request) {
+	pairID, hasValue := mux.Vars(r)["pairId"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	writePairNodes(w, pairID)
+}
+
+//AddPairNode adds the syncdao.PairNode of the body to the nodes of a sync pair having no active session. A pair is
+//complete once both of its nodes have been added, each targeting the other. Invoked performed via the following http
+//command:
+//	curl -i --header "Content-Type: application/json" --request POST http://localhost:8080/syncPair/pairId/*pair-6/nodes -d '{"nodeId":"*node-hub","targetNodeId":"*node-spoke3","syncConflictUri":"none"}'
+func AddPairNode(w http.ResponseWriter, r *http.Request) {
+	pairID, hasValue := mux.Vars(r)["pairId"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var request syncdao.PairNode
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		errMsg := "Could not parse json from body"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	request.PairID = pairID
+	err = syncapi.ValidatePairNode(request)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	err = syncdao.DefaultDaos.SyncPairDao().AddPairNode(pairID, request.NodeID, request.TargetNodeID,
+		request.SeededDataVersion, request.SyncConflictURI)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	writePairNodes(w, pairID)
+}
+
+//UpdatePairNode replaces the seeded data version and the conflict uri of a node of a sync pair having no active
+//session by those of the syncdao.PairNode of the body. Invoked performed via the following http command:
+//	curl -i --header "Content-Type: application/json" --request PUT http://localhost:8080/syncPair/pairId/*pair-1/nodeId/*node-hub/targetNodeId/*node-spoke1 -d '{"seededDataVersion":"Demo Model 1","syncConflictUri":"none"}'
+func UpdatePairNode(w http.ResponseWriter, r *http.Request) {
+	node, hasValue := pairNodeVars(mux.Vars(r))
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var request syncdao.PairNode
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		errMsg := "Could not parse json from body"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	node.SeededDataVersion = request.SeededDataVersion
+	node.SyncConflictURI = request.SyncConflictURI
+	err = syncapi.ValidatePairNode(node)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	err = syncdao.DefaultDaos.SyncPairDao().UpdatePairNode(node)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	writePairNodes(w, node.PairID)
+}
+
+//DeletePairNode removes a node from a sync pair having no active session. Invoked performed via the following http
+//command:
+//	curl -i --request DELETE http://localhost:8080/syncPair/pairId/*pair-1/nodeId/*node-hub/targetNodeId/*node-spoke1
+func DeletePairNode(w http.ResponseWriter, r *http.Request) {
+	node, hasValue := pairNodeVars(mux.Vars(r))
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	err := syncdao.DefaultDaos.SyncPairDao().DeletePairNode(node.PairID, node.NodeID, node.TargetNodeID)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	writePairJSON(w, DeletePairNodeResponse{
+		PairID:       node.PairID,
+		NodeID:       node.NodeID,
+		TargetNodeID: node.TargetNodeID,
+		Result:       "OK",
+	})
+}
+
+//pairNodeVars gives the pair node named by the 'pairId', 'nodeId' and 'targetNodeId' parameters.
+func pairNodeVars(vars map[string]string) (syncdao.PairNode, bool) {
+	pairID, hasPairID := vars["pairId"]
+	nodeID, hasNodeID := vars["nodeId"]
+	targetNodeID, hasTargetNodeID := vars["targetNodeId"]
+	return syncdao.PairNode{PairID: pairID, NodeID: nodeID, TargetNodeID: targetNodeID},
+		hasPairID && hasNodeID && hasTargetNodeID
+}
+
+//writePair answers the stored configuration of the pair.
+func writePair(w http.ResponseWriter, pairID string) {
+	pair, err := syncdao.DefaultDaos.SyncPairDao().GetPair(pairID)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	writePairJSON(w, toPairItem(pair))
+}
+
+//writePairNodes answers the stored nodes of the pair.
+func writePairNodes(w http.ResponseWriter, pairID string) {
+	nodes, err := syncdao.DefaultDaos.SyncPairDao().ListPairNodes(pairID)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	writePairJSON(w, ListPairNodesResponse{PairID: pairID, Nodes: nodes})
+}
+
+func writePairJSON(w http.ResponseWriter, answer interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	err := json.NewEncoder(w).Encode(answer)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
+}
+
+func writePairError(w http.ResponseWriter, err error) {
+	status := pairErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		syncutil.Error(err)
+	}
+	http.Error(w, err.Error(), status)
+}
+
+//pairErrorStatus maps the errors of the pair and sync model admin dao methods to http status codes.
+func pairErrorStatus(err error) int {
+	if _, isValidation := err.(syncapi.ValidationError); isValidation {
+		return http.StatusBadRequest
+	}
+	switch err {
+	case syncdao.ErrDaoNoDataFound:
+		return http.StatusNotFound
+	case syncapi.ErrPairSessionActive:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,97 @@
+package synchandler
+
+import (
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//doPairRequest sends body to the pair admin route at path and decodes the answer into answer when it is OK.
+func doPairRequest(t *testing.T, method string, path string, body string, answer interface{}) int {
+	request, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if !assert.Nil(t, err) {
+		return 0
+	}
+	request.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(request)
+	if !assert.Nil(t, err) {
+		return 0
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusOK && answer != nil {
+		assert.Nil(t, json.NewDecoder(res.Body).Decode(answer))
+	}
+	return res.StatusCode
+}
+
+func TestHandlers_Pair(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	setupHTTPEnv()
+	defer teardownHTTPEnv()
+
+	var pairs ListPairsResponse
+	if !assert.Equal(t, http.StatusOK, doPairRequest(t, "GET", "/syncPair", "", &pairs)) {
+		return
+	}
+	if assert.Len(t, pairs.Pairs, 5) {
+		assert.Equal(t, "A <-> B (Partial)", pairs.Pairs[0].PairName)
+		assert.Equal(t, "Inactive", pairs.Pairs[0].SyncSessionState)
+	}
+
+	var pair PairItem
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "POST", "/syncPair", `{"pairId":"*pair-6","pairName":"C <-> Z","maxSesDurUnit":"hours"}`, &pair))
+	assert.Equal(t, PairItem{PairID: "*pair-6", PairName: "C <-> Z", MaxSesDurValue: 10, MaxSesDurUnit: "hours", SyncDataTransForm: "json:V1",
+		SyncMsgTransForm: "json:V1", SyncMsgSecPol: "none", SyncConflictURI: "none", SyncSessionState: "Inactive"}, pair)
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncPair", `{"pairId":"*pair-6","pairName":"C <-> Z again"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncPair", `{"pairId":"*pair-7","pairName":"C <-> Z","maxSesDurUnit":"weeks"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncPair", `not json`, nil))
+
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "PUT", "/syncPair/pairId/*pair-6", `{"pairName":"C <-> Z (Renamed)","maxSesDurValue":30,"maxSesDurUnit":"seconds"}`, &pair))
+	assert.Equal(t, "C <-> Z (Renamed)", pair.PairName)
+	assert.Equal(t, 30, pair.MaxSesDurValue)
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "GET", "/syncPair/pairId/*pair-6", "", &pair))
+	assert.Equal(t, "seconds", pair.MaxSesDurUnit)
+	assert.Equal(t, http.StatusNotFound, doPairRequest(t, "GET", "/syncPair/pairId/*pair-unknown", "", nil))
+	assert.Equal(t, http.StatusNotFound, doPairRequest(t, "PUT", "/syncPair/pairId/*pair-unknown", `{"pairName":"Unknown"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "PUT", "/syncPair/pairId/*pair-6", `{"pairName":"C <-> Z","maxSesDurUnit":"weeks"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "PUT", "/syncPair/pairId/*pair-6", `{"maxSesDurValue":30}`, nil), "a pair has a name")
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "PUT", "/syncPair/pairId/*pair-6", `{"pairName":"C <-> Z","syncConflictUri":"builtin:lastWriterWins"}`, nil),
+		"a conflict uri names a resolver")
+
+	var nodes ListPairNodesResponse
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "POST", "/syncPair/pairId/*pair-6/nodes", `{"nodeId":"*node-hub","targetNodeId":"*node-spoke3","syncConflictUri":"none"}`, &nodes))
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "POST", "/syncPair/pairId/*pair-6/nodes", `{"nodeId":"*node-spoke3","targetNodeId":"*node-hub","syncConflictUri":"none"}`, &nodes))
+	if assert.Len(t, nodes.Nodes, 2) {
+		assert.Equal(t, syncdao.PairNode{PairID: "*pair-6", NodeID: "*node-hub", TargetNodeID: "*node-spoke3", SyncConflictURI: "none"}, nodes.Nodes[0])
+	}
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncPair/pairId/*pair-6/nodes", `{"nodeId":"*node-spoke1","targetNodeId":"*node-hub"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncPair/pairId/*pair-unknown/nodes", `{"nodeId":"*node-spoke1","targetNodeId":"*node-spoke1"}`, nil), "a node cannot target itself")
+	assert.Equal(t, http.StatusNotFound, doPairRequest(t, "POST", "/syncPair/pairId/*pair-unknown/nodes", `{"nodeId":"*node-spoke1","targetNodeId":"*node-hub"}`, nil))
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "PUT", "/syncPair/pairId/*pair-6/nodeId/*node-hub/targetNodeId/*node-spoke3", `{"seededDataVersion":"Demo Model 1","syncConflictUri":"none"}`, &nodes))
+	assert.Equal(t, "Demo Model 1", nodes.Nodes[0].SeededDataVersion)
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "PUT", "/syncPair/pairId/*pair-6/nodeId/*node-hub/targetNodeId/*node-spoke3", `{"syncConflictUri":"`+strings.Repeat("x", 2049)+`"}`, nil))
+
+	//The pair cannot change while in session
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "POST", "/syncSession/sessionId/*session-id-1/pairId/*pair-6", "", nil))
+	assert.Equal(t, http.StatusConflict, doPairRequest(t, "DELETE", "/syncPair/pairId/*pair-6", "", nil))
+	assert.Equal(t, http.StatusConflict, doPairRequest(t, "DELETE", "/syncPair/pairId/*pair-6/nodeId/*node-hub/targetNodeId/*node-spoke3", "", nil))
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "DELETE", "/syncSession/sessionId/*session-id-1/pairId/*pair-6", "", nil))
+
+	var deletedNode DeletePairNodeResponse
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "DELETE", "/syncPair/pairId/*pair-6/nodeId/*node-hub/targetNodeId/*node-spoke3", "", &deletedNode))
+	assert.Equal(t, "OK", deletedNode.Result)
+	assert.Equal(t, http.StatusNotFound, doPairRequest(t, "DELETE", "/syncPair/pairId/*pair-6/nodeId/*node-hub/targetNodeId/*node-spoke3", "", nil))
+	var deleted DeletePairResponse
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "DELETE", "/syncPair/pairId/*pair-6", "", &deleted))
+	assert.Equal(t, DeletePairResponse{PairID: "*pair-6", Result: "OK"}, deleted)
+	assert.Equal(t, http.StatusNotFound, doPairRequest(t, "GET", "/syncPair/pairId/*pair-6/nodes", "", nil))
+
+	testhelper.EndTest(testName)
+}
@@ -54,6 +54,12 @@ func createRoutes(handlers Handlers) []route {
 			"/syncNode",
 			CreateNewNode,
 		},
+		route{
+			"ListNodes",
+			"GET",
+			"/syncNode",
+			ListNodes,
+		},
 		route{
 			"GetNodeByNodeName",
 			"GET",
@@ -72,6 +78,102 @@ func createRoutes(handlers Handlers) []route {
 			"/syncNode/nodeId/{nodeId}",
 			DeleteNodeByNodeID,
 		},
+		route{
+			"GetPendingChanges",
+			"GET",
+			"/syncNode/nodeId/{nodeId}/pendingChanges",
+			handlers.GetPendingChanges,
+		},
+		route{
+			"ListPairs",
+			"GET",
+			"/syncPair",
+			ListPairs,
+		},
+		route{
+			"CreatePair",
+			"POST",
+			"/syncPair",
+			CreatePair,
+		},
+		route{
+			"GetPair",
+			"GET",
+			"/syncPair/pairId/{pairId}",
+			GetPair,
+		},
+		route{
+			"UpdatePair",
+			"PUT",
+			"/syncPair/pairId/{pairId}",
+			UpdatePair,
+		},
+		route{
+			"DeletePair",
+			"DELETE",
+			"/syncPair/pairId/{pairId}",
+			DeletePair,
+		},
+		route{
+			"ListPairNodes",
+			"GET",
+			"/syncPair/pairId/{pairId}/nodes",
+			ListPairNodes,
+		},
+		route{
+			"AddPairNode",
+			"POST",
+			"/syncPair/pairId/{pairId}/nodes",
+			AddPairNode,
+		},
+		route{
+			"UpdatePairNode",
+			"PUT",
+			"/syncPair/pairId/{pairId}/nodeId/{nodeId}/targetNodeId/{targetNodeId}",
+			UpdatePairNode,
+		},
+		route{
+			"DeletePairNode",
+			"DELETE",
+			"/syncPair/pairId/{pairId}/nodeId/{nodeId}/targetNodeId/{targetNodeId}",
+			DeletePairNode,
+		},
+		route{
+			"ListDataVersions",
+			"GET",
+			"/syncDataVersion",
+			ListDataVersions,
+		},
+		route{
+			"CreateDataVersion",
+			"POST",
+			"/syncDataVersion",
+			CreateDataVersion,
+		},
+		route{
+			"ListEntities",
+			"GET",
+			"/syncDataVersion/dataVersionName/{dataVersionName}/entities",
+			ListEntities,
+		},
+		route{
+			"AddEntity",
+			"POST",
+			"/syncDataVersion/dataVersionName/{dataVersionName}/entities",
+			AddEntity,
+		},
+		route{
+			"AddField",
+			"POST",
+			"/syncDataVersion/dataVersionName/{dataVersionName}/entitySingularName/{entitySingularName}/fields",
+			AddField,
+		},
+		route{
+			"ValidateSyncModel",
+			"GET",
+			"/syncDataVersion/dataVersionName/{dataVersionName}/validate",
+			ValidateSyncModel,
+		},
 		route{
 			"GetSyncConfig",
 			"GET",
@@ -97,6 +199,18 @@ func createRoutes(handlers Handlers) []route {
 			//"/syncSession/sessionId/{sessionId}/pairId/{pairId}/state/{state:Seeding|Queuing}",
 			UpdateSyncSessionState,
 		},
+		route{
+			"UpdateSyncSessionStateWithTotals",
+			"PUT",
+			"/syncSession/sessionId/{sessionId}/pairId/{pairId}/nodeId/{nodeId}/totals/recordBytes1/{recordBytes1:[0-9]+}/recordCount1/{recordCount1:[0-9]+}/recordBytes2/{recordBytes2:[0-9]+}/recordCount2/{recordCount2:[0-9]+}",
+			UpdateSyncSessionStateWithTotals,
+		},
+		route{
+			"UpdateSyncSessionStateWithProcessed",
+			"PUT",
+			"/syncSession/sessionId/{sessionId}/pairId/{pairId}/nodeId/{nodeId}/processed/recordBytes1/{recordBytes1:[0-9]+}/recordCount1/{recordCount1:[0-9]+}/recordBytes2/{recordBytes2:[0-9]+}/recordCount2/{recordCount2:[0-9]+}",
+			UpdateSyncSessionStateWithProcessed,
+		},
 		route{
 			"ProcessSyncData",
 			"PUT",
@@ -121,12 +235,54 @@ func createRoutes(handlers Handlers) []route {
 			"/syncSession/sessionId/{sessionId}/pairId/{pairId}",
 			CloseSyncSession,
 		},
+		route{
+			"CancelSyncSession",
+			"PUT",
+			"/syncSession/sessionId/{sessionId}/cancel",
+			handlers.CancelSyncSession,
+		},
+		route{
+			"SendSyncSessionMgmtMsg",
+			"POST",
+			"/syncSessionMgmt/sessionId/{sessionId}/nodeId/{nodeId}/messageType/{messageType:Heartbeat|SeedingClosed|Cancel|HaultingError|Start}",
+			handlers.SendSyncSessionMgmtMsg,
+		},
+		route{
+			"PollSyncSessionMgmtMsgs",
+			"GET",
+			"/syncSessionMgmt/sessionId/{sessionId}/nodeId/{nodeId}/afterSeq/{afterSeq:[0-9]+}",
+			handlers.PollSyncSessionMgmtMsgs,
+		},
 		route{
 			"GetPairState",
 			"GET",
 			"/syncPairState/pairId/{pairId}",
 			GetPairState,
 		},
+		route{
+			"FindSyncConflicts",
+			"GET",
+			"/syncConflict/nodeId/{nodeId}",
+			handlers.FindSyncConflicts,
+		},
+		route{
+			"GetSyncConflict",
+			"GET",
+			"/syncConflict/conflictId/{conflictId}",
+			handlers.GetSyncConflict,
+		},
+		route{
+			"ResolveSyncConflict",
+			"PUT",
+			"/syncConflict/conflictId/{conflictId}/resolve/{choice:Local|Remote|Merged}",
+			handlers.ResolveSyncConflict,
+		},
+		route{
+			"CollectTombstones",
+			"PUT",
+			"/tombstone/collect",
+			handlers.CollectTombstones,
+		},
 		route{
 			"IntegrationTestReset",
 			"GET",
@@ -200,20 +356,6 @@ func createRoutes(handlers Handlers) []route {
 						UpdateSyncDataState,
 					},
 
-
-					Route{
-						"UpdateSyncSessionStateWithTotals",
-						"PUT",
-						"/syncSession/sessionId/{sessionId}/pairId/{pairId}/state/Syncing/Totals/recordBytes1/{recordBytes1}/recordCount1/{recordCount1}/recordBytes2/{recordBytes2}/recordCount2/{recordCount2}",
-						UpdateSyncSessionStateWithTotals,
-					},
-					Route{
-						"UpdateSyncSessionStateWithProcessed",
-						"PUT",
-						"/syncSession/sessionId/{sessionId}/pairId/{pairId}/state/Syncing/Processed/recordBytes1/{recordBytes1}/recordCount1/{recordCount1}/recordBytes2/{recordBytes2}/recordCount2/{recordCount2}",
-						UpdateSyncSessionStateWithProcessed,
-					},
-
 		*/
 
 	}
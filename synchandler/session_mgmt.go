@@ -0,0 +1,171 @@
+package synchandler
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//SendSyncSessionMgmtMsg sends a management message to the peer of the node in the session. A Heartbeat renews the
+//lease of the session, so it is not ended for outliving the maximum session duration of its pair. A Cancel or a
+//HaultingError moves the pair into Canceling and rolls back the fetches in flight for the session. The optional
+//'result' query parameter is passed on to the peer. Invoked performed via the following http commands:
+//	curl -i --request POST http://localhost:8080/syncSessionMgmt/sessionId/{sessionId}/nodeId/{nodeId}/messageType/Heartbeat
+//	curl -i --request POST http://localhost:8080/syncSessionMgmt/sessionId/{sessionId}/nodeId/{nodeId}/messageType/Cancel?result=Disk%20full
+func (handlers Handlers) SendSyncSessionMgmtMsg(w http.ResponseWriter, r *http.Request) {
+	if handlers.Repository.SessionRepo == nil {
+		errMsg := "Server Configuration Error: Session repository not set"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+	vars := mux.Vars(r)
+	messageType, found := syncapi.SessionMgmtMsgTypeEnumValue[vars["messageType"]]
+	if !found {
+		errMsg := "Unknown messageType '" + vars["messageType"] + "'"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	msg := syncapi.SessionMgmtMsg{
+		SessionID:    vars["sessionId"],
+		SenderNodeID: vars["nodeId"],
+		MessageType:  syncapi.SessionMgmtMsgTypeEnum(messageType),
+		Result:       r.URL.Query().Get("result"),
+	}
+	err := syncapi.ValidateSessionMgmtMsg(msg)
+	if err != nil {
+		syncutil.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := handlers.Repository.SessionRepo.SendMgmtMsg(msg)
+	if err != nil {
+		syncutil.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	answer := SendSyncSessionMgmtMsgResponse{
+		SessionID:     msg.SessionID,
+		MsgSeq:        result.MsgSeq,
+		PairID:        result.PairID,
+		State:         result.State,
+		ReleasedCount: result.ReleasedCount,
+		Result:        result.Result,
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	err = json.NewEncoder(w).Encode(answer)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
+}
+
+//SendSyncSessionMgmtMsgResponse represents the answer to SendSyncSessionMgmtMsg.
+//Result possible values: 'OK', 'CouldNoFindActiveSession'.
+type SendSyncSessionMgmtMsgResponse struct {
+	SessionID     string `json:"sessionId"`
+	MsgSeq        int64  `json:"msgSeq"`
+	PairID        string `json:"pairId"`
+	State         string `json:"state"`
+	ReleasedCount int    `json:"releasedCount"`
+	Result        string `json:"result"`
+}
+
+//PollSyncSessionMgmtMsgs lists the management messages the peers of the node sent in the session after the message
+//numbered afterSeq, in the order they were sent. Polling again with the msgSeq of the last message gives the next
+//ones. Invoked performed via the following http command:
+//	curl -i --request GET http://localhost:8080/syncSessionMgmt/sessionId/{sessionId}/nodeId/{nodeId}/afterSeq/0
+func (handlers Handlers) PollSyncSessionMgmtMsgs(w http.ResponseWriter, r *http.Request) {
+	if handlers.Repository.SessionRepo == nil {
+		errMsg := "Server Configuration Error: Session repository not set"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+	vars := mux.Vars(r)
+	afterSeq, err := strconv.ParseInt(vars["afterSeq"], 10, 64)
+	if err != nil {
+		errMsg := "Invalid afterSeq '" + vars["afterSeq"] + "'"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	msgs, err := handlers.Repository.SessionRepo.PollMgmtMsgs(vars["sessionId"], vars["nodeId"], afterSeq)
+	if err != nil {
+		syncutil.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	answer := []SyncSessionMgmtMsg{}
+	for _, msg := range msgs {
+		answer = append(answer, SyncSessionMgmtMsg{
+			MsgSeq:      msg.MsgSeq,
+			SessionID:   msg.SessionID,
+			SenderNode:  msg.SenderNodeID,
+			MessageType: int(msg.MessageType),
+			Result:      msg.Result,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	err = json.NewEncoder(w).Encode(answer)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
+}
+
+//CancelSyncSession cancels the active session: the records reserved by the session are released to be queued and sent
+//again, the transactions received in the session are discarded, and the session is closed so the next session of the
+//pair starts from a consistent point. The optional 'reason' query parameter is recorded with the ended session.
+//Invoked performed via the following http command:
+//	curl -i --request PUT http://localhost:8080/syncSession/sessionId/{sessionId}/cancel?reason=Disk%20full
+func (handlers Handlers) CancelSyncSession(w http.ResponseWriter, r *http.Request) {
+	if handlers.Repository.SessionRepo == nil {
+		errMsg := "Server Configuration Error: Session repository not set"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+	vars := mux.Vars(r)
+	reason := r.URL.Query().Get("reason")
+	err := syncapi.ValidateCancelReason(reason)
+	if err != nil {
+		syncutil.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := handlers.Repository.SessionRepo.CancelSession(vars["sessionId"], reason, time.Now())
+	if err != nil {
+		syncutil.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	answer := CancelSyncSessionResponse{
+		SessionID:      vars["sessionId"],
+		PairID:         result.Session.PairID,
+		State:          result.Session.State,
+		ReleasedCount:  result.Session.ReleasedCount,
+		DiscardedCount: result.DiscardedCount,
+		Result:         result.Result,
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	err = json.NewEncoder(w).Encode(answer)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
+}
+
+//CancelSyncSessionResponse represents the answer to CancelSyncSession. State is the state the session was canceled in.
+//Result possible values: 'OK', 'CouldNoFindActiveSession'.
+type CancelSyncSessionResponse struct {
+	SessionID      string `json:"sessionId"`
+	PairID         string `json:"pairId"`
+	State          string `json:"state"`
+	ReleasedCount  int    `json:"releasedCount"`
+	DiscardedCount int    `json:"discardedCount"`
+	Result         string `json:"result"`
+}
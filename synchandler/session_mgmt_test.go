@@ -0,0 +1,189 @@
+package synchandler
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readySessionMgmt(sessionRepo *mockSessionRepository) *httptest.Server {
+	handlers := Handlers{
+		Repository: syncapi.Repository{
+			DataRepo:    mockDataRepository{},
+			ConfigRepo:  mockConfigRepository{},
+			SessionRepo: sessionRepo,
+		},
+	}
+	return httptest.NewServer(NewRouter(handlers))
+}
+
+func TestHandlers_SendSyncSessionMgmtMsg(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	sessionRepo := &mockSessionRepository{
+		sendAnswer: syncapi.SessionMgmtMsgResult{
+			Result:        "OK",
+			MsgSeq:        7,
+			PairID:        "*pair-1",
+			State:         syncapi.SyncSessionStateCanceling,
+			ReleasedCount: 3,
+		},
+	}
+	server := readySessionMgmt(sessionRepo)
+	defer server.Close()
+
+	req, err := http.NewRequest("POST", server.URL+"/syncSessionMgmt/sessionId/*session-1/nodeId/*node-1/messageType/Cancel?result=Disk%20full", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	res, err := http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	if !assert.Equal(t, 1, len(sessionRepo.sent)) {
+		return
+	}
+	assert.Equal(t, "*session-1", sessionRepo.sent[0].SessionID)
+	assert.Equal(t, "*node-1", sessionRepo.sent[0].SenderNodeID)
+	assert.Equal(t, syncapi.SessionMgmtMsgTypeCancel, sessionRepo.sent[0].MessageType)
+	assert.Equal(t, "Disk full", sessionRepo.sent[0].Result)
+	var answer SendSyncSessionMgmtMsgResponse
+	err = json.NewDecoder(res.Body).Decode(&answer)
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", answer.Result)
+	assert.Equal(t, int64(7), answer.MsgSeq)
+	assert.Equal(t, syncapi.SyncSessionStateCanceling, answer.State)
+	assert.Equal(t, 3, answer.ReleasedCount)
+
+	req, err = http.NewRequest("POST", server.URL+"/syncSessionMgmt/sessionId/*session-1/nodeId/*node-1/messageType/Pause", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	res, err = http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	assert.Equal(t, 1, len(sessionRepo.sent))
+
+	//The message is checked before it reaches the repository
+	req, err = http.NewRequest("POST", server.URL+"/syncSessionMgmt/sessionId/*session-1/nodeId/*node-1/messageType/Cancel?result="+strings.Repeat("x", 1025), nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	res, err = http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, 1, len(sessionRepo.sent))
+
+	testhelper.EndTest(testName)
+}
+
+func TestHandlers_PollSyncSessionMgmtMsgs(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	sessionRepo := &mockSessionRepository{
+		pollAnswer: []syncapi.SessionMgmtMsg{
+			{MsgSeq: 4, SessionID: "*session-1", SenderNodeID: "*node-2", MessageType: syncapi.SessionMgmtMsgTypeHeartbeat},
+			{MsgSeq: 6, SessionID: "*session-1", SenderNodeID: "*node-2", MessageType: syncapi.SessionMgmtMsgTypeHaultingError, Result: "Out of memory"},
+		},
+	}
+	server := readySessionMgmt(sessionRepo)
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL+"/syncSessionMgmt/sessionId/*session-1/nodeId/*node-1/afterSeq/3", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	res, err := http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "*session-1", sessionRepo.polledSession)
+	assert.Equal(t, "*node-1", sessionRepo.polledNode)
+	assert.Equal(t, int64(3), sessionRepo.polledAfter)
+	var msgs []SyncSessionMgmtMsg
+	err = json.NewDecoder(res.Body).Decode(&msgs)
+	assert.Nil(t, err)
+	if !assert.Equal(t, 2, len(msgs)) {
+		return
+	}
+	assert.Equal(t, int64(4), msgs[0].MsgSeq)
+	assert.Equal(t, 1, msgs[0].MessageType)
+	assert.Equal(t, "*node-2", msgs[1].SenderNode)
+	assert.Equal(t, 4, msgs[1].MessageType)
+	assert.Equal(t, "Out of memory", msgs[1].Result)
+
+	testhelper.EndTest(testName)
+}
+
+func TestHandlers_CancelSyncSession(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	sessionRepo := &mockSessionRepository{
+		cancelAnswer: syncapi.SessionCancelResult{
+			Result: "OK",
+			Session: syncapi.EndedSession{
+				PairID:        "*pair-1",
+				SessionID:     "*session-1",
+				State:         syncapi.SyncSessionStateSyncing,
+				Reason:        syncapi.SessionEndReasonCanceled,
+				ReleasedCount: 6,
+			},
+			DiscardedCount: 2,
+		},
+	}
+	server := readySessionMgmt(sessionRepo)
+	defer server.Close()
+
+	req, err := http.NewRequest("PUT", server.URL+"/syncSession/sessionId/*session-1/cancel?reason=Disk%20full", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	res, err := http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, []string{"*session-1"}, sessionRepo.canceled)
+	assert.Equal(t, []string{"Disk full"}, sessionRepo.cancelReasons)
+	var answer CancelSyncSessionResponse
+	err = json.NewDecoder(res.Body).Decode(&answer)
+	assert.Nil(t, err)
+	assert.Equal(t, CancelSyncSessionResponse{
+		SessionID:      "*session-1",
+		PairID:         "*pair-1",
+		State:          syncapi.SyncSessionStateSyncing,
+		ReleasedCount:  6,
+		DiscardedCount: 2,
+		Result:         "OK",
+	}, answer)
+
+	//A reason longer than sync_session_history.EndReasonMsg never reaches the repository
+	req, err = http.NewRequest("PUT", server.URL+"/syncSession/sessionId/*session-1/cancel?reason="+strings.Repeat("x", 1025), nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	res, err = http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, []string{"*session-1"}, sessionRepo.canceled)
+
+	testhelper.EndTest(testName)
+}
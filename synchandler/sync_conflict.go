@@ -0,0 +1,152 @@
+package synchandler
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+//FindSyncConflicts lists the conflicts left for manual resolution with records received from a node. Invoked
+//performed via the following http command:
+//	curl -i --request GET http://localhost:8080/syncConflict/nodeId/*node-spoke1
+func (handlers Handlers) FindSyncConflicts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	nodeID, hasValue := vars["nodeId"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if conflictRepoSetupError(w, handlers) {
+		return
+	}
+	conflicts, err := handlers.Repository.ConflictRepo.FindConflicts(nodeID)
+	if err != nil {
+		syncutil.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	err = json.NewEncoder(w).Encode(conflicts)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
+}
+
+//GetSyncConflict gives both versions of a conflict left for manual resolution. Invoked performed via the following
+//http command:
+//	curl -i --request GET http://localhost:8080/syncConflict/conflictId/8E0E2E15-6505-429F-8269-7336D8421D89
+func (handlers Handlers) GetSyncConflict(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	conflictID, hasValue := vars["conflictId"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if conflictRepoSetupError(w, handlers) {
+		return
+	}
+	conflict, err := handlers.Repository.ConflictRepo.GetConflict(conflictID)
+	if err != nil {
+		syncutil.Error(err)
+		http.Error(w, err.Error(), conflictErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	err = json.NewEncoder(w).Encode(conflict)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
+}
+
+//ResolveSyncConflict resolves a conflict by keeping the local version, the remote version or a merged record supplied
+//as the JSON request body. Invoked performed via the following http commands:
+//	curl -i --request PUT http://localhost:8080/syncConflict/conflictId/8E0E2E15-6505-429F-8269-7336D8421D89/resolve/Local
+//	curl -i --header "Content-Type: application/json" --request PUT http://localhost:8080/syncConflict/conflictId/8E0E2E15-6505-429F-8269-7336D8421D89/resolve/Merged -d '{"fields":[...]}'
+func (handlers Handlers) ResolveSyncConflict(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	conflictID, hasValue := vars["conflictId"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	choice, hasValue := vars["choice"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if conflictRepoSetupError(w, handlers) {
+		return
+	}
+	var mergedRecord *syncmsg.ProtoRecord
+	if syncapi.ConflictResolutionChoice(choice) == syncapi.ConflictResolutionChoiceMerged {
+		mergedRecord = &syncmsg.ProtoRecord{}
+		err := json.NewDecoder(r.Body).Decode(mergedRecord)
+		if err != nil {
+			syncutil.Error(err)
+			http.Error(w, "Merged record cannot be decoded: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	response := ResolveSyncConflictResponse{
+		ConflictID: conflictID,
+		Choice:     choice,
+		Result:     "OK",
+	}
+	recordHash, err := handlers.Repository.ConflictRepo.ResolveConflict(conflictID, syncapi.ConflictResolutionChoice(choice), mergedRecord)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if err != nil {
+		syncutil.Error(err)
+		w.WriteHeader(conflictErrorStatus(err))
+		response.Result = "NotResolved"
+		response.ResultMsg = err.Error()
+	} else {
+		response.RecordHash = recordHash
+		w.WriteHeader(http.StatusOK)
+	}
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
+}
+
+//ResolveSyncConflictResponse represents the response to a resolve sync conflict request.
+type ResolveSyncConflictResponse struct {
+	ConflictID string `json:"conflictId"`
+	Choice     string `json:"choice"`
+	RecordHash string `json:"recordHash"`
+	//Result possible values: 'OK' or 'NotResolved'
+	Result    string `json:"result"`
+	ResultMsg string `json:"resultMsg"`
+}
+
+//conflictRepoSetupError reports a server configured without a ConflictRepo.
+func conflictRepoSetupError(w http.ResponseWriter, handlers Handlers) bool {
+	if handlers.Repository.ConflictRepo == nil {
+		errMsg := "Server Configuration Error: Conflict repository not set"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return true
+	}
+	return false
+}
+
+func conflictErrorStatus(err error) int {
+	switch err {
+	case syncapi.ErrConflictNotFound:
+		return http.StatusNotFound
+	case syncapi.ErrConflictStale:
+		return http.StatusConflict
+	case syncapi.ErrConflictMergedRecordMissing:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,155 @@
+package synchandler
+
+import (
+	"bytes"
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncmsg"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readySyncConflict(conflictRepo *mockConflictRepository) *httptest.Server {
+	handlers := Handlers{
+		Repository: syncapi.Repository{
+			DataRepo:     mockDataRepository{},
+			ConfigRepo:   mockConfigRepository{},
+			ConflictRepo: conflictRepo,
+		},
+	}
+	return httptest.NewServer(NewRouter(handlers))
+}
+
+func createTestConflictItem() syncapi.ConflictItem {
+	creator := syncmsg.NewCreator()
+	return syncapi.ConflictItem{
+		ConflictID: "conflict-1",
+		SyncState:  syncmsg.AckSyncStateEnum_AckFieldLevelConflictWithNoAutoResolverResolution.String(),
+		RecordConflict: syncapi.RecordConflict{
+			RemoteNodeID:       "*node-spoke1",
+			EntitySingularName: "Contact",
+			EntityPluralName:   "Contacts",
+			RecordID:           "record 01",
+			ConflictingFields:  []string{"lastName"},
+			Local: syncapi.ConflictRecordVersion{
+				RecordHash: "local-hash",
+				Record:     &syncmsg.ProtoRecord{Fields: []*syncmsg.ProtoField{creator.CreateStringProtoField("lastName", "Smith")}},
+			},
+			Remote: syncapi.ConflictRecordVersion{
+				RecordHash: "remote-hash",
+				Record:     &syncmsg.ProtoRecord{Fields: []*syncmsg.ProtoField{creator.CreateStringProtoField("lastName", "Smyth")}},
+			},
+		},
+	}
+}
+
+func TestHandlers_SyncConflictFindAndGet(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	conflict := createTestConflictItem()
+	server := readySyncConflict(&mockConflictRepository{
+		findAnswer: []syncapi.ConflictItem{conflict},
+		getAnswer:  conflict,
+	})
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/syncConflict/nodeId/*node-spoke1")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var conflicts []syncapi.ConflictItem
+	err = json.NewDecoder(res.Body).Decode(&conflicts)
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(conflicts)) {
+		assert.Equal(t, "conflict-1", conflicts[0].ConflictID)
+		assert.Equal(t, "record 01", conflicts[0].RecordID)
+		assert.Equal(t, conflict.Remote.Record.Fields[0].FieldValue, conflicts[0].Remote.Record.Fields[0].FieldValue)
+	}
+
+	res, err = http.Get(server.URL + "/syncConflict/conflictId/conflict-1")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var actual syncapi.ConflictItem
+	err = json.NewDecoder(res.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"lastName"}, actual.ConflictingFields)
+
+	testhelper.EndTest(testName)
+}
+
+func TestHandlers_SyncConflictNotFound(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	server := readySyncConflict(&mockConflictRepository{
+		getError:     syncapi.ErrConflictNotFound,
+		resolveError: syncapi.ErrConflictNotFound,
+	})
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/syncConflict/conflictId/unknown")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	request, err := http.NewRequest("PUT", server.URL+"/syncConflict/conflictId/unknown/resolve/Local", nil)
+	assert.Nil(t, err)
+	res, err = http.DefaultClient.Do(request)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	testhelper.EndTest(testName)
+}
+
+func TestHandlers_SyncConflictResolveMerged(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	conflictRepo := &mockConflictRepository{resolveAnswer: "merged-hash"}
+	server := readySyncConflict(conflictRepo)
+	defer server.Close()
+
+	creator := syncmsg.NewCreator()
+	merged := &syncmsg.ProtoRecord{Fields: []*syncmsg.ProtoField{creator.CreateStringProtoField("lastName", "Smithe")}}
+	body, err := json.Marshal(merged)
+	assert.Nil(t, err)
+	request, err := http.NewRequest("PUT", server.URL+"/syncConflict/conflictId/conflict-1/resolve/Merged", bytes.NewReader(body))
+	assert.Nil(t, err)
+	res, err := http.DefaultClient.Do(request)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json; charset=UTF-8", res.Header.Get("Content-Type"))
+	var actual ResolveSyncConflictResponse
+	err = json.NewDecoder(res.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", actual.Result)
+	assert.Equal(t, "merged-hash", actual.RecordHash)
+	if assert.NotNil(t, conflictRepo.resolvedWith) {
+		assert.Equal(t, merged.Fields[0].FieldValue, conflictRepo.resolvedWith.Fields[0].FieldValue)
+	}
+
+	//Only Local, Remote and Merged are routed
+	request, err = http.NewRequest("PUT", server.URL+"/syncConflict/conflictId/conflict-1/resolve/Other", nil)
+	assert.Nil(t, err)
+	res, err = http.DefaultClient.Do(request)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	testhelper.EndTest(testName)
+}
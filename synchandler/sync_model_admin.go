@@ -0,0 +1,255 @@
+package synchandler
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncutil"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+//DataVersionItem is a data version of the sync model.
+type DataVersionItem struct {
+	DataVersionName string `json:"dataVersionName"`
+}
+
+//ListDataVersionsResponse is the response structure for ListDataVersions.
+type ListDataVersionsResponse struct {
+	DataVersions []DataVersionItem `json:"dataVersions"`
+}
+
+//FieldItem is a field of an entity. DataTypeName is one of 'String', 'Int', 'Float', 'Bool', 'Date' or 'Binary'.
+type FieldItem struct {
+	FieldName    string `json:"fieldName"`
+	DataTypeName string `json:"dataTypeName"`
+	IsPrimaryKey bool   `json:"isPrimaryKey"`
+}
+
+//EntityItem is an entity of a data version along with its fields ordered by field name.
+type EntityItem struct {
+	EntitySingularName    string      `json:"entitySingularName"`
+	EntityPluralName      string      `json:"entityPluralName"`
+	ProcessOrderAddUpdate int         `json:"processOrderAddUpdate"`
+	ProcessOrderDelete    int         `json:"processOrderDelete"`
+	EntityHandlerURI      string      `json:"entityHandlerUri"`
+	Fields                []FieldItem `json:"fields"`
+}
+
+//ListEntitiesResponse is the response structure for ListEntities, AddEntity and AddField.
+type ListEntitiesResponse struct {
+	DataVersionName string       `json:"dataVersionName"`
+	Entities        []EntityItem `json:"entities"`
+}
+
+//ValidateSyncModelResponse is the response structure for ValidateSyncModel.
+type ValidateSyncModelResponse struct {
+	DataVersionName string `json:"dataVersionName"`
+	//Valid is true when there are no Problems
+	Valid    bool     `json:"valid"`
+	Problems []string `json:"problems"`
+}
+
+func toEntityItem(definition syncdao.EntityDefinition) EntityItem {
+	item := EntityItem{
+		EntitySingularName:    definition.Entity.EntitySingularName,
+		EntityPluralName:      definition.Entity.EntityPluralName,
+		ProcessOrderAddUpdate: definition.Entity.ProcessOrderAddUpdate,
+		ProcessOrderDelete:    definition.Entity.ProcessOrderDelete,
+		EntityHandlerURI:      definition.Entity.EntityHandlerURI,
+		Fields:                []FieldItem{},
+	}
+	for _, field := range definition.Fields {
+		item.Fields = append(item.Fields, FieldItem{
+			FieldName:    field.FieldName,
+			DataTypeName: syncdao.SyncFieldTypeEnumName[int32(field.FieldType)],
+			IsPrimaryKey: field.IsPrimaryKey,
+		})
+	}
+	return item
+}
+
+//ListDataVersions lists every data version of the sync model ordered by name. Invoked performed via the following
+//http command:
+//	curl -i --request GET http://localhost:8080/syncDataVersion
+func ListDataVersions(w http.ResponseWriter, r *http.Request) {
+	if dbSetupError(w) {
+		return
+	}
+	dataVersionNames, err := syncdao.DefaultDaos.SyncPairDao().ListDataVersions()
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	answer := ListDataVersionsResponse{DataVersions: []DataVersionItem{}}
+	for _, dataVersionName := range dataVersionNames {
+		answer.DataVersions = append(answer.DataVersions, DataVersionItem{DataVersionName: dataVersionName})
+	}
+	writePairJSON(w, answer)
+}
+
+//CreateDataVersion registers the data version of the DataVersionItem of the body, which the entities and nodes are
+//then added to. Invoked performed via the following http command:
+//	curl -i --header "Content-Type: application/json" --request POST http://localhost:8080/syncDataVersion -d '{"dataVersionName":"Demo Model 3"}'
+func CreateDataVersion(w http.ResponseWriter, r *http.Request) {
+	var request DataVersionItem
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		errMsg := "Could not parse json from body"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	err = syncapi.ValidateDataVersionName(request.DataVersionName)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	err = syncdao.DefaultDaos.SyncPairDao().AddDataVersion(request.DataVersionName)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	writePairJSON(w, request)
+}
+
+//ListEntities lists the entities of a data version along with their fields, ordered by add/update process order.
+//Invoked performed via the following http command:
+//	curl -i --request GET "http://localhost:8080/syncDataVersion/dataVersionName/Demo%20Model%201/entities"
+func ListEntities(w http.ResponseWriter, r *http.Request) {
+	dataVersionName, hasValue := mux.Vars(r)["dataVersionName"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	writeEntities(w, dataVersionName)
+}
+
+//AddEntity adds the syncdao.EntityPairItem of the body, without fields, to a data version. The process orders of the
+//entities of a data version must agree: the delete order reverses the add/update order. Invoked performed via the
+//following http command:
+//	curl -i --header "Content-Type: application/json" --request POST "http://localhost:8080/syncDataVersion/dataVersionName/Demo%20Model%203/entities" -d '{"entitySingularName":"Task","entityPluralName":"Tasks","processOrderAddUpdate":1,"processOrderDelete":1,"entityHandlerUri":"none"}'
+func AddEntity(w http.ResponseWriter, r *http.Request) {
+	dataVersionName, hasValue := mux.Vars(r)["dataVersionName"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var request syncdao.EntityPairItem
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		errMsg := "Could not parse json from body"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	err = syncapi.ValidateEntity(request)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	err = syncdao.DefaultDaos.SyncPairDao().AddEntity(dataVersionName, request)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	writeEntities(w, dataVersionName)
+}
+
+//AddField adds the FieldItem of the body to an entity of a data version. Invoked performed via the following http
+//command:
+//	curl -i --header "Content-Type: application/json" --request POST "http://localhost:8080/syncDataVersion/dataVersionName/Demo%20Model%203/entitySingularName/Task/fields" -d '{"fieldName":"taskId","dataTypeName":"String","isPrimaryKey":true}'
+func AddField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	dataVersionName, hasDataVersionName := vars["dataVersionName"]
+	entitySingularName, hasEntitySingularName := vars["entitySingularName"]
+	if !hasDataVersionName || !hasEntitySingularName {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var request FieldItem
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		errMsg := "Could not parse json from body"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	fieldType, err := syncapi.ParseFieldType(request.DataTypeName)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	field := syncdao.SyncFieldDefinition{
+		FieldName:    request.FieldName,
+		FieldType:    fieldType,
+		IsPrimaryKey: request.IsPrimaryKey,
+	}
+	err = syncapi.ValidateField(field)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	err = syncdao.DefaultDaos.SyncPairDao().AddField(dataVersionName, entitySingularName, field)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	writeEntities(w, dataVersionName)
+}
+
+//ValidateSyncModel tells whether the entities of a data version can be synced, and if not why. Beyond the checks made
+//while adding them, every entity needs a primary key field. Invoked performed via the following http command:
+//	curl -i --request GET "http://localhost:8080/syncDataVersion/dataVersionName/Demo%20Model%201/validate"
+func ValidateSyncModel(w http.ResponseWriter, r *http.Request) {
+	dataVersionName, hasValue := mux.Vars(r)["dataVersionName"]
+	if !hasValue {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if dbSetupError(w) {
+		return
+	}
+	entities, err := syncdao.DefaultDaos.SyncPairDao().ListEntities(dataVersionName)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	problems := syncapi.SyncModelProblems(entities)
+	writePairJSON(w, ValidateSyncModelResponse{
+		DataVersionName: dataVersionName,
+		Valid:           len(problems) == 0,
+		Problems:        problems,
+	})
+}
+
+//writeEntities answers the stored entities of the data version.
+func writeEntities(w http.ResponseWriter, dataVersionName string) {
+	entities, err := syncdao.DefaultDaos.SyncPairDao().ListEntities(dataVersionName)
+	if err != nil {
+		writePairError(w, err)
+		return
+	}
+	answer := ListEntitiesResponse{DataVersionName: dataVersionName, Entities: []EntityItem{}}
+	for _, entity := range entities {
+		answer.Entities = append(answer.Entities, toEntityItem(entity))
+	}
+	writePairJSON(w, answer)
+}
@@ -0,0 +1,66 @@
+package synchandler
+
+import (
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlers_SyncModel(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	setupHTTPEnv()
+	defer teardownHTTPEnv()
+
+	var versions ListDataVersionsResponse
+	if !assert.Equal(t, http.StatusOK, doPairRequest(t, "GET", "/syncDataVersion", "", &versions)) {
+		return
+	}
+	assert.Equal(t, []DataVersionItem{{DataVersionName: "Demo Model 1"}, {DataVersionName: "Demo Model 2 (orphand node)"}}, versions.DataVersions)
+
+	var entities ListEntitiesResponse
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "GET", "/syncDataVersion/dataVersionName/Demo%20Model%201/entities", "", &entities))
+	if assert.Len(t, entities.Entities, 6) {
+		assert.Equal(t, "Contact", entities.Entities[4].EntitySingularName)
+		assert.Equal(t, FieldItem{FieldName: "contactId", DataTypeName: "String", IsPrimaryKey: true}, entities.Entities[4].Fields[0])
+	}
+	assert.Equal(t, http.StatusNotFound, doPairRequest(t, "GET", "/syncDataVersion/dataVersionName/Unknown/entities", "", nil))
+
+	var validation ValidateSyncModelResponse
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "GET", "/syncDataVersion/dataVersionName/Demo%20Model%201/validate", "", &validation))
+	assert.False(t, validation.Valid)
+	assert.Contains(t, validation.Problems, "Entity 'Entity 1' has no primary key field")
+
+	var version DataVersionItem
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "POST", "/syncDataVersion", `{"dataVersionName":"Demo Model 3"}`, &version))
+	assert.Equal(t, "Demo Model 3", version.DataVersionName)
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncDataVersion", `{"dataVersionName":"Demo Model 3"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncDataVersion", `not json`, nil))
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncDataVersion", `{"dataVersionName":""}`, nil), "a data version has a name")
+
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "POST", "/syncDataVersion/dataVersionName/Demo%20Model%203/entities", `{"entitySingularName":"Project","entityPluralName":"Projects","processOrderAddUpdate":1,"processOrderDelete":2,"entityHandlerUri":"none"}`, &entities))
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "POST", "/syncDataVersion/dataVersionName/Demo%20Model%203/entities", `{"entitySingularName":"Task","entityPluralName":"Tasks","processOrderAddUpdate":2,"processOrderDelete":1,"entityHandlerUri":"none"}`, &entities))
+	assert.Len(t, entities.Entities, 2)
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncDataVersion/dataVersionName/Demo%20Model%203/entities", `{"entitySingularName":"Note","entityPluralName":"Notes","processOrderAddUpdate":3,"processOrderDelete":3}`, nil))
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncDataVersion/dataVersionName/Unknown/entities", `{"entitySingularName":"Note","entityPluralName":"Notes"}`, nil), "the process orders start at 1")
+	assert.Equal(t, http.StatusNotFound, doPairRequest(t, "POST", "/syncDataVersion/dataVersionName/Unknown/entities", `{"entitySingularName":"Note","entityPluralName":"Notes","processOrderAddUpdate":1,"processOrderDelete":1}`, nil))
+
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "POST", "/syncDataVersion/dataVersionName/Demo%20Model%203/entitySingularName/Project/fields", `{"fieldName":"projectId","dataTypeName":"String","isPrimaryKey":true}`, &entities))
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncDataVersion/dataVersionName/Demo%20Model%203/entitySingularName/Task/fields", `{"fieldName":"taskId","dataTypeName":"Text"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, doPairRequest(t, "POST", "/syncDataVersion/dataVersionName/Demo%20Model%203/entitySingularName/Note/fields", `{"fieldName":"","dataTypeName":"String"}`, nil), "a field has a name")
+	assert.Equal(t, http.StatusNotFound, doPairRequest(t, "POST", "/syncDataVersion/dataVersionName/Demo%20Model%203/entitySingularName/Note/fields", `{"fieldName":"noteId","dataTypeName":"String"}`, nil))
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "GET", "/syncDataVersion/dataVersionName/Demo%20Model%203/validate", "", &validation))
+	assert.Equal(t, ValidateSyncModelResponse{DataVersionName: "Demo Model 3", Problems: []string{"Entity 'Task' has no primary key field"}}, validation)
+
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "POST", "/syncDataVersion/dataVersionName/Demo%20Model%203/entitySingularName/Task/fields", `{"fieldName":"taskId","dataTypeName":"String","isPrimaryKey":true}`, &entities))
+	if assert.Len(t, entities.Entities, 2) {
+		assert.Equal(t, []FieldItem{{FieldName: "taskId", DataTypeName: "String", IsPrimaryKey: true}}, entities.Entities[1].Fields)
+	}
+	assert.Equal(t, http.StatusOK, doPairRequest(t, "GET", "/syncDataVersion/dataVersionName/Demo%20Model%203/validate", "", &validation))
+	assert.Equal(t, ValidateSyncModelResponse{DataVersionName: "Demo Model 3", Valid: true, Problems: []string{}}, validation)
+
+	testhelper.EndTest(testName)
+}
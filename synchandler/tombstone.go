@@ -0,0 +1,44 @@
+package synchandler
+
+import (
+	"data-sync-tools-go/syncutil"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+//CollectTombstones purges the deleted records every paired node has acknowledged and reports how many were purged per
+//entity. The optional 'retention' query parameter (e.g. 720h) also purges tombstones older than it, overriding the
+//configured TombstoneRetention. Invoked performed via the following http commands:
+//	curl -i --request PUT http://localhost:8080/tombstone/collect
+//	curl -i --request PUT http://localhost:8080/tombstone/collect?retention=720h
+func (handlers Handlers) CollectTombstones(w http.ResponseWriter, r *http.Request) {
+	if handlers.Repository.TombstoneRepo == nil {
+		errMsg := "Server Configuration Error: Tombstone repository not set"
+		syncutil.Error(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+	retention := handlers.TombstoneRetention
+	if retentionValue := r.URL.Query().Get("retention"); retentionValue != "" {
+		var err error
+		retention, err = time.ParseDuration(retentionValue)
+		if err != nil || retention < 0 {
+			errMsg := "Invalid retention '" + retentionValue + "'. Expected a duration such as '720h'"
+			syncutil.Error(errMsg)
+			http.Error(w, errMsg, http.StatusBadRequest)
+			return
+		}
+	}
+	result, err := handlers.Repository.TombstoneRepo.CollectTombstones(retention)
+	if err != nil {
+		syncutil.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
+}
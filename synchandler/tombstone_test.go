@@ -0,0 +1,80 @@
+package synchandler
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readyTombstone(tombstoneRepo *mockTombstoneRepository, retention time.Duration) *httptest.Server {
+	handlers := Handlers{
+		Repository: syncapi.Repository{
+			DataRepo:      mockDataRepository{},
+			ConfigRepo:    mockConfigRepository{},
+			TombstoneRepo: tombstoneRepo,
+		},
+		TombstoneRetention: retention,
+	}
+	return httptest.NewServer(NewRouter(handlers))
+}
+
+func TestHandlers_CollectTombstones(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+
+	tombstoneRepo := &mockTombstoneRepository{
+		collectAnswer: syncapi.TombstoneCollectResult{
+			Retention:      "168h0m0s",
+			TotalPurged:    3,
+			PurgedByEntity: map[string]int{"Contact": 2, "A": 1},
+		},
+	}
+	server := readyTombstone(tombstoneRepo, 168*time.Hour)
+	defer server.Close()
+
+	req, err := http.NewRequest("PUT", server.URL+"/tombstone/collect", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	res, err := http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, 168*time.Hour, tombstoneRepo.collectedWith)
+	var result syncapi.TombstoneCollectResult
+	err = json.NewDecoder(res.Body).Decode(&result)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.TotalPurged)
+	assert.Equal(t, 2, result.PurgedByEntity["Contact"])
+
+	req, err = http.NewRequest("PUT", server.URL+"/tombstone/collect?retention=24h", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	res, err = http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, 24*time.Hour, tombstoneRepo.collectedWith)
+
+	req, err = http.NewRequest("PUT", server.URL+"/tombstone/collect?retention=forever", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	res, err = http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	testhelper.EndTest(testName)
+}
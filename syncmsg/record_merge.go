@@ -0,0 +1,84 @@
+package syncmsg
+
+import (
+	"bytes"
+	"sort"
+)
+
+//MergeRecords performs a field level three-way merge of the local and remote versions of a record given their common
+//ancestor. For each field name found in any of the three records:
+//
+// 1. local and remote hold the same value -> that value is kept
+//
+// 2. only remote changed the field since the ancestor -> the remote value is kept (or the field removed)
+//
+// 3. only local changed the field since the ancestor -> the local value is kept (or the field removed)
+//
+// 4. both changed the field to different values -> the local value is kept and the field name is reported
+//
+//When the ancestor is nil (i.e. it is no longer available) which side changed a field cannot be told: a field carried
+//by only one side is taken from that side and every field holding different values is reported. The merged record
+//lists its fields in alphabetical order as required for stable record hashes. The reported field names are sorted.
+func MergeRecords(ancestor *ProtoRecord, local *ProtoRecord, remote *ProtoRecord) (*ProtoRecord, []string) {
+	ancestorFields := mapFieldsByName(ancestor)
+	localFields := mapFieldsByName(local)
+	remoteFields := mapFieldsByName(remote)
+
+	fieldNames := []string{}
+	for _, fields := range []map[string]*ProtoField{ancestorFields, localFields, remoteFields} {
+		for fieldName := range fields {
+			fieldNames = append(fieldNames, fieldName)
+		}
+	}
+	sort.Strings(fieldNames)
+
+	merged := &ProtoRecord{Fields: []*ProtoField{}}
+	conflictingFields := []string{}
+	var lastFieldName string
+	for index, fieldName := range fieldNames {
+		if index > 0 && fieldName == lastFieldName {
+			continue
+		}
+		lastFieldName = fieldName
+		localField, remoteField := localFields[fieldName], remoteFields[fieldName]
+		var keep *ProtoField
+		switch {
+		case FieldsEqual(localField, remoteField):
+			keep = localField
+		case ancestor == nil && localField == nil:
+			keep = remoteField
+		case ancestor == nil && remoteField == nil:
+			keep = localField
+		case ancestor != nil && FieldsEqual(localField, ancestorFields[fieldName]):
+			keep = remoteField
+		case ancestor != nil && FieldsEqual(remoteField, ancestorFields[fieldName]):
+			keep = localField
+		default:
+			keep = localField
+			conflictingFields = append(conflictingFields, fieldName)
+		}
+		if keep != nil {
+			merged.Fields = append(merged.Fields, keep)
+		}
+	}
+	return merged, conflictingFields
+}
+
+//FieldsEqual tells if two fields hold the same encoded type and value. Two nil fields are equal.
+func FieldsEqual(a *ProtoField, b *ProtoField) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.GetEncodedFieldType() == b.GetEncodedFieldType() && bytes.Equal(a.FieldValue, b.FieldValue)
+}
+
+func mapFieldsByName(record *ProtoRecord) map[string]*ProtoField {
+	answer := make(map[string]*ProtoField)
+	if record == nil {
+		return answer
+	}
+	for _, field := range record.Fields {
+		answer[field.GetFieldName()] = field
+	}
+	return answer
+}
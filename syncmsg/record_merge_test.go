@@ -0,0 +1,121 @@
+package syncmsg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func fieldNames(record *ProtoRecord) []string {
+	answer := []string{}
+	for _, field := range record.Fields {
+		answer = append(answer, field.GetFieldName())
+	}
+	return answer
+}
+
+func TestMergeRecords_SeparateFieldsChanged(t *testing.T) {
+	creator := NewCreator()
+	ancestor := &ProtoRecord{
+		Fields: []*ProtoField{
+			creator.CreateStringProtoField("contactId", "record 01"),
+			creator.CreateStringProtoField("firstName", "Henry"),
+			creator.CreateInt64ProtoField("heightFt", 5),
+			creator.CreateStringProtoField("lastName", "Adins"),
+		},
+	}
+	local := &ProtoRecord{
+		Fields: []*ProtoField{
+			creator.CreateStringProtoField("contactId", "record 01"),
+			creator.CreateStringProtoField("firstName", "Henry"),
+			creator.CreateInt64ProtoField("heightFt", 5),
+			creator.CreateStringProtoField("lastName", "Adkins"),
+		},
+	}
+	remote := &ProtoRecord{
+		Fields: []*ProtoField{
+			creator.CreateStringProtoField("contactId", "record 01"),
+			creator.CreateInt64ProtoField("heightFt", 6),
+			creator.CreateStringProtoField("lastName", "Adins"),
+		},
+	}
+	merged, conflictingFields := MergeRecords(ancestor, local, remote)
+	if len(conflictingFields) != 0 {
+		t.Errorf("Expected no conflicting fields. it's '%v'", conflictingFields)
+	}
+	//firstName was removed by remote only, so it is removed from the merge
+	expected := &ProtoRecord{
+		Fields: []*ProtoField{
+			creator.CreateStringProtoField("contactId", "record 01"),
+			creator.CreateInt64ProtoField("heightFt", 6),
+			creator.CreateStringProtoField("lastName", "Adkins"),
+		},
+	}
+	if !reflect.DeepEqual(expected, merged) {
+		t.Errorf("Merged record not expected '%v'. it's '%v'", expected, merged)
+	}
+}
+
+func TestMergeRecords_SameFieldChanged(t *testing.T) {
+	creator := NewCreator()
+	ancestor := &ProtoRecord{
+		Fields: []*ProtoField{
+			creator.CreateStringProtoField("contactId", "record 01"),
+			creator.CreateStringProtoField("lastName", "Adins"),
+		},
+	}
+	local := &ProtoRecord{
+		Fields: []*ProtoField{
+			creator.CreateStringProtoField("contactId", "record 01"),
+			creator.CreateStringProtoField("lastName", "Adkins"),
+		},
+	}
+	remote := &ProtoRecord{
+		Fields: []*ProtoField{
+			creator.CreateStringProtoField("contactId", "record 01"),
+			creator.CreateStringProtoField("lastName", "Atkins"),
+		},
+	}
+	merged, conflictingFields := MergeRecords(ancestor, local, remote)
+	if !reflect.DeepEqual([]string{"lastName"}, conflictingFields) {
+		t.Errorf("Expected 'lastName' to conflict. it's '%v'", conflictingFields)
+	}
+	if !reflect.DeepEqual(local, merged) {
+		t.Errorf("Expected the local values to be kept. it's '%v'", merged)
+	}
+
+	//Both sides making the same change is not a conflict
+	merged, conflictingFields = MergeRecords(ancestor, local, local)
+	if len(conflictingFields) != 0 || !reflect.DeepEqual(local, merged) {
+		t.Errorf("Expected identical changes to merge. it's '%v' with conflicts '%v'", merged, conflictingFields)
+	}
+}
+
+func TestMergeRecords_NoAncestor(t *testing.T) {
+	creator := NewCreator()
+	local := &ProtoRecord{
+		Fields: []*ProtoField{
+			creator.CreateStringProtoField("contactId", "record 01"),
+			creator.CreateStringProtoField("lastName", "Smith"),
+		},
+	}
+	remote := &ProtoRecord{
+		Fields: []*ProtoField{
+			creator.CreateStringProtoField("contactId", "record 01"),
+			creator.CreateStringProtoField("firstName", "Jennifer"),
+			creator.CreateStringProtoField("lastName", "Smith"),
+		},
+	}
+	merged, conflictingFields := MergeRecords(nil, local, remote)
+	if len(conflictingFields) != 0 {
+		t.Errorf("Expected no conflicting fields. it's '%v'", conflictingFields)
+	}
+	if expectedNames := []string{"contactId", "firstName", "lastName"}; !reflect.DeepEqual(expectedNames, fieldNames(merged)) {
+		t.Errorf("Merged fields not expected '%v'. it's '%v'", expectedNames, fieldNames(merged))
+	}
+
+	remote.Fields[2] = creator.CreateStringProtoField("lastName", "Smyth")
+	_, conflictingFields = MergeRecords(nil, local, remote)
+	if !reflect.DeepEqual([]string{"lastName"}, conflictingFields) {
+		t.Errorf("Expected 'lastName' to conflict. it's '%v'", conflictingFields)
+	}
+}
@@ -0,0 +1,80 @@
+package syncorchestrator
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"sort"
+)
+
+//syncDirection sends the changes queued by the agent from for the node of to: for AddOrUpdate and then Delete, the
+//entities of each process order of the node of to are fetched a batch at a time until none are left, every batch being
+//processed by to and its results acknowledged to from.
+func (orchestrator Orchestrator) syncDirection(sessionID string, from Agent, fromNode syncdao.NodePairItem, to Agent, toNode syncdao.NodePairItem, summary *DirectionSummary) error {
+	for _, changeType := range []syncapi.ProcessSyncChangeEnum{syncapi.ProcessSyncChangeEnumAddOrUpdate, syncapi.ProcessSyncChangeEnumDelete} {
+		for _, orderNum := range processOrders(toNode.Entities, changeType) {
+			for {
+				var fetched *syncmsg.ProtoRequestSyncEntityMessageResponse
+				err := orchestrator.retry(func() error {
+					var err error
+					fetched, err = from.producer().FetchSyncData(sessionID, toNode.NodeID, orderNum, changeType, orchestrator.Limits)
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				request := fetched.GetRequest()
+				if fetched.GetResult() == syncmsg.SyncRequestEntityMessageResponseResult_NoMsgs || countMsgs(request) == 0 {
+					break
+				}
+				var processed *syncmsg.ProtoSyncEntityMessageResponse
+				err = orchestrator.retry(func() error {
+					var err error
+					processed, err = to.Client.ProcessSyncData(sessionID, fromNode.NodeID, request)
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				err = orchestrator.retry(func() error {
+					_, err := from.producer().AcknowledgeSyncData(sessionID, toNode.NodeID, processed)
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				summary.add(request, processed)
+				if summary.TotalSent() > summary.RecordsQueued {
+					return errTooManyRecords(*summary)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+//processOrders gives the distinct process orders of entities for changeType, in the order they are processed.
+func processOrders(entities []syncdao.EntityPairItem, changeType syncapi.ProcessSyncChangeEnum) []int {
+	found := map[int]bool{}
+	answer := []int{}
+	for _, entity := range entities {
+		orderNum := entity.ProcessOrderDelete
+		if changeType == syncapi.ProcessSyncChangeEnumAddOrUpdate {
+			orderNum = entity.ProcessOrderAddUpdate
+		}
+		if !found[orderNum] {
+			found[orderNum] = true
+			answer = append(answer, orderNum)
+		}
+	}
+	sort.Ints(answer)
+	return answer
+}
+
+func countMsgs(request *syncmsg.ProtoSyncEntityMessageRequest) int {
+	answer := 0
+	for _, item := range request.GetItems() {
+		answer += len(item.Msgs)
+	}
+	return answer
+}
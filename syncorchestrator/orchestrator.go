@@ -0,0 +1,221 @@
+//Package syncorchestrator drives a whole sync session of a sync_pair between two agents through syncclient: it starts
+//the session on both agents, queues the changes of each node for the other, fetches them entity order by entity order
+//for AddOrUpdate and then Delete, has the receiving agent process every batch and the sending agent acknowledge it,
+//and closes the session.
+package syncorchestrator
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncclient"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/synchandler"
+	"data-sync-tools-go/syncutil"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+//Agent is the agent holding the data of the node named NodeName.
+type Agent struct {
+	NodeName string
+	//Client manages the sessions of the agent and has it process the changes of its peer.
+	Client *syncclient.Client
+	//ProducerClient, when set, queues and fetches the changes of the agent and acknowledges them instead of Client, as
+	//for an agent taking changes at its DataMsgConsumerURI and giving them at its DataMsgProducerURI.
+	ProducerClient *syncclient.Client
+}
+
+//producer gives the client pulling changes from the agent.
+func (agent Agent) producer() *syncclient.Client {
+	if agent.ProducerClient != nil {
+		return agent.ProducerClient
+	}
+	return agent.Client
+}
+
+//Orchestrator syncs the pair of the nodes of Local and Peer. Local and Peer may be the same agent when it holds the
+//data of both nodes.
+type Orchestrator struct {
+	Local Agent
+	Peer  Agent
+	//Limits override the batch sizes configured for the nodes when fetching.
+	Limits syncapi.FetchLimits
+	//MaxRetries is how many times a call failing for a reason other than its result, such as an agent not answering,
+	//is retried before Sync gives up.
+	MaxRetries int
+	//RetryWait is the time waited before retrying a call.
+	RetryWait time.Duration
+}
+
+//ResumedReasonMsg is the reason recorded with a session canceled to resume a failed Sync.
+const ResumedReasonMsg = "Canceled to resume the sync of the session"
+
+//Sync syncs the pair in both directions, Local to Peer then Peer to Local, under the session sessionID.
+//
+//A Sync failing part way leaves its session active. Calling Sync again with the same sessionID resumes it: the
+//session left active is canceled, releasing the records it reserved, and the sync starts over. The records acknowledged
+//before the failure are not sent again. A pair with a different active session is not synced.
+func (orchestrator Orchestrator) Sync(sessionID string) (Summary, error) {
+	answer := Summary{SessionID: sessionID}
+	var config synchandler.PairConfigResponse
+	err := orchestrator.retry(func() error {
+		var err error
+		config, err = orchestrator.Local.Client.GetSyncConfig(orchestrator.Local.NodeName, orchestrator.Peer.NodeName)
+		return err
+	})
+	if err != nil {
+		return answer, err
+	}
+	answer.PairID = config.PairID
+	localNode, peerNode := config.Node1, config.Node2
+
+	agents := orchestrator.sessionAgents()
+	for _, agent := range agents {
+		resumed, err := orchestrator.startSession(agent, config.PairID, sessionID)
+		if err != nil {
+			return answer, err
+		}
+		answer.Resumed = answer.Resumed || resumed
+	}
+
+	err = orchestrator.updateState(agents, config.PairID, sessionID, syncapi.SyncSessionStateQueuing)
+	if err != nil {
+		return answer, err
+	}
+	toPeer := DirectionSummary{FromNodeName: localNode.NodeName, ToNodeName: peerNode.NodeName}
+	toPeer.RecordsQueued, err = orchestrator.queue(orchestrator.Local, sessionID, peerNode)
+	if err != nil {
+		return answer, err
+	}
+	toLocal := DirectionSummary{FromNodeName: peerNode.NodeName, ToNodeName: localNode.NodeName}
+	toLocal.RecordsQueued, err = orchestrator.queue(orchestrator.Peer, sessionID, localNode)
+	if err != nil {
+		return answer, err
+	}
+
+	err = orchestrator.updateState(agents, config.PairID, sessionID, syncapi.SyncSessionStateSyncing)
+	if err != nil {
+		return answer, err
+	}
+	err = orchestrator.syncDirection(sessionID, orchestrator.Local, localNode, orchestrator.Peer, peerNode, &toPeer)
+	answer.Directions = append(answer.Directions, toPeer)
+	if err != nil {
+		return answer, err
+	}
+	err = orchestrator.syncDirection(sessionID, orchestrator.Peer, peerNode, orchestrator.Local, localNode, &toLocal)
+	answer.Directions = append(answer.Directions, toLocal)
+	if err != nil {
+		return answer, err
+	}
+
+	for _, agent := range agents {
+		err = orchestrator.retry(func() error {
+			_, err := agent.Client.CloseSyncSession(sessionID, config.PairID)
+			return err
+		})
+		if err != nil {
+			return answer, err
+		}
+	}
+	syncutil.Info("Synced pair", config.PairID, "in session", sessionID, ":", answer.TotalSent(), "records sent")
+	return answer, nil
+}
+
+//sessionAgents gives the agents holding a session of the pair, Peer only being one when it is not Local.
+func (orchestrator Orchestrator) sessionAgents() []Agent {
+	if orchestrator.Peer.Client.BaseURL == orchestrator.Local.Client.BaseURL {
+		return []Agent{orchestrator.Local}
+	}
+	return []Agent{orchestrator.Local, orchestrator.Peer}
+}
+
+//startSession creates the session on the agent and moves it into Seeding, first canceling the session when a failed
+//Sync left it active. It answers whether the session was canceled.
+func (orchestrator Orchestrator) startSession(agent Agent, pairID string, sessionID string) (bool, error) {
+	var pairState synchandler.QueryPairStateResponse
+	err := orchestrator.retry(func() error {
+		var err error
+		pairState, err = agent.Client.GetPairState(pairID)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	resumed := pairState.SessionID == sessionID && pairState.State != syncapi.SyncSessionStateInactive
+	if resumed {
+		syncutil.Info("Canceling session", sessionID, "left", pairState.State, "by a failed sync of pair", pairID)
+		err = orchestrator.retry(func() error {
+			_, err := agent.Client.CancelSyncSession(sessionID, ResumedReasonMsg)
+			return err
+		})
+		if err != nil {
+			return resumed, err
+		}
+	}
+	err = orchestrator.retry(func() error {
+		_, err := agent.Client.CreateSyncSession(sessionID, pairID)
+		//A retried create finds the session the failed attempt created
+		if resultErr, ok := err.(*syncclient.ResultError); ok && resultErr.Result == "ThisSessionIdAlreadyActive" {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return resumed, err
+	}
+	return resumed, orchestrator.updateState([]Agent{agent}, pairID, sessionID, syncapi.SyncSessionStateSeeding)
+}
+
+func (orchestrator Orchestrator) updateState(agents []Agent, pairID string, sessionID string, state string) error {
+	for _, agent := range agents {
+		err := orchestrator.retry(func() error {
+			_, err := agent.Client.UpdateSyncSessionState(sessionID, pairID, state)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//queue queues the changes agent holds for the node to.
+func (orchestrator Orchestrator) queue(agent Agent, sessionID string, to syncdao.NodePairItem) (int, error) {
+	var recordsQueued int
+	err := orchestrator.retry(func() error {
+		queued, err := agent.producer().QueueSyncChanges(sessionID, to.NodeID, sessionID+":"+to.NodeID)
+		recordsQueued = queued.RecordsQueuedCount
+		return err
+	})
+	return recordsQueued, err
+}
+
+//retry calls call until it succeeds, fails for its result or a bad request, or failed MaxRetries more times.
+func (orchestrator Orchestrator) retry(call func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = call()
+		if err == nil || !isRetryable(err) || attempt >= orchestrator.MaxRetries {
+			return err
+		}
+		syncutil.Warn("Retrying after error:", err)
+		time.Sleep(orchestrator.RetryWait)
+	}
+}
+
+//isRetryable tells whether a call failing with err may succeed when made again.
+func isRetryable(err error) bool {
+	switch typedErr := err.(type) {
+	case *syncclient.ResultError:
+		return false
+	case *syncclient.StatusError:
+		return typedErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+//errTooManyRecords is given when an agent keeps giving records after all those queued were sent.
+func errTooManyRecords(direction DirectionSummary) error {
+	return fmt.Errorf("%s sent %d records to %s of the %d queued", direction.FromNodeName, direction.TotalSent(),
+		direction.ToNodeName, direction.RecordsQueued)
+}
@@ -0,0 +1,218 @@
+package syncorchestrator
+
+import (
+	"data-sync-tools-go/syncapi"
+	"data-sync-tools-go/syncclient"
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncdao/syncdaomem"
+	"data-sync-tools-go/synchandler"
+	"data-sync-tools-go/syncutil"
+	"data-sync-tools-go/testhelper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//agentMutex serializes the requests to the agents of a test, each serving with its own syncdao.DefaultDaos.
+var agentMutex sync.Mutex
+
+//readyAgent serves the sample data of seed the way the agent does. The first failures calls to ProcessSyncData answer
+//with an http 500.
+func readyAgent(seed func(factory *syncdaomem.MemoryDaosFactory) error, failures int) *httptest.Server {
+	factory := syncdaomem.NewMemoryDaosFactory()
+	err := seed(factory)
+	if err != nil {
+		panic(err)
+	}
+	handlers := synchandler.Handlers{
+		Repository: syncapi.Repository{
+			DataRepo:      syncdaomem.NewDataRepository(factory),
+			ConfigRepo:    syncdaomem.NewConfigRepository(factory),
+			ConflictRepo:  syncdaomem.NewConflictRepository(factory),
+			TombstoneRepo: syncdaomem.NewTombstoneRepository(factory),
+			SessionRepo:   syncdaomem.NewSessionRepository(factory),
+			NodeAdminRepo: syncdaomem.NewNodeAdminRepository(factory),
+		},
+	}
+	router := synchandler.NewRouter(handlers)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agentMutex.Lock()
+		defer agentMutex.Unlock()
+		if failures > 0 && strings.HasPrefix(r.URL.Path, "/syncData/") {
+			failures--
+			http.Error(w, "Agent unavailable", http.StatusInternalServerError)
+			return
+		}
+		syncdao.DefaultDaos = factory
+		router.ServeHTTP(w, r)
+	}))
+}
+
+//readyAgents serves the nodes of the pair 'A <-> Z': A with the profile3records sample data, queued for Z, and Z
+//with the sync model only. The first failures calls to ProcessSyncData of Z answer with an http 500.
+func readyAgents(failures int) (*httptest.Server, *httptest.Server) {
+	local := readyAgent(func(factory *syncdaomem.MemoryDaosFactory) error {
+		return testhelper.SetupSeededData(factory, "profile3records")
+	}, 0)
+	peer := readyAgent(func(factory *syncdaomem.MemoryDaosFactory) error {
+		return testhelper.SetupSeededModel(factory)
+	}, failures)
+	return local, peer
+}
+
+func newOrchestrator(local *httptest.Server, peer *httptest.Server) Orchestrator {
+	return Orchestrator{
+		Local: Agent{NodeName: "A", Client: syncclient.NewClient(local.URL)},
+		Peer:  Agent{NodeName: "Z", Client: syncclient.NewClient(peer.URL)},
+		Limits: syncapi.FetchLimits{
+			MaxMsgs: 2,
+		},
+	}
+}
+
+func TestOrchestrator_Sync(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	local, peer := readyAgents(0)
+	defer local.Close()
+	defer peer.Close()
+	orchestrator := newOrchestrator(local, peer)
+
+	summary, err := orchestrator.Sync("*session-id-1")
+	if !assert.Nil(t, err) || !assert.Len(t, summary.Directions, 2) {
+		return
+	}
+	assert.Equal(t, "*pair-1", summary.PairID)
+	assert.False(t, summary.Resumed)
+	toPeer := summary.Directions[0]
+	assert.Equal(t, "A", toPeer.FromNodeName)
+	assert.Equal(t, "Z", toPeer.ToNodeName)
+	assert.Equal(t, 6, toPeer.RecordsQueued)
+	assert.Equal(t, 6, toPeer.TotalSent())
+	if assert.Len(t, toPeer.Entities, 2) {
+		//Entity 1 is processed first, and 3 records take two batches of at most 2
+		assert.Equal(t, "Entity 1", toPeer.Entities[0].EntityPluralName)
+		assert.Equal(t, 3, toPeer.Entities[0].AddedOrUpdated)
+		assert.Equal(t, 2, toPeer.Entities[0].Batches)
+		//The peer acknowledges every record sent, each in an item of its own
+		assert.Equal(t, map[string]int{"AckFastBatch": 3}, toPeer.Entities[0].AckStates)
+		assert.Equal(t, "Contacts", toPeer.Entities[1].EntityPluralName)
+		assert.Equal(t, 3, toPeer.Entities[1].AddedOrUpdated)
+		assert.Equal(t, map[string]int{"AckFastBatch": 3}, toPeer.Entities[1].AckStates)
+	}
+	assert.Equal(t, "Z", summary.Directions[1].FromNodeName)
+	assert.Contains(t, summary.String(), "A -> Z: 6 of 6 queued records sent")
+	assert.Contains(t, summary.String(), "Entity 1: 3 added or updated, 0 deleted in 2 batches, AckFastBatch 3")
+
+	pairState, err := orchestrator.Local.Client.GetPairState("*pair-1")
+	assert.Nil(t, err)
+	assert.Equal(t, syncapi.SyncSessionStateInactive, pairState.State)
+
+	//What was acknowledged is not sent again
+	summary, err = orchestrator.Sync("*session-id-2")
+	if assert.Nil(t, err) && assert.Len(t, summary.Directions, 2) {
+		assert.Equal(t, 0, summary.Directions[0].TotalSent())
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestOrchestrator_SyncRetries(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	local, peer := readyAgents(1)
+	defer local.Close()
+	defer peer.Close()
+	orchestrator := newOrchestrator(local, peer)
+	orchestrator.MaxRetries = 1
+
+	summary, err := orchestrator.Sync("*session-id-1")
+	if assert.Nil(t, err) && assert.Len(t, summary.Directions, 2) {
+		assert.Equal(t, 6, summary.Directions[0].TotalSent())
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestOrchestrator_SyncResumes(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	local, peer := readyAgents(1)
+	defer local.Close()
+	defer peer.Close()
+	orchestrator := newOrchestrator(local, peer)
+
+	_, err := orchestrator.Sync("*session-id-1")
+	statusErr, ok := err.(*syncclient.StatusError)
+	if assert.True(t, ok, "expected a StatusError, got %v", err) {
+		assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+	}
+	pairState, err := orchestrator.Local.Client.GetPairState("*pair-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "*session-id-1", pairState.SessionID)
+	assert.Equal(t, syncapi.SyncSessionStateSyncing, pairState.State)
+
+	//A different session is refused while the failed one is active
+	_, err = orchestrator.Sync("*session-id-2")
+	resultErr, ok := err.(*syncclient.ResultError)
+	if assert.True(t, ok, "expected a ResultError, got %v", err) {
+		assert.Equal(t, "DifferentSessionIdAlreadyActive", resultErr.Result)
+	}
+
+	//The records reserved by the failed batch are sent again
+	summary, err := orchestrator.Sync("*session-id-1")
+	if assert.Nil(t, err) && assert.Len(t, summary.Directions, 2) {
+		assert.True(t, summary.Resumed)
+		assert.Equal(t, 6, summary.Directions[0].RecordsQueued)
+		assert.Equal(t, 6, summary.Directions[0].TotalSent())
+	}
+
+	testhelper.EndTest(testName)
+}
+
+func TestPeerSyncer_SyncPeers(t *testing.T) {
+	testName := syncutil.GetCallingName()
+	testhelper.StartTest(testName)
+	local, peer := readyAgents(0)
+	defer local.Close()
+	defer peer.Close()
+	syncer := &PeerSyncer{
+		Local:         Agent{NodeName: "A", Client: syncclient.NewClient(local.URL)},
+		PeerNodeNames: []string{"Z", "C"},
+	}
+	peerSyncServer := httptest.NewServer(syncer)
+	defer peerSyncServer.Close()
+
+	request, err := http.NewRequest("PUT", peerSyncServer.URL+"/peerSync", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	res, err := http.DefaultClient.Do(request)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var results []PeerSyncResult
+	err = json.NewDecoder(res.Body).Decode(&results)
+	if !assert.Nil(t, err) || !assert.Len(t, results, 2) {
+		return
+	}
+	//The sample pair does not say where the agent of Z is and A is not paired with C
+	assert.Equal(t, "Z", results[0].PeerNodeName)
+	assert.Equal(t, "*pair-1", results[0].Summary.PairID)
+	assert.Equal(t, ErrPeerURIMissing.Error(), results[0].Error)
+	assert.Equal(t, "C", results[1].PeerNodeName)
+	assert.Contains(t, results[1].Error, "RequestNodeAndOtherPeerExistsButNotPaired")
+
+	res, err = http.Get(peerSyncServer.URL + "/peerSync")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+	testhelper.EndTest(testName)
+}
@@ -0,0 +1,131 @@
+package syncorchestrator
+
+import (
+	"data-sync-tools-go/syncclient"
+	"data-sync-tools-go/syncutil"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/twinj/uuid"
+)
+
+//PeerSyncer syncs the node of a local agent with the agents of its peers, found at the DataMsgConsumerURI and
+//DataMsgProducerURI of the peer nodes in the configuration of their pairs. Changes are pushed to the consumer URI and
+//pulled from the producer URI, the consumer URI serving both when the producer URI is not set.
+type PeerSyncer struct {
+	//Local is the local agent, Local.NodeName its node.
+	Local Agent
+	//PeerNodeNames are the names of the peer nodes synced with.
+	PeerNodeNames []string
+	//Orchestrator gives the Limits, MaxRetries and RetryWait of every sync. Its Local and Peer are set per peer.
+	Orchestrator Orchestrator
+
+	mutex sync.Mutex
+	//failedSessionIDs has, by peer node name, the session of the last sync which failed, resumed by the next sync.
+	failedSessionIDs map[string]string
+}
+
+//PeerSyncResult reports the sync with one peer of a PeerSyncer.
+type PeerSyncResult struct {
+	PeerNodeName string  `json:"peerNodeName"`
+	Summary      Summary `json:"summary"`
+	//Error is the reason the sync failed, empty when it succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+//ErrPeerURIMissing is given when a peer node has neither a DataMsgConsumerURI nor a DataMsgProducerURI.
+var ErrPeerURIMissing = errors.New("peer node has neither a DataMsgConsumerURI nor a DataMsgProducerURI")
+
+//SyncPeers syncs the local node with every peer in turn, one sync failing not keeping the others from running. Syncs
+//are not run concurrently: a SyncPeers called while another runs waits for it.
+func (syncer *PeerSyncer) SyncPeers() []PeerSyncResult {
+	syncer.mutex.Lock()
+	defer syncer.mutex.Unlock()
+	if syncer.failedSessionIDs == nil {
+		syncer.failedSessionIDs = map[string]string{}
+	}
+	answer := []PeerSyncResult{}
+	for _, peerNodeName := range syncer.PeerNodeNames {
+		result := PeerSyncResult{PeerNodeName: peerNodeName}
+		summary, err := syncer.syncPeer(peerNodeName)
+		result.Summary = summary
+		if err != nil {
+			syncutil.Error(err, ". Error syncing", syncer.Local.NodeName, "with peer", peerNodeName)
+			result.Error = err.Error()
+		}
+		answer = append(answer, result)
+	}
+	return answer
+}
+
+func (syncer *PeerSyncer) syncPeer(peerNodeName string) (Summary, error) {
+	orchestrator := syncer.Orchestrator
+	orchestrator.Local = syncer.Local
+	config, err := syncer.Local.Client.GetSyncConfig(syncer.Local.NodeName, peerNodeName)
+	if err != nil {
+		return Summary{}, err
+	}
+	orchestrator.Peer = Agent{NodeName: peerNodeName}
+	consumerURI, producerURI := config.Node2.DataMsgConsumerURI, config.Node2.DataMsgProducerURI
+	switch {
+	case consumerURI == "" && producerURI == "":
+		return Summary{PairID: config.PairID}, ErrPeerURIMissing
+	case consumerURI == "":
+		orchestrator.Peer.Client = syncclient.NewClient(producerURI)
+	default:
+		orchestrator.Peer.Client = syncclient.NewClient(consumerURI)
+		if producerURI != "" && producerURI != consumerURI {
+			orchestrator.Peer.ProducerClient = syncclient.NewClient(producerURI)
+		}
+	}
+	sessionID, resuming := syncer.failedSessionIDs[peerNodeName]
+	if !resuming {
+		sessionID = uuid.Formatter(uuid.NewV4(), uuid.FormatCanonical)
+	}
+	summary, err := orchestrator.Sync(sessionID)
+	if err != nil {
+		syncer.failedSessionIDs[peerNodeName] = sessionID
+		return summary, err
+	}
+	delete(syncer.failedSessionIDs, peerNodeName)
+	return summary, nil
+}
+
+//StartPeerSync syncs with the peers every interval in the background until the returned function is called.
+func (syncer *PeerSyncer) StartPeerSync(interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				syncer.SyncPeers()
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+	}
+}
+
+//ServeHTTP syncs with the peers on demand, answering with the PeerSyncResult of every peer. Only PUT is allowed.
+//	curl -i --request PUT http://localhost:8080/peerSync
+func (syncer *PeerSyncer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		w.Header().Set("Allow", http.MethodPut)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	answer := syncer.SyncPeers()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	err := json.NewEncoder(w).Encode(answer)
+	if err != nil {
+		syncutil.NotImplementedMsg(err.Error())
+	}
+}
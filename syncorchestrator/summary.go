@@ -0,0 +1,124 @@
+package syncorchestrator
+
+import (
+	"bytes"
+	"data-sync-tools-go/syncmsg"
+	"fmt"
+	"sort"
+)
+
+//Summary reports a Sync of the pair PairID.
+type Summary struct {
+	PairID    string `json:"pairId"`
+	SessionID string `json:"sessionId"`
+	//Resumed tells whether a session left active by a failed Sync was canceled before syncing.
+	Resumed bool `json:"resumed"`
+	//Directions has the changes sent from the local node to its peer then from the peer to the local node.
+	Directions []DirectionSummary `json:"directions"`
+}
+
+//DirectionSummary reports the changes sent from one node of the pair to the other.
+type DirectionSummary struct {
+	FromNodeName  string `json:"fromNodeName"`
+	ToNodeName    string `json:"toNodeName"`
+	RecordsQueued int    `json:"recordsQueued"`
+	//Entities has the entities records were sent for, in the order they were first sent.
+	Entities []EntitySummary `json:"entities"`
+}
+
+//EntitySummary reports the records of one entity sent in one direction. AckStates counts the records by the name of
+//the syncmsg.AckSyncStateEnum the receiving node processed them with, such as 'AckFastBatch'.
+type EntitySummary struct {
+	EntityPluralName string         `json:"entityPluralName"`
+	AddedOrUpdated   int            `json:"addedOrUpdated"`
+	Deleted          int            `json:"deleted"`
+	Batches          int            `json:"batches"`
+	AckStates        map[string]int `json:"ackStates"`
+}
+
+//TotalSent gives the number of records sent in both directions.
+func (summary Summary) TotalSent() int {
+	answer := 0
+	for _, direction := range summary.Directions {
+		answer += direction.TotalSent()
+	}
+	return answer
+}
+
+//TotalSent gives the number of records sent in the direction.
+func (direction DirectionSummary) TotalSent() int {
+	answer := 0
+	for _, entity := range direction.Entities {
+		answer += entity.AddedOrUpdated + entity.Deleted
+	}
+	return answer
+}
+
+//add counts a batch sent as request and processed with the results of response.
+func (direction *DirectionSummary) add(request *syncmsg.ProtoSyncEntityMessageRequest, response *syncmsg.ProtoSyncEntityMessageResponse) {
+	ackStates := map[string]map[string]int{}
+	//A fast batch responds with an item per record, so the items of an entity are counted together
+	for _, item := range response.GetItems() {
+		counts, found := ackStates[item.GetEntityPluralName()]
+		if !found {
+			counts = map[string]int{}
+			ackStates[item.GetEntityPluralName()] = counts
+		}
+		for _, msg := range item.Msgs {
+			counts[msg.GetSyncState().String()]++
+		}
+	}
+	for _, item := range request.GetItems() {
+		if len(item.Msgs) == 0 {
+			continue
+		}
+		entity := direction.entity(item.GetEntityPluralName())
+		if request.GetIsDelete() {
+			entity.Deleted += len(item.Msgs)
+		} else {
+			entity.AddedOrUpdated += len(item.Msgs)
+		}
+		entity.Batches++
+		for state, count := range ackStates[item.GetEntityPluralName()] {
+			entity.AckStates[state] += count
+		}
+	}
+}
+
+func (direction *DirectionSummary) entity(entityPluralName string) *EntitySummary {
+	for i := range direction.Entities {
+		if direction.Entities[i].EntityPluralName == entityPluralName {
+			return &direction.Entities[i]
+		}
+	}
+	direction.Entities = append(direction.Entities, EntitySummary{EntityPluralName: entityPluralName, AckStates: map[string]int{}})
+	return &direction.Entities[len(direction.Entities)-1]
+}
+
+//String reports the summary one line per direction and entity.
+func (summary Summary) String() string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "Pair '%s' session '%s'", summary.PairID, summary.SessionID)
+	if summary.Resumed {
+		buffer.WriteString(" (resumed)")
+	}
+	fmt.Fprintf(&buffer, ": %d records sent\n", summary.TotalSent())
+	for _, direction := range summary.Directions {
+		fmt.Fprintf(&buffer, "  %s -> %s: %d of %d queued records sent\n", direction.FromNodeName, direction.ToNodeName,
+			direction.TotalSent(), direction.RecordsQueued)
+		for _, entity := range direction.Entities {
+			fmt.Fprintf(&buffer, "    %s: %d added or updated, %d deleted in %d batches", entity.EntityPluralName,
+				entity.AddedOrUpdated, entity.Deleted, entity.Batches)
+			states := make([]string, 0, len(entity.AckStates))
+			for state := range entity.AckStates {
+				states = append(states, state)
+			}
+			sort.Strings(states)
+			for _, state := range states {
+				fmt.Fprintf(&buffer, ", %s %d", state, entity.AckStates[state])
+			}
+			buffer.WriteString("\n")
+		}
+	}
+	return buffer.String()
+}
@@ -29,6 +29,8 @@ var (
 	TestDbHost = "localhost"
 	//TestDbPort is the default database port for testing
 	TestDbPort = 5432
+	//TestDbDialect is the sql dialect of the database for testing, either 'postgressql' or 'sqlite'.
+	TestDbDialect = "postgressql"
 )
 
 //RemoveSyncTablesIfNeeded removes the existing data and tables from the sync model (tables starting with sync_*)
@@ -55,7 +57,7 @@ func RemoveSyncTablesIfNeeded(db *sql.DB) {
 //	sync_pair                 5       A<->Z, B<->Z, A<->B (Partial), C<->Z(Dup 1 of 2), C<->Z(Dup 2 of 2)
 //	sync_pair_nodes           9       1 orphaned node associated with a record
 func AddProfile1SampleSyncData(db *sql.DB) {
-	_, err := db.Exec(profile1SQL)
+	_, err := db.Exec(dialectSQL(profile1SQL))
 	if err != nil {
 		msg := "Error getting results from database. Error:%s"
 		syncutil.Error(msg, err.Error())
@@ -78,7 +80,7 @@ func AddProfile1SampleSyncData(db *sql.DB) {
 //	sync_pair_nodes           9       1 orphaned node associated with a record                              Yes
 func AddProfile2SampleSyncData(db *sql.DB) {
 	AddProfile1SampleSyncData(db)
-	_, err := db.Exec(profile2SQL)
+	_, err := db.Exec(dialectSQL(profile2SQL))
 	if err != nil {
 		msg := "Error getting results from database. Error:%s"
 		syncutil.Error(msg, err.Error())
@@ -101,7 +103,7 @@ func AddProfile2SampleSyncData(db *sql.DB) {
 //	sync_pair_nodes           9       1 orphaned node associated with a record                              Yes  -
 func AddProfile3SampleSyncData(db *sql.DB) {
 	AddProfile2SampleSyncData(db)
-	_, err := db.Exec(profile3SQL)
+	_, err := db.Exec(dialectSQL(profile3SQL))
 	if err != nil {
 		msg := "Error creating profile 3 data. Error:"
 		syncutil.Error(msg, err.Error())
@@ -124,7 +126,7 @@ func AddProfile3SampleSyncData(db *sql.DB) {
 //	sync_pair_nodes           9       1 orphaned node associated with a record                              Yes
 func AddProfile4SampleSyncData(db *sql.DB) {
 	AddProfile1SampleSyncData(db)
-	_, err := db.Exec(profile4SQL)
+	_, err := db.Exec(dialectSQL(profile4SQL))
 	if err != nil {
 		msg := "Error getting results from database. Error:%s"
 		syncutil.Error(msg, err.Error())
@@ -147,7 +149,7 @@ func AddProfile4SampleSyncData(db *sql.DB) {
 //	sync_pair_nodes           9       1 orphaned node associated with a record                              Yes  -
 func AddProfile5SampleSyncData(db *sql.DB) {
 	AddProfile2SampleSyncData(db)
-	_, err := db.Exec(profile5SQL)
+	_, err := db.Exec(dialectSQL(profile5SQL))
 	if err != nil {
 		msg := "Error creating profile 5 data. Error:"
 		syncutil.Error(msg, err.Error())
@@ -158,7 +160,11 @@ func AddProfile5SampleSyncData(db *sql.DB) {
 
 //CreateSyncTables adds the sync model (tables starting with sync_*) to the given database connection.
 func CreateSyncTables(db *sql.DB) {
-	_, err := db.Exec(createSyncModelTablesSQL)
+	createSQL := createSyncModelTablesSQL
+	if TestDbDialect == "sqlite" {
+		createSQL = createSQLiteSyncModelTablesSQL
+	}
+	_, err := db.Exec(createSQL)
 	if err != nil {
 		msg := "Error getting results from database. Error:%s"
 		syncutil.Error(msg, err.Error())
@@ -282,7 +288,7 @@ CREATE TABLE contacts (
 
 	for _, sqlStr := range strs {
 		//log.Printf("Running sql for index [%v]", index)
-		_, err := db.Exec(sqlStr)
+		_, err := db.Exec(dialectSQL(sqlStr))
 		if err != nil {
 			msg := "Error getting results from database. Error:%s"
 			syncutil.Fatal(msg, err.Error())
@@ -722,6 +728,7 @@ RecordHash					varchar(100)	NOT NULL,
 RecordData					bytea 				NOT NULL,
 RecordBytesSize			int						NOT NULL,
 IsDelete						boolean				NOT NULL 	default('false'),
+DeletedDate					timestamp			NULL, --when IsDelete was last set, used for tombstone retention
 GroupName						varchar(256)	NULL, --RESERVED: Not currently in use
 GroupType						varchar(256)	NULL, --RESERVED: Not currently in use
 GroupSeqNum					int						NULL, --RESERVED: Not currently in use
@@ -799,6 +806,7 @@ SyncMsgSecPol			varchar(36) 	NOT NULL 	default('none'),
 SyncSessionId			varchar(36)		NULL,
 SyncSessionState	varchar(36)		NOT NULL	default('Inactive'),
 SyncSessionStart	timestamp 		NULL,
+SyncSessionHeartbeat	timestamp	NULL, -- renews the lease of the session
 SyncConflictUri	  varchar(2048) 	NOT NULL 	default('none'),
 RecordCreated			timestamp		NOT NULL	default(now()),
 UNIQUE(PairName, SyncSessionId),
@@ -824,6 +832,64 @@ RecordCreated			timestamp		NOT NULL	default(now()),
 PRIMARY KEY (PairId, NodeId, TargetNodeId)
 );
 
+--9:
+CREATE TABLE sync_state_ancestor (
+EntitySingularName	varchar(50)		NOT NULL,
+RecordId						varchar(112)	NOT NULL,
+RecordHash					varchar(100)	NOT NULL,
+RecordData					bytea 				NOT NULL,
+RecordCreated				timestamp			NOT NULL	default(now()),
+PRIMARY KEY (EntitySingularName, RecordId, RecordHash)
+);
+
+--10:
+CREATE TABLE sync_conflict (
+ConflictId					varchar(36)		NOT NULL,
+NodeId							varchar(36)		NOT NULL,
+SessionId						varchar(36)		NULL,
+EntitySingularName	varchar(50)		NOT NULL,
+EntityPluralName		varchar(50)		NOT NULL,
+RecordId						varchar(112)	NOT NULL,
+SyncState						integer				NOT NULL,
+ConflictingFields		varchar(2048)	NULL,
+AncestorRecordData	bytea					NULL,
+LocalRecordHash			varchar(100)	NOT NULL,
+LocalIsDelete				boolean				NOT NULL	default('false'),
+LocalRecordData			bytea					NULL,
+RemoteRecordHash		varchar(100)	NOT NULL,
+RemoteIsDelete			boolean				NOT NULL	default('false'),
+RemoteRecordData		bytea					NULL,
+RecordCreated				timestamp			NOT NULL	default(now()),
+UNIQUE(NodeId, EntitySingularName, RecordId),
+PRIMARY KEY (ConflictId)
+);
+
+--11:
+CREATE TABLE sync_session_history (
+PairId						varchar(36)		NOT NULL,
+SyncSessionId			varchar(36)		NOT NULL,
+SyncSessionState	varchar(36)		NOT NULL, -- the state the session was in when it was ended
+SyncSessionStart	timestamp			NULL,
+SyncSessionEnd		timestamp			NOT NULL	default(now()),
+EndReason					varchar(36)		NOT NULL, -- 'Expired'
+EndReasonMsg			varchar(1024)	NULL,
+ReleasedCount			int						NOT NULL	default(0),
+PRIMARY KEY (PairId, SyncSessionId)
+);
+
+--12:
+CREATE TABLE sync_session_mgmt_msg (
+MsgSeq						bigserial			NOT NULL,
+PairId						varchar(36)		NOT NULL,
+SyncSessionId			varchar(36)		NOT NULL,
+SenderNodeId			varchar(36)		NOT NULL,
+MessageType				integer				NOT NULL, -- 1-Heartbeat, 2-SeedingClosed, 3-Cancel, 4-HaultingError, 5-Start
+Result						varchar(1024)	NULL,
+RecordCreated			timestamp			NOT NULL	default(now()),
+PRIMARY KEY (MsgSeq),
+CONSTRAINT valid_message_type CHECK (MessageType >= 1 AND MessageType <= 5)
+);
+
 --GRANT SELECT, INSERT, UPDATE, DELETE ON sync_pair_nodes TO doug;
 --GRANT SELECT, INSERT, UPDATE, DELETE ON sync_pair TO doug;
 --GRANT SELECT, INSERT, UPDATE, DELETE ON sync_node TO doug;
@@ -868,6 +934,29 @@ sync_peer_state 	>---*:1--- sync_state
 ALTER TABLE sync_peer_state ADD CONSTRAINT FK_sync_peer_state_sync_state
 FOREIGN KEY(EntitySingularName, RecordId) REFERENCES sync_state (EntitySingularName, RecordId);
 
+/*
+sync_conflict 		>---*:1--- sync_peer_state
+|--	NodeId			>------- NodeId
+|--	EntityId		>------- EntityId
+|--	RecordId  		>------- RecordId
+*/
+ALTER TABLE sync_conflict ADD CONSTRAINT FK_sync_conflict_sync_peer_state
+FOREIGN KEY(NodeId, EntitySingularName, RecordId) REFERENCES sync_peer_state (NodeId, EntitySingularName, RecordId);
+
+/*
+sync_session_history	>---*:1--- sync_pair
+|--	PairId				>------- PairId
+*/
+ALTER TABLE sync_session_history ADD CONSTRAINT FK_sync_session_history_sync_pair
+FOREIGN KEY(PairId) REFERENCES sync_pair (PairId);
+
+/*
+sync_session_mgmt_msg	>---*:1--- sync_pair
+|--	PairId				>------- PairId
+*/
+ALTER TABLE sync_session_mgmt_msg ADD CONSTRAINT FK_sync_session_mgmt_msg_sync_pair
+FOREIGN KEY(PairId) REFERENCES sync_pair (PairId);
+
 /*
 sync_node			>---*:1--- sync_data_version
 |--	DataVersionId	>------- DataVersionId
@@ -912,6 +1001,10 @@ FOREIGN KEY(DataVersionName) REFERENCES sync_data_version (DataVersionName);
 `
 
 var dropSyncModelTablesSQL = `
+drop table if exists sync_session_mgmt_msg;
+drop table if exists sync_session_history;
+drop table if exists sync_conflict;
+drop table if exists sync_state_ancestor;
 drop table sync_pair_nodes;
 drop table sync_pair;
 drop table sync_data_field;
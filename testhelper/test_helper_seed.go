@@ -0,0 +1,234 @@
+package testhelper
+
+import (
+	"data-sync-tools-go/syncdao"
+	"data-sync-tools-go/syncmsg"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+)
+
+//SyncModelSeeder fills a sync model that is not backed by sql, such as syncdaomem.MemoryDaosFactory, with sample data.
+type SyncModelSeeder interface {
+	Reset()
+	AddDataVersion(dataVersionName string) error
+	AddEntity(dataVersionName string, entity syncdao.EntityPairItem) error
+	AddField(dataVersionName string, entitySingularName string, field syncdao.SyncFieldDefinition) error
+	AddNode(node syncdao.SyncNode) error
+	AddPair(pair syncdao.SyncPair) error
+	AddPairNode(pairID string, nodeID string, targetNodeID string, seededDataVersion string, syncConflictURI string) error
+	AddSyncState(entitySingularName string, recordID string, recordHash string, recordData []byte, isDelete bool) error
+	AddPeerState(nodeID string, entitySingularName string, recordID string, sentLastKnownHash string, peerLastKnownHash string, sentSyncState syncmsg.SentSyncStateEnum, changedByClient bool) error
+}
+
+//SetupSeededData is SetupData for a SyncModelSeeder. It replaces the sync model with the data of profile3 or
+//profile5, or of profile2 for any other profile, along with the 'Contact' records of SetupData. Since there are no
+//custom tables, the contacts are only held as sync state. The seeded only profile "profile3records" is profile3 with
+//'Entity 1' records holding marshalled syncmsg.ProtoRecord data, so that they can be processed by a peer.
+func SetupSeededData(seeder SyncModelSeeder, profile string) error {
+	seeder.Reset()
+	err := addProfile2SeededData(seeder)
+	if err != nil {
+		return err
+	}
+	profile = strings.ToLower(profile)
+	switch profile {
+	case "profile3", "profile5":
+		err = addProfile3SeededData(seeder, profile == "profile5")
+	case "profile3records":
+		err = addProfile3RecordsSeededData(seeder)
+	}
+	if err != nil {
+		return err
+	}
+	return addContactSeededData(seeder)
+}
+
+//SetupSeededModel replaces the sync model with the data versions, nodes, pairs, entities and fields of profile2,
+//without any record, as held by a node which never synced.
+func SetupSeededModel(seeder SyncModelSeeder) error {
+	seeder.Reset()
+	return addProfile2SeededData(seeder)
+}
+
+//addProfile2SeededData adds the data of AddProfile2SampleSyncData.
+func addProfile2SeededData(seeder SyncModelSeeder) error {
+	for _, dataVersionName := range []string{"Demo Model 1", "Demo Model 2 (orphand node)"} {
+		err := seeder.AddDataVersion(dataVersionName)
+		if err != nil {
+			return err
+		}
+	}
+	nodes := []syncdao.SyncNode{
+		{NodeID: "*node-spoke1", NodeName: "A", DataVersionName: "Demo Model 1"},
+		{NodeID: "*node-spoke2", NodeName: "B", DataVersionName: "Demo Model 2 (orphand node)"},
+		{NodeID: "*node-spoke3", NodeName: "C", DataVersionName: "Demo Model 1"},
+		{NodeID: "*node-hub", NodeName: "Z", DataVersionName: "Demo Model 1"},
+	}
+	for _, node := range nodes {
+		err := seeder.AddNode(node)
+		if err != nil {
+			return err
+		}
+	}
+	pairs := []syncdao.SyncPair{
+		{PairID: "*pair-1", PairName: "A <-> Z"},
+		{PairID: "*pair-2", PairName: "B <-> Z"},
+		{PairID: "*pair-3", PairName: "A <-> B (Partial)"},
+		{PairID: "*pair-4", PairName: "C <-> Z (Dup 1 of 2)"},
+		{PairID: "*pair-5", PairName: "C <-> Z (Dup 2 of 2)"},
+	}
+	for _, pair := range pairs {
+		err := seeder.AddPair(pair)
+		if err != nil {
+			return err
+		}
+	}
+	pairNodes := [][]string{
+		{"*pair-1", "*node-hub", "*node-spoke1"},
+		{"*pair-1", "*node-spoke1", "*node-hub"},
+		{"*pair-2", "*node-hub", "*node-spoke2"},
+		{"*pair-2", "*node-spoke2", "*node-hub"},
+		{"*pair-3", "*node-spoke2", "*node-spoke1"},
+		{"*pair-4", "*node-hub", "*node-spoke3"},
+		{"*pair-4", "*node-spoke3", "*node-hub"},
+		{"*pair-5", "*node-hub", "*node-spoke3"},
+		{"*pair-5", "*node-spoke3", "*node-hub"},
+	}
+	for _, pairNode := range pairNodes {
+		err := seeder.AddPairNode(pairNode[0], pairNode[1], pairNode[2], "Demo Model 1", "none")
+		if err != nil {
+			return err
+		}
+	}
+	entities := []syncdao.EntityPairItem{
+		{EntitySingularName: "Entity 1", EntityPluralName: "Entity 1", ProcessOrderAddUpdate: 1, ProcessOrderDelete: 4, EntityHandlerURI: "none"},
+		{EntitySingularName: "Entity 2", EntityPluralName: "Entity 2", ProcessOrderAddUpdate: 2, ProcessOrderDelete: 3, EntityHandlerURI: "none"},
+		{EntitySingularName: "Entity 3", EntityPluralName: "Entity 3", ProcessOrderAddUpdate: 2, ProcessOrderDelete: 3, EntityHandlerURI: "none"},
+		{EntitySingularName: "Entity 4", EntityPluralName: "Entity 4", ProcessOrderAddUpdate: 3, ProcessOrderDelete: 2, EntityHandlerURI: "none"},
+		{EntitySingularName: "Entity 5", EntityPluralName: "Entity 5", ProcessOrderAddUpdate: 4, ProcessOrderDelete: 1, EntityHandlerURI: "none"},
+		{EntitySingularName: "Contact", EntityPluralName: "Contacts", ProcessOrderAddUpdate: 4, ProcessOrderDelete: 1, EntityHandlerURI: "none"},
+	}
+	for _, entity := range entities {
+		err := seeder.AddEntity("Demo Model 1", entity)
+		if err != nil {
+			return err
+		}
+	}
+	fields := map[string][]syncdao.SyncFieldDefinition{
+		"Entity 1": {
+			{FieldName: "FirstName", FieldType: syncdao.SyncFieldTypeEnumString},
+			{FieldName: "LastName", FieldType: syncdao.SyncFieldTypeEnumString},
+		},
+		"Contact": {
+			{FieldName: "contactId", FieldType: syncdao.SyncFieldTypeEnumString, IsPrimaryKey: true},
+			{FieldName: "dateOfBirth", FieldType: syncdao.SyncFieldTypeEnumDate},
+			{FieldName: "firstName", FieldType: syncdao.SyncFieldTypeEnumString},
+			{FieldName: "heightFt", FieldType: syncdao.SyncFieldTypeEnumInt},
+			{FieldName: "heightInch", FieldType: syncdao.SyncFieldTypeEnumFloat},
+			{FieldName: "lastName", FieldType: syncdao.SyncFieldTypeEnumString},
+			{FieldName: "preferredHeight", FieldType: syncdao.SyncFieldTypeEnumInt},
+		},
+	}
+	for entitySingularName, entityFields := range fields {
+		for _, field := range entityFields {
+			err := seeder.AddField("Demo Model 1", entitySingularName, field)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//addProfile3SeededData adds the records of profile3SQL, queued for the hub. With withSpoke1 the records are also
+//known to spoke1 as in profile5SQL.
+func addProfile3SeededData(seeder SyncModelSeeder, withSpoke1 bool) error {
+	records := [][]string{
+		{"*record-1", "hash-1"},
+		{"*record-2", "hash-2"},
+		{"*record-3", "hash-3"},
+	}
+	for _, record := range records {
+		err := seeder.AddSyncState("Entity 1", record[0], record[1], []byte("<record data>"), false)
+		if err != nil {
+			return err
+		}
+		err = seeder.AddPeerState("*node-hub", "Entity 1", record[0], "", "", syncmsg.SentSyncStateEnum_PersistedNeverSentToPeer, true)
+		if err != nil {
+			return err
+		}
+		if withSpoke1 {
+			err = seeder.AddPeerState("*node-spoke1", "Entity 1", record[0], "", "", syncmsg.SentSyncStateEnum_PersistedNeverSentToPeer, false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//addProfile3RecordsSeededData adds the records of addProfile3SeededData, queued for the hub, as marshalled
+//syncmsg.ProtoRecords of the 'FirstName' and 'LastName' fields of 'Entity 1'.
+func addProfile3RecordsSeededData(seeder SyncModelSeeder) error {
+	creator := syncmsg.NewCreator()
+	records := [][]string{
+		{"*record-1", "Ann", "Archer"},
+		{"*record-2", "Bob", "Baker"},
+		{"*record-3", "Cid", "Carter"},
+	}
+	for _, record := range records {
+		recordBytes, err := proto.Marshal(&syncmsg.ProtoRecord{
+			Fields: []*syncmsg.ProtoField{
+				creator.CreateStringProtoField("FirstName", record[1]),
+				creator.CreateStringProtoField("LastName", record[2]),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		err = seeder.AddSyncState("Entity 1", record[0], Hash256Bytes(recordBytes), recordBytes, false)
+		if err != nil {
+			return err
+		}
+		err = seeder.AddPeerState("*node-hub", "Entity 1", record[0], "", "", syncmsg.SentSyncStateEnum_PersistedNeverSentToPeer, true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//addContactSeededData adds the 'Contact' records of SetupData.
+func addContactSeededData(seeder SyncModelSeeder) error {
+	creator := syncmsg.NewCreator()
+	contactSyncPackage1, err := CreateRecordAndSupport(Contact{"B6581A36-804D-45AC-B2E2-F6DA265AF7DE", creator.FormatTimeFromString("1990-04-29 00:00:00.000"), "Jack", 6, 1.0, "Smith", 1}, false, syncmsg.SentSyncStateEnum_PersistedNeverSentToPeer, "")
+	if err != nil {
+		return err
+	}
+	previousContactSyncPackage2, err := CreateRecordAndSupport(Contact{"911DD745-8916-41C4-9973-F8B38A501602", creator.FormatTimeFromString("1994-07-10 00:00:00.000"), "Henry", 5, 5.5, "Adins", 2}, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, "")
+	if err != nil {
+		return err
+	}
+	contactSyncPackage2, err := CreateRecordAndSupport(Contact{"911DD745-8916-41C4-9973-F8B38A501602", creator.FormatTimeFromString("1994-07-10 00:00:00.000"), "Henry", 5, 5.5, "Adkins", 2}, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, previousContactSyncPackage2.RecordSha256Hex)
+	if err != nil {
+		return err
+	}
+	previousContactSyncPackage4, err := CreateRecordAndSupport(Contact{"0934A378-DEDB-4207-B99C-DD0D61DC59BC", creator.FormatTimeFromString("1987-05-28 00:00:00.000"), "Mindy", 5, 1.0, "Johnson", 2}, false, syncmsg.SentSyncStateEnum_PersistedStandardSentToPeer, "")
+	if err != nil {
+		return err
+	}
+	for _, contactSyncPackage := range []ContactSyncPackage{contactSyncPackage1, contactSyncPackage2, previousContactSyncPackage4} {
+		err = seeder.AddSyncState("Contact", contactSyncPackage.Contact.ContactID, contactSyncPackage.RecordSha256Hex, contactSyncPackage.RecordBytes, false)
+		if err != nil {
+			return err
+		}
+		if contactSyncPackage.SentState.IsNotFirstTimeSend() {
+			err = seeder.AddPeerState("*node-spoke1", "Contact", contactSyncPackage.Contact.ContactID, contactSyncPackage.PeerLastKnownHash, contactSyncPackage.PeerLastKnownHash, contactSyncPackage.SentState, contactSyncPackage.ChangedByClient)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package testhelper
+
+import (
+	"strings"
+)
+
+//sqliteBooleanReplacer turns the quoted boolean literals of the sample data into sqlite's true and false, as sqlite
+//would otherwise keep 'true' and 'false' as text.
+var sqliteBooleanReplacer = strings.NewReplacer("'true'", "true", "'false'", "false")
+
+//dialectSQL adapts the sample data sql, written for postgressql, to TestDbDialect.
+func dialectSQL(sqlStr string) string {
+	if TestDbDialect == "sqlite" {
+		return sqliteBooleanReplacer.Replace(sqlStr)
+	}
+	return sqlStr
+}
+
+//createSQLiteSyncModelTablesSQL is createSyncModelTablesSQL for sqlite, which declares foreign keys with the table as
+//it cannot add them later.
+var createSQLiteSyncModelTablesSQL = `
+--1:
+CREATE TABLE sync_data_version (
+DataVersionName		varchar(36)	NOT NULL,
+RecordCreated			timestamp		NOT NULL	default(CURRENT_TIMESTAMP),
+PRIMARY KEY (DataVersionName)
+);
+
+--2:
+CREATE TABLE sync_data_entity (
+EntitySingularName	varchar(50)		NOT NULL,
+EntityPluralName		varchar(50)		NOT NULL,
+DataVersionName			varchar(36)		NOT NULL,
+ProcOrderAddUpdate	integer				NOT NULL,
+ProcOrderDelete			integer				NOT NULL,
+EntityHandlerUri		varchar(2048) NULL,
+RecordCreated				timestamp			NOT NULL	default(CURRENT_TIMESTAMP),
+PRIMARY KEY (EntitySingularName),
+CONSTRAINT FK_sync_data_entity_sync_data_version FOREIGN KEY(DataVersionName) REFERENCES sync_data_version (DataVersionName)
+);
+
+--3:
+CREATE TABLE sync_state (
+EntitySingularName	varchar(50)		NOT NULL,
+RecordId						varchar(112)	NOT NULL,
+DataVersionName			varchar(36)		NOT NULL,
+RecordHash					varchar(100)	NOT NULL,
+RecordData					blob	 				NOT NULL,
+RecordBytesSize			int						NOT NULL,
+IsDelete						boolean				NOT NULL 	default(false),
+DeletedDate					timestamp			NULL, --when IsDelete was last set, used for tombstone retention
+GroupName						varchar(256)	NULL, --RESERVED: Not currently in use
+GroupType						varchar(256)	NULL, --RESERVED: Not currently in use
+GroupSeqNum					int						NULL, --RESERVED: Not currently in use
+RecordCreated				timestamp			NOT NULL	default(CURRENT_TIMESTAMP),
+PRIMARY KEY (EntitySingularName, RecordId),
+CONSTRAINT FK_sync_state_sync_entity FOREIGN KEY(EntitySingularName) REFERENCES sync_data_entity (EntitySingularName),
+CONSTRAINT FK_sync_state_sync_data_version FOREIGN KEY(DataVersionName) REFERENCES sync_data_version (DataVersionName)
+);
+
+--4:
+CREATE TABLE sync_node(
+NodeId							varchar(36) 	NOT NULL,
+NodeName						varchar(256) 	NOT NULL,
+DataVersionName			varchar(36)		NOT NULL,
+Enabled							boolean 			NOT NULL	default(true),
+DataMsgConsUri			varchar(2048) NULL,
+DataMsgProdUri			varchar(2048) NULL,
+MgmtMsgConsUri			varchar(2048) NULL,
+MgmtMsgProdUri			varchar(2048) NULL,
+SyncDataPersistForm	varchar(36) 	NULL,
+InMsgBatchSize			int 					NULL 			default(100),
+MaxOutMsgBatchSize  int 					NULL 			default(200),
+InChanDepthSize			int 					NULL 			default(16),
+MaxOutChanDepthSize int 					NULL 			default(16),
+RecordCreated				timestamp			NOT NULL	default(CURRENT_TIMESTAMP),
+UNIQUE(NodeName),
+PRIMARY KEY (NodeId),
+CONSTRAINT FK_sync_node_sync_data_version FOREIGN KEY(DataVersionName) REFERENCES sync_data_version (DataVersionName)
+);
+
+--5:
+CREATE TABLE sync_peer_state (
+NodeId										varchar(36)		NOT NULL,
+EntitySingularName				varchar(50)		NOT NULL,
+RecordId									varchar(112)	NOT NULL,
+SessionBindId							varchar(36)		NULL,
+TransactionBindReceiveId	varchar(36)		NULL,
+TransactionBindSendId			varchar(36)		NULL,
+QueueBindSendId						varchar(36)		NULL,
+SentLastKnownHash					varchar(100)	NULL,
+PeerLastKnownHash					varchar(100)	NULL,
+IsConflict 								boolean				NOT NULL 	default(false),
+IsDelete 									boolean				NOT NULL 	default(false),
+SentSyncState							integer 			NOT NULL 	default(1),
+-- 1-never sent to peer (default on add record), 2-first time sent to peer, 3-standard sent to peer,
+-- 4-fast delete
+RecordBytesSize						int						NOT NULL,
+ChangedByClient						boolean				NOT NULL 	default(false),
+LastUpdated								date					NULL,
+RecordCreated							timestamp			NOT NULL	default(CURRENT_TIMESTAMP),
+PRIMARY KEY (NodeId, EntitySingularName, RecordId),
+CONSTRAINT valid_sent_sync_state CHECK (SentSyncState >= 1 AND SentSyncState <= 4),
+CONSTRAINT FK_sync_peer_state_sync_node FOREIGN KEY(NodeId) REFERENCES sync_node (NodeId),
+CONSTRAINT FK_sync_peer_state_sync_state FOREIGN KEY(EntitySingularName, RecordId) REFERENCES sync_state (EntitySingularName, RecordId)
+);
+
+--6:
+CREATE TABLE sync_data_field (
+EntitySingularName	varchar(50)		NOT NULL,
+FieldName						varchar(100)	NOT NULL, -- this is a canonical name regardless of local naming convensions using camel case starting with Upper Case
+DataVersionName			varchar(36) 	NOT NULL,
+DataTypeName				varchar(6)		NOT NULL,
+IsPrimaryKey				boolean			NOT NULL,
+RecordCreated				timestamp		NOT NULL	default(CURRENT_TIMESTAMP),
+PRIMARY KEY (EntitySingularName, FieldName),
+CONSTRAINT valid_date_type_name CHECK (DataTypeName = 'String' OR DataTypeName = 'Date' OR
+										DataTypeName = 'Bool'	   OR DataTypeName = 'Int'  OR
+										DataTypeName = 'Float'   OR DataTypeName = 'Binary'),
+CONSTRAINT FK_sync_data_field_sync_data_entity FOREIGN KEY(EntitySingularName) REFERENCES sync_data_entity (EntitySingularName),
+CONSTRAINT FK_sync_data_field_sync_data_version FOREIGN KEY(DataVersionName) REFERENCES sync_data_version (DataVersionName)
+);
+
+--7:
+CREATE TABLE sync_pair(
+PairId						varchar(36) 	NOT NULL,
+PairName					varchar(72) 	NOT NULL,
+MaxSesDurValue		int 			NULL 		default(10),
+MaxSesDurUnit			varchar(36) 	NOT NULL 	default('minutes'),
+SyncDataTransForm	varchar(36) 	NOT NULL 	default('json:V1'),
+SyncMsgTransForm	varchar(36) 	NOT NULL 	default('json:V1'),
+SyncMsgSecPol			varchar(36) 	NOT NULL 	default('none'),
+SyncSessionId			varchar(36)		NULL,
+SyncSessionState	varchar(36)		NOT NULL	default('Inactive'),
+SyncSessionStart	timestamp 		NULL,
+SyncSessionHeartbeat	timestamp	NULL, -- renews the lease of the session
+SyncConflictUri	  varchar(2048) 	NOT NULL 	default('none'),
+RecordCreated			timestamp		NOT NULL	default(CURRENT_TIMESTAMP),
+UNIQUE(PairName, SyncSessionId),
+PRIMARY KEY (PairId),
+CONSTRAINT valid_session_state CHECK (SyncSessionState = 'Inactive' OR SyncSessionState = 'Initializing' OR
+										SyncSessionState = 'Seeding'  OR SyncSessionState = 'Queuing'      OR
+										SyncSessionState = 'Syncing'  OR SyncSessionState = 'Canceling')
+);
+
+--8:
+CREATE TABLE sync_pair_nodes(
+PairId						varchar(36) 	NOT NULL,
+NodeId						varchar(36) 	NOT NULL,
+TargetNodeId			varchar(36) 	NOT NULL,
+LastSeededDate		timestamp		NULL,
+SeededDataVersion	varchar(36)		NULL,
+SyncConflictUri	  varchar(2048) 	NOT NULL,
+TotalSessRecBytes	int				NOT NULL	default(0),
+TotalSessRecCount	int				NOT NULL	default(0),
+ProceSessRecBytes	int				NOT NULL	default(0),
+ProceSessRecCount	int				NOT NULL	default(0),
+RecordCreated			timestamp		NOT NULL	default(CURRENT_TIMESTAMP),
+PRIMARY KEY (PairId, NodeId, TargetNodeId),
+CONSTRAINT FK_sync_pair_nodes_source_source_node FOREIGN KEY(NodeId) REFERENCES sync_node (NodeId),
+CONSTRAINT FK_sync_pair_nodes_source_target_node FOREIGN KEY(TargetNodeId) REFERENCES sync_node (NodeId),
+CONSTRAINT FK_sync_pair_nodes_sync_pair FOREIGN KEY(PairId) REFERENCES sync_pair (PairId),
+CONSTRAINT FK_sync_pair_nodes_sync_data_version FOREIGN KEY(SeededDataVersion) REFERENCES sync_data_version (DataVersionName)
+);
+
+--9:
+CREATE TABLE sync_state_ancestor (
+EntitySingularName	varchar(50)		NOT NULL,
+RecordId						varchar(112)	NOT NULL,
+RecordHash					varchar(100)	NOT NULL,
+RecordData					blob	 				NOT NULL,
+RecordCreated				timestamp			NOT NULL	default(CURRENT_TIMESTAMP),
+PRIMARY KEY (EntitySingularName, RecordId, RecordHash)
+);
+
+--10:
+CREATE TABLE sync_conflict (
+ConflictId					varchar(36)		NOT NULL,
+NodeId							varchar(36)		NOT NULL,
+SessionId						varchar(36)		NULL,
+EntitySingularName	varchar(50)		NOT NULL,
+EntityPluralName		varchar(50)		NOT NULL,
+RecordId						varchar(112)	NOT NULL,
+SyncState						integer				NOT NULL,
+ConflictingFields		varchar(2048)	NULL,
+AncestorRecordData	blob					NULL,
+LocalRecordHash			varchar(100)	NOT NULL,
+LocalIsDelete				boolean				NOT NULL	default(false),
+LocalRecordData			blob					NULL,
+RemoteRecordHash		varchar(100)	NOT NULL,
+RemoteIsDelete			boolean				NOT NULL	default(false),
+RemoteRecordData		blob					NULL,
+RecordCreated				timestamp			NOT NULL	default(CURRENT_TIMESTAMP),
+UNIQUE(NodeId, EntitySingularName, RecordId),
+PRIMARY KEY (ConflictId),
+CONSTRAINT FK_sync_conflict_sync_peer_state FOREIGN KEY(NodeId, EntitySingularName, RecordId) REFERENCES sync_peer_state (NodeId, EntitySingularName, RecordId)
+);
+
+--11:
+CREATE TABLE sync_session_history (
+PairId						varchar(36)		NOT NULL,
+SyncSessionId			varchar(36)		NOT NULL,
+SyncSessionState	varchar(36)		NOT NULL, -- the state the session was in when it was ended
+SyncSessionStart	timestamp			NULL,
+SyncSessionEnd		timestamp			NOT NULL	default(CURRENT_TIMESTAMP),
+EndReason					varchar(36)		NOT NULL, -- 'Expired'
+EndReasonMsg			varchar(1024)	NULL,
+ReleasedCount			int						NOT NULL	default(0),
+PRIMARY KEY (PairId, SyncSessionId),
+CONSTRAINT FK_sync_session_history_sync_pair FOREIGN KEY(PairId) REFERENCES sync_pair (PairId)
+);
+
+--12:
+CREATE TABLE sync_session_mgmt_msg (
+MsgSeq						integer				NOT NULL	PRIMARY KEY AUTOINCREMENT,
+PairId						varchar(36)		NOT NULL,
+SyncSessionId			varchar(36)		NOT NULL,
+SenderNodeId			varchar(36)		NOT NULL,
+MessageType				integer				NOT NULL, -- 1-Heartbeat, 2-SeedingClosed, 3-Cancel, 4-HaultingError, 5-Start
+Result						varchar(1024)	NULL,
+RecordCreated			timestamp			NOT NULL	default(CURRENT_TIMESTAMP),
+CONSTRAINT valid_message_type CHECK (MessageType >= 1 AND MessageType <= 5),
+CONSTRAINT FK_sync_session_mgmt_msg_sync_pair FOREIGN KEY(PairId) REFERENCES sync_pair (PairId)
+);
+`